@@ -17,22 +17,9 @@ var connectCmd = &cobra.Command{
 	SilenceUsage: true, // Don't show usage on errors
 	Long:         `Connect to a MySQL instance, detect topology (standalone, replica, Galera/PXC, Group Replication), and display cluster state.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		connCfg := mysql.ConnectionConfig{
-			Host:     viper.GetString("host"),
-			Port:     viper.GetInt("port"),
-			User:     viper.GetString("user"),
-			Password: viper.GetString("password"),
-			Database: viper.GetString("database"),
-			Socket:   viper.GetString("socket"),
-			TLSMode:  viper.GetString("tls"),
-			TLSCA:    viper.GetString("tls_ca"),
-		}
-
-		if connCfg.Host == "" && connCfg.Socket == "" {
-			connCfg.Host = "127.0.0.1"
-		}
-		if connCfg.User == "" {
-			connCfg.User = "dbsafe"
+		connCfg, _, err := connectionConfig()
+		if err != nil {
+			return err
 		}
 
 		// Prompt for password if not provided
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/nethalo/dbsafe/internal/analyzer"
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var diffCmd = &cobra.Command{
+	Use:          "diff <current.sql> <desired.sql>",
+	Short:        "Plan the safe migration between two CREATE TABLE definitions",
+	SilenceUsage: true, // Don't show usage on errors
+	Long: `Compare two CREATE TABLE statements for the same table and report the
+ALTER TABLE statements that turn the current definition into the desired one,
+in order, each with its own classification (algorithm, lock, risk and
+recommended method) against the live table, plus the combined risk.
+
+Each change gets its own ALTER so it can be run and rolled back separately;
+changes MySQL only accepts together stay in one statement.
+
+With --assume-version the diff is analyzed offline, against the current
+definition, without connecting to a server.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		currentSQL, err := readSQLFile(args[0])
+		if err != nil {
+			return err
+		}
+		desiredSQL, err := readSQLFile(args[1])
+		if err != nil {
+			return err
+		}
+
+		version, err := assumedVersion(cmd)
+		if err != nil {
+			return err
+		}
+		dangerousSize, cautionSize, err := sizeThresholds(cmd)
+		if err != nil {
+			return err
+		}
+		copyRate, err := copyThroughput(cmd)
+		if err != nil {
+			return err
+		}
+		minSeverity, err := minWarningSeverity(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Build connection config
+		connCfg, passwordEnv, err := connectionConfig()
+		if err != nil {
+			return err
+		}
+
+		// Offline (--assume-version) the diff is analyzed without a connection.
+		var conn *sql.DB
+		if version == nil {
+			// Prompt for password if not provided
+			if connCfg.Password == "" {
+				connCfg.Password = mysql.PromptPassword()
+			}
+
+			conn, err = mysql.Connect(connCfg)
+			if err != nil {
+				return fmt.Errorf("connection failed: %w", err)
+			}
+			defer conn.Close()
+		}
+
+		safePtOSC, _ := cmd.Flags().GetBool("generate-safe-ptosc")
+		recursion, err := ptoscRecursionMethod(cmd)
+		if err != nil {
+			return err
+		}
+		ghost, err := ghostReplication(cmd)
+		if err != nil {
+			return err
+		}
+		withHooks, _ := cmd.Flags().GetBool("with-hooks")
+		noOnlineTools, _ := cmd.Flags().GetBool("no-online-tools")
+		connInfo := connectionInfo(connCfg, passwordEnv)
+		plan, err := analyzer.AnalyzeDiff(cmd.Context(), conn, currentSQL, desiredSQL, analyzer.Options{
+			Database:      connCfg.Database,
+			SafePtOSC:     safePtOSC,
+			WithHooks:     withHooks,
+			NoOnlineTools: noOnlineTools,
+			PtOSCChunking: ptoscChunking(cmd),
+			Verbose:       viper.GetBool("verbose"),
+			Version:       version,
+
+			DangerousSizeThreshold: dangerousSize,
+			CautionSizeThreshold:   cautionSize,
+			CopyBytesPerSec:        copyRate,
+			PtOSCRecursionMethod:   recursion,
+			GhostReplication:       ghost,
+			Connection:             connInfo,
+		})
+		if err != nil {
+			return err
+		}
+
+		results := make([]*analyzer.Result, len(plan.Steps))
+		for i, step := range plan.Steps {
+			results[i] = step.Result
+		}
+		if err := appendAuditLog(cmd, connInfo, connCfg.Password, results...); err != nil {
+			return err
+		}
+
+		plan.FilterWarnings(minSeverity)
+		renderer := output.NewRenderer(viper.GetString("format"), os.Stdout)
+		renderer.RenderDiff(plan)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().Bool("generate-safe-ptosc", false, "Emit pt-osc as a --dry-run followed by --execute with --no-drop-old-table")
+	diffCmd.Flags().Bool("with-hooks", false, "Add --hooks-path (gh-ost) or --plugin (pt-osc) to the generated commands, with a commented scaffold for cut-over notifications")
+	diffCmd.Flags().Bool("no-online-tools", false, "Never recommend gh-ost or pt-osc (for environments where they can't be installed): large blocking ALTERs run natively, with the write-blocking time for a maintenance window")
+	addPtOSCFlags(diffCmd)
+	addGhostFlags(diffCmd)
+	addSizeThresholdFlags(diffCmd)
+	addAssumeVersionFlag(diffCmd)
+	addAuditLogFlag(diffCmd)
+	addMinWarningSeverityFlag(diffCmd)
+}
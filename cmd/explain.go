@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/nethalo/dbsafe/internal/analyzer"
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:          "explain <OPERATION>",
+	Short:        "Explain how an operation is classified on a MySQL version",
+	SilenceUsage: true,
+	Long: `Explain the reasoning behind dbsafe's verdict for an operation on a MySQL
+version, without a statement or a connection: the classification matrix entry
+(algorithm, lock, rebuild), why the version falls in its range, and the
+statement- and table-specific checks that can change the verdict.
+
+OPERATION is a name as shown in plan output, e.g. ADD_COLUMN or "modify column".
+
+Examples:
+  dbsafe explain ADD_COLUMN --version 8.0.35
+  dbsafe explain drop_column --version 8.0.28
+  dbsafe explain ADD_FOREIGN_KEY --version 8.0.mysql_aurora.3.05.2`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		op, err := analyzer.ParseOperation(args[0])
+		if err != nil {
+			return err
+		}
+		s, _ := cmd.Flags().GetString("version")
+		version, err := mysql.ParseAssumedVersion(s)
+		if err != nil {
+			return fmt.Errorf("--version: %w", err)
+		}
+		explanation, err := analyzer.ExplainOperation(op, version)
+		if err != nil {
+			return err
+		}
+		printExplanation(cmd.OutOrStdout(), explanation)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.Flags().String("version", "", "MySQL version to explain for, with optional flavor (e.g. 8.0.35, 8.4.2, 8.0.36-percona)")
+	_ = explainCmd.MarkFlagRequired("version")
+}
+
+// printExplanation writes an explanation as prose.
+func printExplanation(w io.Writer, e *analyzer.Explanation) {
+	c := e.Classification
+	rebuild := "no table rebuild"
+	if c.RebuildsTable {
+		rebuild = "rebuilds the table"
+	}
+
+	fmt.Fprintf(w, "%s on MySQL %s\n\n", e.Operation, e.Version.String())
+	fmt.Fprintf(w, "Version range: %s\n  %s\n\n", e.Range, e.RangeReason)
+	fmt.Fprintf(w, "Matrix entry: ALGORITHM=%s, LOCK=%s, %s\n  %s\n", c.Algorithm, c.Lock, rebuild, c.Notes)
+	if len(e.Refinements) > 0 {
+		fmt.Fprintln(w, "\nWith a real statement and table, dbsafe also checks:")
+		for _, r := range e.Refinements {
+			fmt.Fprintf(w, "  • %s\n", r)
+		}
+	}
+}
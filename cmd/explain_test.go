@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/nethalo/dbsafe/internal/analyzer"
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/parser"
+)
+
+func TestPrintExplanation(t *testing.T) {
+	e, err := analyzer.ExplainOperation(parser.DropColumn, mysql.ServerVersion{Major: 8, Minor: 0, Patch: 28, Flavor: "mysql"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	printExplanation(&buf, e)
+	out := buf.String()
+
+	for _, want := range []string{
+		"DROP_COLUMN on MySQL 8.0.28",
+		"Version range: 8.0.12 – 8.0.28",
+		"Matrix entry: ALGORITHM=INPLACE, LOCK=NONE, rebuilds the table",
+		"dbsafe also checks:",
+		"part of an index",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
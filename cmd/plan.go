@@ -1,17 +1,26 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nethalo/dbsafe/internal/analyzer"
 	"github.com/nethalo/dbsafe/internal/mysql"
 	"github.com/nethalo/dbsafe/internal/output"
 	"github.com/nethalo/dbsafe/internal/parser"
-	"github.com/nethalo/dbsafe/internal/topology"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -25,7 +34,23 @@ var planCmd = &cobra.Command{
   - Replication impact
   - Affected row count (for DML)
   - Execution method recommendation (native, gh-ost, pt-osc, chunked)
-  - Rollback plan`,
+  - Rollback plan
+
+With --assume-version the statement is analyzed offline for that server
+version, without connecting: pass the table's CREATE TABLE with --schema-file
+(SHOW CREATE TABLE output, or a mysqldump --no-data of several tables) to check
+it against the table's columns, indexes and foreign keys.
+
+A --file holding several statements separated by semicolons is analyzed in
+order, as a batch: each statement gets its own plan, the combined risk is the
+highest, and consecutive ALTERs on one table that could run as one are
+flagged with the merged statement. An ALTER following one on the same table
+sees the columns and indexes the one before adds or drops.
+
+With --shards the DDL is analyzed on each of the named connections of the
+config file (connections.<name>: host, port, user, password, database, ...),
+which hold the same table at different sizes: the output lists each shard's
+size, risk, method and duration estimate, and the worst case in full.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get SQL from args or --file flag
@@ -34,21 +59,21 @@ var planCmd = &cobra.Command{
 			return err
 		}
 
-		// Parse the SQL
-		parsed, err := parser.Parse(sqlText)
+		// Parse the SQL: a file may hold several statements, analyzed as a batch
+		statements, err := parseStatements(sqlText)
 		if err != nil {
-			return fmt.Errorf("SQL parse error: %w", err)
+			return err
 		}
-
-		// Check if this is an unsupported operation (INSERT/LOAD DATA/CREATE TABLE)
-		if (parsed.Type == parser.DML && (parsed.DMLOp == parser.Insert || parsed.DMLOp == parser.LoadData)) ||
-			(parsed.Type == parser.DDL && parsed.DDLOp == parser.CreateTable) {
-			operationName := "INSERT"
-			if parsed.DMLOp == parser.LoadData {
-				operationName = "LOAD DATA INFILE"
-			} else if parsed.DDLOp == parser.CreateTable {
-				operationName = "CREATE TABLE"
+		parsed := statements[0]
+		batch := len(statements) > 1
+		if batch {
+			if err := checkBatchFlags(cmd); err != nil {
+				return err
 			}
+		}
+
+		// Check if this is an unsupported operation (INSERT/LOAD DATA)
+		if operationName, ok := analyzer.UnsupportedOperation(parsed); ok && !batch {
 			fmt.Fprintf(os.Stderr, "\n⚠️  dbsafe doesn't analyze %s statements\n\n", operationName)
 			fmt.Fprintf(os.Stderr, "This tool is designed to analyze the \"UD\" in CRUD (UPDATE and DELETE),\n")
 			fmt.Fprintf(os.Stderr, "as well as DDL modifications like ALTER TABLE.\n\n")
@@ -57,141 +82,720 @@ var planCmd = &cobra.Command{
 		}
 
 		// Build connection config
-		connCfg := mysql.ConnectionConfig{
-			Host:     viper.GetString("host"),
-			Port:     viper.GetInt("port"),
-			User:     viper.GetString("user"),
-			Password: viper.GetString("password"),
-			Database: viper.GetString("database"),
-			Socket:   viper.GetString("socket"),
-			TLSMode:  viper.GetString("tls"),
-			TLSCA:    viper.GetString("tls_ca"),
-		}
-
-		if connCfg.Host == "" && connCfg.Socket == "" {
-			connCfg.Host = "127.0.0.1"
-		}
-		if connCfg.User == "" {
-			connCfg.User = "dbsafe"
+		connCfg, passwordEnv, err := connectionConfig()
+		if err != nil {
+			return err
 		}
 
-		// Use database from parsed SQL if not specified via flag
+		// Use database from parsed SQL if not specified via flag. In a batch each statement
+		// keeps its own; the first one's is the connection's default.
+		flagDatabase := connCfg.Database
 		if connCfg.Database == "" && parsed.Database != "" {
 			connCfg.Database = parsed.Database
 		}
 
-		// Require a database to be specified (tablespace operations have no associated table/database)
-		if connCfg.Database == "" && parsed.DDLOp != parser.AlterTablespace {
-			return fmt.Errorf("database not specified: use -d flag or specify database in SQL (e.g., ALTER TABLE mydb.users ...)")
+		version, err := assumedVersion(cmd)
+		if err != nil {
+			return err
+		}
+		schemaFile, _ := cmd.Flags().GetString("schema-file")
+		if schemaFile != "" && version == nil {
+			return fmt.Errorf("--schema-file is only used for offline analysis: add --assume-version")
+		}
+		shards, err := shardTargets(cmd, parsed, version)
+		if err != nil {
+			return err
 		}
 
-		// Prompt for password if not provided
-		if connCfg.Password == "" {
-			connCfg.Password = mysql.PromptPassword()
+		// Require a database to be specified (tablespace operations, view/routine/trigger/event
+		// definitions and account management have no associated table). Offline there is no
+		// server to look it up in.
+		if version == nil && shards == nil && !batch && connCfg.Database == "" && parsed.DDLOp != parser.AlterTablespace && parsed.DDLOp != parser.ObjectDefinition && parsed.DDLOp != parser.AccountManagement {
+			return fmt.Errorf("database not specified: use -d flag or specify database in SQL (e.g., ALTER TABLE mydb.users ...)")
 		}
 
-		// Connect
-		conn, err := mysql.Connect(connCfg)
+		chunkSize, _ := cmd.Flags().GetInt("chunk-size")
+		sleepSeconds, _ := cmd.Flags().GetFloat64("sleep-seconds")
+		if sleepSeconds < 0 {
+			return fmt.Errorf("--sleep-seconds: must not be negative, got %v", sleepSeconds)
+		}
+		maxReplicaLag, _ := cmd.Flags().GetInt("max-replica-lag")
+		explainConnect, _ := cmd.Flags().GetBool("explain-connect")
+		safePtOSC, _ := cmd.Flags().GetBool("generate-safe-ptosc")
+		withHooks, _ := cmd.Flags().GetBool("with-hooks")
+		noOnlineTools, _ := cmd.Flags().GetBool("no-online-tools")
+		idempotent, _ := cmd.Flags().GetBool("idempotent")
+		projectSchema, _ := cmd.Flags().GetBool("projected-schema")
+		dangerousSize, cautionSize, err := sizeThresholds(cmd)
 		if err != nil {
-			return fmt.Errorf("connection failed: %w", err)
+			return err
 		}
-		defer conn.Close()
-
-		// Detect topology
-		verbose := viper.GetBool("verbose")
-		topo, err := topology.Detect(conn, verbose)
+		copyRate, err := copyThroughput(cmd)
 		if err != nil {
-			return fmt.Errorf("topology detection failed: %w", err)
+			return err
+		}
+		var freeDisk int64
+		if s, _ := cmd.Flags().GetString("free-disk-bytes"); s != "" {
+			if freeDisk, err = parseSize(s); err != nil {
+				return fmt.Errorf("--free-disk-bytes: %w", err)
+			}
+		}
+		traffic, err := trafficProfile(cmd)
+		if err != nil {
+			return err
+		}
+		trace, _ := cmd.Flags().GetBool("trace")
+		writeRateSample, _ := cmd.Flags().GetDuration("write-rate-sample")
+		recursion, err := ptoscRecursionMethod(cmd)
+		if err != nil {
+			return err
+		}
+		rollbackPath, rollbackFormat, err := rollbackOutput(cmd)
+		if err != nil {
+			return err
+		}
+		minSeverity, err := minWarningSeverity(cmd)
+		if err != nil {
+			return err
+		}
+		ghost, err := ghostReplication(cmd)
+		if err != nil {
+			return err
+		}
+		opts := analyzer.Options{
+			Database:      connCfg.Database,
+			ChunkSize:     chunkSize,
+			SleepSeconds:  &sleepSeconds,
+			MaxReplicaLag: maxReplicaLag,
+			ExplainRows:   explainConnect,
+			SafePtOSC:     safePtOSC,
+			WithHooks:     withHooks,
+			NoOnlineTools: noOnlineTools,
+			PtOSCChunking: ptoscChunking(cmd),
+			Idempotent:    idempotent,
+			ProjectSchema: projectSchema,
+			Verbose:       viper.GetBool("verbose"),
+			Version:       version,
+
+			DangerousSizeThreshold: dangerousSize,
+			CautionSizeThreshold:   cautionSize,
+			CopyBytesPerSec:        copyRate,
+			PtOSCRecursionMethod:   recursion,
+			GhostReplication:       ghost,
+			FreeDiskBytes:          freeDisk,
+			TrafficProfile:         traffic,
+			Trace:                  trace,
+			Connection:             connectionInfo(connCfg, passwordEnv),
+			WriteRateSample:        writeRateSample,
 		}
 
-		// Collect table metadata (skip for tablespace operations — no table involved)
-		var meta *mysql.TableMetadata
-		if parsed.DDLOp == parser.AlterTablespace {
-			meta = &mysql.TableMetadata{}
+		// Offline: classify for the assumed version against the schema dump, if any.
+		if schemaFile != "" {
+			dump, err := readSQLFile(schemaFile)
+			if err != nil {
+				return err
+			}
+			if opts.Metadata, err = analyzer.NewSchemaDump(dump); err != nil {
+				return fmt.Errorf("--schema-file: %w", err)
+			}
+		}
+
+		if shards != nil {
+			return planShards(cmd, parsed, shards, opts, minSeverity)
+		}
+		if batch {
+			opts.Database = flagDatabase
+			return planBatch(cmd, statements, connCfg, opts, minSeverity)
+		}
+
+		var result *analyzer.Result
+		if version != nil {
+			if result, err = analyzer.AnalyzeOffline(parsed, nil, opts); err != nil {
+				return err
+			}
 		} else {
-			meta, err = mysql.GetTableMetadata(conn, connCfg.Database, parsed.Table)
+			// Prompt for password if not provided
+			if connCfg.Password == "" {
+				connCfg.Password = mysql.PromptPassword()
+			}
+
+			// Connect
+			conn, err := mysql.Connect(connCfg)
 			if err != nil {
-				return fmt.Errorf("metadata collection failed: %w", err)
+				return fmt.Errorf("connection failed: %w", err)
+			}
+			defer conn.Close()
+
+			// Load topology, metadata and version, then run the analysis
+			if result, err = analyzer.AnalyzeParsed(cmd.Context(), conn, parsed, opts); err != nil {
+				return err
 			}
 		}
 
-		// Get server version
-		version, err := mysql.GetServerVersion(conn)
-		if err != nil {
-			return fmt.Errorf("version detection failed: %w", err)
+		// Record the analysis before showing it: a plan must not be usable unrecorded
+		if err := appendAuditLog(cmd, opts.Connection, connCfg.Password, result); err != nil {
+			return err
 		}
 
-		// Query foreign_key_checks: determines whether ADD FOREIGN KEY requires COPY or INPLACE.
-		// Default to false (checks enabled = COPY required) if the variable can't be read.
-		fkChecksDisabled := false
-		if fkChecksVal, err := mysql.GetVariable(conn, "foreign_key_checks"); err == nil {
-			lower := strings.ToLower(fkChecksVal)
-			fkChecksDisabled = lower == "off" || lower == "0"
+		// Ask for confirmation before printing any commands
+		if confirm, _ := cmd.Flags().GetBool("confirm"); confirm {
+			if err := confirmBlastRadius(os.Stdin, os.Stderr, result); err != nil {
+				return err
+			}
 		}
 
-		// For DML with WHERE clause, run EXPLAIN to estimate affected rows
-		var estimatedRows int64
-		if parsed.Type == parser.DML && parsed.HasWhere {
-			estimatedRows, err = mysql.EstimateRowsAffected(conn, parsed.RawSQL)
-			if err != nil {
-				// Log warning but continue with 0 estimate
-				fmt.Fprintf(os.Stderr, "Warning: EXPLAIN failed: %v\n", err)
+		// Everything is recorded and confirmed; leave out of the output what wasn't asked for
+		result.FilterWarnings(minSeverity)
+
+		// Render output: only the command when piping into a runner
+		if commandsOnly, _ := cmd.Flags().GetBool("commands-only"); commandsOnly {
+			if err := dangerousCommandsError(os.Stderr, result); err != nil {
+				return err
+			}
+			if err := writeCommands(os.Stdout, os.Stderr, result); err != nil {
+				return err
 			}
+		} else {
+			format := viper.GetString("format")
+			renderer := output.NewRenderer(format, os.Stdout)
+			renderer.RenderPlan(result)
 		}
 
-		// Run analysis
-		chunkSize, _ := cmd.Flags().GetInt("chunk-size")
-		result := analyzer.Analyze(analyzer.Input{
-			Parsed:                   parsed,
-			Meta:                     meta,
-			Topo:                     topo,
-			Version:                  version,
-			ChunkSize:                chunkSize,
-			EstimatedRows:            estimatedRows,
-			ForeignKeyChecksDisabled: fkChecksDisabled,
-			Connection: &analyzer.ConnectionInfo{
-				Host:     connCfg.Host,
-				Port:     connCfg.Port,
-				User:     connCfg.User,
-				Socket:   connCfg.Socket,
-				Database: connCfg.Database,
-			},
-		})
-
-		// Generate idempotent stored procedure wrapper if requested
-		if idempotent, _ := cmd.Flags().GetBool("idempotent"); idempotent && result.StatementType == parser.DDL {
-			sp, warn := analyzer.GenerateIdempotentSP(parsed, result.Database, result.Table)
-			result.IdempotentSP = sp
-			if warn != "" {
-				result.Warnings = append(result.Warnings, warn)
+		// Write the Markdown report if requested
+		if reportPath, _ := cmd.Flags().GetString("report"); reportPath != "" {
+			var buf bytes.Buffer
+			output.WriteReport(&buf, result)
+			if err := os.WriteFile(reportPath, buf.Bytes(), 0600); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not write report to %s: %v\n", reportPath, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "✓ Markdown report written to %s (permissions: 0600)\n", reportPath)
 			}
 		}
 
-		// Render output
-		format := viper.GetString("format")
-		renderer := output.NewRenderer(format, os.Stdout)
-		renderer.RenderPlan(result)
+		// Write the rollback changeset if requested
+		if rollbackPath != "" {
+			var buf bytes.Buffer
+			if err := output.WriteRollback(&buf, result, rollbackFormat); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not write rollback to %s: %v\n", rollbackPath, err)
+			} else if err := os.WriteFile(rollbackPath, buf.Bytes(), 0600); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not write rollback to %s: %v\n", rollbackPath, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "✓ Rollback (%s) written to %s (permissions: 0600)\n", rollbackFormat, rollbackPath)
+			}
+		}
 
 		// Write generated scripts if any
 		if result.GeneratedScript != "" {
-			scriptPath := result.ScriptPath
-			// Security: Use 0600 (owner read/write only) to prevent exposure of sensitive SQL
-			if err := os.WriteFile(scriptPath, []byte(result.GeneratedScript), 0600); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: could not write script to %s: %v\n", scriptPath, err)
-			} else {
-				fmt.Fprintf(os.Stderr, "✓ Chunked script written to %s (permissions: 0600)\n", scriptPath)
-			}
+			writeScript(os.Stderr, result.ScriptPath, result.GeneratedScript)
 		}
 
 		return nil
 	},
 }
 
+// parseStatements parses sqlText, which may hold several statements separated by
+// semicolons.
+func parseStatements(sqlText string) ([]*parser.ParsedSQL, error) {
+	pieces, err := parser.SplitStatements(sqlText)
+	if err != nil || len(pieces) <= 1 {
+		parsed, err := parser.Parse(sqlText)
+		if err != nil {
+			return nil, fmt.Errorf("SQL parse error: %w", err)
+		}
+		return []*parser.ParsedSQL{parsed}, nil
+	}
+	statements := make([]*parser.ParsedSQL, len(pieces))
+	for i, piece := range pieces {
+		if statements[i], err = parser.Parse(piece); err != nil {
+			return nil, fmt.Errorf("statement %d: SQL parse error: %w", i+1, err)
+		}
+	}
+	return statements, nil
+}
+
+// checkBatchFlags rejects the flags that act on a single analysis for a file of several
+// statements.
+func checkBatchFlags(cmd *cobra.Command) error {
+	for _, flag := range []string{"shards", "confirm", "report", "rollback-file", "idempotent", "projected-schema"} {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s works on a single statement: it can't be used with a file of several statements", flag)
+		}
+	}
+	return nil
+}
+
+// planBatch analyzes the statements of a file in order (see analyzer.AnalyzeBatch),
+// records each analysis in the audit log and renders them together, with the warnings
+// about the statements as a whole.
+func planBatch(cmd *cobra.Command, statements []*parser.ParsedSQL, connCfg mysql.ConnectionConfig, opts analyzer.Options, minSeverity analyzer.Severity) error {
+	var conn *sql.DB
+	if opts.Version == nil {
+		// Prompt for password if not provided
+		if connCfg.Password == "" {
+			connCfg.Password = mysql.PromptPassword()
+		}
+		var err error
+		if conn, err = mysql.Connect(connCfg); err != nil {
+			return fmt.Errorf("connection failed: %w", err)
+		}
+		defer conn.Close()
+	}
+
+	inputs, err := analyzer.BatchInputs(cmd.Context(), conn, statements, opts)
+	if err != nil {
+		return err
+	}
+	batch := analyzer.AnalyzeBatch(inputs)
+
+	if err := appendAuditLog(cmd, opts.Connection, connCfg.Password, batch.Results...); err != nil {
+		return err
+	}
+	batch.FilterWarnings(minSeverity)
+
+	out, errOut := cmd.OutOrStdout(), cmd.ErrOrStderr()
+	if commandsOnly, _ := cmd.Flags().GetBool("commands-only"); commandsOnly {
+		if err := dangerousCommandsError(errOut, batch.Results...); err != nil {
+			return err
+		}
+		for _, result := range batch.Results {
+			if err := writeCommands(out, errOut, result); err != nil {
+				return err
+			}
+		}
+	} else {
+		output.NewRenderer(viper.GetString("format"), out).RenderBatch(batch)
+	}
+
+	// Two chunked statements on the same table can get the same script name.
+	written := map[string]bool{}
+	for i, result := range batch.Results {
+		if result.GeneratedScript == "" {
+			continue
+		}
+		path := result.ScriptPath
+		if written[path] {
+			ext := filepath.Ext(path)
+			path = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), i+1, ext)
+		}
+		written[path] = true
+		writeScript(errOut, path, result.GeneratedScript)
+	}
+	return nil
+}
+
+// writeScript writes a generated script to path, reporting the outcome on errOut.
+func writeScript(errOut io.Writer, path, script string) {
+	// Security: Use 0600 (owner read/write only) to prevent exposure of sensitive SQL
+	if err := os.WriteFile(path, []byte(script), 0600); err != nil {
+		fmt.Fprintf(errOut, "Warning: could not write script to %s: %v\n", path, err)
+	} else {
+		fmt.Fprintf(errOut, "✓ Chunked script written to %s (permissions: 0600)\n", path)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(planCmd)
 	planCmd.Flags().String("file", "", "Read SQL from file instead of argument")
-	planCmd.Flags().Int("chunk-size", 10000, "Override default chunk size for DML recommendations")
+	planCmd.Flags().Int("chunk-size", analyzer.DefaultChunkSize, "Override default chunk size for DML recommendations (alias: --batch-size)")
+	planCmd.Flags().Float64("sleep-seconds", 0.5, "Seconds to pause between chunks in the generated chunked script")
+	planCmd.Flags().Int("max-replica-lag", 0, "Generate the chunked script as a shell script that backs off while replica lag exceeds this many seconds (0 = off)")
+	// --batch-size is an alias for --chunk-size.
+	planCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "batch-size" {
+			name = "chunk-size"
+		}
+		return pflag.NormalizedName(name)
+	})
 	planCmd.Flags().Bool("idempotent", false, "Generate an idempotent stored procedure wrapper for the DDL")
+	planCmd.Flags().Bool("projected-schema", false, "Show the table's CREATE TABLE as the ALTER leaves it, with the changed lines marked: the live SHOW CREATE TABLE, or the --schema-file definition offline")
+	planCmd.Flags().Bool("generate-safe-ptosc", false, "Emit pt-osc as a --dry-run followed by --execute with --no-drop-old-table")
+	planCmd.Flags().Bool("with-hooks", false, "Add --hooks-path (gh-ost) or --plugin (pt-osc) to the generated command, with a commented scaffold for cut-over notifications")
+	planCmd.Flags().Bool("no-online-tools", false, "Never recommend gh-ost or pt-osc (for environments where they can't be installed): large blocking ALTERs run natively, with the write-blocking time for a maintenance window")
+	addPtOSCFlags(planCmd)
+	addGhostFlags(planCmd)
+	addSizeThresholdFlags(planCmd)
+	addAssumeVersionFlag(planCmd)
+	planCmd.Flags().String("schema-file", "", "With --assume-version: schema dump holding the target table's CREATE TABLE (SHOW CREATE TABLE output, or mysqldump --no-data) to analyze against")
+	planCmd.Flags().String("free-disk-bytes", "", "Free space on the server's data directory filesystem, e.g. 200GB, checked against the disk estimate (default: read from @@datadir when the server is local)")
+	planCmd.Flags().String("traffic-profile", "", "Hourly traffic weights, inline or in a file, e.g. '0-1=1,2=1,3=8 batch jobs,4-6=2,7-23=10': recommend a start time that fits the estimated duration into the quietest hours")
+	planCmd.Flags().Duration("write-rate-sample", time.Second, "How long to sample the table's write rate from performance_schema before an ALTER that blocks writes: a hot table gets an online schema change tool below the size thresholds, a cold one a direct INPLACE ALTER above them (0 = don't sample)")
+	planCmd.Flags().Bool("trace", false, "Show each classification decision: the matrix baseline, every override that fired, and the resulting risk and method")
+	planCmd.Flags().String("report", "", "Also write the full analysis as a Markdown report to this file")
+	planCmd.Flags().String("rollback-file", "", "Also write the rollback as a migration framework changeset to this file, e.g. a Flyway undo migration U2__add_email.sql")
+	planCmd.Flags().String("rollback-format", string(output.RollbackFlyway), "Framework of the --rollback-file changeset: flyway")
+	addAuditLogFlag(planCmd)
+	addMinWarningSeverityFlag(planCmd)
+	planCmd.Flags().Bool("commands-only", false, "Print only the command to run (the optimized DDL, gh-ost/pt-osc command or chunked script) on stdout, for piping into a job runner")
+	planCmd.Flags().Bool("confirm", false, "Print a blast-radius summary and require typing the table name before emitting commands")
+	planCmd.Flags().StringSlice("shards", nil, "Analyze the DDL on each of these connections of the config file (connections.<name>), e.g. shard1,shard2,shard3, and report the worst case")
+	planCmd.Flags().Bool("explain-connect", true, "Run EXPLAIN on DELETE/UPDATE (and the SELECT of CREATE TABLE ... AS SELECT) over the live connection to estimate affected rows (optimizer estimate, approximate)")
+}
+
+// addPtOSCFlags registers the flags that tune generated pt-osc commands: their chunking and
+// how they find the replicas to watch for lag.
+func addPtOSCFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("ptosc-chunk-size", 0, "pt-osc --chunk-size: rows in the first chunk (0 = 1000)")
+	cmd.Flags().Float64("ptosc-chunk-time", 0, "pt-osc --chunk-time: seconds each chunk should take (0 = from the topology: 0.2 on Galera/Group Replication, 0.25 on semi-sync, 0.5 otherwise)")
+	cmd.Flags().Float64("ptosc-chunk-size-limit", 0, "pt-osc --chunk-size-limit: skip chunks larger than this multiple of the chunk size (0 = 4)")
+	cmd.Flags().String("ptosc-recursion-method", "", "pt-osc --recursion-method for finding replicas: processlist, hosts, dsn[=DSN] (default table D=percona,t=dsns) or none (default: none on Aurora/RDS, processlist otherwise)")
+}
+
+// shardTarget is one of the connections --shards names.
+type shardTarget struct {
+	name        string
+	cfg         mysql.ConnectionConfig
+	passwordEnv string
+}
+
+// shardTargets returns the connections named by --shards, nil without the flag. Every name
+// has to be a connection of the config file, listed once, and the statement a DDL
+// analyzed live, with none of the flags that act on a single result.
+func shardTargets(cmd *cobra.Command, parsed *parser.ParsedSQL, version *mysql.ServerVersion) ([]shardTarget, error) {
+	names, _ := cmd.Flags().GetStringSlice("shards")
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if version != nil {
+		return nil, fmt.Errorf("--shards analyzes each shard live: drop --assume-version")
+	}
+	if parsed.Type != parser.DDL {
+		return nil, fmt.Errorf("--shards analyzes DDL, not %s statements", parsed.Type)
+	}
+	for _, flag := range []string{"commands-only", "confirm", "report", "rollback-file"} {
+		if cmd.Flags().Changed(flag) {
+			return nil, fmt.Errorf("--%s works on a single analysis: it can't be combined with --shards", flag)
+		}
+	}
+
+	targets := make([]shardTarget, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !targetName.MatchString(name) {
+			return nil, fmt.Errorf("--shards: %w %q: not a connection name", errUnknownTarget, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("--shards: %s is listed twice", name)
+		}
+		seen[name] = true
+		cfg, passwordEnv, err := targetConfig(name)
+		if err != nil {
+			return nil, fmt.Errorf("--shards: %w", err)
+		}
+		targets = append(targets, shardTarget{name: name, cfg: cfg, passwordEnv: passwordEnv})
+	}
+	return targets, nil
+}
+
+// planShards analyzes the statement on each shard in turn, records each analysis in the
+// audit log and renders them with the worst case. A shard that can't be reached or
+// analyzed is reported with the others rather than stopping the run.
+func planShards(cmd *cobra.Command, parsed *parser.ParsedSQL, shards []shardTarget, opts analyzer.Options, minSeverity analyzer.Severity) error {
+	results := make([]analyzer.ShardResult, 0, len(shards))
+	for _, shard := range shards {
+		result, conn, err := planShard(cmd.Context(), parsed, shard, opts)
+		if err == nil {
+			if err := appendAuditLog(cmd, conn, shard.cfg.Password, result); err != nil {
+				return err
+			}
+		}
+		results = append(results, analyzer.ShardResult{Name: shard.name, Result: result, Err: err})
+	}
+
+	plan := analyzer.AggregateShards(results)
+	plan.FilterWarnings(minSeverity)
+	renderer := output.NewRenderer(viper.GetString("format"), os.Stdout)
+	renderer.RenderShards(plan)
+	return nil
+}
+
+// planShard analyzes the statement on one shard, in the shard's database when its
+// connection names one, in opts.Database otherwise. It also returns the connection the
+// generated commands use.
+func planShard(ctx context.Context, parsed *parser.ParsedSQL, shard shardTarget, opts analyzer.Options) (*analyzer.Result, *analyzer.ConnectionInfo, error) {
+	cfg := shard.cfg
+	if cfg.Database == "" {
+		cfg.Database = opts.Database
+	}
+	conn, err := mysql.Connect(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connection failed: %w", err)
+	}
+	defer conn.Close()
+
+	opts.Database = cfg.Database
+	opts.Connection = connectionInfo(cfg, shard.passwordEnv)
+	result, err := analyzer.AnalyzeParsed(ctx, conn, parsed, opts)
+	return result, opts.Connection, err
+}
+
+// flywayUndoName matches the file names Flyway picks up as undo migrations.
+var flywayUndoName = regexp.MustCompile(`^U[0-9]+([._][0-9]+)*__.+\.sql$`)
+
+func addMinWarningSeverityFlag(cmd *cobra.Command) {
+	cmd.Flags().String("min-warning-severity", "informational", "Only print warnings of at least this severity: informational (all), caution or dangerous (only those that make the statement fail or lose data). The audit log keeps them all")
+}
+
+// minWarningSeverity returns the lowest warning severity --min-warning-severity prints.
+func minWarningSeverity(cmd *cobra.Command) (analyzer.Severity, error) {
+	s, _ := cmd.Flags().GetString("min-warning-severity")
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "informational", "info":
+		return analyzer.SeverityInfo, nil
+	case "caution", "warning":
+		return analyzer.SeverityWarning, nil
+	case "dangerous", "critical":
+		return analyzer.SeverityCritical, nil
+	}
+	return "", fmt.Errorf("--min-warning-severity %q: use informational, caution or dangerous", s)
+}
+
+func addAuditLogFlag(cmd *cobra.Command) {
+	cmd.Flags().String("audit-log", "", "Append a JSON line per analysis to this file (timestamp, user, statement, classification, risk, method, command; password redacted), each with the SHA-256 of the line before it for tamper-evidence")
+}
+
+// appendAuditLog appends an audit log line for each result to the --audit-log file, if
+// given. Unlike the report, a failure is an error: the analysis must not go unrecorded.
+func appendAuditLog(cmd *cobra.Command, conn *analyzer.ConnectionInfo, password string, results ...*analyzer.Result) error {
+	path, _ := cmd.Flags().GetString("audit-log")
+	if path == "" {
+		return nil
+	}
+	entries := make([]output.AuditEntry, len(results))
+	for i, result := range results {
+		entries[i] = output.NewAuditEntry(result, conn, password)
+	}
+	if err := output.AppendAudit(path, entries...); err != nil {
+		return fmt.Errorf("--audit-log: %w", err)
+	}
+	return nil
+}
+
+// rollbackOutput returns the --rollback-file and its --rollback-format; the path is ""
+// when unset. A Flyway undo migration whose name Flyway wouldn't pick up gets a warning.
+func rollbackOutput(cmd *cobra.Command) (string, output.RollbackFormat, error) {
+	path, _ := cmd.Flags().GetString("rollback-file")
+	name, _ := cmd.Flags().GetString("rollback-format")
+	format := output.RollbackFormat(strings.ToLower(name))
+	if !slices.Contains(output.RollbackFormats(), format) {
+		return "", "", fmt.Errorf("--rollback-format %q: use one of %v", name, output.RollbackFormats())
+	}
+	if path != "" && format == output.RollbackFlyway && !flywayUndoName.MatchString(filepath.Base(path)) {
+		fmt.Fprintf(os.Stderr, "Warning: Flyway only runs undo migrations named U<version>__<description>.sql, not %s\n", filepath.Base(path))
+	}
+	return path, format, nil
+}
+
+// addSizeThresholdFlags registers --dangerous-size and --caution-size, the table sizes
+// that bound the DDL risk bands, and --copy-throughput, which turns sizes into durations.
+func addSizeThresholdFlags(cmd *cobra.Command) {
+	cmd.Flags().String("dangerous-size", "", "Table size above which COPY and locking INPLACE operations are DANGEROUS and need an online schema change tool, e.g. 5GB (default 1GB)")
+	cmd.Flags().String("caution-size", "", "Table size above which non-locking INPLACE operations are CAUTION, e.g. 50GB (default 10GB)")
+	cmd.Flags().String("copy-throughput", "", "Bytes per second a native table copy or index build runs at on this server, for the duration estimates, e.g. 100MB (default 25MB)")
+}
+
+// sizeThresholds returns the --dangerous-size and --caution-size in bytes, 0 when unset.
+func sizeThresholds(cmd *cobra.Command) (dangerous, caution int64, err error) {
+	for _, f := range []struct {
+		name string
+		dst  *int64
+	}{{"dangerous-size", &dangerous}, {"caution-size", &caution}} {
+		s, _ := cmd.Flags().GetString(f.name)
+		if s == "" {
+			continue
+		}
+		if *f.dst, err = parseSize(s); err != nil {
+			return 0, 0, fmt.Errorf("--%s: %w", f.name, err)
+		}
+	}
+	return dangerous, caution, nil
+}
+
+// copyThroughput returns the --copy-throughput in bytes per second, 0 when unset.
+func copyThroughput(cmd *cobra.Command) (int64, error) {
+	s, _ := cmd.Flags().GetString("copy-throughput")
+	if s == "" {
+		return 0, nil
+	}
+	rate, err := parseSize(s)
+	if err != nil {
+		return 0, fmt.Errorf("--copy-throughput: %w", err)
+	}
+	return rate, nil
+}
+
+// trafficProfile returns the --traffic-profile, read from the file it names or parsed
+// inline; nil when unset.
+func trafficProfile(cmd *cobra.Command) (*analyzer.TrafficProfile, error) {
+	spec, _ := cmd.Flags().GetString("traffic-profile")
+	if spec == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(spec); err == nil {
+		if spec, err = readSQLFile(spec); err != nil {
+			return nil, fmt.Errorf("--traffic-profile: %w", err)
+		}
+	}
+	profile, err := analyzer.ParseTrafficProfile(spec)
+	if err != nil {
+		return nil, fmt.Errorf("--traffic-profile: %w", err)
+	}
+	return profile, nil
+}
+
+// parseSize parses a size in bytes with an optional binary unit: 512MB, 1.5G, 10GB, 1TB.
+func parseSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		bytes  float64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	}
+	number, multiplier := strings.ToUpper(strings.TrimSpace(s)), 1.0
+	for _, u := range units {
+		if strings.HasSuffix(number, u.suffix) {
+			number, multiplier = strings.TrimSpace(strings.TrimSuffix(number, u.suffix)), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q: want a positive number with an optional unit, e.g. 500MB or 5GB", s)
+	}
+	return int64(n * multiplier), nil
+}
+
+// addAssumeVersionFlag registers --assume-version, which switches a command to offline analysis.
+func addAssumeVersionFlag(cmd *cobra.Command) {
+	cmd.Flags().String("assume-version", "", "Analyze offline, without connecting, for this server version and optional flavor (e.g. 8.0.36, 8.0.36-percona, 8.0.mysql_aurora.3.05.2)")
+}
+
+// assumedVersion returns the --assume-version, or nil when the command should connect.
+func assumedVersion(cmd *cobra.Command) (*mysql.ServerVersion, error) {
+	s, _ := cmd.Flags().GetString("assume-version")
+	if s == "" {
+		return nil, nil
+	}
+	v, err := mysql.ParseAssumedVersion(s)
+	if err != nil {
+		return nil, fmt.Errorf("--assume-version: %w", err)
+	}
+	return &v, nil
+}
+
+func addGhostFlags(cmd *cobra.Command) {
+	cmd.Flags().String("ghost-assume-master-host", "", "gh-ost --assume-master-host (host[:port]): the primary, when gh-ost can't find it from the server it connects to (master-master, or a replication chain with unreachable Source_Host names; default: the writable side of a detected master-master pair)")
+	cmd.Flags().Int("ghost-replica-server-id", 0, "gh-ost --replica-server-id: the server_id gh-ost reads the binlogs with, when its default 99999 is used by a server or another running migration (0 = gh-ost's default)")
+}
+
+// ghostReplication returns the gh-ost --assume-master-host and --replica-server-id flags.
+func ghostReplication(cmd *cobra.Command) (analyzer.GhostReplication, error) {
+	host, _ := cmd.Flags().GetString("ghost-assume-master-host")
+	id, _ := cmd.Flags().GetInt("ghost-replica-server-id")
+	if id < 0 {
+		return analyzer.GhostReplication{}, fmt.Errorf("--ghost-replica-server-id: must be a positive server_id, got %d", id)
+	}
+	return analyzer.GhostReplication{AssumeMasterHost: strings.TrimSpace(host), ReplicaServerID: id}, nil
+}
+
+func ptoscChunking(cmd *cobra.Command) analyzer.PtOSCChunking {
+	size, _ := cmd.Flags().GetInt("ptosc-chunk-size")
+	seconds, _ := cmd.Flags().GetFloat64("ptosc-chunk-time")
+	limit, _ := cmd.Flags().GetFloat64("ptosc-chunk-size-limit")
+	return analyzer.PtOSCChunking{ChunkSize: size, ChunkTime: seconds, ChunkSizeLimit: limit}
+}
+
+// ptoscRecursionMethod returns the --ptosc-recursion-method, "" when unset.
+func ptoscRecursionMethod(cmd *cobra.Command) (string, error) {
+	method, _ := cmd.Flags().GetString("ptosc-recursion-method")
+	switch name, _, _ := strings.Cut(method, "="); name {
+	case "", "processlist", "hosts", "none":
+		if strings.Contains(method, "=") {
+			return "", fmt.Errorf("--ptosc-recursion-method: only dsn takes a value, got %q", method)
+		}
+		return method, nil
+	case "dsn":
+		return method, nil
+	}
+	return "", fmt.Errorf("--ptosc-recursion-method: want processlist, hosts, dsn[=DSN] or none, got %q", method)
+}
+
+// confirmBlastRadius prints the blast-radius summary to out and reads a line from in.
+// Returns an error unless the line matches the table name.
+func confirmBlastRadius(in io.Reader, out io.Writer, result *analyzer.Result) error {
+	fmt.Fprintf(out, "\n%s\n", analyzer.BlastRadiusSummary(result))
+	fmt.Fprintf(out, "Type the table name (%s) to continue: ", result.Table)
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return fmt.Errorf("confirmation aborted: %w", err)
+	}
+	if strings.TrimSpace(line) != result.Table {
+		return fmt.Errorf("confirmation failed: expected %q, got %q", result.Table, strings.TrimSpace(line))
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+// writeCommands writes only the command that carries out the plan to out: the optimized DDL
+// (or the statement itself) for DIRECT, the chunked script for CHUNKED, and the gh-ost or
+// pt-osc command otherwise. The alternative tool's command, if any, goes to errOut.
+// Returns an error when no command could be generated.
+func writeCommands(out, errOut io.Writer, result *analyzer.Result) error {
+	command := result.Command()
+	if command == "" {
+		return fmt.Errorf("no %s command could be generated for this statement (gh-ost and pt-osc need connection details): run without --commands-only for the full plan", result.Method)
+	}
+	fmt.Fprintln(out, strings.TrimRight(command, "\n"))
+	if result.AlternativeExecutionCommand != "" {
+		fmt.Fprintf(errOut, "# Alternative (%s):\n%s\n", result.AlternativeMethod, strings.TrimRight(result.AlternativeExecutionCommand, "\n"))
+	}
+	return nil
+}
+
+// dangerousCommandsError writes a note to errOut for each DANGEROUS result whose command
+// doesn't take the danger away, with its critical warnings, and returns an error if there was
+// one. --commands-only feeds a job runner, which must get nothing to run when the plan needs
+// a review first.
+func dangerousCommandsError(errOut io.Writer, results ...*analyzer.Result) error {
+	dangerous := 0
+	for _, result := range results {
+		if !unmitigatedRisk(result) {
+			continue
+		}
+		dangerous++
+		fmt.Fprintf(errOut, "# DANGEROUS: %s\n", strings.TrimSpace(result.Statement))
+		for _, w := range slices.Concat(result.Warnings, result.ClusterWarnings) {
+			if w.Severity == analyzer.SeverityCritical {
+				fmt.Fprintf(errOut, "#   %s\n", w.Message)
+			}
+		}
+	}
+	if dangerous == 0 {
+		return nil
+	}
+	what := "the statement is DANGEROUS"
+	if len(results) > 1 {
+		what = fmt.Sprintf("%d of %d statements are DANGEROUS", dangerous, len(results))
+	}
+	return fmt.Errorf("%s, so no commands were printed: run without --commands-only to review the full plan", what)
+}
+
+// unmitigatedRisk reports whether a DANGEROUS result's command runs the danger as is: a
+// DIRECT statement, or any method with a critical warning (the statement fails or loses data
+// as written). gh-ost, pt-osc and chunked DML are how dbsafe mitigates a large change, so
+// their commands are what --commands-only is for.
+func unmitigatedRisk(result *analyzer.Result) bool {
+	if result.Risk != analyzer.RiskDangerous {
+		return false
+	}
+	if result.Method == analyzer.ExecDirect {
+		return true
+	}
+	return slices.ContainsFunc(slices.Concat(result.Warnings, result.ClusterWarnings), func(w analyzer.Warning) bool {
+		return w.Severity == analyzer.SeverityCritical
+	})
 }
 
 // validateSQLFilePath checks if the file path is safe to read.
@@ -239,16 +843,7 @@ func getSQLInput(cmd *cobra.Command, args []string) (string, error) {
 	filePath, _ := cmd.Flags().GetString("file")
 
 	if filePath != "" {
-		// Security: Validate file path before reading
-		if err := validateSQLFilePath(filePath); err != nil {
-			return "", fmt.Errorf("file validation failed: %w", err)
-		}
-
-		data, err := os.ReadFile(filePath)
-		if err != nil {
-			return "", fmt.Errorf("could not read file %s: %w", filePath, err)
-		}
-		return strings.TrimSpace(string(data)), nil
+		return readSQLFile(filePath)
 	}
 
 	if len(args) > 0 {
@@ -257,3 +852,17 @@ func getSQLInput(cmd *cobra.Command, args []string) (string, error) {
 
 	return "", fmt.Errorf("provide a SQL statement as argument or use --file flag")
 }
+
+// readSQLFile validates and reads a SQL file, trimming surrounding whitespace.
+func readSQLFile(path string) (string, error) {
+	// Security: Validate file path before reading
+	if err := validateSQLFilePath(path); err != nil {
+		return "", fmt.Errorf("file validation failed: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not read file %s: %w", path, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -1,9 +1,18 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/nethalo/dbsafe/internal/analyzer"
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/parser"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 func TestGetSQLInput_FromArgs(t *testing.T) {
@@ -149,6 +158,65 @@ func TestPlanCmd_Flags(t *testing.T) {
 	if chunkSizeFlag.DefValue != "10000" {
 		t.Errorf("chunk-size default = %s, want 10000", chunkSizeFlag.DefValue)
 	}
+
+	explainFlag := planCmd.Flags().Lookup("explain-connect")
+	if explainFlag == nil {
+		t.Error("plan command should have --explain-connect flag")
+		return
+	}
+	if explainFlag.DefValue != "true" {
+		t.Errorf("explain-connect default = %s, want true", explainFlag.DefValue)
+	}
+
+	safeFlag := planCmd.Flags().Lookup("generate-safe-ptosc")
+	if safeFlag == nil {
+		t.Error("plan command should have --generate-safe-ptosc flag")
+		return
+	}
+	if safeFlag.DefValue != "false" {
+		t.Errorf("generate-safe-ptosc default = %s, want false", safeFlag.DefValue)
+	}
+
+	reportFlag := planCmd.Flags().Lookup("report")
+	if reportFlag == nil {
+		t.Error("plan command should have --report flag")
+		return
+	}
+	if reportFlag.DefValue != "" {
+		t.Errorf("report default = %q, want empty", reportFlag.DefValue)
+	}
+
+	confirmFlag := planCmd.Flags().Lookup("confirm")
+	if confirmFlag == nil {
+		t.Error("plan command should have --confirm flag")
+		return
+	}
+	if confirmFlag.DefValue != "false" {
+		t.Errorf("confirm default = %s, want false", confirmFlag.DefValue)
+	}
+
+	sleepFlag := planCmd.Flags().Lookup("sleep-seconds")
+	if sleepFlag == nil {
+		t.Error("plan command should have --sleep-seconds flag")
+		return
+	}
+	if sleepFlag.DefValue != "0.5" {
+		t.Errorf("sleep-seconds default = %s, want 0.5", sleepFlag.DefValue)
+	}
+
+	lagFlag := planCmd.Flags().Lookup("max-replica-lag")
+	if lagFlag == nil {
+		t.Error("plan command should have --max-replica-lag flag")
+		return
+	}
+	if lagFlag.DefValue != "0" {
+		t.Errorf("max-replica-lag default = %s, want 0", lagFlag.DefValue)
+	}
+
+	// --batch-size is normalized to --chunk-size
+	if planCmd.Flags().Lookup("batch-size") != chunkSizeFlag {
+		t.Error("--batch-size should be an alias for --chunk-size")
+	}
 }
 
 func TestPlanCmd_MaxArgs(t *testing.T) {
@@ -230,3 +298,384 @@ func TestGetSQLInput_FileWithWhitespace(t *testing.T) {
 		t.Errorf("getSQLInput() = %q, want %q", sql, expected)
 	}
 }
+
+func TestConfirmBlastRadius(t *testing.T) {
+	result := &analyzer.Result{
+		StatementType: parser.DDL,
+		DDLOp:         parser.AddIndex,
+		Database:      "shop",
+		Table:         "orders",
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"matching table name", "orders\n", false},
+		{"surrounding whitespace", "  orders  \n", false},
+		{"wrong table name", "users\n", true},
+		{"empty line", "\n", true},
+		{"no input", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := confirmBlastRadius(strings.NewReader(tt.input), &out, result)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("confirmBlastRadius() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), "Blast radius — shop.orders") {
+				t.Errorf("summary not printed, got: %q", out.String())
+			}
+			if !strings.Contains(out.String(), "Type the table name (orders)") {
+				t.Errorf("prompt not printed, got: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"1073741824", 1 << 30},
+		{"5GB", 5 << 30},
+		{"512mb", 512 << 20},
+		{"1.5G", 3 << 29},
+		{"2 TB", 2 << 40},
+		{"100K", 100 << 10},
+	}
+	for _, tt := range tests {
+		got, err := parseSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseSize(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "GB", "-1GB", "five"} {
+		if _, err := parseSize(bad); err == nil {
+			t.Errorf("parseSize(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestPtOSCRecursionMethod(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		wantErr bool
+	}{
+		{"", false},
+		{"processlist", false},
+		{"none", false},
+		{"dsn", false},
+		{"dsn=D=percona,t=dsns", false},
+		{"hosts=1", true},
+		{"cluster", true},
+	} {
+		cmd := &cobra.Command{}
+		addPtOSCFlags(cmd)
+		if err := cmd.Flags().Set("ptosc-recursion-method", tt.in); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ptoscRecursionMethod(cmd)
+		if (err != nil) != tt.wantErr || (err == nil && got != tt.in) {
+			t.Errorf("ptoscRecursionMethod(%q) = %q, %v, want error %v", tt.in, got, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWriteCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *analyzer.Result
+		wantOut string
+		wantErr string
+		wantAlt bool
+	}{
+		{
+			name: "direct prints the optimized DDL",
+			result: &analyzer.Result{
+				Method:       analyzer.ExecDirect,
+				Statement:    "ALTER TABLE t ADD COLUMN c INT",
+				OptimizedDDL: "ALTER TABLE t ADD COLUMN c INT, ALGORITHM=INSTANT, LOCK=NONE;",
+			},
+			wantOut: "ALTER TABLE t ADD COLUMN c INT, ALGORITHM=INSTANT, LOCK=NONE;\n",
+		},
+		{
+			name:    "direct without optimized DDL prints the statement",
+			result:  &analyzer.Result{Method: analyzer.ExecDirect, Statement: "DELETE FROM t WHERE id = 1"},
+			wantOut: "DELETE FROM t WHERE id = 1;\n",
+		},
+		{
+			name: "online tool command with alternative on stderr",
+			result: &analyzer.Result{
+				Method:                      analyzer.ExecGhost,
+				AlternativeMethod:           analyzer.ExecPtOSC,
+				ExecutionCommand:            "gh-ost --execute\n",
+				AlternativeExecutionCommand: "pt-online-schema-change --execute",
+			},
+			wantOut: "gh-ost --execute\n",
+			wantAlt: true,
+		},
+		{
+			name:    "chunked prints the script",
+			result:  &analyzer.Result{Method: analyzer.ExecChunked, GeneratedScript: "DELETE FROM t LIMIT 1000;\n"},
+			wantOut: "DELETE FROM t LIMIT 1000;\n",
+		},
+		{
+			name:    "no command",
+			result:  &analyzer.Result{Method: analyzer.ExecPtOSC},
+			wantErr: "no PT-ONLINE-SCHEMA-CHANGE command could be generated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			err := writeCommands(&out, &errOut, tt.result)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("writeCommands() error = %v, want %q", err, tt.wantErr)
+				}
+				if out.Len() != 0 {
+					t.Errorf("stdout should be empty on error, got %q", out.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("writeCommands() error = %v", err)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", out.String(), tt.wantOut)
+			}
+			if got := strings.Contains(errOut.String(), "pt-online-schema-change --execute"); got != tt.wantAlt {
+				t.Errorf("alternative on stderr = %v, want %v (stderr %q)", got, tt.wantAlt, errOut.String())
+			}
+		})
+	}
+}
+
+func TestDangerousCommandsError(t *testing.T) {
+	safe := &analyzer.Result{Risk: analyzer.RiskSafe, Statement: "ALTER TABLE t ADD COLUMN c INT"}
+	ghost := &analyzer.Result{
+		Risk:             analyzer.RiskDangerous,
+		Method:           analyzer.ExecGhost,
+		Statement:        "ALTER TABLE t MODIFY COLUMN c BIGINT",
+		ExecutionCommand: "gh-ost --execute",
+		Warnings:         []analyzer.Warning{{Severity: analyzer.SeverityWarning, Message: "large table"}},
+	}
+	dangerous := &analyzer.Result{
+		Risk:      analyzer.RiskDangerous,
+		Method:    analyzer.ExecDirect,
+		Statement: "ALTER TABLE t DROP COLUMN c",
+		Warnings: []analyzer.Warning{
+			{Severity: analyzer.SeverityCritical, Message: "column c is referenced by a view"},
+			{Severity: analyzer.SeverityWarning, Message: "minor note"},
+		},
+	}
+
+	var out, errOut bytes.Buffer
+	if err := dangerousCommandsError(&errOut, safe); err != nil || errOut.Len() != 0 {
+		t.Errorf("safe statement: error = %v, stderr %q, want neither", err, errOut.String())
+	}
+
+	// gh-ost is the mitigation for a DANGEROUS plan: its command is printed.
+	if err := dangerousCommandsError(&errOut, ghost); err != nil || errOut.Len() != 0 {
+		t.Errorf("DANGEROUS gh-ost plan: error = %v, stderr %q, want neither", err, errOut.String())
+	}
+	if err := writeCommands(&out, &errOut, ghost); err != nil || out.String() != "gh-ost --execute\n" {
+		t.Errorf("DANGEROUS gh-ost plan: stdout %q, error %v, want its command", out.String(), err)
+	}
+
+	blocked := *ghost
+	blocked.Warnings = append(blocked.Warnings, analyzer.Warning{Severity: analyzer.SeverityCritical, Message: "not enough disk"})
+	if err := dangerousCommandsError(&errOut, &blocked); err == nil {
+		t.Error("gh-ost plan with a critical warning should be refused")
+	}
+	errOut.Reset()
+
+	err := dangerousCommandsError(&errOut, safe, ghost, dangerous)
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 statements are DANGEROUS") {
+		t.Fatalf("error = %v, want 1 of 3 DANGEROUS", err)
+	}
+	note := errOut.String()
+	if !strings.Contains(note, "# DANGEROUS: ALTER TABLE t DROP COLUMN c") || !strings.Contains(note, "column c is referenced by a view") {
+		t.Errorf("stderr should name the statement and its critical warnings, got %q", note)
+	}
+	if strings.Contains(note, "minor note") || strings.Contains(note, "ADD COLUMN") || strings.Contains(note, "MODIFY COLUMN") {
+		t.Errorf("stderr should only cover the DANGEROUS statement's critical warnings, got %q", note)
+	}
+}
+
+func TestRollbackOutput(t *testing.T) {
+	for _, name := range []string{"U2__add_email.sql", "U1.2_3__x.sql"} {
+		if !flywayUndoName.MatchString(name) {
+			t.Errorf("%s: want a Flyway undo migration name", name)
+		}
+	}
+	for _, name := range []string{"V2__add_email.sql", "U2_add_email.sql", "undo.sql"} {
+		if flywayUndoName.MatchString(name) {
+			t.Errorf("%s: not a Flyway undo migration name", name)
+		}
+	}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("rollback-file", "", "")
+	cmd.Flags().String("rollback-format", "flyway", "")
+	if _, _, err := rollbackOutput(cmd); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := cmd.Flags().Set("rollback-format", "liquibase"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := rollbackOutput(cmd); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestShardTargets(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("connections.shard1.host", "db1.example.com")
+	viper.Set("connections.shard1.database", "app_1")
+	viper.Set("connections.shard2.host", "db2.example.com")
+
+	ddl, err := parser.Parse("ALTER TABLE users ADD COLUMN email VARCHAR(255)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shardsCmd := func(args ...string) *cobra.Command {
+		c := &cobra.Command{}
+		c.Flags().StringSlice("shards", nil, "")
+		c.Flags().Bool("commands-only", false, "")
+		c.Flags().Bool("confirm", false, "")
+		c.Flags().String("report", "", "")
+		c.Flags().String("rollback-file", "", "")
+		if err := c.Flags().Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	if targets, err := shardTargets(shardsCmd(), ddl, nil); err != nil || targets != nil {
+		t.Errorf("without --shards: %v, %v; want nil", targets, err)
+	}
+
+	targets, err := shardTargets(shardsCmd("--shards", "shard1, shard2"), ddl, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 || targets[0].name != "shard1" || targets[0].cfg.Host != "db1.example.com" ||
+		targets[0].cfg.Database != "app_1" || targets[1].cfg.Port != 3306 || targets[1].cfg.User != "dbsafe" {
+		t.Errorf("targets = %+v", targets)
+	}
+
+	dml, err := parser.Parse("DELETE FROM users WHERE id < 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := &mysql.ServerVersion{Major: 8, Minor: 0, Patch: 36}
+	for name, tc := range map[string]struct {
+		cmd     *cobra.Command
+		parsed  *parser.ParsedSQL
+		version *mysql.ServerVersion
+		want    string
+	}{
+		"unknown shard":  {shardsCmd("--shards", "shard1,shard9"), ddl, nil, "no connections.shard9"},
+		"bad name":       {shardsCmd("--shards", "a.b"), ddl, nil, "not a connection name"},
+		"listed twice":   {shardsCmd("--shards", "shard1,shard1"), ddl, nil, "listed twice"},
+		"offline":        {shardsCmd("--shards", "shard1"), ddl, version, "--assume-version"},
+		"dml":            {shardsCmd("--shards", "shard1"), dml, nil, "analyzes DDL"},
+		"single results": {shardsCmd("--shards", "shard1", "--commands-only"), ddl, nil, "--commands-only"},
+	} {
+		if _, err := shardTargets(tc.cmd, tc.parsed, tc.version); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want one mentioning %q", name, err, tc.want)
+		}
+	}
+}
+
+func TestMinWarningSeverity(t *testing.T) {
+	for value, want := range map[string]analyzer.Severity{
+		"informational": analyzer.SeverityInfo,
+		"caution":       analyzer.SeverityWarning,
+		"Dangerous":     analyzer.SeverityCritical,
+		"critical":      analyzer.SeverityCritical,
+	} {
+		c := &cobra.Command{}
+		addMinWarningSeverityFlag(c)
+		if err := c.Flags().Set("min-warning-severity", value); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := minWarningSeverity(c); err != nil || got != want {
+			t.Errorf("%s: got %s, %v; want %s", value, got, err, want)
+		}
+	}
+
+	c := &cobra.Command{}
+	addMinWarningSeverityFlag(c)
+	if got, err := minWarningSeverity(c); err != nil || got != analyzer.SeverityInfo {
+		t.Errorf("default: got %s, %v; want every warning", got, err)
+	}
+	_ = c.Flags().Set("min-warning-severity", "loud")
+	if _, err := minWarningSeverity(c); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
+
+// A --file with several statements is planned as a batch, statement by statement.
+func TestPlanCmd_MultiStatementFile(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("format", "plain")
+
+	dir := t.TempDir()
+	sqlFile := filepath.Join(dir, "migration.sql")
+	schemaFile := filepath.Join(dir, "schema.sql")
+	auditLog := filepath.Join(dir, "audit.log")
+	migration := "ALTER TABLE shop.orders ADD INDEX idx_total (total);\n" +
+		"-- and the second one\n" +
+		"ALTER TABLE shop.orders ADD INDEX idx_created (created_at);\n"
+	if err := os.WriteFile(sqlFile, []byte(migration), 0600); err != nil {
+		t.Fatal(err)
+	}
+	schema := "CREATE TABLE shop.orders (id INT PRIMARY KEY, total DECIMAL(10,2), created_at DATETIME);"
+	if err := os.WriteFile(schemaFile, []byte(schema), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for flag, value := range map[string]string{"file": sqlFile, "assume-version": "8.0.35", "schema-file": schemaFile, "audit-log": auditLog} {
+		if err := planCmd.Flags().Set(flag, value); err != nil {
+			t.Fatal(err)
+		}
+		defer planCmd.Flags().Set(flag, "")
+	}
+	var out bytes.Buffer
+	planCmd.SetOut(&out)
+	defer planCmd.SetOut(nil)
+	planCmd.SetContext(context.Background())
+
+	if err := planCmd.RunE(planCmd, nil); err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+
+	for _, want := range []string{
+		"Statements:    2",
+		"1. ALTER TABLE shop.orders ADD INDEX idx_total (total)",
+		"2. ALTER TABLE shop.orders ADD INDEX idx_created (created_at)",
+		"### Statement 2 of 2 ###",
+		"Statements 1, 2 each alter `shop`.`orders`",
+		"ALTER TABLE `shop`.`orders` ADD INDEX idx_total (total), ADD INDEX idx_created (created_at);",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	data, err := os.ReadFile(auditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("audit log has %d lines, want one per statement:\n%s", lines, data)
+	}
+}
@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nethalo/dbsafe/internal/analyzer"
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/output"
+	"github.com/nethalo/dbsafe/internal/parser"
+	"github.com/nethalo/dbsafe/internal/topology"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:          "serve",
+	Short:        "Serve the analysis over HTTP",
+	SilenceUsage: true, // Don't show usage on errors
+	Long: `Run dbsafe as an HTTP service, for deploy tooling that calls it instead of
+running the binary.
+
+  POST /analyze  analyze a statement; returns the JSON of plan --format json
+  GET  /healthz  liveness check
+
+The body of /analyze is a JSON object:
+
+  {"statement": "ALTER TABLE shop.users ADD COLUMN age INT",
+   "database": "shop",               optional default database
+   "target": "replica1",             connections.replica1 in the config file;
+                                     omitted: the default connection
+   "version": "8.0.36",              analyze offline for this version instead
+   "schema": "CREATE TABLE ...",     offline: the table's CREATE TABLE
+   "topology": "galera"}             offline: the assumed topology
+
+A live target is only ever one of the config file's connections, so callers
+never send credentials. Each request is logged as a JSON line on stderr.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listen, _ := cmd.Flags().GetString("listen")
+		noOnlineTools, _ := cmd.Flags().GetBool("no-online-tools")
+		auditLog, _ := cmd.Flags().GetString("audit-log")
+		chunkSize, _ := cmd.Flags().GetInt("chunk-size")
+		dangerousSize, cautionSize, err := sizeThresholds(cmd)
+		if err != nil {
+			return err
+		}
+		copyRate, err := copyThroughput(cmd)
+		if err != nil {
+			return err
+		}
+
+		logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+		s := newAnalysisServer(logger, analyzer.Options{
+			ChunkSize:              chunkSize,
+			NoOnlineTools:          noOnlineTools,
+			ExplainRows:            true,
+			DangerousSizeThreshold: dangerousSize,
+			CautionSizeThreshold:   cautionSize,
+			CopyBytesPerSec:        copyRate,
+		})
+		s.auditLog = auditLog
+		defer s.Close()
+
+		srv := &http.Server{Addr: listen, Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		errc := make(chan error, 1)
+		go func() { errc <- srv.ListenAndServe() }()
+		logger.Info("listening", "addr", listen)
+		select {
+		case err := <-errc:
+			return err
+		case <-ctx.Done():
+		}
+		logger.Info("shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().String("listen", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().Int("chunk-size", analyzer.DefaultChunkSize, "Chunk size for DML recommendations")
+	serveCmd.Flags().Bool("no-online-tools", false, "Never recommend gh-ost or pt-osc: large blocking ALTERs run natively, with the write-blocking time for a maintenance window")
+	addSizeThresholdFlags(serveCmd)
+	addAuditLogFlag(serveCmd)
+}
+
+// analyzeRequest is the body of POST /analyze.
+type analyzeRequest struct {
+	Statement string `json:"statement"`
+	Database  string `json:"database,omitempty"`
+	Target    string `json:"target,omitempty"`   // a connection of the config file; "" or "default" is the default one
+	Version   string `json:"version,omitempty"`  // assumed server version: analyze offline
+	Schema    string `json:"schema,omitempty"`   // offline: CREATE TABLE statements to analyze against
+	Topology  string `json:"topology,omitempty"` // offline: assumed topology
+}
+
+// offlineTopologies are the topologies an offline request can assume.
+var offlineTopologies = []topology.Type{
+	topology.Standalone, topology.AsyncReplica, topology.SemiSyncReplica, topology.Galera,
+	topology.GroupRepl, topology.AuroraWriter, topology.AuroraReader, topology.Proxied,
+}
+
+// liveTarget is an open connection to one of the config file's connections.
+type liveTarget struct {
+	db       *sql.DB
+	conn     *analyzer.ConnectionInfo
+	password string
+}
+
+// analysisServer serves POST /analyze and GET /healthz. Connections to targets are opened
+// on first use and kept for later requests.
+type analysisServer struct {
+	log      *slog.Logger
+	opts     analyzer.Options // for every request; the request sets the database, version and metadata
+	auditLog string           // --audit-log, "" when unset
+
+	mu      sync.Mutex
+	targets map[string]*liveTarget
+	// connect opens a target's connection; mysql.Connect, replaced in tests.
+	connect func(mysql.ConnectionConfig) (*sql.DB, error)
+}
+
+func newAnalysisServer(log *slog.Logger, opts analyzer.Options) *analysisServer {
+	return &analysisServer{log: log, opts: opts, targets: map[string]*liveTarget{}, connect: mysql.Connect}
+}
+
+// Handler routes the API, logging each request.
+func (s *analysisServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("POST /analyze", s.analyze)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			// A failed analysis answers with a generic JSON error, the panic going only to
+			// the log, instead of net/http dropping the connection.
+			if p := recover(); p != nil {
+				s.log.Error("analysis failed", "path", r.URL.Path, "panic", fmt.Sprint(p))
+				writeError(rec, http.StatusInternalServerError, errors.New("internal error"))
+			}
+			s.log.Info("request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rec.status,
+				"duration_ms", time.Since(start).Milliseconds(),
+				"remote", r.RemoteAddr,
+			)
+		}()
+		mux.ServeHTTP(rec, r)
+	})
+}
+
+// Close closes the targets' connections.
+func (s *analysisServer) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.targets {
+		t.db.Close()
+	}
+	s.targets = map[string]*liveTarget{}
+}
+
+func (s *analysisServer) analyze(w http.ResponseWriter, r *http.Request) {
+	var req analyzeRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("request body: %w", err))
+		return
+	}
+	if req.Statement == "" {
+		writeError(w, http.StatusBadRequest, errors.New("statement is required"))
+		return
+	}
+	parsed, err := parser.Parse(req.Statement)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("SQL parse error: %w", err))
+		return
+	}
+	if name, ok := analyzer.UnsupportedOperation(parsed); ok {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Errorf("%w: %s", analyzer.ErrUnsupportedStatement, name))
+		return
+	}
+
+	opts := s.opts
+	opts.Database = req.Database
+	var result *analyzer.Result
+	var target *liveTarget
+	if req.Version != "" {
+		if req.Target != "" {
+			writeError(w, http.StatusBadRequest, errors.New("target and version are exclusive: a version is analyzed offline"))
+			return
+		}
+		if opts.Version, opts.Metadata, opts.Topology, err = offlineOptions(req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		result, err = analyzer.AnalyzeOffline(parsed, nil, opts)
+	} else {
+		if req.Schema != "" || req.Topology != "" {
+			writeError(w, http.StatusBadRequest, errors.New("schema and topology are only used offline: add version"))
+			return
+		}
+		if target, err = s.target(req.Target); err != nil {
+			status := http.StatusBadGateway
+			if errors.Is(err, errUnknownTarget) {
+				status = http.StatusBadRequest
+			}
+			writeError(w, status, err)
+			return
+		}
+		opts.Connection = target.conn
+		if opts.Database == "" {
+			opts.Database = target.conn.Database
+		}
+		result, err = analyzer.AnalyzeParsed(r.Context(), target.db, parsed, opts)
+	}
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if s.auditLog != "" {
+		var conn *analyzer.ConnectionInfo
+		var password string
+		if target != nil {
+			conn, password = target.conn, target.password
+		}
+		s.mu.Lock() // one append at a time, so each line chains to the one before it
+		err := output.AppendAudit(s.auditLog, output.NewAuditEntry(result, conn, password))
+		s.mu.Unlock()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Errorf("audit log: %w", err))
+			return
+		}
+	}
+
+	s.log.Info("analysis", "target", req.Target, "offline", req.Version != "",
+		"database", result.Database, "table", result.Table, "risk", result.Risk, "method", result.Method)
+	w.Header().Set("Content-Type", "application/json")
+	output.NewRenderer("json", w).RenderPlan(result)
+}
+
+// offlineOptions returns the assumed version, schema and topology of an offline request.
+func offlineOptions(req analyzeRequest) (*mysql.ServerVersion, mysql.MetadataSource, topology.Type, error) {
+	v, err := mysql.ParseAssumedVersion(req.Version)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("version: %w", err)
+	}
+	var schema mysql.MetadataSource
+	if req.Schema != "" {
+		dump, err := analyzer.NewSchemaDump(req.Schema)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("schema: %w", err)
+		}
+		schema = dump
+	}
+	topo := topology.Type(req.Topology)
+	if topo != "" && !slices.Contains(offlineTopologies, topo) {
+		return nil, nil, "", fmt.Errorf("topology %q: use one of %v", req.Topology, offlineTopologies)
+	}
+	return &v, schema, topo, nil
+}
+
+// target returns the connection to the named target, opening it on first use. The
+// connection is opened without holding s.mu, so a slow or unreachable target doesn't hold
+// up requests for the others; of two requests opening the same target, the second one to
+// connect closes its connection and uses the first.
+func (s *analysisServer) target(name string) (*liveTarget, error) {
+	if name == "" {
+		name = "default"
+	}
+	if !targetName.MatchString(name) {
+		return nil, fmt.Errorf("%w %q: not a connection name", errUnknownTarget, name)
+	}
+	s.mu.Lock()
+	t, ok := s.targets[name]
+	s.mu.Unlock()
+	if ok {
+		return t, nil
+	}
+
+	cfg, passwordEnv, err := targetConfig(name)
+	if err != nil {
+		return nil, err
+	}
+	db, err := s.connect(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("target %s: connection failed: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.targets[name]; ok {
+		db.Close()
+		return t, nil
+	}
+	t = &liveTarget{db: db, conn: connectionInfo(cfg, passwordEnv), password: cfg.Password}
+	s.targets[name] = t
+	return t, nil
+}
+
+// statusRecorder keeps the status code a handler wrote, for the request log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nethalo/dbsafe/internal/analyzer"
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/mysql/mysqltest"
+	"github.com/spf13/viper"
+)
+
+func TestAnalysisServer(t *testing.T) {
+	var logs bytes.Buffer
+	s := newAnalysisServer(slog.New(slog.NewJSONHandler(&logs, nil)), analyzer.Options{})
+	s.connect = func(mysql.ConnectionConfig) (*sql.DB, error) { return nil, errors.New("connection refused") }
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	post := func(body string) (int, map[string]any) {
+		t.Helper()
+		resp, err := http.Post(srv.URL+"/analyze", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		var out map[string]any
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatalf("response isn't JSON: %v\n%s", err, data)
+		}
+		return resp.StatusCode, out
+	}
+
+	resp, err := http.Get(srv.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /healthz: status %d", resp.StatusCode)
+	}
+
+	status, out := post(`{"statement": "ALTER TABLE shop.users ADD COLUMN age INT", "version": "8.0.36",
+		"schema": "CREATE TABLE shop.users (id INT NOT NULL PRIMARY KEY) ENGINE=InnoDB", "topology": "galera"}`)
+	if status != http.StatusOK || out["risk"] == nil || out["table"] != "users" {
+		t.Errorf("offline analysis: status %d, %v", status, out)
+	}
+	if topo, _ := out["topology"].(map[string]any); topo["type"] != "galera" {
+		t.Errorf("expected the assumed topology, got %v", out["topology"])
+	}
+
+	for body, want := range map[string]int{
+		`not json`:                             http.StatusBadRequest,
+		`{"statement": ""}`:                    http.StatusBadRequest,
+		`{"statement": "ALTER TABLE"}`:         http.StatusBadRequest,
+		`{"statement": "SELECT 1", "oops": 1}`: http.StatusBadRequest,
+		`{"statement": "ALTER TABLE t ADD COLUMN c INT", "schema": "CREATE TABLE t (id INT)"}`:     http.StatusBadRequest,
+		`{"statement": "ALTER TABLE t ADD COLUMN c INT", "version": "8.0.36", "topology": "ring"}`: http.StatusBadRequest,
+		`{"statement": "INSERT INTO t VALUES (1)", "version": "8.0.36"}`:                           http.StatusUnprocessableEntity,
+		`{"statement": "ALTER TABLE t ADD COLUMN c INT", "target": "nowhere"}`:                     http.StatusBadRequest,
+		`{"statement": "ALTER TABLE t ADD COLUMN c INT", "target": "a.b"}`:                         http.StatusBadRequest,
+		`{"statement": "ALTER TABLE t ADD COLUMN c INT"}`:                                          http.StatusBadGateway,
+	} {
+		if status, out := post(body); status != want || out["error"] == nil {
+			t.Errorf("%s: got %d %v, want %d with an error", body, status, out, want)
+		}
+	}
+
+	if !strings.Contains(logs.String(), `"path":"/analyze","status":200`) || !strings.Contains(logs.String(), `"risk":`) {
+		t.Errorf("expected JSON request logs, got:\n%s", logs.String())
+	}
+}
+
+func TestAnalysisServer_Panic(t *testing.T) {
+	var logs bytes.Buffer
+	s := newAnalysisServer(slog.New(slog.NewJSONHandler(&logs, nil)), analyzer.Options{})
+	s.connect = func(mysql.ConnectionConfig) (*sql.DB, error) { panic("dsn user:s3cret@tcp(db)") }
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/analyze", "application/json", strings.NewReader(`{"statement": "ALTER TABLE t ADD COLUMN c INT"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusInternalServerError || strings.Contains(string(body), "s3cret") {
+		t.Errorf("got %d %s, want a generic 500", resp.StatusCode, body)
+	}
+	if !strings.Contains(logs.String(), `"msg":"analysis failed"`) || !strings.Contains(logs.String(), `"path":"/analyze","status":500`) {
+		t.Errorf("expected the panic and the failed request in the log, got:\n%s", logs.String())
+	}
+}
+
+func TestAnalysisServer_LiveDML(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("connections.primary.host", "db.example.com")
+	viper.Set("connections.primary.database", "shop")
+
+	var logs bytes.Buffer
+	// No ChunkSize: the analyzer's default applies.
+	s := newAnalysisServer(slog.New(slog.NewJSONHandler(&logs, nil)), analyzer.Options{})
+	s.connect = func(mysql.ConnectionConfig) (*sql.DB, error) { return mysqltest.FakeServer(t, 500000), nil }
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/analyze", "application/json",
+		strings.NewReader(`{"statement": "UPDATE events SET archived = 1", "target": "primary"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var out map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("response isn't JSON: %v", err)
+	}
+	op, _ := out["operation"].(map[string]any)
+	if resp.StatusCode != http.StatusOK || out["recommended_method"] != string(analyzer.ExecChunked) || op["chunk_size"] != float64(analyzer.DefaultChunkSize) {
+		t.Errorf("got %d %v, want a chunked plan\n%s", resp.StatusCode, out, logs.String())
+	}
+}
+
+func TestAnalysisServer_SlowTarget(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("connections.slow.host", "slow.example.com")
+	viper.Set("connections.fast.host", "fast.example.com")
+
+	s := newAnalysisServer(slog.New(slog.NewJSONHandler(io.Discard, nil)), analyzer.Options{})
+	connecting, release := make(chan struct{}), make(chan struct{})
+	s.connect = func(cfg mysql.ConnectionConfig) (*sql.DB, error) {
+		if cfg.Host == "slow.example.com" {
+			close(connecting)
+			<-release
+		}
+		return sql.Open("mysql", "dbsafe@tcp(127.0.0.1:1)/")
+	}
+	defer s.Close()
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := s.target("slow")
+		slow <- err
+	}()
+	<-connecting
+
+	fast := make(chan error, 1)
+	go func() {
+		_, err := s.target("fast")
+		fast <- err
+	}()
+	select {
+	case err := <-fast:
+		if err != nil {
+			t.Errorf("fast target: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("a target waited for another one to connect")
+	}
+	close(release)
+	if err := <-slow; err != nil {
+		t.Errorf("slow target: %v", err)
+	}
+}
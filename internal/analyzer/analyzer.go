@@ -2,6 +2,9 @@ package analyzer
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -43,11 +46,22 @@ const ptOSCForeignKeyRationale = "gh-ost cannot operate on tables that have fore
 	"cutover, which breaks FK relationships. " +
 	"pt-online-schema-change supports --alter-foreign-keys-method to safely handle FK tables."
 
+// ptOSCPartitioningRationale explains why pt-osc is used for partitioning scheme changes.
+const ptOSCPartitioningRationale = "gh-ost doesn't handle partitioning scheme changes well: its ghost table is " +
+	"created with the new layout before the row copy and binlog replay, and REMOVE PARTITIONING or PARTITION BY " +
+	"in --alter is not a supported use. pt-online-schema-change creates the new table with the requested " +
+	"partitioning and copies into it with triggers keeping it in sync."
+
 // auroraGhostRationale explains why gh-ost cannot be used on Aurora MySQL.
 const auroraGhostRationale = "gh-ost is NOT compatible with Aurora MySQL: Aurora uses storage-layer " +
 	"replication instead of MySQL binary log replication. gh-ost relies on reading the binary log stream " +
 	"which is not accessible on Aurora. Use pt-online-schema-change instead."
 
+// noOnlineToolsRationale explains why a large blocking ALTER runs natively.
+const noOnlineToolsRationale = "Online schema change tools are disabled: gh-ost and pt-online-schema-change " +
+	"can't be installed or used in this environment, so the ALTER runs natively and blocks writes for the " +
+	"whole rebuild. Run it in a maintenance window."
+
 // ExecutionMethod is what dbsafe recommends.
 type ExecutionMethod string
 
@@ -66,6 +80,17 @@ type ConnectionInfo struct {
 	User     string
 	Socket   string
 	Database string
+
+	// PasswordEnv, when set, names the environment variable generated commands read the
+	// password from (e.g. MYSQL_PWD). The password itself is never written into a command.
+	PasswordEnv string
+
+	// TLS means the connection is encrypted, so generated gh-ost and pt-osc commands connect
+	// over TLS too. TLSCA is the CA certificate the server is verified with, when one was
+	// given; TLSSkipVerify means the server's certificate isn't verified.
+	TLS           bool
+	TLSCA         string
+	TLSSkipVerify bool
 }
 
 // Input holds everything the analyzer needs.
@@ -74,14 +99,133 @@ type Input struct {
 	Meta          *mysql.TableMetadata
 	Topo          *topology.Info
 	Version       mysql.ServerVersion
-	ChunkSize     int
+	ChunkSize     int             // DML chunk size; 0 uses DefaultChunkSize
 	Connection    *ConnectionInfo // Optional: for generating executable commands
-	EstimatedRows int64           // EXPLAIN-based row estimate for DML
-
-	// ForeignKeyChecksDisabled reflects the server's foreign_key_checks variable at analysis
+	EstimatedRows int64           // EXPLAIN-based row estimate for DML (optimizer-derived, approximate)
+	SafePtOSC     bool            // Emit pt-osc as a --dry-run followed by --execute --no-drop-old-table
+	WithHooks     bool            // Add --hooks-path (gh-ost) or --plugin (pt-osc) and a commented hook scaffold
+	NoOnlineTools bool            // gh-ost and pt-osc can't be used: large rebuilds run natively in a maintenance window
+	SleepSeconds  *float64        // Pause between chunks in generated scripts; nil uses defaultChunkSleep, 0 doesn't pause
+	MaxReplicaLag int             // When > 0, generate a shell script that backs off while replica lag exceeds this (seconds)
+
+	// MaxConnections and ThreadsRunning are the server's max_connections and a sample of
+	// Threads_running, used to scale the pt-osc load thresholds. Zero means unknown.
+	MaxConnections int64
+	ThreadsRunning int64
+
+	// TxIsolation is the server's transaction_isolation (e.g. REPEATABLE-READ). Empty means
+	// unknown, which is treated as InnoDB's default REPEATABLE READ.
+	TxIsolation string
+
+	// ServerConfig holds the server variables that bound online ALTERs (online log size,
+	// temporary directory, redo log capacity). Zero values mean unknown.
+	ServerConfig mysql.ServerConfig
+
+	// FreeDiskBytes is the free space on the filesystem of the server's data directory,
+	// checked against the disk estimate. Zero means unknown.
+	FreeDiskBytes int64
+
+	// BinlogFreeBytes is the free space on the filesystem of the server's binary logs,
+	// checked against the binlog volume estimate. Zero means unknown.
+	BinlogFreeBytes int64
+
+	// TrafficProfile, when set, is used to recommend a start time that fits the estimated
+	// duration into the quietest hours (Result.Schedule).
+	TrafficProfile *TrafficProfile
+
+	// Trace records each classification decision in Result.Trace.
+	Trace bool
+
+	// PtOSCChunking overrides the chunking flags of generated pt-osc commands.
+	PtOSCChunking PtOSCChunking
+
+	// GhostReplication sets how generated gh-ost commands find the primary.
+	GhostReplication GhostReplication
+
+	// PtOSCRecursionMethod is the --recursion-method of generated pt-osc commands:
+	// processlist, hosts, dsn[=DSN] or none. Empty picks one from the topology.
+	PtOSCRecursionMethod string
+
+	// DangerousSizeThreshold is the table size in bytes above which COPY and locking
+	// INPLACE operations are DANGEROUS and go through an online schema change tool;
+	// CautionSizeThreshold the size above which non-locking INPLACE operations are CAUTION.
+	// Zero uses the defaults, 1 GB and 10 GB.
+	DangerousSizeThreshold int64
+	CautionSizeThreshold   int64
+
+	// CopyBytesPerSec is the assumed speed of a native table copy or index build, for the
+	// duration estimates; zero uses 25 MB/s.
+	CopyBytesPerSec int64
+
+	// MetadataLockHolders are the other sessions holding or waiting for a metadata lock on the
+	// table. LongTransactions is the fallback when metadata locks couldn't be inspected. Both
+	// are only collected when analyzing against a live connection.
+	MetadataLockHolders []mysql.MetadataLockHolder
+	LongTransactions    []mysql.TransactionInfo
+
+	// ForeignKeyChecksDisabled reflects the session's foreign_key_checks variable at analysis
 	// time. Zero value (false) means checks are ON — the safe default that requires COPY for
 	// ADD FOREIGN KEY. Set to true only when the server reports foreign_key_checks=OFF.
+	// UniqueChecksDisabled likewise reflects the session's unique_checks.
 	ForeignKeyChecksDisabled bool
+	UniqueChecksDisabled     bool
+
+	// CheckSettings is the session and global foreign_key_checks and unique_checks, read
+	// from a live server; nil offline or when they couldn't be read.
+	CheckSettings *mysql.CheckSettings
+
+	// MetadataUnknown means Meta is a placeholder: the analysis runs offline without the
+	// table's definition, so checks against its columns are skipped.
+	MetadataUnknown bool
+
+	// Observer, when set, is given the result and its attributes when the analysis completes.
+	Observer AnalysisObserver
+
+	// offline and explainErr are what BatchInputs knows about the input beyond it:
+	// AnalyzeBatch reports them as AnalyzeOffline and AnalyzeParsed do.
+	offline    bool
+	explainErr error
+}
+
+// Default table-size boundaries of the DDL risk bands.
+const (
+	defaultDangerousSizeThreshold int64 = 1 * 1024 * 1024 * 1024  // 1 GB
+	defaultCautionSizeThreshold   int64 = 10 * 1024 * 1024 * 1024 // 10 GB
+)
+
+// DefaultChunkSize is the DML chunk size when Input.ChunkSize is not set.
+const DefaultChunkSize = 10000
+
+// chunkSize returns the rows per chunk of a chunked DML.
+func (input Input) chunkSize() int {
+	if input.ChunkSize > 0 {
+		return input.ChunkSize
+	}
+	return DefaultChunkSize
+}
+
+// dangerousSize returns the table size above which COPY and locking INPLACE are DANGEROUS.
+func (input Input) dangerousSize() int64 {
+	if input.DangerousSizeThreshold > 0 {
+		return input.DangerousSizeThreshold
+	}
+	return defaultDangerousSizeThreshold
+}
+
+// cautionSize returns the table size above which non-locking INPLACE is CAUTION.
+func (input Input) cautionSize() int64 {
+	if input.CautionSizeThreshold > 0 {
+		return input.CautionSizeThreshold
+	}
+	return defaultCautionSizeThreshold
+}
+
+// copyRate returns the assumed native copy speed in bytes per second.
+func (input Input) copyRate() int64 {
+	if input.CopyBytesPerSec > 0 {
+		return input.CopyBytesPerSec
+	}
+	return rebuildBytesPerSec
 }
 
 // SubOpResult holds the per-sub-operation classification for a multi-op ALTER TABLE.
@@ -106,13 +250,16 @@ type Result struct {
 	DDLOp          parser.DDLOperation
 	Classification DDLClassification
 	SubOpResults   []SubOpResult // per-sub-op classification breakdown (multi-op only)
+	ColumnsBefore  int           // multi-op only: column count before the ALTER
+	ColumnsAfter   int           // multi-op only: net column count after the ALTER
 
 	// DML-specific
 	DMLOp        parser.DMLOperation
 	AffectedRows int64
 	AffectedPct  float64
 	HasWhere     bool
-	WriteSetSize int64 // estimated bytes for write-set
+	WriteSetSize int64 // estimated bytes for write-set, cascaded child rows included
+	CascadeRows  int64 // estimated rows changed in child tables by ON DELETE/UPDATE CASCADE or SET NULL
 
 	// Recommendation
 	Risk                        RiskLevel
@@ -122,9 +269,11 @@ type Result struct {
 	ExecutionCommand            string // Generated command for primary method
 	AlternativeExecutionCommand string // Generated command for alternative method
 	MethodRationale             string // Explains why primary is preferred (or why alternative is excluded)
-	Warnings                    []string
-	ClusterWarnings             []string
+	Warnings                    []Warning
+	ClusterWarnings             []Warning
 	DiskEstimate                *DiskSpaceEstimate
+	EstimatedBinlogBytes        int64           // bytes written to the binary log; 0 when only the statement is logged
+	Schedule                    *ScheduleWindow // recommended start time; only with a traffic profile
 
 	// Rollback
 	RollbackSQL     string
@@ -140,9 +289,20 @@ type Result struct {
 	// Idempotent stored procedure (when --idempotent is set)
 	IdempotentSP string
 
+	// ProjectedSchema is the table's CREATE TABLE before and after the ALTER (when
+	// Options.ProjectSchema is set and the table's definition is known).
+	ProjectedSchema *ProjectedSchema
+
 	// OptimizedDDL is the original ALTER TABLE with explicit ALGORITHM and LOCK hints appended,
 	// ready to copy-paste. Only set for ALTER TABLE with INSTANT or INPLACE algorithm.
 	OptimizedDDL string
+
+	// Trace is the sequence of classification decisions; only with Input.Trace.
+	Trace   []TraceStep
+	tracing bool
+
+	copyBytesPerSec int64 // Input.copyRate(), for EstimateDuration
+	dangerousSize   int64 // Input.dangerousSize(), for AggregateShards
 }
 
 // RollbackOption describes one way to undo the operation.
@@ -159,8 +319,36 @@ type DiskSpaceEstimate struct {
 	Reason        string
 }
 
-// Analyze runs the full analysis pipeline.
+// Command returns the command that carries out the plan: the optimized DDL (or the
+// statement itself) for DIRECT, the chunked script for CHUNKED, and the gh-ost or pt-osc
+// command otherwise. Returns "" when none could be generated, and for account-management
+// statements, which dbsafe doesn't plan (and whose passwords are redacted).
+func (r *Result) Command() string {
+	if r.DDLOp == parser.AccountManagement {
+		return ""
+	}
+	switch r.Method {
+	case ExecDirect:
+		if r.OptimizedDDL != "" {
+			return r.OptimizedDDL
+		}
+		return strings.TrimRight(strings.TrimSpace(r.Statement), ";") + ";"
+	case ExecChunked:
+		return r.GeneratedScript
+	}
+	return r.ExecutionCommand
+}
+
+// Analyze runs the full analysis pipeline, then notifies input.Observer of the result.
 func Analyze(input Input) *Result {
+	result := analyze(input)
+	observe(input.Observer, result)
+	return result
+}
+
+// analyze is Analyze without the observer, for the callers that add to the result
+// afterwards and notify the observer of the final result themselves.
+func analyze(input Input) *Result {
 	result := &Result{
 		Statement:     input.Parsed.RawSQL,
 		StatementType: input.Parsed.Type,
@@ -170,7 +358,11 @@ func Analyze(input Input) *Result {
 		Topology:      input.Topo,
 		Version:       input.Version,
 		AnalyzedAt:    time.Now(),
-		ChunkSize:     input.ChunkSize,
+		ChunkSize:     input.chunkSize(),
+		tracing:       input.Trace,
+
+		copyBytesPerSec: input.copyRate(),
+		dangerousSize:   input.dangerousSize(),
 	}
 
 	if result.Database == "" {
@@ -180,46 +372,483 @@ func Analyze(input Input) *Result {
 	switch input.Parsed.Type {
 	case parser.DDL:
 		analyzeDDL(input, result)
+		applyVersionSupportWarning(input, result)
+		applyUpgradeBenefit(input, result)
+		result.traceOutcome("risk and method from the algorithm, lock and table size")
 	case parser.DML:
 		analyzeDML(input, result)
 	}
 
 	// Apply topology-specific warnings
 	applyTopologyWarnings(input, result)
+	applyCheckSettingsWarnings(input, result)
 
 	// Compute disk space estimate after method is finalized (topology may override ExecGhost → ExecPtOSC)
 	if result.StatementType == parser.DDL {
 		result.DiskEstimate = estimateDiskSpace(input, result)
+		applyDiskSpaceCheck(input, result)
+		applyServerConfigWarnings(input, result)
+		applySQLModeWarnings(input, result)
+		applyRedoUndoPressure(input, result)
+		applyMetadataLockWarnings(input, result)
+	}
+	applyBinlogVolume(input, result)
+
+	if result.StatementType == parser.DDL {
+		result.traceOutcome("final, after topology, disk space, server and metadata lock checks")
 	}
 
+	// Schedule last: the duration estimate depends on the final method and disk estimate.
+	result.Schedule = RecommendWindow(input.TrafficProfile, EstimateDuration(result))
 	return result
 }
 
+// applyCheckSettingsWarnings flags foreign_key_checks or unique_checks set differently in the
+// analyzing session than globally. The analysis follows the session, but the statement may
+// run elsewhere: in a new client session, or through gh-ost or pt-osc, which start with the
+// global values.
+func applyCheckSettingsWarnings(input Input, result *Result) {
+	c := input.CheckSettings
+	if c == nil {
+		return
+	}
+	onOff := func(on bool) string {
+		if on {
+			return "ON"
+		}
+		return "OFF"
+	}
+	var differ []string
+	if c.ForeignKeyChecks != c.GlobalForeignKeyChecks {
+		differ = append(differ, fmt.Sprintf("foreign_key_checks is %s in this session but %s globally", onOff(c.ForeignKeyChecks), onOff(c.GlobalForeignKeyChecks)))
+	}
+	if c.UniqueChecks != c.GlobalUniqueChecks {
+		differ = append(differ, fmt.Sprintf("unique_checks is %s in this session but %s globally", onOff(c.UniqueChecks), onOff(c.GlobalUniqueChecks)))
+	}
+	if len(differ) == 0 {
+		return
+	}
+	result.addWarning(WarnCheckSettingsDiffer, strings.Join(differ, "; ")+
+		". The analysis uses the session values; run the statement in a session with the same settings, "+
+		"since new connections (and gh-ost or pt-osc) start with the global values.")
+}
+
+// maxListedSessions caps how many blocking sessions a warning names.
+const maxListedSessions = 5
+
+// applyMetadataLockWarnings flags DDL on a table that other sessions hold a metadata lock on.
+// The DDL needs an exclusive metadata lock, so it waits for them; while it waits, every new
+// query on the table queues behind it, which stalls the table as surely as a table lock.
+// The wait is only expected to be long, and the plan DANGEROUS, when a session is already
+// waiting for the lock or holds it in a long-running transaction; holders that are just
+// running short statements are reported as CAUTION.
+func applyMetadataLockWarnings(input Input, result *Result) {
+	if len(input.MetadataLockHolders) > 0 {
+		blocking := false
+		for _, h := range input.MetadataLockHolders {
+			if strings.EqualFold(h.LockStatus, "PENDING") || h.TrxSeconds >= longTransactionSeconds {
+				blocking = true
+			}
+		}
+		var sessions []string
+		for i, h := range input.MetadataLockHolders {
+			if i == maxListedSessions {
+				sessions = append(sessions, fmt.Sprintf("and %d more", len(input.MetadataLockHolders)-i))
+				break
+			}
+			sessions = append(sessions, describeLockHolder(h))
+		}
+		msg := fmt.Sprintf("%d other session(s) hold or wait for a metadata lock on %s: %s. ",
+			len(input.MetadataLockHolders), input.Parsed.Table, strings.Join(sessions, "; "))
+		switch input.Parsed.LockWait {
+		case "NOWAIT":
+			// It fails instead of queuing, so nothing stalls behind it.
+			if result.Risk != RiskDangerous {
+				result.Risk = RiskCaution
+			}
+			msg += "With NOWAIT this statement fails at once with a lock wait timeout instead of queuing: run it again once they finish."
+		case "WAIT":
+			escalateLockRisk(result, blocking)
+			msg += fmt.Sprintf(
+				"With WAIT %d this statement blocks on the metadata lock for up to %d seconds, and every query on the table queues behind it meanwhile, before it fails. "+
+					"Wait for them to complete (or KILL them) before running.",
+				input.Parsed.LockWaitSeconds, input.Parsed.LockWaitSeconds,
+			)
+		default:
+			escalateLockRisk(result, blocking)
+			msg += "This statement will block on the metadata lock until they finish, and every query on the table queues behind it. " +
+				"Wait for them to complete (or KILL them) and " + lockWaitAdvice(input) + " before running."
+		}
+		if !blocking {
+			msg += fmt.Sprintf(" None of them waits for the lock or has a transaction open for %ds or more, so the wait should be short.", longTransactionSeconds)
+		}
+		warning := newWarning(WarnMetadataLockHeld, msg)
+		if !blocking {
+			warning.Severity = SeverityWarning
+		}
+		result.Warnings = append(result.Warnings, warning)
+		return
+	}
+
+	if len(input.LongTransactions) > 0 {
+		var sessions []string
+		for i, t := range input.LongTransactions {
+			if i == maxListedSessions {
+				sessions = append(sessions, fmt.Sprintf("and %d more", len(input.LongTransactions)-i))
+				break
+			}
+			sessions = append(sessions, fmt.Sprintf("process %d open for %ds", t.ProcessID, t.Seconds))
+		}
+		result.Risk = RiskDangerous
+		result.addWarning(WarnLongRunningTransactions, fmt.Sprintf(
+			"Metadata locks could not be inspected (performance_schema unavailable), and %d transaction(s) have been open for over %ds: %s. "+
+				"If any of them touched %s, this statement will block on its metadata lock and every query on the table queues behind it. "+
+				"Check them before running and %s.",
+			len(input.LongTransactions), longTransactionSeconds, strings.Join(sessions, "; "), input.Parsed.Table, lockWaitAdvice(input),
+		))
+	}
+}
+
+// escalateLockRisk raises the risk for a statement that will wait for a metadata lock:
+// DANGEROUS when the wait is expected to be long, at least CAUTION otherwise.
+func escalateLockRisk(result *Result, blocking bool) {
+	if blocking {
+		result.Risk = RiskDangerous
+	} else if result.Risk != RiskDangerous {
+		result.Risk = RiskCaution
+	}
+}
+
+// lockWaitAdvice says how to bound the statement's wait for a metadata lock: the WAIT n or
+// NOWAIT it already has, MariaDB's NOWAIT clause, or a short lock_wait_timeout on MySQL.
+func lockWaitAdvice(input Input) string {
+	switch {
+	case input.Parsed.LockWait == "NOWAIT":
+		return "note that NOWAIT makes it fail at once if a lock is held"
+	case input.Parsed.LockWait == "WAIT":
+		return fmt.Sprintf("note that WAIT %d bounds its wait to %d seconds", input.Parsed.LockWaitSeconds, input.Parsed.LockWaitSeconds)
+	case input.Version.Flavor == "mariadb" && strings.HasPrefix(strings.ToUpper(strings.TrimSpace(input.Parsed.RawSQL)), "ALTER TABLE"):
+		return fmt.Sprintf("add NOWAIT (ALTER TABLE %s NOWAIT ...) or WAIT n so it fails instead of stalling the table", input.Parsed.Table)
+	}
+	return "set a short lock_wait_timeout"
+}
+
+func describeLockHolder(h mysql.MetadataLockHolder) string {
+	verb := "holds"
+	if strings.EqualFold(h.LockStatus, "PENDING") {
+		verb = "waits for"
+	}
+	desc := fmt.Sprintf("process %d %s %s", h.ProcessID, verb, h.LockType)
+	if h.TrxSeconds > 0 {
+		desc += fmt.Sprintf(" in a transaction open for %ds", h.TrxSeconds)
+	}
+	if h.Query != "" {
+		query := h.Query
+		if len(query) > 80 {
+			query = query[:77] + "..."
+		}
+		desc += fmt.Sprintf(" (running: %s)", query)
+	}
+	return desc
+}
+
+// defaultOnlineAlterLogMaxSize is MySQL's default innodb_online_alter_log_max_size (128 MB),
+// assumed when the server value can't be read.
+const defaultOnlineAlterLogMaxSize = 128 * 1024 * 1024
+
+// applyServerConfigWarnings checks a large INPLACE ALTER run directly against the server
+// variables that can make it fail or fill a disk partway through: the online log that buffers
+// concurrent DML, and the temporary directory that holds the sort files of index builds.
+func applyServerConfigWarnings(input Input, result *Result) {
+	const largeTable = 1 * 1024 * 1024 * 1024 // 1 GB
+	c := result.Classification
+	if c.Algorithm != AlgoInplace || result.Method != ExecDirect || input.Meta.TotalSize() < largeTable ||
+		input.Parsed.DDLOp == parser.AnalyzeTable || input.Parsed.DDLOp == parser.CheckTable {
+		return
+	}
+
+	if c.Lock == LockNone {
+		logSize := input.ServerConfig.OnlineAlterLogMaxSize
+		configured := "currently " + humanBytes(logSize)
+		if logSize <= 0 {
+			logSize = defaultOnlineAlterLogMaxSize
+			configured = humanBytes(logSize) + " by default; the server value could not be read"
+		}
+		result.addWarning(WarnOnlineAlterLogLimit, fmt.Sprintf(
+			"INPLACE with concurrent DML: writes made to this %s table while the ALTER runs are buffered in an online log capped by "+
+				"innodb_online_alter_log_max_size (%s). On a busy table a slow ALTER can overflow it and fail with "+
+				"\"Creating index '...' required more than 'innodb_online_alter_log_max_size' bytes\" after doing most of the work. "+
+				"Consider raising it before running, e.g. SET GLOBAL innodb_online_alter_log_max_size = %d; -- %s",
+			humanBytes(input.Meta.TotalSize()), configured, 4*logSize, humanBytes(4*logSize),
+		))
+	}
+
+	// Sort files scale with the indexes being built; like estimateDiskSpace, the existing index
+	// size stands in for them and anything under 100 MB is not worth a warning.
+	const sortFileThreshold = 100 * 1024 * 1024
+	if dir, variable := input.ServerConfig.TempDir(); dir != "" && input.Meta.IndexLength >= sortFileThreshold {
+		result.addWarning(WarnInplaceTmpdirSpace, fmt.Sprintf(
+			"INPLACE ALTER writes its temporary sort files to %s (%s), not the data directory. "+
+				"Make sure that filesystem has room for them (up to ~%s, the size of the table's indexes), or point innodb_tmpdir at a larger volume.",
+			dir, variable, humanBytes(input.Meta.IndexLength),
+		))
+	}
+}
+
+// applySQLModeWarnings says what the server's sql_mode does to the ALTERs that depend on
+// it: NULLs in a column becoming NOT NULL (a new primary key's, or MODIFY ... NOT NULL),
+// values that aren't dates in a column becoming DATE, DATETIME or TIMESTAMP, and zero-date
+// defaults, which strict mode with NO_ZERO_DATE rejects in any ALTER TABLE. Only with a
+// live connection, which reads the mode.
+func applySQLModeWarnings(input Input, result *Result) {
+	if input.ServerConfig.SQLMode == nil || input.MetadataUnknown ||
+		!strings.HasPrefix(strings.ToUpper(strings.TrimSpace(input.Parsed.RawSQL)), "ALTER TABLE") {
+		return
+	}
+	mode := *input.ServerConfig.SQLMode
+	strict := sqlModeHas(mode, "STRICT_TRANS_TABLES") || sqlModeHas(mode, "STRICT_ALL_TABLES")
+	shown := fmt.Sprintf("sql_mode is '%s'", mode)
+	if !strict {
+		shown += " (not strict)"
+	}
+	const sessionNote = " This is the server's default (@@GLOBAL.sql_mode): a session that sets its own, like a migration tool's, gets that mode's behavior."
+
+	var notNull, temporal []string
+	var newType string
+	for _, sub := range input.Parsed.SubOperations {
+		switch sub.Op {
+		case parser.AddPrimaryKey:
+			notNull = append(notNull, nullableColumns(input.Meta, sub.IndexColumns)...)
+		case parser.ModifyColumn, parser.ChangeColumn:
+			name := sub.ColumnName
+			if sub.Op == parser.ChangeColumn && sub.OldColumnName != "" {
+				name = sub.OldColumnName
+			}
+			col := findColumnInfo(input.Meta, name)
+			if col == nil {
+				continue
+			}
+			if sub.NewColumnNullable != nil && !*sub.NewColumnNullable && col.Nullable {
+				notNull = append(notNull, col.Name)
+			}
+			if t := temporalBase(sub.NewColumnType); t != "" && temporalBase(col.Type) != t {
+				temporal = append(temporal, col.Name)
+				newType = strings.ToUpper(t)
+			}
+		}
+	}
+
+	if len(notNull) > 0 {
+		cols := strings.Join(notNull, "`, `")
+		if strict {
+			result.addWarning(WarnSQLModeDependent, fmt.Sprintf(
+				"%s: if `%s` holds NULLs, the ALTER fails with error 1138 \"Invalid use of NULL value\" and changes nothing.%s",
+				shown, cols, sessionNote,
+			))
+		} else {
+			result.addWarning(WarnSQLModeDependent, fmt.Sprintf(
+				"%s: NULLs in `%s` are silently replaced with the type's implicit default (0, '', the zero date) and the ALTER succeeds with warnings only. "+
+					"Fix the NULLs first, or add STRICT_TRANS_TABLES to the sql_mode of the session running the ALTER so it fails instead.%s",
+				shown, cols, sessionNote,
+			))
+		}
+	}
+
+	if len(temporal) > 0 {
+		cols := strings.Join(temporal, "`, `")
+		var msg string
+		switch {
+		case strict && (sqlModeHas(mode, "NO_ZERO_DATE") || sqlModeHas(mode, "NO_ZERO_IN_DATE")):
+			msg = fmt.Sprintf("%s: converting `%s` to %s fails with error 1292 on a value that isn't a valid date, a zero date ('0000-00-00') or a date with a zero month or day.", shown, cols, newType)
+		case strict:
+			msg = fmt.Sprintf("%s: converting `%s` to %s keeps zero dates, but fails with error 1292 on a value that isn't a valid date.", shown, cols, newType)
+		default:
+			msg = fmt.Sprintf("%s: converting `%s` to %s silently stores a value that isn't a valid date as the zero date ('0000-00-00'), with warnings only.", shown, cols, newType)
+		}
+		result.addWarning(WarnSQLModeDependent, msg+sessionNote)
+	}
+
+	if strict && sqlModeHas(mode, "NO_ZERO_DATE") {
+		var zeroDefaults []string
+		for _, col := range input.Meta.Columns {
+			if col.Default != nil && strings.HasPrefix(strings.Trim(*col.Default, "'"), "0000-00-00") && temporalBase(col.Type) != "" {
+				zeroDefaults = append(zeroDefaults, col.Name)
+			}
+		}
+		if len(zeroDefaults) > 0 {
+			result.addWarning(WarnSQLModeDependent, fmt.Sprintf(
+				"%s: `%s` default to the zero date, which strict mode with NO_ZERO_DATE rejects, so the ALTER fails with error 1067 \"Invalid default value\". "+
+					"Change the defaults (e.g. to NULL or CURRENT_TIMESTAMP) in the same ALTER, or run it in a session without NO_ZERO_DATE.%s",
+				shown, strings.Join(zeroDefaults, "`, `"), sessionNote,
+			))
+			result.Risk = RiskDangerous
+		}
+	}
+}
+
+// sqlModeHas reports whether the comma-separated sql_mode includes mode.
+func sqlModeHas(sqlMode, mode string) bool {
+	for _, m := range strings.Split(sqlMode, ",") {
+		if strings.EqualFold(strings.TrimSpace(m), mode) {
+			return true
+		}
+	}
+	return false
+}
+
+// temporalBase returns the base type of a DATE, DATETIME or TIMESTAMP column type, in
+// lowercase, or "" for any other type.
+func temporalBase(colType string) string {
+	base := strings.ToLower(strings.TrimSpace(colType))
+	if i := strings.IndexAny(base, "( "); i >= 0 {
+		base = base[:i]
+	}
+	switch base {
+	case "date", "datetime", "timestamp":
+		return base
+	}
+	return ""
+}
+
+// Default redo log capacity, assumed when the server value can't be read:
+// innodb_redo_log_capacity from MySQL 8.0.30; 2 × 48 MB innodb_log_file_size before, and
+// MariaDB's single 96 MB file.
+const (
+	defaultRedoLogCapacity       = 100 * 1024 * 1024
+	defaultRedoLogCapacityLegacy = 96 * 1024 * 1024
+)
+
+// applyRedoUndoPressure warns when a large table rebuild run directly writes more redo than
+// the redo log holds. A rebuild logs roughly the whole table; once the redo log is full,
+// InnoDB flushes dirty pages aggressively to free it, which stalls writes server-wide. The
+// statement also holds back purge while it runs. Aurora is skipped: its storage layer has
+// no redo log files to fill.
+func applyRedoUndoPressure(input Input, result *Result) {
+	const largeTable = 1 * 1024 * 1024 * 1024 // 1 GB
+	c := result.Classification
+	if result.Method != ExecDirect || input.Meta.TotalSize() < largeTable || input.Version.IsAurora() ||
+		!(c.Algorithm == AlgoCopy || (c.Algorithm == AlgoInplace && c.RebuildsTable)) {
+		return
+	}
+
+	capacity := input.ServerConfig.RedoLogCapacity
+	configured := humanBytes(capacity)
+	if capacity <= 0 {
+		capacity = defaultRedoLogCapacity
+		if !input.Version.AtLeast(8, 0, 30) || input.Version.Flavor == "mariadb" {
+			capacity = defaultRedoLogCapacityLegacy
+		}
+		configured = humanBytes(capacity) + " by default; the server value could not be read"
+	}
+	volume := input.Meta.TotalSize()
+	if volume < capacity {
+		return
+	}
+
+	msg := fmt.Sprintf(
+		"A direct %s rebuild of this %s table writes about its size to the redo log, %.0fx the redo log capacity (%s). "+
+			"Once the redo log fills, InnoDB flushes dirty pages aggressively to free it, stalling writes on the whole server until the rebuild ends. "+
+			"While the statement runs, purge can't remove the undo of other transactions' changes, so the history list and the undo tablespaces grow",
+		c.Algorithm, humanBytes(volume), float64(volume)/float64(capacity), configured,
+	)
+	if input.ServerConfig.UndoLogTruncateOff {
+		msg += ", and with innodb_undo_log_truncate OFF they don't shrink afterwards"
+	}
+	msg += "."
+	// Beyond ten times the capacity, the redo log wraps many times over: copy in small
+	// throttled transactions instead, when the tools can be used.
+	if volume >= 10*capacity && !input.NoOnlineTools {
+		msg += " Use gh-ost or pt-online-schema-change instead: they copy the table in small transactions and throttle on load."
+	} else if input.Version.Flavor == "mariadb" {
+		msg += " Run it off-peak, or raise innodb_log_file_size for its duration (dynamic from MariaDB 10.9)."
+	} else if input.Version.AtLeast(8, 0, 30) {
+		msg += " Run it off-peak, or raise innodb_redo_log_capacity (dynamic) for its duration."
+	} else {
+		msg += " Run it off-peak: innodb_log_file_size only changes with a restart."
+	}
+	result.addWarning(WarnRedoUndoPressure, msg)
+}
+
 func analyzeDDL(input Input, result *Result) {
 	result.DDLOp = input.Parsed.DDLOp
 
 	// Warn if operation couldn't be fully parsed
 	if input.Parsed.DDLOp == parser.OtherDDL {
-		result.Warnings = append(result.Warnings,
-			"⚠️  DDL operation could not be fully parsed. This may indicate a syntax error or unsupported operation.",
-			"Please verify the SQL syntax manually before execution.",
+		result.addWarning(WarnDDLUnparsed,
+			"⚠️  DDL operation could not be fully parsed. This may indicate a syntax error or unsupported operation. "+
+				"Please verify the SQL syntax manually before execution.",
 		)
 		result.Risk = RiskDangerous
 	}
 
+	// Views, stored routines, triggers and events only change the data dictionary: there is
+	// no table to rebuild, so the online-schema-change analysis doesn't apply.
+	if input.Parsed.DDLOp == parser.ObjectDefinition {
+		analyzeObjectDefinition(input, result)
+		return
+	}
+
+	// GRANT, CREATE USER and the like change accounts, not tables: nothing to plan.
+	if input.Parsed.DDLOp == parser.AccountManagement {
+		analyzeAccountManagement(input, result)
+		return
+	}
+
+	// CREATE TABLE ... AS SELECT is a data copy, not a schema change: size it from the
+	// SELECT and skip the ALTER algorithm and online-schema-change logic below.
+	if input.Parsed.DDLOp == parser.CreateTableAsSelect {
+		analyzeCreateTableAsSelect(input, result)
+		return
+	}
+
+	// ANALYZE and CHECK TABLE only read the table: nothing is rebuilt or changed.
+	if input.Parsed.DDLOp == parser.AnalyzeTable || input.Parsed.DDLOp == parser.CheckTable {
+		analyzeMaintenance(input, result)
+		return
+	}
+
+	// A new table has no data to migrate; what matters is the definition itself.
+	if input.Parsed.DDLOp == parser.CreateTable {
+		analyzeCreateTable(input, result)
+		return
+	}
+
 	// Validate column existence before proceeding
-	validateColumnOperation(input, result)
+	if !input.MetadataUnknown {
+		validateColumnOperation(input, result)
+	}
 
 	// Classify using the DDL matrix
 	// Use EffectivePatch() so Aurora 8.0 is treated as MySQL 8.0.23 for algorithm selection.
 	v := input.Version
-	result.Classification = ClassifyDDLWithContext(input.Parsed, v.Major, v.Minor, v.EffectivePatch())
+	result.reclassify(
+		fmt.Sprintf("matrix baseline: %s on %d.%d.%d, range %s", input.Parsed.DDLOp, v.Major, v.Minor, v.EffectivePatch(), classifyVersion(v.Major, v.Minor, v.EffectivePatch())),
+		ClassifyDDLWithContext(input.Parsed, v.Major, v.Minor, v.EffectivePatch()),
+	)
 
 	// For CONVERT TO CHARACTER SET: refine the COPY baseline from the matrix using live
 	// table metadata. Per WL#11605, COPY is required when any indexed string column exists;
 	// INPLACE is sufficient otherwise — but SHARED lock always applies regardless.
 	if input.Parsed.DDLOp == parser.ConvertCharset {
 		applyConvertCharsetClassification(input, result)
+		result.Warnings = append(result.Warnings, convertCharsetKeyLengthWarnings(input)...)
+	}
+
+	// For ADD FULLTEXT INDEX: only the first FULLTEXT index rebuilds the table (to add the
+	// hidden FTS_DOC_ID column). The matrix baseline assumes a rebuild; refine it from live indexes.
+	if input.Parsed.DDLOp == parser.AddFulltextIndex {
+		if hasFTSDocID(input.Meta) {
+			result.reclassify("FULLTEXT index: the table already has FTS_DOC_ID", subsequentFulltextClassification())
+		} else {
+			result.addWarning(WarnFirstFulltextIndexRebuild, firstFulltextWarning(input.Meta))
+		}
+	}
+
+	// For ALTER TABLE ... ORDER BY: a full COPY rebuild whose effect InnoDB doesn't keep.
+	if input.Parsed.DDLOp == parser.OrderBy {
+		result.addWarning(WarnOrderByIneffective,
+			"ALTER TABLE ... ORDER BY rebuilds the whole table with COPY, but InnoDB stores rows in primary key order and does not preserve the new physical order after later inserts and updates. "+
+				"The operation is usually pointless and expensive; MySQL ignores the ordering entirely when the table has a user-defined PRIMARY KEY or UNIQUE NOT NULL key.")
 	}
 
 	// For CHANGE COLUMN: check if the data type is actually changing.
@@ -228,13 +857,13 @@ func analyzeDDL(input Input, result *Result) {
 	if input.Parsed.DDLOp == parser.ChangeColumn && input.Parsed.NewColumnType != "" {
 		if oldType := findColumnType(input.Meta.Columns, input.Parsed.OldColumnName); oldType != "" {
 			if !strings.EqualFold(strings.ReplaceAll(oldType, " ", ""), strings.ReplaceAll(input.Parsed.NewColumnType, " ", "")) {
-				result.Classification = DDLClassification{
+				result.reclassify("CHANGE COLUMN: data type change", DDLClassification{
 					Algorithm:     AlgoCopy,
 					Lock:          LockShared,
 					RebuildsTable: true,
 					Notes:         "Data type change requires COPY algorithm with SHARED lock. Reads allowed, writes blocked during rebuild.",
-				}
-				result.Warnings = append(result.Warnings, fmt.Sprintf(
+				})
+				result.addWarning(WarnColumnTypeChangeCopy, fmt.Sprintf(
 					"Column '%s' type change detected: %s → %s. COPY algorithm required.",
 					input.Parsed.OldColumnName, oldType, input.Parsed.NewColumnType,
 				))
@@ -249,11 +878,17 @@ func analyzeDDL(input Input, result *Result) {
 		if oldType != "" {
 			charset := findColumnCharset(input.Meta.Columns, input.Parsed.ColumnName)
 
+			// Numeric narrowing (e.g. INT → SMALLINT, DECIMAL(14,4) → DECIMAL(10,2)) stays COPY,
+			// but existing values may not fit: suggest a pre-flight range check.
+			if warn, ok := numericNarrowingWarning(input.Parsed.Table, input.Parsed.ColumnName, oldType, input.Parsed.NewColumnType); ok {
+				result.addWarning(WarnNumericNarrowing, warn)
+			}
+
 			// Check for an explicit charset change: always requires COPY.
 			// (The COPY baseline from the matrix already applies; we add a specific warning.)
 			if input.Parsed.NewColumnCharset != "" && charset != "" &&
 				!strings.EqualFold(charset, input.Parsed.NewColumnCharset) {
-				result.Warnings = append(result.Warnings, fmt.Sprintf(
+				result.addWarning(WarnCharsetChangeCopy, fmt.Sprintf(
 					"Column '%s' charset change detected: %s → %s. COPY with SHARED lock required.",
 					input.Parsed.ColumnName, charset, input.Parsed.NewColumnCharset,
 				))
@@ -263,30 +898,115 @@ func analyzeDDL(input Input, result *Result) {
 
 				// Priority 1: ENUM/SET append-at-end → INSTANT (metadata-only).
 				if cls, ok := classifyModifyColumnEnum(oldType, input.Parsed.NewColumnType); ok {
-					result.Classification = cls
+					result.reclassify("MODIFY COLUMN: ENUM/SET members appended", cls)
 				} else if cls, ok := classifyModifyColumnVarchar(oldType, input.Parsed.NewColumnType, charset); ok {
 					// Priority 2: VARCHAR extension within same length-prefix tier → INPLACE, no rebuild.
-					result.Classification = cls
+					result.reclassify("MODIFY COLUMN: VARCHAR extended within its length-prefix size", cls)
 				}
 			}
 		}
 	}
 
+	// For MODIFY COLUMN of a generated column: a new generation expression means every
+	// STORED value is recomputed (COPY), while a VIRTUAL column only changes its definition.
+	if input.Parsed.DDLOp == parser.ModifyColumn {
+		if cls, warn, ok := generationExprChange(input.Meta.Columns, input.Parsed.ColumnName, input.Parsed.NewColumnType,
+			input.Parsed.NewGenerationExpr, input.Parsed.IsGeneratedStored); ok {
+			result.reclassify("MODIFY COLUMN: generation expression change", cls)
+			if warn != "" {
+				result.addWarning(WarnGeneratedExprChangeCopy, warn)
+			}
+		}
+	}
+
+	// For ADD/MODIFY of a generated column with FIRST/AFTER: the new position must keep the
+	// generated columns it references before it, and the ones referencing it after it.
+	if (input.Parsed.DDLOp == parser.AddColumn || input.Parsed.DDLOp == parser.ModifyColumn) &&
+		input.Parsed.IsGeneratedColumn && input.Parsed.IsFirstAfter {
+		if warn, ok := generatedColumnOrderWarning(input.Meta, input.Parsed.ColumnName, input.Parsed.NewGenerationExpr, input.Parsed.AfterColumn); ok {
+			result.addWarning(WarnGeneratedColumnOrder, warn)
+			result.Risk = RiskDangerous
+		}
+	}
+
+	// For MODIFY/CHANGE COLUMN with an SRID on a spatial column: COPY, and every existing
+	// geometry is validated against the SRID — suggest finding mismatched rows first.
+	if input.Parsed.DDLOp == parser.ModifyColumn || input.Parsed.DDLOp == parser.ChangeColumn {
+		column := input.Parsed.ColumnName
+		if input.Parsed.OldColumnName != "" {
+			column = input.Parsed.OldColumnName
+		}
+		if cls, warn, ok := spatialSRIDChange(input.Meta, input.Parsed.Table, column, input.Parsed.NewColumnType, input.Parsed.NewColumnSRID); ok {
+			result.reclassify("spatial column SRID", cls)
+			result.addWarning(WarnSpatialSRIDValidation, warn)
+		}
+	}
+
 	// For ALTER TABLESPACE RENAME: warn if the server version is too old (introduced in 8.0.21).
 	if input.Parsed.DDLOp == parser.AlterTablespace {
 		vr := classifyVersion(v.Major, v.Minor, v.EffectivePatch())
 		if vr == V8_0_Early || (vr == V8_0_Instant && v.EffectivePatch() < 21) {
-			result.Warnings = append(result.Warnings,
+			result.addWarning(WarnTablespaceRenameUnsupported,
 				fmt.Sprintf("ALTER TABLESPACE ... RENAME TO requires MySQL 8.0.21+. Your version (%s) will reject this statement with a syntax error.", v.String()),
 			)
 			result.Risk = RiskDangerous
 		}
 	}
 
+	// For ENGINE_ATTRIBUTE / SECONDARY_ENGINE_ATTRIBUTE: the options were added in 8.0.21.
+	if input.Parsed.DDLOp == parser.EngineAttribute && v.Major == 8 && v.Minor == 0 && v.EffectivePatch() < 21 {
+		result.addWarning(WarnEngineAttributeUnsupported,
+			fmt.Sprintf("ENGINE_ATTRIBUTE and SECONDARY_ENGINE_ATTRIBUTE require MySQL 8.0.21+. Your version (%s) will reject this statement with a syntax error.", v.String()),
+		)
+		result.Risk = RiskDangerous
+	}
+
+	// For SECONDARY_LOAD: nothing is blocked on InnoDB, but the load scans the whole table
+	// and needs a secondary engine (HeatWave) to load into.
+	if input.Parsed.DDLOp == parser.SecondaryLoad {
+		result.addWarning(WarnSecondaryLoad, fmt.Sprintf(
+			"SECONDARY_LOAD copies the whole table (%s) into the secondary engine: DML on the InnoDB table continues, but the load reads every row and can run for a long time, "+
+				"competing for I/O and CPU with the workload. It fails unless the table has a SECONDARY_ENGINE and a HeatWave cluster is attached.",
+			humanBytes(input.Meta.TotalSize()),
+		))
+	}
+
+	// For a multi-table RENAME TABLE (e.g. the a TO a_old, a_new TO a swap): one atomic
+	// metadata operation, but every pair must find its source present and its target free
+	// at that point in the list.
+	if input.Parsed.DDLOp == parser.RenameTable && len(input.Parsed.RenamePairs) > 1 {
+		result.Classification.Notes = fmt.Sprintf(
+			"Atomic multi-table RENAME (%d pairs): all renames happen as one metadata operation under exclusive metadata locks on every table. No data is copied; readers never see a missing table.",
+			len(input.Parsed.RenamePairs))
+		if warnings := renameSwapWarnings(result.Database, input.Parsed.RenamePairs); len(warnings) > 0 {
+			result.Warnings = append(result.Warnings, warnings...)
+			result.Risk = RiskDangerous
+		}
+	}
+
+	// For ADD/DROP COLUMN IF [NOT] EXISTS: the modifier is MariaDB syntax, which MySQL's
+	// ALTER TABLE grammar doesn't include.
+	if input.Parsed.ColumnIfExists && input.Version.Flavor != "mariadb" {
+		result.addWarning(WarnColumnIfExistsSyntax,
+			"IF [NOT] EXISTS on ADD/DROP COLUMN is MariaDB syntax: MySQL, Percona Server and Aurora MySQL reject it with a syntax error (ER_PARSE_ERROR) and change nothing. "+
+				"For a re-runnable migration on MySQL, use --idempotent to wrap the plain statement in an existence check.")
+	}
+
+	// WAIT n / NOWAIT bound the statement's metadata lock waits. MySQL has no such clause.
+	if input.Parsed.LockWait != "" {
+		if input.Version.Flavor != "mariadb" {
+			result.addWarning(WarnLockWaitSyntax,
+				"WAIT n / NOWAIT on ALTER TABLE is MariaDB syntax: MySQL, Percona Server and Aurora MySQL reject it with a syntax error (ER_PARSE_ERROR) and change nothing. "+
+					"On MySQL, bound the wait with SET SESSION lock_wait_timeout = n before the statement instead.")
+		} else {
+			result.addWarning(WarnLockWaitModifier, lockWaitModifierNote(input.Parsed))
+		}
+	}
+
 	// For TABLE ENCRYPTION: warn that keyring plugin must be configured.
 	// dbsafe cannot verify plugin presence from a read-only connection, so this is informational.
 	if input.Parsed.DDLOp == parser.TableEncryption {
-		result.Warnings = append(result.Warnings,
+		result.addWarning(WarnKeyringRequired,
 			"Requires keyring plugin (keyring_file, keyring_vault, or component_keyring_*). Operation will fail if keyring is not configured.",
 		)
 	}
@@ -300,12 +1020,12 @@ func analyzeDDL(input Input, result *Result) {
 		input.Parsed.NewEngine != "" &&
 		input.Meta != nil &&
 		strings.EqualFold(input.Parsed.NewEngine, input.Meta.Engine) {
-		result.Classification = DDLClassification{
+		result.reclassify("ENGINE= the current engine (null ALTER)", DDLClassification{
 			Algorithm:     AlgoInplace,
 			Lock:          LockNone,
 			RebuildsTable: true,
 			Notes:         "ENGINE=<same engine>: equivalent to ALTER TABLE ... FORCE. INPLACE rebuild to reclaim fragmentation. Concurrent DML allowed.",
-		}
+		})
 	}
 
 	// For ADD FOREIGN KEY: the matrix baseline (INPLACE+NONE) applies only when
@@ -313,13 +1033,13 @@ func analyzeDDL(input Input, result *Result) {
 	// existing rows against the new constraint, which requires COPY+SHARED (no concurrent writes).
 	// Zero value of ForeignKeyChecksDisabled is false, so the safe COPY path is the default.
 	if input.Parsed.DDLOp == parser.AddForeignKey && !input.ForeignKeyChecksDisabled {
-		result.Classification = DDLClassification{
+		result.reclassify("ADD FOREIGN KEY with foreign_key_checks=ON", DDLClassification{
 			Algorithm:     AlgoCopy,
 			Lock:          LockShared,
 			RebuildsTable: false,
 			Notes:         "ADD FOREIGN KEY with foreign_key_checks=ON requires COPY algorithm. MySQL must validate all existing rows against the constraint. Set foreign_key_checks=OFF before the ALTER to use INPLACE.",
-		}
-		result.Warnings = append(result.Warnings,
+		})
+		result.addWarning(WarnFKChecksOnForcesCopy,
 			"foreign_key_checks=ON: COPY algorithm required for ADD FOREIGN KEY. All existing rows will be validated against the new constraint, blocking concurrent writes.",
 		)
 	}
@@ -330,13 +1050,13 @@ func analyzeDDL(input Input, result *Result) {
 		for _, colName := range input.Parsed.IndexColumns {
 			for _, col := range input.Meta.Columns {
 				if strings.EqualFold(col.Name, colName) && col.Nullable {
-					result.Classification = DDLClassification{
+					result.reclassify("ADD PRIMARY KEY on a nullable column", DDLClassification{
 						Algorithm:     AlgoCopy,
 						Lock:          LockShared,
 						RebuildsTable: true,
 						Notes:         "Nullable PK column requires COPY: MySQL must implicitly convert the column from NULL to NOT NULL during the rebuild.",
-					}
-					result.Warnings = append(result.Warnings, fmt.Sprintf(
+					})
+					result.addWarning(WarnNullablePKForcesCopy, fmt.Sprintf(
 						"Column '%s' is nullable: ADD PRIMARY KEY on a nullable column requires COPY algorithm (MySQL must enforce NOT NULL).",
 						colName,
 					))
@@ -347,6 +1067,9 @@ func analyzeDDL(input Input, result *Result) {
 				break
 			}
 		}
+		if nullable := nullableColumns(input.Meta, input.Parsed.IndexColumns); len(nullable) > 0 {
+			result.addWarning(WarnNullablePKNullValues, nullablePKNullValuesWarning(input.Parsed.Table, nullable))
+		}
 	}
 
 	// For ADD UNIQUE KEY or ADD PRIMARY KEY: suggest a pre-flight duplicate-check query.
@@ -355,16 +1078,53 @@ func analyzeDDL(input Input, result *Result) {
 	if (input.Parsed.DDLOp == parser.AddPrimaryKey || (input.Parsed.DDLOp == parser.AddIndex && input.Parsed.IsUniqueIndex)) &&
 		len(input.Parsed.IndexColumns) > 0 {
 		cols := strings.Join(input.Parsed.IndexColumns, ", ")
-		result.Warnings = append(result.Warnings, fmt.Sprintf(
-			"This ALTER will fail if duplicates exist. Verify with:\n  SELECT %s, COUNT(*) cnt FROM %s GROUP BY %s HAVING cnt > 1 LIMIT 5;",
-			cols, input.Parsed.Table, cols,
-		))
+		// A UNIQUE key doesn't constrain rows with a NULL in any of its columns, so those
+		// rows can't cause a duplicate: leave them out of the count.
+		var nullable []string
+		if input.Parsed.DDLOp == parser.AddIndex {
+			nullable = nullableColumns(input.Meta, input.Parsed.IndexColumns)
+		}
+		where := ""
+		if len(nullable) > 0 {
+			conds := make([]string, len(nullable))
+			for i, col := range nullable {
+				conds[i] = col + " IS NOT NULL"
+			}
+			where = " WHERE " + strings.Join(conds, " AND ")
+		}
+		query := fmt.Sprintf("SELECT %s, COUNT(*) cnt FROM %s%s GROUP BY %s HAVING cnt > 1 LIMIT 5;", cols, input.Parsed.Table, where, cols)
+		if input.Parsed.DDLOp == parser.AddPrimaryKey {
+			result.addWarning(WarnUniqueDuplicates, primaryKeyDuplicatesWarning(input.Meta, input.Parsed.IndexColumns, query))
+		} else {
+			result.addWarning(WarnUniqueDuplicates, "This ALTER will fail if duplicates exist. Verify with:\n  "+query)
+		}
+		if len(nullable) > 0 {
+			result.addWarning(WarnNullableUniqueColumn, fmt.Sprintf(
+				"Nullable UNIQUE key column(s) `%s`: a UNIQUE key treats NULLs as distinct, so any number of rows can still have NULL there, "+
+					"and the duplicate check above skips them. Declare the column(s) NOT NULL as well if every row must have a unique value.",
+				strings.Join(nullable, "`, `"),
+			))
+		}
+	}
+
+	// For ADD INDEX on VIRTUAL generated columns: the index materializes their values.
+	if input.Parsed.DDLOp == parser.AddIndex {
+		if warn, ok := virtualColumnIndexWarning(input.Meta, input.Parsed.IndexColumns); ok {
+			result.addWarning(WarnVirtualColumnIndex, warn)
+		}
+	}
+
+	// For ADD CONSTRAINT ... CHECK ... NOT ENFORCED: existing rows aren't validated, so it's
+	// a metadata-only change that can't fail on existing data.
+	if input.Parsed.DDLOp == parser.AddCheckConstraint && input.Parsed.CheckNotEnforced {
+		result.reclassify("CHECK constraint NOT ENFORCED", notEnforcedCheckClassification())
+		result.addWarning(WarnCheckNotEnforced, notEnforcedCheckWarning)
 	}
 
 	// For ADD CONSTRAINT ... CHECK: suggest a pre-flight validation query.
 	// If any existing row violates the check expression, the ALTER will fail.
-	if input.Parsed.DDLOp == parser.AddCheckConstraint && input.Parsed.CheckExpr != "" {
-		result.Warnings = append(result.Warnings, fmt.Sprintf(
+	if input.Parsed.DDLOp == parser.AddCheckConstraint && input.Parsed.CheckExpr != "" && !input.Parsed.CheckNotEnforced {
+		result.addWarning(WarnCheckConstraintViolation, fmt.Sprintf(
 			"This ALTER will fail if any row violates the check constraint. Verify with:\n  SELECT * FROM %s WHERE NOT (%s) LIMIT 5;",
 			input.Parsed.Table, input.Parsed.CheckExpr,
 		))
@@ -373,44 +1133,134 @@ func analyzeDDL(input Input, result *Result) {
 	// For ADD COLUMN with AUTO_INCREMENT: requires INPLACE with SHARED lock minimum and
 	// full table rebuild. Concurrent DML is not permitted (MySQL 8.0 Table 17.18).
 	if input.Parsed.DDLOp == parser.AddColumn && input.Parsed.HasAutoIncrement {
-		result.Classification = DDLClassification{
+		result.reclassify("ADD COLUMN with AUTO_INCREMENT", DDLClassification{
 			Algorithm:     AlgoInplace,
 			Lock:          LockShared,
 			RebuildsTable: true,
 			Notes:         "ADD COLUMN with AUTO_INCREMENT: INPLACE with SHARED lock minimum. Concurrent DML not permitted. Full table rebuild required.",
+		})
+		msg := "AUTO_INCREMENT column: INPLACE with LOCK=SHARED required. Concurrent DML (writes) are blocked during the rebuild."
+		if input.Parsed.HasUniqueKey {
+			msg += " Its UNIQUE key (SERIAL declares one) is built in the same rebuild; the generated values are distinct, so it can't fail on duplicates."
+		}
+		result.addWarning(WarnAutoIncrementSharedLock, msg)
+		if warn, ok := secondAutoIncrementWarning(input.Meta, input.Parsed.ColumnName); ok {
+			result.addWarning(WarnSecondAutoIncrement, warn)
+			result.Risk = RiskDangerous
+		}
+	}
+
+	// For ADD COLUMN ... UNIQUE without AUTO_INCREMENT: the index build rules out INSTANT,
+	// and every existing row gets the column's default, which collides unless it is NULL.
+	if input.Parsed.DDLOp == parser.AddColumn && input.Parsed.HasUniqueKey && !input.Parsed.HasAutoIncrement {
+		if result.Classification.Algorithm == AlgoInstant {
+			result.reclassify("ADD COLUMN with a UNIQUE key", DDLClassification{
+				Algorithm:     AlgoInplace,
+				Lock:          LockNone,
+				RebuildsTable: true,
+				Notes:         "ADD COLUMN with a UNIQUE key: the index build rules out INSTANT. INPLACE with table rebuild; concurrent DML allowed.",
+			})
+		}
+		if input.Parsed.HasNotNull || (input.Parsed.HasDefault && !strings.Contains(strings.ToUpper(input.Parsed.ColumnDef), "DEFAULT NULL")) {
+			result.addWarning(WarnUniqueDuplicates, fmt.Sprintf(
+				"Every existing row gets the same value in the new UNIQUE column `%s` (its default), so this ALTER fails with \"Duplicate entry\" once the table has two rows. "+
+					"Add the column nullable without a default, fill it with distinct values, then add the UNIQUE key.",
+				input.Parsed.ColumnName,
+			))
 		}
-		result.Warnings = append(result.Warnings,
-			"AUTO_INCREMENT column: INPLACE with LOCK=SHARED required. Concurrent DML (writes) are blocked during the rebuild.",
-		)
 	}
 
 	// For ADD STORED generated column: always requires COPY with SHARED lock.
 	// MySQL must rewrite all rows to compute and store the generated values.
 	// ADD VIRTUAL generated column is already INSTANT from the matrix.
 	if input.Parsed.DDLOp == parser.AddColumn && input.Parsed.IsGeneratedStored {
-		result.Classification = DDLClassification{
+		result.reclassify("ADD STORED generated column", DDLClassification{
 			Algorithm:     AlgoCopy,
 			Lock:          LockShared,
 			RebuildsTable: true,
 			Notes:         "ADD STORED generated column requires COPY algorithm. MySQL must rewrite all rows to compute and store the generated values. Concurrent writes blocked.",
-		}
-		result.Warnings = append(result.Warnings,
+		})
+		result.addWarning(WarnStoredGeneratedColumnCopy,
 			"STORED generated column: COPY with LOCK=SHARED required. All rows must be rewritten to compute stored values. Concurrent writes are blocked.",
 		)
 	}
 
+	// For ADD COLUMN ... DEFAULT (expr): the classification is left to the matrix, but the
+	// expression is evaluated for every existing row and may rule out INSTANT.
+	if input.Parsed.DDLOp == parser.AddColumn && input.Parsed.HasExpressionDefault {
+		result.addWarning(WarnExpressionDefault, expressionDefaultWarning(input.Parsed.ColumnName))
+	}
+
+	// For an INSTANT ADD COLUMN of a TEXT, BLOB or JSON column: the storage cost comes later.
+	if input.Parsed.DDLOp == parser.AddColumn && isLOBType(input.Parsed.NewColumnType) && result.Classification.Algorithm == AlgoInstant {
+		result.addWarning(WarnLOBBackfillDeferred, lobBackfillNote(input.Parsed.ColumnName, input.Parsed.NewColumnType, input.Parsed.HasExpressionDefault))
+	}
+
+	// For DROP INDEX: an index backing a foreign key can't be dropped unless another index
+	// covers the FK's columns.
+	if input.Parsed.DDLOp == parser.DropIndex {
+		if warnings := foreignKeyIndexWarnings(input.Meta, []string{input.Parsed.IndexName}, nil, nil); len(warnings) > 0 {
+			result.Warnings = append(result.Warnings, warnings...)
+			result.Risk = RiskDangerous
+		}
+	}
+
+	// For ALTER INDEX ... INVISIBLE: the reversible way to find out whether the index can go.
+	if input.Parsed.DDLOp == parser.ChangeIndexVisibility && input.Parsed.IndexInvisible {
+		result.addWarning(WarnInvisibleIndexTrial, invisibleIndexNote(input.Parsed.IndexName))
+	}
+
+	// For PARTITION BY: every unique key, the primary key included, must contain all the
+	// columns of the partitioning expression, or MySQL rejects the ALTER (error 1503).
+	if input.Parsed.DDLOp == parser.RepartitionTable {
+		if warn, ok := partitionKeyUniqueWarning(input.Meta, input.Parsed.PartitionColumns); ok {
+			result.addWarning(WarnPartitionKeyNotInUniqueKey, warn)
+			result.Risk = RiskDangerous
+		}
+	}
+
+	// For DROP/MODIFY/CHANGE COLUMN on a column of the partitioning expression: MySQL
+	// rejects dropping or renaming it, and a type change repartitions every row.
+	switch input.Parsed.DDLOp {
+	case parser.DropColumn, parser.ModifyColumn, parser.ChangeColumn, parser.MultipleOps:
+		if warnings := partitionColumnWarnings(input.Meta, input.Parsed.SubOperations); len(warnings) > 0 {
+			result.Warnings = append(result.Warnings, warnings...)
+			result.Risk = RiskDangerous
+		}
+		// The same for the columns of a CHECK constraint: dropping or renaming one fails, and a
+		// type change checks every row again.
+		for _, w := range checkConstraintColumnWarnings(input.Meta, input.Parsed.SubOperations) {
+			result.Warnings = append(result.Warnings, w)
+			if w.Code == WarnCheckConstraintColumn {
+				result.Risk = RiskDangerous
+			}
+		}
+		// A table needs a column, and one that isn't a virtual generated column.
+		if w, ok := remainingColumnsWarning(input.Meta, input.Parsed.SubOperations); ok {
+			result.Warnings = append(result.Warnings, w)
+			result.Risk = RiskDangerous
+		}
+	}
+
 	// For DROP STORED generated column: always INPLACE with table rebuild.
 	// MySQL must rewrite all rows to remove the stored values, but allows concurrent DML.
 	// DROP VIRTUAL generated column uses the matrix baseline (INSTANT on 8.0.29+).
 	if input.Parsed.DDLOp == parser.DropColumn {
+		// A column referenced by a generated column can't be dropped until the generated
+		// column (and any index on it) is dropped first.
+		if warnings := dependentGeneratedColumnWarnings(input.Meta, []string{input.Parsed.ColumnName}); len(warnings) > 0 {
+			result.Warnings = append(result.Warnings, warnings...)
+			result.Risk = RiskDangerous
+		}
+
 		for _, col := range input.Meta.Columns {
 			if strings.EqualFold(col.Name, input.Parsed.ColumnName) && col.IsStoredGenerated {
-				result.Classification = DDLClassification{
+				result.reclassify("DROP STORED generated column", DDLClassification{
 					Algorithm:     AlgoInplace,
 					Lock:          LockNone,
 					RebuildsTable: true,
 					Notes:         "DROP STORED generated column: INPLACE with table rebuild. MySQL rewrites all rows to remove the stored values. Concurrent DML allowed.",
-				}
+				})
 				break
 			}
 		}
@@ -426,15 +1276,15 @@ func analyzeDDL(input Input, result *Result) {
 				}
 				for _, idxCol := range idx.Columns {
 					if strings.EqualFold(idxCol, input.Parsed.ColumnName) {
-						result.Classification = DDLClassification{
+						result.reclassify("DROP COLUMN on an indexed column", DDLClassification{
 							Algorithm:     AlgoInplace,
 							Lock:          LockNone,
 							RebuildsTable: true,
 							Notes: fmt.Sprintf(
 								"DROP COLUMN on indexed column: INPLACE with table rebuild. Column '%s' is part of index '%s'; MySQL cannot use INSTANT for indexed columns. Concurrent DML allowed.",
 								input.Parsed.ColumnName, idx.Name),
-						}
-						result.Warnings = append(result.Warnings, fmt.Sprintf(
+						})
+						result.addWarning(WarnDropIndexedColumnRebuild, fmt.Sprintf(
 							"Column '%s' is part of index '%s'. Consider dropping the index first if you want a faster operation.",
 							input.Parsed.ColumnName, idx.Name))
 						found = true
@@ -448,12 +1298,51 @@ func analyzeDDL(input Input, result *Result) {
 	// For MULTIPLE_OPS: classify each sub-operation individually with live-metadata
 	// refinements and return the most restrictive combined result.
 	if input.Parsed.DDLOp == parser.MultipleOps && len(input.Parsed.SubOperations) > 0 {
-		var subOpWarnings []string
+		var subOpWarnings []Warning
 		result.Classification, result.SubOpResults, subOpWarnings = aggregateMultipleOps(
 			input.Parsed.SubOperations, input.Meta, input.ForeignKeyChecksDisabled, v,
 		)
 		result.Warnings = append(result.Warnings, subOpWarnings...)
-	}
+		for i, sub := range result.SubOpResults {
+			result.trace(fmt.Sprintf("sub-operation %d: %s", i+1, sub.Op), sub.Classification)
+		}
+		result.trace("multi-op: most restrictive sub-operation", result.Classification)
+		applyColumnLimits(input, result)
+
+		var dropped, droppedIndexes, droppedFKs []string
+		var addedIndexes [][]string
+		for _, subOp := range input.Parsed.SubOperations {
+			switch subOp.Op {
+			case parser.DropColumn:
+				dropped = append(dropped, subOp.ColumnName)
+			case parser.DropIndex:
+				droppedIndexes = append(droppedIndexes, subOp.IndexName)
+			case parser.DropForeignKey:
+				droppedFKs = append(droppedFKs, subOp.IndexName)
+			case parser.AddIndex, parser.AddPrimaryKey:
+				addedIndexes = append(addedIndexes, subOp.IndexColumns)
+				if warn, ok := virtualColumnIndexWarning(input.Meta, subOp.IndexColumns); ok && subOp.Op == parser.AddIndex {
+					result.addWarning(WarnVirtualColumnIndex, warn)
+				}
+			}
+		}
+		if warnings := dependentGeneratedColumnWarnings(input.Meta, dropped); len(warnings) > 0 {
+			result.Warnings = append(result.Warnings, warnings...)
+			result.Risk = RiskDangerous
+		}
+		for _, subOp := range input.Parsed.SubOperations {
+			if (subOp.Op == parser.AddColumn || subOp.Op == parser.ModifyColumn) && subOp.IsGeneratedColumn && subOp.IsFirstAfter {
+				if warn, ok := generatedColumnOrderWarning(input.Meta, subOp.ColumnName, subOp.NewGenerationExpr, subOp.AfterColumn); ok {
+					result.addWarning(WarnGeneratedColumnOrder, warn)
+					result.Risk = RiskDangerous
+				}
+			}
+		}
+		if warnings := foreignKeyIndexWarnings(input.Meta, droppedIndexes, addedIndexes, droppedFKs); len(warnings) > 0 {
+			result.Warnings = append(result.Warnings, warnings...)
+			result.Risk = RiskDangerous
+		}
+	}
 
 	// For MODIFY COLUMN with FIRST/AFTER: column reorder behavior depends on column type.
 	// Generated columns have different rules than regular columns.
@@ -466,29 +1355,29 @@ func analyzeDDL(input Input, result *Result) {
 				case input.Parsed.IsGeneratedStored:
 					// STORED generated column reorder requires COPY: all rows must be rewritten
 					// to move the physical column data.
-					result.Classification = DDLClassification{
+					result.reclassify("STORED generated column reorder", DDLClassification{
 						Algorithm:     AlgoCopy,
 						Lock:          LockShared,
 						RebuildsTable: true,
 						Notes:         "STORED generated column reorder (FIRST/AFTER): COPY required. MySQL must rewrite all rows to relocate the stored values.",
-					}
+					})
 				case input.Parsed.IsGeneratedColumn:
 					// VIRTUAL generated column reorder: INPLACE, no rebuild. There is no
 					// stored data to move — only metadata changes.
-					result.Classification = DDLClassification{
+					result.reclassify("VIRTUAL generated column reorder", DDLClassification{
 						Algorithm:     AlgoInplace,
 						Lock:          LockNone,
 						RebuildsTable: false,
 						Notes:         "VIRTUAL generated column reorder (FIRST/AFTER): INPLACE, no rebuild. No stored values to move.",
-					}
+					})
 				default:
 					// Regular column reorder — INPLACE with table rebuild, concurrent DML allowed.
-					result.Classification = DDLClassification{
+					result.reclassify("column reorder (FIRST/AFTER)", DDLClassification{
 						Algorithm:     AlgoInplace,
 						Lock:          LockNone,
 						RebuildsTable: true,
 						Notes:         "Column reorder (FIRST/AFTER) with same type: INPLACE with table rebuild, concurrent DML allowed.",
-					}
+					})
 				}
 			}
 			// If types differ, the existing classification (COPY) already covers the type-change case.
@@ -507,12 +1396,12 @@ func analyzeDDL(input Input, result *Result) {
 					if strings.EqualFold(col.Name, input.Parsed.ColumnName) {
 						newNullable := *input.Parsed.NewColumnNullable
 						if newNullable != col.Nullable {
-							result.Classification = DDLClassification{
+							result.reclassify("nullability change", DDLClassification{
 								Algorithm:     AlgoInplace,
 								Lock:          LockNone,
 								RebuildsTable: true,
 								Notes:         "Nullability change (NULL ↔ NOT NULL) with same base type: INPLACE with table rebuild, concurrent DML allowed.",
-							}
+							})
 						}
 						break
 					}
@@ -521,8 +1410,50 @@ func analyzeDDL(input Input, result *Result) {
 		}
 	}
 
+	// For MODIFY/CHANGE COLUMN making several kinds of change at once (name, type, charset,
+	// nullability, position): the blocks above refine one kind at a time, but the statement
+	// takes the most restrictive algorithm of them all. Explain which one dominates.
+	if input.Parsed.DDLOp == parser.ModifyColumn || input.Parsed.DDLOp == parser.ChangeColumn {
+		if mods := columnModifiers(input); len(mods) > 1 {
+			dominant := dominantModifier(mods)
+			if moreRestrictive(dominant.Classification, result.Classification) {
+				result.reclassify("mixed column changes: "+dominant.Change+" dominates", dominant.Classification)
+			}
+			result.addWarning(WarnMixedColumnChanges, mixedColumnChangesNote(mods, dominant))
+		}
+	}
+
+	// For ROW_FORMAT=COMPRESSED tables: INSTANT ADD/DROP COLUMN isn't supported, so MySQL
+	// silently falls back to an INPLACE rebuild; index builds and rebuilds recompress pages.
+	// Multi-op sub-operations are reclassified in classifySubOp.
+	if isCompressedTable(input.Meta) {
+		if c, ok := compressedRowFormatClassification(input.Parsed.DDLOp, result.Classification); ok {
+			result.reclassify("ROW_FORMAT=COMPRESSED: no INSTANT", c)
+			result.addWarning(WarnCompressedInstantFallback, compressedInstantFallbackWarning(input.Parsed.DDLOp))
+		}
+		if result.Classification.RebuildsTable || buildsIndex(input.Parsed) {
+			result.addWarning(WarnCompressedRebuildCost,
+				"Table uses ROW_FORMAT=COMPRESSED: rebuilding the table or building an index recompresses every page it writes. "+
+					"Expect extra CPU and a noticeably longer run than on a DYNAMIC table, plus compression failures (page splits) under write load.",
+			)
+		}
+	}
+
+	switch input.Parsed.DDLOp {
+	case parser.AddColumn, parser.ModifyColumn, parser.ChangeColumn:
+		applyColumnLimits(input, result)
+	}
+
+	applyRowVersionLimit(input, result)
+
+	// Explicit ALGORITHM=/LOCK= clauses in the user's ALTER must be compatible with the
+	// classification, and one forcing a slower path is what will actually run.
+	natural := result.Classification
+	checkAlgorithmLockHints(input, result)
+
 	// Determine risk and method based on algorithm
 	// Note: Column validation may have already set Risk to RiskDangerous, which we preserve
+	riskBeforeMethod := result.Risk
 	switch result.Classification.Algorithm {
 	case AlgoInstant:
 		if result.Risk != RiskDangerous {
@@ -538,7 +1469,7 @@ func analyzeDDL(input Input, result *Result) {
 
 	case AlgoInplace:
 		if result.Classification.Lock == LockNone {
-			if input.Meta.TotalSize() > 10*1024*1024*1024 { // > 10 GB
+			if input.Meta.TotalSize() > input.cautionSize() {
 				if result.Risk != RiskDangerous {
 					result.Risk = RiskCaution
 					result.Recommendation = "INPLACE with no lock, but table is large. I/O impact during index build. Consider scheduling during low-traffic window."
@@ -555,15 +1486,19 @@ func analyzeDDL(input Input, result *Result) {
 			// Both gh-ost and pt-osc can avoid the lock by copying the table online.
 			// gh-ost is preferred for non-Galera; applyGaleraWarnings() will override
 			// to pt-osc (and clear the alternative) if the topology is Galera.
-			if input.Meta.TotalSize() > 1*1024*1024*1024 { // > 1 GB
+			if input.Meta.TotalSize() > input.dangerousSize() {
 				if result.Risk != RiskDangerous {
 					result.Risk = RiskDangerous
 				}
-				result.Method = ExecGhost
-				result.AlternativeMethod = ExecPtOSC
-				result.MethodRationale = ghostPreferredRationale
-				if result.Risk == RiskDangerous && result.Recommendation == "" {
-					result.Recommendation = "INPLACE with SHARED lock on a large table. Use an online schema change tool to avoid blocking writes."
+				if input.NoOnlineTools {
+					requireMaintenanceWindow(input, result)
+				} else {
+					result.Method = ExecGhost
+					result.AlternativeMethod = ExecPtOSC
+					result.MethodRationale = ghostPreferredRationale
+					if result.Risk == RiskDangerous && result.Recommendation == "" {
+						result.Recommendation = "INPLACE with SHARED lock on a large table. Use an online schema change tool to avoid blocking writes."
+					}
 				}
 			} else {
 				if result.Risk != RiskDangerous {
@@ -575,7 +1510,7 @@ func analyzeDDL(input Input, result *Result) {
 		}
 
 	case AlgoCopy:
-		if input.Meta.TotalSize() > 1*1024*1024*1024 { // > 1 GB
+		if input.Meta.TotalSize() > input.dangerousSize() {
 			if result.Risk != RiskDangerous {
 				result.Risk = RiskDangerous
 			}
@@ -590,7 +1525,9 @@ func analyzeDDL(input Input, result *Result) {
 			// However, gh-ost is incompatible with Galera/PXC because it relies on binlog
 			// streaming which conflicts with Galera's writeset replication. In Galera clusters,
 			// we must use pt-online-schema-change (which uses triggers that replicate correctly).
-			if input.Topo.Type == topology.Galera {
+			if input.NoOnlineTools {
+				requireMaintenanceWindow(input, result)
+			} else if input.Topo.Type == topology.Galera {
 				result.Method = ExecPtOSC
 				result.MethodRationale = ptOSCOnlyRationale
 				if result.Recommendation == "" {
@@ -610,9 +1547,14 @@ func analyzeDDL(input Input, result *Result) {
 				result.Recommendation = "COPY algorithm rebuilds the table. Table is small enough for direct execution during low-traffic window."
 			}
 			result.Method = ExecDirect
+			if warn, ok := directCopyTradeoffWarning(input.Meta.TotalSize(), input.copyRate()); ok && !input.NoOnlineTools {
+				result.addWarning(WarnDirectCopyTradeoff, warn)
+			}
 		}
 	}
 
+	applyWriteRate(input, result, riskBeforeMethod)
+
 	// gh-ost cannot operate on tables with triggers: its shadow table approach causes triggers
 	// on the original table to fire during population, leading to data corruption or errors.
 	// Override to pt-osc (with --preserve-triggers) when triggers are present.
@@ -630,34 +1572,492 @@ func analyzeDDL(input Input, result *Result) {
 		result.MethodRationale = ptOSCForeignKeyRationale
 	}
 
+	// gh-ost doesn't handle partitioning scheme changes well. Override to pt-osc.
+	if result.Method == ExecGhost && (input.Parsed.DDLOp == parser.RemovePartitioning || input.Parsed.DDLOp == parser.RepartitionTable) {
+		result.Method = ExecPtOSC
+		result.AlternativeMethod = ""
+		result.MethodRationale = ptOSCPartitioningRationale
+	}
+
 	// Generate executable command for the primary method, and alternative when both are viable.
 	switch result.Method {
 	case ExecGhost:
 		result.ExecutionCommand = generateGhostCommand(input)
 		if result.AlternativeMethod == ExecPtOSC {
-			result.AlternativeExecutionCommand = generatePtOSCCommand(input, false)
+			result.AlternativeExecutionCommand = ptoscExecutionCommand(input, false)
 		}
 	case ExecPtOSC:
-		result.ExecutionCommand = generatePtOSCCommand(input, input.Topo.Type == topology.Galera)
+		result.ExecutionCommand = ptoscExecutionCommand(input, input.Topo.Type == topology.Galera)
+	}
+	if result.Method == ExecPtOSC || result.AlternativeMethod == ExecPtOSC {
+		if msg := ptoscForeignKeyWarning(input); msg != "" {
+			result.addWarning(WarnPtOSCForeignKeys, msg)
+		}
+		if msg := triggerDoubleWriteWarning(input); msg != "" {
+			result.addWarning(WarnTriggerDoubleWrite, msg)
+		}
 	}
 
-	// Build an optimized copy-paste DDL for ALTER TABLE with INSTANT/INPLACE algorithm.
+	// Build an optimized copy-paste DDL for ALTER TABLE with INSTANT/INPLACE algorithm,
+	// without the user's hints if they force a slower path.
 	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(input.Parsed.RawSQL)), "ALTER TABLE") {
-		result.OptimizedDDL = buildOptimizedDDL(input.Parsed.RawSQL, result.Classification)
+		result.OptimizedDDL = buildOptimizedDDL(input.Parsed.RawSQL, natural, input.Version)
 	}
 
 	// Generate rollback SQL
 	generateDDLRollback(input, result)
 }
 
+// requireMaintenanceWindow runs a large blocking ALTER natively when online schema change
+// tools can't be used (Input.NoOnlineTools): the plan says how long writes are blocked, so
+// the change can be scheduled into a maintenance window.
+func requireMaintenanceWindow(input Input, result *Result) {
+	c := result.Classification
+	blocked := formatEstimate(time.Duration(input.Meta.TotalSize()/input.copyRate()) * time.Second)
+	result.Method = ExecDirect
+	result.MethodRationale = noOnlineToolsRationale
+	if result.Recommendation == "" {
+		result.Recommendation = fmt.Sprintf(
+			"%s with %s lock on a large table, and online schema change tools are not available. Run the ALTER directly in a maintenance window: writes are blocked for est. %s.",
+			c.Algorithm, c.Lock, blocked,
+		)
+	}
+	result.addWarning(WarnMaintenanceWindowRequired, fmt.Sprintf(
+		"Online schema change tools are disabled, so this %s ALTER runs natively with a %s lock: writes to %s are blocked for the whole rebuild, est. %s for %s (assuming ~%s/s). "+
+			"Schedule it in a maintenance window and stop or queue the application's writes to the table; on replicas, the ALTER blocks replication for as long again once it is applied.",
+		c.Algorithm, c.Lock, input.Parsed.Table, blocked, humanBytes(input.Meta.TotalSize()), humanBytes(input.copyRate()),
+	))
+}
+
+// Write rates (rows per second, Meta.WriteRate) that move a blocking ALTER off the size
+// thresholds, and the size below which the rate doesn't matter.
+const (
+	hotWriteRate      = 50.0
+	coldWriteRate     = 1.0
+	writeRateMinBytes = 100 * 1024 * 1024
+)
+
+// applyWriteRate refines the method of a blocking ALTER by the table's sampled write rate.
+// Every write queues behind the lock for the whole ALTER, so a hot table goes to an online
+// schema change tool even below the size threshold. A table that is barely written to loses
+// little to a SHARED lock, so a large one can take an INPLACE ALTER directly instead of a
+// full online copy. riskBefore is the risk before the algorithm set it: a statement that
+// was already DANGEROUS keeps its tool.
+func applyWriteRate(input Input, result *Result, riskBefore RiskLevel) {
+	rate := input.Meta.WriteRate
+	c := result.Classification
+	if rate == nil || input.Meta.TotalSize() < writeRateMinBytes {
+		return
+	}
+	if c.Algorithm != AlgoCopy && (c.Algorithm != AlgoInplace || c.Lock == LockNone) {
+		return
+	}
+	blocked := formatEstimate(time.Duration(input.Meta.TotalSize()/input.copyRate()) * time.Second)
+
+	switch {
+	case *rate >= hotWriteRate && result.Method == ExecDirect && !input.NoOnlineTools:
+		if input.Topo.Type == topology.Galera {
+			result.Method = ExecPtOSC
+			result.AlternativeMethod = ""
+			result.MethodRationale = ptOSCOnlyRationale
+		} else {
+			result.Method = ExecGhost
+			result.AlternativeMethod = ExecPtOSC
+			result.MethodRationale = ghostPreferredRationale
+		}
+		if result.Risk == RiskSafe {
+			result.Risk = RiskCaution
+		}
+		result.Recommendation = fmt.Sprintf(
+			"%s with %s lock, and ~%.0f rows/s are being written to the table: a direct ALTER would queue them all for est. %s. "+
+				"Use an online schema change tool even though the table is below the size threshold.",
+			c.Algorithm, c.Lock, *rate, blocked,
+		)
+
+	case *rate < coldWriteRate && c.Algorithm == AlgoInplace && c.Lock == LockShared &&
+		(result.Method == ExecGhost || result.Method == ExecPtOSC) && riskBefore != RiskDangerous:
+		result.Method = ExecDirect
+		result.AlternativeMethod = ""
+		result.MethodRationale = ""
+		result.Risk = RiskCaution
+		result.Recommendation = fmt.Sprintf(
+			"INPLACE with SHARED lock on a large table, but only ~%.1f rows/s were written to it while sampling: the lock blocks little. "+
+				"Run it directly (est. %s) instead of copying the table online; replicas still apply it serially, delaying replication for as long.",
+			*rate, blocked,
+		)
+	}
+}
+
+// analyzeCreateTableAsSelect classifies CREATE TABLE ... AS SELECT by the size of the copy.
+// input.Meta describes the source table (all of a UNION's, added up); input.EstimatedRows is
+// the EXPLAIN estimate for the SELECT. Without an estimate, the whole source table is assumed
+// to be copied, and when part of the SELECT reads from no table that can be sized the copy's
+// size is reported as unknown rather than estimated from the tables alone.
+func analyzeCreateTableAsSelect(input Input, result *Result) {
+	v := input.Version
+	result.reclassify(fmt.Sprintf("matrix baseline: %s on %s", parser.CreateTableAsSelect, v.String()), ClassifyDDL(parser.CreateTableAsSelect, v.Major, v.Minor, v.EffectivePatch()))
+
+	result.AffectedRows = input.EstimatedRows
+	sizeUnknown := false
+	if result.AffectedRows <= 0 {
+		result.AffectedRows = input.Meta.RowCount
+		switch {
+		case input.Parsed.SourceUnknown:
+			sizeUnknown = true
+			result.addWarning(WarnCTASSizeUnknown, fmt.Sprintf(
+				"No EXPLAIN row estimate available, and part of the SELECT reads from a derived table or a query dbsafe couldn't parse: the number of rows copied is unknown. Count them before running:\n  SELECT COUNT(*) FROM (%s) AS t;",
+				input.Parsed.SelectSQL,
+			))
+		case input.Parsed.SelectSQL != "":
+			assumed := "the whole source table is"
+			if len(input.Parsed.UnionSources) > 1 {
+				assumed = "every table of the UNION is"
+			}
+			result.addWarning(WarnNoExplainEstimate, fmt.Sprintf(
+				"No EXPLAIN row estimate available for the SELECT: assuming %s copied. Verify with:\n  SELECT COUNT(*) FROM (%s) AS t;",
+				assumed, input.Parsed.SelectSQL,
+			))
+		}
+	}
+	if input.Meta.RowCount > 0 {
+		result.AffectedPct = float64(result.AffectedRows) / float64(input.Meta.RowCount) * 100
+	}
+	result.WriteSetSize = result.AffectedRows * input.Meta.AvgRowLength
+
+	refs := input.Parsed.UnionSources
+	if len(refs) == 0 && input.Parsed.SourceTable != "" {
+		refs = []parser.TableRef{{Database: input.Parsed.SourceDatabase, Table: input.Parsed.SourceTable}}
+	}
+	var sources []string
+	for _, t := range refs {
+		if t.Database != "" {
+			sources = append(sources, t.Database+"."+t.Table)
+		} else {
+			sources = append(sources, t.Table)
+		}
+	}
+	source := strings.Join(sources, ", ")
+	if source == "" {
+		source = "the source table"
+	}
+	copied := fmt.Sprintf("~%s rows (~%s)", formatNumber(result.AffectedRows), humanBytes(result.WriteSetSize))
+	if sizeUnknown {
+		copied = "an unknown number of rows"
+		if result.AffectedRows > 0 {
+			copied += fmt.Sprintf(" (at least ~%s, ~%s)", formatNumber(result.AffectedRows), humanBytes(result.WriteSetSize))
+		}
+	}
+	result.addWarning(WarnCreateTableAsSelect, fmt.Sprintf(
+		"CREATE TABLE ... AS SELECT copies %s from %s in a single statement. Scanned source rows are share-locked until it commits, and the new table needs that much free disk space.",
+		copied, source,
+	))
+
+	result.Method = ExecDirect
+	switch {
+	case result.WriteSetSize > 1*1024*1024*1024: // > 1 GB
+		result.Risk = RiskDangerous
+		result.Recommendation = "Large copy in one statement. Create the table with CREATE TABLE ... LIKE and fill it with chunked INSERT ... SELECT to avoid long-held locks and replication lag."
+	case sizeUnknown:
+		result.Risk = RiskCaution
+		result.Recommendation = "Copy of unknown size. Count the SELECT's rows first: if the copy is large, create the table with CREATE TABLE ... LIKE and fill it with chunked INSERT ... SELECT."
+	default:
+		result.Risk = RiskCaution
+		result.Recommendation = "Small copy. Direct execution OK during a low-traffic window."
+	}
+
+	generateDDLRollback(input, result)
+}
+
+// analyzeCreateTable checks a new table's definition for anti-patterns that are cheap to fix
+// now and expensive once the table holds data: each one makes the statement CAUTION.
+func analyzeCreateTable(input Input, result *Result) {
+	p := input.Parsed
+	v := input.Version
+	result.reclassify(fmt.Sprintf("matrix baseline: %s on %s", parser.CreateTable, v.String()), ClassifyDDL(parser.CreateTable, v.Major, v.Minor, v.EffectivePatch()))
+	result.Method = ExecDirect
+
+	if !p.HasPrimaryKey {
+		warning := "No PRIMARY KEY: InnoDB clusters the table on the first UNIQUE NOT NULL key or, failing that, a hidden 6-byte row ID. " +
+			"With row-based replication, every UPDATE and DELETE on the replicas then has to find its rows by scanning (or a secondary index), " +
+			"which is the classic cause of replica lag; online schema change tools also need a primary or unique key. Add an explicit PRIMARY KEY."
+		switch input.Topo.Type {
+		case topology.GroupRepl:
+			warning += " Group Replication rejects writes to tables without a primary key."
+		case topology.Galera:
+			warning += " Galera does not support DELETE on tables without a primary key and replicates their writes inefficiently."
+		}
+		result.addWarning(WarnNoPrimaryKey, warning)
+	}
+
+	if p.TableEngine != "" && p.TableEngine != "innodb" {
+		engine := strings.ToUpper(p.TableEngine)
+		switch p.TableEngine {
+		case "myisam":
+			engine = "MyISAM"
+		case "memory":
+			engine = "MEMORY"
+		}
+		result.addWarning(WarnNonInnoDBEngine, fmt.Sprintf(
+			"ENGINE=%s: unlike InnoDB it has no transactions or crash recovery and locks the whole table for every write. Use ENGINE=InnoDB unless the engine is required.",
+			engine,
+		))
+	}
+
+	if legacy := legacyCharsets(p); len(legacy) > 0 {
+		result.addWarning(WarnLegacyCharset, fmt.Sprintf(
+			"%s: latin1 and utf8mb3 can't store all of Unicode (emoji, many CJK characters), and converting later means a COPY rebuild of the table. Use utf8mb4, MySQL 8.0's default.",
+			strings.Join(legacy, ", "),
+		))
+	}
+
+	if p.TableRowFormat == "" {
+		result.addWarning(WarnNoRowFormat,
+			"No explicit ROW_FORMAT: the table gets the server's innodb_default_row_format, which can differ between servers (e.g. a primary and a replica built from different configs). "+
+				"Set ROW_FORMAT=DYNAMIC explicitly so the table is the same everywhere.",
+		)
+	}
+
+	if len(result.Warnings) > 0 {
+		result.Risk = RiskCaution
+		result.Recommendation = "New table: safe to create, but fix the definition warnings first; they are cheap to change now and need a table rebuild once it holds data."
+	} else {
+		result.Risk = RiskSafe
+		result.Recommendation = "New table: metadata-only and safe to run directly."
+	}
+
+	generateDDLRollback(input, result)
+}
+
+// legacyCharsets describes the table and column character sets of a CREATE TABLE that aren't
+// utf8mb4, e.g. "Table charset latin1" or "Column `name` charset utf8mb3".
+func legacyCharsets(p *parser.ParsedSQL) []string {
+	legacy := func(charset string) bool {
+		return charset == "latin1" || charset == "utf8" || charset == "utf8mb3"
+	}
+	var out []string
+	if legacy(p.TableCharset) {
+		out = append(out, "Table charset "+p.TableCharset)
+	}
+	for _, c := range p.ColumnCharsets {
+		if legacy(c.Charset) {
+			out = append(out, fmt.Sprintf("Column `%s` charset %s", c.Column, c.Charset))
+		}
+	}
+	return out
+}
+
+// analyzeMaintenance handles ANALYZE TABLE and CHECK TABLE. Neither changes the table's
+// definition or data, so there is nothing to rebuild or roll back; the risk comes from how
+// long they hold their locks.
+func analyzeMaintenance(input Input, result *Result) {
+	p := input.Parsed
+	v := input.Version
+	result.reclassify(fmt.Sprintf("matrix baseline: %s on %s", p.DDLOp, v.String()), ClassifyDDL(p.DDLOp, v.Major, v.Minor, v.EffectivePatch()))
+	result.Method = ExecDirect
+	result.Risk = RiskSafe
+	result.RollbackNotes = "No rollback needed. This statement doesn't change the table's definition or data."
+
+	switch p.DDLOp {
+	case parser.AnalyzeTable:
+		result.Recommendation = "Statistics refresh: samples a fixed number of pages per index, so it is fast at any table size. Safe to run directly."
+		if v.Major == 8 && v.Minor == 0 && v.EffectivePatch() < 24 {
+			result.Risk = RiskCaution
+			result.Recommendation = "Statistics refresh: fast, but run it when no long query is using the table."
+			result.addWarning(WarnAnalyzeTableFlush,
+				"Before MySQL 8.0.24, ANALYZE TABLE marks the table for a flush when it finishes: new queries on it wait (\"Waiting for table flush\") until every query already running on it completes. "+
+					"Behind one long-running query, that stalls all traffic to the table. Check the processlist for long queries on it first.")
+		}
+	case parser.CheckTable:
+		result.Recommendation = "Integrity check: reads every index while blocking writes. Quick on a table this size; safe to run directly."
+		if size := input.Meta.TotalSize(); size >= input.dangerousSize() {
+			result.Risk = RiskCaution
+			result.Recommendation = "Integrity check of a large table: writes are blocked for the whole scan. Run it on a replica or a restored backup, or in a low-traffic window."
+			result.addWarning(WarnCheckTableReadLock, fmt.Sprintf(
+				"CHECK TABLE reads every index of this %s table under a read lock that blocks writes to it for as long as the scan takes. "+
+					"It isn't written to the binary log, so it can run on a replica, or on a restored backup, without touching the primary.",
+				humanBytes(size),
+			))
+		}
+	}
+}
+
+// analyzeObjectDefinition handles CREATE/ALTER/DROP of a view, stored routine, trigger or
+// event: a data-dictionary change that is safe to run directly.
+func analyzeObjectDefinition(input Input, result *Result) {
+	p := input.Parsed
+	result.Classification = DDLClassification{
+		Algorithm:     AlgoInstant,
+		Lock:          LockNone,
+		RebuildsTable: false,
+		Notes:         "Data-dictionary change only. No table data is read, copied or rebuilt.",
+	}
+	result.Risk = RiskSafe
+	result.Method = ExecDirect
+	result.Recommendation = fmt.Sprintf("This is a %s definition; it is metadata-only and safe, no online-schema-change needed.", p.ObjectType)
+
+	if p.ObjectType == "TRIGGER" {
+		result.addWarning(WarnTriggerMetadataLock,
+			"CREATE/DROP TRIGGER takes a brief exclusive metadata lock on the trigger's table: it waits for open transactions on the table and blocks new queries while it waits. "+
+				"Once created, the trigger runs on every matching write, and gh-ost refuses to migrate a table that has triggers.")
+	}
+
+	show := "SHOW CREATE " + p.ObjectType
+	if strings.HasPrefix(strings.ToUpper(p.RawSQL), "DROP") {
+		result.RollbackNotes = fmt.Sprintf("DROP %s cannot be undone by dbsafe. Save the definition with %s `%s` before running it, to recreate it if needed.", p.ObjectType, show, p.ObjectName)
+	} else {
+		result.RollbackNotes = fmt.Sprintf("Restore the previous definition (from %s `%s`, captured beforehand) or DROP %s if it is new.", show, p.ObjectName, p.ObjectType)
+	}
+}
+
+// analyzeAccountManagement handles GRANT, REVOKE, CREATE/ALTER/DROP/RENAME USER, roles and
+// SET PASSWORD: account-management statements, outside what dbsafe analyzes. They only
+// change the grant tables, so there is no online-schema-change consideration to report,
+// and no algorithm or lock: the classification is left empty. Result.Command returns no
+// command for them.
+func analyzeAccountManagement(input Input, result *Result) {
+	p := input.Parsed
+	result.Classification = DDLClassification{
+		Notes: "Account management: only the grant tables change. No table data is read, copied or rebuilt.",
+	}
+	result.Risk = RiskSafe
+	result.Method = ExecDirect
+	result.Recommendation = fmt.Sprintf(
+		"%s is an account-management statement: dbsafe analyzes schema changes (DDL) and data changes (DML), and account management has no online-schema-change considerations. Review its privileges and accounts yourself.",
+		p.ObjectType,
+	)
+	result.RollbackNotes = "dbsafe doesn't generate a rollback for account changes. Capture the current state beforehand (SHOW GRANTS FOR the account, SHOW CREATE USER) to restore it if needed."
+}
+
 // buildOptimizedDDL appends ALGORITHM and LOCK hints to an ALTER TABLE statement so the user
 // can copy-paste it directly. Returns empty string for COPY or DEPENDS (no improvement possible).
-func buildOptimizedDDL(rawSQL string, c DDLClassification) string {
-	if c.Algorithm != AlgoInstant && c.Algorithm != AlgoInplace {
+func buildOptimizedDDL(rawSQL string, c DDLClassification, v mysql.ServerVersion) string {
+	algorithm := guaranteeingAlgorithm(c, v)
+	if algorithm == "" {
 		return ""
 	}
-	sql := strings.TrimRight(strings.TrimSpace(rawSQL), ";")
-	return fmt.Sprintf("%s, ALGORITHM=%s, LOCK=%s;", sql, c.Algorithm, c.Lock)
+	sql := stripAlgorithmLockHints(strings.TrimRight(strings.TrimSpace(rawSQL), ";"))
+	return fmt.Sprintf("%s, ALGORITHM=%s, LOCK=%s;", sql, algorithm, c.Lock)
+}
+
+// guaranteeingAlgorithm returns the most specific ALGORITHM= clause that holds the server to
+// the classification: with it, a statement dbsafe was too optimistic about fails at once
+// (ER_ALTER_OPERATION_NOT_SUPPORTED) instead of running with a slower algorithm. INSTANT
+// guarantees a metadata-only change. MySQL has nothing between INSTANT and INPLACE, but
+// MariaDB 10.3+ has NOCOPY, which guarantees an INPLACE change doesn't rebuild the table.
+// Returns "" for COPY and DEPENDS.
+func guaranteeingAlgorithm(c DDLClassification, v mysql.ServerVersion) string {
+	switch c.Algorithm {
+	case AlgoInstant:
+		return string(AlgoInstant)
+	case AlgoInplace:
+		if !c.RebuildsTable && v.Flavor == "mariadb" && (v.Major > 10 || (v.Major == 10 && v.Minor >= 3)) {
+			return "NOCOPY"
+		}
+		return string(AlgoInplace)
+	}
+	return ""
+}
+
+// Explicit ALGORITHM= / LOCK= clauses, either after another option (", LOCK=NONE") or
+// before one ("ALGORITHM=INPLACE, ").
+var (
+	reTrailingAlterHint = regexp.MustCompile(`(?i)\s*,\s*(?:ALGORITHM\s*=?\s*(?:DEFAULT|INSTANT|NOCOPY|INPLACE|COPY)|LOCK\s*=?\s*(?:DEFAULT|NONE|SHARED|EXCLUSIVE))\b`)
+	reLeadingAlterHint  = regexp.MustCompile(`(?i)\b(?:ALGORITHM\s*=?\s*(?:DEFAULT|INSTANT|NOCOPY|INPLACE|COPY)|LOCK\s*=?\s*(?:DEFAULT|NONE|SHARED|EXCLUSIVE))\s*,\s*`)
+)
+
+// reLockWaitClause is MariaDB's WAIT n / NOWAIT at the start of an ALTER TABLE spec.
+var reLockWaitClause = regexp.MustCompile(`(?i)^(?:WAIT\s+\d+|NOWAIT)\s+`)
+
+// stripAlgorithmLockHints removes ALGORITHM= and LOCK= clauses from an ALTER statement or spec.
+func stripAlgorithmLockHints(sql string) string {
+	sql = reTrailingAlterHint.ReplaceAllString(sql, "")
+	return reLeadingAlterHint.ReplaceAllString(sql, "")
+}
+
+var (
+	algorithmRank = map[Algorithm]int{AlgoInstant: 0, AlgoInplace: 1, AlgoCopy: 2}
+	lockRank      = map[LockLevel]int{LockNone: 0, LockShared: 1, LockExclusive: 2}
+)
+
+// checkAlgorithmLockHints compares explicit ALGORITHM= and LOCK= clauses in the user's ALTER
+// with the classification. MySQL rejects a statement whose hints ask for less than the operation
+// needs (ER_ALTER_OPERATION_NOT_SUPPORTED), so that mismatch is DANGEROUS. A hint asking for more
+// (e.g. ALGORITHM=COPY for an INSTANT change) runs, but slower than necessary: the result is
+// reclassified to what the hint forces, so risk and method follow from it.
+func checkAlgorithmLockHints(input Input, result *Result) {
+	c := result.Classification
+	algo, lock := c.Algorithm, c.Lock
+
+	if hint := Algorithm(input.Parsed.AlgorithmHint); hint != "" && hint != "DEFAULT" {
+		hintRank, ok := algorithmRank[hint]
+		needRank, known := algorithmRank[algo]
+		switch {
+		case !ok || !known:
+			// DEPENDS or an unrecognized hint: nothing to compare.
+		case hintRank < needRank:
+			result.addWarning(WarnAlgorithmHintUnsupported, fmt.Sprintf(
+				"ALGORITHM=%s is not supported for this operation: it requires ALGORITHM=%s. MySQL will reject the statement (ER_ALTER_OPERATION_NOT_SUPPORTED); remove the hint or use ALGORITHM=%s.",
+				hint, algo, algo,
+			))
+			result.Risk = RiskDangerous
+		case hintRank > needRank:
+			result.addWarning(WarnAlgorithmHintSlower, fmt.Sprintf(
+				"ALGORITHM=%s forces a slower path than needed: MySQL supports ALGORITHM=%s for this operation.",
+				hint, algo,
+			))
+			result.reclassify("ALGORITHM="+string(hint)+" hint", forcedAlgorithmClassification(input.Parsed, c, hint))
+			lock = result.Classification.Lock
+		}
+	}
+
+	if hint := LockLevel(input.Parsed.LockHint); hint != "" && hint != "DEFAULT" {
+		hintRank, ok := lockRank[hint]
+		needRank, known := lockRank[lock]
+		switch {
+		case !ok || !known:
+		case hintRank < needRank:
+			result.addWarning(WarnLockHintUnsupported, fmt.Sprintf(
+				"LOCK=%s is not supported for this operation: it requires at least LOCK=%s. MySQL will reject the statement (ER_ALTER_OPERATION_NOT_SUPPORTED); remove the hint or use LOCK=%s.",
+				hint, lock, lock,
+			))
+			result.Risk = RiskDangerous
+		case hintRank > needRank && result.Classification.Algorithm != AlgoInstant:
+			// A stricter lock than needed is taken as asked for.
+			cls := result.Classification
+			cls.Lock = hint
+			cls.Notes = fmt.Sprintf("LOCK=%s hint: %s is held for the whole operation, though the operation allows LOCK=%s.", hint, hint, lock)
+			result.reclassify("LOCK="+string(hint)+" hint", cls)
+		}
+	}
+}
+
+// forcedAlgorithmClassification is what runs when an ALGORITHM= hint forces a slower path
+// than classification c. A forced INPLACE rebuilds the table for the column changes INSTANT
+// would have applied to metadata; a forced COPY always rebuilds and blocks writes.
+func forcedAlgorithmClassification(parsed *parser.ParsedSQL, c DDLClassification, hint Algorithm) DDLClassification {
+	if hint == AlgoCopy {
+		lock := c.Lock
+		if lockRank[lock] < lockRank[LockShared] {
+			lock = LockShared
+		}
+		return DDLClassification{
+			Algorithm:     AlgoCopy,
+			Lock:          lock,
+			RebuildsTable: true,
+			Notes:         "ALGORITHM=COPY hint: the table is copied row by row with a SHARED lock. Reads allowed, writes blocked during the rebuild.",
+		}
+	}
+	rebuilds := c.RebuildsTable || parsed.DDLOp == parser.AddColumn || parsed.DDLOp == parser.DropColumn
+	for _, sub := range parsed.SubOperations {
+		if sub.Op == parser.AddColumn || sub.Op == parser.DropColumn {
+			rebuilds = true
+		}
+	}
+	notes := "ALGORITHM=INPLACE hint: metadata change in place, concurrent DML allowed."
+	if rebuilds {
+		notes = "ALGORITHM=INPLACE hint: the column change rebuilds the table in place instead of changing metadata only. Concurrent DML allowed."
+	}
+	return DDLClassification{Algorithm: AlgoInplace, Lock: c.Lock, RebuildsTable: rebuilds, Notes: notes}
 }
 
 func analyzeDML(input Input, result *Result) {
@@ -669,27 +2069,51 @@ func analyzeDML(input Input, result *Result) {
 		result.AffectedPct = float64(result.AffectedRows) / float64(input.Meta.RowCount) * 100
 	}
 
-	// Estimate write-set size
-	result.WriteSetSize = result.AffectedRows * input.Meta.AvgRowLength
+	// Estimate write-set size, including the rows foreign keys cascade to in child tables
+	result.WriteSetSize = estimateWriteSet(input, result.AffectedRows)
+	cascades := estimateCascades(input, result.AffectedRows)
+	for _, c := range cascades {
+		result.CascadeRows += c.Rows
+		result.WriteSetSize += c.Bytes
+	}
 
 	// Check for missing WHERE clause
 	if !result.HasWhere && (result.DMLOp == parser.Delete || result.DMLOp == parser.Update) {
 		result.Risk = RiskDangerous
-		result.Warnings = append(result.Warnings, "No WHERE clause! This will affect ALL rows in the table.")
+		result.addWarning(WarnNoWhereClause, "No WHERE clause! This will affect ALL rows in the table.")
+	}
+
+	// Without an EXPLAIN estimate, a WHERE clause reports 0 affected rows, which would read
+	// as SAFE. Make the missing estimate explicit and give the user a way to measure it.
+	if result.HasWhere && input.EstimatedRows <= 0 && (result.DMLOp == parser.Delete || result.DMLOp == parser.Update) {
+		// A join can match a target row more than once: count the target's distinct keys.
+		from, count := input.Parsed.Table, "COUNT(*)"
+		if multiTableDML(input.Parsed) {
+			from = input.Parsed.FromClause
+			if pk := primaryKeyColumns(input.Meta); len(pk) > 0 {
+				count = "COUNT(DISTINCT " + input.Parsed.TargetRef + "." + strings.Join(pk, ", "+input.Parsed.TargetRef+".") + ")"
+			}
+		}
+		result.addWarning(WarnNoExplainEstimate, fmt.Sprintf(
+			"No EXPLAIN row estimate available: affected rows are reported as 0 and the risk level may be understated. Verify with:\n  SELECT %s FROM %s WHERE %s;",
+			count, from, input.Parsed.WhereClause,
+		))
 	}
 
-	// Determine chunking need
+	// Determine chunking need. Cascaded child rows are written and locked in the same
+	// transaction, so they count toward the thresholds.
 	const chunkThreshold int64 = 100000 // 100K rows
+	writtenRows := result.AffectedRows + result.CascadeRows
 	switch {
-	case result.AffectedRows > chunkThreshold:
+	case writtenRows > chunkThreshold:
 		result.Risk = RiskDangerous
 		result.Method = ExecChunked
-		result.ChunkCount = (result.AffectedRows + int64(input.ChunkSize) - 1) / int64(input.ChunkSize)
+		result.ChunkCount = (result.AffectedRows + int64(input.chunkSize()) - 1) / int64(input.chunkSize())
 		result.Recommendation = fmt.Sprintf(
 			"Affecting ~%s rows (%.1f%%). Chunk into batches of %d rows with sleep between chunks to avoid lock contention and replication lag.",
-			formatNumber(result.AffectedRows), result.AffectedPct, input.ChunkSize,
+			formatNumber(result.AffectedRows), result.AffectedPct, input.chunkSize(),
 		)
-	case result.AffectedRows > 10000:
+	case writtenRows > 10000:
 		result.Risk = RiskCaution
 		result.Method = ExecDirect
 		result.Recommendation = fmt.Sprintf(
@@ -707,87 +2131,833 @@ func analyzeDML(input Input, result *Result) {
 		)
 	}
 
+	if len(cascades) > 0 {
+		result.addWarning(WarnFKCascadeAmplification, cascadeWarning(input, result, cascades))
+	}
+	if result.DMLOp == parser.Delete {
+		if msg := restrictChildrenWarning(input); msg != "" {
+			result.addWarning(WarnFKRestrictChildren, msg)
+		}
+	}
+
+	// A large DELETE on a partitioned table may be a partition drop in disguise
+	if result.DMLOp == parser.Delete && result.HasWhere && result.AffectedRows > 10000 {
+		result.Recommendation += partitionDropSuggestion(input, result)
+	}
+
 	// Check triggers
 	for _, trigger := range input.Meta.Triggers {
 		event := strings.ToUpper(trigger.Event)
 		dmlOp := strings.ToUpper(string(result.DMLOp))
 		if event == dmlOp {
-			result.Warnings = append(result.Warnings, fmt.Sprintf(
+			result.addWarning(WarnTriggerFiresPerRow, fmt.Sprintf(
 				"Trigger %s (%s %s) will fire for each affected row. Verify target table can handle the write volume.",
 				trigger.Name, trigger.Timing, trigger.Event,
 			))
 		}
 	}
 
+	if multiTableDML(input.Parsed) {
+		result.addWarning(WarnMultiTableDML, multiTableDMLWarning(input, result))
+	}
+
 	// Generate rollback plan
 	generateDMLRollback(input, result)
 
-	// Generate chunked script if needed
-	if result.Method == ExecChunked {
+	// Generate chunked script if needed. A multi-table DELETE or UPDATE accepts no LIMIT,
+	// and its WHERE can reference the other tables: the warning above says how to chunk it.
+	if result.Method == ExecChunked && !multiTableDML(input.Parsed) {
 		generateChunkedScript(input, result)
+		if result.DMLOp == parser.Delete && result.HasWhere {
+			warnChunkedDeleteGapLocks(input, result)
+		}
 	}
 }
 
-func estimateAffectedRows(input Input) int64 {
-	// If EXPLAIN-based estimate was provided, use it
-	if input.EstimatedRows > 0 {
-		return input.EstimatedRows
-	}
-
-	// Fallback: if no WHERE clause, entire table is affected
-	if !input.Parsed.HasWhere {
-		return input.Meta.RowCount
-	}
-
-	// If no estimate provided and has WHERE, return 0
-	// (caller should provide EXPLAIN estimate for accurate results)
-	return 0
+// cascadeEstimate is the estimated effect of one inbound foreign key's referential action
+// on its child table.
+type cascadeEstimate struct {
+	Child  string // `schema`.`table`
+	Action string // e.g. "ON DELETE CASCADE"
+	Rows   int64
+	Bytes  int64
 }
 
-// applyConvertCharsetClassification refines the DDL matrix baseline for CONVERT TO CHARACTER SET.
-// Per WL#11605: if any indexed string column exists the algorithm must be COPY; otherwise INPLACE
-// is permitted. In both cases MySQL always acquires a SHARED lock — concurrent DML is never allowed.
-func applyConvertCharsetClassification(input Input, result *Result) {
-	// Build set of indexed column names (case-insensitive).
-	indexedCols := make(map[string]bool)
-	for _, idx := range input.Meta.Indexes {
-		for _, col := range idx.Columns {
-			indexedCols[strings.ToLower(col)] = true
-		}
+// estimateCascades estimates the child rows a DELETE or UPDATE of rows target rows changes
+// through inbound foreign keys with CASCADE, SET NULL or SET DEFAULT. An UPDATE only
+// cascades when it sets a referenced column. Children are assumed to spread evenly over the
+// parent rows; only direct children are counted, not the tables they cascade to in turn.
+func estimateCascades(input Input, rows int64) []cascadeEstimate {
+	op := input.Parsed.DMLOp
+	if rows <= 0 || (op != parser.Delete && op != parser.Update) {
+		return nil
 	}
+	var estimates []cascadeEstimate
+	for _, fk := range input.Meta.InboundForeignKeys {
+		rule := fk.DeleteRule
+		if op == parser.Update {
+			rule = fk.UpdateRule
+			if !setsAnyColumn(input.Parsed.UpdateColumns, fk.ReferencedCols) {
+				continue
+			}
+		}
+		rule = strings.ToUpper(rule)
+		if rule != "CASCADE" && rule != "SET NULL" && rule != "SET DEFAULT" {
+			continue
+		}
+		if fk.ChildRows <= 0 {
+			continue
+		}
 
-	// Find which indexed columns are string types.
-	var indexedStringCols []string
-	for _, col := range input.Meta.Columns {
-		if indexedCols[strings.ToLower(col.Name)] && isStringType(col.Type) {
-			indexedStringCols = append(indexedStringCols, col.Name)
+		childRows := fk.ChildRows
+		if input.Meta.RowCount > rows {
+			childRows = int64(float64(fk.ChildRows) * float64(rows) / float64(input.Meta.RowCount))
 		}
+		// A cascaded DELETE logs the child row once; an UPDATE or SET NULL logs it twice.
+		bytes := childRows * fk.ChildAvgRowLen
+		if op == parser.Update || rule != "CASCADE" {
+			bytes *= 2
+		}
+		estimates = append(estimates, cascadeEstimate{
+			Child:  fmt.Sprintf("`%s`.`%s`", fk.ChildSchema, fk.ChildTable),
+			Action: fmt.Sprintf("ON %s %s", strings.ToUpper(string(op)), rule),
+			Rows:   childRows,
+			Bytes:  bytes,
+		})
 	}
+	return estimates
+}
 
-	if len(indexedStringCols) > 0 {
-		result.Classification = DDLClassification{
-			Algorithm:     AlgoCopy,
-			Lock:          LockShared,
-			RebuildsTable: true,
-			Notes: fmt.Sprintf(
-				"COPY algorithm required: indexed string column(s) (%s) cannot have their collation changed INPLACE (WL#11605). Reads allowed, writes blocked during full table rebuild.",
-				strings.Join(indexedStringCols, ", "),
-			),
+// restrictChildrenWarning describes the child tables a DELETE can't remove referenced rows
+// from: inbound foreign keys with ON DELETE RESTRICT or NO ACTION (the default) make it fail
+// with error 1451, and with foreign_key_checks OFF every inbound foreign key is ignored and
+// the child rows are orphaned. References the schema doesn't declare can't be seen. Returns
+// "" when there is nothing to say.
+func restrictChildrenWarning(input Input) string {
+	var parts, deletes []string
+	for _, fk := range input.Meta.InboundForeignKeys {
+		rule := strings.ToUpper(fk.DeleteRule)
+		if rule == "" {
+			rule = "NO ACTION"
 		}
-	} else {
-		result.Classification = DDLClassification{
-			Algorithm:     AlgoInplace,
-			Lock:          LockShared,
-			RebuildsTable: true,
-			Notes:         "INPLACE possible (no indexed string columns), but CONVERT TO CHARACTER SET always acquires SHARED lock — writes are blocked for the entire rebuild regardless of algorithm.",
+		if !input.ForeignKeyChecksDisabled && rule != "RESTRICT" && rule != "NO ACTION" {
+			continue
 		}
-		result.Warnings = append(result.Warnings,
-			"No indexed string columns: INPLACE algorithm is used, but CONVERT TO CHARACTER SET always holds a SHARED lock. Writes are blocked during the entire table rebuild.",
+		child := fmt.Sprintf("`%s`.`%s`", fk.ChildSchema, fk.ChildTable)
+		parts = append(parts, fmt.Sprintf("%s (%s, ON DELETE %s)", child, fk.Name, rule))
+		// A subquery can't read the table its statement deletes from (error 1093), and a
+		// multi-table DELETE's WHERE can reference the other tables of its join.
+		if !strings.EqualFold(fk.ChildTable, input.Meta.Table) && !multiTableDML(input.Parsed) {
+			deletes = append(deletes, childDeleteSQL(input, fk, child))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	if input.ForeignKeyChecksDisabled {
+		return fmt.Sprintf(
+			"foreign_key_checks is OFF in this session: the DELETE ignores the foreign keys referencing `%s` and leaves their child rows orphaned, "+
+				"with no RESTRICT error and no CASCADE or SET NULL: %s. Enable foreign_key_checks, or delete the child rows first.",
+			input.Meta.Table, strings.Join(parts, ", "),
 		)
 	}
+	msg := fmt.Sprintf(
+		"Child tables reference `%s` with no ON DELETE action: %s. The DELETE fails with error 1451 on the first row it reaches that a child row references, "+
+			"and is rolled back; run in chunks, it stops mid-way with the earlier chunks committed. Delete or re-point the child rows first",
+		input.Meta.Table, strings.Join(parts, ", "),
+	)
+	if len(deletes) > 0 {
+		msg += ":\n  " + strings.Join(deletes, "\n  ")
+	} else {
+		msg += "."
+	}
+	msg += "\nReferences the schema doesn't declare as foreign keys (application-level) aren't checked: their rows are orphaned silently."
+	return msg
 }
 
-// isStringType reports whether a MySQL column type is a character string type
+// childDeleteSQL is a DELETE of the child rows of fk that reference the rows the statement deletes.
+func childDeleteSQL(input Input, fk mysql.ForeignKeyInfo, child string) string {
+	cols := "`" + strings.Join(fk.Columns, "`, `") + "`"
+	refs := "`" + strings.Join(fk.ReferencedCols, "`, `") + "`"
+	if len(fk.Columns) > 1 {
+		cols = "(" + cols + ")"
+	}
+	sub := fmt.Sprintf("SELECT %s FROM `%s`.`%s`", refs, input.Meta.Database, input.Meta.Table)
+	if input.Parsed.HasWhere {
+		sub += " WHERE " + input.Parsed.WhereClause
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s);", child, cols, sub)
+}
+
+// setsAnyColumn reports whether any of the SET columns is one of cols.
+func setsAnyColumn(set, cols []string) bool {
+	for _, s := range set {
+		for _, c := range cols {
+			if strings.EqualFold(s, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cascadeWarning describes the child rows the statement changes through foreign keys, and
+// what they add to the write set.
+func cascadeWarning(input Input, result *Result, cascades []cascadeEstimate) string {
+	parts := make([]string, len(cascades))
+	for i, c := range cascades {
+		parts[i] = fmt.Sprintf("~%s rows in %s (%s)", formatNumber(c.Rows), c.Child, c.Action)
+	}
+	msg := fmt.Sprintf(
+		"Foreign keys cascade this %s to child tables: %s. The transaction writes and locks ~%s rows, not the ~%s of `%s` alone, "+
+			"and the estimated write set (~%s) includes them. Child tables that cascade further aren't counted.",
+		strings.ToUpper(string(result.DMLOp)), strings.Join(parts, ", "),
+		formatNumber(result.AffectedRows+result.CascadeRows), formatNumber(result.AffectedRows), result.Table,
+		humanBytes(result.WriteSetSize),
+	)
+	switch input.Topo.Type {
+	case topology.Galera:
+		msg += " On Galera the cascaded rows are part of the replicated write set and count toward wsrep_max_ws_size: size chunks by the total rows, not the target's."
+	case topology.GroupRepl:
+		msg += " On Group Replication they count toward group_replication_transaction_size_limit: size chunks by the total rows, not the target's."
+	}
+	return msg
+}
+
+// multiTableDML reports whether the statement is a DELETE or UPDATE that joins several tables.
+func multiTableDML(p *parser.ParsedSQL) bool {
+	return len(p.JoinTables) > 1
+}
+
+// multiTableDMLWarning describes what the analysis of a multi-table DELETE or UPDATE covers:
+// the first target table only, with a row estimate that spans the join. It also says how to
+// chunk the statement, since multi-table DML accepts no LIMIT.
+func multiTableDMLWarning(input Input, result *Result) string {
+	p := input.Parsed
+	verb := "deletes from"
+	if p.DMLOp == parser.Update {
+		verb = "updates"
+	}
+	targets := p.TargetTables
+	if len(targets) == 0 {
+		targets = []string{p.JoinTables[0]}
+	}
+	msg := fmt.Sprintf(
+		"Multi-table %s joining %s: it %s %s, and the analysis covers `%s` only. "+
+			"The EXPLAIN estimate is the largest row count examined for any table of the join, not the rows changed in `%s`.",
+		p.DMLOp, strings.Join(p.JoinTables, ", "), verb, strings.Join(targets, ", "), result.Table, result.Table,
+	)
+	if len(p.TargetTables) > 1 {
+		msg += " Rows are changed in every target table, so the write set and the rollback backup are larger than shown."
+	}
+	if len(p.TargetTables) == 0 && p.DMLOp == parser.Update {
+		msg += " The SET columns aren't qualified, so the updated table was assumed to be the first one."
+	}
+	msg += " Multi-table DELETE and UPDATE accept no LIMIT or ORDER BY, so the statement can't be chunked with LIMIT"
+	if pk := pkRangeColumn(input.Meta); pk != "" {
+		msg += fmt.Sprintf(
+			": chunk it by ranges of `%[1]s`'s primary key instead, adding AND %[2]s.`%[3]s` BETWEEN @lo AND @hi to the WHERE clause "+
+				"(with the existing condition in parentheses) and walking from SELECT MIN(%[2]s.`%[3]s`) to MAX(%[2]s.`%[3]s`) FROM %[4]s WHERE %[5]s.",
+			result.Table, p.TargetRef, pk, p.FromClause, p.WhereClause,
+		)
+	} else {
+		msg += ": collect the keys of the target rows first, then change them in batches through a single-table statement."
+	}
+	return msg
+}
+
+// warnChunkedDeleteGapLocks flags the chunked DELETE pattern that deadlocks under REPEATABLE
+// READ: each DELETE ... WHERE ... LIMIT takes next-key locks on the gaps of the index the WHERE
+// uses, and concurrent INSERTs into those gaps deadlock with the next chunk.
+func warnChunkedDeleteGapLocks(input Input, result *Result) {
+	switch strings.ToUpper(strings.ReplaceAll(input.TxIsolation, " ", "-")) {
+	case "READ-COMMITTED", "READ-UNCOMMITTED":
+		return
+	}
+	isolation := "REPEATABLE READ"
+	if strings.EqualFold(input.TxIsolation, "SERIALIZABLE") {
+		isolation = "SERIALIZABLE"
+	}
+
+	warning := fmt.Sprintf(
+		"Chunked DELETE under %s: DELETE ... WHERE ... LIMIT takes next-key (gap) locks on the index used by the WHERE clause, "+
+			"which can deadlock with concurrent INSERTs into the same range. Run the script in a session with "+
+			"SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED",
+		isolation,
+	)
+	pk := primaryKeyColumns(input.Meta)
+	switch {
+	case pkRangeColumn(input.Meta) != "":
+		warning += fmt.Sprintf("; the generated script already deletes by explicit ranges of the primary key `%s` instead of LIMIT.", pk[0])
+	case len(pk) > 0:
+		warning += fmt.Sprintf(", or walk the primary key (`%s`) in order and delete by explicit key ranges instead of LIMIT.", strings.Join(pk, "`, `"))
+	default:
+		warning += "; the table has no primary key to delete by explicit ranges instead."
+	}
+	result.addWarning(WarnChunkedDeleteGapLocks, warning)
+}
+
+var (
+	reBacktickedIdent = regexp.MustCompile("`([^`]+)`")
+	rePlainIdent      = regexp.MustCompile(`^\w+$`)
+)
+
+// partitionDropSuggestion returns the recommendation suffix for a DELETE on a RANGE or LIST
+// partitioned table whose WHERE clause is a single comparison on the partitioning column:
+// dropping or truncating the partitions it covers is a metadata operation, with no binlog
+// row events and no undo. For a RANGE on the bare column and a WHERE of the form
+// `key < value`, the partitions entirely below value are named. Any other condition in
+// the WHERE (AND, OR) selects fewer rows than the partitions hold, so there is nothing to
+// suggest: dropping them would delete rows the statement keeps. Returns "" in that case.
+func partitionDropSuggestion(input Input, result *Result) string {
+	part := input.Meta.Partitioning
+	if part == nil {
+		return ""
+	}
+	method := strings.ToUpper(part.Method)
+	if !strings.HasPrefix(method, "RANGE") && !strings.HasPrefix(method, "LIST") {
+		return "" // HASH and KEY spread every range of values over all partitions
+	}
+	key, bare := partitionKeyColumn(part.Expression)
+	if key == "" {
+		return ""
+	}
+	column, op, value, ok := parser.ColumnComparison(input.Parsed.WhereClause)
+	if !ok || !strings.EqualFold(column, key) {
+		return ""
+	}
+
+	table := "`" + result.Table + "`"
+	if bare && strings.HasPrefix(method, "RANGE") {
+		if covered, rows, exact := partitionsBelow(part.Partitions, op, value); len(covered) > 0 {
+			s := fmt.Sprintf(
+				" The table is %s partitioned on `%s`: ALTER TABLE %s DROP PARTITION %s removes the ~%s rows of the partitions entirely inside the WHERE range "+
+					"as a metadata operation, with no binlog row events and no undo (TRUNCATE PARTITION empties them and keeps the definitions).",
+				method, key, table, strings.Join(covered, ", "), formatNumber(rows),
+			)
+			if !exact {
+				s += " DELETE only the remaining rows, from the next partition."
+			}
+			return s
+		}
+	}
+	return fmt.Sprintf(
+		" The table is %s partitioned on %s, which the WHERE clause filters on: if the rows to delete fill whole partitions (EXPLAIN shows the partitions read), "+
+			"ALTER TABLE %s DROP PARTITION or TRUNCATE PARTITION removes them as a metadata operation, with no binlog row events and no undo.",
+		method, part.Expression, table,
+	)
+}
+
+// partitionKeyColumn returns the single column a partitioning expression uses, and whether
+// the expression is that column alone (not a function of it). Returns "" when the
+// expression uses several columns.
+func partitionKeyColumn(expr string) (string, bool) {
+	expr = strings.TrimSpace(expr)
+	if rePlainIdent.MatchString(expr) {
+		return expr, true
+	}
+	matches := reBacktickedIdent.FindAllStringSubmatch(expr, -1)
+	if len(matches) == 0 {
+		return "", false
+	}
+	key := matches[0][1]
+	for _, m := range matches[1:] {
+		if !strings.EqualFold(m[1], key) {
+			return "", false
+		}
+	}
+	return key, expr == "`"+key+"`"
+}
+
+// partitionsBelow returns the leading RANGE partitions whose LESS THAN bound is at most
+// value, for a WHERE clause of the form `key op value` with op < or <=, with their row
+// count, and whether they hold exactly the rows the WHERE selects.
+func partitionsBelow(partitions []mysql.Partition, op, value string) ([]string, int64, bool) {
+	if op != "<" && op != "<=" {
+		return nil, 0, false
+	}
+
+	var names []string
+	var rows int64
+	for _, p := range partitions {
+		cmp, ok := compareRangeBound(p.Description, value)
+		if !ok || cmp > 0 {
+			break
+		}
+		names = append(names, p.Name)
+		rows += p.RowCount
+		if cmp == 0 {
+			// key < bound selects exactly these partitions; key <= bound also the bound itself
+			return names, rows, op == "<"
+		}
+	}
+	return names, rows, false
+}
+
+// compareRangeBound compares a partition's LESS THAN bound with a literal from the WHERE
+// clause: both numbers, or both quoted strings (which orders ISO dates correctly).
+func compareRangeBound(bound, value string) (int, bool) {
+	if strings.EqualFold(bound, "MAXVALUE") {
+		return 1, true
+	}
+	b, errB := strconv.ParseFloat(bound, 64)
+	v, errV := strconv.ParseFloat(value, 64)
+	if errB == nil && errV == nil {
+		switch {
+		case b < v:
+			return -1, true
+		case b > v:
+			return 1, true
+		}
+		return 0, true
+	}
+	unquote := func(s string) (string, bool) {
+		if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+			return s[1 : len(s)-1], true
+		}
+		return "", false
+	}
+	bs, okB := unquote(bound)
+	vs, okV := unquote(value)
+	if !okB || !okV {
+		return 0, false
+	}
+	return strings.Compare(bs, vs), true
+}
+
+func estimateAffectedRows(input Input) int64 {
+	// If EXPLAIN-based estimate was provided, use it. This is the optimizer's
+	// estimate from index statistics, not an exact count.
+	if input.EstimatedRows > 0 {
+		return input.EstimatedRows
+	}
+
+	// Fallback: if no WHERE clause, entire table is affected
+	if !input.Parsed.HasWhere {
+		return input.Meta.RowCount
+	}
+
+	// If no estimate provided and has WHERE, return 0
+	// (caller should provide EXPLAIN estimate for accurate results)
+	return 0
+}
+
+// applyConvertCharsetClassification refines the DDL matrix baseline for CONVERT TO CHARACTER SET.
+// Per WL#11605: if any indexed string column is converted the algorithm must be COPY; otherwise
+// INPLACE is permitted. In both cases MySQL always acquires a SHARED lock — concurrent DML is
+// never allowed. CONVERT TO converts every string column, explicit CHARACTER SET or not, except
+// those that already have the target charset and collation.
+func applyConvertCharsetClassification(input Input, result *Result) {
+	// Build set of indexed column names (case-insensitive).
+	indexedCols := make(map[string]bool)
+	for _, idx := range input.Meta.Indexes {
+		for _, col := range idx.Columns {
+			indexedCols[strings.ToLower(col)] = true
+		}
+	}
+
+	// Find which indexed columns are string types the conversion changes.
+	var indexedStringCols, unchanged []string
+	for _, col := range input.Meta.Columns {
+		if !indexedCols[strings.ToLower(col.Name)] || !isStringType(col.Type) {
+			continue
+		}
+		if convertLeavesColumn(col, input.Parsed.NewCharset, input.Parsed.NewCollation) {
+			unchanged = append(unchanged, col.Name)
+			continue
+		}
+		indexedStringCols = append(indexedStringCols, col.Name)
+	}
+	skipped := ""
+	if len(unchanged) > 0 {
+		skipped = fmt.Sprintf(" Indexed column(s) %s already use the target charset and collation and aren't converted.", strings.Join(unchanged, ", "))
+	}
+
+	if len(indexedStringCols) > 0 {
+		result.reclassify("CONVERT TO CHARACTER SET with indexed string columns", DDLClassification{
+			Algorithm:     AlgoCopy,
+			Lock:          LockShared,
+			RebuildsTable: true,
+			Notes: fmt.Sprintf(
+				"COPY algorithm required: indexed string column(s) (%s) cannot have their collation changed INPLACE (WL#11605). Reads allowed, writes blocked during full table rebuild.%s "+
+					"If only some columns need the new charset, MODIFY those columns instead of converting the whole table.",
+				strings.Join(indexedStringCols, ", "), skipped,
+			),
+		})
+	} else {
+		result.reclassify("CONVERT TO CHARACTER SET without indexed string columns", DDLClassification{
+			Algorithm:     AlgoInplace,
+			Lock:          LockShared,
+			RebuildsTable: true,
+			Notes:         "INPLACE possible (no indexed string columns are converted), but CONVERT TO CHARACTER SET always acquires SHARED lock — writes are blocked for the entire rebuild regardless of algorithm." + skipped,
+		})
+		result.addWarning(WarnConvertCharsetSharedLock,
+			"No indexed string columns: INPLACE algorithm is used, but CONVERT TO CHARACTER SET always holds a SHARED lock. Writes are blocked during the entire table rebuild.",
+		)
+	}
+}
+
+// convertLeavesColumn reports whether CONVERT TO CHARACTER SET charset [COLLATE collation]
+// leaves the column as it is: it already has the charset and the collation the conversion
+// gives it (the COLLATE clause, or the charset's default). A column whose charset or
+// collation is unknown is assumed to be converted.
+func convertLeavesColumn(col mysql.ColumnInfo, charset, collation string) bool {
+	if charset == "" || col.CharacterSet == nil || col.Collation == nil ||
+		normalizeCharset(*col.CharacterSet) != normalizeCharset(charset) {
+		return false
+	}
+	if collation == "" {
+		collation = defaultCollations[normalizeCharset(charset)]
+	}
+	return collation != "" && normalizeCharset(*col.Collation) == normalizeCharset(collation)
+}
+
+// normalizeCharset maps the utf8 alias to utf8mb3, the name 8.0.30+ reports, in a charset
+// or collation name (utf8_general_ci → utf8mb3_general_ci).
+func normalizeCharset(name string) string {
+	name = strings.ToLower(name)
+	if name == "utf8" || strings.HasPrefix(name, "utf8_") {
+		return "utf8mb3" + strings.TrimPrefix(name, "utf8")
+	}
+	return name
+}
+
+// defaultCollations are the MySQL 8.0 default collations of the common charsets.
+var defaultCollations = map[string]string{
+	"utf8mb4": "utf8mb4_0900_ai_ci",
+	"utf8mb3": "utf8mb3_general_ci",
+	"latin1":  "latin1_swedish_ci",
+	"ascii":   "ascii_general_ci",
+	"binary":  "binary",
+	"ucs2":    "ucs2_general_ci",
+	"utf16":   "utf16_general_ci",
+	"utf32":   "utf32_general_ci",
+}
+
+// InnoDB and server limits that an ALTER can run into at execution time.
+const (
+	innodbMaxKeyBytes   = 3072  // index key length limit for DYNAMIC/COMPRESSED row formats
+	innodbSmallKeyBytes = 767   // index key length limit for REDUNDANT/COMPACT row formats
+	innodbMaxColumns    = 1017  // columns per table
+	mysqlMaxRowBytes    = 65535 // server row-size limit, BLOB/TEXT counted as 9-12 byte pointers
+	innodbHalfPageBytes = 8126  // max in-page record size with 16KB pages
+	innodbPrefixBytes   = 768   // in-page prefix of long columns in REDUNDANT/COMPACT
+
+	innodbOffPageRefBytes = 40 // in-page bytes InnoDB reserves for an off-page column in DYNAMIC
+)
+
+// applyColumnLimits tallies the net column change of an ALTER that adds, drops or redefines
+// columns against the live table and warns when the result approaches the InnoDB column
+// limit or exceeds the row-size limits. Such ALTERs often classify as INSTANT but fail at
+// execution time. The row-size checks are skipped when any column width can't be
+// determined. Column counts are recorded in the result for multi-op ALTERs only.
+func applyColumnLimits(input Input, result *Result) {
+	if input.Meta == nil || len(input.Meta.Columns) == 0 {
+		return
+	}
+
+	type colWidth struct {
+		bytes   int
+		compact int // bytes stored in the clustered index page for REDUNDANT/COMPACT
+		dynamic int // bytes stored in the clustered index page for DYNAMIC
+	}
+	widthOf := func(colType, charset string) (colWidth, bool) {
+		n, ok := columnByteWidth(colType, charset)
+		if !ok {
+			return colWidth{}, false
+		}
+		w := colWidth{bytes: n, compact: min(n, innodbPrefixBytes), dynamic: n}
+		if isBlobType(colType) {
+			w.compact = innodbPrefixBytes
+		}
+		// DYNAMIC keeps columns up to 255 bytes in the row; longer ones can move off-page.
+		if isBlobType(colType) || n > 255 {
+			w.dynamic = innodbOffPageRefBytes
+		}
+		return w, true
+	}
+
+	widths := make(map[string]colWidth, len(input.Meta.Columns))
+	widthsKnown := true
+	for _, col := range input.Meta.Columns {
+		charset := ""
+		if col.CharacterSet != nil {
+			charset = *col.CharacterSet
+		}
+		w, ok := widthOf(col.Type, charset)
+		widthsKnown = widthsKnown && ok
+		widths[strings.ToLower(col.Name)] = w
+	}
+
+	for _, subOp := range input.Parsed.SubOperations {
+		switch subOp.Op {
+		case parser.AddColumn:
+			w, ok := widthOf(subOp.NewColumnType, subOp.NewColumnCharset)
+			widthsKnown = widthsKnown && ok
+			widths[strings.ToLower(subOp.ColumnName)] = w
+		case parser.DropColumn:
+			delete(widths, strings.ToLower(subOp.ColumnName))
+		case parser.ModifyColumn, parser.ChangeColumn:
+			if subOp.NewColumnType == "" {
+				continue
+			}
+			name := subOp.ColumnName
+			if subOp.OldColumnName != "" {
+				name = subOp.OldColumnName
+			}
+			delete(widths, strings.ToLower(name))
+			w, ok := widthOf(subOp.NewColumnType, subOp.NewColumnCharset)
+			widthsKnown = widthsKnown && ok
+			widths[strings.ToLower(subOp.ColumnName)] = w
+		}
+	}
+
+	before, after := len(input.Meta.Columns), len(widths)
+	if input.Parsed.DDLOp == parser.MultipleOps {
+		result.ColumnsBefore, result.ColumnsAfter = before, after
+	}
+
+	switch {
+	case after > innodbMaxColumns:
+		result.Risk = RiskDangerous
+		result.addWarning(WarnTooManyColumns, fmt.Sprintf(
+			"Table would have %d columns after this ALTER (currently %d), over the InnoDB limit of %d. The ALTER will fail with \"Too many columns\".",
+			after, before, innodbMaxColumns,
+		))
+	case after > before && after >= innodbMaxColumns*9/10:
+		result.addWarning(WarnColumnLimitApproaching, fmt.Sprintf(
+			"Table would have %d columns after this ALTER (currently %d), approaching the InnoDB limit of %d.",
+			after, before, innodbMaxColumns,
+		))
+	}
+
+	if !widthsKnown {
+		return
+	}
+	var rowBytes, compactBytes, dynamicBytes int
+	for _, w := range widths {
+		rowBytes += w.bytes
+		compactBytes += w.compact
+		dynamicBytes += w.dynamic
+	}
+	if rowBytes > mysqlMaxRowBytes {
+		result.Risk = RiskDangerous
+		result.addWarning(WarnRowSizeTooLarge, fmt.Sprintf(
+			"Maximum row size after this ALTER is ~%d bytes, over the %d-byte limit. The ALTER will fail with \"Row size too large\"; convert some VARCHAR columns to TEXT or BLOB.",
+			rowBytes, mysqlMaxRowBytes,
+		))
+		return
+	}
+	switch rowFormat := strings.ToLower(input.Meta.RowFormat); rowFormat {
+	case "redundant", "compact":
+		if compactBytes > innodbHalfPageBytes {
+			result.addWarning(WarnRecordSizeTooLarge, fmt.Sprintf(
+				"Worst-case in-page record size after this ALTER is ~%d bytes with ROW_FORMAT=%s, over the %d-byte InnoDB limit: long columns keep a %d-byte prefix in the row, TEXT and BLOB included. "+
+					"With innodb_strict_mode=ON the ALTER will fail with \"Row size too large\", even an INSTANT ADD COLUMN; consider ROW_FORMAT=DYNAMIC, which moves long columns off-page entirely.",
+				compactBytes, strings.ToUpper(rowFormat), innodbHalfPageBytes, innodbPrefixBytes,
+			))
+		}
+	case "dynamic", "":
+		if dynamicBytes > innodbHalfPageBytes {
+			result.addWarning(WarnRecordSizeTooLarge, fmt.Sprintf(
+				"Worst-case in-page record size after this ALTER is ~%d bytes with ROW_FORMAT=DYNAMIC, over the %d-byte InnoDB limit: columns of up to 255 bytes always stay in the row. "+
+					"With innodb_strict_mode=ON the ALTER will fail with \"Row size too large\", even an INSTANT ADD COLUMN; convert some of the short VARCHAR or CHAR columns to TEXT, or widen them past 255 bytes, so they can move off-page.",
+				dynamicBytes, innodbHalfPageBytes,
+			))
+		}
+	}
+}
+
+// columnByteWidth returns the maximum number of bytes a column of the given type counts
+// toward the row-size limit. BLOB, TEXT and JSON count only their 9-12 byte pointer.
+// Returns (0, false) for types it doesn't recognize.
+func columnByteWidth(colType, charset string) (int, bool) {
+	s := strings.ToLower(strings.TrimSpace(colType))
+	base, args := s, ""
+	if i := strings.Index(s, "("); i >= 0 {
+		base = s[:i]
+		if j := strings.Index(s[i:], ")"); j >= 0 {
+			args = s[i+1 : i+j]
+		}
+	}
+	if fields := strings.Fields(base); len(fields) > 0 {
+		base = fields[0] // drop UNSIGNED, ZEROFILL, etc.
+	}
+	var n, d int
+	if args != "" {
+		fmt.Sscanf(args, "%d,%d", &n, &d)
+	}
+
+	switch base {
+	case "tinyint", "bool", "boolean", "year":
+		return 1, true
+	case "smallint":
+		return 2, true
+	case "mediumint", "date":
+		return 3, true
+	case "int", "integer", "float":
+		return 4, true
+	case "bigint", "double", "real":
+		return 8, true
+	case "decimal", "numeric", "dec", "fixed":
+		if args == "" {
+			n = 10
+		}
+		return decimalBytes(n-d) + decimalBytes(d), true
+	case "bit":
+		if args == "" {
+			n = 1
+		}
+		return (n + 7) / 8, true
+	case "time":
+		return 3 + (n+1)/2, true
+	case "datetime":
+		return 5 + (n+1)/2, true
+	case "timestamp":
+		return 4 + (n+1)/2, true
+	case "char":
+		if args == "" {
+			n = 1
+		}
+		return n * maxBytesPerChar(charset), true
+	case "binary":
+		if args == "" {
+			n = 1
+		}
+		return n, true
+	case "varchar":
+		return n*maxBytesPerChar(charset) + varcharLengthPrefixBytes(n, charset), true
+	case "varbinary":
+		if n <= 255 {
+			return n + 1, true
+		}
+		return n + 2, true
+	case "enum":
+		return 2, true
+	case "set":
+		return 8, true
+	case "tinytext", "tinyblob":
+		return 9, true
+	case "text", "blob":
+		return 10, true
+	case "mediumtext", "mediumblob":
+		return 11, true
+	case "longtext", "longblob", "json":
+		return 12, true
+	}
+	return 0, false
+}
+
+// decimalBytes returns the storage size of a run of decimal digits: 4 bytes per 9 digits
+// plus 0-4 bytes for the remainder.
+func decimalBytes(digits int) int {
+	leftover := [...]int{0, 1, 1, 2, 2, 3, 3, 4, 4, 4}
+	return digits/9*4 + leftover[digits%9]
+}
+
+// isBlobType reports whether a column type is stored off-page beyond its in-row prefix.
+func isBlobType(colType string) bool {
+	lower := strings.ToLower(colType)
+	return strings.Contains(lower, "text") || strings.Contains(lower, "blob") || strings.HasPrefix(lower, "json")
+}
+
+// convertCharsetKeyLengthWarnings returns a warning for each index whose worst-case key
+// length would exceed the row format's key limit after CONVERT TO a wider character set. MySQL
+// rejects such an ALTER with "Specified key was too long" after it has started.
+// Only CHAR/VARCHAR columns and prefixed string columns are counted; other key parts
+// are ignored, so the computed length is a lower bound for mixed indexes.
+func convertCharsetKeyLengthWarnings(input Input) []Warning {
+	newCharset := input.Parsed.NewCharset
+	if newCharset == "" {
+		return nil
+	}
+	newMB := maxBytesPerChar(newCharset)
+	maxKey, limit := maxKeyBytes(input.Meta.RowFormat)
+
+	columns := make(map[string]mysql.ColumnInfo, len(input.Meta.Columns))
+	for _, col := range input.Meta.Columns {
+		columns[strings.ToLower(col.Name)] = col
+	}
+
+	var warnings []Warning
+	for _, idx := range input.Meta.Indexes {
+		if strings.EqualFold(idx.Type, "FULLTEXT") || strings.EqualFold(idx.Type, "SPATIAL") {
+			continue
+		}
+
+		var newBytes int
+		widens := false
+		widest, widestBytes := "", 0
+		for i, name := range idx.Columns {
+			col, ok := columns[strings.ToLower(name)]
+			if !ok || !isStringType(col.Type) {
+				continue
+			}
+			chars := 0
+			if i < len(idx.SubParts) && idx.SubParts[i] > 0 {
+				chars = idx.SubParts[i]
+			} else if n, ok := extractStringLength(col.Type); ok {
+				chars = n
+			}
+			if col.CharacterSet == nil || maxBytesPerChar(*col.CharacterSet) < newMB {
+				widens = true
+			}
+			newBytes += chars * newMB
+			if chars*newMB > widestBytes {
+				widest, widestBytes = col.Name, chars*newMB
+			}
+		}
+
+		if !widens || newBytes <= maxKey {
+			continue
+		}
+		msg := fmt.Sprintf(
+			"Index '%s' would need up to %d bytes per key in %s, over the %s of %d bytes. The ALTER will fail with \"Specified key was too long\". ",
+			idx.Name, newBytes, newCharset, limit, maxKey,
+		)
+		// Prefixing the widest column is enough only if the others leave room for a character.
+		if prefix := (maxKey - (newBytes - widestBytes)) / newMB; prefix >= 1 {
+			msg += fmt.Sprintf("Shorten it with a prefix index first, e.g. %s(%d).", widest, prefix)
+		} else {
+			msg += fmt.Sprintf("Its other columns alone take %d bytes, so shorten several of them with prefixes, or drop columns from the index, first.", newBytes-widestBytes)
+		}
+		warnings = append(warnings, newWarning(WarnKeyTooLong, msg))
+	}
+	return warnings
+}
+
+// maxKeyBytes returns the InnoDB index key length limit for a row format and a
+// description of it for warnings. An unknown row format is taken to be DYNAMIC,
+// the default since MySQL 5.7.
+func maxKeyBytes(rowFormat string) (int, string) {
+	switch rowFormat = strings.ToUpper(rowFormat); rowFormat {
+	case "REDUNDANT", "COMPACT":
+		return innodbSmallKeyBytes, "InnoDB limit for ROW_FORMAT=" + rowFormat
+	default:
+		return innodbMaxKeyBytes, "InnoDB limit"
+	}
+}
+
+// extractStringLength parses the character length N from "varchar(N)" or "char(N)".
+// Returns (0, false) for other types, including the TEXT family, which can only be
+// indexed with a prefix.
+func extractStringLength(typeStr string) (int, bool) {
+	if n, ok := extractVarcharLength(typeStr); ok {
+		return n, true
+	}
+	s := strings.TrimSpace(strings.ToLower(typeStr))
+	if !strings.HasPrefix(s, "char(") {
+		return 0, false
+	}
+	var n int
+	if _, err := fmt.Sscanf(s[len("char("):], "%d", &n); err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// isStringType reports whether a MySQL column type is a character string type
 // (varchar, char, text family, enum, set) that participates in character set encoding.
 func isStringType(colType string) bool {
 	lower := strings.ToLower(colType)
@@ -799,19 +2969,63 @@ func isStringType(colType string) bool {
 	return false
 }
 
+// expressionDefaultWarning explains why an ADD COLUMN with a DEFAULT (expr) clause should
+// not be trusted to run as INSTANT without verification.
+func expressionDefaultWarning(column string) string {
+	return fmt.Sprintf(
+		"Column '%s' has an expression DEFAULT: it is evaluated per row for every existing row, "+
+			"and nondeterministic expressions such as UUID() or RAND() may not be INSTANT-eligible on all versions. "+
+			"Verify by running the ALTER with ALGORITHM=INSTANT on a staging copy; MySQL rejects it if INSTANT is not possible.",
+		column,
+	)
+}
+
+// isLOBType reports whether a column type is stored as a large object, off-page when the
+// row doesn't fit in the page: TEXT, BLOB and JSON, but not TINYTEXT or TINYBLOB, whose
+// 255 bytes always fit.
+func isLOBType(colType string) bool {
+	switch strings.ToLower(strings.TrimSpace(colType)) {
+	case "text", "blob", "mediumtext", "mediumblob", "longtext", "longblob", "json":
+		return true
+	}
+	return false
+}
+
+// lobBackfillNote explains that an INSTANT ADD COLUMN of a large object type defers the
+// storage cost to the column's backfill instead of avoiding it.
+func lobBackfillNote(column, colType string, hasDefault bool) string {
+	msg := fmt.Sprintf(
+		"Adding %s column `%s` is INSTANT because no row is rewritten, but the cost is deferred, not avoided: filling the column later is an UPDATE of every row, "+
+			"which rewrites each one and writes undo, redo and binlog for all of them. Values that don't fit in the row (about half a 16 KB page with ROW_FORMAT=DYNAMIC) "+
+			"are stored off-page in pages of their own, so the tablespace grows by more than the data. Backfill in chunks (dbsafe plan on the UPDATE generates a chunked script).",
+		strings.ToUpper(colType), column,
+	)
+	if hasDefault {
+		msg += " Its default is kept in the table definition: existing rows show it without storing it, and store it when they are next updated, so a large default grows the table gradually."
+	}
+	return msg
+}
+
 // classifySubOp returns the DDL classification and any warnings for a single sub-operation
 // within a multi-op ALTER TABLE, applying the same live-metadata refinements as analyzeDDL.
-func classifySubOp(subOp parser.SubOperation, meta *mysql.TableMetadata, fkChecksDisabled bool, v mysql.ServerVersion) (DDLClassification, []string) {
-	var warnings []string
+func classifySubOp(subOp parser.SubOperation, meta *mysql.TableMetadata, fkChecksDisabled bool, v mysql.ServerVersion) (DDLClassification, []Warning) {
+	var warnings []Warning
 
 	// Matrix baseline — with the AUTO_INCREMENT override handled up front.
 	var cls DDLClassification
 	if subOp.Op == parser.AddColumn && subOp.HasAutoIncrement {
 		cls = DDLClassification{Algorithm: AlgoInplace, Lock: LockShared, RebuildsTable: true,
 			Notes: "ADD COLUMN with AUTO_INCREMENT: INPLACE with SHARED lock minimum. Full table rebuild required."}
+		if warn, ok := secondAutoIncrementWarning(meta, subOp.ColumnName); ok {
+			warnings = append(warnings, newWarning(WarnSecondAutoIncrement, warn))
+		}
 	} else {
 		cls = ClassifyDDL(subOp.Op, v.Major, v.Minor, v.EffectivePatch())
 	}
+	if subOp.Op == parser.AddColumn && subOp.HasUniqueKey && cls.Algorithm == AlgoInstant {
+		cls = DDLClassification{Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: true,
+			Notes: "ADD COLUMN with a UNIQUE key: the index build rules out INSTANT."}
+	}
 
 	switch subOp.Op {
 	case parser.AddColumn:
@@ -819,7 +3033,13 @@ func classifySubOp(subOp parser.SubOperation, meta *mysql.TableMetadata, fkCheck
 		if subOp.IsGeneratedStored {
 			cls = DDLClassification{Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true,
 				Notes: "ADD STORED generated column requires COPY algorithm."}
-			warnings = append(warnings, "STORED generated column in compound ALTER: COPY with LOCK=SHARED required.")
+			warnings = append(warnings, newWarning(WarnStoredGeneratedColumnCopy, "STORED generated column in compound ALTER: COPY with LOCK=SHARED required."))
+		}
+		if subOp.HasExpressionDefault {
+			warnings = append(warnings, newWarning(WarnExpressionDefault, expressionDefaultWarning(subOp.ColumnName)))
+		}
+		if isLOBType(subOp.NewColumnType) && cls.Algorithm == AlgoInstant {
+			warnings = append(warnings, newWarning(WarnLOBBackfillDeferred, lobBackfillNote(subOp.ColumnName, subOp.NewColumnType, subOp.HasExpressionDefault)))
 		}
 
 	case parser.DropColumn:
@@ -839,9 +3059,9 @@ func classifySubOp(subOp parser.SubOperation, meta *mysql.TableMetadata, fkCheck
 						if strings.EqualFold(idxCol, subOp.ColumnName) {
 							cls = DDLClassification{Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: true,
 								Notes: fmt.Sprintf("DROP COLUMN on indexed column '%s': INPLACE with rebuild.", subOp.ColumnName)}
-							warnings = append(warnings, fmt.Sprintf(
+							warnings = append(warnings, newWarning(WarnDropIndexedColumnRebuild, fmt.Sprintf(
 								"Column '%s' is part of index '%s'. Consider dropping the index first.",
-								subOp.ColumnName, idx.Name))
+								subOp.ColumnName, idx.Name)))
 							break
 						}
 					}
@@ -852,75 +3072,811 @@ func classifySubOp(subOp parser.SubOperation, meta *mysql.TableMetadata, fkCheck
 			}
 		}
 
-	case parser.ChangeColumn:
-		if subOp.OldColumnName != "" && subOp.NewColumnType != "" && meta != nil {
-			oldType := findColumnType(meta.Columns, subOp.OldColumnName)
-			if oldType != "" && !strings.EqualFold(
-				strings.ReplaceAll(oldType, " ", ""),
-				strings.ReplaceAll(subOp.NewColumnType, " ", ""),
-			) {
-				cls = DDLClassification{Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true,
-					Notes: "Data type change requires COPY algorithm."}
-				warnings = append(warnings, fmt.Sprintf(
-					"Column '%s' type change detected: %s → %s. COPY algorithm required.",
-					subOp.OldColumnName, oldType, subOp.NewColumnType,
-				))
-			}
+	case parser.ChangeColumn:
+		if subOp.OldColumnName != "" && subOp.NewColumnType != "" && meta != nil {
+			oldType := findColumnType(meta.Columns, subOp.OldColumnName)
+			if oldType != "" && !strings.EqualFold(
+				strings.ReplaceAll(oldType, " ", ""),
+				strings.ReplaceAll(subOp.NewColumnType, " ", ""),
+			) {
+				cls = DDLClassification{Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true,
+					Notes: "Data type change requires COPY algorithm."}
+				warnings = append(warnings, newWarning(WarnColumnTypeChangeCopy, fmt.Sprintf(
+					"Column '%s' type change detected: %s → %s. COPY algorithm required.",
+					subOp.OldColumnName, oldType, subOp.NewColumnType,
+				)))
+			}
+		}
+
+	case parser.ModifyColumn:
+		if subOp.NewColumnType != "" && meta != nil {
+			oldType := findColumnType(meta.Columns, subOp.ColumnName)
+			if oldType != "" {
+				if warn, ok := numericNarrowingWarning(meta.Table, subOp.ColumnName, oldType, subOp.NewColumnType); ok {
+					warnings = append(warnings, newWarning(WarnNumericNarrowing, warn))
+				}
+				if subOp.NewColumnCharset == "" {
+					// No charset change: try INSTANT/INPLACE optimizations.
+					if c, ok := classifyModifyColumnEnum(oldType, subOp.NewColumnType); ok {
+						cls = c
+					} else {
+						charset := findColumnCharset(meta.Columns, subOp.ColumnName)
+						if c, ok := classifyModifyColumnVarchar(oldType, subOp.NewColumnType, charset); ok {
+							cls = c
+						}
+					}
+				}
+			}
+			if c, warn, ok := generationExprChange(meta.Columns, subOp.ColumnName, subOp.NewColumnType,
+				subOp.NewGenerationExpr, subOp.IsGeneratedStored); ok {
+				cls = c
+				if warn != "" {
+					warnings = append(warnings, newWarning(WarnGeneratedExprChangeCopy, warn))
+				}
+			}
+		}
+
+	case parser.AddPrimaryKey:
+		if len(subOp.IndexColumns) > 0 && meta != nil {
+			for _, colName := range subOp.IndexColumns {
+				for _, col := range meta.Columns {
+					if strings.EqualFold(col.Name, colName) && col.Nullable {
+						cls = DDLClassification{Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true,
+							Notes: "Nullable PK column requires COPY."}
+						warnings = append(warnings, newWarning(WarnNullablePKForcesCopy, fmt.Sprintf(
+							"Column '%s' is nullable: ADD PRIMARY KEY on a nullable column requires COPY algorithm.",
+							colName,
+						)))
+						break
+					}
+				}
+				if cls.Algorithm == AlgoCopy {
+					break
+				}
+			}
+			if nullable := nullableColumns(meta, subOp.IndexColumns); len(nullable) > 0 {
+				warnings = append(warnings, newWarning(WarnNullablePKNullValues, nullablePKNullValuesWarning(meta.Table, nullable)))
+			}
+		}
+
+	case parser.AddForeignKey:
+		if !fkChecksDisabled {
+			cls = DDLClassification{Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: false,
+				Notes: "ADD FOREIGN KEY with foreign_key_checks=ON requires COPY."}
+			warnings = append(warnings, newWarning(WarnFKChecksOnForcesCopy, "foreign_key_checks=ON: COPY algorithm required for ADD FOREIGN KEY."))
+		}
+
+	case parser.AddCheckConstraint:
+		if subOp.CheckNotEnforced {
+			cls = notEnforcedCheckClassification()
+		}
+
+	case parser.AddFulltextIndex:
+		if meta != nil {
+			if hasFTSDocID(meta) {
+				cls = subsequentFulltextClassification()
+			} else {
+				warnings = append(warnings, newWarning(WarnFirstFulltextIndexRebuild, firstFulltextWarning(meta)))
+			}
+		}
+
+	case parser.ChangeIndexVisibility:
+		if subOp.IndexInvisible {
+			warnings = append(warnings, newWarning(WarnInvisibleIndexTrial, invisibleIndexNote(subOp.IndexName)))
+		}
+
+	case parser.ChangeEngine:
+		if subOp.NewEngine != "" && meta != nil && strings.EqualFold(subOp.NewEngine, meta.Engine) {
+			cls = DDLClassification{Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: true,
+				Notes: "ENGINE=<same engine>: equivalent to ALTER TABLE ... FORCE. INPLACE rebuild."}
+		}
+	}
+
+	if isCompressedTable(meta) {
+		if c, ok := compressedRowFormatClassification(subOp.Op, cls); ok {
+			cls = c
+			warnings = append(warnings, newWarning(WarnCompressedInstantFallback, compressedInstantFallbackWarning(subOp.Op)))
+		}
+	}
+
+	if subOp.Op == parser.ModifyColumn || subOp.Op == parser.ChangeColumn {
+		column := subOp.ColumnName
+		if subOp.OldColumnName != "" {
+			column = subOp.OldColumnName
+		}
+		table := ""
+		if meta != nil {
+			table = meta.Table
+		}
+		if c, warn, ok := spatialSRIDChange(meta, table, column, subOp.NewColumnType, subOp.NewColumnSRID); ok {
+			cls = c
+			warnings = append(warnings, newWarning(WarnSpatialSRIDValidation, warn))
+		}
+	}
+
+	return cls, warnings
+}
+
+// hasFTSDocID reports whether the table already has the FTS_DOC_ID column that InnoDB
+// needs for FULLTEXT indexes: either an existing FULLTEXT index or a user-defined FTS_DOC_ID.
+func hasFTSDocID(meta *mysql.TableMetadata) bool {
+	for _, idx := range meta.Indexes {
+		if strings.EqualFold(idx.Type, "FULLTEXT") {
+			return true
+		}
+	}
+	for _, col := range meta.Columns {
+		if strings.EqualFold(col.Name, "FTS_DOC_ID") {
+			return true
+		}
+	}
+	return false
+}
+
+// subsequentFulltextClassification is the classification of ADD FULLTEXT INDEX when the
+// table already has FTS_DOC_ID: INPLACE without rebuild, but still with a SHARED lock.
+func subsequentFulltextClassification() DDLClassification {
+	return DDLClassification{
+		Algorithm:     AlgoInplace,
+		Lock:          LockShared,
+		RebuildsTable: false,
+		Notes:         "INPLACE with SHARED lock — writes blocked. Table already has a FULLTEXT index (FTS_DOC_ID exists), so no rebuild is needed.",
+	}
+}
+
+// invisibleIndexNote explains how to use an invisible index to find out whether it can be dropped.
+func invisibleIndexNote(index string) string {
+	return fmt.Sprintf(
+		"An invisible index is the safe precursor to DROP INDEX: the optimizer stops using `%s`, but InnoDB keeps maintaining it, so ALTER INDEX `%s` VISIBLE restores it instantly. "+
+			"Watch query latency and the slow query log for regressions over a full business cycle (including batch jobs and reports), then drop it. "+
+			"Queries with an index hint naming it (USE/FORCE INDEX) fail while it is invisible.",
+		index, index,
+	)
+}
+
+func firstFulltextWarning(meta *mysql.TableMetadata) string {
+	return fmt.Sprintf(
+		"First FULLTEXT index on this table: InnoDB adds a hidden FTS_DOC_ID column and rebuilds the whole table (%s) with writes blocked (LOCK=SHARED). Later FULLTEXT indexes do not rebuild.",
+		humanBytes(meta.TotalSize()),
+	)
+}
+
+// isCompressedTable reports whether the table uses ROW_FORMAT=COMPRESSED.
+func isCompressedTable(meta *mysql.TableMetadata) bool {
+	return meta != nil && strings.EqualFold(meta.RowFormat, "compressed")
+}
+
+// compressedRowFormatClassification reclassifies operations whose INSTANT path isn't available
+// on ROW_FORMAT=COMPRESSED tables. Without an explicit ALGORITHM=INSTANT, MySQL falls back to
+// INPLACE with a full rebuild instead of failing.
+func compressedRowFormatClassification(op parser.DDLOperation, cls DDLClassification) (DDLClassification, bool) {
+	if cls.Algorithm != AlgoInstant || (op != parser.AddColumn && op != parser.DropColumn) {
+		return cls, false
+	}
+	return DDLClassification{
+		Algorithm:     AlgoInplace,
+		Lock:          LockNone,
+		RebuildsTable: true,
+		Notes:         "ROW_FORMAT=COMPRESSED does not support INSTANT ADD/DROP COLUMN: INPLACE with table rebuild, concurrent DML allowed.",
+	}, true
+}
+
+func compressedInstantFallbackWarning(op parser.DDLOperation) string {
+	return fmt.Sprintf(
+		"Table uses ROW_FORMAT=COMPRESSED, which does not support INSTANT %s: MySQL silently falls back to INPLACE with a full table rebuild. An explicit ALGORITHM=INSTANT would fail.",
+		strings.ReplaceAll(string(op), "_", " "),
+	)
+}
+
+// maxRowVersions is the number of INSTANT ADD/DROP COLUMN row versions InnoDB keeps per
+// table (8.0.29+); rowVersionsWarnMargin how close to it dbsafe starts warning.
+const (
+	maxRowVersions        = 64
+	rowVersionsWarnMargin = 8
+)
+
+// applyRowVersionLimit checks INSTANT ADD/DROP COLUMN against the table's row versions.
+// Each ALTER that adds or drops columns INSTANT uses one; once all 64 are used MySQL falls
+// back to INPLACE with a table rebuild, which resets the counter. Near the limit the ALTER
+// stays INSTANT but gets a warning.
+func applyRowVersionLimit(input Input, result *Result) {
+	used := input.Meta.TotalRowVersions
+	if used < maxRowVersions-rowVersionsWarnMargin {
+		return
+	}
+	isInstantColumnOp := func(op parser.DDLOperation, cls DDLClassification) bool {
+		return cls.Algorithm == AlgoInstant && (op == parser.AddColumn || op == parser.DropColumn)
+	}
+	instant := isInstantColumnOp(input.Parsed.DDLOp, result.Classification)
+	for _, sub := range result.SubOpResults {
+		instant = instant || isInstantColumnOp(sub.Op, sub.Classification)
+	}
+	if !instant {
+		return
+	}
+
+	table := fmt.Sprintf("`%s`.`%s`", result.Database, result.Table)
+	if used < maxRowVersions {
+		result.addWarning(WarnRowVersionLimit, fmt.Sprintf(
+			"Table has used %d of %d row versions for INSTANT ADD/DROP COLUMN: this ALTER is still INSTANT, but after %d more such ALTERs MySQL falls back to a full table rebuild. "+
+				"Reset the counter with ALTER TABLE %s FORCE (a rebuild; use an online schema change tool for a large table) at a convenient time.",
+			used, maxRowVersions, maxRowVersions-used-1, table,
+		))
+		return
+	}
+
+	fallback := DDLClassification{
+		Algorithm:     AlgoInplace,
+		Lock:          LockNone,
+		RebuildsTable: true,
+		Notes:         fmt.Sprintf("All %d row versions are used: INSTANT ADD/DROP COLUMN isn't possible, INPLACE with table rebuild (resets the row version counter), concurrent DML allowed.", maxRowVersions),
+	}
+	if input.Parsed.DDLOp == parser.MultipleOps {
+		for i, sub := range result.SubOpResults {
+			if isInstantColumnOp(sub.Op, sub.Classification) {
+				result.SubOpResults[i].Classification = fallback
+			}
+		}
+		if result.Classification.Algorithm == AlgoInstant {
+			result.Classification.Algorithm = AlgoInplace
+		}
+		result.Classification.RebuildsTable = true
+		result.trace("row versions exhausted", result.Classification)
+	} else {
+		result.reclassify("row versions exhausted", fallback)
+	}
+	result.addWarning(WarnRowVersionLimit, fmt.Sprintf(
+		"Table has used all %d row versions for INSTANT ADD/DROP COLUMN (TOTAL_ROW_VERSIONS=%d): MySQL falls back to INPLACE with a full table rebuild, and an explicit ALGORITHM=INSTANT fails. "+
+			"The rebuild resets the counter; to keep this ALTER INSTANT, run ALTER TABLE %s FORCE first (through an online schema change tool for a large table).",
+		maxRowVersions, used, table,
+	))
+}
+
+// buildsIndex reports whether the ALTER builds a secondary index.
+func buildsIndex(p *parser.ParsedSQL) bool {
+	isIndexOp := func(op parser.DDLOperation) bool {
+		switch op {
+		case parser.AddIndex, parser.AddFulltextIndex, parser.AddSpatialIndex, parser.ChangeIndexType:
+			return true
+		}
+		return false
+	}
+	if p.DDLOp != parser.MultipleOps {
+		return isIndexOp(p.DDLOp)
+	}
+	for _, subOp := range p.SubOperations {
+		if isIndexOp(subOp.Op) {
+			return true
+		}
+	}
+	return false
+}
+
+// notEnforcedCheckWarning explains what NOT ENFORCED defers.
+const notEnforcedCheckWarning = "CHECK constraint is NOT ENFORCED: existing and new rows are not validated. " +
+	"Enforcing it later (ALTER TABLE ... ALTER CHECK <name> ENFORCED) validates every existing row and fails if any violates it."
+
+// notEnforcedCheckClassification is ADD CHECK ... NOT ENFORCED: the constraint is only
+// recorded in the data dictionary.
+func notEnforcedCheckClassification() DDLClassification {
+	return DDLClassification{
+		Algorithm:     AlgoInstant,
+		Lock:          LockNone,
+		RebuildsTable: false,
+		Notes:         "ADD CHECK ... NOT ENFORCED: metadata-only change; existing rows are not validated.",
+	}
+}
+
+// dependentGeneratedColumnWarnings returns a warning for each generated column whose
+// expression references one of the dropped columns. MySQL rejects such a DROP COLUMN
+// (ER_DEPENDENT_BY_GENERATED_COLUMN) unless the generated column is dropped in the same ALTER.
+func dependentGeneratedColumnWarnings(meta *mysql.TableMetadata, dropped []string) []Warning {
+	if meta == nil || len(dropped) == 0 {
+		return nil
+	}
+	isDropped := func(name string) bool {
+		for _, d := range dropped {
+			if strings.EqualFold(d, name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var warnings []Warning
+	for _, base := range dropped {
+		for _, gen := range meta.Columns {
+			if gen.GenerationExpr == "" || isDropped(gen.Name) || !expressionReferencesColumn(gen.GenerationExpr, base) {
+				continue
+			}
+			var indexes []string
+			for _, idx := range meta.Indexes {
+				for _, idxCol := range idx.Columns {
+					if strings.EqualFold(idxCol, gen.Name) {
+						indexes = append(indexes, idx.Name)
+						break
+					}
+				}
+			}
+			msg := fmt.Sprintf("Column '%s' is referenced by generated column '%s' (%s). The DROP COLUMN will fail: drop '%s' first",
+				base, gen.Name, gen.GenerationExpr, gen.Name)
+			if len(indexes) > 0 {
+				msg += fmt.Sprintf(" (this also drops its index %s)", strings.Join(indexes, ", "))
+			}
+			warnings = append(warnings, newWarning(WarnGeneratedColumnDependent, msg+", or drop both columns in the same ALTER."))
+		}
+	}
+	return warnings
+}
+
+// remainingColumnsWarning checks the columns the DROP COLUMNs of an ALTER leave, with the
+// ones it adds: MySQL rejects dropping every column (ER_CANT_REMOVE_ALL_FIELDS) and
+// leaving only virtual generated columns, which have no stored values to make up a row.
+func remainingColumnsWarning(meta *mysql.TableMetadata, subOps []parser.SubOperation) (Warning, bool) {
+	if meta == nil || len(meta.Columns) == 0 {
+		return Warning{}, false
+	}
+	var dropped []string
+	added, addedStored := 0, 0
+	for _, subOp := range subOps {
+		switch subOp.Op {
+		case parser.DropColumn:
+			if findColumnInfo(meta, subOp.ColumnName) != nil {
+				dropped = append(dropped, subOp.ColumnName)
+			}
+		case parser.AddColumn:
+			added++
+			if !subOp.IsGeneratedColumn || subOp.IsGeneratedStored {
+				addedStored++
+			}
+		}
+	}
+	if len(dropped) == 0 {
+		return Warning{}, false
+	}
+
+	var remaining []string
+	stored := 0
+	for _, col := range meta.Columns {
+		if slices.ContainsFunc(dropped, func(d string) bool { return strings.EqualFold(d, col.Name) }) {
+			continue
+		}
+		remaining = append(remaining, col.Name)
+		if col.GenerationExpr == "" || col.IsStoredGenerated {
+			stored++
+		}
+	}
+	names := func(cols []string) string { return "`" + strings.Join(cols, "`, `") + "`" }
+	switch {
+	case len(remaining)+added == 0:
+		what := fmt.Sprintf("Column %s is the table's only column: MySQL rejects dropping it", names(dropped))
+		if len(dropped) > 1 {
+			what = fmt.Sprintf("Columns %s are all of the table's columns: MySQL rejects dropping them all", names(dropped))
+		}
+		return newWarning(WarnDropLastColumn, what+
+			" (error 1090: You can't delete all columns with ALTER TABLE; use DROP TABLE instead). "+
+			"Use DROP TABLE if the table is no longer needed, or add the new columns in the same ALTER."), true
+	case stored+addedStored == 0:
+		return newWarning(WarnNoStoredColumns, fmt.Sprintf(
+			"Dropping %s leaves only virtual generated columns (%s), and a table needs at least one stored column: "+
+				"MySQL rejects the ALTER. Keep a stored column, or add one in the same ALTER.",
+			names(dropped), names(remaining),
+		)), true
+	}
+	return Warning{}, false
+}
+
+// generatedColumnOrderWarning checks the position FIRST (after == "") or AFTER after gives
+// the generated column column with expression expr. A generated column may only refer to
+// generated columns defined before it (ER_GENERATED_COLUMN_NON_PRIOR); base columns can be
+// referenced wherever they are. Only the table's existing columns are checked, not ones
+// added by the same ALTER.
+func generatedColumnOrderWarning(meta *mysql.TableMetadata, column, expr, after string) (string, bool) {
+	if meta == nil || expr == "" {
+		return "", false
+	}
+	// The columns in their new order, without the added or moved column, which goes at pos.
+	var order []mysql.ColumnInfo
+	pos := 0
+	for _, col := range meta.Columns {
+		if strings.EqualFold(col.Name, column) {
+			continue
+		}
+		order = append(order, col)
+		if after != "" && strings.EqualFold(col.Name, after) {
+			pos = len(order)
+		}
+	}
+	if after != "" && pos == 0 {
+		return "", false // unknown anchor column
+	}
+
+	position := "FIRST"
+	if after != "" {
+		position = "AFTER `" + after + "`"
+	}
+	references := func(expr, column string) bool {
+		return slices.ContainsFunc(parser.ExpressionColumns(expr), func(c string) bool { return strings.EqualFold(c, column) })
+	}
+	var problems []string
+	for i, col := range order {
+		if col.GenerationExpr == "" {
+			continue
+		}
+		if i >= pos && references(expr, col.Name) {
+			problems = append(problems, fmt.Sprintf("it references generated column '%s', which would come after it", col.Name))
+		}
+		if i < pos && references(col.GenerationExpr, column) {
+			problems = append(problems, fmt.Sprintf("generated column '%s' references it and would come before it", col.Name))
+		}
+	}
+	if len(problems) == 0 {
+		return "", false
+	}
+	return fmt.Sprintf(
+		"Generated column '%s' placed %s: %s. A generated column can only refer to generated columns defined before it, "+
+			"so MySQL rejects the ALTER (error 3107, ER_GENERATED_COLUMN_NON_PRIOR). Choose a position after the generated columns it references.",
+		column, position, strings.Join(problems, "; "),
+	), true
+}
+
+// partitionKeyUniqueWarning reports the table's unique keys, the primary key included, that
+// don't contain every column of the new partitioning expression. MySQL requires each unique
+// key to include them all, so the ALTER fails with error 1503 on such a table.
+func partitionKeyUniqueWarning(meta *mysql.TableMetadata, columns []string) (string, bool) {
+	if meta == nil || len(columns) == 0 {
+		return "", false
+	}
+	var keys []string
+	for _, idx := range meta.Indexes {
+		if idx.NonUnique {
+			continue
+		}
+		for _, col := range columns {
+			found := false
+			for _, c := range idx.Columns {
+				if strings.EqualFold(c, col) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				keys = append(keys, fmt.Sprintf("`%s` (%s)", idx.Name, strings.Join(idx.Columns, ", ")))
+				break
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return "", false
+	}
+	verb := "do"
+	if len(keys) == 1 {
+		verb = "does"
+	}
+	return fmt.Sprintf(
+		"Every unique key, the PRIMARY KEY included, must contain all columns of the partitioning expression (%s). "+
+			"%s %s not, so MySQL rejects this ALTER (error 1503: A UNIQUE INDEX must include all columns in the table's partitioning function). "+
+			"Add the partitioning columns to those keys first, or partition by a column they already contain.",
+		strings.Join(columns, ", "), strings.Join(keys, ", "), verb,
+	), true
+}
+
+// partitionColumnWarnings returns a warning for each DROP, MODIFY or CHANGE COLUMN among
+// subOps that targets a column of the table's partitioning expression. Dropping or renaming
+// such a column fails (error 3855: it has a partitioning function dependency); changing its
+// type is a COPY that moves every row to the partition of its new value, and fails when the
+// partitioning method doesn't allow the new type.
+func partitionColumnWarnings(meta *mysql.TableMetadata, subOps []parser.SubOperation) []Warning {
+	if meta == nil || meta.Partitioning == nil || meta.Partitioning.Expression == "" {
+		return nil
+	}
+	part := meta.Partitioning
+	inExpression := func(column string) bool {
+		expr := strings.TrimSpace(part.Expression)
+		return expressionReferencesColumn(expr, column) || (rePlainIdent.MatchString(expr) && strings.EqualFold(expr, column))
+	}
+	repartition := "Repartition the table on other columns first (ALTER TABLE ... PARTITION BY), or remove the partitioning (REMOVE PARTITIONING); both copy the whole table."
+
+	var warnings []Warning
+	for _, subOp := range subOps {
+		column := subOp.ColumnName
+		if subOp.Op == parser.ChangeColumn && subOp.OldColumnName != "" {
+			column = subOp.OldColumnName
+		}
+		if column == "" || !inExpression(column) {
+			continue
+		}
+		switch {
+		case subOp.Op == parser.DropColumn:
+			warnings = append(warnings, newWarning(WarnPartitionColumnDependency, fmt.Sprintf(
+				"Column `%s` is used by the table's %s partitioning expression (%s): MySQL rejects dropping it "+
+					"(error 3855: Column has a partitioning function dependency and cannot be dropped or renamed). %s",
+				column, part.Method, part.Expression, repartition,
+			)))
+		case subOp.Op == parser.ChangeColumn && !strings.EqualFold(subOp.ColumnName, column):
+			warnings = append(warnings, newWarning(WarnPartitionColumnDependency, fmt.Sprintf(
+				"Column `%s` is used by the table's %s partitioning expression (%s): MySQL rejects renaming it to `%s` "+
+					"(error 3855: Column has a partitioning function dependency and cannot be dropped or renamed). %s",
+				column, part.Method, part.Expression, subOp.ColumnName, repartition,
+			)))
+		case (subOp.Op == parser.ModifyColumn || subOp.Op == parser.ChangeColumn) && subOp.NewColumnType != "":
+			col := findColumnInfo(meta, column)
+			if col != nil && strings.EqualFold(col.Type, subOp.NewColumnType) {
+				continue
+			}
+			from := ""
+			if col != nil {
+				from = fmt.Sprintf(" from %s", col.Type)
+			}
+			warnings = append(warnings, newWarning(WarnPartitionColumnTypeChange, fmt.Sprintf(
+				"Column `%s` is used by the table's %s partitioning expression (%s): changing its type%s to %s can't be done in place. "+
+					"It is a COPY that recomputes the partition of every row, and MySQL rejects it if %s partitioning doesn't allow the new type "+
+					"(RANGE, LIST and HASH need an integer expression; RANGE COLUMNS and LIST COLUMNS integer, date and string columns). "+
+					"To change the partition key itself, repartition the table (ALTER TABLE ... PARTITION BY).",
+				column, part.Method, part.Expression, from, subOp.NewColumnType, part.Method,
+			)))
+		}
+	}
+	return warnings
+}
+
+// checkConstraintColumnWarnings returns a warning for each DROP, MODIFY or CHANGE COLUMN
+// among subOps that targets a column a CHECK constraint uses. Dropping or renaming the
+// column fails (error 3959: the constraint uses it) unless the same ALTER drops the
+// constraint; changing its type evaluates the constraint again on every converted row, and
+// fails on the first one that violates it.
+func checkConstraintColumnWarnings(meta *mysql.TableMetadata, subOps []parser.SubOperation) []Warning {
+	if meta == nil || len(meta.CheckConstraints) == 0 {
+		return nil
+	}
+	dropped := func(name string) bool {
+		for _, subOp := range subOps {
+			if subOp.Op == parser.DropIndex && strings.EqualFold(subOp.IndexName, name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var warnings []Warning
+	for _, subOp := range subOps {
+		column := subOp.ColumnName
+		if subOp.Op == parser.ChangeColumn && subOp.OldColumnName != "" {
+			column = subOp.OldColumnName
+		}
+		if column == "" || (subOp.Op != parser.DropColumn && subOp.Op != parser.ModifyColumn && subOp.Op != parser.ChangeColumn) {
+			continue
+		}
+		for _, chk := range meta.CheckConstraints {
+			if dropped(chk.Name) || !slices.ContainsFunc(parser.ExpressionColumns(chk.Expression), func(c string) bool { return strings.EqualFold(c, column) }) {
+				continue
+			}
+			switch {
+			case subOp.Op == parser.DropColumn || !strings.EqualFold(subOp.ColumnName, column):
+				action := "dropping"
+				if subOp.Op == parser.ChangeColumn {
+					action = fmt.Sprintf("renaming it to `%s`", subOp.ColumnName)
+				}
+				warnings = append(warnings, newWarning(WarnCheckConstraintColumn, fmt.Sprintf(
+					"Column `%s` is used by check constraint `%s` (%s): MySQL rejects %s "+
+						"(error 3959: Check constraint uses column, hence column cannot be dropped or renamed). "+
+						"Drop the constraint first, or in the same ALTER (DROP CHECK `%s`), and add it back on the new definition if it still applies.",
+					column, chk.Name, chk.Expression, action, chk.Name,
+				)))
+			case subOp.NewColumnType != "" && !sameColumnType(meta, column, subOp.NewColumnType):
+				warnings = append(warnings, newWarning(WarnCheckConstraintViolation, fmt.Sprintf(
+					"Column `%s` is used by check constraint `%s` (%s): the ALTER checks it again on every row with the converted value, "+
+						"and fails (error 3819) on the first row that violates it, e.g. a value rounded or truncated by the new type %s.",
+					column, chk.Name, chk.Expression, subOp.NewColumnType,
+				)))
+			}
+		}
+	}
+	return warnings
+}
+
+// sameColumnType reports whether the table metadata gives column the type newType already.
+func sameColumnType(meta *mysql.TableMetadata, column, newType string) bool {
+	col := findColumnInfo(meta, column)
+	return col != nil && strings.EqualFold(col.Type, newType)
+}
+
+// foreignKeyIndexWarnings returns a warning for each foreign key left without an index
+// once the dropped indexes are gone. InnoDB needs an index whose leading columns are the
+// FK's columns, on the child table and on the parent's referenced columns, and rejects
+// the DROP INDEX otherwise ("Cannot drop index needed in a foreign key constraint").
+// Indexes added and foreign keys dropped in the same ALTER are taken into account.
+func foreignKeyIndexWarnings(meta *mysql.TableMetadata, dropped []string, added [][]string, droppedFKs []string) []Warning {
+	if meta == nil || len(dropped) == 0 {
+		return nil
+	}
+	isDropped := func(name string, names []string) bool {
+		for _, n := range names {
+			if strings.EqualFold(n, name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	remaining := added
+	for _, idx := range meta.Indexes {
+		if !isDropped(idx.Name, dropped) {
+			remaining = append(remaining, idx.Columns)
+		}
+	}
+	covered := func(cols []string) bool {
+		for _, idxCols := range remaining {
+			if hasLeadingColumns(idxCols, cols) {
+				return true
+			}
+		}
+		return false
+	}
+	// needing returns the dropped index that covered cols, if any.
+	needing := func(cols []string) string {
+		for _, idx := range meta.Indexes {
+			if isDropped(idx.Name, dropped) && hasLeadingColumns(idx.Columns, cols) {
+				return idx.Name
+			}
+		}
+		return ""
+	}
+
+	var warnings []Warning
+	for _, fk := range meta.ForeignKeys {
+		if isDropped(fk.Name, droppedFKs) || covered(fk.Columns) {
+			continue
 		}
+		if idx := needing(fk.Columns); idx != "" {
+			warnings = append(warnings, newWarning(WarnForeignKeyIndexRequired, fmt.Sprintf(
+				"Index '%s' is the only index covering foreign key '%s' (%s) and can't be dropped: MySQL rejects this with \"Cannot drop index needed in a foreign key constraint\". "+
+					"Add another index on (%s) first, or drop the foreign key in the same ALTER.",
+				idx, fk.Name, strings.Join(fk.Columns, ", "), strings.Join(fk.Columns, ", "))))
+		}
+	}
+	for _, fk := range meta.InboundForeignKeys {
+		if covered(fk.ReferencedCols) {
+			continue
+		}
+		if idx := needing(fk.ReferencedCols); idx != "" {
+			warnings = append(warnings, newWarning(WarnForeignKeyIndexRequired, fmt.Sprintf(
+				"Index '%s' is the only index on (%s) referenced by foreign key '%s' on %s.%s and can't be dropped: MySQL rejects this with \"Cannot drop index needed in a foreign key constraint\". "+
+					"Add another index on (%s) first, or drop the foreign key on %s.%s.",
+				idx, strings.Join(fk.ReferencedCols, ", "), fk.Name, fk.ChildSchema, fk.ChildTable,
+				strings.Join(fk.ReferencedCols, ", "), fk.ChildSchema, fk.ChildTable)))
+		}
+	}
+	return warnings
+}
 
-	case parser.ModifyColumn:
-		if subOp.NewColumnType != "" && meta != nil {
-			oldType := findColumnType(meta.Columns, subOp.ColumnName)
-			if oldType != "" {
-				if subOp.NewColumnCharset == "" {
-					// No charset change: try INSTANT/INPLACE optimizations.
-					if c, ok := classifyModifyColumnEnum(oldType, subOp.NewColumnType); ok {
-						cls = c
-					} else {
-						charset := findColumnCharset(meta.Columns, subOp.ColumnName)
-						if c, ok := classifyModifyColumnVarchar(oldType, subOp.NewColumnType, charset); ok {
-							cls = c
-						}
-					}
-				}
-			}
+// hasLeadingColumns reports whether an index on idxCols starts with cols, in order.
+func hasLeadingColumns(idxCols, cols []string) bool {
+	if len(cols) == 0 || len(idxCols) < len(cols) {
+		return false
+	}
+	for i, col := range cols {
+		if !strings.EqualFold(idxCols[i], col) {
+			return false
 		}
+	}
+	return true
+}
 
-	case parser.AddPrimaryKey:
-		if len(subOp.IndexColumns) > 0 && meta != nil {
-			for _, colName := range subOp.IndexColumns {
-				for _, col := range meta.Columns {
-					if strings.EqualFold(col.Name, colName) && col.Nullable {
-						cls = DDLClassification{Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true,
-							Notes: "Nullable PK column requires COPY."}
-						warnings = append(warnings, fmt.Sprintf(
-							"Column '%s' is nullable: ADD PRIMARY KEY on a nullable column requires COPY algorithm.",
-							colName,
-						))
-						break
-					}
-				}
-				if cls.Algorithm == AlgoCopy {
-					break
-				}
-			}
+// renameSwapWarnings checks a multi-table RENAME TABLE for pairs MySQL rejects: MySQL
+// applies the pairs left to right, so each source must still exist (not be renamed away
+// by an earlier pair) and each target must be free (not created by an earlier pair). A
+// clean rotation such as a TO tmp, b TO a, tmp TO b passes.
+func renameSwapWarnings(database string, pairs []parser.RenamePair) []Warning {
+	key := func(db, table string) string {
+		if db == "" {
+			db = database
+		}
+		return strings.ToLower(db + "." + table)
+	}
+	// exists holds what the statement itself has established about a name so far.
+	exists := make(map[string]bool)
+
+	var warnings []Warning
+	for i, p := range pairs {
+		from, to := key(p.FromDatabase, p.From), key(p.ToDatabase, p.To)
+		pair := fmt.Sprintf("pair %d (%s TO %s)", i+1, p.From, p.To)
+		if present, known := exists[from]; known && !present {
+			warnings = append(warnings, newWarning(WarnRenameSwapInvalid, fmt.Sprintf(
+				"RENAME TABLE %s: '%s' was already renamed away by an earlier pair, so this rename will fail. Check the order of the pairs.", pair, p.From)))
 		}
+		if exists[to] {
+			warnings = append(warnings, newWarning(WarnRenameSwapInvalid, fmt.Sprintf(
+				"RENAME TABLE %s: an earlier pair already renamed a table to '%s', so this rename will fail with \"Table already exists\". Check the order of the pairs.", pair, p.To)))
+		}
+		exists[from] = false
+		exists[to] = true
+	}
+	return warnings
+}
 
-	case parser.AddForeignKey:
-		if !fkChecksDisabled {
-			cls = DDLClassification{Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: false,
-				Notes: "ADD FOREIGN KEY with foreign_key_checks=ON requires COPY."}
-			warnings = append(warnings, "foreign_key_checks=ON: COPY algorithm required for ADD FOREIGN KEY.")
+// expressionReferencesColumn reports whether a generation expression as stored in
+// information_schema (identifiers backtick-quoted, e.g. "(`price` * `qty`)") refers to column.
+func expressionReferencesColumn(expr, column string) bool {
+	quoted := "`" + strings.ReplaceAll(column, "`", "``") + "`"
+	return strings.Contains(strings.ToLower(expr), strings.ToLower(quoted))
+}
+
+// spatialSRIDChange returns a COPY classification and a pre-flight warning when a
+// MODIFY/CHANGE COLUMN sets an SRID on a spatial column. MySQL checks every existing
+// geometry against the SRID and fails the ALTER on the first mismatch. Restating the SRID
+// the column already has (per meta) changes nothing, so it is left to the other rules.
+func spatialSRIDChange(meta *mysql.TableMetadata, table, column, newType, srid string) (DDLClassification, string, bool) {
+	if srid == "" || !isSpatialType(newType) {
+		return DDLClassification{}, "", false
+	}
+	if col := findColumnInfo(meta, column); col != nil && col.SRID == srid && strings.EqualFold(col.Type, newType) {
+		return DDLClassification{}, "", false
+	}
+	cls := DDLClassification{
+		Algorithm:     AlgoCopy,
+		Lock:          LockShared,
+		RebuildsTable: true,
+		Notes:         "Setting a spatial column SRID requires COPY: every existing geometry is validated against the SRID.",
+	}
+	warn := fmt.Sprintf(
+		"Column '%s' SRID set to %s: the ALTER fails if any existing geometry has a different SRID. Find mismatched rows first with:\n  SELECT COUNT(*) FROM %s WHERE ST_SRID(%s) <> %s;",
+		column, srid, table, column, srid,
+	)
+	return cls, warn, true
+}
+
+// generationExprChange classifies a MODIFY COLUMN that gives an existing generated column
+// a different generation expression. A STORED column has every value recomputed: COPY with
+// LOCK=SHARED and a warning. A VIRTUAL column that stays VIRTUAL with the same data type
+// only changes its definition: INPLACE without a rebuild, and no warning.
+func generationExprChange(columns []mysql.ColumnInfo, column, newType, newExpr string, stored bool) (DDLClassification, string, bool) {
+	if newExpr == "" {
+		return DDLClassification{}, "", false
+	}
+	var old *mysql.ColumnInfo
+	for i := range columns {
+		if strings.EqualFold(columns[i].Name, column) {
+			old = &columns[i]
+			break
 		}
+	}
+	if old == nil || old.GenerationExpr == "" || parser.NormalizeExpr(old.GenerationExpr) == parser.NormalizeExpr(newExpr) {
+		return DDLClassification{}, "", false
+	}
 
-	case parser.ChangeEngine:
-		if subOp.NewEngine != "" && meta != nil && strings.EqualFold(subOp.NewEngine, meta.Engine) {
-			cls = DDLClassification{Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: true,
-				Notes: "ENGINE=<same engine>: equivalent to ALTER TABLE ... FORCE. INPLACE rebuild."}
+	if stored {
+		cls := DDLClassification{
+			Algorithm:     AlgoCopy,
+			Lock:          LockShared,
+			RebuildsTable: true,
+			Notes:         "Changing a STORED generated column's expression requires COPY: every stored value is recomputed.",
 		}
+		warn := fmt.Sprintf(
+			"Column '%s' generation expression changes: %s → %s. All stored values will be recomputed, so the ALTER runs as COPY with LOCK=SHARED and writes are blocked during the rebuild.",
+			column, old.GenerationExpr, newExpr,
+		)
+		return cls, warn, true
+	}
+	sameType := strings.EqualFold(strings.ReplaceAll(old.Type, " ", ""), strings.ReplaceAll(newType, " ", ""))
+	if old.IsStoredGenerated || !sameType {
+		return DDLClassification{}, "", false
 	}
+	return DDLClassification{
+		Algorithm: AlgoInplace,
+		Lock:      LockNone,
+		Notes:     "Changing a VIRTUAL generated column's expression: INPLACE, metadata only. Values are computed on read, so no rows are rewritten.",
+	}, "", true
+}
 
-	return cls, warnings
+// isSpatialType reports whether a column type is one of the MySQL spatial data types.
+func isSpatialType(colType string) bool {
+	switch strings.ToLower(strings.TrimSpace(colType)) {
+	case "geometry", "point", "linestring", "polygon", "multipoint",
+		"multilinestring", "multipolygon", "geometrycollection", "geomcollection":
+		return true
+	}
+	return false
 }
 
 // aggregateMultipleOps classifies a MULTIPLE_OPS ALTER TABLE by applying live-metadata
@@ -929,7 +3885,7 @@ func classifySubOp(subOp parser.SubOperation, meta *mysql.TableMetadata, fkCheck
 //
 // Algorithm precedence (most to least restrictive): COPY > INPLACE > INSTANT
 // Lock precedence (most to least restrictive): EXCLUSIVE > SHARED > NONE
-func aggregateMultipleOps(subOps []parser.SubOperation, meta *mysql.TableMetadata, fkChecksDisabled bool, v mysql.ServerVersion) (DDLClassification, []SubOpResult, []string) {
+func aggregateMultipleOps(subOps []parser.SubOperation, meta *mysql.TableMetadata, fkChecksDisabled bool, v mysql.ServerVersion) (DDLClassification, []SubOpResult, []Warning) {
 	algoPriority := map[Algorithm]int{AlgoInstant: 0, AlgoInplace: 1, AlgoCopy: 2}
 	lockPriority := map[LockLevel]int{LockNone: 0, LockShared: 1, LockExclusive: 2}
 
@@ -939,7 +3895,7 @@ func aggregateMultipleOps(subOps []parser.SubOperation, meta *mysql.TableMetadat
 	}
 
 	var subOpResults []SubOpResult
-	var allWarnings []string
+	var allWarnings []Warning
 
 	for _, subOp := range subOps {
 		cls, warnings := classifySubOp(subOp, meta, fkChecksDisabled, v)
@@ -961,6 +3917,136 @@ func aggregateMultipleOps(subOps []parser.SubOperation, meta *mysql.TableMetadat
 	return combined, subOpResults, allWarnings
 }
 
+// columnModifier is one kind of change a MODIFY or CHANGE COLUMN makes to its column, with
+// the classification the statement would get if it made only that change.
+type columnModifier struct {
+	Change         string
+	Classification DDLClassification
+}
+
+// columnModifiers returns the kinds of change a MODIFY or CHANGE COLUMN makes to the live
+// column: rename, data type, charset, nullability and position. A DEFAULT change is
+// metadata-only and never decides the algorithm, so it isn't listed. Generated and spatial
+// columns follow their own rules (see generationExprChange and spatialSRIDChange) and
+// return nil, as does a column that isn't in the table.
+func columnModifiers(input Input) []columnModifier {
+	p := input.Parsed
+	name := p.ColumnName
+	if p.DDLOp == parser.ChangeColumn {
+		name = p.OldColumnName
+	}
+	col := findColumnInfo(input.Meta, name)
+	if col == nil || col.GenerationExpr != "" || p.IsGeneratedColumn || p.NewColumnSRID != "" || isSpatialType(col.Type) {
+		return nil
+	}
+
+	rebuild := DDLClassification{Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: true}
+	copyShared := DDLClassification{Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true}
+	var mods []columnModifier
+
+	if p.DDLOp == parser.ChangeColumn && p.NewColumnName != "" && !strings.EqualFold(p.NewColumnName, p.OldColumnName) {
+		v := input.Version
+		mods = append(mods, columnModifier{
+			Change:         fmt.Sprintf("rename to `%s`", p.NewColumnName),
+			Classification: ClassifyDDL(parser.ChangeColumn, v.Major, v.Minor, v.EffectivePatch()),
+		})
+	}
+
+	oldType := strings.ToLower(col.Type)
+	if p.NewColumnType != "" && !strings.EqualFold(strings.ReplaceAll(oldType, " ", ""), strings.ReplaceAll(p.NewColumnType, " ", "")) {
+		mod := columnModifier{Change: fmt.Sprintf("type %s → %s", oldType, p.NewColumnType), Classification: copyShared}
+		if p.DDLOp == parser.ModifyColumn {
+			charset := findColumnCharset(input.Meta.Columns, name)
+			if cls, ok := classifyModifyColumnEnum(oldType, p.NewColumnType); ok {
+				mod.Change, mod.Classification = "members appended to "+oldType, cls
+			} else if cls, ok := classifyModifyColumnVarchar(oldType, p.NewColumnType, charset); ok {
+				mod.Change, mod.Classification = fmt.Sprintf("%s extended to %s", oldType, p.NewColumnType), cls
+			}
+		}
+		mods = append(mods, mod)
+	}
+
+	if p.NewColumnCharset != "" && col.CharacterSet != nil && !strings.EqualFold(*col.CharacterSet, p.NewColumnCharset) {
+		mods = append(mods, columnModifier{
+			Change:         fmt.Sprintf("charset %s → %s", *col.CharacterSet, p.NewColumnCharset),
+			Classification: copyShared,
+		})
+	}
+
+	if p.NewColumnNullable != nil && *p.NewColumnNullable != col.Nullable {
+		change := "NULL → NOT NULL"
+		if *p.NewColumnNullable {
+			change = "NOT NULL → NULL"
+		}
+		mods = append(mods, columnModifier{Change: change, Classification: rebuild})
+	}
+
+	if p.IsFirstAfter {
+		change := "moved FIRST"
+		if p.AfterColumn != "" {
+			change = fmt.Sprintf("moved AFTER `%s`", p.AfterColumn)
+		}
+		mods = append(mods, columnModifier{Change: change, Classification: rebuild})
+	}
+	return mods
+}
+
+// moreRestrictive reports whether a blocks more than b: a slower algorithm, then a table
+// rebuild, then a stronger lock.
+func moreRestrictive(a, b DDLClassification) bool {
+	if algorithmRank[a.Algorithm] != algorithmRank[b.Algorithm] {
+		return algorithmRank[a.Algorithm] > algorithmRank[b.Algorithm]
+	}
+	if a.RebuildsTable != b.RebuildsTable {
+		return a.RebuildsTable
+	}
+	return lockRank[a.Lock] > lockRank[b.Lock]
+}
+
+// dominantModifier returns the most restrictive of mods (the first one on a tie), with
+// notes naming it.
+func dominantModifier(mods []columnModifier) columnModifier {
+	dominant := mods[0]
+	for _, m := range mods[1:] {
+		if moreRestrictive(m.Classification, dominant.Classification) {
+			dominant = m
+		}
+	}
+	dominant.Classification.Notes = fmt.Sprintf(
+		"Several column changes in one statement: the %s change needs %s, the most restrictive of them, so the whole ALTER runs that way.",
+		dominant.Change, classificationSummary(dominant.Classification))
+	return dominant
+}
+
+// classificationSummary describes a classification in a few words, e.g. "COPY with a
+// SHARED lock and a table rebuild".
+func classificationSummary(c DDLClassification) string {
+	s := string(c.Algorithm)
+	if c.Lock != LockNone && c.Lock != "" {
+		s += fmt.Sprintf(" with a %s lock", c.Lock)
+	}
+	if c.RebuildsTable {
+		s += " and a table rebuild"
+	} else if c.Algorithm != AlgoInstant {
+		s += " without a rebuild"
+	}
+	return s
+}
+
+// mixedColumnChangesNote lists each change of a MODIFY/CHANGE COLUMN with its own
+// classification and names the one that decides the statement's.
+func mixedColumnChangesNote(mods []columnModifier, dominant columnModifier) string {
+	parts := make([]string, len(mods))
+	for i, m := range mods {
+		parts[i] = fmt.Sprintf("%s (%s)", m.Change, classificationSummary(m.Classification))
+	}
+	return fmt.Sprintf(
+		"This statement makes %d changes to the column: %s. MySQL runs the ALTER with the most restrictive algorithm any of them needs, "+
+			"so the %s change decides it. Split the other changes into their own statements only if they are cheaper alone and the dominant one isn't needed now.",
+		len(mods), strings.Join(parts, "; "), dominant.Change,
+	)
+}
+
 // findColumnType returns the type string for a column by name, or empty if not found.
 func findColumnType(columns []mysql.ColumnInfo, name string) string {
 	for _, col := range columns {
@@ -984,6 +4070,141 @@ func findColumnInfo(meta *mysql.TableMetadata, name string) *mysql.ColumnInfo {
 	return nil
 }
 
+// nullablePKNullValuesWarning explains what happens to existing NULLs in the nullable
+// columns of a new primary key, which MySQL has to make NOT NULL, and how to count them.
+func nullablePKNullValuesWarning(table string, nullable []string) string {
+	conds := make([]string, len(nullable))
+	for i, col := range nullable {
+		conds[i] = col + " IS NULL"
+	}
+	return fmt.Sprintf(
+		"Nullable primary key column(s) `%s` become NOT NULL. Without strict SQL mode, MySQL silently replaces existing NULLs with the type's implicit default (0, '', the zero date), "+
+			"which changes what the data means and can make rows collide on the new key; with STRICT_TRANS_TABLES (the default) the ALTER fails with \"Invalid use of NULL value\" instead. "+
+			"Count the affected rows and fix them first:\n  SELECT COUNT(*) FROM %s WHERE %s;",
+		strings.Join(nullable, "`, `"), table, strings.Join(conds, " OR "),
+	)
+}
+
+// primaryKeyDuplicatesWarning asks for the duplicate check of ADD PRIMARY KEY, weighted
+// by what the table already guarantees: an existing NOT NULL unique index on some of the
+// key's columns makes duplicates impossible, an AUTO_INCREMENT column unlikely, and a
+// composite key no index has kept unique so far gets the check stressed, more so when
+// NULLs converted to the implicit default can collide too.
+func primaryKeyDuplicatesWarning(meta *mysql.TableMetadata, columns []string, query string) string {
+	key := "(" + strings.Join(columns, ", ") + ")"
+	if idx := uniqueIndexWithin(meta, columns); idx != nil {
+		return fmt.Sprintf(
+			"The new primary key %s fails on duplicate combinations, but unique index `%s` on (%s) already keeps them apart, so the check is expected to pass:\n  %s",
+			key, idx.Name, strings.Join(idx.Columns, ", "), query,
+		)
+	}
+	if len(columns) == 1 {
+		if col := findColumnInfo(meta, columns[0]); col != nil && col.AutoIncrement {
+			return fmt.Sprintf(
+				"This ALTER will fail if duplicates exist. `%s` is the AUTO_INCREMENT column, so the check is likely to pass unless rows were inserted with explicit values:\n  %s",
+				col.Name, query,
+			)
+		}
+		return "This ALTER will fail if duplicates exist. Verify with:\n  " + query
+	}
+
+	msg := fmt.Sprintf(
+		"Run the duplicate check before this ALTER: no unique index covers %s, so nothing has kept the combination unique so far, "+
+			"and a single duplicate fails the ALTER after it has rebuilt the table.",
+		key,
+	)
+	if nullable := nullableColumns(meta, columns); len(nullable) > 0 {
+		msg += fmt.Sprintf(
+			" Nullable `%s` makes it worse: without strict SQL mode its NULLs become the implicit default, which can collide with each other and with rows already holding that value. "+
+				"The query groups NULLs together, but doesn't catch collisions with existing default values.",
+			strings.Join(nullable, "`, `"),
+		)
+	}
+	return msg + "\n  " + query
+}
+
+// uniqueIndexWithin returns a unique index whose columns are all among columns and NOT
+// NULL, whole (no prefix): any key over columns is then unique too. Returns nil if there
+// is none.
+func uniqueIndexWithin(meta *mysql.TableMetadata, columns []string) *mysql.IndexInfo {
+	for i, idx := range meta.Indexes {
+		if idx.NonUnique || len(idx.Columns) == 0 {
+			continue
+		}
+		ok := true
+		for j, name := range idx.Columns {
+			col := findColumnInfo(meta, name)
+			if col == nil || col.Nullable || (j < len(idx.SubParts) && idx.SubParts[j] > 0) ||
+				!slices.ContainsFunc(columns, func(c string) bool { return strings.EqualFold(c, name) }) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return &meta.Indexes[i]
+		}
+	}
+	return nil
+}
+
+// virtualColumnIndexWarning notes what indexing VIRTUAL generated columns costs: the
+// index stores values the table doesn't, so building it computes them for every row, and
+// every later write that changes them computes them again. Returns ("", false) when none
+// of the columns is a VIRTUAL generated column.
+func virtualColumnIndexWarning(meta *mysql.TableMetadata, columns []string) (string, bool) {
+	var virtual []string
+	for _, name := range columns {
+		if col := findColumnInfo(meta, name); col != nil && col.GenerationExpr != "" && !col.IsStoredGenerated {
+			virtual = append(virtual, col.Name)
+		}
+	}
+	if len(virtual) == 0 {
+		return "", false
+	}
+	subject := fmt.Sprintf("`%s` is a VIRTUAL generated column: the table doesn't store its values", virtual[0])
+	if len(virtual) > 1 {
+		subject = fmt.Sprintf("`%s` are VIRTUAL generated columns: the table doesn't store their values", strings.Join(virtual, "`, `"))
+	}
+	return fmt.Sprintf(
+		"%s, but the index does. "+
+			"Building the index evaluates the generation expression for every row, which is heavier than indexing a regular column, "+
+			"and the ALTER fails if the expression raises an error for any existing row. Afterwards every INSERT, and every UPDATE of a column the expression reads, "+
+			"computes the value again to maintain the index, and the undo log keeps the old values for rollback and purge.",
+		subject,
+	), true
+}
+
+// secondAutoIncrementWarning reports an ADD COLUMN ... AUTO_INCREMENT (or SERIAL) on a table
+// that already has an AUTO_INCREMENT column: InnoDB allows one per table, so the ALTER
+// fails with error 1075.
+func secondAutoIncrementWarning(meta *mysql.TableMetadata, column string) (string, bool) {
+	if meta == nil {
+		return "", false
+	}
+	for _, col := range meta.Columns {
+		if col.AutoIncrement && !strings.EqualFold(col.Name, column) {
+			return fmt.Sprintf(
+				"`%s` is already AUTO_INCREMENT, and a table can have only one: adding `%s` as AUTO_INCREMENT (SERIAL included) fails with error 1075 "+
+					"\"Incorrect table definition; there can be only one auto column\". Use a plain BIGINT UNSIGNED column instead.",
+				col.Name, column,
+			), true
+		}
+	}
+	return "", false
+}
+
+// nullableColumns returns the columns among names that the table metadata reports as
+// nullable. Columns missing from the metadata are skipped.
+func nullableColumns(meta *mysql.TableMetadata, names []string) []string {
+	var nullable []string
+	for _, name := range names {
+		if col := findColumnInfo(meta, name); col != nil && col.Nullable {
+			nullable = append(nullable, name)
+		}
+	}
+	return nullable
+}
+
 // buildColumnRollbackSQL generates a MODIFY/CHANGE COLUMN statement that restores
 // the original column definition using live metadata.
 func buildColumnRollbackSQL(tbl string, op parser.DDLOperation, col *mysql.ColumnInfo, p *parser.ParsedSQL) string {
@@ -1203,13 +4424,163 @@ func classifyModifyColumnVarchar(oldType, newType, charset string) (DDLClassific
 	}, true
 }
 
+// numericType is a parsed MySQL integer or fixed-point column type.
+type numericType struct {
+	base      string // tinyint, smallint, mediumint, int, bigint, decimal
+	unsigned  bool
+	precision int // DECIMAL only: total digits
+	scale     int // DECIMAL only: digits after the decimal point
+}
+
+// integerBounds holds the signed minimum and maximum for each integer type.
+// Unsigned types range from 0 to 2*max+1.
+var integerBounds = map[string]struct{ min, max int64 }{
+	"tinyint":   {-128, 127},
+	"smallint":  {-32768, 32767},
+	"mediumint": {-8388608, 8388607},
+	"int":       {-2147483648, 2147483647},
+	"bigint":    {-9223372036854775808, 9223372036854775807},
+}
+
+// integerRange returns the minimum and maximum storable values for an integer type.
+// The maximum is unsigned so that BIGINT UNSIGNED fits.
+func (nt numericType) integerRange() (int64, uint64) {
+	b := integerBounds[nt.base]
+	if nt.unsigned {
+		return 0, uint64(b.max)*2 + 1
+	}
+	return b.min, uint64(b.max)
+}
+
+// parseNumericType parses an integer or DECIMAL type string such as "int(11) unsigned"
+// or "decimal(14,4)". Returns (zero, false) for non-numeric or floating-point types.
+func parseNumericType(typeStr string) (numericType, bool) {
+	s := strings.TrimSpace(strings.ToLower(typeStr))
+	if s == "" {
+		return numericType{}, false
+	}
+	nt := numericType{unsigned: strings.Contains(s, "unsigned")}
+
+	base := s
+	args := ""
+	if i := strings.IndexAny(s, "( "); i >= 0 {
+		base = s[:i]
+		if s[i] == '(' {
+			if j := strings.IndexByte(s, ')'); j > i {
+				args = s[i+1 : j]
+			}
+		}
+	}
+	if base == "integer" {
+		base = "int"
+	}
+
+	if _, ok := integerBounds[base]; ok {
+		nt.base = base
+		return nt, true
+	}
+
+	switch base {
+	case "decimal", "numeric", "dec", "fixed":
+		nt.base = "decimal"
+		nt.precision, nt.scale = 10, 0 // MySQL default: DECIMAL == DECIMAL(10,0)
+		if args != "" {
+			p, sc, hasScale := strings.Cut(args, ",")
+			if _, err := fmt.Sscanf(strings.TrimSpace(p), "%d", &nt.precision); err != nil {
+				return numericType{}, false
+			}
+			if hasScale {
+				if _, err := fmt.Sscanf(strings.TrimSpace(sc), "%d", &nt.scale); err != nil {
+					return numericType{}, false
+				}
+			}
+		}
+		return nt, true
+	}
+	return numericType{}, false
+}
+
+// numericNarrowingPredicate returns a WHERE predicate that matches rows whose current value
+// of column would not fit (or would be rounded) in newType. Returns ("", false) when the
+// change is not a numeric narrowing: the types are not both integer or both DECIMAL, or
+// every value representable by oldType is also representable by newType.
+func numericNarrowingPredicate(column, oldType, newType string) (string, bool) {
+	oldNT, oldOK := parseNumericType(oldType)
+	newNT, newOK := parseNumericType(newType)
+	if !oldOK || !newOK {
+		return "", false
+	}
+	col := "`" + column + "`"
+
+	if _, ok := integerBounds[oldNT.base]; ok {
+		if _, ok := integerBounds[newNT.base]; !ok {
+			return "", false
+		}
+		oldMin, oldMax := oldNT.integerRange()
+		newMin, newMax := newNT.integerRange()
+
+		var conds []string
+		if oldMax > newMax {
+			conds = append(conds, fmt.Sprintf("%s > %d", col, newMax))
+		}
+		if oldMin < newMin {
+			conds = append(conds, fmt.Sprintf("%s < %d", col, newMin))
+		}
+		if len(conds) == 0 {
+			return "", false
+		}
+		return strings.Join(conds, " OR "), true
+	}
+
+	if oldNT.base == "decimal" && newNT.base == "decimal" {
+		var conds []string
+		if newNT.precision-newNT.scale < oldNT.precision-oldNT.scale {
+			// Largest absolute value the new type can hold, e.g. DECIMAL(10,2) → 99999999.99.
+			limit := strings.Repeat("9", newNT.precision-newNT.scale)
+			if limit == "" {
+				limit = "0"
+			}
+			if newNT.scale > 0 {
+				limit += "." + strings.Repeat("9", newNT.scale)
+			}
+			conds = append(conds, fmt.Sprintf("ABS(%s) > %s", col, limit))
+		}
+		if newNT.scale < oldNT.scale {
+			conds = append(conds, fmt.Sprintf("%s <> ROUND(%s, %d)", col, col, newNT.scale))
+		}
+		if newNT.unsigned && !oldNT.unsigned {
+			conds = append(conds, fmt.Sprintf("%s < 0", col))
+		}
+		if len(conds) == 0 {
+			return "", false
+		}
+		return strings.Join(conds, " OR "), true
+	}
+
+	return "", false
+}
+
+// numericNarrowingWarning returns a data-safety warning with a pre-flight validation query
+// when a MODIFY COLUMN narrows a numeric type, or ("", false) when the change is not narrowing.
+func numericNarrowingWarning(table, column, oldType, newType string) (string, bool) {
+	pred, ok := numericNarrowingPredicate(column, oldType, newType)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf(
+		"Column '%s' numeric type narrowing detected: %s → %s. Out-of-range values make the ALTER fail under strict SQL mode, or are silently clamped/rounded otherwise. Verify with:\n  SELECT COUNT(*) FROM %s WHERE %s;",
+		column, oldType, newType, table, pred,
+	), true
+}
+
 func validateColumnOperation(input Input, result *Result) {
 	p := input.Parsed
 
 	// Helper to check if column exists
+	// Column names are case-insensitive in MySQL.
 	columnExists := func(colName string) bool {
 		for _, col := range input.Meta.Columns {
-			if col.Name == colName {
+			if strings.EqualFold(col.Name, colName) {
 				return true
 			}
 		}
@@ -1218,22 +4589,30 @@ func validateColumnOperation(input Input, result *Result) {
 
 	switch p.DDLOp {
 	case parser.AddColumn:
-		if columnExists(p.ColumnName) {
-			result.Warnings = append(result.Warnings,
+		switch {
+		case columnExists(p.ColumnName) && p.ColumnIfExists:
+			result.addWarning(WarnColumnOperationNoOp,
+				fmt.Sprintf("Column '%s' already exists: with IF NOT EXISTS this ADD COLUMN is a no-op (a note, not an error).", p.ColumnName))
+		case columnExists(p.ColumnName):
+			result.addWarning(WarnColumnAlreadyExists,
 				fmt.Sprintf("Column '%s' already exists! This ADD COLUMN operation will fail.", p.ColumnName))
 			result.Risk = RiskDangerous
 		}
 
 	case parser.DropColumn:
-		if !columnExists(p.ColumnName) {
-			result.Warnings = append(result.Warnings,
+		switch {
+		case !columnExists(p.ColumnName) && p.ColumnIfExists:
+			result.addWarning(WarnColumnOperationNoOp,
+				fmt.Sprintf("Column '%s' does not exist: with IF EXISTS this DROP COLUMN is a no-op (a note, not an error).", p.ColumnName))
+		case !columnExists(p.ColumnName):
+			result.addWarning(WarnColumnNotFound,
 				fmt.Sprintf("Column '%s' does not exist! This DROP COLUMN operation will fail.", p.ColumnName))
 			result.Risk = RiskDangerous
 		}
 
 	case parser.ModifyColumn:
 		if !columnExists(p.ColumnName) {
-			result.Warnings = append(result.Warnings,
+			result.addWarning(WarnColumnNotFound,
 				fmt.Sprintf("Column '%s' does not exist! This MODIFY COLUMN operation will fail.", p.ColumnName))
 			result.Risk = RiskDangerous
 		}
@@ -1243,18 +4622,95 @@ func validateColumnOperation(input Input, result *Result) {
 		newExists := columnExists(p.NewColumnName)
 
 		if !oldExists {
-			result.Warnings = append(result.Warnings,
+			result.addWarning(WarnColumnNotFound,
 				fmt.Sprintf("Source column '%s' does not exist! This CHANGE COLUMN operation will fail.", p.OldColumnName))
 			result.Risk = RiskDangerous
 		}
 
 		// Only warn about new name if it's different from old name and already exists
-		if p.OldColumnName != p.NewColumnName && newExists {
-			result.Warnings = append(result.Warnings,
+		if !strings.EqualFold(p.OldColumnName, p.NewColumnName) && newExists {
+			result.addWarning(WarnColumnAlreadyExists,
 				fmt.Sprintf("Target column name '%s' already exists! This CHANGE COLUMN operation will fail.", p.NewColumnName))
 			result.Risk = RiskDangerous
 		}
 	}
+
+	// AFTER <col> must name an existing column for ADD/MODIFY/CHANGE COLUMN.
+	switch p.DDLOp {
+	case parser.AddColumn, parser.ModifyColumn, parser.ChangeColumn:
+		if p.AfterColumn != "" && !columnExists(p.AfterColumn) {
+			result.addWarning(WarnColumnNotFound,
+				fmt.Sprintf("AFTER column '%s' does not exist! This %s operation will fail.", p.AfterColumn, strings.ReplaceAll(string(p.DDLOp), "_", " ")))
+			result.Risk = RiskDangerous
+		}
+	}
+}
+
+// applyVersionSupportWarning warns when the server runs a release the classification
+// matrix isn't tuned for: anything but 8.0 and 8.4 LTS. 5.7 is classified with the
+// pre-8.0.12 rules (no INSTANT), newer innovation releases with the 8.0.29+ ones; either
+// way the plan may be wrong, so a SAFE statement is raised to CAUTION. A zero version
+// (unknown) gets no warning.
+func applyVersionSupportWarning(input Input, result *Result) {
+	v := input.Version
+	if v.Major == 0 || (v.Major == 8 && (v.Minor == 0 || v.Minor == 4)) {
+		return
+	}
+	version := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	var msg string
+	if v.Major == 5 {
+		msg = fmt.Sprintf("MySQL %s is not supported: 5.7 has no ALGORITHM=INSTANT and its online DDL differs from 8.0 in places (most ADD COLUMN is INPLACE with a table rebuild). "+
+			"dbsafe classified this statement with its 8.0.0 – 8.0.11 rules, the closest it has, so the algorithm and lock may be inaccurate. ", version)
+	} else {
+		msg = fmt.Sprintf("MySQL %s is a release dbsafe hasn't been validated against: the classification matrix is tuned for 8.0 and 8.4 LTS, and the 8.0.29+ rules were assumed, so the algorithm and lock may be inaccurate. ", version)
+	}
+	msg += "Add an explicit ALGORITHM and LOCK clause to the statement: MySQL rejects it instead of silently taking a slower algorithm or a stronger lock."
+	result.addWarning(WarnUnsupportedVersion, msg)
+	if result.Risk == RiskSafe {
+		result.Risk = RiskCaution
+	}
+}
+
+// upgradeVersions are the newer matrix ranges an upgrade could land on, oldest first, each
+// with a representative version.
+var upgradeVersions = []struct {
+	vr      VersionRange
+	version mysql.ServerVersion
+}{
+	{V8_0_Full, mysql.ServerVersion{Major: 8, Minor: 0, Patch: 29}},
+	{V8_4_LTS, mysql.ServerVersion{Major: 8, Minor: 4, Patch: 0}},
+}
+
+// applyUpgradeBenefit notes when the statement would take a cheaper algorithm on a newer
+// version, e.g. INPLACE on 8.0.20 but INSTANT on 8.0.29+. The statement is classified again
+// by analyzeDDL for each newer range, so the table-specific refinements (a FIRST/AFTER
+// position, an indexed column, the row version limit) apply there too; the oldest range
+// that is cheaper is named.
+func applyUpgradeBenefit(input Input, result *Result) {
+	v := input.Version
+	current, ok := algorithmRank[result.Classification.Algorithm]
+	if !ok || v.Major == 0 {
+		return
+	}
+	vr := classifyVersion(v.Major, v.Minor, v.EffectivePatch())
+	for _, newer := range upgradeVersions {
+		if newer.vr <= vr {
+			continue
+		}
+		hypothetical := input
+		hypothetical.Version = newer.version
+		upgraded := &Result{}
+		analyzeDDL(hypothetical, upgraded)
+		rank, ok := algorithmRank[upgraded.Classification.Algorithm]
+		if !ok || rank >= current {
+			continue
+		}
+		result.addWarning(WarnUpgradeBenefit, fmt.Sprintf(
+			"This operation is %s on your version (%d.%d.%d) but would be %s on %s.",
+			result.Classification.Algorithm, v.Major, v.Minor, v.EffectivePatch(), upgraded.Classification.Algorithm, newer.vr.String(),
+		))
+		return
+	}
 }
 
 func applyTopologyWarnings(input Input, result *Result) {
@@ -1267,12 +4723,66 @@ func applyTopologyWarnings(input Input, result *Result) {
 		applyReplicationWarnings(input, result)
 	case topology.AuroraWriter, topology.AuroraReader:
 		applyAuroraWarnings(input, result)
+	case topology.Proxied:
+		applyProxyWarnings(input, result)
+	}
+
+	applyCutoverWarnings(input, result)
+
+	// RDS-specific advisory: gh-ost needs extra flags on RDS managed MySQL.
+	if input.Topo.IsCloudManaged && input.Topo.CloudProvider == "aws-rds" && result.Method == ExecGhost {
+		result.addClusterWarning(WarnRDSGhostFlags,
+			"AWS RDS: gh-ost runs against the primary without replicas to read from, so the generated command passes --allow-on-master and --assume-rbr. Ensure binary logging is enabled and the IAM/DB user has REPLICATION SLAVE privilege.",
+		)
+	}
+}
+
+// applyCutoverWarnings explains the lock window at the end of an online schema change, for
+// the tool the plan chose (after the topology may have switched gh-ost to pt-osc). Neither
+// tool takes FLUSH TABLES WITH READ LOCK, but the swap needs an exclusive metadata lock on
+// the table: it waits for every open transaction that touched the table, and while it waits
+// every new query on the table queues behind it.
+func applyCutoverWarnings(input Input, result *Result) {
+	if result.StatementType != parser.DDL {
+		return
+	}
+	table := input.Parsed.Table
+	switch result.Method {
+	case ExecGhost:
+		result.addClusterWarning(WarnGhostCutover, fmt.Sprintf(
+			"gh-ost cut-over: the atomic cut-over holds LOCK TABLES ... WRITE on %s while it renames the ghost table into place, "+
+				"so every query on the table stalls for up to --cut-over-lock-timeout-seconds (default 3s) per attempt, and a long-running "+
+				"transaction on the table makes it retry. The generated command postpones the cut-over while /tmp/ghost.postpone.flag exists: "+
+				"create the file before starting, and delete it during a low-traffic window once the row copy has caught up.",
+			table,
+		))
+	case ExecPtOSC:
+		result.addClusterWarning(WarnPtOSCCutover, fmt.Sprintf(
+			"pt-online-schema-change cut-over: as soon as the row copy ends, RENAME TABLE swaps the new table in, which needs an exclusive metadata lock on %s: "+
+				"it waits for open transactions on the table, every new query on it queues meanwhile, and dropping the triggers afterwards takes the lock again. "+
+				"--max-lag only pauses the row copy while replicas lag; it doesn't hold the swap, which runs whenever the copy finishes. "+
+				"Start the run so the copy ends in a low-traffic window, and make sure no long transaction touches the table at the end.",
+			table,
+		))
 	}
+}
 
-	// RDS-specific advisory: gh-ost needs extra flags on RDS managed MySQL.
-	if input.Topo.IsCloudManaged && input.Topo.CloudProvider == "aws-rds" && result.Method == ExecGhost {
-		result.ClusterWarnings = append(result.ClusterWarnings,
-			"AWS RDS: gh-ost requires --allow-on-master and --assume-rbr flags. Ensure binary logging is enabled and the IAM/DB user has REPLICATION SLAVE privilege.",
+func applyProxyWarnings(input Input, result *Result) {
+	proxy := "a proxy"
+	switch input.Topo.Proxy {
+	case "proxysql":
+		proxy = "ProxySQL"
+	case "vitess":
+		proxy = "Vitess vtgate"
+	}
+
+	result.addClusterWarning(WarnProxiedConnection, fmt.Sprintf(
+		"Connected through %s. Metadata and EXPLAIN estimates may come from a different backend than the one that runs the change, and the proxy may rewrite or reject DDL.",
+		proxy,
+	))
+	if result.Method == ExecGhost || result.Method == ExecPtOSC {
+		result.addClusterWarning(WarnProxyOnlineTools,
+			"gh-ost and pt-online-schema-change must connect directly to the backend MySQL primary, not through the proxy: they manage binlog streaming and triggers on the real server. Replace the host in the generated command with the primary's address.",
 		)
 	}
 }
@@ -1280,30 +4790,115 @@ func applyTopologyWarnings(input Input, result *Result) {
 func applyAuroraWarnings(input Input, result *Result) {
 	// Warn if connected to an Aurora read replica — DDL/DML must run on writer.
 	if input.Topo.Type == topology.AuroraReader {
-		result.ClusterWarnings = append(result.ClusterWarnings,
+		result.addClusterWarning(WarnAuroraReader,
 			"Connected to an Aurora READ REPLICA. DDL and DML must be executed on the writer instance.",
 		)
 	}
 
-	// gh-ost is incompatible with Aurora: Aurora uses storage-layer replication, not binlog streaming.
-	// Override to pt-osc and clear the now-invalid alternative.
+	// gh-ost can run against a writer that exposes binlogs, but only with care.
+	if result.Method == ExecGhost && input.Topo.Type == topology.AuroraWriter && input.Topo.AuroraBinlogEnabled {
+		result.addClusterWarning(WarnAuroraGhostBinlog,
+			"Aurora binary logging is enabled, so gh-ost can stream changes from the writer, with caveats: "+
+				"run it against the cluster writer endpoint (the generated command passes --allow-on-master and --assume-rbr), and make sure "+
+				"binlog_format=ROW in the cluster parameter group. Readers share the writer's storage volume, so the "+
+				"row copy still loads the whole cluster and is not throttled by replica lag. "+
+				"pt-online-schema-change remains the safer choice on Aurora.",
+		)
+		return
+	}
+
+	// gh-ost is incompatible with Aurora without binlogs: Aurora uses storage-layer replication,
+	// not binlog streaming. Override to pt-osc and clear the now-invalid alternative.
 	if result.Method == ExecGhost {
-		result.ClusterWarnings = append(result.ClusterWarnings,
+		result.addClusterWarning(WarnGhostIncompatible,
 			"gh-ost is NOT compatible with Aurora MySQL. Aurora uses storage-layer replication instead of MySQL binary log replication. Use pt-online-schema-change instead.",
 		)
 		result.Method = ExecPtOSC
 		result.AlternativeMethod = ""
 		result.AlternativeExecutionCommand = ""
 		result.MethodRationale = auroraGhostRationale
-		result.ExecutionCommand = generatePtOSCCommand(input, false)
+		result.ExecutionCommand = ptoscExecutionCommand(input, false)
+	}
+}
+
+// estimateWriteSet estimates the bytes a DML statement writes to the binary log, which is
+// what Galera and Group Replication certify and limit. Row events carry a before image
+// (DELETE, UPDATE) and an after image (INSERT, UPDATE), sized by binlog_row_image: full
+// logs every column, noblob leaves out BLOB/TEXT/JSON columns that aren't needed, and
+// minimal logs only the primary key before and only the changed columns after. An unknown
+// row image is treated as full; a column whose width can't be bounded counts as a whole row.
+func estimateWriteSet(input Input, rows int64) int64 {
+	meta := input.Meta
+	row := meta.AvgRowLength
+	before, after := row, row
+
+	switch input.Topo.BinlogRowImage {
+	case "minimal":
+		// Without a primary key, the before image holds every column.
+		if pk, ok := rowImageWidth(meta, primaryKeyColumns(meta)); ok {
+			before = min(pk, row)
+		}
+		if changed, ok := rowImageWidth(meta, input.Parsed.UpdateColumns); ok {
+			after = min(changed, row)
+		}
+	case "noblob":
+		var nonBlob []string
+		for _, col := range meta.Columns {
+			if !isBlobType(col.Type) {
+				nonBlob = append(nonBlob, col.Name)
+			}
+		}
+		if len(nonBlob) < len(meta.Columns) {
+			if width, ok := rowImageWidth(meta, nonBlob); ok {
+				before = min(width, row)
+				// Changed BLOB columns are logged in the after image.
+				if _, ok := rowImageWidth(meta, input.Parsed.UpdateColumns); ok {
+					after = before
+				}
+			}
+		}
+	}
+
+	switch input.Parsed.DMLOp {
+	case parser.Delete:
+		return rows * before
+	case parser.Update:
+		return rows * (before + after)
+	}
+	return rows * row
+}
+
+// rowImageWidth returns the worst-case bytes the named columns take in a binlog row image,
+// or false when the list is empty or a column is missing, stored off-page (BLOB, TEXT,
+// JSON) or of a type columnByteWidth doesn't know.
+func rowImageWidth(meta *mysql.TableMetadata, columns []string) (int64, bool) {
+	if len(columns) == 0 {
+		return 0, false
 	}
+	var total int64
+	for _, name := range columns {
+		col := findColumnInfo(meta, name)
+		if col == nil || isBlobType(col.Type) {
+			return 0, false
+		}
+		charset := ""
+		if col.CharacterSet != nil {
+			charset = *col.CharacterSet
+		}
+		width, ok := columnByteWidth(col.Type, charset)
+		if !ok {
+			return 0, false
+		}
+		total += int64(width)
+	}
+	return total, true
 }
 
 func applyGaleraWarnings(input Input, result *Result) {
 	// DDL: warn about TOI impact
 	if result.StatementType == parser.DDL && input.Topo.GaleraOSUMethod == "TOI" {
 		if result.Classification.Algorithm != AlgoInstant {
-			result.ClusterWarnings = append(result.ClusterWarnings, fmt.Sprintf(
+			result.addClusterWarning(WarnGaleraTOI, fmt.Sprintf(
 				"TOI will execute this DDL on ALL %d nodes simultaneously. Consider RSU for large operations: SET wsrep_OSU_method=RSU; then run ALTER on each node individually.",
 				input.Topo.GaleraClusterSize,
 			))
@@ -1313,7 +4908,7 @@ func applyGaleraWarnings(input Input, result *Result) {
 	// DML: warn about write-set size limit
 	if result.StatementType == parser.DML && input.Topo.WsrepMaxWsSize > 0 {
 		if result.WriteSetSize > input.Topo.WsrepMaxWsSize {
-			result.ClusterWarnings = append(result.ClusterWarnings, fmt.Sprintf(
+			result.addClusterWarning(WarnWriteSetTooLarge, fmt.Sprintf(
 				"Estimated write-set (~%s) EXCEEDS wsrep_max_ws_size (%s). Transaction WILL be rejected by Galera. Chunking is MANDATORY.",
 				humanBytes(result.WriteSetSize), humanBytes(input.Topo.WsrepMaxWsSize),
 			))
@@ -1324,7 +4919,7 @@ func applyGaleraWarnings(input Input, result *Result) {
 
 	// Flow control warning
 	if input.Topo.FlowControlPaused > 0.01 {
-		result.ClusterWarnings = append(result.ClusterWarnings, fmt.Sprintf(
+		result.addClusterWarning(WarnFlowControlPaused, fmt.Sprintf(
 			"Flow control paused at %s. Cluster is already under write pressure. Consider waiting or reducing chunk size.",
 			input.Topo.FlowControlPausedPct,
 		))
@@ -1332,14 +4927,14 @@ func applyGaleraWarnings(input Input, result *Result) {
 
 	// gh-ost incompatibility: override to pt-osc and remove the now-invalid alternative.
 	if result.Method == ExecGhost {
-		result.ClusterWarnings = append(result.ClusterWarnings,
+		result.addClusterWarning(WarnGhostIncompatible,
 			"gh-ost is NOT compatible with Galera/PXC. It relies on binlog streaming which conflicts with Galera writeset replication. Use pt-online-schema-change instead.",
 		)
 		result.Method = ExecPtOSC
 		result.AlternativeMethod = ""
 		result.AlternativeExecutionCommand = ""
 		result.MethodRationale = ptOSCOnlyRationale
-		result.ExecutionCommand = generatePtOSCCommand(input, true)
+		result.ExecutionCommand = ptoscExecutionCommand(input, true)
 	}
 }
 
@@ -1347,7 +4942,7 @@ func applyGRWarnings(input Input, result *Result) {
 	// Transaction size limit
 	if result.StatementType == parser.DML && input.Topo.GRTransactionLimit > 0 {
 		if result.WriteSetSize > input.Topo.GRTransactionLimit {
-			result.ClusterWarnings = append(result.ClusterWarnings, fmt.Sprintf(
+			result.addClusterWarning(WarnWriteSetTooLarge, fmt.Sprintf(
 				"Estimated write-set (~%s) EXCEEDS group_replication_transaction_size_limit (%s). Transaction will be rejected. Chunking is MANDATORY.",
 				humanBytes(result.WriteSetSize), humanBytes(input.Topo.GRTransactionLimit),
 			))
@@ -1358,19 +4953,49 @@ func applyGRWarnings(input Input, result *Result) {
 
 	// Multi-primary warning for DDL
 	if result.StatementType == parser.DDL && input.Topo.GRMode == "MULTI-PRIMARY" {
-		result.ClusterWarnings = append(result.ClusterWarnings,
+		result.addClusterWarning(WarnGRMultiPrimary,
 			"Running DDL in multi-primary Group Replication mode. Ensure no conflicting DDL is running on other primaries.",
 		)
 	}
 }
 
 func applyReplicationWarnings(input Input, result *Result) {
+	// Master-master: gh-ost refuses to run unless told it's expected and which side is the
+	// primary; the generated command has --allow-master-master, and --assume-master-host when
+	// the writable side could be determined.
+	if input.Topo.MasterMaster && result.Method == ExecGhost {
+		msg := "Master-master replication: this server's source also replicates from it. gh-ost refuses to run there without --allow-master-master and needs --assume-master-host to know which side takes the writes"
+		if host := ghostAssumeMasterHost(input); host != "" {
+			msg += fmt.Sprintf(": the command assumes %s, the writable side. Check that only that side takes writes.", host)
+		} else {
+			msg += ": dbsafe couldn't tell which side is writable, so give it with --ghost-assume-master-host."
+		}
+		result.addClusterWarning(WarnMasterMaster, msg)
+	}
+
 	if input.Topo.ReplicaLagSecs != nil && *input.Topo.ReplicaLagSecs > 30 {
-		result.ClusterWarnings = append(result.ClusterWarnings, fmt.Sprintf(
+		result.addClusterWarning(WarnReplicationLag, fmt.Sprintf(
 			"Replication lag detected: %d seconds. Large operations will increase lag further. Consider chunking with sleep.",
 			*input.Topo.ReplicaLagSecs,
 		))
 	}
+
+	// Semi-sync primary: each commit waits for a replica ACK. A large write that replicas
+	// can't acknowledge within the timeout makes the primary fall back to async, then switch
+	// back once they catch up — visible to the application as write-latency spikes.
+	if input.Topo.Type == topology.SemiSyncReplica && input.Topo.IsPrimary &&
+		result.StatementType == parser.DML && result.AffectedRows > 10000 {
+		timeout := "rpl_semi_sync_source_timeout"
+		if ms := input.Topo.SemiSyncTimeoutMs; ms > 0 {
+			timeout = fmt.Sprintf("%s (%s)", timeout, time.Duration(ms)*time.Millisecond)
+		}
+		result.addClusterWarning(WarnSemiSyncFallback, fmt.Sprintf(
+			"Semi-sync primary: every commit waits up to %s for a replica ACK. If a chunk takes replicas longer than that to acknowledge, "+
+				"the primary falls back to async replication and switches back when they catch up, causing write-latency spikes. "+
+				"Use smaller chunks than %d rows with longer sleeps (--chunk-size, --sleep-seconds).",
+			timeout, input.chunkSize(),
+		))
+	}
 }
 
 func generateDDLRollback(input Input, result *Result) {
@@ -1445,7 +5070,22 @@ func generateDDLRollback(input Input, result *Result) {
 		}
 
 	case parser.RenameTable:
-		if p.NewTableName != "" {
+		if len(p.RenamePairs) > 1 {
+			// Undo the pairs last to first, each with source and target swapped.
+			qualified := func(database, table string) string {
+				if database == "" {
+					database = db
+				}
+				return fmt.Sprintf("`%s`.`%s`", database, table)
+			}
+			reversed := make([]string, 0, len(p.RenamePairs))
+			for i := len(p.RenamePairs) - 1; i >= 0; i-- {
+				pair := p.RenamePairs[i]
+				reversed = append(reversed, qualified(pair.ToDatabase, pair.To)+" TO "+qualified(pair.FromDatabase, pair.From))
+			}
+			result.RollbackSQL = "RENAME TABLE " + strings.Join(reversed, ", ") + ";"
+			result.RollbackNotes = "Reverses the swap in one atomic RENAME TABLE. Metadata-only. Instant."
+		} else if p.NewTableName != "" {
 			result.RollbackSQL = fmt.Sprintf("RENAME TABLE `%s`.`%s` TO %s;", db, p.NewTableName, tbl)
 			result.RollbackNotes = "RENAME TABLE is a metadata-only operation. Instant."
 		} else {
@@ -1460,6 +5100,18 @@ func generateDDLRollback(input Input, result *Result) {
 			result.RollbackNotes = "Reverse the RENAME INDEX with the original and new names swapped."
 		}
 
+	case parser.ChangeIndexVisibility:
+		if p.IndexName != "" {
+			visibility := "INVISIBLE"
+			if p.IndexInvisible {
+				visibility = "VISIBLE"
+			}
+			result.RollbackSQL = fmt.Sprintf("ALTER TABLE %s ALTER INDEX `%s` %s;", tbl, p.IndexName, visibility)
+			result.RollbackNotes = "Index visibility is a metadata-only change. Instant."
+		} else {
+			result.RollbackNotes = "Reverse the ALTER INDEX with the opposite visibility."
+		}
+
 	case parser.ChangeEngine:
 		if input.Meta != nil && input.Meta.Engine != "" {
 			result.RollbackSQL = fmt.Sprintf("ALTER TABLE %s ENGINE=%s;", tbl, input.Meta.Engine)
@@ -1501,7 +5153,7 @@ func generateDDLRollback(input Input, result *Result) {
 			result.RollbackNotes = "Cannot determine original AUTO_INCREMENT value."
 		}
 
-	case parser.CreateTable:
+	case parser.CreateTable, parser.CreateTableLike, parser.CreateTableAsSelect:
 		result.RollbackSQL = fmt.Sprintf("DROP TABLE IF EXISTS %s;", tbl)
 		result.RollbackNotes = "WARNING: DROP TABLE is irreversible and destroys all data."
 
@@ -1511,7 +5163,7 @@ func generateDDLRollback(input Input, result *Result) {
 	case parser.ConvertCharset:
 		result.RollbackNotes = "CONVERT TO CHARACTER SET rewrites all string columns. Revert using the original charset from SHOW CREATE TABLE."
 
-	case parser.ForceRebuild, parser.OptimizeTable:
+	case parser.ForceRebuild, parser.OptimizeTable, parser.OrderBy:
 		result.RollbackNotes = "No rollback needed. This operation rebuilds the table in place without changing its definition."
 
 	case parser.AddPartition:
@@ -1526,6 +5178,20 @@ func generateDDLRollback(input Input, result *Result) {
 	case parser.ReorganizePartition, parser.RebuildPartition:
 		result.RollbackNotes = "Rebuild/reorganize is a structural change. Use SHOW CREATE TABLE to reconstruct the original partitioning."
 
+	case parser.EngineAttribute, parser.SecondaryEngine:
+		result.RollbackNotes = "Metadata-only. Restore the previous value from SHOW CREATE TABLE with another ALTER TABLE."
+
+	case parser.SecondaryLoad:
+		result.RollbackSQL = fmt.Sprintf("ALTER TABLE %s SECONDARY_UNLOAD;", tbl)
+		result.RollbackNotes = "Unloading removes the secondary engine's copy only; the InnoDB table is untouched."
+
+	case parser.SecondaryUnload:
+		result.RollbackSQL = fmt.Sprintf("ALTER TABLE %s SECONDARY_LOAD;", tbl)
+		result.RollbackNotes = "Reloading reads the whole table again."
+
+	case parser.RemovePartitioning, parser.RepartitionTable:
+		result.RollbackNotes = "Restore the original partitioning with ALTER TABLE ... PARTITION BY using the clause from SHOW CREATE TABLE (or REMOVE PARTITIONING if the table wasn't partitioned). This is another full table copy."
+
 	case parser.MultipleOps:
 		result.RollbackNotes = "Multi-operation ALTER TABLE. Review each sub-operation individually to determine rollback steps."
 
@@ -1542,6 +5208,10 @@ func generateDMLRollback(input Input, result *Result) {
 	// Option A: Pre-backup
 	backupTable := fmt.Sprintf("%s_backup_%s", table, ts)
 	backupSQL := fmt.Sprintf("CREATE TABLE `%s`.`%s` AS\nSELECT * FROM `%s`.`%s`", db, backupTable, db, table)
+	if multiTableDML(input.Parsed) {
+		// Back up the target's rows through the join; DISTINCT, as a row can match several times.
+		backupSQL = fmt.Sprintf("CREATE TABLE `%s`.`%s` AS\nSELECT DISTINCT %s.* FROM %s", db, backupTable, input.Parsed.TargetRef, input.Parsed.FromClause)
+	}
 	if input.Parsed.HasWhere {
 		backupSQL += fmt.Sprintf("\nWHERE %s", input.Parsed.WhereClause)
 	}
@@ -1566,28 +5236,111 @@ func generateDMLRollback(input Input, result *Result) {
 	})
 }
 
+// defaultChunkSleep is the pause between chunks when Input.SleepSeconds is not set.
+const defaultChunkSleep = 0.5
+
+func chunkSleep(input Input) float64 {
+	if input.SleepSeconds != nil {
+		return *input.SleepSeconds
+	}
+	return defaultChunkSleep
+}
+
+// primaryKeyColumns returns the columns of the table's PRIMARY KEY, or nil if it has none.
+func primaryKeyColumns(meta *mysql.TableMetadata) []string {
+	for _, idx := range meta.Indexes {
+		if strings.EqualFold(idx.Name, "PRIMARY") {
+			return idx.Columns
+		}
+	}
+	return nil
+}
+
+// updateChunkColumn returns the column an UPDATE chunked script walks in ranges: the table's
+// single-column integer primary key when it has one (ok is true), otherwise "id" for the
+// user to replace.
+func updateChunkColumn(meta *mysql.TableMetadata) (column string, ok bool) {
+	if pk := pkRangeColumn(meta); pk != "" {
+		return pk, true
+	}
+	return "id", false
+}
+
+// pkRangeColumn returns the primary key column when the table has a single-column integer
+// primary key that chunked scripts can walk in explicit ranges, or "".
+func pkRangeColumn(meta *mysql.TableMetadata) string {
+	pk := primaryKeyColumns(meta)
+	if len(pk) != 1 {
+		return ""
+	}
+	for _, col := range meta.Columns {
+		if !strings.EqualFold(col.Name, pk[0]) {
+			continue
+		}
+		if nt, ok := parseNumericType(col.Type); ok {
+			if _, isInt := integerBounds[nt.base]; isInt {
+				return col.Name
+			}
+		}
+	}
+	return ""
+}
+
 func generateChunkedScript(input Input, result *Result) {
+	if input.MaxReplicaLag > 0 {
+		generateLagAwareChunkedScript(input, result)
+		return
+	}
+
 	// This is a simplified chunked script generator
 	// Real implementation would detect the best column to chunk on (usually PK)
 	db := result.Database
 	table := result.Table
 	ts := time.Now().Format("20060102_150405")
+	sleep := strconv.FormatFloat(chunkSleep(input), 'f', -1, 64)
 
 	var script strings.Builder
 	script.WriteString("-- dbsafe generated chunked script\n")
 	fmt.Fprintf(&script, "-- Table: %s.%s\n", db, table)
 	fmt.Fprintf(&script, "-- Estimated rows: %d\n", result.AffectedRows)
-	fmt.Fprintf(&script, "-- Chunk size: %d\n", input.ChunkSize)
-	fmt.Fprintf(&script, "-- Generated: %s\n\n", time.Now().Format(time.RFC3339))
-
-	fmt.Fprintf(&script, "SET @batch_size = %d;\n", input.ChunkSize)
-	script.WriteString("SET @sleep_time = 0.5;\n\n")
+	fmt.Fprintf(&script, "-- Chunk size: %d\n", input.chunkSize())
+	fmt.Fprintf(&script, "-- Generated: %s\n", time.Now().Format(time.RFC3339))
+	script.WriteString("--\n")
+	script.WriteString("-- Tunables:\n")
+	script.WriteString("--   @batch_size  rows changed per chunk (--chunk-size / --batch-size)\n")
+	script.WriteString("--   @sleep_time  seconds to pause between chunks (--sleep-seconds)\n")
+	script.WriteString("-- For replication-sensitive environments, regenerate with --max-replica-lag to get a\n")
+	script.WriteString("-- shell script that checks replica lag between chunks.\n\n")
+
+	fmt.Fprintf(&script, "SET @batch_size = %d;\n", input.chunkSize())
+	fmt.Fprintf(&script, "SET @sleep_time = %s;\n\n", sleep)
 
 	script.WriteString("-- Loop: execute in batches\n")
 	script.WriteString("-- Adjust @batch_size and @sleep_time as needed\n")
 
-	switch input.Parsed.DMLOp {
-	case parser.Delete:
+	pk := pkRangeColumn(input.Meta)
+	switch {
+	case input.Parsed.DMLOp == parser.Delete && pk != "":
+		// Explicit PK ranges instead of LIMIT: each chunk locks only its own PK range, avoiding
+		// the secondary-index gap locks that deadlock with concurrent inserts.
+		fmt.Fprintf(&script, `
+SET @min_pk = (SELECT MIN(%[3]s) FROM %[1]s.%[2]s WHERE %[4]s);
+SET @max_pk = (SELECT MAX(%[3]s) FROM %[1]s.%[2]s WHERE %[4]s);
+SET @current = @min_pk;
+
+WHILE @current <= @max_pk DO
+    DELETE FROM %[1]s.%[2]s
+    WHERE (%[4]s)
+      AND %[3]s BETWEEN @current AND @current + @batch_size - 1;
+    
+    SELECT CONCAT('Deleted ', ROW_COUNT(), ' rows with %[5]s up to ', @current + @batch_size - 1) AS progress;
+    SET @current = @current + @batch_size;
+    
+    DO SLEEP(@sleep_time);
+END WHILE;
+`, "`"+db+"`", "`"+table+"`", "`"+pk+"`", input.Parsed.WhereClause, pk)
+
+	case input.Parsed.DMLOp == parser.Delete:
 		fmt.Fprintf(&script, `
 SET @affected = 1;
 WHILE @affected > 0 DO
@@ -1602,32 +5355,233 @@ WHILE @affected > 0 DO
 END WHILE;
 `, "`"+db+"`", "`"+table+"`", input.Parsed.WhereClause)
 
-	case parser.Update:
+	case input.Parsed.DMLOp == parser.Update:
+		col, ok := updateChunkColumn(input.Meta)
 		script.WriteString("-- UPDATE chunking requires a primary key column.\n")
 		script.WriteString("-- Use the PK to iterate in ranges.\n")
-		script.WriteString("-- Example pattern (adjust for your PK column):\n\n")
+		if ok {
+			fmt.Fprintf(&script, "-- Example pattern (walks the primary key %s):\n\n", col)
+		} else {
+			script.WriteString("-- Example pattern (the table has no single-column integer PK: replace id with a column to walk):\n\n")
+		}
 		fmt.Fprintf(&script, `
-SET @min_id = (SELECT MIN(id) FROM %s.%s WHERE %s);
-SET @max_id = (SELECT MAX(id) FROM %s.%s WHERE %s);
+SET @min_id = (SELECT MIN(%[3]s) FROM %[1]s.%[2]s WHERE %[4]s);
+SET @max_id = (SELECT MAX(%[3]s) FROM %[1]s.%[2]s WHERE %[4]s);
 SET @current = @min_id;
 
 WHILE @current <= @max_id DO
-    -- Replace this with your actual UPDATE statement
-    -- %s
-    -- AND id BETWEEN @current AND @current + @batch_size - 1;
+    UPDATE %[1]s.%[2]s SET %[5]s
+    WHERE (%[4]s)
+      AND %[3]s BETWEEN @current AND @current + @batch_size - 1;
     
+    SELECT CONCAT('Updated ', ROW_COUNT(), ' rows with %[6]s up to ', @current + @batch_size - 1) AS progress;
     SET @current = @current + @batch_size;
     DO SLEEP(@sleep_time);
 END WHILE;
-`, "`"+db+"`", "`"+table+"`", input.Parsed.WhereClause,
-			"`"+db+"`", "`"+table+"`", input.Parsed.WhereClause,
-			input.Parsed.RawSQL)
+`, "`"+db+"`", "`"+table+"`", "`"+col+"`", input.Parsed.WhereClause, input.Parsed.SetClause, col)
 	}
 
 	result.GeneratedScript = script.String()
 	result.ScriptPath = fmt.Sprintf("./dbsafe-plan-%s-%s-%s.sql", table, strings.ToLower(string(input.Parsed.DMLOp)), ts)
 }
 
+// generateLagAwareChunkedScript writes the chunked DML as a bash script that runs each chunk
+// through the mysql client and, between chunks, waits while any replica's Seconds_Behind_Source
+// exceeds the threshold. SQL can't read a replica's lag from the primary, hence the shell wrapper.
+func generateLagAwareChunkedScript(input Input, result *Result) {
+	db := result.Database
+	table := result.Table
+	ts := time.Now().Format("20060102_150405")
+	target := "`" + db + "`.`" + table + "`"
+
+	var script strings.Builder
+	script.WriteString("#!/usr/bin/env bash\n")
+	script.WriteString("# dbsafe generated chunked script (replication-lag aware)\n")
+	fmt.Fprintf(&script, "# Table: %s.%s\n", db, table)
+	fmt.Fprintf(&script, "# Estimated rows: %d\n", result.AffectedRows)
+	fmt.Fprintf(&script, "# Chunk size: %d\n", input.chunkSize())
+	fmt.Fprintf(&script, "# Generated: %s\n", time.Now().Format(time.RFC3339))
+	script.WriteString("#\n")
+	script.WriteString("# Tunables (override via environment):\n")
+	script.WriteString("#   BATCH_SIZE     rows changed per chunk\n")
+	script.WriteString("#   SLEEP_SECONDS  seconds to pause between chunks\n")
+	script.WriteString("#   MAX_LAG        wait while any replica's Seconds_Behind_Source exceeds this many seconds\n")
+	script.WriteString("#   MAX_BACKOFF    longest single wait (seconds) while replicas catch up\n")
+	script.WriteString("#   REPLICAS       space-separated replica hosts to check (required)\n")
+	script.WriteString("#   MYSQL          client command for the primary; credentials from ~/.my.cnf or --defaults-file\n")
+	script.WriteString("#   MYSQL_REPLICA  client command for the replicas (the host is appended with -h)\n")
+	script.WriteString("set -euo pipefail\n\n")
+
+	fmt.Fprintf(&script, "BATCH_SIZE=\"${BATCH_SIZE:-%d}\"\n", input.chunkSize())
+	fmt.Fprintf(&script, "SLEEP_SECONDS=\"${SLEEP_SECONDS:-%s}\"\n", strconv.FormatFloat(chunkSleep(input), 'f', -1, 64))
+	fmt.Fprintf(&script, "MAX_LAG=\"${MAX_LAG:-%d}\"\n", input.MaxReplicaLag)
+	script.WriteString("MAX_BACKOFF=\"${MAX_BACKOFF:-30}\"\n")
+	script.WriteString("REPLICAS=\"${REPLICAS:?set REPLICAS to the replica hosts to monitor}\"\n")
+	script.WriteString("MYSQL=\"${MYSQL:-mysql}\"\n")
+	script.WriteString("MYSQL_REPLICA=\"${MYSQL_REPLICA:-$MYSQL}\"\n")
+	script.WriteString(`
+# Highest Seconds_Behind_Source across REPLICAS. A stopped replica (NULL) counts as lagging.
+replica_lag() {
+    local max=0 lag host
+    for host in $REPLICAS; do
+        lag=$($MYSQL_REPLICA -h "$host" -e 'SHOW REPLICA STATUS\G' | awk '/Seconds_Behind_Source:/ {print $2}')
+        if [ -z "$lag" ] || [ "$lag" = "NULL" ]; then
+            echo "replica $host: replication is not running" >&2
+            lag=$((MAX_LAG + 1))
+        fi
+        if [ "$lag" -gt "$max" ]; then
+            max=$lag
+        fi
+    done
+    echo "$max"
+}
+
+# Sleep between chunks, extending the pause while replicas are behind.
+pause() {
+    local lag
+    sleep "$SLEEP_SECONDS"
+    while lag=$(replica_lag) && [ "$lag" -gt "$MAX_LAG" ]; do
+        echo "replica lag ${lag}s exceeds ${MAX_LAG}s, backing off" >&2
+        sleep $((lag < MAX_BACKOFF ? lag : MAX_BACKOFF))
+    done
+}
+`)
+
+	pk := pkRangeColumn(input.Meta)
+	switch {
+	case input.Parsed.DMLOp == parser.Delete && pk != "":
+		fmt.Fprintf(&script, `
+read -r -d '' BOUNDS_SQL <<'SQL' || true
+SELECT COALESCE(MIN(%[2]s), 0), COALESCE(MAX(%[2]s), -1) FROM %[1]s WHERE %[3]s
+SQL
+read -r -d '' CHUNK_SQL <<'SQL' || true
+DELETE FROM %[1]s
+WHERE (%[3]s)
+AND %[2]s BETWEEN
+SQL
+
+read -r current max_pk <<<"$($MYSQL -N -e "$BOUNDS_SQL")"
+while [ "$current" -le "$max_pk" ]; do
+    affected=$($MYSQL -N -e "$CHUNK_SQL $current AND $((current + BATCH_SIZE - 1)); SELECT ROW_COUNT();")
+    echo "Deleted $affected rows with %[4]s $current..$((current + BATCH_SIZE - 1))"
+    current=$((current + BATCH_SIZE))
+    pause
+done
+`, target, "`"+pk+"`", input.Parsed.WhereClause, pk)
+
+	case input.Parsed.DMLOp == parser.Delete:
+		fmt.Fprintf(&script, `
+read -r -d '' CHUNK_SQL <<'SQL' || true
+DELETE FROM %s
+WHERE %s
+LIMIT
+SQL
+
+affected=1
+while [ "$affected" -gt 0 ]; do
+    affected=$($MYSQL -N -e "$CHUNK_SQL $BATCH_SIZE; SELECT ROW_COUNT();")
+    echo "Deleted $affected rows"
+    pause
+done
+`, target, input.Parsed.WhereClause)
+
+	case input.Parsed.DMLOp == parser.Update:
+		col, ok := updateChunkColumn(input.Meta)
+		script.WriteString("\n# UPDATE chunking requires a primary key column.\n")
+		if ok {
+			fmt.Fprintf(&script, "# Walks the primary key %s in ranges", col)
+		} else {
+			script.WriteString("# The table has no single-column integer primary key: replace id with a column to walk in ranges")
+		}
+		script.WriteString(".\n")
+		fmt.Fprintf(&script, `
+read -r -d '' BOUNDS_SQL <<'SQL' || true
+SELECT COALESCE(MIN(%[2]s), 0), COALESCE(MAX(%[2]s), -1) FROM %[1]s WHERE %[3]s
+SQL
+read -r -d '' CHUNK_SQL <<'SQL' || true
+UPDATE %[1]s SET %[4]s
+WHERE (%[3]s)
+AND %[2]s BETWEEN
+SQL
+
+read -r current max_id <<<"$($MYSQL -N -e "$BOUNDS_SQL")"
+while [ "$current" -le "$max_id" ]; do
+    $MYSQL -e "$CHUNK_SQL $current AND $((current + BATCH_SIZE - 1))"
+    echo "Updated %[5]s $current..$((current + BATCH_SIZE - 1))"
+    current=$((current + BATCH_SIZE))
+    pause
+done
+`, target, "`"+col+"`", input.Parsed.WhereClause, input.Parsed.SetClause, col)
+	}
+
+	result.GeneratedScript = script.String()
+	result.ScriptPath = fmt.Sprintf("./dbsafe-plan-%s-%s-%s.sh", table, strings.ToLower(string(input.Parsed.DMLOp)), ts)
+}
+
+// applyDiskSpaceCheck escalates to DANGEROUS when the disk estimate exceeds the free space
+// on the data directory's filesystem: running out of disk mid-ALTER fails the statement
+// after hours of work, and can take the whole server down with it.
+func applyDiskSpaceCheck(input Input, result *Result) {
+	if input.FreeDiskBytes <= 0 || result.DiskEstimate == nil || result.DiskEstimate.RequiredBytes <= input.FreeDiskBytes {
+		return
+	}
+	result.Risk = RiskDangerous
+	result.addWarning(WarnInsufficientDiskSpace, fmt.Sprintf(
+		"Insufficient disk space: the operation needs ~%s, but only %s is free on the data directory's filesystem. "+
+			"Free up space or grow the volume first: running out of disk fails the statement after the copy has done most of its work, and can stop the server.",
+		result.DiskEstimate.RequiredHuman, humanBytes(input.FreeDiskBytes),
+	))
+}
+
+// applyBinlogVolume estimates the binary log the operation writes and warns when it could
+// outgrow the binary log's filesystem or binlog_space_limit. Must run after the method is
+// final: a native ALTER logs only its statement, but gh-ost and pt-osc copy every row
+// through row events.
+func applyBinlogVolume(input Input, result *Result) {
+	if input.ServerConfig.BinlogDisabled {
+		return
+	}
+	volume := estimateBinlogBytes(input, result)
+	result.EstimatedBinlogBytes = volume
+	if volume == 0 {
+		return
+	}
+
+	if free := input.BinlogFreeBytes; free > 0 && volume > free {
+		result.Risk = RiskDangerous
+		result.addWarning(WarnBinlogVolume, fmt.Sprintf(
+			"The operation writes ~%s to the binary log, but only %s is free on its filesystem. "+
+				"A full binlog disk stops every write on the server (binlog_error_action=ABORT_SERVER by default): free up space, or purge the logs every replica has applied, first.",
+			humanBytes(volume), humanBytes(free),
+		))
+		return
+	}
+	if limit := input.ServerConfig.BinlogSpaceLimit; limit > 0 && volume > limit/2 {
+		result.addWarning(WarnBinlogVolume, fmt.Sprintf(
+			"The operation writes ~%s to the binary log, %.0f%% of binlog_space_limit (%s). "+
+				"To stay under the limit the server purges the oldest binary logs, even ones a lagging replica or a running gh-ost hasn't read yet: "+
+				"make sure they keep up, or raise binlog_space_limit for the duration.",
+			humanBytes(volume), float64(volume)/float64(limit)*100, humanBytes(limit),
+		))
+	}
+}
+
+// estimateBinlogBytes returns the row events the operation writes to the binary log. DML
+// logs its write set, without cascaded child rows: replicas apply those through their own
+// foreign keys. CREATE TABLE ... AS SELECT logs the copied rows, and gh-ost and pt-osc the
+// whole table as they copy it. A native ALTER, COPY included, logs only the statement.
+func estimateBinlogBytes(input Input, result *Result) int64 {
+	switch {
+	case result.StatementType == parser.DML:
+		return estimateWriteSet(input, result.AffectedRows)
+	case input.Parsed.DDLOp == parser.CreateTableAsSelect:
+		return result.WriteSetSize
+	case result.Method == ExecGhost || result.Method == ExecPtOSC:
+		return input.Meta.DataLength
+	}
+	return 0
+}
+
 // estimateDiskSpace returns the additional disk space needed for a DDL operation,
 // or nil if no significant extra space is required (INSTANT algorithm or table < 100 MB).
 // Must be called after applyTopologyWarnings so that the final Method is reflected.
@@ -1639,6 +5593,18 @@ func estimateDiskSpace(input Input, result *Result) *DiskSpaceEstimate {
 		return nil
 	}
 
+	// CREATE TABLE ... AS SELECT writes a new table sized by the SELECT result
+	if input.Parsed.DDLOp == parser.CreateTableAsSelect {
+		if result.WriteSetSize < threshold {
+			return nil
+		}
+		return &DiskSpaceEstimate{
+			RequiredBytes: result.WriteSetSize,
+			RequiredHuman: humanBytes(result.WriteSetSize),
+			Reason:        "CREATE TABLE ... AS SELECT writes a new table holding every row returned by the SELECT",
+		}
+	}
+
 	// gh-ost and pt-osc both create a full shadow table during migration
 	if result.Method == ExecGhost {
 		total := input.Meta.TotalSize()
@@ -1766,9 +5732,24 @@ func extractAlterSpec(sql string) string {
 		return "" // Couldn't find table name
 	}
 
-	// Return everything after the table name
+	// Return everything after the table name. The online schema change tools alter their own
+	// shadow table, so ALGORITHM=/LOCK= hints meant for the original table are dropped, and
+	// so is a WAIT n / NOWAIT clause, which only MariaDB accepts there.
 	alterSpec := strings.TrimSpace(remaining[tableEnd:])
-	return alterSpec
+	alterSpec = reLockWaitClause.ReplaceAllString(alterSpec, "")
+	return stripAlgorithmLockHints(alterSpec)
+}
+
+// lockWaitModifierNote describes what a WAIT n or NOWAIT clause changes: how long the
+// statement queues for a metadata lock before failing.
+func lockWaitModifierNote(p *parser.ParsedSQL) string {
+	wait := "NOWAIT: the statement fails at once with a lock wait timeout if another session holds a metadata lock on the table, instead of queuing for it (and stalling the queries behind it)."
+	if p.LockWait == "WAIT" {
+		wait = fmt.Sprintf("WAIT %d: the statement waits at most %d seconds for a metadata lock on the table, then fails with a lock wait timeout; queries on the table queue behind it while it waits.",
+			p.LockWaitSeconds, p.LockWaitSeconds)
+	}
+	return wait + " The bound applies to every metadata lock the statement takes, including the upgrade to an exclusive lock at the end of an online ALTER: " +
+		"a transaction that opens on the table while it runs can make it fail after all the work is done."
 }
 
 // generateGhostCommand generates a gh-ost command for the given DDL.
@@ -1785,6 +5766,9 @@ func generateGhostCommand(input Input) string {
 	var cmd strings.Builder
 	cmd.WriteString("gh-ost \\\n")
 	fmt.Fprintf(&cmd, "  --user=\"%s\" \\\n", input.Connection.User)
+	if input.Connection.PasswordEnv != "" {
+		fmt.Fprintf(&cmd, "  --password=\"$%s\" \\\n", input.Connection.PasswordEnv)
+	}
 
 	if input.Connection.Socket != "" {
 		fmt.Fprintf(&cmd, "  --socket=\"%s\" \\\n", input.Connection.Socket)
@@ -1792,24 +5776,450 @@ func generateGhostCommand(input Input) string {
 		fmt.Fprintf(&cmd, "  --host=\"%s\" \\\n", input.Connection.Host)
 		fmt.Fprintf(&cmd, "  --port=%d \\\n", input.Connection.Port)
 	}
+	if input.Connection.TLS {
+		cmd.WriteString("  --ssl \\\n")
+		if input.Connection.TLSCA != "" {
+			fmt.Fprintf(&cmd, "  --ssl-ca=\"%s\" \\\n", input.Connection.TLSCA)
+		}
+		if input.Connection.TLSSkipVerify {
+			cmd.WriteString("  --ssl-allow-insecure \\\n")
+		}
+	}
 
 	fmt.Fprintf(&cmd, "  --database=\"%s\" \\\n", input.Parsed.Database)
 	fmt.Fprintf(&cmd, "  --table=\"%s\" \\\n", input.Parsed.Table)
 	fmt.Fprintf(&cmd, "  --alter=\"%s\" \\\n", alterSpec)
 	cmd.WriteString("  --assume-rbr \\\n")
+	if ghostAllowOnMaster(input) {
+		cmd.WriteString("  --allow-on-master \\\n")
+	}
+	if input.Topo != nil && input.Topo.MasterMaster {
+		cmd.WriteString("  --allow-master-master \\\n")
+	}
+	if host := ghostAssumeMasterHost(input); host != "" {
+		fmt.Fprintf(&cmd, "  --assume-master-host=\"%s\" \\\n", host)
+	}
+	if id := input.GhostReplication.ReplicaServerID; id > 0 {
+		fmt.Fprintf(&cmd, "  --replica-server-id=%d \\\n", id)
+	}
 	cmd.WriteString("  --cut-over=default \\\n")
 	cmd.WriteString("  --exact-rowcount \\\n")
 	cmd.WriteString("  --concurrent-rowcount \\\n")
 	cmd.WriteString("  --default-retries=120 \\\n")
 	cmd.WriteString("  --panic-flag-file=/tmp/ghost.panic.flag \\\n")
 	cmd.WriteString("  --postpone-cut-over-flag-file=/tmp/ghost.postpone.flag \\\n")
+	if input.WithHooks {
+		fmt.Fprintf(&cmd, "  --hooks-path=\"%s\" \\\n", ghostHooksPath)
+	}
 	cmd.WriteString("  --execute")
 
+	if input.WithHooks {
+		cmd.WriteString("\n\n" + ghostHooksScaffold(input.Parsed.Table))
+	}
+	return cmd.String()
+}
+
+// GhostReplication overrides how generated gh-ost commands find the primary in complex
+// replication. AssumeMasterHost (host[:port]) is the --assume-master-host, for when gh-ost
+// can't work the primary out by crawling up from the server it connects to: master-master,
+// or a chain whose Source_Host it can't reach. ReplicaServerID is the --replica-server-id
+// gh-ost reads the binlogs with, when its default (99999) is taken by a server or another
+// running migration. Zero values leave the flags out.
+type GhostReplication struct {
+	AssumeMasterHost string
+	ReplicaServerID  int
+}
+
+// ghostAllowOnMaster reports whether a generated gh-ost command needs --allow-on-master:
+// on RDS and on an Aurora writer with binary logging, gh-ost reads the binlogs of the
+// primary it connects to instead of a replica's.
+func ghostAllowOnMaster(input Input) bool {
+	if input.Topo == nil {
+		return false
+	}
+	return (input.Topo.IsCloudManaged && input.Topo.CloudProvider == "aws-rds") ||
+		(input.Topo.Type == topology.AuroraWriter && input.Topo.AuroraBinlogEnabled)
+}
+
+// ghostAssumeMasterHost returns the --assume-master-host of a generated gh-ost command: the
+// one given in Input.GhostReplication, otherwise, in master-master replication, the writable
+// side: the connected server unless it is read_only, in which case the source it replicates
+// from. "" leaves the flag out.
+func ghostAssumeMasterHost(input Input) string {
+	if host := input.GhostReplication.AssumeMasterHost; host != "" {
+		return host
+	}
+	if input.Topo == nil || !input.Topo.MasterMaster {
+		return ""
+	}
+	if input.Topo.ReadOnly && input.Topo.SourceHost != "" {
+		if input.Topo.SourcePort > 0 {
+			return fmt.Sprintf("%s:%d", input.Topo.SourceHost, input.Topo.SourcePort)
+		}
+		return input.Topo.SourceHost
+	}
+	if !input.Topo.ReadOnly && input.Connection.Socket == "" && input.Connection.Host != "" {
+		return fmt.Sprintf("%s:%d", input.Connection.Host, input.Connection.Port)
+	}
+	return ""
+}
+
+// ghostHooksPath is the --hooks-path of generated gh-ost commands.
+const ghostHooksPath = "./gh-ost-hooks"
+
+// ghostHooksScaffold returns a commented script that sets up a gh-ost hooks directory with
+// a Slack notification before the cut-over. gh-ost runs every executable in --hooks-path
+// whose name starts with a hook name, passing the migration's details in GH_OST_*
+// environment variables; a failing gh-ost-on-before-cut-over hook postpones the cut-over.
+func ghostHooksScaffold(table string) string {
+	hook := ghostHooksPath + "/gh-ost-on-before-cut-over-notify"
+	var s strings.Builder
+	s.WriteString("# Hooks scaffold: gh-ost runs the executables in --hooks-path whose names start with a hook name\n")
+	s.WriteString("# (gh-ost-on-startup, gh-ost-on-row-copy-complete, gh-ost-on-begin-postponed, gh-ost-on-before-cut-over,\n")
+	s.WriteString("# gh-ost-on-success, gh-ost-on-failure, ...), with GH_OST_DATABASE_NAME, GH_OST_TABLE_NAME,\n")
+	s.WriteString("# GH_OST_ELAPSED_SECONDS and more in the environment. Uncomment to notify Slack before the cut-over:\n")
+	s.WriteString("#\n")
+	fmt.Fprintf(&s, "# mkdir -p %s\n", ghostHooksPath)
+	fmt.Fprintf(&s, "# cat > %s <<'HOOK'\n", hook)
+	s.WriteString("# #!/usr/bin/env bash\n")
+	s.WriteString("# curl -fsS -X POST -H 'Content-type: application/json' \\\n")
+	fmt.Fprintf(&s, "#   --data \"{\\\"text\\\":\\\"gh-ost: cutting over ${GH_OST_DATABASE_NAME}.${GH_OST_TABLE_NAME} after ${GH_OST_ELAPSED_SECONDS}s\\\"}\" \\\n")
+	s.WriteString("#   \"$SLACK_WEBHOOK_URL\"\n")
+	s.WriteString("# HOOK\n")
+	fmt.Fprintf(&s, "# chmod +x %s\n", hook)
+	s.WriteString("#\n")
+	fmt.Fprintf(&s, "# Copy the file to gh-ost-on-success-* and gh-ost-on-failure-* to hear how the migration of %s ends.", table)
+	return s.String()
+}
+
+// pt-osc load thresholds used when max_connections is unknown.
+const (
+	defaultPtOSCMaxLoad      = 25
+	defaultPtOSCCriticalLoad = 50
+	minPtOSCMaxLoad          = 5
+)
+
+// ptoscLoadThresholds scales pt-osc's --max-load (pause) and --critical-load (abort)
+// Threads_running thresholds to the server. A quarter of max_connections is taken as the
+// ceiling of useful concurrency; --critical-load sits at 80% of it and --max-load at half
+// of that. --max-load is kept at least twice the sampled Threads_running baseline so the
+// copy doesn't stall on normal traffic, and --critical-load at least twice --max-load.
+// Both are capped at max_connections. Falls back to 25/50 when max_connections is unknown.
+func ptoscLoadThresholds(maxConnections, threadsRunning int64) (maxLoad, criticalLoad int64) {
+	if maxConnections <= 0 {
+		return defaultPtOSCMaxLoad, defaultPtOSCCriticalLoad
+	}
+	ceiling := maxConnections / 4
+	criticalLoad = ceiling * 8 / 10
+	maxLoad = max(criticalLoad/2, threadsRunning*2, minPtOSCMaxLoad)
+	criticalLoad = min(max(criticalLoad, maxLoad*2), maxConnections)
+	maxLoad = min(maxLoad, criticalLoad)
+	return maxLoad, criticalLoad
+}
+
+// PtOSCChunking overrides the chunking flags of generated pt-online-schema-change commands:
+// pt-osc starts at ChunkSize rows and resizes chunks to take ChunkTime seconds each, skipping
+// chunks over ChunkSizeLimit times the chunk size. Zero values use the defaults: 1000 rows,
+// a chunk time picked for the topology, and pt-osc's own limit of 4.
+type PtOSCChunking struct {
+	ChunkSize      int
+	ChunkTime      float64
+	ChunkSizeLimit float64
+}
+
+// ptoscOptions controls the run mode of a generated pt-online-schema-change command.
+type ptoscOptions struct {
+	Galera         bool // add flow-control and plan-check flags for Galera/PXC
+	DryRun         bool // --dry-run instead of --execute
+	NoDropOldTable bool // keep the original table as _<table>_old after the swap
+	Chunking       PtOSCChunking
+	Recursion      string // --recursion-method; "" picks one from the topology
+}
+
+// defaultPtOSCDSNTable is the DSN table of --recursion-method=dsn when none is given.
+const defaultPtOSCDSNTable = "D=percona,t=dsns"
+
+// recursionMethod returns the --recursion-method to emit: the one given, or one picked for
+// the topology. Managed services report replicas at addresses pt-osc can't reach, and
+// Aurora readers share the writer's storage instead of replicating from it, so replica
+// discovery is off there; elsewhere pt-osc finds the replicas in the processlist. A bare
+// "dsn" gets the default DSN table.
+func (o ptoscOptions) recursionMethod(topo *topology.Info) string {
+	switch {
+	case o.Recursion == "dsn":
+		return "dsn=" + defaultPtOSCDSNTable
+	case o.Recursion != "":
+		return o.Recursion
+	case topo != nil && topo.IsCloudManaged:
+		return "none"
+	}
+	return "processlist"
+}
+
+// ptoscTLSCAHint returns a comment on verifying the server with the CA certificate at
+// path: a pt-osc DSN has no key for it, so it goes in an option file read with F=.
+func ptoscTLSCAHint(path string) string {
+	return fmt.Sprintf(
+		"# TLS: to verify the server with %[1]s, put it in an option file and add F=<file> to the DSN:\n"+
+			"#   [client]\n#   ssl-ca=%[1]s\n",
+		path,
+	)
+}
+
+// ptoscDSNTableHint returns commented SQL that creates and fills the DSN table of a
+// --recursion-method=dsn=... method, or "" for other methods.
+func ptoscDSNTableHint(method string) string {
+	dsn, ok := strings.CutPrefix(method, "dsn=")
+	if !ok {
+		return ""
+	}
+	database, table := "percona", "dsns"
+	for _, part := range strings.Split(dsn, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "D":
+			database = value
+		case "t":
+			table = value
+		}
+	}
+	name := fmt.Sprintf("`%s`.`%s`", database, table)
+	var s strings.Builder
+	fmt.Fprintf(&s, "# pt-osc watches the replicas listed in %s for lag. Create and fill it first:\n", name)
+	fmt.Fprintf(&s, "#   CREATE TABLE %s (id INT AUTO_INCREMENT PRIMARY KEY, parent_id INT DEFAULT NULL, dsn VARCHAR(255) NOT NULL);\n", name)
+	fmt.Fprintf(&s, "#   INSERT INTO %s (dsn) VALUES ('h=replica1,P=3306'), ('h=replica2,P=3306');\n", name)
+	return s.String()
+}
+
+const (
+	defaultPtOSCChunkSize      = 1000
+	defaultPtOSCChunkSizeLimit = 4.0 // pt-osc's own default
+)
+
+// chunking returns the chunk size, chunk time and chunk size limit to emit, defaulting
+// the unset ones. The default chunk time is shorter on synchronous topologies: each chunk
+// is one transaction that Galera and Group Replication certify across the cluster (and
+// flow control stalls on), and that a semi-sync source waits to be acknowledged.
+func (o ptoscOptions) chunking(topo *topology.Info) (size int, seconds, limit float64) {
+	size, seconds, limit = o.Chunking.ChunkSize, o.Chunking.ChunkTime, o.Chunking.ChunkSizeLimit
+	if size <= 0 {
+		size = defaultPtOSCChunkSize
+	}
+	if seconds <= 0 {
+		seconds = 0.5
+		if topo != nil {
+			switch topo.Type {
+			case topology.Galera, topology.GroupRepl:
+				seconds = 0.2
+			case topology.SemiSyncReplica:
+				seconds = 0.25
+			}
+		}
+	}
+	if limit <= 0 {
+		limit = defaultPtOSCChunkSizeLimit
+	}
+	return size, seconds, limit
+}
+
+// ptoscForeignKeyWarning says what pt-osc does to the table's foreign keys, "" when it has
+// none. With --alter-foreign-keys-method=auto, pt-osc re-points the inbound foreign keys with
+// rebuild_constraints when it expects to ALTER every child table within --chunk-time, that
+// is when no child holds more rows than it copies per chunk, and falls back to drop_swap
+// otherwise; the prediction uses the initial chunk size for that rate. The table's own
+// foreign keys are recreated on the new table under new names either way.
+func ptoscForeignKeyWarning(input Input) string {
+	var parts []string
+	renames := len(input.Meta.ForeignKeys) > 0
+	if inbound := input.Meta.InboundForeignKeys; len(inbound) > 0 {
+		chunkSize, _, _ := ptoscOptions{Chunking: input.PtOSCChunking}.chunking(input.Topo)
+		var large, renamed []string
+		for _, fk := range inbound {
+			child := fmt.Sprintf("`%s`.`%s`", fk.ChildSchema, fk.ChildTable)
+			if fk.ChildRows > int64(chunkSize) {
+				large = append(large, fmt.Sprintf("%s (%s rows)", child, formatNumber(fk.ChildRows)))
+			}
+			renamed = append(renamed, fmt.Sprintf("%s %s → %s", child, fk.Name, ptoscConstraintName(fk.Name)))
+		}
+		if len(large) > 0 {
+			parts = append(parts, fmt.Sprintf(
+				"pt-osc's --alter-foreign-keys-method=auto will likely pick drop_swap: %s holds more rows than it copies per chunk (~%d), too many to re-point its foreign key within --chunk-time. "+
+					"drop_swap turns foreign_key_checks off, drops `%s` and renames the new table into place: in between the table doesn't exist, queries on it fail and the child tables' foreign keys enforce nothing, and if the rename fails the original table is gone. "+
+					"To keep enforcement, pass --alter-foreign-keys-method=rebuild_constraints instead, which ALTERs every child table after the copy and renames their foreign keys (%s).",
+				strings.Join(large, ", "), chunkSize, input.Parsed.Table, strings.Join(renamed, ", "),
+			))
+		} else {
+			parts = append(parts, fmt.Sprintf(
+				"pt-osc's --alter-foreign-keys-method=auto will likely pick rebuild_constraints, since the child tables are small: after the copy it ALTERs each one to drop its foreign key and add it back against the new table, under a new name (%s).",
+				strings.Join(renamed, ", "),
+			))
+			renames = true
+		}
+	}
+	if len(input.Meta.ForeignKeys) > 0 {
+		var renamed []string
+		for _, fk := range input.Meta.ForeignKeys {
+			renamed = append(renamed, fmt.Sprintf("%s → %s", fk.Name, ptoscConstraintName(fk.Name)))
+		}
+		parts = append(parts, fmt.Sprintf(
+			"pt-osc recreates the table's own foreign keys on the new table under new names (%s), since a constraint name is unique per schema.",
+			strings.Join(renamed, ", "),
+		))
+	}
+	if renames {
+		parts = append(parts, "Migrations or code that refer to these constraints by name need the new names.")
+	}
+	return strings.Join(parts, " ")
+}
+
+// triggerTableName matches a table name in a trigger body, optionally qualified and quoted.
+const triggerTableName = "(?:`[^`]+`|\\w+)(?:\\.(?:`[^`]+`|\\w+))?"
+
+// The statements in a trigger body that write to a table, and CALLs of procedures that may.
+// An UPDATE needs its SET so that ON DUPLICATE KEY UPDATE isn't taken for one.
+var (
+	reTriggerInsert = regexp.MustCompile(`(?i)\b(?:INSERT|REPLACE)(?:\s+(?:LOW_PRIORITY|DELAYED|HIGH_PRIORITY|IGNORE))*\s+(?:INTO\s+)?(` + triggerTableName + `)`)
+	reTriggerUpdate = regexp.MustCompile(`(?i)\bUPDATE(?:\s+(?:LOW_PRIORITY|IGNORE))*\s+(` + triggerTableName + `)(?:\s+(?:AS\s+)?\w+)?\s+SET\b`)
+	reTriggerDelete = regexp.MustCompile(`(?i)\bDELETE(?:\s+(?:LOW_PRIORITY|QUICK|IGNORE))*\s+FROM\s+(` + triggerTableName + `)`)
+	reTriggerCall   = regexp.MustCompile(`(?i)\bCALL\s+(` + triggerTableName + `)`)
+)
+
+// triggerDoubleWriteWarning names the table's triggers that write to other tables (or call
+// procedures, which may), for a pt-osc migration: with --preserve-triggers pt-osc recreates
+// them on its new table, and while both tables have them, a write to the table fires them
+// twice, once on the table itself and once through pt-osc's trigger that copies the row
+// into the new table. "" when no trigger writes elsewhere.
+func triggerDoubleWriteWarning(input Input) string {
+	var writers []string
+	for _, trigger := range input.Meta.Triggers {
+		var targets []string
+		seen := map[string]bool{}
+		add := func(format string, matches [][]string) {
+			for _, m := range matches {
+				name := strings.ReplaceAll(m[1], "`", "")
+				if strings.EqualFold(name, input.Parsed.Table) || seen[strings.ToLower(name)] {
+					continue
+				}
+				seen[strings.ToLower(name)] = true
+				targets = append(targets, fmt.Sprintf(format, name))
+			}
+		}
+		add("`%s`", reTriggerInsert.FindAllStringSubmatch(trigger.Statement, -1))
+		add("`%s`", reTriggerUpdate.FindAllStringSubmatch(trigger.Statement, -1))
+		add("`%s`", reTriggerDelete.FindAllStringSubmatch(trigger.Statement, -1))
+		add("procedure `%s`", reTriggerCall.FindAllStringSubmatch(trigger.Statement, -1))
+		if len(targets) > 0 {
+			writers = append(writers, fmt.Sprintf("%s (%s %s) writes to %s", trigger.Name, trigger.Timing, trigger.Event, strings.Join(targets, ", ")))
+		}
+	}
+	if len(writers) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(
+		"Triggers that write to other tables: %s. pt-osc's --preserve-triggers recreates them on its new table, and while both tables have them, "+
+			"each write to `%s` fires them twice: once on the table and once through pt-osc's own trigger copying the row into the new table. "+
+			"Audit or summary tables they write to can get duplicate rows or double-counted changes until the swap. "+
+			"Make the trigger writes idempotent (e.g. INSERT ... ON DUPLICATE KEY UPDATE on a unique key), or plan to reconcile those tables after the migration.",
+		strings.Join(writers, "; "), input.Parsed.Table,
+	)
+}
+
+// ptoscConstraintName is the name pt-osc gives a foreign key it recreates: a leading
+// underscore added, or two removed when the name already starts with two, so that names
+// don't grow with every run.
+func ptoscConstraintName(name string) string {
+	if strings.HasPrefix(name, "__") {
+		return name[2:]
+	}
+	return "_" + name
+}
+
+// ptoscExecutionCommand returns the pt-osc command to show as an execution command.
+// With input.SafePtOSC set, it returns a staged sequence instead: a --dry-run, then
+// --execute with --no-drop-old-table, then the DROP of the old table once row counts
+// have been verified.
+func ptoscExecutionCommand(input Input, isGalera bool) string {
+	opts := ptoscOptions{Galera: isGalera, Chunking: input.PtOSCChunking, Recursion: input.PtOSCRecursionMethod}
+	hint := ptoscDSNTableHint(opts.recursionMethod(input.Topo))
+	if input.Connection != nil && input.Connection.TLSCA != "" {
+		hint += ptoscTLSCAHint(input.Connection.TLSCA)
+	}
+	if !input.SafePtOSC {
+		cmd := generatePtOSCCommand(input, opts)
+		if cmd == "" {
+			return ""
+		}
+		if input.WithHooks {
+			cmd += "\n\n" + ptoscPluginScaffold(input.Parsed.Table)
+		}
+		return hint + cmd
+	}
+
+	dryRunOpts, executeOpts := opts, opts
+	dryRunOpts.DryRun = true
+	executeOpts.NoDropOldTable = true
+	dryRun := generatePtOSCCommand(input, dryRunOpts)
+	if dryRun == "" {
+		return ""
+	}
+	execute := generatePtOSCCommand(input, executeOpts)
+	oldTable := fmt.Sprintf("`%s`.`_%s_old`", ptoscDatabase(input), input.Parsed.Table)
+
+	var cmd strings.Builder
+	cmd.WriteString(hint)
+	cmd.WriteString("# Step 1: dry run (creates and alters the new table, copies no rows)\n")
+	cmd.WriteString(dryRun + "\n\n")
+	cmd.WriteString("# Step 2: execute, keeping the original table as " + oldTable + "\n")
+	cmd.WriteString(execute + "\n\n")
+	cmd.WriteString("# Step 3: after verifying row counts match, drop the old table\n")
+	fmt.Fprintf(&cmd, "# DROP TABLE %s;", oldTable)
+	if input.WithHooks {
+		cmd.WriteString("\n\n" + ptoscPluginScaffold(input.Parsed.Table))
+	}
 	return cmd.String()
 }
 
+// ptoscPluginPath is the --plugin of generated pt-online-schema-change commands.
+const ptoscPluginPath = "./ptosc_plugin.pl"
+
+// ptoscPluginScaffold returns a commented script that writes a pt-osc plugin with Slack
+// notifications around the table swap. A pt-osc plugin is a Perl file defining the package
+// pt_online_schema_change_plugin; pt-osc calls its methods named after each step
+// (before_copy_rows, before_swap_tables, after_swap_tables, before_exit, ...).
+func ptoscPluginScaffold(table string) string {
+	var s strings.Builder
+	s.WriteString("# Plugin scaffold: pt-osc calls the methods of pt_online_schema_change_plugin named after each step\n")
+	s.WriteString("# (init, before_copy_rows, after_copy_rows, before_swap_tables, after_swap_tables, before_drop_old_table,\n")
+	s.WriteString("# before_exit, ...). Uncomment to notify Slack around the table swap:\n")
+	s.WriteString("#\n")
+	fmt.Fprintf(&s, "# cat > %s <<'PLUGIN'\n", ptoscPluginPath)
+	s.WriteString("# package pt_online_schema_change_plugin;\n")
+	s.WriteString("# use strict;\n")
+	s.WriteString("# sub new { my ($class, %args) = @_; return bless {%args}, $class }\n")
+	s.WriteString("# sub notify {\n")
+	s.WriteString("#     my ($text) = @_;\n")
+	s.WriteString("#     system('curl', '-fsS', '-X', 'POST', '-H', 'Content-type: application/json',\n")
+	s.WriteString("#         '--data', qq({\"text\":\"$text\"}), $ENV{SLACK_WEBHOOK_URL});\n")
+	s.WriteString("# }\n")
+	fmt.Fprintf(&s, "# sub before_swap_tables { notify('pt-osc: swapping %s') }\n", table)
+	fmt.Fprintf(&s, "# sub after_swap_tables  { notify('pt-osc: swapped %s') }\n", table)
+	fmt.Fprintf(&s, "# sub before_exit { my ($self, %%args) = @_; notify(\"pt-osc: %s finished with exit status $args{exit_status}\") }\n", table)
+	s.WriteString("# 1;\n")
+	s.WriteString("# PLUGIN")
+	return s.String()
+}
+
+// ptoscDatabase returns the database pt-osc works in: the connection's, or the one
+// qualifying the table in the statement.
+func ptoscDatabase(input Input) string {
+	if input.Connection.Database != "" {
+		return input.Connection.Database
+	}
+	return input.Parsed.Database
+}
+
 // generatePtOSCCommand generates a pt-online-schema-change command for the given DDL.
-func generatePtOSCCommand(input Input, isGalera bool) string {
+func generatePtOSCCommand(input Input, opts ptoscOptions) string {
 	if input.Connection == nil {
 		return "" // Can't generate without connection info
 	}
@@ -1830,27 +6240,43 @@ func generatePtOSCCommand(input Input, isGalera bool) string {
 		dsn = fmt.Sprintf("h=%s,P=%d", input.Connection.Host, input.Connection.Port)
 	}
 	dsn += fmt.Sprintf(",u=%s", input.Connection.User)
-	database := input.Connection.Database
-	if database == "" {
-		database = input.Parsed.Database
+	dsn += fmt.Sprintf(",D=%s,t=%s", ptoscDatabase(input), input.Parsed.Table)
+	if input.Connection.TLS {
+		dsn += ",s=1" // mysql_ssl=1
 	}
-	dsn += fmt.Sprintf(",D=%s,t=%s", database, input.Parsed.Table)
 
 	fmt.Fprintf(&cmd, "  %s \\\n", dsn)
+	if input.Connection.PasswordEnv != "" {
+		fmt.Fprintf(&cmd, "  --password \"$%s\" \\\n", input.Connection.PasswordEnv)
+	}
 	fmt.Fprintf(&cmd, "  --alter \"%s\" \\\n", alterSpec)
-	cmd.WriteString("  --execute \\\n")
-	cmd.WriteString("  --chunk-size=1000 \\\n")
-	cmd.WriteString("  --chunk-time=0.5 \\\n")
-	cmd.WriteString("  --max-load=Threads_running=25 \\\n")
-	cmd.WriteString("  --critical-load=Threads_running=50 \\\n")
+	if opts.DryRun {
+		cmd.WriteString("  --dry-run \\\n")
+	} else {
+		cmd.WriteString("  --execute \\\n")
+	}
+	if opts.NoDropOldTable {
+		cmd.WriteString("  --no-drop-old-table \\\n")
+	}
+	chunkSize, chunkTime, chunkSizeLimit := opts.chunking(input.Topo)
+	fmt.Fprintf(&cmd, "  --chunk-size=%d \\\n", chunkSize)
+	fmt.Fprintf(&cmd, "  --chunk-time=%s \\\n", strconv.FormatFloat(chunkTime, 'f', -1, 64))
+	fmt.Fprintf(&cmd, "  --chunk-size-limit=%s \\\n", strconv.FormatFloat(chunkSizeLimit, 'f', -1, 64))
+	maxLoad, criticalLoad := ptoscLoadThresholds(input.MaxConnections, input.ThreadsRunning)
+	fmt.Fprintf(&cmd, "  --max-load=Threads_running=%d \\\n", maxLoad)
+	fmt.Fprintf(&cmd, "  --critical-load=Threads_running=%d \\\n", criticalLoad)
+	fmt.Fprintf(&cmd, "  --recursion-method=%s \\\n", opts.recursionMethod(input.Topo))
 
 	// Galera-specific flags
-	if isGalera {
+	if opts.Galera {
 		cmd.WriteString("  --max-flow-ctl=0.5 \\\n")
 		cmd.WriteString("  --check-plan \\\n")
 	}
 
 	cmd.WriteString("  --alter-foreign-keys-method=auto \\\n")
+	if input.WithHooks {
+		fmt.Fprintf(&cmd, "  --plugin=%s \\\n", ptoscPluginPath)
+	}
 	cmd.WriteString("  --preserve-triggers")
 
 	return cmd.String()
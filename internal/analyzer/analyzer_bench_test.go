@@ -171,7 +171,7 @@ func BenchmarkGeneratePtOSCCommand(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = generatePtOSCCommand(input, false)
+		_ = generatePtOSCCommand(input, ptoscOptions{})
 	}
 }
 
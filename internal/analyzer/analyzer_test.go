@@ -1,6 +1,8 @@
 package analyzer
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
 
@@ -306,6 +308,35 @@ func TestAnalyzeDDL_AddPrimaryKey_NullableColumn(t *testing.T) {
 	if len(result.Warnings) == 0 {
 		t.Error("expected a nullable column warning, got none")
 	}
+	if !result.HasWarning(WarnNullablePKNullValues) {
+		t.Errorf("expected a NULL conversion warning, got %v", result.WarningMessages())
+	}
+	if !containsWarning(result.WarningMessages(), "SELECT COUNT(*) FROM t WHERE a IS NULL;") {
+		t.Errorf("expected a NULL count query, got %v", result.WarningMessages())
+	}
+}
+
+func TestAnalyzeDDL_AddPrimaryKey_NullValues_MultiColumn(t *testing.T) {
+	input := ddlInput(parser.AddPrimaryKey, v8_0_35, 0, topology.Standalone)
+	input.Parsed.IndexColumns = []string{"tenant_id", "code"}
+	input.Meta.Columns = []mysql.ColumnInfo{
+		{Name: "tenant_id", Type: "int", Nullable: false},
+		{Name: "code", Type: "varchar(20)", Nullable: true},
+	}
+	result := Analyze(input)
+	if !containsWarning(result.WarningMessages(), "WHERE code IS NULL;") {
+		t.Errorf("expected the count query to cover only the nullable column, got %v", result.WarningMessages())
+	}
+
+	input.Parsed.DDLOp = parser.MultipleOps
+	input.Parsed.SubOperations = []parser.SubOperation{
+		{Op: parser.DropPrimaryKey},
+		{Op: parser.AddPrimaryKey, IndexColumns: []string{"tenant_id", "code"}},
+	}
+	result = Analyze(input)
+	if !result.HasWarning(WarnNullablePKNullValues) {
+		t.Errorf("expected a NULL conversion warning in a multi-op ALTER, got %v", result.WarningMessages())
+	}
 }
 
 func TestAnalyzeDDL_AddPrimaryKey_DuplicateCheckWarning(t *testing.T) {
@@ -325,7 +356,7 @@ func TestAnalyzeDDL_AddPrimaryKey_DuplicateCheckWarning(t *testing.T) {
 	result := Analyze(input)
 	found := false
 	for _, w := range result.Warnings {
-		if strings.Contains(w, "SELECT id, COUNT(*) cnt FROM orders GROUP BY id HAVING cnt > 1") {
+		if strings.Contains(w.Message, "SELECT id, COUNT(*) cnt FROM orders GROUP BY id HAVING cnt > 1") {
 			found = true
 			break
 		}
@@ -353,7 +384,7 @@ func TestAnalyzeDDL_AddUniqueKey_DuplicateCheckWarning(t *testing.T) {
 	result := Analyze(input)
 	found := false
 	for _, w := range result.Warnings {
-		if strings.Contains(w, "SELECT email, COUNT(*) cnt FROM users GROUP BY email HAVING cnt > 1") {
+		if strings.Contains(w.Message, "SELECT email, COUNT(*) cnt FROM users GROUP BY email HAVING cnt > 1") {
 			found = true
 			break
 		}
@@ -381,7 +412,7 @@ func TestAnalyzeDDL_AddUniqueKey_Composite_DuplicateCheckWarning(t *testing.T) {
 	result := Analyze(input)
 	found := false
 	for _, w := range result.Warnings {
-		if strings.Contains(w, "SELECT first_name, last_name, COUNT(*) cnt FROM customers GROUP BY first_name, last_name HAVING cnt > 1") {
+		if strings.Contains(w.Message, "SELECT first_name, last_name, COUNT(*) cnt FROM customers GROUP BY first_name, last_name HAVING cnt > 1") {
 			found = true
 			break
 		}
@@ -391,6 +422,47 @@ func TestAnalyzeDDL_AddUniqueKey_Composite_DuplicateCheckWarning(t *testing.T) {
 	}
 }
 
+func TestAnalyzeDDL_AddUniqueKey_NullableColumn_ExcludesNulls(t *testing.T) {
+	// NULLs never collide in a UNIQUE key: the duplicate check must skip them and the
+	// user should be told NULLs stay unconstrained.
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:          parser.DDL,
+			RawSQL:        "ALTER TABLE users ADD UNIQUE KEY uk_ref (tenant_id, external_ref)",
+			Table:         "users",
+			DDLOp:         parser.AddIndex,
+			IsUniqueIndex: true,
+			IndexColumns:  []string{"tenant_id", "external_ref"},
+		},
+		Meta: &mysql.TableMetadata{
+			Table: "users",
+			Columns: []mysql.ColumnInfo{
+				{Name: "tenant_id", Type: "int", Nullable: false},
+				{Name: "external_ref", Type: "varchar(64)", Nullable: true},
+			},
+		},
+		Version: v8_0_35,
+		Topo:    &topology.Info{Type: topology.Standalone},
+	}
+	result := Analyze(input)
+	if !containsWarning(result.WarningMessages(), "SELECT tenant_id, external_ref, COUNT(*) cnt FROM users WHERE external_ref IS NOT NULL GROUP BY tenant_id, external_ref HAVING cnt > 1") {
+		t.Errorf("expected duplicate check excluding NULLs, got: %v", result.Warnings)
+	}
+	if !containsWarning(result.WarningMessages(), "Nullable UNIQUE key column(s) `external_ref`") {
+		t.Errorf("expected nullable-column note, got: %v", result.Warnings)
+	}
+
+	// NOT NULL columns keep the plain query and get no note.
+	input.Meta.Columns[1].Nullable = false
+	result = Analyze(input)
+	if !containsWarning(result.WarningMessages(), "FROM users GROUP BY tenant_id, external_ref HAVING cnt > 1") {
+		t.Errorf("expected duplicate check without WHERE, got: %v", result.Warnings)
+	}
+	if containsWarning(result.WarningMessages(), "Nullable UNIQUE key") {
+		t.Errorf("NOT NULL columns should not get the nullable note, got: %v", result.Warnings)
+	}
+}
+
 func TestAnalyzeDDL_AddIndex_NonUnique_NoDuplicateCheckWarning(t *testing.T) {
 	// Regular (non-unique) ADD INDEX should NOT get a duplicate-check warning.
 	input := Input{
@@ -407,7 +479,7 @@ func TestAnalyzeDDL_AddIndex_NonUnique_NoDuplicateCheckWarning(t *testing.T) {
 	}
 	result := Analyze(input)
 	for _, w := range result.Warnings {
-		if strings.Contains(w, "GROUP BY") {
+		if strings.Contains(w.Message, "GROUP BY") {
 			t.Errorf("non-unique index should not get duplicate-check warning, got: %s", w)
 		}
 	}
@@ -661,7 +733,7 @@ func TestAnalyzeDML_SmallDelete(t *testing.T) {
 	if result.Risk != RiskDangerous {
 		t.Errorf("Risk = %q, want DANGEROUS (no WHERE clause)", result.Risk)
 	}
-	if !containsWarning(result.Warnings, "No WHERE clause") {
+	if !containsWarning(result.WarningMessages(), "No WHERE clause") {
 		t.Error("expected warning about missing WHERE clause")
 	}
 }
@@ -752,6 +824,30 @@ func TestAnalyzeDML_NoEstimateProvided_WithWhere(t *testing.T) {
 	}
 }
 
+func TestAnalyzeDML_NoEstimateProvided_WithWhere_Warns(t *testing.T) {
+	input := dmlInput(parser.Delete, true, 1000000, 100, 10000, topology.Standalone)
+
+	result := Analyze(input)
+
+	if !containsWarning(result.WarningMessages(), "No EXPLAIN row estimate available") {
+		t.Errorf("expected missing-estimate warning, got: %v", result.Warnings)
+	}
+	if !containsWarning(result.WarningMessages(), "SELECT COUNT(*) FROM test WHERE id > 0;") {
+		t.Errorf("expected COUNT(*) verification query, got: %v", result.Warnings)
+	}
+}
+
+func TestAnalyzeDML_WithEstimatedRows_NoMissingEstimateWarning(t *testing.T) {
+	input := dmlInput(parser.Delete, true, 1000000, 100, 10000, topology.Standalone)
+	input.EstimatedRows = 500
+
+	result := Analyze(input)
+
+	if containsWarning(result.WarningMessages(), "No EXPLAIN row estimate available") {
+		t.Errorf("unexpected missing-estimate warning when EXPLAIN estimate is provided: %v", result.Warnings)
+	}
+}
+
 func TestAnalyzeDML_UpdateNoWhere(t *testing.T) {
 	input := dmlInput(parser.Update, false, 200000, 100, 10000, topology.Standalone)
 	result := Analyze(input)
@@ -759,7 +855,7 @@ func TestAnalyzeDML_UpdateNoWhere(t *testing.T) {
 	if result.Risk != RiskDangerous {
 		t.Errorf("Risk = %q, want DANGEROUS", result.Risk)
 	}
-	if !containsWarning(result.Warnings, "No WHERE clause") {
+	if !containsWarning(result.WarningMessages(), "No WHERE clause") {
 		t.Error("expected warning about missing WHERE clause")
 	}
 	// 200K rows without WHERE → all rows affected → needs chunking
@@ -794,7 +890,7 @@ func TestAnalyzeDML_MediumDelete_Caution(t *testing.T) {
 		t.Errorf("Method = %q, want DIRECT", result.Method)
 	}
 	// Should still have the no-WHERE warning
-	if !containsWarning(result.Warnings, "No WHERE clause") {
+	if !containsWarning(result.WarningMessages(), "No WHERE clause") {
 		t.Error("expected warning about missing WHERE clause")
 	}
 }
@@ -806,7 +902,7 @@ func TestAnalyzeDML_TriggerWarning(t *testing.T) {
 	}
 	result := Analyze(input)
 
-	if !containsWarning(result.Warnings, "Trigger trg_audit") {
+	if !containsWarning(result.WarningMessages(), "Trigger trg_audit") {
 		t.Errorf("expected trigger warning, got: %v", result.Warnings)
 	}
 }
@@ -819,7 +915,7 @@ func TestAnalyzeDML_TriggerNoMatch(t *testing.T) {
 	result := Analyze(input)
 
 	for _, w := range result.Warnings {
-		if containsStr(w, "trg_insert") {
+		if containsStr(w.Message, "trg_insert") {
 			t.Errorf("unexpected trigger warning for non-matching event: %s", w)
 		}
 	}
@@ -846,8 +942,8 @@ func TestTopologyWarnings_Galera_TOI(t *testing.T) {
 
 	result := Analyze(input)
 
-	if !containsWarning(result.ClusterWarnings, "TOI") {
-		t.Errorf("expected TOI warning, got: %v", result.ClusterWarnings)
+	if !containsWarning(result.ClusterWarningMessages(), "TOI") {
+		t.Errorf("expected TOI warning, got: %v", result.ClusterWarningMessages())
 	}
 }
 
@@ -859,8 +955,8 @@ func TestTopologyWarnings_Galera_TOI_Instant_NoWarning(t *testing.T) {
 
 	result := Analyze(input)
 
-	if containsWarning(result.ClusterWarnings, "TOI") {
-		t.Errorf("INSTANT operation should not get TOI warning, got: %v", result.ClusterWarnings)
+	if containsWarning(result.ClusterWarningMessages(), "TOI") {
+		t.Errorf("INSTANT operation should not get TOI warning, got: %v", result.ClusterWarningMessages())
 	}
 }
 
@@ -870,7 +966,7 @@ func TestTopologyWarnings_Galera_WriteSetExceeded(t *testing.T) {
 	// WriteSetSize = 500K * 200 = 100MB, which is < 1GB, so no warning
 
 	result := Analyze(input)
-	if containsWarning(result.ClusterWarnings, "EXCEEDS wsrep_max_ws_size") {
+	if containsWarning(result.ClusterWarningMessages(), "EXCEEDS wsrep_max_ws_size") {
 		t.Error("write-set should not exceed limit")
 	}
 
@@ -879,8 +975,8 @@ func TestTopologyWarnings_Galera_WriteSetExceeded(t *testing.T) {
 	input2.Topo.WsrepMaxWsSize = 1024 * 1024 * 1024 // 1GB
 	result2 := Analyze(input2)
 
-	if !containsWarning(result2.ClusterWarnings, "EXCEEDS wsrep_max_ws_size") {
-		t.Errorf("expected write-set exceeded warning, got: %v", result2.ClusterWarnings)
+	if !containsWarning(result2.ClusterWarningMessages(), "EXCEEDS wsrep_max_ws_size") {
+		t.Errorf("expected write-set exceeded warning, got: %v", result2.ClusterWarningMessages())
 	}
 	if result2.Risk != RiskDangerous {
 		t.Errorf("Risk = %q, want DANGEROUS", result2.Risk)
@@ -897,8 +993,8 @@ func TestTopologyWarnings_Galera_FlowControl(t *testing.T) {
 
 	result := Analyze(input)
 
-	if !containsWarning(result.ClusterWarnings, "Flow control") {
-		t.Errorf("expected flow control warning, got: %v", result.ClusterWarnings)
+	if !containsWarning(result.ClusterWarningMessages(), "Flow control") {
+		t.Errorf("expected flow control warning, got: %v", result.ClusterWarningMessages())
 	}
 }
 
@@ -919,8 +1015,8 @@ func TestTopologyWarnings_GroupReplication_TransactionLimit(t *testing.T) {
 
 	result := Analyze(input)
 
-	if !containsWarning(result.ClusterWarnings, "group_replication_transaction_size_limit") {
-		t.Errorf("expected GR transaction limit warning, got: %v", result.ClusterWarnings)
+	if !containsWarning(result.ClusterWarningMessages(), "group_replication_transaction_size_limit") {
+		t.Errorf("expected GR transaction limit warning, got: %v", result.ClusterWarningMessages())
 	}
 }
 
@@ -930,8 +1026,8 @@ func TestTopologyWarnings_GroupReplication_MultiPrimary(t *testing.T) {
 
 	result := Analyze(input)
 
-	if !containsWarning(result.ClusterWarnings, "multi-primary") {
-		t.Errorf("expected multi-primary warning, got: %v", result.ClusterWarnings)
+	if !containsWarning(result.ClusterWarningMessages(), "multi-primary") {
+		t.Errorf("expected multi-primary warning, got: %v", result.ClusterWarningMessages())
 	}
 }
 
@@ -942,8 +1038,8 @@ func TestTopologyWarnings_Replication_Lag(t *testing.T) {
 
 	result := Analyze(input)
 
-	if !containsWarning(result.ClusterWarnings, "Replication lag") {
-		t.Errorf("expected replication lag warning, got: %v", result.ClusterWarnings)
+	if !containsWarning(result.ClusterWarningMessages(), "Replication lag") {
+		t.Errorf("expected replication lag warning, got: %v", result.ClusterWarningMessages())
 	}
 }
 
@@ -954,11 +1050,68 @@ func TestTopologyWarnings_Replication_NoLag(t *testing.T) {
 
 	result := Analyze(input)
 
-	if containsWarning(result.ClusterWarnings, "Replication lag") {
+	if containsWarning(result.ClusterWarningMessages(), "Replication lag") {
 		t.Error("should not warn about lag when lag is small")
 	}
 }
 
+func TestTopologyWarnings_SemiSyncPrimary_LargeDML(t *testing.T) {
+	input := dmlInput(parser.Delete, false, 500000, 100, 10000, topology.SemiSyncReplica)
+	input.Topo.IsPrimary = true
+	input.Topo.SemiSyncTimeoutMs = 10000
+
+	result := Analyze(input)
+
+	if !containsWarning(result.ClusterWarningMessages(), "rpl_semi_sync_source_timeout (10s)") {
+		t.Errorf("expected semi-sync ack-timeout warning with the configured timeout, got: %v", result.ClusterWarningMessages())
+	}
+}
+
+func TestTopologyWarnings_SemiSync_NoWarningForReplicaOrSmallDML(t *testing.T) {
+	// Replica side: it doesn't wait for ACKs.
+	input := dmlInput(parser.Delete, false, 500000, 100, 10000, topology.SemiSyncReplica)
+	result := Analyze(input)
+	if containsWarning(result.ClusterWarningMessages(), "Semi-sync primary") {
+		t.Errorf("unexpected semi-sync warning on a replica: %v", result.ClusterWarningMessages())
+	}
+
+	// Small DML on the primary.
+	input = dmlInput(parser.Delete, false, 500, 100, 10000, topology.SemiSyncReplica)
+	input.Topo.IsPrimary = true
+	result = Analyze(input)
+	if containsWarning(result.ClusterWarningMessages(), "Semi-sync primary") {
+		t.Errorf("unexpected semi-sync warning for small DML: %v", result.ClusterWarningMessages())
+	}
+}
+
+func TestTopologyWarnings_Proxied_OSCMustBypassProxy(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 2*1024*1024*1024, topology.Proxied)
+	input.Topo.Proxy = "proxysql"
+
+	result := Analyze(input)
+
+	if !containsWarning(result.ClusterWarningMessages(), "Connected through ProxySQL") {
+		t.Errorf("expected proxy warning, got: %v", result.ClusterWarningMessages())
+	}
+	if !containsWarning(result.ClusterWarningMessages(), "must connect directly to the backend MySQL primary") {
+		t.Errorf("expected direct-connection warning for OSC tools, got: %v", result.ClusterWarningMessages())
+	}
+}
+
+func TestTopologyWarnings_Proxied_DirectExecution(t *testing.T) {
+	input := ddlInput(parser.AddColumn, v8_0_35, 100*1024*1024, topology.Proxied)
+	input.Topo.Proxy = "vitess"
+
+	result := Analyze(input)
+
+	if !containsWarning(result.ClusterWarningMessages(), "Connected through Vitess vtgate") {
+		t.Errorf("expected proxy warning, got: %v", result.ClusterWarningMessages())
+	}
+	if containsWarning(result.ClusterWarningMessages(), "must connect directly") {
+		t.Errorf("direct execution should not warn about OSC tools, got: %v", result.ClusterWarningMessages())
+	}
+}
+
 // =============================================================
 // Rollback Generation Tests
 // =============================================================
@@ -1065,6 +1218,83 @@ func TestChunkedScript_GeneratedForLargeDelete(t *testing.T) {
 	}
 }
 
+func TestChunkedScript_DeleteByPrimaryKeyRanges(t *testing.T) {
+	input := dmlInput(parser.Delete, true, 500000, 100, 10000, topology.Standalone)
+	input.EstimatedRows = 200000
+	input.Meta.Columns = []mysql.ColumnInfo{{Name: "order_id", Type: "bigint unsigned"}, {Name: "created_at", Type: "datetime"}}
+	input.Meta.Indexes = []mysql.IndexInfo{{Name: "PRIMARY", Columns: []string{"order_id"}}}
+
+	result := Analyze(input)
+
+	if result.Method != ExecChunked {
+		t.Fatalf("Method = %q, want CHUNKED", result.Method)
+	}
+	if containsStr(result.GeneratedScript, "LIMIT") {
+		t.Errorf("script should delete by PK ranges instead of LIMIT, got:\n%s", result.GeneratedScript)
+	}
+	if !containsStr(result.GeneratedScript, "AND `order_id` BETWEEN @current AND @current + @batch_size - 1") {
+		t.Errorf("script should bound each chunk by the primary key, got:\n%s", result.GeneratedScript)
+	}
+	if !containsWarning(result.WarningMessages(), "already deletes by explicit ranges of the primary key `order_id`") {
+		t.Errorf("expected gap-lock warning referencing the PK range script, got: %v", result.Warnings)
+	}
+
+	input.MaxReplicaLag = 5
+	result = Analyze(input)
+	if !containsStr(result.GeneratedScript, "AND `order_id` BETWEEN") || containsStr(result.GeneratedScript, "LIMIT") {
+		t.Errorf("lag-aware script should also delete by PK ranges, got:\n%s", result.GeneratedScript)
+	}
+}
+
+func TestChunkedScript_DeleteGapLockWarning(t *testing.T) {
+	tests := []struct {
+		name      string
+		isolation string
+		indexes   []mysql.IndexInfo
+		want      string // "" means no warning
+	}{
+		{
+			name:      "composite PK",
+			isolation: "REPEATABLE-READ",
+			indexes:   []mysql.IndexInfo{{Name: "PRIMARY", Columns: []string{"tenant_id", "id"}}},
+			want:      "walk the primary key (`tenant_id`, `id`) in order",
+		},
+		{
+			name: "no PK, unknown isolation",
+			want: "Chunked DELETE under REPEATABLE READ",
+		},
+		{
+			name:      "read committed",
+			isolation: "READ-COMMITTED",
+			indexes:   []mysql.IndexInfo{{Name: "PRIMARY", Columns: []string{"tenant_id", "id"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := dmlInput(parser.Delete, true, 500000, 100, 10000, topology.Standalone)
+			input.EstimatedRows = 200000
+			input.TxIsolation = tt.isolation
+			input.Meta.Indexes = tt.indexes
+
+			result := Analyze(input)
+
+			if !containsStr(result.GeneratedScript, "LIMIT @batch_size") {
+				t.Errorf("script should fall back to LIMIT chunks without a single-column integer PK")
+			}
+			got := containsWarning(result.WarningMessages(), "gap")
+			if tt.want == "" {
+				if got {
+					t.Errorf("unexpected gap-lock warning under %s: %v", tt.isolation, result.Warnings)
+				}
+				return
+			}
+			if !containsWarning(result.WarningMessages(), tt.want) {
+				t.Errorf("expected warning containing %q, got: %v", tt.want, result.Warnings)
+			}
+		})
+	}
+}
+
 func TestChunkedScript_NotGeneratedForSmallOps(t *testing.T) {
 	input := dmlInput(parser.Delete, true, 100, 100, 10000, topology.Standalone)
 
@@ -1275,7 +1505,7 @@ func TestColumnValidation_AddColumn_AlreadyExists(t *testing.T) {
 
 	result := Analyze(input)
 
-	if !containsWarning(result.Warnings, "already exists") {
+	if !containsWarning(result.WarningMessages(), "already exists") {
 		t.Errorf("Expected warning about column already existing, got: %v", result.Warnings)
 	}
 	if result.Risk != RiskDangerous {
@@ -1293,7 +1523,7 @@ func TestColumnValidation_AddColumn_NewColumn(t *testing.T) {
 
 	result := Analyze(input)
 
-	if containsWarning(result.Warnings, "already exists") {
+	if containsWarning(result.WarningMessages(), "already exists") {
 		t.Errorf("Did not expect warning about column existing, got: %v", result.Warnings)
 	}
 	// Risk should be RiskSafe for INSTANT operations (8.0.35)
@@ -1312,7 +1542,7 @@ func TestColumnValidation_DropColumn_DoesNotExist(t *testing.T) {
 
 	result := Analyze(input)
 
-	if !containsWarning(result.Warnings, "does not exist") {
+	if !containsWarning(result.WarningMessages(), "does not exist") {
 		t.Errorf("Expected warning about column not existing, got: %v", result.Warnings)
 	}
 	if result.Risk != RiskDangerous {
@@ -1330,7 +1560,7 @@ func TestColumnValidation_DropColumn_Exists(t *testing.T) {
 
 	result := Analyze(input)
 
-	if containsWarning(result.Warnings, "does not exist") {
+	if containsWarning(result.WarningMessages(), "does not exist") {
 		t.Errorf("Did not expect warning about column not existing, got: %v", result.Warnings)
 	}
 }
@@ -1345,7 +1575,7 @@ func TestColumnValidation_ModifyColumn_DoesNotExist(t *testing.T) {
 
 	result := Analyze(input)
 
-	if !containsWarning(result.Warnings, "does not exist") {
+	if !containsWarning(result.WarningMessages(), "does not exist") {
 		t.Errorf("Expected warning about column not existing, got: %v", result.Warnings)
 	}
 	if result.Risk != RiskDangerous {
@@ -1364,7 +1594,7 @@ func TestColumnValidation_ChangeColumn_OldDoesNotExist(t *testing.T) {
 
 	result := Analyze(input)
 
-	if !containsWarning(result.Warnings, "Source column") || !containsWarning(result.Warnings, "does not exist") {
+	if !containsWarning(result.WarningMessages(), "Source column") || !containsWarning(result.WarningMessages(), "does not exist") {
 		t.Errorf("Expected warning about source column not existing, got: %v", result.Warnings)
 	}
 	if result.Risk != RiskDangerous {
@@ -1383,7 +1613,7 @@ func TestColumnValidation_ChangeColumn_NewAlreadyExists(t *testing.T) {
 
 	result := Analyze(input)
 
-	if !containsWarning(result.Warnings, "Target column") || !containsWarning(result.Warnings, "already exists") {
+	if !containsWarning(result.WarningMessages(), "Target column") || !containsWarning(result.WarningMessages(), "already exists") {
 		t.Errorf("Expected warning about target column already existing, got: %v", result.Warnings)
 	}
 	if result.Risk != RiskDangerous {
@@ -1402,7 +1632,7 @@ func TestColumnValidation_ChangeColumn_ValidRename(t *testing.T) {
 
 	result := Analyze(input)
 
-	if containsWarning(result.Warnings, "does not exist") || containsWarning(result.Warnings, "already exists") {
+	if containsWarning(result.WarningMessages(), "does not exist") || containsWarning(result.WarningMessages(), "already exists") {
 		t.Errorf("Did not expect column validation warnings, got: %v", result.Warnings)
 	}
 }
@@ -1420,11 +1650,69 @@ func TestColumnValidation_ChangeColumn_SameNameAllowed(t *testing.T) {
 	result := Analyze(input)
 
 	// Should not warn about "already exists" when old and new names are the same
-	if containsWarning(result.Warnings, "already exists") {
+	if containsWarning(result.WarningMessages(), "already exists") {
 		t.Errorf("Should not warn about target already existing when renaming to same name, got: %v", result.Warnings)
 	}
 }
 
+func TestColumnValidation_AddColumn_AfterMissingColumn(t *testing.T) {
+	input := ddlInput(parser.AddColumn, mysql.ServerVersion{Major: 8, Minor: 0, Patch: 35}, 0, topology.Standalone)
+	input.Parsed.IsFirstAfter = true
+	input.Parsed.AfterColumn = "nonexistent_col"
+
+	result := Analyze(input)
+
+	if !containsWarning(result.WarningMessages(), "AFTER column 'nonexistent_col' does not exist! This ADD COLUMN operation will fail.") {
+		t.Errorf("Expected warning about missing AFTER column, got: %v", result.Warnings)
+	}
+	if result.Risk != RiskDangerous {
+		t.Errorf("Expected RiskDangerous, got: %v", result.Risk)
+	}
+}
+
+func TestColumnValidation_AddColumn_AfterExistingColumn(t *testing.T) {
+	input := ddlInput(parser.AddColumn, mysql.ServerVersion{Major: 8, Minor: 0, Patch: 35}, 0, topology.Standalone)
+	input.Parsed.IsFirstAfter = true
+	input.Parsed.AfterColumn = "existing_col"
+
+	result := Analyze(input)
+
+	if containsWarning(result.WarningMessages(), "AFTER column") {
+		t.Errorf("Did not expect AFTER warning for an existing anchor, got: %v", result.Warnings)
+	}
+	if result.Risk == RiskDangerous {
+		t.Errorf("Did not expect RiskDangerous, got: %v", result.Risk)
+	}
+}
+
+// Column names are case-insensitive: AFTER ID anchors on `id`.
+func TestColumnValidation_AddColumn_AfterColumnMixedCase(t *testing.T) {
+	input := ddlInput(parser.AddColumn, mysql.ServerVersion{Major: 8, Minor: 0, Patch: 35}, 0, topology.Standalone)
+	input.Parsed.IsFirstAfter = true
+	input.Parsed.AfterColumn = "ID"
+
+	result := Analyze(input)
+
+	if containsWarning(result.WarningMessages(), "AFTER column") {
+		t.Errorf("Did not expect AFTER warning for `ID` on a table with `id`, got: %v", result.Warnings)
+	}
+	if result.Risk == RiskDangerous {
+		t.Errorf("Did not expect RiskDangerous, got: %v", result.Risk)
+	}
+}
+
+func TestColumnValidation_ModifyColumn_AfterMissingColumn(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, mysql.ServerVersion{Major: 8, Minor: 0, Patch: 35}, 0, topology.Standalone)
+	input.Parsed.IsFirstAfter = true
+	input.Parsed.AfterColumn = "gone"
+
+	result := Analyze(input)
+
+	if !containsWarning(result.WarningMessages(), "AFTER column 'gone' does not exist! This MODIFY COLUMN operation will fail.") {
+		t.Errorf("Expected warning about missing AFTER column, got: %v", result.Warnings)
+	}
+}
+
 func TestClassifyDDL_ChangeColumn_AlgorithmByVersion(t *testing.T) {
 	// CHANGE COLUMN rename-only is INPLACE before MySQL 8.0.29 (Bug#33175960 added INSTANT in 8.0.28,
 	// which maps to V8_0_Full range starting at 8.0.29 in our version bucketing).
@@ -1468,7 +1756,7 @@ func TestChangeColumn_TypeChange_RequiresCopy(t *testing.T) {
 	if !result.Classification.RebuildsTable {
 		t.Error("Expected RebuildsTable=true for type change")
 	}
-	if !containsWarning(result.Warnings, "type change detected") {
+	if !containsWarning(result.WarningMessages(), "type change detected") {
 		t.Errorf("Expected type-change warning, got: %v", result.Warnings)
 	}
 }
@@ -1501,7 +1789,7 @@ func TestChangeColumn_RenameOnly_UsesInstant(t *testing.T) {
 			t.Errorf("v%d.%d.%d: Expected %s for rename-only, got %s",
 				tt.version.Major, tt.version.Minor, tt.version.Patch, tt.wantAlgo, result.Classification.Algorithm)
 		}
-		if containsWarning(result.Warnings, "type change detected") {
+		if containsWarning(result.WarningMessages(), "type change detected") {
 			t.Errorf("v%d.%d.%d: Should not warn about type change for rename-only, got: %v",
 				tt.version.Major, tt.version.Minor, tt.version.Patch, result.Warnings)
 		}
@@ -1524,7 +1812,7 @@ func TestChangeColumn_SameName_TypeChange_RequiresCopy(t *testing.T) {
 	if result.Classification.Algorithm != AlgoCopy {
 		t.Errorf("Expected COPY for type change (same name), got %s", result.Classification.Algorithm)
 	}
-	if !containsWarning(result.Warnings, "type change detected") {
+	if !containsWarning(result.WarningMessages(), "type change detected") {
 		t.Errorf("Expected type-change warning, got: %v", result.Warnings)
 	}
 }
@@ -1574,7 +1862,7 @@ func TestChangeColumn_RenameOnly_BaseTypeOnly_NoFalsePositive(t *testing.T) {
 			if result.Classification.Algorithm != tt.wantAlgo {
 				t.Errorf("Algorithm = %s, want %s", result.Classification.Algorithm, tt.wantAlgo)
 			}
-			if containsWarning(result.Warnings, "type change detected") {
+			if containsWarning(result.WarningMessages(), "type change detected") {
 				t.Errorf("Should not warn about type change for rename-only, got: %v", result.Warnings)
 			}
 		})
@@ -1756,14 +2044,49 @@ func TestAnalyzeDDL_AddCheckConstraint_Warning(t *testing.T) {
 	if result.Classification.Lock != LockNone {
 		t.Errorf("Lock = %q, want NONE", result.Classification.Lock)
 	}
-	if !containsWarning(result.Warnings, "NOT (amount > 0)") {
+	if !containsWarning(result.WarningMessages(), "NOT (amount > 0)") {
 		t.Errorf("Expected check constraint validation warning, got: %v", result.Warnings)
 	}
-	if !containsWarning(result.Warnings, "orders") {
+	if !containsWarning(result.WarningMessages(), "orders") {
 		t.Errorf("Expected table name in warning, got: %v", result.Warnings)
 	}
 }
 
+func TestAnalyzeDDL_AddCheckConstraint_NotEnforced(t *testing.T) {
+	input := ddlInput(parser.AddCheckConstraint, mysql.ServerVersion{Major: 8, Minor: 0, Patch: 35}, 10*1024*1024, topology.Standalone)
+	input.Parsed.CheckExpr = "amount > 0"
+	input.Parsed.CheckNotEnforced = true
+
+	result := Analyze(input)
+
+	if result.Classification.Algorithm != AlgoInstant {
+		t.Errorf("Algorithm = %q, want INSTANT", result.Classification.Algorithm)
+	}
+	if containsWarning(result.WarningMessages(), "NOT (amount > 0)") {
+		t.Errorf("NOT ENFORCED check should not get a validation query, got: %v", result.Warnings)
+	}
+	if !containsWarning(result.WarningMessages(), "NOT ENFORCED") {
+		t.Errorf("Expected NOT ENFORCED note, got: %v", result.Warnings)
+	}
+	if result.Risk != RiskSafe {
+		t.Errorf("Risk = %s, want SAFE", result.Risk)
+	}
+}
+
+func TestMultiOp_AddCheckConstraint_NotEnforced(t *testing.T) {
+	input := ddlInput(parser.MultipleOps, v8_0_35, 10*1024*1024, topology.Standalone)
+	input.Parsed.SubOperations = []parser.SubOperation{
+		{Op: parser.AddCheckConstraint, CheckExpr: "amount > 0", CheckNotEnforced: true},
+		{Op: parser.AddColumn, ColumnName: "note"},
+	}
+
+	result := Analyze(input)
+
+	if result.Classification.Algorithm != AlgoInstant {
+		t.Errorf("Algorithm = %q, want INSTANT", result.Classification.Algorithm)
+	}
+}
+
 func TestAnalyzeDDL_UnparsableOperation(t *testing.T) {
 	// Test that OtherDDL operations generate a syntax warning
 	input := ddlInput(parser.OtherDDL, mysql.ServerVersion{Major: 8, Minor: 0, Patch: 35}, 100*1024*1024, topology.Standalone)
@@ -1773,7 +2096,7 @@ func TestAnalyzeDDL_UnparsableOperation(t *testing.T) {
 	result := Analyze(input)
 
 	// Should generate warning about unparsable operation
-	if !containsWarning(result.Warnings, "could not be fully parsed") {
+	if !containsWarning(result.WarningMessages(), "could not be fully parsed") {
 		t.Errorf("Expected warning about unparsable operation, got warnings: %v", result.Warnings)
 	}
 
@@ -1788,26 +2111,3333 @@ func TestAnalyzeDDL_UnparsableOperation(t *testing.T) {
 	}
 }
 
+// =============================================================
+// MODIFY COLUMN numeric narrowing
 // =============================================================
 
-func containsWarning(warnings []string, substr string) bool {
-	for _, w := range warnings {
-		if containsStr(w, substr) {
-			return true
-		}
+func TestNumericNarrowingPredicate(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldType  string
+		newType  string
+		wantPred string
+		wantOK   bool
+	}{
+		{"int to smallint", "int", "smallint", "`qty` > 32767 OR `qty` < -32768", true},
+		{"bigint to int", "bigint(20)", "int", "`qty` > 2147483647 OR `qty` < -2147483648", true},
+		{"int unsigned to int", "int unsigned", "int", "`qty` > 2147483647", true},
+		{"int to int unsigned", "int(11)", "int unsigned", "`qty` < 0", true},
+		{"tinyint unsigned to smallint", "tinyint unsigned", "smallint", "", false},
+		{"smallint to int widening", "smallint", "int", "", false},
+		{"same type", "int", "int(11)", "", false},
+		{"decimal shrink", "decimal(14,4)", "decimal(10,2)", "ABS(`qty`) > 99999999.99 OR `qty` <> ROUND(`qty`, 2)", true},
+		{"decimal scale only", "decimal(10,4)", "decimal(8,2)", "`qty` <> ROUND(`qty`, 2)", true},
+		{"decimal widening", "decimal(10,2)", "decimal(14,4)", "", false},
+		{"integer to decimal not handled", "int", "decimal(5,0)", "", false},
+		{"varchar ignored", "varchar(10)", "varchar(5)", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := numericNarrowingPredicate("qty", tt.oldType, tt.newType)
+			if ok != tt.wantOK || got != tt.wantPred {
+				t.Errorf("numericNarrowingPredicate(%q, %q) = (%q, %v), want (%q, %v)",
+					tt.oldType, tt.newType, got, ok, tt.wantPred, tt.wantOK)
+			}
+		})
 	}
-	return false
 }
 
-func containsStr(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > 0 && contains(s, substr))
+func TestModifyColumn_NumericNarrowing_Warns(t *testing.T) {
+	input := modifyColumnInput("int", "SMALLINT", "", 50*1024*1024)
+	input.Meta.Columns[1].CharacterSet = nil
+	result := Analyze(input)
+
+	if result.Classification.Algorithm != AlgoCopy {
+		t.Errorf("expected COPY, got %s", result.Classification.Algorithm)
+	}
+	if !containsWarning(result.WarningMessages(), "numeric type narrowing detected: int → smallint") {
+		t.Errorf("expected numeric narrowing warning, got: %v", result.Warnings)
+	}
+	if !containsWarning(result.WarningMessages(), "SELECT COUNT(*) FROM orders WHERE `order_number` > 32767") {
+		t.Errorf("expected pre-flight validation query, got: %v", result.Warnings)
+	}
 }
 
-func contains(s, substr string) bool {
-	for i := 0; i+len(substr) <= len(s); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
+func TestModifyColumn_NumericWidening_NoWarning(t *testing.T) {
+	input := modifyColumnInput("int", "BIGINT", "", 50*1024*1024)
+	input.Meta.Columns[1].CharacterSet = nil
+	result := Analyze(input)
+
+	if containsWarning(result.WarningMessages(), "narrowing") {
+		t.Errorf("widening should not warn about narrowing, got: %v", result.Warnings)
+	}
+}
+
+// =============================================================
+// ADD COLUMN with expression DEFAULT
+// =============================================================
+
+func TestAddColumn_ExpressionDefault_Warns(t *testing.T) {
+	input := ddlInput(parser.AddColumn, v8_0_35, 100*1024*1024, topology.Standalone)
+	input.Parsed.ColumnName = "token"
+	input.Parsed.HasExpressionDefault = true
+	result := Analyze(input)
+
+	if result.Classification.Algorithm != AlgoInstant {
+		t.Errorf("Algorithm = %q, want INSTANT (classification is unchanged)", result.Classification.Algorithm)
+	}
+	if !containsWarning(result.WarningMessages(), "Column 'token' has an expression DEFAULT") {
+		t.Errorf("expected expression DEFAULT warning, got: %v", result.Warnings)
+	}
+}
+
+func TestAddColumn_ExpressionDefault_MultiOpWarns(t *testing.T) {
+	input := ddlInput(parser.MultipleOps, v8_0_35, 100*1024*1024, topology.Standalone)
+	input.Parsed.SubOperations = []parser.SubOperation{
+		{Op: parser.AddColumn, ColumnName: "token", HasExpressionDefault: true},
+		{Op: parser.AddIndex, IndexName: "idx_token", IndexColumns: []string{"token"}},
+	}
+	result := Analyze(input)
+
+	if !containsWarning(result.WarningMessages(), "Column 'token' has an expression DEFAULT") {
+		t.Errorf("expected expression DEFAULT warning, got: %v", result.Warnings)
+	}
+}
+
+// =============================================================
+// CREATE TABLE ... LIKE / ... AS SELECT
+// =============================================================
+
+func ctasInput(rowCount, avgRowLen, estimatedRows int64) Input {
+	return Input{
+		Parsed: &parser.ParsedSQL{
+			Type:        parser.DDL,
+			RawSQL:      "CREATE TABLE orders_archive AS SELECT * FROM orders WHERE created_at < '2024-01-01'",
+			Table:       "orders_archive",
+			DDLOp:       parser.CreateTableAsSelect,
+			SourceTable: "orders",
+			SelectSQL:   "SELECT * FROM orders WHERE created_at < '2024-01-01'",
+		},
+		Meta: &mysql.TableMetadata{
+			Database:     "testdb",
+			Table:        "orders",
+			DataLength:   rowCount * avgRowLen,
+			RowCount:     rowCount,
+			AvgRowLength: avgRowLen,
+		},
+		Version:       v8_0_35,
+		Topo:          &topology.Info{Type: topology.Standalone},
+		EstimatedRows: estimatedRows,
+	}
+}
+
+func TestCreateTableLike_IsSafe(t *testing.T) {
+	input := ddlInput(parser.CreateTableLike, v8_0_35, 50*1024*1024*1024, topology.Standalone)
+	input.Parsed.SourceTable = "test"
+	result := Analyze(input)
+
+	if result.Risk != RiskSafe {
+		t.Errorf("Risk = %q, want SAFE", result.Risk)
+	}
+	if result.Method != ExecDirect {
+		t.Errorf("Method = %q, want DIRECT", result.Method)
+	}
+	if result.DiskEstimate != nil {
+		t.Errorf("DiskEstimate = %+v, want nil", result.DiskEstimate)
+	}
+}
+
+func TestCreateTable_AntiPatterns(t *testing.T) {
+	newTable := func(topo topology.Type, p parser.ParsedSQL) Input {
+		p.Type, p.DDLOp, p.Table = parser.DDL, parser.CreateTable, "events"
+		return Input{
+			Parsed:  &p,
+			Meta:    &mysql.TableMetadata{Database: "testdb", Table: "events"},
+			Version: v8_0_35,
+			Topo:    &topology.Info{Type: topo},
 		}
 	}
-	return false
+
+	clean := Analyze(newTable(topology.Standalone, parser.ParsedSQL{HasPrimaryKey: true, TableRowFormat: "DYNAMIC", TableCharset: "utf8mb4"}))
+	if clean.Risk != RiskSafe || len(clean.Warnings) != 0 {
+		t.Errorf("clean definition: Risk = %s, warnings = %v, want SAFE with none", clean.Risk, clean.Warnings)
+	}
+	if clean.Method != ExecDirect || clean.Classification.Algorithm != AlgoInstant {
+		t.Errorf("Method/Algorithm = %s/%s, want DIRECT/INSTANT", clean.Method, clean.Classification.Algorithm)
+	}
+	if !strings.Contains(clean.RollbackSQL, "DROP TABLE IF EXISTS") {
+		t.Errorf("RollbackSQL = %q, want a DROP TABLE", clean.RollbackSQL)
+	}
+
+	bad := Analyze(newTable(topology.GroupRepl, parser.ParsedSQL{
+		TableEngine:    "myisam",
+		TableCharset:   "latin1",
+		ColumnCharsets: []parser.ColCharset{{Column: "title", Charset: "utf8mb3"}, {Column: "body", Charset: "utf8mb4"}},
+	}))
+	if bad.Risk != RiskCaution {
+		t.Errorf("Risk = %s, want CAUTION", bad.Risk)
+	}
+	for _, want := range []string{
+		"No PRIMARY KEY",
+		"Group Replication rejects writes",
+		"ENGINE=MyISAM",
+		"Table charset latin1, Column `title` charset utf8mb3:",
+		"No explicit ROW_FORMAT",
+	} {
+		if !containsWarning(bad.WarningMessages(), want) {
+			t.Errorf("expected warning containing %q, got: %v", want, bad.Warnings)
+		}
+	}
+	if containsWarning(bad.WarningMessages(), "`body`") {
+		t.Errorf("utf8mb4 column should not be flagged: %v", bad.Warnings)
+	}
+}
+
+func TestCreateTableAsSelect_UsesEstimatedRows(t *testing.T) {
+	// 10M rows in the source, EXPLAIN says the SELECT returns 2M rows of 1 KB each (~2 GB).
+	result := Analyze(ctasInput(10_000_000, 1024, 2_000_000))
+
+	if result.AffectedRows != 2_000_000 {
+		t.Errorf("AffectedRows = %d, want 2000000", result.AffectedRows)
+	}
+	if result.Risk != RiskDangerous {
+		t.Errorf("Risk = %q, want DANGEROUS for a ~2 GB copy", result.Risk)
+	}
+	if result.Method != ExecDirect {
+		t.Errorf("Method = %q, want DIRECT (no online schema change tool applies)", result.Method)
+	}
+	if result.DiskEstimate == nil || result.DiskEstimate.RequiredBytes != 2_000_000*1024 {
+		t.Errorf("DiskEstimate = %+v, want %d bytes", result.DiskEstimate, 2_000_000*1024)
+	}
+	if !containsWarning(result.WarningMessages(), "copies ~2.0M rows") {
+		t.Errorf("expected copy warning, got: %v", result.Warnings)
+	}
+	if result.RollbackSQL != "DROP TABLE IF EXISTS `testdb`.`orders_archive`;" {
+		t.Errorf("RollbackSQL = %q", result.RollbackSQL)
+	}
+}
+
+func TestCreateTableAsSelect_NoEstimate_AssumesWholeTable(t *testing.T) {
+	result := Analyze(ctasInput(5000, 200, 0))
+
+	if result.AffectedRows != 5000 {
+		t.Errorf("AffectedRows = %d, want 5000 (whole source table)", result.AffectedRows)
+	}
+	if result.Risk != RiskCaution {
+		t.Errorf("Risk = %q, want CAUTION for a small copy", result.Risk)
+	}
+	if !containsWarning(result.WarningMessages(), "No EXPLAIN row estimate available for the SELECT") {
+		t.Errorf("expected missing-estimate warning, got: %v", result.Warnings)
+	}
+}
+
+// =============================================================
+// CONVERT TO CHARACTER SET: index key length
+// =============================================================
+
+func convertCharsetInput(newCharset string, indexes []mysql.IndexInfo) Input {
+	latin1 := "latin1"
+	input := ddlInput(parser.ConvertCharset, v8_0_35, 100*1024*1024, topology.Standalone)
+	input.Parsed.NewCharset = newCharset
+	input.Meta.Columns = []mysql.ColumnInfo{
+		{Name: "id", Type: "int", Position: 1},
+		{Name: "email", Type: "varchar(1000)", Position: 2, CharacterSet: &latin1},
+		{Name: "bio", Type: "text", Position: 3, CharacterSet: &latin1},
+	}
+	input.Meta.Indexes = indexes
+	return input
+}
+
+func TestConvertCharset_KeyTooLong_Warns(t *testing.T) {
+	input := convertCharsetInput("utf8mb4", []mysql.IndexInfo{
+		{Name: "idx_email", Columns: []string{"email"}, SubParts: []int{0}, Type: "BTREE"},
+	})
+	result := Analyze(input)
+
+	// varchar(1000) × 4 bytes = 4000 > 3072; a prefix of 768 chars fits.
+	if !containsWarning(result.WarningMessages(), "Index 'idx_email' would need up to 4000 bytes") {
+		t.Errorf("expected key length warning, got: %v", result.Warnings)
+	}
+	if !containsWarning(result.WarningMessages(), "email(768)") {
+		t.Errorf("expected prefix suggestion email(768), got: %v", result.Warnings)
+	}
+}
+
+// REDUNDANT and COMPACT tables are limited to 767-byte keys, so an index that fits
+// 3072 bytes under DYNAMIC still fails there.
+func TestConvertCharset_KeyTooLong_CompactRowFormat(t *testing.T) {
+	indexes := []mysql.IndexInfo{
+		{Name: "idx_email", Columns: []string{"email"}, SubParts: []int{255}, Type: "BTREE"},
+	}
+
+	// email(255) × 4 bytes = 1020: within 3072, over 767.
+	input := convertCharsetInput("utf8mb4", indexes)
+	input.Meta.RowFormat = "Dynamic"
+	if result := Analyze(input); result.HasWarning(WarnKeyTooLong) {
+		t.Errorf("DYNAMIC: expected no key length warning, got: %v", result.WarningMessages())
+	}
+
+	input = convertCharsetInput("utf8mb4", indexes)
+	input.Meta.RowFormat = "Compact"
+	result := Analyze(input)
+	if !containsWarning(result.WarningMessages(), "over the InnoDB limit for ROW_FORMAT=COMPACT of 767 bytes") {
+		t.Errorf("COMPACT: expected 767-byte key length warning, got: %v", result.WarningMessages())
+	}
+	if !containsWarning(result.WarningMessages(), "email(191)") {
+		t.Errorf("COMPACT: expected prefix suggestion email(191), got: %v", result.WarningMessages())
+	}
+}
+
+// The prefix suggestion only makes sense when the other key parts leave room for one
+// character of the widest column.
+func TestConvertCharset_KeyTooLong_PrefixBoundary(t *testing.T) {
+	for _, tc := range []struct {
+		bioPrefix int
+		want      string
+	}{
+		{767, "email(1)"}, // the others take 3068 bytes: 4 left
+		{768, "Its other columns alone take 3072 bytes"}, // no room left
+		{800, "Its other columns alone take 3200 bytes"}, // over the limit on their own
+	} {
+		input := convertCharsetInput("utf8mb4", []mysql.IndexInfo{
+			{Name: "idx_email_bio", Columns: []string{"email", "bio"}, SubParts: []int{0, tc.bioPrefix}, Type: "BTREE"},
+		})
+		result := Analyze(input)
+		if !containsWarning(result.WarningMessages(), tc.want) {
+			t.Errorf("bio(%d): expected %q, got: %v", tc.bioPrefix, tc.want, result.WarningMessages())
+		}
+		if containsWarning(result.WarningMessages(), "email(0)") || containsWarning(result.WarningMessages(), "email(-") {
+			t.Errorf("bio(%d): non-positive prefix suggested: %v", tc.bioPrefix, result.WarningMessages())
+		}
+	}
+}
+
+func TestConvertCharset_PrefixIndexWithinLimit_NoWarning(t *testing.T) {
+	input := convertCharsetInput("utf8mb4", []mysql.IndexInfo{
+		{Name: "idx_email", Columns: []string{"email"}, SubParts: []int{191}, Type: "BTREE"},
+		{Name: "idx_bio", Columns: []string{"bio"}, SubParts: []int{255}, Type: "BTREE"},
+		{Name: "ft_bio", Columns: []string{"bio"}, SubParts: []int{0}, Type: "FULLTEXT"},
+	})
+	result := Analyze(input)
+
+	if containsWarning(result.WarningMessages(), "Specified key was too long") {
+		t.Errorf("prefix indexes within the limit should not warn, got: %v", result.Warnings)
+	}
+}
+
+func TestConvertCharset_SameWidthCharset_NoWarning(t *testing.T) {
+	input := convertCharsetInput("latin1", []mysql.IndexInfo{
+		{Name: "idx_email", Columns: []string{"email"}, SubParts: []int{0}, Type: "BTREE"},
+	})
+	// Simulate a column whose key is already over the limit but not widened.
+	input.Meta.Columns[1].Type = "varchar(4000)"
+	result := Analyze(input)
+
+	if containsWarning(result.WarningMessages(), "Specified key was too long") {
+		t.Errorf("conversion that does not widen the charset should not warn, got: %v", result.Warnings)
+	}
+}
+
+// =============================================================
+// Multi-op ALTER: net column count and row size
+// =============================================================
+
+// wideTableInput returns a multi-op Input against a table with n int columns.
+func wideTableInput(n int, subOps []parser.SubOperation) Input {
+	input := ddlInput(parser.MultipleOps, v8_0_35, 100*1024*1024, topology.Standalone)
+	cols := make([]mysql.ColumnInfo, n)
+	for i := range cols {
+		cols[i] = mysql.ColumnInfo{Name: fmt.Sprintf("c%d", i), Type: "int", Position: i + 1}
+	}
+	input.Meta.Columns = cols
+	input.Parsed.SubOperations = subOps
+	return input
+}
+
+func TestMultiOp_ColumnCount(t *testing.T) {
+	input := wideTableInput(10, []parser.SubOperation{
+		{Op: parser.AddColumn, ColumnName: "a", NewColumnType: "int"},
+		{Op: parser.AddColumn, ColumnName: "b", NewColumnType: "int"},
+		{Op: parser.DropColumn, ColumnName: "c3"},
+	})
+	result := Analyze(input)
+
+	if result.ColumnsBefore != 10 || result.ColumnsAfter != 11 {
+		t.Errorf("columns = %d → %d, want 10 → 11", result.ColumnsBefore, result.ColumnsAfter)
+	}
+	if containsWarning(result.WarningMessages(), "InnoDB limit of 1017") {
+		t.Errorf("small table should not warn about the column limit, got: %v", result.Warnings)
+	}
+}
+
+func TestMultiOp_ColumnCount_ApproachingLimit(t *testing.T) {
+	input := wideTableInput(950, []parser.SubOperation{
+		{Op: parser.AddColumn, ColumnName: "a", NewColumnType: "int"},
+	})
+	result := Analyze(input)
+
+	if !containsWarning(result.WarningMessages(), "approaching the InnoDB limit of 1017") {
+		t.Errorf("expected approaching-limit warning, got: %v", result.Warnings)
+	}
+	if result.Risk == RiskDangerous {
+		t.Errorf("Risk = DANGEROUS, want below DANGEROUS when still under the limit")
+	}
+}
+
+func TestMultiOp_ColumnCount_OverLimit(t *testing.T) {
+	input := wideTableInput(1016, []parser.SubOperation{
+		{Op: parser.AddColumn, ColumnName: "a", NewColumnType: "int"},
+		{Op: parser.AddColumn, ColumnName: "b", NewColumnType: "int"},
+	})
+	result := Analyze(input)
+
+	if !containsWarning(result.WarningMessages(), "Table would have 1018 columns") {
+		t.Errorf("expected over-limit warning, got: %v", result.Warnings)
+	}
+	if result.Risk != RiskDangerous {
+		t.Errorf("Risk = %q, want DANGEROUS", result.Risk)
+	}
+}
+
+func TestMultiOp_RowSizeOverLimit(t *testing.T) {
+	// 2 × varchar(10000) utf8mb4 = ~80,000 bytes > 65,535.
+	input := wideTableInput(1, []parser.SubOperation{
+		{Op: parser.AddColumn, ColumnName: "a", NewColumnType: "varchar(10000)", NewColumnCharset: "utf8mb4"},
+		{Op: parser.AddColumn, ColumnName: "b", NewColumnType: "varchar(10000)", NewColumnCharset: "utf8mb4"},
+	})
+	result := Analyze(input)
+
+	if !containsWarning(result.WarningMessages(), "over the 65535-byte limit") {
+		t.Errorf("expected row size warning, got: %v", result.Warnings)
+	}
+	if result.Risk != RiskDangerous {
+		t.Errorf("Risk = %q, want DANGEROUS", result.Risk)
+	}
+}
+
+func TestMultiOp_RowSize_CompactHalfPage(t *testing.T) {
+	// 12 × varchar(255) utf8mb4 each store a 768-byte in-page prefix: 9216 > 8126.
+	var subOps []parser.SubOperation
+	for i := 0; i < 12; i++ {
+		subOps = append(subOps, parser.SubOperation{
+			Op: parser.AddColumn, ColumnName: fmt.Sprintf("v%d", i), NewColumnType: "varchar(255)", NewColumnCharset: "utf8mb4",
+		})
+	}
+
+	input := wideTableInput(1, subOps)
+	input.Meta.RowFormat = "Compact"
+	result := Analyze(input)
+	if !containsWarning(result.WarningMessages(), "ROW_FORMAT=COMPACT, over the 8126-byte InnoDB limit") {
+		t.Errorf("expected half-page warning for COMPACT, got: %v", result.Warnings)
+	}
+
+	input = wideTableInput(1, subOps)
+	input.Meta.RowFormat = "Dynamic"
+	result = Analyze(input)
+	if containsWarning(result.WarningMessages(), "8126-byte") {
+		t.Errorf("DYNAMIC row format should not get the half-page warning, got: %v", result.Warnings)
+	}
+}
+
+func TestMultiOp_RowSize_UnknownTypeSkipsCheck(t *testing.T) {
+	input := wideTableInput(1, []parser.SubOperation{
+		{Op: parser.AddColumn, ColumnName: "a", NewColumnType: "varchar(10000)", NewColumnCharset: "utf8mb4"},
+		{Op: parser.AddColumn, ColumnName: "b", NewColumnType: "varchar(10000)", NewColumnCharset: "utf8mb4"},
+		{Op: parser.AddColumn, ColumnName: "g", NewColumnType: "geometry"},
+	})
+	result := Analyze(input)
+
+	if containsWarning(result.WarningMessages(), "Maximum row size") {
+		t.Errorf("row size check should be skipped for unknown column types, got: %v", result.Warnings)
+	}
+}
+
+func TestColumnByteWidth(t *testing.T) {
+	tests := []struct {
+		colType string
+		charset string
+		want    int
+		ok      bool
+	}{
+		{"int", "", 4, true},
+		{"bigint unsigned", "", 8, true},
+		{"decimal(14,4)", "", 7, true},
+		{"datetime(6)", "", 8, true},
+		{"char(10)", "utf8mb4", 40, true},
+		{"varchar(50)", "latin1", 51, true},
+		{"varchar(100)", "utf8mb4", 402, true},
+		{"varbinary(300)", "", 302, true},
+		{"text", "utf8mb4", 10, true},
+		{"json", "", 12, true},
+		{"bit(9)", "", 2, true},
+		{"geometry", "", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := columnByteWidth(tt.colType, tt.charset)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("columnByteWidth(%q, %q) = (%d, %v), want (%d, %v)", tt.colType, tt.charset, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// =============================================================
+// Generated column expression changes
+// =============================================================
+
+func TestModifyColumn_GenerationExprChange(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 100*1024*1024, topology.Standalone)
+	input.Parsed.ColumnName = "total"
+	input.Parsed.NewColumnType = "decimal(12,2)"
+	input.Parsed.IsGeneratedColumn = true
+	input.Parsed.IsGeneratedStored = true
+	input.Parsed.NewGenerationExpr = "price * qty * 1.1"
+	input.Meta.Columns = append(input.Meta.Columns, mysql.ColumnInfo{
+		Name: "total", Type: "decimal(12,2)", Position: 3, IsStoredGenerated: true, GenerationExpr: "(`price` * `qty`)",
+	})
+	result := Analyze(input)
+	if result.Classification.Algorithm != AlgoCopy || result.Classification.Lock != LockShared {
+		t.Errorf("STORED expression change: Classification = %s/%s, want COPY/SHARED", result.Classification.Algorithm, result.Classification.Lock)
+	}
+	if !result.HasWarning(WarnGeneratedExprChangeCopy) || !containsWarning(result.WarningMessages(), "stored values will be recomputed") {
+		t.Errorf("expected recompute warning, got: %v", result.Warnings)
+	}
+
+	// Same expression, written differently: no change.
+	input.Parsed.NewGenerationExpr = "price*qty"
+	if result := Analyze(input); result.HasWarning(WarnGeneratedExprChangeCopy) {
+		t.Errorf("equivalent expression flagged as a change: %v", result.Warnings)
+	}
+
+	// VIRTUAL column with a new expression and the same type stays INPLACE.
+	input.Parsed.IsGeneratedStored = false
+	input.Parsed.NewGenerationExpr = "price * qty * 1.1"
+	input.Meta.Columns[len(input.Meta.Columns)-1].IsStoredGenerated = false
+	result = Analyze(input)
+	if result.Classification.Algorithm != AlgoInplace || result.Classification.RebuildsTable {
+		t.Errorf("VIRTUAL expression change: Classification = %s (rebuild=%v), want INPLACE without rebuild",
+			result.Classification.Algorithm, result.Classification.RebuildsTable)
+	}
+	if result.HasWarning(WarnGeneratedExprChangeCopy) {
+		t.Errorf("VIRTUAL expression change should not warn about recomputing, got: %v", result.Warnings)
+	}
+}
+
+func TestMultiOp_GenerationExprChange(t *testing.T) {
+	meta := &mysql.TableMetadata{Table: "orders", Columns: []mysql.ColumnInfo{
+		{Name: "total", Type: "decimal(12,2)", IsStoredGenerated: true, GenerationExpr: "(`price` * `qty`)"},
+	}}
+	subOps := []parser.SubOperation{
+		{Op: parser.ModifyColumn, ColumnName: "total", NewColumnType: "decimal(12,2)",
+			IsGeneratedColumn: true, IsGeneratedStored: true, NewGenerationExpr: "price * qty * 1.1"},
+		{Op: parser.AddIndex, IndexName: "idx_total", IndexColumns: []string{"total"}},
+	}
+	cls, _, warnings := aggregateMultipleOps(subOps, meta, false, v8_0_35)
+	if cls.Algorithm != AlgoCopy {
+		t.Errorf("Algorithm = %s, want COPY", cls.Algorithm)
+	}
+	found := false
+	for _, w := range warnings {
+		found = found || w.Code == WarnGeneratedExprChangeCopy
+	}
+	if !found {
+		t.Errorf("expected %s, got: %v", WarnGeneratedExprChangeCopy, warnings)
+	}
+}
+
+// =============================================================
+// Spatial SRID changes
+// =============================================================
+
+func TestModifyColumn_SpatialSRID_RequiresCopy(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 100*1024*1024, topology.Standalone)
+	input.Parsed.Table = "places"
+	input.Parsed.ColumnName = "g"
+	input.Parsed.NewColumnType = "geometry"
+	input.Parsed.NewColumnSRID = "4326"
+	input.Meta.Columns = append(input.Meta.Columns, mysql.ColumnInfo{Name: "g", Type: "geometry", Position: 3})
+	result := Analyze(input)
+
+	if result.Classification.Algorithm != AlgoCopy || result.Classification.Lock != LockShared {
+		t.Errorf("Classification = %s/%s, want COPY/SHARED", result.Classification.Algorithm, result.Classification.Lock)
+	}
+	if !containsWarning(result.WarningMessages(), "SELECT COUNT(*) FROM places WHERE ST_SRID(g) <> 4326;") {
+		t.Errorf("expected SRID pre-flight query, got: %v", result.Warnings)
+	}
+}
+
+func TestChangeColumn_SpatialSRID_UsesOldColumnName(t *testing.T) {
+	input := ddlInput(parser.ChangeColumn, v8_0_35, 100*1024*1024, topology.Standalone)
+	input.Parsed.Table = "places"
+	input.Parsed.OldColumnName = "loc"
+	input.Parsed.NewColumnName = "location"
+	input.Parsed.ColumnName = "location"
+	input.Parsed.NewColumnType = "point"
+	input.Parsed.NewColumnSRID = "4326"
+	input.Meta.Columns = append(input.Meta.Columns, mysql.ColumnInfo{Name: "loc", Type: "point", Position: 3})
+	result := Analyze(input)
+
+	if result.Classification.Algorithm != AlgoCopy {
+		t.Errorf("Algorithm = %s, want COPY", result.Classification.Algorithm)
+	}
+	if !containsWarning(result.WarningMessages(), "WHERE ST_SRID(loc) <> 4326") {
+		t.Errorf("expected pre-flight query on the existing column name, got: %v", result.Warnings)
+	}
+}
+
+func TestModifyColumn_SpatialSRID_MultiOp(t *testing.T) {
+	input := ddlInput(parser.MultipleOps, v8_0_35, 100*1024*1024, topology.Standalone)
+	input.Meta.Table = "places"
+	input.Meta.Columns = append(input.Meta.Columns, mysql.ColumnInfo{Name: "g", Type: "geometry", Position: 3})
+	input.Parsed.SubOperations = []parser.SubOperation{
+		{Op: parser.ModifyColumn, ColumnName: "g", NewColumnType: "geometry", NewColumnSRID: "4326"},
+		{Op: parser.AddColumn, ColumnName: "note", NewColumnType: "int"},
+	}
+	result := Analyze(input)
+
+	if result.Classification.Algorithm != AlgoCopy {
+		t.Errorf("Algorithm = %s, want COPY", result.Classification.Algorithm)
+	}
+	if !containsWarning(result.WarningMessages(), "WHERE ST_SRID(g) <> 4326") {
+		t.Errorf("expected SRID pre-flight query, got: %v", result.Warnings)
+	}
+}
+
+func TestModifyColumn_SpatialSRID_Unchanged(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 100*1024*1024, topology.Standalone)
+	input.Parsed.Table = "places"
+	input.Parsed.ColumnName = "g"
+	input.Parsed.NewColumnType = "geometry"
+	input.Parsed.NewColumnSRID = "4326"
+	input.Meta.Columns = append(input.Meta.Columns, mysql.ColumnInfo{Name: "g", Type: "geometry", Position: 3, SRID: "4326"})
+	result := Analyze(input)
+
+	if result.HasWarning(WarnSpatialSRIDValidation) {
+		t.Errorf("restating the column's SRID should not warn, got: %v", result.WarningMessages())
+	}
+	if strings.Contains(result.Classification.Notes, "SRID") {
+		t.Errorf("restating the column's SRID should not be classified as an SRID change: %s", result.Classification.Notes)
+	}
+
+	// A different SRID still does.
+	input.Parsed.NewColumnSRID = "0"
+	if result := Analyze(input); result.Classification.Algorithm != AlgoCopy || !result.HasWarning(WarnSpatialSRIDValidation) {
+		t.Errorf("SRID 4326 -> 0: got %s, %v", result.Classification.Algorithm, result.WarningMessages())
+	}
+}
+
+func TestModifyColumn_NonSpatialWithoutSRID_NoWarning(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 100*1024*1024, topology.Standalone)
+	input.Parsed.NewColumnType = "varchar(200)"
+	result := Analyze(input)
+
+	if containsWarning(result.WarningMessages(), "ST_SRID") {
+		t.Errorf("non-spatial MODIFY should not warn about SRID, got: %v", result.Warnings)
+	}
+}
+
+// =============================================================
+// ADD FULLTEXT INDEX: first vs. subsequent
+// =============================================================
+
+func TestAddFulltextIndex_First_RebuildsTable(t *testing.T) {
+	const size = 10 * 1024 * 1024 * 1024
+	input := ddlInput(parser.AddFulltextIndex, v8_0_35, size, topology.Standalone)
+	input.Parsed.IndexName = "ft_body"
+	input.Meta.Indexes = []mysql.IndexInfo{{Name: "PRIMARY", Columns: []string{"id"}, Type: "BTREE"}}
+	result := Analyze(input)
+
+	if !result.Classification.RebuildsTable {
+		t.Error("first FULLTEXT index should rebuild the table")
+	}
+	if !containsWarning(result.WarningMessages(), "First FULLTEXT index on this table") {
+		t.Errorf("expected FTS_DOC_ID rebuild warning, got: %v", result.Warnings)
+	}
+	if result.DiskEstimate == nil || result.DiskEstimate.RequiredBytes != size {
+		t.Errorf("DiskEstimate = %+v, want whole-table size %d", result.DiskEstimate, int64(size))
+	}
+}
+
+func TestAddFulltextIndex_Subsequent_NoRebuild(t *testing.T) {
+	const size = 500 * 1024 * 1024
+	input := ddlInput(parser.AddFulltextIndex, v8_0_35, size, topology.Standalone)
+	input.Parsed.IndexName = "ft_title"
+	input.Meta.Indexes = []mysql.IndexInfo{
+		{Name: "PRIMARY", Columns: []string{"id"}, Type: "BTREE"},
+		{Name: "ft_body", Columns: []string{"existing_col"}, Type: "FULLTEXT"},
+	}
+	result := Analyze(input)
+
+	if result.Classification.RebuildsTable {
+		t.Error("subsequent FULLTEXT index should not rebuild the table")
+	}
+	if result.Classification.Algorithm != AlgoInplace || result.Classification.Lock != LockShared {
+		t.Errorf("Classification = %s/%s, want INPLACE/SHARED", result.Classification.Algorithm, result.Classification.Lock)
+	}
+	if containsWarning(result.WarningMessages(), "First FULLTEXT index") {
+		t.Errorf("subsequent FULLTEXT index should not get the first-index warning, got: %v", result.Warnings)
+	}
+	if result.DiskEstimate == nil || result.DiskEstimate.RequiredBytes != input.Meta.IndexLength {
+		t.Errorf("DiskEstimate = %+v, want index size %d", result.DiskEstimate, input.Meta.IndexLength)
+	}
+}
+
+func TestAddFulltextIndex_UserDefinedFTSDocID_NoRebuild(t *testing.T) {
+	input := ddlInput(parser.AddFulltextIndex, v8_0_35, 100*1024*1024, topology.Standalone)
+	input.Meta.Columns = append(input.Meta.Columns, mysql.ColumnInfo{Name: "FTS_DOC_ID", Type: "bigint unsigned", Position: 3})
+	result := Analyze(input)
+
+	if result.Classification.RebuildsTable {
+		t.Error("table with a user-defined FTS_DOC_ID column should not rebuild")
+	}
+}
+
+func TestAddFulltextIndex_MultiOp(t *testing.T) {
+	input := ddlInput(parser.MultipleOps, v8_0_35, 100*1024*1024, topology.Standalone)
+	input.Meta.Indexes = []mysql.IndexInfo{{Name: "ft_body", Columns: []string{"existing_col"}, Type: "FULLTEXT"}}
+	input.Parsed.SubOperations = []parser.SubOperation{
+		{Op: parser.AddFulltextIndex, IndexName: "ft_title"},
+		{Op: parser.AddColumn, ColumnName: "note", NewColumnType: "int"},
+	}
+	result := Analyze(input)
+
+	if result.Classification.RebuildsTable {
+		t.Error("multi-op with a subsequent FULLTEXT index and INSTANT ADD COLUMN should not rebuild")
+	}
+}
+
+// =============================================================
+// ALTER TABLE ... ORDER BY
+// =============================================================
+
+func TestOrderBy_CopyRebuildWithWarning(t *testing.T) {
+	for _, v := range []mysql.ServerVersion{v8_0_5, v8_0_20, v8_0_35, v8_4_0} {
+		c := ClassifyDDL(parser.OrderBy, v.Major, v.Minor, v.Patch)
+		if c.Algorithm != AlgoCopy || c.Lock != LockShared || !c.RebuildsTable {
+			t.Errorf("v%s: ORDER BY = %s/%s rebuild=%v, want COPY/SHARED rebuild=true", v.String(), c.Algorithm, c.Lock, c.RebuildsTable)
+		}
+	}
+
+	input := ddlInput(parser.OrderBy, v8_0_35, 100*1024*1024, topology.Standalone)
+	result := Analyze(input)
+
+	if result.Risk != RiskCaution {
+		t.Errorf("Risk = %s, want CAUTION", result.Risk)
+	}
+	if !containsWarning(result.WarningMessages(), "does not preserve the new physical order") {
+		t.Errorf("expected ORDER BY warning, got: %v", result.Warnings)
+	}
+}
+
+// =============================================================
+// Dropping a column referenced by a generated column
+// =============================================================
+
+func generatedColumnInput() Input {
+	input := ddlInput(parser.DropColumn, v8_0_35, 0, topology.Standalone)
+	input.Meta.Columns = append(input.Meta.Columns, mysql.ColumnInfo{
+		Name: "existing_col_upper", Type: "varchar(100)", Position: 3,
+		GenerationExpr: "upper(`existing_col`)",
+	})
+	input.Meta.Indexes = []mysql.IndexInfo{
+		{Name: "idx_upper", Columns: []string{"existing_col_upper"}, NonUnique: true, Type: "BTREE"},
+	}
+	return input
+}
+
+func TestDropColumn_ReferencedByGeneratedColumn(t *testing.T) {
+	result := Analyze(generatedColumnInput())
+
+	if !containsWarning(result.WarningMessages(), "referenced by generated column 'existing_col_upper'") {
+		t.Errorf("expected dependent generated column warning, got: %v", result.Warnings)
+	}
+	if !containsWarning(result.WarningMessages(), "idx_upper") {
+		t.Errorf("expected warning to name the index on the generated column, got: %v", result.Warnings)
+	}
+	if result.Risk != RiskDangerous {
+		t.Errorf("Risk = %s, want DANGEROUS", result.Risk)
+	}
+}
+
+func TestDropColumn_NotReferencedByGeneratedColumn(t *testing.T) {
+	input := generatedColumnInput()
+	input.Parsed.ColumnName = "id"
+
+	result := Analyze(input)
+
+	if containsWarning(result.WarningMessages(), "referenced by generated column") {
+		t.Errorf("unexpected dependent generated column warning: %v", result.Warnings)
+	}
+}
+
+func TestMultiOp_DropBaseAndGeneratedColumn(t *testing.T) {
+	input := generatedColumnInput()
+	input.Parsed.DDLOp = parser.MultipleOps
+	input.Parsed.SubOperations = []parser.SubOperation{
+		{Op: parser.DropColumn, ColumnName: "existing_col_upper"},
+		{Op: parser.DropColumn, ColumnName: "existing_col"},
+	}
+
+	result := Analyze(input)
+
+	if containsWarning(result.WarningMessages(), "referenced by generated column") {
+		t.Errorf("dropping both columns in one ALTER is valid, got: %v", result.Warnings)
+	}
+
+	input.Parsed.SubOperations = input.Parsed.SubOperations[1:]
+	result = Analyze(input)
+	if !containsWarning(result.WarningMessages(), "referenced by generated column") || result.Risk != RiskDangerous {
+		t.Errorf("expected DANGEROUS with dependent column warning, got %s: %v", result.Risk, result.Warnings)
+	}
+}
+
+// =============================================================
+// Explicit ALGORITHM= / LOCK= hints in the user's ALTER
+// =============================================================
+
+func TestAlgorithmHint_StricterThanSupported(t *testing.T) {
+	input := ddlInput(parser.OrderBy, v8_0_35, 10*1024*1024, topology.Standalone)
+	input.Parsed.RawSQL = "ALTER TABLE test ORDER BY id, ALGORITHM=INSTANT"
+	input.Parsed.AlgorithmHint = "INSTANT"
+
+	result := Analyze(input)
+
+	if !containsWarning(result.WarningMessages(), "ALGORITHM=INSTANT is not supported for this operation: it requires ALGORITHM=COPY") {
+		t.Errorf("expected ALGORITHM mismatch warning, got: %v", result.Warnings)
+	}
+	if result.Risk != RiskDangerous {
+		t.Errorf("Risk = %s, want DANGEROUS", result.Risk)
+	}
+}
+
+func TestLockHint_StricterThanSupported(t *testing.T) {
+	input := ddlInput(parser.OrderBy, v8_0_35, 10*1024*1024, topology.Standalone)
+	input.Parsed.LockHint = "NONE"
+
+	result := Analyze(input)
+
+	if !containsWarning(result.WarningMessages(), "LOCK=NONE is not supported for this operation: it requires at least LOCK=SHARED") {
+		t.Errorf("expected LOCK mismatch warning, got: %v", result.Warnings)
+	}
+	if result.Risk != RiskDangerous {
+		t.Errorf("Risk = %s, want DANGEROUS", result.Risk)
+	}
+}
+
+func TestAlgorithmLockHints_Compatible(t *testing.T) {
+	input := ddlInput(parser.AddColumn, v8_0_35, 10*1024*1024, topology.Standalone)
+	input.Parsed.RawSQL = "ALTER TABLE test ADD COLUMN new_col INT, ALGORITHM=INSTANT, LOCK=NONE;"
+	input.Parsed.AlgorithmHint = "INSTANT"
+	input.Parsed.LockHint = "NONE"
+
+	result := Analyze(input)
+
+	if containsWarning(result.WarningMessages(), "ALGORITHM=") || containsWarning(result.WarningMessages(), "LOCK=") {
+		t.Errorf("unexpected hint warning: %v", result.Warnings)
+	}
+	if result.Risk == RiskDangerous {
+		t.Errorf("Risk = %s, want not DANGEROUS", result.Risk)
+	}
+	want := "ALTER TABLE test ADD COLUMN new_col INT, ALGORITHM=INSTANT, LOCK=NONE;"
+	if result.OptimizedDDL != want {
+		t.Errorf("OptimizedDDL = %q, want %q (hints must not be appended twice)", result.OptimizedDDL, want)
+	}
+}
+
+func TestAlgorithmHint_ForcedCopy(t *testing.T) {
+	input := ddlInput(parser.AddColumn, v8_0_35, 10*1024*1024, topology.Standalone)
+	input.Parsed.AlgorithmHint = "COPY"
+	input.Parsed.LockHint = "NONE"
+
+	result := Analyze(input)
+
+	if !containsWarning(result.WarningMessages(), "ALGORITHM=COPY forces a slower path than needed: MySQL supports ALGORITHM=INSTANT") {
+		t.Errorf("expected forced COPY warning, got: %v", result.Warnings)
+	}
+	// COPY can't run with LOCK=NONE.
+	if !containsWarning(result.WarningMessages(), "LOCK=NONE is not supported") {
+		t.Errorf("expected LOCK=NONE to be rejected with a forced COPY, got: %v", result.Warnings)
+	}
+	if c := result.Classification; c.Algorithm != AlgoCopy || c.Lock != LockShared || !c.RebuildsTable {
+		t.Errorf("Classification = %+v, want COPY/SHARED with rebuild", c)
+	}
+}
+
+// A hint forcing a slower path is what runs: risk and method follow the forced algorithm.
+func TestAlgorithmHint_ForcedAlgorithmReclassifies(t *testing.T) {
+	tests := []struct {
+		name       string
+		hint       string
+		size       int64
+		want       DDLClassification
+		wantRisk   RiskLevel
+		wantMethod ExecutionMethod
+	}{
+		{"INPLACE small", "INPLACE", 10 * 1024 * 1024, DDLClassification{Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: true}, RiskSafe, ExecDirect},
+		{"INPLACE large", "INPLACE", 20 * 1024 * 1024 * 1024, DDLClassification{Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: true}, RiskCaution, ExecDirect},
+		{"COPY small", "COPY", 10 * 1024 * 1024, DDLClassification{Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true}, RiskCaution, ExecDirect},
+		{"COPY large", "COPY", 5 * 1024 * 1024 * 1024, DDLClassification{Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true}, RiskDangerous, ExecGhost},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := ddlInput(parser.AddColumn, v8_0_35, tt.size, topology.Standalone)
+			input.Parsed.RawSQL = "ALTER TABLE test ADD COLUMN y INT, ALGORITHM=" + tt.hint
+			input.Parsed.AlgorithmHint = tt.hint
+
+			result := Analyze(input)
+
+			c := result.Classification
+			if c.Algorithm != tt.want.Algorithm || c.Lock != tt.want.Lock || c.RebuildsTable != tt.want.RebuildsTable {
+				t.Errorf("Classification = %+v, want %s/%s rebuild=%v", c, tt.want.Algorithm, tt.want.Lock, tt.want.RebuildsTable)
+			}
+			if result.Risk != tt.wantRisk || result.Method != tt.wantMethod {
+				t.Errorf("Risk/Method = %s/%s, want %s/%s", result.Risk, result.Method, tt.wantRisk, tt.wantMethod)
+			}
+			// The optimized DDL still offers the fast path.
+			if want := "ALTER TABLE test ADD COLUMN y INT, ALGORITHM=INSTANT, LOCK=NONE;"; result.OptimizedDDL != want {
+				t.Errorf("OptimizedDDL = %q, want %q", result.OptimizedDDL, want)
+			}
+		})
+	}
+}
+
+func TestLockHint_StricterLockReclassifies(t *testing.T) {
+	input := ddlInput(parser.AddIndex, v8_0_35, 10*1024*1024, topology.Standalone)
+	input.Parsed.LockHint = "SHARED"
+
+	result := Analyze(input)
+
+	if c := result.Classification; c.Algorithm != AlgoInplace || c.Lock != LockShared {
+		t.Errorf("Classification = %+v, want INPLACE/SHARED", c)
+	}
+	if result.Risk != RiskCaution {
+		t.Errorf("Risk = %s, want CAUTION (writes blocked)", result.Risk)
+	}
+}
+
+func TestStripAlgorithmLockHints(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"ALTER TABLE t ADD COLUMN x INT, ALGORITHM=INSTANT, LOCK=NONE", "ALTER TABLE t ADD COLUMN x INT"},
+		{"ALTER TABLE t algorithm = inplace, lock=shared, ADD INDEX idx (a)", "ALTER TABLE t ADD INDEX idx (a)"},
+		{"ADD COLUMN locked INT, ALGORITHM COPY", "ADD COLUMN locked INT"},
+		{"ALTER TABLE t ADD COLUMN x INT", "ALTER TABLE t ADD COLUMN x INT"},
+	}
+	for _, tt := range tests {
+		if got := stripAlgorithmLockHints(tt.in); got != tt.want {
+			t.Errorf("stripAlgorithmLockHints(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// =============================================================
+// ROW_FORMAT=COMPRESSED tables
+// =============================================================
+
+func TestCompressedTable_AddColumnFallsBackToInplace(t *testing.T) {
+	input := ddlInput(parser.AddColumn, v8_0_35, 50*1024*1024, topology.Standalone)
+	input.Meta.RowFormat = "Compressed"
+
+	result := Analyze(input)
+
+	if result.Classification.Algorithm != AlgoInplace || !result.Classification.RebuildsTable {
+		t.Errorf("Classification = %s rebuild=%v, want INPLACE with rebuild", result.Classification.Algorithm, result.Classification.RebuildsTable)
+	}
+	if !containsWarning(result.WarningMessages(), "does not support INSTANT ADD COLUMN") {
+		t.Errorf("expected INSTANT fallback warning, got: %v", result.Warnings)
+	}
+	if !containsWarning(result.WarningMessages(), "recompresses every page") {
+		t.Errorf("expected recompression warning, got: %v", result.Warnings)
+	}
+}
+
+func TestCompressedTable_AddIndexWarnsAboutRecompression(t *testing.T) {
+	input := ddlInput(parser.AddIndex, v8_0_35, 50*1024*1024, topology.Standalone)
+	input.Meta.RowFormat = "COMPRESSED"
+
+	result := Analyze(input)
+
+	if result.Classification.Algorithm != AlgoInplace {
+		t.Errorf("Algorithm = %s, want INPLACE", result.Classification.Algorithm)
+	}
+	if !containsWarning(result.WarningMessages(), "ROW_FORMAT=COMPRESSED") {
+		t.Errorf("expected compressed table warning, got: %v", result.Warnings)
+	}
+}
+
+func TestCompressedTable_MetadataOnlyOpNotAffected(t *testing.T) {
+	input := ddlInput(parser.RenameIndex, v8_0_35, 50*1024*1024, topology.Standalone)
+	input.Meta.RowFormat = "Compressed"
+
+	result := Analyze(input)
+
+	if containsWarning(result.WarningMessages(), "ROW_FORMAT=COMPRESSED") {
+		t.Errorf("unexpected compressed table warning for metadata-only op: %v", result.Warnings)
+	}
+}
+
+func TestDynamicTable_AddColumnStaysInstant(t *testing.T) {
+	input := ddlInput(parser.AddColumn, v8_0_35, 50*1024*1024, topology.Standalone)
+	input.Meta.RowFormat = "Dynamic"
+
+	result := Analyze(input)
+
+	if result.Classification.Algorithm != AlgoInstant {
+		t.Errorf("Algorithm = %s, want INSTANT", result.Classification.Algorithm)
+	}
+}
+
+func TestCompressedTable_MultiOpDropColumn(t *testing.T) {
+	input := ddlInput(parser.MultipleOps, v8_0_35, 50*1024*1024, topology.Standalone)
+	input.Meta.RowFormat = "Compressed"
+	input.Parsed.SubOperations = []parser.SubOperation{
+		{Op: parser.DropColumn, ColumnName: "existing_col"},
+		{Op: parser.RenameIndex, IndexName: "idx_a"},
+	}
+
+	result := Analyze(input)
+
+	if result.Classification.Algorithm != AlgoInplace || !result.Classification.RebuildsTable {
+		t.Errorf("Classification = %s rebuild=%v, want INPLACE with rebuild", result.Classification.Algorithm, result.Classification.RebuildsTable)
+	}
+	if !containsWarning(result.WarningMessages(), "does not support INSTANT DROP COLUMN") {
+		t.Errorf("expected INSTANT fallback warning, got: %v", result.Warnings)
+	}
+}
+
+// =============================================================
+
+func containsWarning(warnings []string, substr string) bool {
+	for _, w := range warnings {
+		if containsStr(w, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsStr(s, substr string) bool {
+	return len(s) >= len(substr) && (s == substr || len(s) > 0 && contains(s, substr))
+}
+
+func contains(s, substr string) bool {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
+
+func TestServerConfigWarnings_OnlineAlterLog(t *testing.T) {
+	const gb = int64(1024 * 1024 * 1024)
+
+	input := ddlInput(parser.AddIndex, v8_0_35, 5*gb, topology.Standalone)
+	input.ServerConfig = mysql.ServerConfig{OnlineAlterLogMaxSize: 256 * 1024 * 1024, Tmpdir: "/tmp"}
+	result := Analyze(input)
+	if !containsWarning(result.WarningMessages(), "innodb_online_alter_log_max_size (currently 256.0 MB)") {
+		t.Errorf("expected online log warning quoting the configured size, got: %v", result.Warnings)
+	}
+	if !containsWarning(result.WarningMessages(), "temporary sort files to /tmp (tmpdir)") {
+		t.Errorf("expected tmpdir warning, got: %v", result.Warnings)
+	}
+
+	input.ServerConfig = mysql.ServerConfig{InnoDBTmpdir: "/mnt/scratch", Tmpdir: "/tmp"}
+	result = Analyze(input)
+	if !containsWarning(result.WarningMessages(), "(128.0 MB by default; the server value could not be read)") {
+		t.Errorf("expected online log warning with the default size, got: %v", result.Warnings)
+	}
+	if !containsWarning(result.WarningMessages(), "/mnt/scratch (innodb_tmpdir)") {
+		t.Errorf("innodb_tmpdir should take precedence over tmpdir, got: %v", result.Warnings)
+	}
+
+	// Small tables and INSTANT changes don't need either warning.
+	for _, in := range []Input{
+		ddlInput(parser.AddIndex, v8_0_35, 100*1024*1024, topology.Standalone),
+		ddlInput(parser.AddColumn, v8_0_35, 5*gb, topology.Standalone),
+	} {
+		in.ServerConfig = mysql.ServerConfig{Tmpdir: "/tmp"}
+		result := Analyze(in)
+		if containsWarning(result.WarningMessages(), "innodb_online_alter_log_max_size") || containsWarning(result.WarningMessages(), "sort files") {
+			t.Errorf("%s on %d bytes: unexpected server config warning: %v", in.Parsed.DDLOp, in.Meta.TotalSize(), result.Warnings)
+		}
+	}
+}
+
+func TestMetadataLockWarnings(t *testing.T) {
+	input := ddlInput(parser.AddColumn, v8_0_35, 10*1024*1024, topology.Standalone)
+	input.MetadataLockHolders = []mysql.MetadataLockHolder{
+		{ProcessID: 41, LockType: "SHARED_READ", LockStatus: "GRANTED", TrxSeconds: 312},
+		{ProcessID: 57, LockType: "SHARED_UPGRADABLE", LockStatus: "PENDING", Query: "ALTER TABLE test ADD INDEX idx_a (a)"},
+	}
+	result := Analyze(input)
+	if result.Risk != RiskDangerous {
+		t.Errorf("Risk = %s, want DANGEROUS when other sessions hold metadata locks", result.Risk)
+	}
+	for _, want := range []string{
+		"2 other session(s) hold or wait for a metadata lock on test",
+		"process 41 holds SHARED_READ in a transaction open for 312s",
+		"process 57 waits for SHARED_UPGRADABLE (running: ALTER TABLE test ADD INDEX idx_a (a))",
+	} {
+		if !containsWarning(result.WarningMessages(), want) {
+			t.Errorf("expected warning containing %q, got: %v", want, result.Warnings)
+		}
+	}
+
+	// Short statements holding the lock, with nobody waiting for it: CAUTION.
+	input.MetadataLockHolders = []mysql.MetadataLockHolder{
+		{ProcessID: 41, LockType: "SHARED_READ", LockStatus: "GRANTED", TrxSeconds: 2, Query: "SELECT * FROM test"},
+	}
+	result = Analyze(input)
+	if result.Risk != RiskCaution || !containsWarning(result.WarningMessages(), "so the wait should be short") {
+		t.Errorf("expected CAUTION for a short-lived holder, got %s: %v", result.Risk, result.WarningMessages())
+	}
+	for _, w := range result.Warnings {
+		if w.Code == WarnMetadataLockHeld && w.Severity != SeverityWarning {
+			t.Errorf("short-lived holder: severity %s, want %s", w.Severity, SeverityWarning)
+		}
+	}
+
+	input.MetadataLockHolders = nil
+	input.LongTransactions = []mysql.TransactionInfo{{ProcessID: 9, Seconds: 900}}
+	result = Analyze(input)
+	if result.Risk != RiskDangerous || !containsWarning(result.WarningMessages(), "process 9 open for 900s") {
+		t.Errorf("expected DANGEROUS long-transaction warning, got %s: %v", result.Risk, result.Warnings)
+	}
+
+	input.LongTransactions = nil
+	result = Analyze(input)
+	if result.Risk != RiskSafe || containsWarning(result.WarningMessages(), "metadata lock") {
+		t.Errorf("no lock holders: got %s: %v", result.Risk, result.Warnings)
+	}
+}
+
+func TestWarnings_CodesAndSeverity(t *testing.T) {
+	result := Analyze(dmlInput(parser.Update, false, 200000, 100, 10000, topology.Standalone))
+	if !result.HasWarning(WarnNoWhereClause) {
+		t.Fatalf("expected %s, got: %v", WarnNoWhereClause, result.Warnings)
+	}
+	for _, w := range result.Warnings {
+		if w.Code == WarnNoWhereClause && w.Severity != SeverityCritical {
+			t.Errorf("%s severity = %s, want CRITICAL", w.Code, w.Severity)
+		}
+	}
+
+	// Warnings raised per sub-operation of a multi-op ALTER carry codes too.
+	input := ddlInput(parser.MultipleOps, v8_0_35, 1024, topology.Standalone)
+	input.Parsed.SubOperations = []parser.SubOperation{
+		{Op: parser.AddColumn, ColumnName: "customer_id"},
+		{Op: parser.AddForeignKey},
+	}
+	result = Analyze(input)
+	if !result.HasWarning(WarnFKChecksOnForcesCopy) {
+		t.Errorf("expected %s, got: %v", WarnFKChecksOnForcesCopy, result.Warnings)
+	}
+	if got := result.WarningMessages(); len(got) != len(result.Warnings) || !containsWarning(got, "foreign_key_checks=ON") {
+		t.Errorf("WarningMessages() = %v", got)
+	}
+
+	// Codes without an explicit severity default to WARNING.
+	if w := newWarning(WarnCharsetChangeCopy, "x"); w.Severity != SeverityWarning {
+		t.Errorf("%s severity = %s, want WARNING", w.Code, w.Severity)
+	}
+}
+
+// =============================================================
+// Dropping an index that backs a foreign key
+// =============================================================
+
+func fkIndexInput() Input {
+	input := ddlInput(parser.DropIndex, v8_0_35, 0, topology.Standalone)
+	input.Parsed.IndexName = "idx_user"
+	input.Meta.Indexes = []mysql.IndexInfo{
+		{Name: "PRIMARY", Columns: []string{"id"}, Type: "BTREE"},
+		{Name: "idx_user", Columns: []string{"user_id"}, NonUnique: true, Type: "BTREE"},
+	}
+	input.Meta.ForeignKeys = []mysql.ForeignKeyInfo{
+		{Name: "fk_orders_user", Columns: []string{"user_id"}, ReferencedTable: "users", ReferencedCols: []string{"id"}},
+	}
+	return input
+}
+
+func TestDropIndex_NeededByForeignKey(t *testing.T) {
+	result := Analyze(fkIndexInput())
+
+	if !result.HasWarning(WarnForeignKeyIndexRequired) {
+		t.Fatalf("expected a foreign key index warning, got: %v", result.WarningMessages())
+	}
+	if !containsWarning(result.WarningMessages(), "fk_orders_user") {
+		t.Errorf("expected the warning to name the constraint, got: %v", result.WarningMessages())
+	}
+	if result.Risk != RiskDangerous {
+		t.Errorf("Risk = %s, want DANGEROUS", result.Risk)
+	}
+}
+
+func TestDropIndex_ForeignKeyCoveredByAnotherIndex(t *testing.T) {
+	input := fkIndexInput()
+	input.Meta.Indexes = append(input.Meta.Indexes,
+		mysql.IndexInfo{Name: "idx_user_created", Columns: []string{"user_id", "created_at"}, NonUnique: true, Type: "BTREE"})
+
+	result := Analyze(input)
+
+	if result.HasWarning(WarnForeignKeyIndexRequired) {
+		t.Errorf("another index leads with the FK column, got: %v", result.WarningMessages())
+	}
+	if result.Risk != RiskSafe {
+		t.Errorf("Risk = %s, want SAFE", result.Risk)
+	}
+}
+
+func TestDropIndex_ForeignKeyColumnNotLeading(t *testing.T) {
+	input := fkIndexInput()
+	input.Meta.Indexes = append(input.Meta.Indexes,
+		mysql.IndexInfo{Name: "idx_created_user", Columns: []string{"created_at", "user_id"}, NonUnique: true, Type: "BTREE"})
+
+	result := Analyze(input)
+
+	if !result.HasWarning(WarnForeignKeyIndexRequired) {
+		t.Errorf("an index with the FK column second doesn't cover the FK, got: %v", result.WarningMessages())
+	}
+}
+
+func TestDropIndex_NeededByInboundForeignKey(t *testing.T) {
+	input := fkIndexInput()
+	input.Meta.ForeignKeys = nil
+	input.Parsed.IndexName = "uk_code"
+	input.Meta.Indexes = append(input.Meta.Indexes,
+		mysql.IndexInfo{Name: "uk_code", Columns: []string{"code"}, Type: "BTREE"})
+	input.Meta.InboundForeignKeys = []mysql.ForeignKeyInfo{
+		{Name: "fk_items_code", Columns: []string{"order_code"}, ReferencedCols: []string{"code"}, ChildSchema: "testdb", ChildTable: "items"},
+	}
+
+	result := Analyze(input)
+
+	if !containsWarning(result.WarningMessages(), "fk_items_code") || result.Risk != RiskDangerous {
+		t.Errorf("expected DANGEROUS with the inbound constraint named, got %s: %v", result.Risk, result.WarningMessages())
+	}
+}
+
+func TestMultiOp_DropIndexWithReplacementOrForeignKey(t *testing.T) {
+	for name, other := range map[string]parser.SubOperation{
+		"replacement index": {Op: parser.AddIndex, IndexName: "idx_user2", IndexColumns: []string{"user_id", "status"}},
+		"drop foreign key":  {Op: parser.DropForeignKey, IndexName: "fk_orders_user"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			input := fkIndexInput()
+			input.Parsed.DDLOp = parser.MultipleOps
+			input.Parsed.SubOperations = []parser.SubOperation{
+				{Op: parser.DropIndex, IndexName: "idx_user"},
+				other,
+			}
+
+			result := Analyze(input)
+
+			if result.HasWarning(WarnForeignKeyIndexRequired) {
+				t.Errorf("unexpected foreign key index warning: %v", result.WarningMessages())
+			}
+		})
+	}
+
+	input := fkIndexInput()
+	input.Parsed.DDLOp = parser.MultipleOps
+	input.Parsed.SubOperations = []parser.SubOperation{
+		{Op: parser.DropIndex, IndexName: "idx_user"},
+		{Op: parser.AddColumn, ColumnName: "note"},
+	}
+	if result := Analyze(input); !result.HasWarning(WarnForeignKeyIndexRequired) {
+		t.Errorf("expected a foreign key index warning, got: %v", result.WarningMessages())
+	}
+}
+
+// =============================================================
+// Multi-table RENAME TABLE swaps
+// =============================================================
+
+func renameInput(t *testing.T, sql string) Input {
+	t.Helper()
+	parsed, err := parser.Parse(sql)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	input := ddlInput(parser.RenameTable, v8_0_35, 0, topology.Standalone)
+	input.Parsed = parsed
+	return input
+}
+
+func TestRenameTable_AtomicSwap(t *testing.T) {
+	for _, sql := range []string{
+		"RENAME TABLE orders TO orders_old, orders_new TO orders",
+		"RENAME TABLE a TO tmp, b TO a, tmp TO b",
+	} {
+		result := Analyze(renameInput(t, sql))
+
+		if result.Classification.Algorithm != AlgoInstant || result.Risk != RiskSafe {
+			t.Errorf("%s: got %s/%s, want INSTANT/SAFE", sql, result.Classification.Algorithm, result.Risk)
+		}
+		if !strings.Contains(result.Classification.Notes, "Atomic multi-table RENAME") {
+			t.Errorf("%s: Notes = %q", sql, result.Classification.Notes)
+		}
+		if result.HasWarning(WarnRenameSwapInvalid) {
+			t.Errorf("%s: unexpected warning: %v", sql, result.WarningMessages())
+		}
+	}
+}
+
+func TestRenameTable_InvalidSwap(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"RENAME TABLE orders TO orders_old, orders_new TO orders_old", "already renamed a table to 'orders_old'"},
+		{"RENAME TABLE orders TO orders_tmp, orders_new TO orders, orders_tmp TO orders", "already renamed a table to 'orders'"},
+		{"RENAME TABLE orders TO orders_old, orders TO orders_archive", "'orders' was already renamed away"},
+	}
+	for _, tt := range tests {
+		result := Analyze(renameInput(t, tt.sql))
+
+		if !containsWarning(result.WarningMessages(), tt.want) {
+			t.Errorf("%s: expected %q, got %v", tt.sql, tt.want, result.WarningMessages())
+		}
+		if result.Risk != RiskDangerous {
+			t.Errorf("%s: Risk = %s, want DANGEROUS", tt.sql, result.Risk)
+		}
+	}
+}
+
+// =============================================================
+// Configurable size thresholds
+// =============================================================
+
+func TestSizeThresholds(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+	tests := []struct {
+		name      string
+		op        parser.DDLOperation
+		size      int64
+		dangerous int64
+		caution   int64
+		wantRisk  RiskLevel
+		wantMeth  ExecutionMethod
+	}{
+		{"COPY 2GB default", parser.ChangeEngine, 2 * gb, 0, 0, RiskDangerous, ExecGhost},
+		{"COPY 2GB under raised threshold", parser.ChangeEngine, 2 * gb, 5 * gb, 0, RiskCaution, ExecDirect},
+		{"COPY 500MB over lowered threshold", parser.ChangeEngine, gb / 2, gb / 4, 0, RiskDangerous, ExecGhost},
+		{"INPLACE no lock 20GB default", parser.AddIndex, 20 * gb, 0, 0, RiskCaution, ExecDirect},
+		{"INPLACE no lock 20GB under raised threshold", parser.AddIndex, 20 * gb, 0, 50 * gb, RiskSafe, ExecDirect},
+		{"INPLACE no lock 2GB over lowered threshold", parser.AddIndex, 2 * gb, 0, gb, RiskCaution, ExecDirect},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := ddlInput(tt.op, v8_0_35, tt.size, topology.Standalone)
+			input.DangerousSizeThreshold = tt.dangerous
+			input.CautionSizeThreshold = tt.caution
+
+			result := Analyze(input)
+
+			if result.Risk != tt.wantRisk || result.Method != tt.wantMeth {
+				t.Errorf("got %s via %s, want %s via %s", result.Risk, result.Method, tt.wantRisk, tt.wantMeth)
+			}
+		})
+	}
+}
+
+// =============================================================
+// ADD/DROP COLUMN IF [NOT] EXISTS
+// =============================================================
+
+func TestColumnIfExists_NoOp(t *testing.T) {
+	tests := []struct {
+		op     parser.DDLOperation
+		column string
+	}{
+		{parser.AddColumn, "existing_col"},
+		{parser.DropColumn, "missing_col"},
+	}
+	for _, tt := range tests {
+		input := ddlInput(tt.op, v8_0_35, 0, topology.Standalone)
+		input.Parsed.ColumnName = tt.column
+		input.Parsed.ColumnIfExists = true
+
+		result := Analyze(input)
+
+		if !result.HasWarning(WarnColumnOperationNoOp) {
+			t.Errorf("%s: expected a no-op note, got %v", tt.op, result.WarningMessages())
+		}
+		if result.HasWarning(WarnColumnAlreadyExists) || result.HasWarning(WarnColumnNotFound) || result.Risk == RiskDangerous {
+			t.Errorf("%s: IF [NOT] EXISTS shouldn't be DANGEROUS, got %s %v", tt.op, result.Risk, result.WarningMessages())
+		}
+		if !result.HasWarning(WarnColumnIfExistsSyntax) {
+			t.Errorf("%s: expected the MariaDB syntax warning on MySQL, got %v", tt.op, result.WarningMessages())
+		}
+	}
+}
+
+func TestColumnIfExists_MariaDB(t *testing.T) {
+	input := ddlInput(parser.AddColumn, mysql.ServerVersion{Major: 10, Minor: 11, Patch: 6, Flavor: "mariadb"}, 0, topology.Standalone)
+	input.Parsed.ColumnIfExists = true
+
+	result := Analyze(input)
+
+	if result.HasWarning(WarnColumnIfExistsSyntax) {
+		t.Errorf("MariaDB accepts IF NOT EXISTS, got %v", result.WarningMessages())
+	}
+}
+
+// =============================================================
+// Write-set estimate by binlog_row_image
+// =============================================================
+
+// rowImageInput is a 1000-row DML input on a table with a BIGINT primary key, a
+// VARCHAR(100) utf8mb4 column (402 bytes at most) and a TEXT column, 2000 bytes per row.
+func rowImageInput(op parser.DMLOperation, rowImage string, updateCols ...string) Input {
+	input := dmlInput(op, true, 1000, 2000, 0, topology.Galera)
+	input.EstimatedRows = 1000
+	input.Parsed.UpdateColumns = updateCols
+	input.Topo.BinlogRowImage = rowImage
+	utf8mb4 := "utf8mb4"
+	input.Meta.Columns = []mysql.ColumnInfo{
+		{Name: "id", Type: "bigint", Position: 1},
+		{Name: "name", Type: "varchar(100)", CharacterSet: &utf8mb4, Position: 2},
+		{Name: "body", Type: "text", CharacterSet: &utf8mb4, Position: 3},
+	}
+	input.Meta.Indexes = []mysql.IndexInfo{{Name: "PRIMARY", Columns: []string{"id"}}}
+	return input
+}
+
+func TestEstimateWriteSet_RowImage(t *testing.T) {
+	tests := []struct {
+		name  string
+		input Input
+		want  int64
+	}{
+		{"delete full", rowImageInput(parser.Delete, "full"), 1000 * 2000},
+		{"delete unknown image is full", rowImageInput(parser.Delete, ""), 1000 * 2000},
+		{"delete minimal logs the primary key", rowImageInput(parser.Delete, "minimal"), 1000 * 8},
+		{"delete noblob skips TEXT", rowImageInput(parser.Delete, "noblob"), 1000 * (8 + 402)},
+		{"update full logs before and after", rowImageInput(parser.Update, "full", "name"), 1000 * 2 * 2000},
+		{"update minimal logs pk and changed columns", rowImageInput(parser.Update, "minimal", "name"), 1000 * (8 + 402)},
+		{"update minimal changing TEXT", rowImageInput(parser.Update, "minimal", "body"), 1000 * (8 + 2000)},
+		{"update noblob", rowImageInput(parser.Update, "noblob", "name"), 1000 * 2 * (8 + 402)},
+		{"update noblob changing TEXT", rowImageInput(parser.Update, "noblob", "body"), 1000 * (8 + 402 + 2000)},
+		{"insert is one full row", rowImageInput(parser.Insert, "minimal"), 1000 * 2000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateWriteSet(tt.input, 1000); got != tt.want {
+				t.Errorf("estimateWriteSet = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateWriteSet_MinimalWithoutPrimaryKey(t *testing.T) {
+	input := rowImageInput(parser.Delete, "minimal")
+	input.Meta.Indexes = nil
+
+	// Without a primary key the before image holds every column.
+	if got := estimateWriteSet(input, 1000); got != 1000*2000 {
+		t.Errorf("estimateWriteSet = %d, want %d", got, 1000*2000)
+	}
+}
+
+func TestGaleraWriteSetLimit_MinimalRowImage(t *testing.T) {
+	// 1000 rows * 2000 bytes = ~2 MB with a full row image, over a 1 MB limit; minimal logs
+	// 8 bytes of primary key per row.
+	full := rowImageInput(parser.Delete, "full")
+	full.Topo.WsrepMaxWsSize = 1024 * 1024
+	if result := Analyze(full); !containsWarning(result.ClusterWarningMessages(), "EXCEEDS wsrep_max_ws_size") {
+		t.Errorf("expected write-set exceeded with a full row image, got %v", result.ClusterWarningMessages())
+	}
+
+	minimal := rowImageInput(parser.Delete, "minimal")
+	minimal.Topo.WsrepMaxWsSize = 1024 * 1024
+	result := Analyze(minimal)
+	if containsWarning(result.ClusterWarningMessages(), "EXCEEDS wsrep_max_ws_size") {
+		t.Errorf("minimal row image should fit the limit, got %v", result.ClusterWarningMessages())
+	}
+	if result.WriteSetSize != 8000 {
+		t.Errorf("WriteSetSize = %d, want 8000", result.WriteSetSize)
+	}
+}
+
+// =============================================================
+// ALTER INDEX ... VISIBLE / INVISIBLE
+// =============================================================
+
+func TestClassifyDDL_ChangeIndexVisibility(t *testing.T) {
+	tests := []struct {
+		version mysql.ServerVersion
+		algo    Algorithm
+	}{
+		{v8_0_5, AlgoInplace},
+		{v8_0_20, AlgoInstant},
+		{v8_0_35, AlgoInstant},
+		{v8_4_0, AlgoInstant},
+	}
+	for _, tt := range tests {
+		c := ClassifyDDL(parser.ChangeIndexVisibility, tt.version.Major, tt.version.Minor, tt.version.Patch)
+		if c.Algorithm != tt.algo || c.Lock != LockNone || c.RebuildsTable {
+			t.Errorf("%s: got %s/%s rebuild=%v, want %s/NONE without rebuild", tt.version.String(), c.Algorithm, c.Lock, c.RebuildsTable, tt.algo)
+		}
+	}
+}
+
+func TestAnalyzeDDL_IndexInvisible(t *testing.T) {
+	input := ddlInput(parser.ChangeIndexVisibility, v8_0_35, 50*1024*1024*1024, topology.Standalone)
+	input.Parsed.IndexName = "idx_created"
+	input.Parsed.IndexInvisible = true
+
+	result := Analyze(input)
+
+	if result.Risk != RiskSafe {
+		t.Errorf("Risk = %s, want SAFE", result.Risk)
+	}
+	if !result.HasWarning(WarnInvisibleIndexTrial) {
+		t.Errorf("expected INVISIBLE_INDEX_TRIAL note, got %v", result.WarningMessages())
+	}
+	if want := "ALTER TABLE `testdb`.`test` ALTER INDEX `idx_created` VISIBLE;"; result.RollbackSQL != want {
+		t.Errorf("RollbackSQL = %q, want %q", result.RollbackSQL, want)
+	}
+}
+
+func TestAnalyzeDDL_IndexVisible(t *testing.T) {
+	input := ddlInput(parser.ChangeIndexVisibility, v8_0_35, 50*1024*1024, topology.Standalone)
+	input.Parsed.IndexName = "idx_created"
+
+	result := Analyze(input)
+
+	if result.HasWarning(WarnInvisibleIndexTrial) {
+		t.Errorf("no note expected when making an index visible, got %v", result.WarningMessages())
+	}
+	if want := "ALTER TABLE `testdb`.`test` ALTER INDEX `idx_created` INVISIBLE;"; result.RollbackSQL != want {
+		t.Errorf("RollbackSQL = %q, want %q", result.RollbackSQL, want)
+	}
+}
+
+// =============================================================
+// DELETE on a partitioned table
+// =============================================================
+
+func partitionedDeleteInput(where string, part *mysql.PartitionInfo) Input {
+	input := dmlInput(parser.Delete, true, 1000000, 200, 10000, topology.Standalone)
+	input.Parsed.WhereClause = where
+	input.EstimatedRows = 500000
+	input.Meta.Partitioning = part
+	return input
+}
+
+func rangeColumnsByDate() *mysql.PartitionInfo {
+	return &mysql.PartitionInfo{
+		Method:     "RANGE COLUMNS",
+		Expression: "`created_at`",
+		Partitions: []mysql.Partition{
+			{Name: "p2022", Description: "'2023-01-01'", RowCount: 200000},
+			{Name: "p2023", Description: "'2024-01-01'", RowCount: 300000},
+			{Name: "p2024", Description: "'2025-01-01'", RowCount: 400000},
+			{Name: "pmax", Description: "MAXVALUE", RowCount: 100000},
+		},
+	}
+}
+
+func TestPartitionDropSuggestion(t *testing.T) {
+	tests := []struct {
+		name      string
+		where     string
+		part      *mysql.PartitionInfo
+		want      []string
+		notWant   []string
+		suggested bool
+	}{
+		{
+			name:      "boundary-aligned range",
+			where:     "created_at < '2024-01-01'",
+			part:      rangeColumnsByDate(),
+			want:      []string{"DROP PARTITION p2022, p2023", "~500.0K rows", "no binlog row events"},
+			notWant:   []string{"remaining rows"},
+			suggested: true,
+		},
+		{
+			name:      "range ending inside a partition",
+			where:     "created_at < '2024-06-01'",
+			part:      rangeColumnsByDate(),
+			want:      []string{"DROP PARTITION p2022, p2023", "DELETE only the remaining rows"},
+			suggested: true,
+		},
+		{
+			name:      "<= a bound includes the next partition's first value",
+			where:     "created_at <= '2024-01-01'",
+			part:      rangeColumnsByDate(),
+			want:      []string{"DROP PARTITION p2022, p2023", "DELETE only the remaining rows"},
+			suggested: true,
+		},
+		{
+			name:  "function of the key",
+			where: "created_at < '2024-01-01'",
+			part: &mysql.PartitionInfo{
+				Method:     "RANGE",
+				Expression: "to_days(`created_at`)",
+				Partitions: []mysql.Partition{{Name: "p0", Description: "738886", RowCount: 500000}},
+			},
+			want:      []string{"partitioned on to_days(`created_at`)", "EXPLAIN shows the partitions read"},
+			suggested: true,
+		},
+		{
+			name:      "WHERE on another column",
+			where:     "status = 'archived'",
+			part:      rangeColumnsByDate(),
+			suggested: false,
+		},
+		{
+			name:      "range AND another condition",
+			where:     "created_at < '2025-01-01' AND tenant_id = 7",
+			part:      rangeColumnsByDate(),
+			suggested: false,
+		},
+		{
+			name:      "range AND a quoted condition",
+			where:     "created_at < '2025-01-01' AND tenant = 'acme'",
+			part:      rangeColumnsByDate(),
+			suggested: false,
+		},
+		{
+			name:      "range OR another condition",
+			where:     "created_at < '2024-01-01' OR status = 'archived'",
+			part:      rangeColumnsByDate(),
+			suggested: false,
+		},
+		{
+			name:      "parenthesized conjunction",
+			where:     "(created_at < '2024-01-01' AND (tenant_id = 7))",
+			part:      rangeColumnsByDate(),
+			suggested: false,
+		},
+		{
+			name:      "range compared with a column",
+			where:     "created_at < updated_at",
+			part:      rangeColumnsByDate(),
+			suggested: false,
+		},
+		{
+			name:      "hash partitioning",
+			where:     "id < 500000",
+			part:      &mysql.PartitionInfo{Method: "HASH", Expression: "`id`"},
+			suggested: false,
+		},
+		{
+			name:      "not partitioned",
+			where:     "created_at < '2024-01-01'",
+			suggested: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Analyze(partitionedDeleteInput(tt.where, tt.part))
+			got := strings.Contains(result.Recommendation, "PARTITION")
+			if got != tt.suggested {
+				t.Fatalf("partition suggestion = %v, want %v; recommendation: %s", got, tt.suggested, result.Recommendation)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(result.Recommendation, w) {
+					t.Errorf("recommendation missing %q: %s", w, result.Recommendation)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(result.Recommendation, w) {
+					t.Errorf("recommendation should not contain %q: %s", w, result.Recommendation)
+				}
+			}
+		})
+	}
+}
+
+func TestPartitionDropSuggestion_SmallDelete(t *testing.T) {
+	input := partitionedDeleteInput("created_at < '2023-01-01'", rangeColumnsByDate())
+	input.EstimatedRows = 500
+
+	result := Analyze(input)
+
+	if strings.Contains(result.Recommendation, "PARTITION") {
+		t.Errorf("no partition suggestion expected for a small DELETE: %s", result.Recommendation)
+	}
+}
+
+// =============================================================
+// Cut-over lock advisory
+// =============================================================
+
+func TestCutoverWarning_Ghost(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 2*1024*1024*1024, topology.Standalone)
+	result := Analyze(input)
+
+	if result.Method != ExecGhost {
+		t.Fatalf("expected ExecGhost, got %s", result.Method)
+	}
+	if !containsWarning(result.ClusterWarningMessages(), "gh-ost cut-over") || !containsWarning(result.ClusterWarningMessages(), "ghost.postpone.flag") {
+		t.Errorf("expected gh-ost cut-over warning, got: %v", result.ClusterWarningMessages())
+	}
+	if containsWarning(result.ClusterWarningMessages(), "pt-online-schema-change cut-over") {
+		t.Errorf("pt-osc cut-over warning should not appear for gh-ost, got: %v", result.ClusterWarningMessages())
+	}
+}
+
+func TestCutoverWarning_PtOSCOnGalera(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 2*1024*1024*1024, topology.Galera)
+	result := Analyze(input)
+
+	if result.Method != ExecPtOSC {
+		t.Fatalf("expected ExecPtOSC, got %s", result.Method)
+	}
+	if !containsWarning(result.ClusterWarningMessages(), "pt-online-schema-change cut-over") || !containsWarning(result.ClusterWarningMessages(), "--max-lag") {
+		t.Errorf("expected pt-osc cut-over warning, got: %v", result.ClusterWarningMessages())
+	}
+	if containsWarning(result.ClusterWarningMessages(), "gh-ost cut-over") {
+		t.Errorf("gh-ost cut-over warning should not appear once gh-ost was replaced, got: %v", result.ClusterWarningMessages())
+	}
+}
+
+func TestCutoverWarning_DirectHasNone(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 50*1024*1024, topology.Standalone)
+	result := Analyze(input)
+
+	if containsWarning(result.ClusterWarningMessages(), "cut-over") {
+		t.Errorf("no cut-over warning expected for direct execution, got: %v", result.ClusterWarningMessages())
+	}
+}
+
+// =============================================================
+// Session vs global foreign_key_checks / unique_checks
+// =============================================================
+
+func TestCheckSettingsWarning(t *testing.T) {
+	tests := []struct {
+		name     string
+		checks   *mysql.CheckSettings
+		want     bool
+		contains string
+	}{
+		{"not read", nil, false, ""},
+		{"same", &mysql.CheckSettings{ForeignKeyChecks: true, UniqueChecks: true, GlobalForeignKeyChecks: true, GlobalUniqueChecks: true}, false, ""},
+		{"fk off in session", &mysql.CheckSettings{ForeignKeyChecks: false, UniqueChecks: true, GlobalForeignKeyChecks: true, GlobalUniqueChecks: true}, true, "foreign_key_checks is OFF in this session but ON globally"},
+		{"unique off globally", &mysql.CheckSettings{ForeignKeyChecks: true, UniqueChecks: true, GlobalForeignKeyChecks: true, GlobalUniqueChecks: false}, true, "unique_checks is ON in this session but OFF globally"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := ddlInput(parser.AddForeignKey, v8_0_35, 50*1024*1024, topology.Standalone)
+			input.CheckSettings = tt.checks
+			result := Analyze(input)
+			if got := result.HasWarning(WarnCheckSettingsDiffer); got != tt.want {
+				t.Fatalf("CHECK_SETTINGS_DIFFER = %v, want %v: %v", got, tt.want, result.WarningMessages())
+			}
+			if tt.contains != "" && !containsWarning(result.WarningMessages(), tt.contains) {
+				t.Errorf("expected %q in warnings, got %v", tt.contains, result.WarningMessages())
+			}
+		})
+	}
+}
+
+// =============================================================
+// INSTANT row version limit
+// =============================================================
+
+func TestRowVersionLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions int64
+		wantAlgo Algorithm
+		wantWarn bool
+	}{
+		{"few versions", 10, AlgoInstant, false},
+		{"near the limit", 60, AlgoInstant, true},
+		{"at the limit", 64, AlgoInplace, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := ddlInput(parser.AddColumn, v8_0_35, 50*1024*1024, topology.Standalone)
+			input.Parsed.ColumnName = "new_col"
+			input.Meta.TotalRowVersions = tt.versions
+
+			result := Analyze(input)
+
+			if result.Classification.Algorithm != tt.wantAlgo {
+				t.Errorf("Algorithm = %s, want %s", result.Classification.Algorithm, tt.wantAlgo)
+			}
+			if got := result.HasWarning(WarnRowVersionLimit); got != tt.wantWarn {
+				t.Errorf("ROW_VERSION_LIMIT = %v, want %v: %v", got, tt.wantWarn, result.WarningMessages())
+			}
+			if tt.wantWarn && !containsWarning(result.WarningMessages(), "FORCE") {
+				t.Errorf("expected a FORCE suggestion, got %v", result.WarningMessages())
+			}
+		})
+	}
+}
+
+func TestRowVersionLimit_MultipleOps(t *testing.T) {
+	input := ddlInput(parser.MultipleOps, v8_0_35, 50*1024*1024, topology.Standalone)
+	input.Parsed.SubOperations = []parser.SubOperation{
+		{Op: parser.AddColumn, ColumnName: "a"},
+		{Op: parser.DropColumn, ColumnName: "b"},
+	}
+	input.Meta.Columns = []mysql.ColumnInfo{{Name: "b", Type: "int"}}
+	input.Meta.TotalRowVersions = 64
+
+	result := Analyze(input)
+
+	if result.Classification.Algorithm != AlgoInplace || !result.Classification.RebuildsTable {
+		t.Errorf("Classification = %+v, want INPLACE with rebuild", result.Classification)
+	}
+	for _, sub := range result.SubOpResults {
+		if sub.Classification.Algorithm != AlgoInplace {
+			t.Errorf("%s: Algorithm = %s, want INPLACE", sub.Op, sub.Classification.Algorithm)
+		}
+	}
+	if !result.HasWarning(WarnRowVersionLimit) {
+		t.Errorf("expected ROW_VERSION_LIMIT, got %v", result.WarningMessages())
+	}
+}
+
+// =============================================================
+// Partitioning scheme changes
+// =============================================================
+
+func TestPartitioningSchemeChange(t *testing.T) {
+	for _, op := range []parser.DDLOperation{parser.RemovePartitioning, parser.RepartitionTable} {
+		t.Run(string(op), func(t *testing.T) {
+			small := Analyze(ddlInput(op, v8_0_35, 50*1024*1024, topology.Standalone))
+			if c := small.Classification; c.Algorithm != AlgoCopy || c.Lock != LockShared || !c.RebuildsTable {
+				t.Errorf("Classification = %+v, want COPY, SHARED, rebuild", c)
+			}
+			if small.Method != ExecDirect {
+				t.Errorf("small table: Method = %s, want DIRECT", small.Method)
+			}
+
+			large := Analyze(ddlInput(op, v8_0_35, 5*1024*1024*1024, topology.Standalone))
+			if large.Method != ExecPtOSC || large.AlternativeMethod != "" {
+				t.Errorf("large table: Method = %s (alternative %q), want PTOSC only", large.Method, large.AlternativeMethod)
+			}
+			if large.MethodRationale != ptOSCPartitioningRationale {
+				t.Errorf("MethodRationale = %q, want the partitioning rationale", large.MethodRationale)
+			}
+			if large.DiskEstimate == nil || large.DiskEstimate.RequiredBytes < 5*1024*1024*1024 {
+				t.Errorf("DiskEstimate = %+v, want at least the whole table", large.DiskEstimate)
+			}
+		})
+	}
+}
+
+func TestRepartition_PartitionKeyNotInUniqueKey(t *testing.T) {
+	input := ddlInput(parser.RepartitionTable, v8_0_35, 50*1024*1024, topology.Standalone)
+	input.Parsed.PartitionColumns = []string{"created_at"}
+	input.Meta.Indexes = []mysql.IndexInfo{
+		{Name: "PRIMARY", Columns: []string{"id"}},
+		{Name: "uk_order", Columns: []string{"order_no", "created_at"}},
+		{Name: "idx_status", Columns: []string{"status"}, NonUnique: true},
+	}
+
+	result := Analyze(input)
+
+	if !result.HasWarning(WarnPartitionKeyNotInUniqueKey) {
+		t.Fatalf("expected PARTITION_KEY_NOT_IN_UNIQUE_KEY, got %v", result.WarningMessages())
+	}
+	if !containsWarning(result.WarningMessages(), "`PRIMARY` (id) does not") {
+		t.Errorf("expected only PRIMARY to be listed, got %v", result.WarningMessages())
+	}
+	if result.Risk != RiskDangerous {
+		t.Errorf("Risk = %s, want DANGEROUS", result.Risk)
+	}
+
+	input.Meta.Indexes[0].Columns = []string{"id", "created_at"}
+	if result := Analyze(input); result.HasWarning(WarnPartitionKeyNotInUniqueKey) {
+		t.Errorf("expected no warning once every unique key has the column, got %v", result.WarningMessages())
+	}
+}
+
+// =============================================================
+// Free disk space
+// =============================================================
+
+func TestInsufficientDiskSpace(t *testing.T) {
+	tests := []struct {
+		name     string
+		free     int64
+		wantWarn bool
+	}{
+		{"unknown", 0, false},
+		{"enough", 10 * 1024 * 1024 * 1024, false},
+		{"too little", 1 * 1024 * 1024 * 1024, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := ddlInput(parser.ChangeEngine, v8_0_35, 2*1024*1024*1024, topology.Standalone)
+			input.FreeDiskBytes = tt.free
+
+			result := Analyze(input)
+
+			if result.DiskEstimate == nil {
+				t.Fatal("expected a disk estimate")
+			}
+			if got := result.HasWarning(WarnInsufficientDiskSpace); got != tt.wantWarn {
+				t.Errorf("INSUFFICIENT_DISK_SPACE = %v, want %v: %v", got, tt.wantWarn, result.WarningMessages())
+			}
+			if tt.wantWarn {
+				if result.Risk != RiskDangerous {
+					t.Errorf("Risk = %s, want DANGEROUS", result.Risk)
+				}
+				if !containsWarning(result.WarningMessages(), "only 1.0 GB is free") {
+					t.Errorf("expected the free space in the message, got %v", result.WarningMessages())
+				}
+			}
+		})
+	}
+}
+
+// =============================================================
+// Multi-table DELETE / UPDATE
+// =============================================================
+
+func TestMultiTableDML(t *testing.T) {
+	parsed, err := parser.Parse("DELETE o FROM shop.orders o JOIN customers c ON c.id = o.customer_id WHERE c.status = 'closed'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := dmlInput(parser.Delete, true, 10_000_000, 200, 10000, topology.Standalone)
+	input.Parsed = parsed
+	input.Meta.Database, input.Meta.Table = "shop", "orders"
+	input.Meta.Indexes = []mysql.IndexInfo{{Name: "PRIMARY", Columns: []string{"id"}}}
+	input.Meta.Columns = []mysql.ColumnInfo{{Name: "id", Type: "bigint"}}
+	input.EstimatedRows = 500_000
+
+	result := Analyze(input)
+
+	if result.Table != "orders" || result.Database != "shop" {
+		t.Errorf("table = %s.%s, want shop.orders", result.Database, result.Table)
+	}
+	if !result.HasWarning(WarnMultiTableDML) {
+		t.Fatalf("expected MULTI_TABLE_DML, got %v", result.WarningMessages())
+	}
+	for _, want := range []string{"joining shop.orders, customers", "deletes from shop.orders", "o.`id` BETWEEN @lo AND @hi"} {
+		if !containsWarning(result.WarningMessages(), want) {
+			t.Errorf("expected %q in the warning, got %v", want, result.WarningMessages())
+		}
+	}
+	if result.Method != ExecChunked || result.GeneratedScript != "" {
+		t.Errorf("Method = %s with script %q, want CHUNKED without a generated script", result.Method, result.GeneratedScript)
+	}
+	if backup := result.RollbackOptions[0].SQL; !strings.Contains(backup, "SELECT DISTINCT o.* FROM shop.orders as o join customers as c") {
+		t.Errorf("backup SQL = %q, want the target's rows through the join", backup)
+	}
+}
+
+func TestMultiTableDML_NoEstimate(t *testing.T) {
+	parsed, err := parser.Parse("UPDATE orders o JOIN customers c ON c.id = o.customer_id SET o.status = 'vip' WHERE c.vip = 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := dmlInput(parser.Update, true, 1000, 200, 10000, topology.Standalone)
+	input.Parsed = parsed
+	input.Meta.Indexes = []mysql.IndexInfo{{Name: "PRIMARY", Columns: []string{"id"}}}
+
+	result := Analyze(input)
+
+	if !containsWarning(result.WarningMessages(), "SELECT COUNT(DISTINCT o.id) FROM orders as o join customers as c on c.id = o.customer_id WHERE c.vip = 1;") {
+		t.Errorf("expected a count through the join, got %v", result.WarningMessages())
+	}
+}
+
+// =============================================================
+// MODIFY / CHANGE COLUMN with several kinds of change
+// =============================================================
+
+func TestMixedColumnChanges(t *testing.T) {
+	utf8 := "utf8mb4"
+	tests := []struct {
+		name       string
+		sql        string
+		wantAlgo   Algorithm
+		wantLock   LockLevel
+		wantWarn   bool
+		dominating string
+	}{
+		{"enum append and reorder", "ALTER TABLE test MODIFY COLUMN status ENUM('a','b','c') AFTER id",
+			AlgoInplace, LockNone, true, "the moved AFTER `id` change decides it"},
+		{"varchar extension and NOT NULL", "ALTER TABLE test MODIFY COLUMN name VARCHAR(60) NOT NULL",
+			AlgoInplace, LockNone, true, "the NULL → NOT NULL change decides it"},
+		{"type change and reorder", "ALTER TABLE test MODIFY COLUMN name TEXT FIRST",
+			AlgoCopy, LockShared, true, "the type varchar(20) → text change decides it"},
+		{"rename and reorder", "ALTER TABLE test CHANGE COLUMN name full_name VARCHAR(20) AFTER id",
+			AlgoInplace, LockNone, true, "the moved AFTER `id` change decides it"},
+		{"reorder only", "ALTER TABLE test MODIFY COLUMN name VARCHAR(20) FIRST",
+			AlgoInplace, LockNone, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parser.Parse(tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			input := ddlInput(parsed.DDLOp, v8_0_35, 50*1024*1024, topology.Standalone)
+			input.Parsed = parsed
+			input.Meta.Columns = []mysql.ColumnInfo{
+				{Name: "id", Type: "int"},
+				{Name: "status", Type: "enum('a','b')", Nullable: true, CharacterSet: &utf8},
+				{Name: "name", Type: "varchar(20)", Nullable: true, CharacterSet: &utf8},
+			}
+
+			result := Analyze(input)
+
+			if result.Classification.Algorithm != tt.wantAlgo || result.Classification.Lock != tt.wantLock || !result.Classification.RebuildsTable {
+				t.Errorf("classification = %+v, want %s/%s with a rebuild", result.Classification, tt.wantAlgo, tt.wantLock)
+			}
+			if result.HasWarning(WarnMixedColumnChanges) != tt.wantWarn {
+				t.Fatalf("MIXED_COLUMN_CHANGES = %v, want %v: %v", !tt.wantWarn, tt.wantWarn, result.WarningMessages())
+			}
+			if tt.wantWarn && !containsWarning(result.WarningMessages(), tt.dominating) {
+				t.Errorf("expected %q, got %v", tt.dominating, result.WarningMessages())
+			}
+		})
+	}
+}
+
+// =============================================================
+// ENGINE_ATTRIBUTE and secondary engines
+// =============================================================
+
+func TestEngineAttributeAndSecondaryEngine(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       parser.DDLOperation
+		version  mysql.ServerVersion
+		wantAlgo Algorithm
+		wantRisk RiskLevel
+		wantWarn WarningCode
+	}{
+		{"engine attribute", parser.EngineAttribute, v8_0_35, AlgoInstant, RiskSafe, ""},
+		{"engine attribute before 8.0.21", parser.EngineAttribute, mysql.ServerVersion{Major: 8, Minor: 0, Patch: 20}, AlgoInstant, RiskDangerous, WarnEngineAttributeUnsupported},
+		{"secondary engine", parser.SecondaryEngine, v8_0_35, AlgoInstant, RiskSafe, ""},
+		{"secondary load", parser.SecondaryLoad, v8_0_35, AlgoInplace, RiskSafe, WarnSecondaryLoad},
+		{"secondary unload", parser.SecondaryUnload, v8_0_35, AlgoInplace, RiskSafe, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := ddlInput(tt.op, tt.version, 50*1024*1024, topology.Standalone)
+			result := Analyze(input)
+
+			if result.Classification.Algorithm != tt.wantAlgo || result.Classification.Lock != LockNone || result.Classification.RebuildsTable {
+				t.Errorf("classification = %+v, want %s without a lock or rebuild", result.Classification, tt.wantAlgo)
+			}
+			if result.Risk != tt.wantRisk || result.Method != ExecDirect {
+				t.Errorf("Risk = %s, Method = %s, want %s and DIRECT", result.Risk, result.Method, tt.wantRisk)
+			}
+			if result.HasWarning(WarnDDLUnparsed) {
+				t.Errorf("unexpected DDL_UNPARSED warning: %v", result.WarningMessages())
+			}
+			if tt.wantWarn != "" && !result.HasWarning(tt.wantWarn) {
+				t.Errorf("expected %s, got %v", tt.wantWarn, result.WarningMessages())
+			}
+		})
+	}
+}
+
+// =============================================================
+// Foreign key cascades in DML
+// =============================================================
+
+func TestFKCascadeAmplification(t *testing.T) {
+	withChild := func(op parser.DMLOperation, deleteRule, updateRule string, setCols ...string) Input {
+		input := dmlInput(op, true, 1_000_000, 100, 1000, topology.Standalone)
+		input.EstimatedRows = 5000
+		input.Parsed.UpdateColumns = setCols
+		input.Meta.InboundForeignKeys = []mysql.ForeignKeyInfo{{
+			Name: "fk_items_test", Columns: []string{"test_id"}, ReferencedCols: []string{"id"},
+			ChildSchema: "testdb", ChildTable: "items", ChildRows: 40_000_000, ChildAvgRowLen: 200,
+			DeleteRule: deleteRule, UpdateRule: updateRule,
+		}}
+		return input
+	}
+
+	t.Run("delete cascade", func(t *testing.T) {
+		result := Analyze(withChild(parser.Delete, "CASCADE", "RESTRICT"))
+		if result.CascadeRows != 200_000 {
+			t.Errorf("CascadeRows = %d, want 200000", result.CascadeRows)
+		}
+		if want := int64(5000*100 + 200_000*200); result.WriteSetSize != want {
+			t.Errorf("WriteSetSize = %d, want %d", result.WriteSetSize, want)
+		}
+		if result.Risk != RiskDangerous || result.Method != ExecChunked {
+			t.Errorf("Risk = %s, Method = %s, want DANGEROUS and CHUNKED", result.Risk, result.Method)
+		}
+		if !result.HasWarning(WarnFKCascadeAmplification) || !containsWarning(result.WarningMessages(), "`testdb`.`items` (ON DELETE CASCADE)") {
+			t.Errorf("expected a cascade warning naming the child, got %v", result.WarningMessages())
+		}
+	})
+
+	t.Run("galera write-set limit", func(t *testing.T) {
+		input := withChild(parser.Delete, "SET NULL", "RESTRICT")
+		input.Topo = &topology.Info{Type: topology.Galera, WsrepMaxWsSize: 16 * 1024 * 1024}
+		result := Analyze(input)
+		if !containsWarning(result.WarningMessages(), "wsrep_max_ws_size") {
+			t.Errorf("expected the cascade warning to mention wsrep_max_ws_size, got %v", result.WarningMessages())
+		}
+		if len(result.ClusterWarnings) == 0 {
+			t.Errorf("expected the write-set limit to be exceeded once cascades are counted")
+		}
+	})
+
+	t.Run("update of an unreferenced column", func(t *testing.T) {
+		result := Analyze(withChild(parser.Update, "CASCADE", "CASCADE", "status"))
+		if result.HasWarning(WarnFKCascadeAmplification) || result.CascadeRows != 0 {
+			t.Errorf("unexpected cascade for an UPDATE that doesn't set a referenced column: %v", result.WarningMessages())
+		}
+	})
+
+	t.Run("update of the referenced key", func(t *testing.T) {
+		result := Analyze(withChild(parser.Update, "RESTRICT", "CASCADE", "id"))
+		if !containsWarning(result.WarningMessages(), "(ON UPDATE CASCADE)") {
+			t.Errorf("expected an ON UPDATE CASCADE warning, got %v", result.WarningMessages())
+		}
+	})
+
+	t.Run("restrict", func(t *testing.T) {
+		result := Analyze(withChild(parser.Delete, "RESTRICT", "RESTRICT"))
+		if result.HasWarning(WarnFKCascadeAmplification) || result.Risk != RiskSafe {
+			t.Errorf("Risk = %s, warnings %v: RESTRICT doesn't cascade", result.Risk, result.WarningMessages())
+		}
+	})
+}
+
+// =============================================================
+// Generated column ordering
+// =============================================================
+
+func TestGeneratedColumnOrder(t *testing.T) {
+	meta := &mysql.TableMetadata{
+		Database: "testdb", Table: "orders", RowCount: 1000, AvgRowLength: 100,
+		Columns: []mysql.ColumnInfo{
+			{Name: "id", Type: "int", Position: 1},
+			{Name: "price", Type: "decimal(10,2)", Position: 2},
+			{Name: "qty", Type: "int", Position: 3},
+			{Name: "total", Type: "decimal(12,2)", Position: 4, IsStoredGenerated: true, GenerationExpr: "(`price` * `qty`)"},
+			{Name: "tax", Type: "decimal(12,2)", Position: 5, GenerationExpr: "(`total` * 0.2)"},
+		},
+	}
+	tests := []struct {
+		name     string
+		sql      string
+		wantWarn bool
+	}{
+		{"base columns later", "ALTER TABLE orders ADD COLUMN g DECIMAL(12,2) AS (price * qty) STORED FIRST", false},
+		{"generated column later", "ALTER TABLE orders ADD COLUMN g DECIMAL(12,2) AS (total + 1) STORED FIRST", true},
+		{"after the generated column", "ALTER TABLE orders ADD COLUMN g DECIMAL(12,2) AS (total + 1) VIRTUAL AFTER total", false},
+		{"before the generated column", "ALTER TABLE orders ADD COLUMN g DECIMAL(12,2) AS (total + 1) VIRTUAL AFTER qty", true},
+		{"moved after a dependent", "ALTER TABLE orders MODIFY COLUMN total DECIMAL(12,2) AS (price * qty) STORED AFTER tax", true},
+		{"multi-op", "ALTER TABLE orders ADD COLUMN x INT, ADD COLUMN g DECIMAL(12,2) AS (tax * 2) VIRTUAL AFTER id", true},
+		{"no position", "ALTER TABLE orders ADD COLUMN g DECIMAL(12,2) AS (tax * 2) VIRTUAL", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parser.Parse(tt.sql)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			result := Analyze(Input{Parsed: parsed, Meta: meta, Version: v8_0_35, Topo: &topology.Info{Type: topology.Standalone}, ChunkSize: 1000})
+			if got := result.HasWarning(WarnGeneratedColumnOrder); got != tt.wantWarn {
+				t.Fatalf("GENERATED_COLUMN_ORDER = %v, want %v: %v", got, tt.wantWarn, result.WarningMessages())
+			}
+			if tt.wantWarn && result.Risk != RiskDangerous {
+				t.Errorf("Risk = %s, want DANGEROUS", result.Risk)
+			}
+		})
+	}
+}
+
+// =============================================================
+// Decision trace
+// =============================================================
+
+func TestTrace(t *testing.T) {
+	input := ddlInput(parser.AddPrimaryKey, v8_0_35, 50*1024*1024*1024, topology.Standalone)
+	input.Parsed.IndexColumns = []string{"id"}
+	input.Meta.Columns = append(input.Meta.Columns, mysql.ColumnInfo{Name: "id", Type: "int", Nullable: true})
+
+	if result := Analyze(input); len(result.Trace) != 0 {
+		t.Fatalf("Trace recorded without Input.Trace: %v", result.Trace)
+	}
+
+	input.Trace = true
+	result := Analyze(input)
+	var steps []string
+	for _, s := range result.Trace {
+		steps = append(steps, s.Step)
+	}
+	want := []string{
+		"matrix baseline: ADD_PRIMARY_KEY on 8.0.35, range 8.0.29+",
+		"ADD PRIMARY KEY on a nullable column",
+		"risk and method from the algorithm, lock and table size",
+		"final, after topology, disk space, server and metadata lock checks",
+	}
+	if !slices.Equal(steps, want) {
+		t.Fatalf("trace steps = %q, want %q", steps, want)
+	}
+	if result.Trace[0].Classification.Algorithm != AlgoInplace || result.Trace[1].Classification.Algorithm != AlgoCopy {
+		t.Errorf("trace classifications = %+v", result.Trace)
+	}
+	last := result.Trace[len(result.Trace)-1]
+	if last.Detail != fmt.Sprintf("risk %s, method %s", result.Risk, result.Method) {
+		t.Errorf("final step detail = %q, want the result's risk and method", last.Detail)
+	}
+}
+
+// =============================================================
+// CONVERT TO CHARACTER SET: columns already in the target charset
+// =============================================================
+
+func TestConvertCharset_SkipsColumnsAlreadyConverted(t *testing.T) {
+	utf8mb4, latin1 := "utf8mb4", "latin1"
+	ai, bin, swedish := "utf8mb4_0900_ai_ci", "utf8mb4_bin", "latin1_swedish_ci"
+	indexes := []mysql.IndexInfo{
+		{Name: "idx_email", Columns: []string{"email"}, SubParts: []int{0}, Type: "BTREE"},
+		{Name: "idx_code", Columns: []string{"code"}, SubParts: []int{0}, Type: "BTREE"},
+	}
+	tests := []struct {
+		name      string
+		collation string // COLLATE clause
+		code      mysql.ColumnInfo
+		wantAlgo  Algorithm
+		unchanged string // indexed columns the conversion leaves alone
+	}{
+		{"indexed columns already in the default collation", "",
+			mysql.ColumnInfo{Name: "code", Type: "varchar(10)", CharacterSet: &utf8mb4, Collation: &ai}, AlgoInplace, "email, code"},
+		{"indexed column in another collation", "",
+			mysql.ColumnInfo{Name: "code", Type: "varchar(10)", CharacterSet: &utf8mb4, Collation: &bin}, AlgoCopy, "email"},
+		{"indexed column in the COLLATE clause's collation", "utf8mb4_bin",
+			mysql.ColumnInfo{Name: "code", Type: "varchar(10)", CharacterSet: &utf8mb4, Collation: &bin}, AlgoCopy, "code"},
+		{"indexed column with an explicit other charset", "",
+			mysql.ColumnInfo{Name: "code", Type: "varchar(10)", CharacterSet: &latin1, Collation: &swedish}, AlgoCopy, "email"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := ddlInput(parser.ConvertCharset, v8_0_35, 100*1024*1024, topology.Standalone)
+			input.Parsed.NewCharset = "utf8mb4"
+			input.Parsed.NewCollation = tt.collation
+			input.Meta.Columns = []mysql.ColumnInfo{
+				{Name: "id", Type: "int"},
+				{Name: "email", Type: "varchar(100)", CharacterSet: &utf8mb4, Collation: &ai},
+				tt.code,
+			}
+			input.Meta.Indexes = indexes
+			result := Analyze(input)
+			if result.Classification.Algorithm != tt.wantAlgo || result.Classification.Lock != LockShared {
+				t.Errorf("classification = %s/%s, want %s/SHARED: %s",
+					result.Classification.Algorithm, result.Classification.Lock, tt.wantAlgo, result.Classification.Notes)
+			}
+			if !strings.Contains(result.Classification.Notes, "column(s) "+tt.unchanged+" already use") {
+				t.Errorf("Notes should name the unconverted columns %s: %s", tt.unchanged, result.Classification.Notes)
+			}
+		})
+	}
+}
+
+// =============================================================
+// Binlog volume
+// =============================================================
+
+func TestBinlogVolume(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+
+	t.Run("online schema change copies the table", func(t *testing.T) {
+		result := Analyze(ddlInput(parser.ModifyColumn, v8_0_35, 100*gb, topology.Standalone))
+		if result.Method != ExecGhost {
+			t.Fatalf("Method = %s, want gh-ost", result.Method)
+		}
+		if result.EstimatedBinlogBytes != 50*gb {
+			t.Errorf("EstimatedBinlogBytes = %d, want the data length %d", result.EstimatedBinlogBytes, int64(50*gb))
+		}
+		if result.HasWarning(WarnBinlogVolume) {
+			t.Errorf("no limit or free space known: expected no warning, got %v", result.WarningMessages())
+		}
+	})
+
+	t.Run("native alter logs only the statement", func(t *testing.T) {
+		result := Analyze(ddlInput(parser.ModifyColumn, v8_0_35, 10*1024*1024, topology.Standalone))
+		if result.Method != ExecDirect || result.EstimatedBinlogBytes != 0 {
+			t.Errorf("Method = %s, EstimatedBinlogBytes = %d, want DIRECT and 0", result.Method, result.EstimatedBinlogBytes)
+		}
+	})
+
+	t.Run("dml excludes cascaded rows", func(t *testing.T) {
+		input := dmlInput(parser.Delete, true, 1_000_000, 100, 1000, topology.Standalone)
+		input.EstimatedRows = 5000
+		input.Meta.InboundForeignKeys = []mysql.ForeignKeyInfo{{
+			Name: "fk_items_test", Columns: []string{"test_id"}, ReferencedCols: []string{"id"},
+			ChildSchema: "testdb", ChildTable: "items", ChildRows: 40_000_000, ChildAvgRowLen: 200,
+			DeleteRule: "CASCADE", UpdateRule: "RESTRICT",
+		}}
+		result := Analyze(input)
+		if result.EstimatedBinlogBytes != 5000*100 {
+			t.Errorf("EstimatedBinlogBytes = %d, want %d", result.EstimatedBinlogBytes, 5000*100)
+		}
+	})
+
+	t.Run("binlog_space_limit", func(t *testing.T) {
+		input := ddlInput(parser.ModifyColumn, v8_0_35, 100*gb, topology.Standalone)
+		input.ServerConfig.BinlogSpaceLimit = 64 * gb
+		result := Analyze(input)
+		if !result.HasWarning(WarnBinlogVolume) || !containsWarning(result.WarningMessages(), "78% of binlog_space_limit (64.0 GB)") {
+			t.Errorf("expected a binlog_space_limit warning, got %v", result.WarningMessages())
+		}
+	})
+
+	t.Run("binlog filesystem too small", func(t *testing.T) {
+		input := ddlInput(parser.ModifyColumn, v8_0_35, 100*gb, topology.Standalone)
+		input.BinlogFreeBytes = 20 * gb
+		result := Analyze(input)
+		if result.Risk != RiskDangerous || !containsWarning(result.WarningMessages(), "only 20.0 GB is free") {
+			t.Errorf("Risk = %s, want DANGEROUS with a free space warning, got %v", result.Risk, result.WarningMessages())
+		}
+	})
+
+	t.Run("binary log disabled", func(t *testing.T) {
+		input := ddlInput(parser.ModifyColumn, v8_0_35, 100*gb, topology.Standalone)
+		input.ServerConfig.BinlogDisabled = true
+		input.BinlogFreeBytes = 20 * gb
+		result := Analyze(input)
+		if result.EstimatedBinlogBytes != 0 || result.HasWarning(WarnBinlogVolume) {
+			t.Errorf("EstimatedBinlogBytes = %d, warnings %v: want neither", result.EstimatedBinlogBytes, result.WarningMessages())
+		}
+	})
+}
+
+// =============================================================
+// ADD PRIMARY KEY duplicate check
+// =============================================================
+
+func TestAddPrimaryKey_DuplicateCheckEmphasis(t *testing.T) {
+	pkInput := func(columns []string, meta *mysql.TableMetadata) Input {
+		meta.Table = "orders"
+		return Input{
+			Parsed: &parser.ParsedSQL{
+				Type:         parser.DDL,
+				RawSQL:       "ALTER TABLE orders ADD PRIMARY KEY (" + strings.Join(columns, ", ") + ")",
+				Table:        "orders",
+				DDLOp:        parser.AddPrimaryKey,
+				IndexColumns: columns,
+			},
+			Meta:    meta,
+			Version: v8_0_35,
+			Topo:    &topology.Info{Type: topology.Standalone},
+		}
+	}
+	duplicateWarning := func(t *testing.T, result *Result) string {
+		t.Helper()
+		for _, w := range result.Warnings {
+			if w.Code == WarnUniqueDuplicates {
+				return w.Message
+			}
+		}
+		t.Fatalf("expected a UNIQUE_DUPLICATES warning, got %v", result.WarningMessages())
+		return ""
+	}
+
+	tests := []struct {
+		name    string
+		columns []string
+		meta    *mysql.TableMetadata
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "auto_increment column",
+			columns: []string{"id"},
+			meta:    &mysql.TableMetadata{Columns: []mysql.ColumnInfo{{Name: "id", Type: "bigint", AutoIncrement: true}}},
+			want:    []string{"`id` is the AUTO_INCREMENT column, so the check is likely to pass", "GROUP BY id HAVING cnt > 1"},
+		},
+		{
+			name:    "covered by a unique index",
+			columns: []string{"tenant_id", "order_no"},
+			meta: &mysql.TableMetadata{
+				Columns: []mysql.ColumnInfo{{Name: "tenant_id", Type: "int"}, {Name: "order_no", Type: "int"}},
+				Indexes: []mysql.IndexInfo{{Name: "uk_order_no", Columns: []string{"order_no"}}},
+			},
+			want:    []string{"unique index `uk_order_no` on (order_no) already keeps them apart"},
+			notWant: []string{"Run the duplicate check"},
+		},
+		{
+			name:    "nullable unique index doesn't count",
+			columns: []string{"tenant_id", "order_no"},
+			meta: &mysql.TableMetadata{
+				Columns: []mysql.ColumnInfo{{Name: "tenant_id", Type: "int"}, {Name: "order_no", Type: "int", Nullable: true}},
+				Indexes: []mysql.IndexInfo{{Name: "uk_order_no", Columns: []string{"order_no"}}},
+			},
+			want: []string{"Run the duplicate check before this ALTER: no unique index covers (tenant_id, order_no)", "Nullable `order_no` makes it worse"},
+		},
+		{
+			name:    "composite without a unique index",
+			columns: []string{"tenant_id", "order_no"},
+			meta: &mysql.TableMetadata{
+				Columns: []mysql.ColumnInfo{{Name: "tenant_id", Type: "int"}, {Name: "order_no", Type: "int"}},
+				Indexes: []mysql.IndexInfo{{Name: "idx_order_no", Columns: []string{"order_no"}, NonUnique: true}},
+			},
+			want:    []string{"no unique index covers (tenant_id, order_no)", "GROUP BY tenant_id, order_no HAVING cnt > 1"},
+			notWant: []string{"makes it worse"},
+		},
+		{
+			name:    "plain single column",
+			columns: []string{"order_no"},
+			meta:    &mysql.TableMetadata{Columns: []mysql.ColumnInfo{{Name: "order_no", Type: "int"}}},
+			want:    []string{"This ALTER will fail if duplicates exist. Verify with:"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := duplicateWarning(t, Analyze(pkInput(tt.columns, tt.meta)))
+			for _, want := range tt.want {
+				if !strings.Contains(msg, want) {
+					t.Errorf("warning should contain %q, got:\n%s", want, msg)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(msg, notWant) {
+					t.Errorf("warning should not contain %q, got:\n%s", notWant, msg)
+				}
+			}
+		})
+	}
+}
+
+// =============================================================
+// ANALYZE TABLE and CHECK TABLE
+// =============================================================
+
+func TestMaintenanceStatements(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+	maintInput := func(op parser.DDLOperation, version mysql.ServerVersion, size int64) Input {
+		input := ddlInput(op, version, size, topology.Standalone)
+		input.Parsed.RawSQL = strings.ReplaceAll(string(op), "_", " ") + " test"
+		return input
+	}
+
+	tests := []struct {
+		name     string
+		op       parser.DDLOperation
+		version  mysql.ServerVersion
+		size     int64
+		wantRisk RiskLevel
+		wantWarn WarningCode
+	}{
+		{"analyze on a large table", parser.AnalyzeTable, v8_0_35, 100 * gb, RiskSafe, ""},
+		{"analyze before 8.0.24", parser.AnalyzeTable, v8_0_20, 10 * 1024 * 1024, RiskCaution, WarnAnalyzeTableFlush},
+		{"check on a small table", parser.CheckTable, v8_0_35, 100 * 1024 * 1024, RiskSafe, ""},
+		{"check on a large table", parser.CheckTable, v8_0_35, 100 * gb, RiskCaution, WarnCheckTableReadLock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Analyze(maintInput(tt.op, tt.version, tt.size))
+			if result.Risk != tt.wantRisk || result.Method != ExecDirect {
+				t.Errorf("Risk = %s, Method = %s, want %s and DIRECT", result.Risk, result.Method, tt.wantRisk)
+			}
+			if result.Classification.RebuildsTable || result.OptimizedDDL != "" || result.RollbackSQL != "" {
+				t.Errorf("expected no rebuild, optimized DDL or rollback SQL, got %+v", result.Classification)
+			}
+			var codes []WarningCode
+			for _, w := range result.Warnings {
+				codes = append(codes, w.Code)
+			}
+			if tt.wantWarn == "" && len(codes) > 0 || tt.wantWarn != "" && !slices.Equal(codes, []WarningCode{tt.wantWarn}) {
+				t.Errorf("warnings = %v, want %q", codes, tt.wantWarn)
+			}
+		})
+	}
+
+	if c := ClassifyDDL(parser.CheckTable, 8, 0, 35); c.Lock != LockShared {
+		t.Errorf("CHECK TABLE Lock = %s, want SHARED", c.Lock)
+	}
+}
+
+// =============================================================
+// Unsupported server versions
+// =============================================================
+
+func TestUnsupportedVersionWarning(t *testing.T) {
+	v5_7 := mysql.ServerVersion{Major: 5, Minor: 7, Patch: 44, Flavor: "mysql"}
+	v9_1 := mysql.ServerVersion{Major: 9, Minor: 1, Patch: 0, Flavor: "mysql"}
+
+	// 5.7: no INSTANT, so ADD COLUMN is classified INPLACE.
+	result := Analyze(ddlInput(parser.AddColumn, v5_7, 1024, topology.Standalone))
+	if result.Classification.Algorithm != AlgoInplace {
+		t.Errorf("5.7 ADD COLUMN algorithm = %s, want INPLACE", result.Classification.Algorithm)
+	}
+	if !result.HasWarning(WarnUnsupportedVersion) || !containsWarning(result.WarningMessages(), "5.7 has no ALGORITHM=INSTANT") {
+		t.Errorf("expected a 5.7 warning, got %v", result.WarningMessages())
+	}
+	if result.Risk == RiskSafe {
+		t.Errorf("risk = SAFE, want at least CAUTION on an unsupported version")
+	}
+
+	result = Analyze(ddlInput(parser.AddColumn, v9_1, 1024, topology.Standalone))
+	if result.Classification.Algorithm != AlgoInstant {
+		t.Errorf("9.1 ADD COLUMN algorithm = %s, want INSTANT", result.Classification.Algorithm)
+	}
+	if !containsWarning(result.WarningMessages(), "9.1.0 is a release dbsafe hasn't been validated against") {
+		t.Errorf("expected a 9.x warning, got %v", result.WarningMessages())
+	}
+
+	for _, v := range []mysql.ServerVersion{v8_0_35, {Major: 8, Minor: 4, Patch: 2, Flavor: "mysql"}} {
+		if result := Analyze(ddlInput(parser.AddColumn, v, 1024, topology.Standalone)); result.HasWarning(WarnUnsupportedVersion) {
+			t.Errorf("%s: unexpected warning %v", v, result.WarningMessages())
+		}
+	}
+}
+
+// =============================================================
+// No online schema change tools
+// =============================================================
+
+func TestNoOnlineTools(t *testing.T) {
+	for _, topo := range []topology.Type{topology.Standalone, topology.Galera, topology.AuroraWriter} {
+		input := ddlInput(parser.ModifyColumn, v8_0_35, 2*1024*1024*1024, topo) // 2GB, COPY
+		input.NoOnlineTools = true
+		result := Analyze(input)
+
+		if result.Method != ExecDirect || result.AlternativeMethod != "" {
+			t.Errorf("%s: method = %s (alternative %q), want DIRECT only", topo, result.Method, result.AlternativeMethod)
+		}
+		if result.Risk != RiskDangerous {
+			t.Errorf("%s: risk = %s, want DANGEROUS", topo, result.Risk)
+		}
+		if result.ExecutionCommand != "" {
+			t.Errorf("%s: unexpected command %q", topo, result.ExecutionCommand)
+		}
+		if !result.HasWarning(WarnMaintenanceWindowRequired) || !containsWarning(result.WarningMessages(), "est. ~1m for 2.0 GB") {
+			t.Errorf("%s: expected a maintenance window warning, got %v", topo, result.WarningMessages())
+		}
+		if !strings.Contains(result.Recommendation, "maintenance window") {
+			t.Errorf("%s: recommendation = %q", topo, result.Recommendation)
+		}
+	}
+
+	// Below the danger threshold the plan doesn't change.
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 200*1024*1024, topology.Standalone)
+	input.NoOnlineTools = true
+	result := Analyze(input)
+	if result.Method != ExecDirect || result.HasWarning(WarnMaintenanceWindowRequired) || result.HasWarning(WarnDirectCopyTradeoff) {
+		t.Errorf("small table: method %s, warnings %v", result.Method, result.WarningMessages())
+	}
+}
+
+// =============================================================
+// Row size of single-column changes
+// =============================================================
+
+// rowSizeInput is a single-op ALTER on a table of n VARCHAR(60) utf8mb4 columns (242 bytes
+// each, always in the row) with the given row format.
+func rowSizeInput(op parser.DDLOperation, n int, rowFormat string, subOp parser.SubOperation) Input {
+	input := ddlInput(op, v8_0_35, 100*1024*1024, topology.Standalone)
+	charset := "utf8mb4"
+	cols := []mysql.ColumnInfo{{Name: "existing_col", Type: "int", Position: 1}}
+	for i := 0; i < n; i++ {
+		cols = append(cols, mysql.ColumnInfo{Name: fmt.Sprintf("v%d", i), Type: "varchar(60)", CharacterSet: &charset, Position: i + 2})
+	}
+	input.Meta.Columns = cols
+	input.Meta.RowFormat = rowFormat
+	subOp.Op = op
+	input.Parsed.SubOperations = []parser.SubOperation{subOp}
+	input.Parsed.NewColumnType = subOp.NewColumnType
+	input.Parsed.NewColumnCharset = subOp.NewColumnCharset
+	return input
+}
+
+func TestSingleOp_RowSize(t *testing.T) {
+	// 33 × 242 = 7986 bytes in the row; one more VARCHAR(60) goes over 8126 on DYNAMIC.
+	add := parser.SubOperation{ColumnName: "new_col", NewColumnType: "varchar(60)", NewColumnCharset: "utf8mb4"}
+	result := Analyze(rowSizeInput(parser.AddColumn, 33, "Dynamic", add))
+	if result.Classification.Algorithm != AlgoInstant {
+		t.Errorf("algorithm = %s, want INSTANT", result.Classification.Algorithm)
+	}
+	if !result.HasWarning(WarnRecordSizeTooLarge) || !containsWarning(result.WarningMessages(), "ROW_FORMAT=DYNAMIC, over the 8126-byte InnoDB limit") {
+		t.Errorf("expected a DYNAMIC record size warning, got %v", result.WarningMessages())
+	}
+	if result.ColumnsBefore != 0 || result.ColumnsAfter != 0 {
+		t.Errorf("columns = %d → %d, want them left unset for a single op", result.ColumnsBefore, result.ColumnsAfter)
+	}
+
+	// A long VARCHAR can move off-page, so it only takes 40 bytes of the row.
+	add.NewColumnType = "varchar(500)"
+	if result := Analyze(rowSizeInput(parser.AddColumn, 33, "Dynamic", add)); result.HasWarning(WarnRecordSizeTooLarge) {
+		t.Errorf("off-page column: unexpected warning %v", result.WarningMessages())
+	}
+
+	// On COMPACT it keeps a 768-byte prefix: 10 × 242 + 768 fits, widening a column to
+	// VARCHAR(2000) on a table with ten long columns doesn't.
+	wide := parser.SubOperation{ColumnName: "existing_col", NewColumnType: "text"}
+	if result := Analyze(rowSizeInput(parser.ModifyColumn, 10, "Compact", wide)); result.HasWarning(WarnRecordSizeTooLarge) {
+		t.Errorf("COMPACT, fits: unexpected warning %v", result.WarningMessages())
+	}
+	input := rowSizeInput(parser.ModifyColumn, 0, "Compact", parser.SubOperation{ColumnName: "existing_col", NewColumnType: "varchar(2000)", NewColumnCharset: "utf8mb4"})
+	for i := 0; i < 10; i++ {
+		input.Meta.Columns = append(input.Meta.Columns, mysql.ColumnInfo{Name: fmt.Sprintf("t%d", i), Type: "text", Position: i + 2})
+	}
+	result = Analyze(input)
+	if !containsWarning(result.WarningMessages(), "ROW_FORMAT=COMPACT, over the 8126-byte InnoDB limit") {
+		t.Errorf("expected a COMPACT record size warning, got %v", result.WarningMessages())
+	}
+}
+
+// =============================================================
+// Indexes on VIRTUAL generated columns
+// =============================================================
+
+func TestAddIndex_VirtualGeneratedColumn(t *testing.T) {
+	withGenerated := func(input Input) Input {
+		input.Meta.Columns = append(input.Meta.Columns,
+			mysql.ColumnInfo{Name: "total", Type: "decimal(10,2)", GenerationExpr: "`price` * `qty`"},
+			mysql.ColumnInfo{Name: "total_stored", Type: "decimal(10,2)", GenerationExpr: "`price` * `qty`", IsStoredGenerated: true},
+		)
+		return input
+	}
+
+	input := withGenerated(ddlInput(parser.AddIndex, v8_0_35, 1024, topology.Standalone))
+	input.Parsed.IndexColumns = []string{"total"}
+	result := Analyze(input)
+	if !result.HasWarning(WarnVirtualColumnIndex) || !containsWarning(result.WarningMessages(), "`total` is a VIRTUAL generated column") {
+		t.Errorf("expected a virtual column index note, got %v", result.WarningMessages())
+	}
+	if result.Classification.Algorithm != AlgoInplace || result.Classification.Lock != LockNone {
+		t.Errorf("classification = %s/%s, want INPLACE/NONE", result.Classification.Algorithm, result.Classification.Lock)
+	}
+
+	for _, cols := range [][]string{{"total_stored"}, {"existing_col"}} {
+		input := withGenerated(ddlInput(parser.AddIndex, v8_0_35, 1024, topology.Standalone))
+		input.Parsed.IndexColumns = cols
+		if result := Analyze(input); result.HasWarning(WarnVirtualColumnIndex) {
+			t.Errorf("%v: unexpected note %v", cols, result.WarningMessages())
+		}
+	}
+
+	// Multi-op: the ADD INDEX sub-operation gets the note.
+	input = withGenerated(ddlInput(parser.MultipleOps, v8_0_35, 1024, topology.Standalone))
+	input.Parsed.SubOperations = []parser.SubOperation{
+		{Op: parser.AddColumn, ColumnName: "note", NewColumnType: "int"},
+		{Op: parser.AddIndex, IndexName: "idx_total", IndexColumns: []string{"total"}},
+	}
+	if result := Analyze(input); !result.HasWarning(WarnVirtualColumnIndex) {
+		t.Errorf("multi-op: expected a virtual column index note, got %v", result.WarningMessages())
+	}
+}
+
+// =============================================================
+// ALTER on a column of the partitioning expression
+// =============================================================
+
+func TestPartitionExpressionColumnChange(t *testing.T) {
+	partitioned := func(sql string) Input {
+		parsed, err := parser.Parse(sql)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", sql, err)
+		}
+		input := ddlInput(parsed.DDLOp, v8_0_35, 1024, topology.Standalone)
+		input.Parsed = parsed
+		input.Meta.Columns = []mysql.ColumnInfo{
+			{Name: "id", Type: "int", Position: 1},
+			{Name: "created_at", Type: "datetime", Position: 2},
+			{Name: "note", Type: "varchar(100)", Position: 3},
+		}
+		input.Meta.Partitioning = &mysql.PartitionInfo{Method: "RANGE", Expression: "to_days(`created_at`)"}
+		return input
+	}
+
+	tests := []struct {
+		sql  string
+		code WarningCode
+		want string
+	}{
+		{"ALTER TABLE test DROP COLUMN created_at", WarnPartitionColumnDependency, "MySQL rejects dropping it"},
+		{"ALTER TABLE test CHANGE COLUMN created_at created datetime", WarnPartitionColumnDependency, "MySQL rejects renaming it to `created`"},
+		{"ALTER TABLE test MODIFY COLUMN created_at date", WarnPartitionColumnTypeChange, "changing its type from datetime to date"},
+		{"ALTER TABLE test ADD COLUMN x int, DROP COLUMN created_at", WarnPartitionColumnDependency, "MySQL rejects dropping it"},
+	}
+	for _, tt := range tests {
+		result := Analyze(partitioned(tt.sql))
+		if !result.HasWarning(tt.code) || !containsWarning(result.WarningMessages(), tt.want) {
+			t.Errorf("%s: expected %s containing %q, got %v", tt.sql, tt.code, tt.want, result.WarningMessages())
+		}
+		if result.Risk != RiskDangerous {
+			t.Errorf("%s: risk = %s, want DANGEROUS", tt.sql, result.Risk)
+		}
+	}
+
+	for _, sql := range []string{
+		"ALTER TABLE test DROP COLUMN note",
+		"ALTER TABLE test MODIFY COLUMN created_at datetime NOT NULL",
+	} {
+		result := Analyze(partitioned(sql))
+		if result.HasWarning(WarnPartitionColumnDependency) || result.HasWarning(WarnPartitionColumnTypeChange) {
+			t.Errorf("%s: unexpected partition warning %v", sql, result.WarningMessages())
+		}
+	}
+}
+
+// =============================================================
+// Upgrade benefit
+// =============================================================
+
+func TestUpgradeBenefit(t *testing.T) {
+	after := ddlInput(parser.AddColumn, v8_0_20, 1024, topology.Standalone)
+	after.Parsed.IsFirstAfter = true
+	result := Analyze(after)
+	if !result.HasWarning(WarnUpgradeBenefit) || !containsWarning(result.WarningMessages(), "INPLACE on your version (8.0.20) but would be INSTANT on 8.0.29+") {
+		t.Errorf("ADD COLUMN AFTER on 8.0.20: expected an upgrade note, got %v", result.WarningMessages())
+	}
+
+	result = Analyze(ddlInput(parser.DropColumn, v8_0_5, 1024, topology.Standalone))
+	if !containsWarning(result.WarningMessages(), "INPLACE on your version (8.0.5) but would be INSTANT on 8.0.29+") {
+		t.Errorf("DROP COLUMN on 8.0.5: expected an upgrade note, got %v", result.WarningMessages())
+	}
+
+	for name, input := range map[string]Input{
+		"DROP COLUMN on 8.0.35":        ddlInput(parser.DropColumn, v8_0_35, 1024, topology.Standalone),
+		"ADD INDEX on 8.0.20":          ddlInput(parser.AddIndex, v8_0_20, 1024, topology.Standalone),
+		"trailing ADD COLUMN on 8.0.20": ddlInput(parser.AddColumn, v8_0_20, 1024, topology.Standalone),
+	} {
+		if result := Analyze(input); result.HasWarning(WarnUpgradeBenefit) {
+			t.Errorf("%s: unexpected upgrade note %v", name, result.WarningMessages())
+		}
+	}
+}
+
+// =============================================================
+// Optimized DDL: the most specific guaranteeing ALGORITHM
+// =============================================================
+
+func TestOptimizedDDL_GuaranteeingAlgorithm(t *testing.T) {
+	mariadb := mysql.ServerVersion{Major: 10, Minor: 11, Patch: 6, Flavor: "mariadb"}
+	tests := []struct {
+		name    string
+		op      parser.DDLOperation
+		version mysql.ServerVersion
+		sql     string
+		want    string
+	}{
+		{"MySQL INPLACE without rebuild", parser.AddIndex, v8_0_35, "ALTER TABLE test ADD INDEX idx (existing_col)", "ALTER TABLE test ADD INDEX idx (existing_col), ALGORITHM=INPLACE, LOCK=NONE;"},
+		{"MariaDB INPLACE without rebuild", parser.AddIndex, mariadb, "ALTER TABLE test ADD INDEX idx (existing_col)", "ALTER TABLE test ADD INDEX idx (existing_col), ALGORITHM=NOCOPY, LOCK=NONE;"},
+		{"MariaDB NOCOPY hint replaced", parser.AddIndex, mariadb, "ALTER TABLE test ADD INDEX idx (existing_col), ALGORITHM=NOCOPY", "ALTER TABLE test ADD INDEX idx (existing_col), ALGORITHM=NOCOPY, LOCK=NONE;"},
+		{"INSTANT", parser.DropColumn, v8_0_35, "ALTER TABLE test DROP COLUMN existing_col", "ALTER TABLE test DROP COLUMN existing_col, ALGORITHM=INSTANT, LOCK=NONE;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := ddlInput(tt.op, tt.version, 1024, topology.Standalone)
+			input.Parsed.RawSQL = tt.sql
+			input.Parsed.IndexColumns = []string{"existing_col"}
+			if result := Analyze(input); result.OptimizedDDL != tt.want {
+				t.Errorf("OptimizedDDL = %q, want %q", result.OptimizedDDL, tt.want)
+			}
+		})
+	}
+
+	// A rebuild can't be NOCOPY, even on MariaDB.
+	c := DDLClassification{Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: true}
+	if got := guaranteeingAlgorithm(c, mariadb); got != "INPLACE" {
+		t.Errorf("guaranteeingAlgorithm(INPLACE rebuild, MariaDB) = %q, want INPLACE", got)
+	}
+}
+
+// =============================================================
+// Foreign keys that reject a DELETE
+// =============================================================
+
+func TestFKRestrictChildren(t *testing.T) {
+	withChild := func(deleteRule string) Input {
+		input := dmlInput(parser.Delete, true, 1_000_000, 100, 1000, topology.Standalone)
+		input.EstimatedRows = 500
+		input.Parsed.WhereClause = "created_at < '2020-01-01'"
+		input.Meta.InboundForeignKeys = []mysql.ForeignKeyInfo{{
+			Name: "fk_items_test", Columns: []string{"test_id"}, ReferencedCols: []string{"id"},
+			ChildSchema: "testdb", ChildTable: "items", DeleteRule: deleteRule,
+		}}
+		return input
+	}
+
+	for _, rule := range []string{"RESTRICT", "NO ACTION", ""} {
+		result := Analyze(withChild(rule))
+		msgs := result.WarningMessages()
+		if !result.HasWarning(WarnFKRestrictChildren) || !containsWarning(msgs, "error 1451") ||
+			!containsWarning(msgs, "DELETE FROM `testdb`.`items` WHERE `test_id` IN (SELECT `id` FROM `testdb`.`test` WHERE created_at < '2020-01-01');") {
+			t.Errorf("rule %q: expected a warning with the child DELETE, got %v", rule, msgs)
+		}
+	}
+
+	if result := Analyze(withChild("CASCADE")); result.HasWarning(WarnFKRestrictChildren) {
+		t.Errorf("ON DELETE CASCADE doesn't reject the DELETE, got %v", result.WarningMessages())
+	}
+
+	input := withChild("CASCADE")
+	input.ForeignKeyChecksDisabled = true
+	if result := Analyze(input); !containsWarning(result.WarningMessages(), "orphaned") {
+		t.Errorf("with foreign_key_checks OFF the child rows are orphaned, got %v", result.WarningMessages())
+	}
+
+	input = withChild("RESTRICT")
+	input.Parsed.DMLOp = parser.Update
+	if result := Analyze(input); result.HasWarning(WarnFKRestrictChildren) {
+		t.Errorf("an UPDATE isn't a DELETE, got %v", result.WarningMessages())
+	}
+}
+
+// =============================================================
+// Redo and undo pressure of large direct rebuilds
+// =============================================================
+
+func TestRedoUndoPressure(t *testing.T) {
+	const gb = int64(1024 * 1024 * 1024)
+	rebuild := func(size int64) Input {
+		input := ddlInput(parser.ForceRebuild, v8_0_35, size, topology.Standalone)
+		input.ServerConfig = mysql.ServerConfig{RedoLogCapacity: 2 * gb}
+		return input
+	}
+
+	result := Analyze(rebuild(5 * gb))
+	msgs := result.WarningMessages()
+	if !result.HasWarning(WarnRedoUndoPressure) || !containsWarning(msgs, "2x the redo log capacity (2.0 GB)") ||
+		!containsWarning(msgs, "raise innodb_redo_log_capacity") {
+		t.Errorf("expected a redo pressure warning, got %s: %v", result.Method, msgs)
+	}
+
+	input := rebuild(30 * gb)
+	input.ServerConfig.UndoLogTruncateOff = true
+	msgs = Analyze(input).WarningMessages()
+	if !containsWarning(msgs, "Use gh-ost or pt-online-schema-change instead") || !containsWarning(msgs, "innodb_undo_log_truncate OFF") {
+		t.Errorf("expected the online tool recommendation and the undo truncate note, got %v", msgs)
+	}
+
+	input = rebuild(5 * gb)
+	input.ServerConfig = mysql.ServerConfig{}
+	input.Version = v8_0_20
+	input.NoOnlineTools = true
+	if msgs := Analyze(input).WarningMessages(); !containsWarning(msgs, "(96.0 MB by default; the server value could not be read)") ||
+		!containsWarning(msgs, "innodb_log_file_size only changes with a restart") {
+		t.Errorf("expected the pre-8.0.30 default capacity, got %v", msgs)
+	}
+
+	for name, in := range map[string]Input{
+		"redo log larger than the table": rebuild(1536 * 1024 * 1024),
+		"no rebuild":                     ddlInput(parser.AddIndex, v8_0_35, 5*gb, topology.Standalone),
+	} {
+		if result := Analyze(in); result.HasWarning(WarnRedoUndoPressure) {
+			t.Errorf("%s: unexpected redo pressure warning: %v", name, result.WarningMessages())
+		}
+	}
+}
+
+// =============================================================
+// MariaDB WAIT n / NOWAIT
+// =============================================================
+
+func TestLockWaitModifier(t *testing.T) {
+	mariadb := mysql.ServerVersion{Major: 10, Minor: 11, Patch: 6, Flavor: "mariadb"}
+	holders := []mysql.MetadataLockHolder{{ProcessID: 41, LockType: "SHARED_READ", LockStatus: "GRANTED", TrxSeconds: 312}}
+	withSQL := func(sql string, v mysql.ServerVersion) Input {
+		parsed, err := parser.Parse(sql)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", sql, err)
+		}
+		input := ddlInput(parsed.DDLOp, v, 10*1024*1024, topology.Standalone)
+		input.Parsed = parsed
+		return input
+	}
+
+	input := withSQL("ALTER TABLE test NOWAIT ADD INDEX idx (existing_col)", mariadb)
+	input.MetadataLockHolders = holders
+	result := Analyze(input)
+	if !result.HasWarning(WarnLockWaitModifier) || result.Risk != RiskCaution ||
+		!containsWarning(result.WarningMessages(), "With NOWAIT this statement fails at once") {
+		t.Errorf("NOWAIT: expected CAUTION with the NOWAIT note, got %s: %v", result.Risk, result.WarningMessages())
+	}
+
+	input = withSQL("ALTER TABLE test WAIT 30 ADD INDEX idx (existing_col)", mariadb)
+	input.MetadataLockHolders = holders
+	result = Analyze(input)
+	if result.Risk != RiskDangerous || !containsWarning(result.WarningMessages(), "With WAIT 30 this statement blocks on the metadata lock for up to 30 seconds") {
+		t.Errorf("WAIT 30: expected DANGEROUS with the bounded wait, got %s: %v", result.Risk, result.WarningMessages())
+	}
+
+	input = withSQL("ALTER TABLE test ADD INDEX idx (existing_col)", mariadb)
+	input.MetadataLockHolders = holders
+	if result := Analyze(input); !containsWarning(result.WarningMessages(), "add NOWAIT (ALTER TABLE test NOWAIT ...)") {
+		t.Errorf("expected a NOWAIT suggestion on MariaDB, got %v", result.WarningMessages())
+	}
+
+	result = Analyze(withSQL("ALTER TABLE test NOWAIT ADD INDEX idx (existing_col)", v8_0_35))
+	if !result.HasWarning(WarnLockWaitSyntax) || result.HasWarning(WarnLockWaitModifier) {
+		t.Errorf("MySQL: expected the syntax warning only, got %v", result.WarningMessages())
+	}
+	for _, sql := range []string{"ALTER TABLE test NOWAIT ADD INDEX idx (existing_col)", "ALTER TABLE test WAIT 30 ADD INDEX idx (existing_col)"} {
+		if spec := extractAlterSpec(sql); spec != "ADD INDEX idx (existing_col)" {
+			t.Errorf("extractAlterSpec(%q) = %q, want the clause dropped", sql, spec)
+		}
+	}
+}
+
+// =============================================================
+// Sampled write rate
+// =============================================================
+
+func TestWriteRate(t *testing.T) {
+	const mb = int64(1024 * 1024)
+	withRate := func(size int64, rate float64) Input {
+		input := ddlInput(parser.AddFulltextIndex, v8_0_35, size, topology.Standalone)
+		input.Meta.WriteRate = &rate
+		return input
+	}
+
+	// Below the size threshold, a hot table still goes to an online tool.
+	result := Analyze(withRate(500*mb, 800))
+	if result.Method != ExecGhost || result.AlternativeMethod != ExecPtOSC || !strings.Contains(result.Recommendation, "~800 rows/s") {
+		t.Errorf("hot table: expected gh-ost with the rate, got %s: %q", result.Method, result.Recommendation)
+	}
+	input := withRate(500*mb, 800)
+	input.Topo.Type = topology.Galera
+	if result := Analyze(input); result.Method != ExecPtOSC {
+		t.Errorf("hot table on Galera: expected pt-osc, got %s", result.Method)
+	}
+	input = withRate(500*mb, 800)
+	input.NoOnlineTools = true
+	if result := Analyze(input); result.Method != ExecDirect {
+		t.Errorf("hot table without online tools: expected direct, got %s", result.Method)
+	}
+
+	// Above it, a cold table takes the SHARED lock directly.
+	result = Analyze(withRate(5*1024*mb, 0.2))
+	if result.Method != ExecDirect || result.Risk != RiskCaution || !strings.Contains(result.Recommendation, "replicas still apply it serially") {
+		t.Errorf("cold table: expected direct CAUTION, got %s %s: %q", result.Method, result.Risk, result.Recommendation)
+	}
+
+	// Neither a moderate rate nor an unsampled one changes the method.
+	if result := Analyze(withRate(5*1024*mb, 20)); result.Method != ExecGhost {
+		t.Errorf("moderate rate: expected gh-ost, got %s", result.Method)
+	}
+	if result := Analyze(ddlInput(parser.AddFulltextIndex, v8_0_35, 500*mb, topology.Standalone)); result.Method != ExecDirect {
+		t.Errorf("unsampled: expected direct, got %s", result.Method)
+	}
+}
+
+// =============================================================
+// ALTERs whose outcome depends on sql_mode
+// =============================================================
+
+func TestSQLModeWarnings(t *testing.T) {
+	zeroDate := "0000-00-00"
+	alter := func(t *testing.T, sql, mode string) Input {
+		t.Helper()
+		parsed, err := parser.Parse(sql)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		input := ddlInput(parsed.DDLOp, v8_0_35, 0, topology.Standalone)
+		input.Parsed = parsed
+		input.Meta.Columns = []mysql.ColumnInfo{
+			{Name: "id", Type: "int", Nullable: true, Position: 1},
+			{Name: "shipped", Type: "varchar(20)", Nullable: true, Position: 2},
+			{Name: "created", Type: "datetime", Default: &zeroDate, Position: 3},
+		}
+		input.ServerConfig.SQLMode = &mode
+		return input
+	}
+	const strictMode = "ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ENGINE_SUBSTITUTION"
+
+	msgs := Analyze(alter(t, "ALTER TABLE test ADD PRIMARY KEY (id)", strictMode)).WarningMessages()
+	if !containsWarning(msgs, "if `id` holds NULLs, the ALTER fails with error 1138") {
+		t.Errorf("strict NOT NULL: got %v", msgs)
+	}
+	msgs = Analyze(alter(t, "ALTER TABLE test MODIFY shipped VARCHAR(20) NOT NULL", "NO_ENGINE_SUBSTITUTION")).WarningMessages()
+	if !containsWarning(msgs, "(not strict): NULLs in `shipped` are silently replaced") {
+		t.Errorf("non-strict NOT NULL: got %v", msgs)
+	}
+
+	msgs = Analyze(alter(t, "ALTER TABLE test MODIFY shipped DATE", strictMode)).WarningMessages()
+	if !containsWarning(msgs, "converting `shipped` to DATE keeps zero dates") {
+		t.Errorf("strict DATE conversion: got %v", msgs)
+	}
+	msgs = Analyze(alter(t, "ALTER TABLE test MODIFY shipped DATE", "")).WarningMessages()
+	if !containsWarning(msgs, "stores a value that isn't a valid date as the zero date") {
+		t.Errorf("non-strict DATE conversion: got %v", msgs)
+	}
+
+	result := Analyze(alter(t, "ALTER TABLE test ADD COLUMN note TEXT", strictMode+",NO_ZERO_DATE"))
+	if !containsWarning(result.WarningMessages(), "`created` default to the zero date") || result.Risk != RiskDangerous {
+		t.Errorf("zero-date default: got %s %v", result.Risk, result.WarningMessages())
+	}
+
+	// Without a mode (offline), or for an ALTER that depends on none, there is nothing to say.
+	input := alter(t, "ALTER TABLE test ADD PRIMARY KEY (id)", strictMode)
+	input.ServerConfig.SQLMode = nil
+	if result := Analyze(input); result.HasWarning(WarnSQLModeDependent) {
+		t.Errorf("no sql_mode: got %v", result.WarningMessages())
+	}
+	if result := Analyze(alter(t, "ALTER TABLE test ADD COLUMN note TEXT", strictMode)); result.HasWarning(WarnSQLModeDependent) {
+		t.Errorf("unaffected ALTER: got %v", result.WarningMessages())
+	}
+}
+
+// =============================================================
+// ADD COLUMN ... SERIAL and inline UNIQUE keys
+// =============================================================
+
+func TestAddColumnSerial(t *testing.T) {
+	add := func(t *testing.T, sql string) Input {
+		t.Helper()
+		parsed, err := parser.Parse(sql)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		input := ddlInput(parsed.DDLOp, v8_0_35, 100*1024*1024, topology.Standalone)
+		input.Parsed = parsed
+		return input
+	}
+
+	result := Analyze(add(t, "ALTER TABLE test ADD COLUMN seq SERIAL"))
+	cls := result.Classification
+	if cls.Algorithm != AlgoInplace || cls.Lock != LockShared || !cls.RebuildsTable {
+		t.Errorf("SERIAL: got %s/%s rebuild=%v, want INPLACE/SHARED with rebuild", cls.Algorithm, cls.Lock, cls.RebuildsTable)
+	}
+	if !containsWarning(result.WarningMessages(), "Its UNIQUE key (SERIAL declares one)") {
+		t.Errorf("SERIAL: expected the UNIQUE key note, got %v", result.WarningMessages())
+	}
+
+	input := add(t, "ALTER TABLE test ADD COLUMN seq INT SERIAL DEFAULT VALUE")
+	input.Meta.Columns[0].AutoIncrement = true
+	result = Analyze(input)
+	if !result.HasWarning(WarnSecondAutoIncrement) || result.Risk != RiskDangerous {
+		t.Errorf("second AUTO_INCREMENT: got %s %v", result.Risk, result.WarningMessages())
+	}
+
+	input = add(t, "ALTER TABLE test ADD COLUMN note TEXT, ADD COLUMN seq SERIAL")
+	input.Meta.Columns[0].AutoIncrement = true
+	if result := Analyze(input); !result.HasWarning(WarnSecondAutoIncrement) {
+		t.Errorf("second AUTO_INCREMENT in a compound ALTER: got %v", result.WarningMessages())
+	}
+
+	// Without AUTO_INCREMENT, an inline UNIQUE key still needs an index build, and a
+	// shared default collides.
+	result = Analyze(add(t, "ALTER TABLE test ADD COLUMN code INT NOT NULL DEFAULT 0 UNIQUE"))
+	if result.Classification.Algorithm != AlgoInplace || !result.HasWarning(WarnUniqueDuplicates) {
+		t.Errorf("inline UNIQUE: got %s %v", result.Classification.Algorithm, result.WarningMessages())
+	}
+	if result := Analyze(add(t, "ALTER TABLE test ADD COLUMN code INT UNIQUE")); result.HasWarning(WarnUniqueDuplicates) {
+		t.Errorf("nullable inline UNIQUE: all NULL can't collide, got %v", result.WarningMessages())
+	}
+}
+
+// =============================================================
+// Columns used by CHECK constraints
+// =============================================================
+
+func TestCheckConstraintColumn(t *testing.T) {
+	def, err := parser.ParseTableDefinition("CREATE TABLE shop.items (id INT NOT NULL PRIMARY KEY, price DECIMAL(10,2) NOT NULL, qty INT, " +
+		"CONSTRAINT chk_price CHECK (price > 0)) ENGINE=InnoDB")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	meta := MetadataFromDefinition(def, def.CreateSQL)
+	analyze := func(t *testing.T, sql string) *Result {
+		t.Helper()
+		parsed, err := parser.Parse(sql)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		result, err := AnalyzeOffline(parsed, meta, Options{Version: &v8_0_35})
+		if err != nil {
+			t.Fatalf("analyze: %v", err)
+		}
+		return result
+	}
+
+	for _, sql := range []string{
+		"ALTER TABLE shop.items DROP COLUMN price",
+		"ALTER TABLE shop.items CHANGE price unit_price DECIMAL(10,2) NOT NULL",
+	} {
+		result := analyze(t, sql)
+		if !result.HasWarning(WarnCheckConstraintColumn) || result.Risk != RiskDangerous ||
+			!containsWarning(result.WarningMessages(), "DROP CHECK `chk_price`") {
+			t.Errorf("%s: got %s %v", sql, result.Risk, result.WarningMessages())
+		}
+	}
+
+	// Dropping the constraint in the same ALTER lets the column go.
+	if result := analyze(t, "ALTER TABLE shop.items DROP CHECK chk_price, DROP COLUMN price"); result.HasWarning(WarnCheckConstraintColumn) {
+		t.Errorf("constraint dropped too: got %v", result.WarningMessages())
+	}
+
+	// A type change re-checks the rows; the same type, or another column, doesn't.
+	if result := analyze(t, "ALTER TABLE shop.items MODIFY price DECIMAL(8,0) NOT NULL"); !containsWarning(result.WarningMessages(), "check constraint `chk_price`") ||
+		result.HasWarning(WarnCheckConstraintColumn) {
+		t.Errorf("type change: got %v", result.WarningMessages())
+	}
+	for _, sql := range []string{
+		"ALTER TABLE shop.items MODIFY price DECIMAL(10,2) NOT NULL DEFAULT 1",
+		"ALTER TABLE shop.items DROP COLUMN qty",
+	} {
+		if result := analyze(t, sql); containsWarning(result.WarningMessages(), "chk_price") {
+			t.Errorf("%s: got %v", sql, result.WarningMessages())
+		}
+	}
+}
+
+// =============================================================
+// pt-osc foreign key handling
+// =============================================================
+
+func TestPtOSCForeignKeys(t *testing.T) {
+	fkInput := func(childRows int64) Input {
+		input := ddlInput(parser.ModifyColumn, v8_0_35, 5*1024*1024*1024, topology.Standalone)
+		input.Parsed.NewColumnType = "TEXT"
+		input.Meta.InboundForeignKeys = []mysql.ForeignKeyInfo{
+			{Name: "fk_items_test", ChildSchema: "testdb", ChildTable: "items", ChildRows: childRows},
+		}
+		return input
+	}
+
+	result := Analyze(fkInput(2_000_000))
+	if result.Method != ExecPtOSC || !result.HasWarning(WarnPtOSCForeignKeys) {
+		t.Fatalf("expected pt-osc with a PTOSC_FOREIGN_KEYS warning, got %s: %v", result.Method, result.WarningMessages())
+	}
+	for _, want := range []string{"drop_swap", "`testdb`.`items` (2.0M rows)", "per chunk (~1000)", "rebuild_constraints", "fk_items_test → _fk_items_test"} {
+		if !containsWarning(result.WarningMessages(), want) {
+			t.Errorf("large child: warning missing %q: %v", want, result.WarningMessages())
+		}
+	}
+
+	if containsWarning(result.WarningMessages(), "need the new names") {
+		t.Errorf("large child: drop_swap keeps the names, got %v", result.WarningMessages())
+	}
+
+	result = Analyze(fkInput(500))
+	if !containsWarning(result.WarningMessages(), "will likely pick rebuild_constraints") || containsWarning(result.WarningMessages(), "drop_swap") ||
+		!containsWarning(result.WarningMessages(), "need the new names") {
+		t.Errorf("small child: expected rebuild_constraints, got %v", result.WarningMessages())
+	}
+
+	input := fkInput(0)
+	input.Meta.InboundForeignKeys = nil
+	input.Meta.ForeignKeys = []mysql.ForeignKeyInfo{{Name: "__fk_test_owner", Columns: []string{"id"}, ReferencedTable: "owners"}}
+	result = Analyze(input)
+	if !containsWarning(result.WarningMessages(), "__fk_test_owner → fk_test_owner") || containsWarning(result.WarningMessages(), "alter-foreign-keys-method") {
+		t.Errorf("outbound only: expected the renamed constraint, got %v", result.WarningMessages())
+	}
+
+	input = ddlInput(parser.ModifyColumn, v8_0_35, 5*1024*1024*1024, topology.Standalone)
+	input.Parsed.NewColumnType = "TEXT"
+	if result := Analyze(input); result.HasWarning(WarnPtOSCForeignKeys) {
+		t.Errorf("no foreign keys: unexpected warning %v", result.WarningMessages())
+	}
+}
+
+// =============================================================
+// ADD COLUMN of a large object type
+// =============================================================
+
+func TestAddColumnLOBDeferredCost(t *testing.T) {
+	analyze := func(sql string) *Result {
+		t.Helper()
+		parsed, err := parser.Parse(sql)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", sql, err)
+		}
+		input := ddlInput(parsed.DDLOp, v8_0_35, 5*1024*1024*1024, topology.Standalone)
+		input.Parsed = parsed
+		return Analyze(input)
+	}
+
+	for _, sql := range []string{
+		"ALTER TABLE test ADD COLUMN body LONGTEXT",
+		"ALTER TABLE test ADD COLUMN doc JSON, ADD COLUMN n INT",
+	} {
+		result := analyze(sql)
+		if result.Classification.Algorithm != AlgoInstant || !result.HasWarning(WarnLOBBackfillDeferred) {
+			t.Errorf("%s: expected INSTANT with LOB_BACKFILL_DEFERRED, got %s: %v", sql, result.Classification.Algorithm, result.WarningMessages())
+		}
+	}
+
+	result := analyze("ALTER TABLE test ADD COLUMN note TEXT DEFAULT ('none')")
+	if !containsWarning(result.WarningMessages(), "deferred, not avoided") || !containsWarning(result.WarningMessages(), "Its default is kept in the table definition") {
+		t.Errorf("TEXT with a default: got %v", result.WarningMessages())
+	}
+
+	for _, sql := range []string{
+		"ALTER TABLE test ADD COLUMN code VARCHAR(100)",
+		"ALTER TABLE test ADD COLUMN tag TINYTEXT",
+	} {
+		if result := analyze(sql); result.HasWarning(WarnLOBBackfillDeferred) {
+			t.Errorf("%s: unexpected LOB note %v", sql, result.WarningMessages())
+		}
+	}
+}
+
+// =============================================================
+// Warning severity filter
+// =============================================================
+
+func TestFilterWarnings(t *testing.T) {
+	result := func() *Result {
+		r := &Result{ClusterWarnings: []Warning{
+			newWarning(WarnPtOSCCutover, "cut-over"),
+			newWarning(WarnGaleraTOI, "TOI"),
+			newWarning(WarnAuroraReader, "reader"),
+		}}
+		r.addWarning(WarnSchemaProjection, "info")
+		r.addWarning(WarnBinlogVolume, "warning")
+		r.addWarning(WarnKeyTooLong, "critical")
+		return r
+	}
+	for min, want := range map[Severity][2][]string{
+		SeverityInfo:     {{"info", "warning", "critical"}, {"cut-over", "TOI", "reader"}},
+		SeverityWarning:  {{"warning", "critical"}, {"TOI", "reader"}},
+		SeverityCritical: {{"critical"}, {"reader"}},
+	} {
+		r := result()
+		r.FilterWarnings(min)
+		if got := r.WarningMessages(); strings.Join(got, ",") != strings.Join(want[0], ",") {
+			t.Errorf("%s: warnings %v, want %v", min, got, want[0])
+		}
+		if got := r.ClusterWarningMessages(); strings.Join(got, ",") != strings.Join(want[1], ",") {
+			t.Errorf("%s: cluster warnings %v, want %v", min, got, want[1])
+		}
+	}
+
+	step := result()
+	plan := &DiffPlan{Steps: []DiffStep{{Result: step}}, Warnings: []Warning{newWarning(WarnMergeableAlters, "merge")}}
+	plan.FilterWarnings(SeverityWarning)
+	if len(plan.Warnings) != 0 || len(step.Warnings) != 2 {
+		t.Errorf("diff plan: %v, step %v", plan.Warnings, step.WarningMessages())
+	}
+}
+
+// =============================================================
+// Triggers writing elsewhere during pt-osc
+// =============================================================
+
+func TestTriggerDoubleWrite(t *testing.T) {
+	triggerInput := func(triggers ...mysql.TriggerInfo) Input {
+		input := ddlInput(parser.ModifyColumn, v8_0_35, 5*1024*1024*1024, topology.Standalone)
+		input.Parsed.NewColumnType = "TEXT"
+		input.Meta.Triggers = triggers
+		return input
+	}
+
+	result := Analyze(triggerInput(
+		mysql.TriggerInfo{Name: "trg_audit", Event: "UPDATE", Timing: "AFTER",
+			Statement: "BEGIN INSERT INTO audit.`test_log` (id, changed_at) VALUES (NEW.id, NOW()); UPDATE stats SET updates = updates + 1; END"},
+		mysql.TriggerInfo{Name: "trg_norm", Event: "INSERT", Timing: "BEFORE", Statement: "SET NEW.existing_col = LOWER(NEW.existing_col)"},
+		mysql.TriggerInfo{Name: "trg_del", Event: "DELETE", Timing: "AFTER", Statement: "CALL archive_row(OLD.id)"},
+	))
+	if result.Method != ExecPtOSC || !result.HasWarning(WarnTriggerDoubleWrite) {
+		t.Fatalf("expected pt-osc with TRIGGER_DOUBLE_WRITE, got %s: %v", result.Method, result.WarningMessages())
+	}
+	for _, want := range []string{"trg_audit (AFTER UPDATE) writes to `audit.test_log`, `stats`", "trg_del (AFTER DELETE) writes to procedure `archive_row`"} {
+		if !containsWarning(result.WarningMessages(), want) {
+			t.Errorf("missing %q: %v", want, result.WarningMessages())
+		}
+	}
+	if containsWarning(result.WarningMessages(), "trg_norm (") {
+		t.Errorf("a trigger that only sets NEW columns doesn't write elsewhere: %v", result.WarningMessages())
+	}
+
+	upsert := mysql.TriggerInfo{Name: "trg_count", Event: "INSERT", Timing: "AFTER",
+		Statement: "INSERT INTO counters (id, n) VALUES (1, 1) ON DUPLICATE KEY UPDATE n = n + 1"}
+	if result := Analyze(triggerInput(upsert)); !containsWarning(result.WarningMessages(), "writes to `counters`.") {
+		t.Errorf("ON DUPLICATE KEY UPDATE isn't an UPDATE of another table: %v", result.WarningMessages())
+	}
+
+	small := triggerInput(mysql.TriggerInfo{Name: "trg_audit", Event: "INSERT", Timing: "AFTER", Statement: "INSERT INTO log VALUES (NEW.id)"})
+	small.Meta.DataLength, small.Meta.IndexLength = 1024, 0
+	if result := Analyze(small); result.Method == ExecPtOSC || result.HasWarning(WarnTriggerDoubleWrite) {
+		t.Errorf("no pt-osc, no warning: got %s %v", result.Method, result.WarningMessages())
+	}
+}
+
+// =============================================================
+// DROP COLUMN of the last (stored) column
+// =============================================================
+
+func TestDropLastColumn(t *testing.T) {
+	dropInput := func(sql string, columns ...mysql.ColumnInfo) Input {
+		t.Helper()
+		parsed, err := parser.Parse(sql)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		input := ddlInput(parsed.DDLOp, v8_0_35, 1024*1024, topology.Standalone)
+		input.Parsed = parsed
+		input.Meta.Columns = columns
+		return input
+	}
+	id := mysql.ColumnInfo{Name: "id", Type: "int", Position: 1}
+	virtual := mysql.ColumnInfo{Name: "id_plus", Type: "int", Position: 2, GenerationExpr: "`id` + 1"}
+	stored := mysql.ColumnInfo{Name: "id_twice", Type: "int", Position: 2, GenerationExpr: "`id` * 2", IsStoredGenerated: true}
+
+	result := Analyze(dropInput("ALTER TABLE test DROP COLUMN id", id))
+	if !result.HasWarning(WarnDropLastColumn) || result.Risk != RiskDangerous ||
+		!containsWarning(result.WarningMessages(), "`id` is the table's only column") {
+		t.Errorf("expected DROP_LAST_COLUMN and DANGEROUS, got %s: %v", result.Risk, result.WarningMessages())
+	}
+
+	result = Analyze(dropInput("ALTER TABLE test DROP COLUMN id", id, virtual))
+	if !result.HasWarning(WarnNoStoredColumns) || result.Risk != RiskDangerous ||
+		!containsWarning(result.WarningMessages(), "leaves only virtual generated columns (`id_plus`)") {
+		t.Errorf("expected NO_STORED_COLUMNS and DANGEROUS, got %s: %v", result.Risk, result.WarningMessages())
+	}
+
+	result = Analyze(dropInput("ALTER TABLE test DROP COLUMN id_plus, DROP COLUMN id", id, virtual))
+	if !containsWarning(result.WarningMessages(), "Columns `id_plus`, `id` are all of the table's columns") {
+		t.Errorf("expected DROP_LAST_COLUMN for dropping every column: %v", result.WarningMessages())
+	}
+
+	for _, tc := range []struct {
+		name    string
+		sql     string
+		columns []mysql.ColumnInfo
+	}{
+		{"stored generated column left", "ALTER TABLE test DROP COLUMN id", []mysql.ColumnInfo{id, stored}},
+		{"column added in the same ALTER", "ALTER TABLE test DROP COLUMN id, ADD COLUMN name VARCHAR(10)", []mysql.ColumnInfo{id, virtual}},
+		{"other columns left", "ALTER TABLE test DROP COLUMN existing_col", []mysql.ColumnInfo{id, {Name: "existing_col", Type: "int", Position: 2}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Analyze(dropInput(tc.sql, tc.columns...))
+			if result.HasWarning(WarnDropLastColumn) || result.HasWarning(WarnNoStoredColumns) {
+				t.Errorf("unexpected warning: %v", result.WarningMessages())
+			}
+		})
+	}
+}
+
+func TestTopologyWarnings_Codes(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		op       parser.DDLOperation
+		topo     topology.Type
+		code     WarningCode
+		severity Severity
+	}{
+		{"aurora reader", parser.AddIndex, topology.AuroraReader, WarnAuroraReader, SeverityCritical},
+		{"gh-ost on aurora", parser.ChangeEngine, topology.AuroraWriter, WarnGhostIncompatible, SeverityWarning},
+		{"pt-osc cut-over", parser.ChangeEngine, topology.Galera, WarnPtOSCCutover, SeverityInfo},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Analyze(ddlInput(tc.op, v8_0_35, 20*1024*1024*1024, tc.topo))
+			var found *Warning
+			for i, w := range result.ClusterWarnings {
+				if w.Code == tc.code {
+					found = &result.ClusterWarnings[i]
+				}
+			}
+			if found == nil {
+				t.Fatalf("expected cluster warning %s, got: %v", tc.code, result.ClusterWarnings)
+			}
+			if found.Severity != tc.severity {
+				t.Errorf("%s severity = %s, want %s", tc.code, found.Severity, tc.severity)
+			}
+			if !result.HasWarning(tc.code) {
+				t.Errorf("HasWarning(%s) = false for a cluster warning", tc.code)
+			}
+		})
+	}
 }
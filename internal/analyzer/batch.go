@@ -0,0 +1,201 @@
+package analyzer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/nethalo/dbsafe/internal/parser"
+)
+
+// BatchResult is the analysis of several statements meant to run in order.
+type BatchResult struct {
+	Results  []*Result // one per input, in order
+	Risk     RiskLevel // highest risk among the results; SAFE when there are none
+	Commands []string  // the command that carries out each result (see Result.Command), in order; "" when none could be generated
+	Warnings []Warning // hazards that involve more than one statement
+}
+
+// WarningMessages returns the messages of the batch's warnings, in order.
+func (b *BatchResult) WarningMessages() []string {
+	return warningMessages(b.Warnings)
+}
+
+// AnalyzeBatch analyzes each input the way Analyze does and aggregates the results: the highest
+// risk, the commands in order, and warnings about the statements as a whole. Consecutive
+// ALTER TABLEs on the same table that each rebuild or scan it get a MERGEABLE_ALTERS
+// warning with the combined statement and its classification.
+func AnalyzeBatch(inputs []Input) *BatchResult {
+	batch := &BatchResult{Risk: RiskSafe}
+	for _, input := range inputs {
+		result := analyze(input)
+		if input.explainErr != nil {
+			result.addWarning(WarnExplainFailed, fmt.Sprintf("EXPLAIN failed: %v", input.explainErr))
+		}
+		if input.offline {
+			addOfflineWarning(input, result)
+		}
+		observe(input.Observer, result)
+		batch.Results = append(batch.Results, result)
+		batch.Commands = append(batch.Commands, result.Command())
+		if riskRank[result.Risk] > riskRank[batch.Risk] {
+			batch.Risk = result.Risk
+		}
+	}
+
+	// Runs of consecutive mergeable ALTERs on the same table.
+	start := 0
+	for i := 1; i <= len(inputs); i++ {
+		if i < len(inputs) && mergeableAlter(inputs[i].Parsed) && mergeableAlter(inputs[start].Parsed) &&
+			sameTable(batch.Results[i], batch.Results[start]) {
+			continue
+		}
+		if warn, ok := mergeAltersWarning(inputs[start:i], batch.Results[start:i], start); ok {
+			batch.Warnings = append(batch.Warnings, newWarning(WarnMergeableAlters, warn))
+		}
+		start = i
+	}
+	return batch
+}
+
+// BatchInputs loads the input of each statement of a file meant to run in order, the way
+// AnalyzeParsed does, for AnalyzeBatch. With a nil db and opts.Version set they are built
+// offline, like AnalyzeOffline does. An ALTER TABLE following one on the same table isn't
+// loaded again: it sees the columns and indexes the one before adds or drops.
+func BatchInputs(ctx context.Context, db *sql.DB, statements []*parser.ParsedSQL, opts Options) ([]Input, error) {
+	offline := db == nil && opts.Version != nil
+	inputs := make([]Input, 0, len(statements))
+	for i, parsed := range statements {
+		if name, ok := UnsupportedOperation(parsed); ok {
+			return nil, fmt.Errorf("statement %d: %w: %s", i+1, ErrUnsupportedStatement, name)
+		}
+		database := opts.Database
+		if database == "" {
+			database = parsed.Database
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			prev := inputs[i-1]
+			if mergeableAlter(prev.Parsed) && mergeableAlter(parsed) &&
+				strings.EqualFold(prev.Meta.Database, database) && strings.EqualFold(prev.Meta.Table, parsed.Table) {
+				next := prev
+				next.Parsed = parsed
+				next.Meta = metaAfterStep(prev.Meta, prev.Parsed)
+				inputs = append(inputs, next)
+				continue
+			}
+		}
+
+		var input Input
+		if offline {
+			meta, err := offlineMetadata(parsed, database, opts)
+			if err != nil {
+				return nil, fmt.Errorf("statement %d: %w", i+1, err)
+			}
+			input = offlineInput(parsed, meta, database, opts)
+		} else {
+			if database == "" && parsed.DDLOp != parser.AlterTablespace && parsed.DDLOp != parser.ObjectDefinition && parsed.DDLOp != parser.AccountManagement {
+				return nil, fmt.Errorf("statement %d: database not specified: qualify the table (e.g. mydb.users) or set Options.Database", i+1)
+			}
+			var err error
+			if input, err = loadInput(ctx, db, parsed, database, opts); err != nil {
+				return nil, fmt.Errorf("statement %d: %w", i+1, err)
+			}
+			input.EstimatedRows, input.explainErr = explainEstimate(db, parsed, opts)
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+// mergeableAlter reports whether the statement is an ALTER TABLE whose changes MySQL
+// accepts together with others in one statement. Partition operations and secondary engine
+// loads must run alone.
+func mergeableAlter(p *parser.ParsedSQL) bool {
+	if p.Type != parser.DDL || !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(p.RawSQL)), "ALTER TABLE") {
+		return false
+	}
+	switch p.DDLOp {
+	case parser.AddPartition, parser.DropPartition, parser.ReorganizePartition, parser.RebuildPartition,
+		parser.TruncatePartition, parser.RemovePartitioning, parser.RepartitionTable,
+		parser.SecondaryLoad, parser.SecondaryUnload,
+		parser.RenameTable, parser.OtherDDL:
+		return false
+	}
+	return true
+}
+
+func sameTable(a, b *Result) bool {
+	return strings.EqualFold(a.Database, b.Database) && strings.EqualFold(a.Table, b.Table)
+}
+
+// mergeAltersWarning suggests combining a run of ALTERs on one table when at least two of
+// them rebuild or scan it (anything but INSTANT, or a copy through gh-ost or pt-osc), and
+// gives the combined statement's classification (see aggregateMultipleOps). offset is the
+// index of the run's first statement in the batch, for the 1-based statement numbers in
+// the message.
+func mergeAltersWarning(inputs []Input, results []*Result, offset int) (string, bool) {
+	var numbers, specs, instant []string
+	passes, rebuilds := 0, 0
+	for i, result := range results {
+		spec := strings.TrimRight(strings.TrimSpace(extractAlterSpec(inputs[i].Parsed.RawSQL)), ";")
+		if spec == "" {
+			return "", false
+		}
+		specs = append(specs, spec)
+		numbers = append(numbers, fmt.Sprint(offset+i+1))
+		switch {
+		case result.Classification.RebuildsTable || result.Method == ExecGhost || result.Method == ExecPtOSC:
+			passes++
+			rebuilds++
+		case result.Classification.Algorithm != AlgoInstant:
+			passes++
+		default:
+			instant = append(instant, fmt.Sprint(offset+i+1))
+		}
+	}
+	if passes < 2 {
+		return "", false
+	}
+
+	first := results[0]
+	table := fmt.Sprintf("`%s`", first.Table)
+	if first.Database != "" {
+		table = fmt.Sprintf("`%s`.`%s`", first.Database, first.Table)
+	}
+	combined := fmt.Sprintf("ALTER TABLE %s %s;", table, strings.Join(specs, ", "))
+
+	work := "rebuild it"
+	if rebuilds < passes {
+		work = "rebuild or scan it"
+	}
+	msg := fmt.Sprintf(
+		"Statements %s each alter %s and %d of them %s: combine them into one ALTER to do the work once. ",
+		strings.Join(numbers, ", "), table, passes, work,
+	)
+	if parsed, err := parser.Parse(combined); err == nil && parsed.DDLOp == parser.MultipleOps {
+		cls, _, _ := aggregateMultipleOps(parsed.SubOperations, inputs[0].Meta, inputs[0].ForeignKeyChecksDisabled, inputs[0].Version)
+		rebuild := "without a table rebuild"
+		if cls.RebuildsTable {
+			rebuild = "rebuilding the table"
+		}
+		msg += fmt.Sprintf("Combined it runs with ALGORITHM=%s, LOCK=%s, %s (the most restrictive of its parts)", cls.Algorithm, cls.Lock, rebuild)
+	} else {
+		msg += "The combined statement takes the most restrictive algorithm and lock of its parts"
+	}
+	if len(instant) > 0 {
+		which := "statement " + instant[0] + " is"
+		if len(instant) > 1 {
+			which = "statements " + strings.Join(instant, ", ") + " are"
+		}
+		msg += fmt.Sprintf(
+			"; %s INSTANT on its own but takes the combined algorithm when merged (an ADD or DROP COLUMN then rebuilds the table): leave it out unless a later statement depends on it",
+			which,
+		)
+	}
+	return msg + ". Plan it again:\n  " + combined, true
+}
@@ -0,0 +1,197 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/parser"
+	"github.com/nethalo/dbsafe/internal/topology"
+)
+
+// batchInput parses sql and analyzes it against a 50 MB testdb table with an existing_col column.
+func batchInput(t *testing.T, sql string) Input {
+	t.Helper()
+	parsed, err := parser.Parse(sql)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", sql, err)
+	}
+	input := ddlInput(parsed.DDLOp, v8_0_35, 50*1024*1024, topology.Standalone)
+	input.Parsed = parsed
+	if parsed.Table != "test" {
+		meta := *input.Meta
+		meta.Table = parsed.Table
+		input.Meta = &meta
+	}
+	return input
+}
+
+func TestAnalyzeBatch_Empty(t *testing.T) {
+	batch := AnalyzeBatch(nil)
+	if batch.Risk != RiskSafe || len(batch.Results) != 0 || len(batch.Warnings) != 0 {
+		t.Errorf("batch = %+v, want SAFE with no results", batch)
+	}
+}
+
+func TestAnalyzeBatch_RiskAndCommands(t *testing.T) {
+	safe := batchInput(t, "ALTER TABLE testdb.test ADD COLUMN c INT")
+	dangerous := batchInput(t, "ALTER TABLE testdb.test MODIFY COLUMN existing_col TEXT")
+	dangerous.Meta = &mysql.TableMetadata{
+		Database: "testdb", Table: "test", DataLength: 5 * 1024 * 1024 * 1024,
+		Columns: []mysql.ColumnInfo{{Name: "existing_col", Type: "varchar(100)"}},
+	}
+
+	batch := AnalyzeBatch([]Input{safe, dangerous})
+
+	if len(batch.Results) != 2 || len(batch.Commands) != 2 {
+		t.Fatalf("got %d results and %d commands, want 2 and 2", len(batch.Results), len(batch.Commands))
+	}
+	if batch.Risk != RiskDangerous {
+		t.Errorf("Risk = %s, want DANGEROUS", batch.Risk)
+	}
+	if !strings.Contains(batch.Commands[0], "ADD COLUMN") {
+		t.Errorf("Commands[0] = %q, want the statement", batch.Commands[0])
+	}
+	if batch.Commands[1] != "" {
+		t.Errorf("Commands[1] = %q, want none without connection details", batch.Commands[1])
+	}
+}
+
+func TestAnalyzeBatch_MergeableAlters(t *testing.T) {
+	batch := AnalyzeBatch([]Input{
+		batchInput(t, "ALTER TABLE testdb.test MODIFY COLUMN existing_col TEXT"),
+		batchInput(t, "ALTER TABLE testdb.test ADD COLUMN c INT, ALGORITHM=INSTANT"),
+		batchInput(t, "ALTER TABLE testdb.test FORCE"),
+	})
+
+	if !containsWarning(batch.WarningMessages(), "Statements 1, 2, 3 each alter `testdb`.`test` and 2 of them rebuild it") {
+		t.Fatalf("expected a merge suggestion, got %v", batch.WarningMessages())
+	}
+	want := "ALTER TABLE `testdb`.`test` MODIFY COLUMN existing_col TEXT, ADD COLUMN c INT, FORCE;"
+	if !containsWarning(batch.WarningMessages(), want) {
+		t.Errorf("expected combined statement %q, got %v", want, batch.WarningMessages())
+	}
+	if batch.Warnings[0].Code != WarnMergeableAlters || batch.Warnings[0].Severity != SeverityInfo {
+		t.Errorf("warning = %+v, want INFO MERGEABLE_ALTERS", batch.Warnings[0])
+	}
+}
+
+func TestAnalyzeBatch_NoMerge(t *testing.T) {
+	tests := []struct {
+		name string
+		sqls []string
+	}{
+		{"instant changes", []string{
+			"ALTER TABLE testdb.test ADD COLUMN c INT",
+			"ALTER TABLE testdb.test ADD COLUMN d INT",
+		}},
+		{"different tables", []string{
+			"ALTER TABLE testdb.test FORCE",
+			"ALTER TABLE testdb.other FORCE",
+		}},
+		{"not consecutive", []string{
+			"ALTER TABLE testdb.test FORCE",
+			"ALTER TABLE testdb.other FORCE",
+			"ALTER TABLE testdb.test FORCE",
+		}},
+		{"partition operation", []string{
+			"ALTER TABLE testdb.test FORCE",
+			"ALTER TABLE testdb.test REBUILD PARTITION p0",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inputs []Input
+			for _, sql := range tt.sqls {
+				inputs = append(inputs, batchInput(t, sql))
+			}
+			if batch := AnalyzeBatch(inputs); len(batch.Warnings) != 0 {
+				t.Errorf("expected no batch warnings, got %v", batch.WarningMessages())
+			}
+		})
+	}
+}
+
+func TestAnalyzeBatch_MergeableAlters_Scans(t *testing.T) {
+	addColumn := batchInput(t, "ALTER TABLE testdb.test ADD COLUMN a INT")
+	addIndex := batchInput(t, "ALTER TABLE testdb.test ADD INDEX idx_a (a)")
+	addColumn.Version, addIndex.Version = v8_0_5, v8_0_5
+
+	msgs := AnalyzeBatch([]Input{addColumn, addIndex}).WarningMessages()
+	for _, want := range []string{
+		"Statements 1, 2 each alter `testdb`.`test` and 2 of them rebuild or scan it",
+		"Combined it runs with ALGORITHM=INPLACE, LOCK=NONE",
+		"ALTER TABLE `testdb`.`test` ADD COLUMN a INT, ADD INDEX idx_a (a);",
+	} {
+		if !containsWarning(msgs, want) {
+			t.Errorf("expected %q, got %v", want, msgs)
+		}
+	}
+
+	// On 8.0.35 the ADD COLUMN is INSTANT: a single index build is one pass, two are worth
+	// merging, and the INSTANT statement is flagged.
+	if batch := AnalyzeBatch([]Input{
+		batchInput(t, "ALTER TABLE testdb.test ADD COLUMN a INT"),
+		batchInput(t, "ALTER TABLE testdb.test ADD INDEX idx_a (a)"),
+	}); len(batch.Warnings) != 0 {
+		t.Errorf("expected no merge for one index build, got %v", batch.WarningMessages())
+	}
+	msgs = AnalyzeBatch([]Input{
+		batchInput(t, "ALTER TABLE testdb.test ADD COLUMN a INT"),
+		batchInput(t, "ALTER TABLE testdb.test ADD INDEX idx_a (a)"),
+		batchInput(t, "ALTER TABLE testdb.test ADD INDEX idx_b (existing_col)"),
+	}).WarningMessages()
+	for _, want := range []string{"without a table rebuild", "statement 1 is INSTANT on its own"} {
+		if !containsWarning(msgs, want) {
+			t.Errorf("expected %q, got %v", want, msgs)
+		}
+	}
+}
+
+func TestBatchInputs_Offline(t *testing.T) {
+	dump, err := NewSchemaDump("CREATE TABLE shop.orders (id INT PRIMARY KEY, total DECIMAL(10,2));")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statements []*parser.ParsedSQL
+	for _, sql := range []string{
+		"ALTER TABLE shop.orders ADD COLUMN note VARCHAR(20)",
+		"ALTER TABLE shop.orders MODIFY COLUMN note VARCHAR(40)",
+		"DELETE FROM shop.orders WHERE id < 10",
+	} {
+		parsed, err := parser.Parse(sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		statements = append(statements, parsed)
+	}
+
+	inputs, err := BatchInputs(context.Background(), nil, statements, Options{Version: &v8_0_35, Metadata: dump})
+	if err != nil {
+		t.Fatalf("BatchInputs: %v", err)
+	}
+	batch := AnalyzeBatch(inputs)
+
+	if len(batch.Results) != 3 {
+		t.Fatalf("got %d results, want 3", len(batch.Results))
+	}
+	// The MODIFY sees the column the ADD before it adds.
+	if batch.Results[1].HasWarning(WarnColumnNotFound) {
+		t.Errorf("MODIFY of the column added by the statement before: %v", batch.Results[1].Warnings)
+	}
+	for i, result := range batch.Results {
+		if !result.HasWarning(WarnOfflineAnalysis) {
+			t.Errorf("result %d: no offline warning, got %v", i+1, result.Warnings)
+		}
+	}
+
+	insert, err := parser.Parse("INSERT INTO shop.orders (id) VALUES (1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BatchInputs(context.Background(), nil, append(statements, insert), Options{Version: &v8_0_35}); !errors.Is(err, ErrUnsupportedStatement) || !strings.Contains(err.Error(), "statement 4") {
+		t.Errorf("err = %v, want statement 4 unsupported", err)
+	}
+}
@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nethalo/dbsafe/internal/parser"
+)
+
+// Rough throughput assumptions used only for the blast-radius duration estimate.
+// Real numbers depend on hardware, buffer pool and concurrent load; the estimate is
+// meant to distinguish "seconds" from "hours". RecommendWindow rounds it up to whole hours.
+const (
+	rebuildBytesPerSec = 25 * 1024 * 1024 // native table copy / index build
+	oscSlowdownFactor  = 2                // gh-ost / pt-osc copy in chunks while replaying changes
+	dmlRowsPerSec      = 5000             // row changes per second for DELETE/UPDATE
+)
+
+// BlastRadiusSummary returns a single line summarizing what an operation touches:
+// table size, row count, triggers, foreign keys, estimated duration and disk needed.
+// It is shown before the execution command when the user asks for confirmation.
+func BlastRadiusSummary(result *Result) string {
+	target := result.Table
+	if result.Database != "" {
+		target = result.Database + "." + result.Table
+	}
+	parts := []string{fmt.Sprintf("%s: %s", target, methodOrOp(result))}
+
+	if meta := result.TableMeta; meta != nil {
+		parts = append(parts,
+			humanBytes(meta.TotalSize()),
+			fmt.Sprintf("~%s rows", formatNumber(meta.RowCount)),
+			pluralize(len(meta.Triggers), "trigger"),
+			pluralize(len(meta.ForeignKeys)+len(meta.InboundForeignKeys), "FK"),
+		)
+	}
+	if result.StatementType == parser.DML {
+		parts = append(parts, fmt.Sprintf("~%s rows affected", formatNumber(result.AffectedRows)))
+	}
+
+	parts = append(parts, "est. duration "+formatEstimate(EstimateDuration(result)))
+
+	disk := "none"
+	if result.DiskEstimate != nil {
+		disk = result.DiskEstimate.RequiredHuman
+	}
+	parts = append(parts, "disk needed "+disk)
+
+	return "Blast radius — " + strings.Join(parts, ", ")
+}
+
+// EstimateDuration returns a rough wall-clock estimate for the operation.
+// INSTANT and metadata-only operations return 0.
+func EstimateDuration(result *Result) time.Duration {
+	if result.StatementType == parser.DML {
+		return time.Duration(result.AffectedRows/dmlRowsPerSec) * time.Second
+	}
+	if result.TableMeta == nil || result.Classification.Algorithm == AlgoInstant {
+		return 0
+	}
+	if result.Classification.Algorithm == AlgoInplace && !result.Classification.RebuildsTable &&
+		result.Method == ExecDirect && result.DiskEstimate == nil {
+		return 0 // metadata-only INPLACE (rename index, drop index, ...)
+	}
+	rate := result.copyBytesPerSec
+	if rate <= 0 {
+		rate = rebuildBytesPerSec
+	}
+	secs := result.TableMeta.TotalSize() / rate
+	if result.Method == ExecGhost || result.Method == ExecPtOSC {
+		secs *= oscSlowdownFactor
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// directCopyTradeoffWarning quantifies the write-blocking window of a direct COPY on a
+// mid-size table (100 MB up to the DANGEROUS size threshold), copied at rate bytes per
+// second, and compares it with an online schema change tool.
+// Returns ("", false) below 100 MB, where the window is too short to matter.
+func directCopyTradeoffWarning(size, rate int64) (string, bool) {
+	if size < 100*1024*1024 {
+		return "", false
+	}
+	blocked := time.Duration(size/rate) * time.Second
+	return fmt.Sprintf(
+		"Direct COPY blocks writes for the whole rebuild: est. %s for %s (assuming ~%s/s). "+
+			"gh-ost/pt-osc take about %dx longer in total (est. %s) but only block writes for the brief cut-over. "+
+			"Use DIRECT if the table can go without writes for that long; otherwise use an online schema change tool.",
+		formatEstimate(blocked), humanBytes(size), humanBytes(rate), oscSlowdownFactor, formatEstimate(blocked*oscSlowdownFactor),
+	), true
+}
+
+func methodOrOp(result *Result) string {
+	op := string(result.DDLOp)
+	if result.StatementType == parser.DML {
+		op = string(result.DMLOp)
+	}
+	if result.Method == "" {
+		return op
+	}
+	return fmt.Sprintf("%s via %s", op, result.Method)
+}
+
+func formatEstimate(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("~%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("~%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
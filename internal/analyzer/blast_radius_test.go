@@ -0,0 +1,180 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/parser"
+	"github.com/nethalo/dbsafe/internal/topology"
+)
+
+func TestBlastRadiusSummary_OnlineSchemaChange(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 100*1024*1024*1024, topology.Standalone)
+	input.Parsed.NewColumnType = "bigint"
+	input.Meta.RowCount = 450_000_000
+	input.Meta.Triggers = []mysql.TriggerInfo{{Name: "trg_audit", Event: "UPDATE", Timing: "AFTER"}}
+	input.Meta.ForeignKeys = []mysql.ForeignKeyInfo{{Name: "fk_user"}}
+	input.Meta.InboundForeignKeys = []mysql.ForeignKeyInfo{{Name: "fk_child", ChildTable: "child"}}
+	result := Analyze(input)
+
+	got := BlastRadiusSummary(result)
+	for _, want := range []string{
+		"testdb.test: MODIFY_COLUMN via ",
+		"100.0 GB",
+		"~450.0M rows",
+		"1 trigger,",
+		"2 FKs",
+		"est. duration ~",
+		"disk needed ",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q, got: %s", want, got)
+		}
+	}
+	if strings.Contains(got, "\n") {
+		t.Errorf("summary should be a single line, got: %q", got)
+	}
+}
+
+func TestBlastRadiusSummary_Instant(t *testing.T) {
+	input := ddlInput(parser.AddColumn, v8_0_35, 10*1024*1024, topology.Standalone)
+	result := Analyze(input)
+
+	got := BlastRadiusSummary(result)
+	if !strings.Contains(got, "est. duration <1m") {
+		t.Errorf("INSTANT operation should estimate <1m, got: %s", got)
+	}
+	if !strings.Contains(got, "disk needed none") {
+		t.Errorf("INSTANT operation should need no disk, got: %s", got)
+	}
+	if !strings.Contains(got, "0 triggers, 0 FKs") {
+		t.Errorf("expected zero triggers and FKs, got: %s", got)
+	}
+}
+
+func TestEstimateDuration(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+	tests := []struct {
+		name   string
+		result *Result
+		want   time.Duration
+	}{
+		{
+			name: "instant",
+			result: &Result{
+				StatementType:  parser.DDL,
+				TableMeta:      &mysql.TableMetadata{DataLength: 10 * gb},
+				Classification: DDLClassification{Algorithm: AlgoInstant},
+				Method:         ExecDirect,
+			},
+			want: 0,
+		},
+		{
+			name: "native copy",
+			result: &Result{
+				StatementType:  parser.DDL,
+				TableMeta:      &mysql.TableMetadata{DataLength: 25 * 1024 * 1024 * 60},
+				Classification: DDLClassification{Algorithm: AlgoCopy, RebuildsTable: true},
+				Method:         ExecDirect,
+			},
+			want: time.Minute,
+		},
+		{
+			name: "gh-ost is slower than a native copy",
+			result: &Result{
+				StatementType:  parser.DDL,
+				TableMeta:      &mysql.TableMetadata{DataLength: 25 * 1024 * 1024 * 60},
+				Classification: DDLClassification{Algorithm: AlgoCopy, RebuildsTable: true},
+				Method:         ExecGhost,
+			},
+			want: 2 * time.Minute,
+		},
+		{
+			name: "configured copy throughput",
+			result: &Result{
+				StatementType:   parser.DDL,
+				TableMeta:       &mysql.TableMetadata{DataLength: 25 * 1024 * 1024 * 60},
+				Classification:  DDLClassification{Algorithm: AlgoCopy, RebuildsTable: true},
+				Method:          ExecDirect,
+				copyBytesPerSec: 100 * 1024 * 1024,
+			},
+			want: 15 * time.Second,
+		},
+		{
+			name:   "dml",
+			result: &Result{StatementType: parser.DML, AffectedRows: 50_000},
+			want:   10 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EstimateDuration(tt.result); got != tt.want {
+				t.Errorf("EstimateDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatEstimate(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "<1m"},
+		{59 * time.Second, "<1m"},
+		{42 * time.Minute, "~42m"},
+		{4*time.Hour + 10*time.Minute, "~4h 10m"},
+	}
+	for _, tt := range tests {
+		if got := formatEstimate(tt.in); got != tt.want {
+			t.Errorf("formatEstimate(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDirectCopyTradeoffWarning(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 900*1024*1024, topology.Standalone)
+	input.Parsed.NewColumnType = "bigint"
+	result := Analyze(input)
+
+	if result.Method != ExecDirect {
+		t.Fatalf("Method = %s, want DIRECT for a 900 MB COPY", result.Method)
+	}
+	if !containsWarning(result.WarningMessages(), "Direct COPY blocks writes for the whole rebuild: est. <1m for 900.0 MB") {
+		t.Errorf("expected write-blocking tradeoff warning, got: %v", result.Warnings)
+	}
+
+	if _, ok := directCopyTradeoffWarning(50*1024*1024, rebuildBytesPerSec); ok {
+		t.Error("tables under 100 MB should not get the tradeoff warning")
+	}
+	warn, _ := directCopyTradeoffWarning(3*1024*1024*1024, rebuildBytesPerSec) // ~2m blocked, ~4m online
+	if !strings.Contains(warn, "est. ~2m") || !strings.Contains(warn, "about 2x longer in total (est. ~4m)") {
+		t.Errorf("unexpected tradeoff warning: %s", warn)
+	}
+}
+
+func TestDirectCopyTradeoffWarning_CopyRate(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 900*1024*1024, topology.Standalone)
+	input.Parsed.NewColumnType = "bigint"
+	input.CopyBytesPerSec = 5 * 1024 * 1024
+	result := Analyze(input)
+
+	if !containsWarning(result.WarningMessages(), "est. ~3m for 900.0 MB (assuming ~5.0 MB/s)") {
+		t.Errorf("expected the tradeoff warning at the configured copy rate, got: %v", result.Warnings)
+	}
+	if got := EstimateDuration(result); got != 3*time.Minute {
+		t.Errorf("EstimateDuration = %v, want 3m, the window the warning quotes", got)
+	}
+}
+
+func TestDirectCopyTradeoffWarning_NotForOnlineTools(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 5*1024*1024*1024, topology.Standalone)
+	input.Parsed.NewColumnType = "bigint"
+	result := Analyze(input)
+
+	if containsWarning(result.WarningMessages(), "Direct COPY blocks writes") {
+		t.Errorf("tables routed to gh-ost/pt-osc should not get the direct COPY tradeoff warning, got: %v", result.Warnings)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/nethalo/dbsafe/internal/parser"
+	"github.com/nethalo/dbsafe/internal/topology"
 )
 
 func TestExtractAlterSpec(t *testing.T) {
@@ -304,7 +305,7 @@ func TestGeneratePtOSCCommand(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := generatePtOSCCommand(tt.input, tt.isGalera)
+			result := generatePtOSCCommand(tt.input, ptoscOptions{Galera: tt.isGalera})
 
 			if tt.wantNotEmpty && result == "" {
 				t.Error("expected non-empty result, got empty string")
@@ -386,7 +387,7 @@ func TestGeneratePtOSCCommand_DSNFormat(t *testing.T) {
 		},
 	}
 
-	result := generatePtOSCCommand(input, false)
+	result := generatePtOSCCommand(input, ptoscOptions{})
 
 	// DSN should be in format: h=host,P=port,u=user,D=db,t=table
 	expectedParts := []string{"h=host", "P=3307", "u=user", "D=db", "t=test"}
@@ -396,3 +397,407 @@ func TestGeneratePtOSCCommand_DSNFormat(t *testing.T) {
 		}
 	}
 }
+
+func TestGeneratePtOSCCommand_RunModes(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:     parser.DDL,
+			DDLOp:    parser.AddColumn,
+			RawSQL:   "ALTER TABLE test ADD COLUMN col INT",
+			Database: "db",
+			Table:    "test",
+		},
+		Connection: &ConnectionInfo{Host: "host", Port: 3306, User: "user"},
+	}
+
+	dryRun := generatePtOSCCommand(input, ptoscOptions{DryRun: true})
+	if !strings.Contains(dryRun, "--dry-run") || strings.Contains(dryRun, "--execute") {
+		t.Errorf("dry-run command should use --dry-run and not --execute, got:\n%s", dryRun)
+	}
+
+	keepOld := generatePtOSCCommand(input, ptoscOptions{NoDropOldTable: true})
+	if !strings.Contains(keepOld, "--execute") || !strings.Contains(keepOld, "--no-drop-old-table") {
+		t.Errorf("command should use --execute with --no-drop-old-table, got:\n%s", keepOld)
+	}
+
+	def := generatePtOSCCommand(input, ptoscOptions{})
+	if strings.Contains(def, "--dry-run") || strings.Contains(def, "--no-drop-old-table") {
+		t.Errorf("default command should not contain safe-mode flags, got:\n%s", def)
+	}
+}
+
+func TestPtOSCLoadThresholds(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxConnections int64
+		threadsRunning int64
+		wantMax        int64
+		wantCritical   int64
+	}{
+		{"unknown server falls back to defaults", 0, 0, 25, 50},
+		{"large server scales up", 2000, 10, 200, 400},
+		{"default max_connections", 151, 3, 14, 29},
+		{"tiny dev box keeps a floor", 20, 1, 5, 10},
+		{"busy baseline raises max-load", 2000, 150, 300, 600},
+		{"capped at max_connections", 30, 20, 30, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMax, gotCritical := ptoscLoadThresholds(tt.maxConnections, tt.threadsRunning)
+			if gotMax != tt.wantMax || gotCritical != tt.wantCritical {
+				t.Errorf("ptoscLoadThresholds(%d, %d) = (%d, %d), want (%d, %d)",
+					tt.maxConnections, tt.threadsRunning, gotMax, gotCritical, tt.wantMax, tt.wantCritical)
+			}
+		})
+	}
+}
+
+func TestGeneratePtOSCCommand_LoadThresholds(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:     parser.DDL,
+			DDLOp:    parser.AddColumn,
+			RawSQL:   "ALTER TABLE test ADD COLUMN col INT",
+			Database: "db",
+			Table:    "test",
+		},
+		Connection: &ConnectionInfo{Host: "host", Port: 3306, User: "user"},
+	}
+
+	def := generatePtOSCCommand(input, ptoscOptions{})
+	for _, want := range []string{"--max-load=Threads_running=25", "--critical-load=Threads_running=50"} {
+		if !strings.Contains(def, want) {
+			t.Errorf("command without server variables should contain %q, got:\n%s", want, def)
+		}
+	}
+
+	input.MaxConnections = 2000
+	input.ThreadsRunning = 10
+	scaled := generatePtOSCCommand(input, ptoscOptions{})
+	for _, want := range []string{"--max-load=Threads_running=200", "--critical-load=Threads_running=400"} {
+		if !strings.Contains(scaled, want) {
+			t.Errorf("command for max_connections=2000 should contain %q, got:\n%s", want, scaled)
+		}
+	}
+}
+
+func TestGeneratePtOSCCommand_Chunking(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:     parser.DDL,
+			DDLOp:    parser.AddColumn,
+			RawSQL:   "ALTER TABLE test ADD COLUMN col INT",
+			Database: "db",
+			Table:    "test",
+		},
+		Connection: &ConnectionInfo{Host: "host", Port: 3306, User: "user"},
+	}
+
+	tests := []struct {
+		name     string
+		topo     topology.Type
+		chunking PtOSCChunking
+		want     []string
+	}{
+		{"standalone defaults", topology.Standalone, PtOSCChunking{}, []string{"--chunk-size=1000", "--chunk-time=0.5", "--chunk-size-limit=4"}},
+		{"Galera uses shorter chunks", topology.Galera, PtOSCChunking{}, []string{"--chunk-time=0.2"}},
+		{"Group Replication uses shorter chunks", topology.GroupRepl, PtOSCChunking{}, []string{"--chunk-time=0.2"}},
+		{"semi-sync uses shorter chunks", topology.SemiSyncReplica, PtOSCChunking{}, []string{"--chunk-time=0.25"}},
+		{
+			"overrides win over topology defaults",
+			topology.Galera,
+			PtOSCChunking{ChunkSize: 500, ChunkTime: 1.5, ChunkSizeLimit: 8},
+			[]string{"--chunk-size=500", "--chunk-time=1.5", "--chunk-size-limit=8"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input.Topo = &topology.Info{Type: tt.topo}
+			input.PtOSCChunking = tt.chunking
+			cmd := ptoscExecutionCommand(input, tt.topo == topology.Galera)
+			for _, want := range tt.want {
+				if !strings.Contains(cmd, want) {
+					t.Errorf("command should contain %q, got:\n%s", want, cmd)
+				}
+			}
+		})
+	}
+}
+
+func TestPtOSCExecutionCommand_SafeMode(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:     parser.DDL,
+			DDLOp:    parser.AddColumn,
+			RawSQL:   "ALTER TABLE test ADD COLUMN col INT",
+			Database: "db",
+			Table:    "test",
+		},
+		Connection: &ConnectionInfo{Host: "host", Port: 3306, User: "user"},
+		SafePtOSC:  true,
+	}
+
+	result := ptoscExecutionCommand(input, false)
+
+	dry := strings.Index(result, "--dry-run")
+	exec := strings.Index(result, "--execute")
+	if dry < 0 || exec < 0 || dry > exec {
+		t.Errorf("safe mode should emit --dry-run before --execute, got:\n%s", result)
+	}
+	for _, want := range []string{"--no-drop-old-table", "DROP TABLE `db`.`_test_old`;"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("safe mode should contain %q, got:\n%s", want, result)
+		}
+	}
+	if strings.Count(result, "pt-online-schema-change") != 2 {
+		t.Errorf("safe mode should emit two pt-osc commands, got:\n%s", result)
+	}
+}
+
+func TestCommands_PasswordEnv(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:     parser.DDL,
+			DDLOp:    parser.AddColumn,
+			RawSQL:   "ALTER TABLE test ADD COLUMN col INT",
+			Database: "db",
+			Table:    "test",
+		},
+		Topo:       &topology.Info{Type: topology.Standalone},
+		Connection: &ConnectionInfo{Host: "host", Port: 3306, User: "user", PasswordEnv: "MYSQL_PWD"},
+	}
+
+	if ghost := generateGhostCommand(input); !strings.Contains(ghost, `--password="$MYSQL_PWD"`) {
+		t.Errorf("gh-ost command should read the password from $MYSQL_PWD, got:\n%s", ghost)
+	}
+	if ptosc := ptoscExecutionCommand(input, false); !strings.Contains(ptosc, `--password "$MYSQL_PWD"`) || strings.Contains(ptosc, ",p=") {
+		t.Errorf("pt-osc command should read the password from $MYSQL_PWD, not the DSN, got:\n%s", ptosc)
+	}
+
+	input.Connection.PasswordEnv = ""
+	if ghost := generateGhostCommand(input); strings.Contains(ghost, "--password") {
+		t.Errorf("gh-ost command should have no --password without PasswordEnv, got:\n%s", ghost)
+	}
+}
+
+func TestCommands_WithHooks(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:     parser.DDL,
+			DDLOp:    parser.AddColumn,
+			RawSQL:   "ALTER TABLE test ADD COLUMN col INT",
+			Database: "db",
+			Table:    "test",
+		},
+		Topo:       &topology.Info{Type: topology.Standalone},
+		Connection: &ConnectionInfo{Host: "host", Port: 3306, User: "user"},
+		WithHooks:  true,
+	}
+
+	ghost := generateGhostCommand(input)
+	if !strings.Contains(ghost, `--hooks-path="./gh-ost-hooks" \`+"\n  --execute") {
+		t.Errorf("gh-ost command should set --hooks-path before --execute, got:\n%s", ghost)
+	}
+	if !strings.Contains(ghost, "# cat > ./gh-ost-hooks/gh-ost-on-before-cut-over-notify <<'HOOK'") {
+		t.Errorf("gh-ost command should end with the hooks scaffold, got:\n%s", ghost)
+	}
+
+	ptosc := ptoscExecutionCommand(input, false)
+	if !strings.Contains(ptosc, "--plugin=./ptosc_plugin.pl") || !strings.Contains(ptosc, "# package pt_online_schema_change_plugin;") {
+		t.Errorf("pt-osc command should load the plugin and include its scaffold, got:\n%s", ptosc)
+	}
+
+	input.SafePtOSC = true
+	staged := ptoscExecutionCommand(input, false)
+	if strings.Count(staged, "--plugin=") != 2 || strings.Count(staged, "# package pt_online_schema_change_plugin;") != 1 {
+		t.Errorf("safe mode should load the plugin in both commands and include the scaffold once, got:\n%s", staged)
+	}
+
+	// Every scaffold line is a comment, so the output stays runnable as is.
+	for _, cmd := range []string{ghost, ptosc} {
+		_, scaffold, _ := strings.Cut(cmd, "\n\n")
+		for _, line := range strings.Split(scaffold, "\n") {
+			if !strings.HasPrefix(line, "#") {
+				t.Errorf("scaffold line %q is not commented out", line)
+			}
+		}
+	}
+
+	input.WithHooks, input.SafePtOSC = false, false
+	if cmd := generateGhostCommand(input) + ptoscExecutionCommand(input, false); strings.Contains(cmd, "hooks") || strings.Contains(cmd, "plugin") {
+		t.Errorf("commands without WithHooks should have no hooks, got:\n%s", cmd)
+	}
+}
+
+func TestGeneratePtOSCCommand_RecursionMethod(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:     parser.DDL,
+			DDLOp:    parser.AddColumn,
+			RawSQL:   "ALTER TABLE test ADD COLUMN col INT",
+			Database: "db",
+			Table:    "test",
+		},
+		Topo:       &topology.Info{Type: topology.Standalone},
+		Connection: &ConnectionInfo{Host: "host", Port: 3306, User: "user"},
+	}
+
+	tests := []struct {
+		name     string
+		topo     *topology.Info
+		method   string
+		want     string
+		wantHint string
+	}{
+		{"default", &topology.Info{Type: topology.Standalone}, "", "--recursion-method=processlist", ""},
+		{"aurora", &topology.Info{Type: topology.AuroraWriter, IsCloudManaged: true}, "", "--recursion-method=none", ""},
+		{"rds", &topology.Info{Type: topology.AsyncReplica, IsCloudManaged: true, CloudProvider: "aws-rds"}, "", "--recursion-method=none", ""},
+		{"explicit", &topology.Info{Type: topology.AuroraWriter, IsCloudManaged: true}, "hosts", "--recursion-method=hosts", ""},
+		{"bare dsn", &topology.Info{Type: topology.Standalone}, "dsn", "--recursion-method=dsn=D=percona,t=dsns", "INSERT INTO `percona`.`dsns` (dsn)"},
+		{"dsn table", &topology.Info{Type: topology.Standalone}, "dsn=h=host,D=ops,t=replicas", "--recursion-method=dsn=h=host,D=ops,t=replicas", "CREATE TABLE `ops`.`replicas`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input.Topo, input.PtOSCRecursionMethod = tt.topo, tt.method
+			cmd := ptoscExecutionCommand(input, false)
+			if !strings.Contains(cmd, tt.want) {
+				t.Errorf("command should contain %q, got:\n%s", tt.want, cmd)
+			}
+			if tt.wantHint == "" && strings.HasPrefix(cmd, "#") {
+				t.Errorf("command should have no DSN table hint, got:\n%s", cmd)
+			}
+			if tt.wantHint != "" && !strings.Contains(cmd, tt.wantHint) {
+				t.Errorf("command should start with a DSN table hint containing %q, got:\n%s", tt.wantHint, cmd)
+			}
+		})
+	}
+
+	input.Topo, input.PtOSCRecursionMethod, input.SafePtOSC = &topology.Info{Type: topology.Standalone}, "dsn", true
+	staged := ptoscExecutionCommand(input, false)
+	if strings.Count(staged, "--recursion-method=dsn=") != 2 || strings.Count(staged, "CREATE TABLE `percona`.`dsns`") != 1 {
+		t.Errorf("safe mode should use the DSN method in both commands and give the hint once, got:\n%s", staged)
+	}
+}
+
+func TestCommands_GhostReplication(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:     parser.DDL,
+			DDLOp:    parser.AddColumn,
+			RawSQL:   "ALTER TABLE test ADD COLUMN col INT",
+			Database: "db",
+			Table:    "test",
+		},
+		Topo:       &topology.Info{Type: topology.AsyncReplica, IsReplica: true, IsPrimary: true},
+		Connection: &ConnectionInfo{Host: "db-a", Port: 3306, User: "user"},
+	}
+
+	tests := []struct {
+		name    string
+		topo    topology.Info
+		ghost   GhostReplication
+		want    []string
+		notWant []string
+	}{
+		{"chained replica", topology.Info{}, GhostReplication{}, nil,
+			[]string{"--allow-master-master", "--assume-master-host", "--replica-server-id"}},
+		{"master-master, writable side", topology.Info{MasterMaster: true, SourceHost: "db-b", SourcePort: 3306}, GhostReplication{},
+			[]string{"--allow-master-master", `--assume-master-host="db-a:3306"`}, nil},
+		{"master-master, read-only side", topology.Info{MasterMaster: true, ReadOnly: true, SourceHost: "db-b", SourcePort: 3307}, GhostReplication{},
+			[]string{"--allow-master-master", `--assume-master-host="db-b:3307"`}, nil},
+		{"explicit", topology.Info{}, GhostReplication{AssumeMasterHost: "primary:3306", ReplicaServerID: 4242},
+			[]string{`--assume-master-host="primary:3306"`, "--replica-server-id=4242"}, []string{"--allow-master-master"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topo := tt.topo
+			topo.Type, topo.IsReplica, topo.IsPrimary = topology.AsyncReplica, true, true
+			input.Topo, input.GhostReplication = &topo, tt.ghost
+			cmd := generateGhostCommand(input)
+			for _, want := range tt.want {
+				if !strings.Contains(cmd, want) {
+					t.Errorf("command should contain %q, got:\n%s", want, cmd)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(cmd, notWant) {
+					t.Errorf("command should not contain %q, got:\n%s", notWant, cmd)
+				}
+			}
+		})
+	}
+}
+
+func TestCommands_GhostAllowOnMaster(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:     parser.DDL,
+			DDLOp:    parser.AddColumn,
+			RawSQL:   "ALTER TABLE test ADD COLUMN col INT",
+			Database: "db",
+			Table:    "test",
+		},
+		Connection: &ConnectionInfo{Host: "db-a", Port: 3306, User: "user"},
+	}
+
+	tests := []struct {
+		name string
+		topo topology.Info
+		want bool
+	}{
+		{"standalone", topology.Info{Type: topology.Standalone}, false},
+		{"rds", topology.Info{Type: topology.Standalone, IsCloudManaged: true, CloudProvider: "aws-rds"}, true},
+		{"aurora writer with binlogs", topology.Info{Type: topology.AuroraWriter, AuroraBinlogEnabled: true}, true},
+		{"aurora writer without binlogs", topology.Info{Type: topology.AuroraWriter}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topo := tt.topo
+			input.Topo = &topo
+			cmd := generateGhostCommand(input)
+			if got := strings.Contains(cmd, "--allow-on-master"); got != tt.want {
+				t.Errorf("--allow-on-master in command = %v, want %v, got:\n%s", got, tt.want, cmd)
+			}
+		})
+	}
+}
+
+func TestCommands_TLS(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:     parser.DDL,
+			DDLOp:    parser.AddColumn,
+			RawSQL:   "ALTER TABLE test ADD COLUMN col INT",
+			Database: "db",
+			Table:    "test",
+		},
+		Topo:       &topology.Info{Type: topology.Standalone},
+		Connection: &ConnectionInfo{Host: "db.example.com", Port: 3306, User: "user", TLS: true, TLSCA: "/etc/ssl/rds-ca.pem"},
+	}
+
+	ghost := generateGhostCommand(input)
+	for _, want := range []string{"--ssl \\", `--ssl-ca="/etc/ssl/rds-ca.pem"`} {
+		if !strings.Contains(ghost, want) {
+			t.Errorf("gh-ost command should contain %q, got:\n%s", want, ghost)
+		}
+	}
+	if strings.Contains(ghost, "--ssl-allow-insecure") {
+		t.Errorf("gh-ost command should verify the server, got:\n%s", ghost)
+	}
+	ptosc := ptoscExecutionCommand(input, false)
+	for _, want := range []string{"h=db.example.com,P=3306,u=user,D=db,t=test,s=1", "#   ssl-ca=/etc/ssl/rds-ca.pem"} {
+		if !strings.Contains(ptosc, want) {
+			t.Errorf("pt-osc command should contain %q, got:\n%s", want, ptosc)
+		}
+	}
+
+	input.Connection = &ConnectionInfo{Host: "db.example.com", Port: 3306, User: "user", TLS: true, TLSSkipVerify: true}
+	if ghost := generateGhostCommand(input); !strings.Contains(ghost, "--ssl-allow-insecure") || strings.Contains(ghost, "--ssl-ca") {
+		t.Errorf("gh-ost command should skip verification without a CA, got:\n%s", ghost)
+	}
+
+	input.Connection = &ConnectionInfo{Host: "db.example.com", Port: 3306, User: "user"}
+	if ghost, ptosc := generateGhostCommand(input), ptoscExecutionCommand(input, false); strings.Contains(ghost, "--ssl") || strings.Contains(ptosc, "s=1") {
+		t.Errorf("commands should not use TLS without it, got:\n%s\n%s", ghost, ptosc)
+	}
+}
@@ -54,6 +54,10 @@ func classifyVersion(major, minor, patch int) VersionRange {
 		}
 		return V8_0_Early
 	}
+	// 5.7 has no INSTANT at all: the pre-8.0.12 rules are the closest the matrix has.
+	if major == 5 {
+		return V8_0_Early
+	}
 	// Default to latest behavior for unknown versions
 	return V8_0_Full
 }
@@ -324,6 +328,16 @@ var ddlMatrix = map[matrixKey]DDLClassification{
 	{parser.ForceRebuild, V8_0_Full}:    {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: true, Notes: "INPLACE with table rebuild. Reclaims fragmented space and resets TOTAL_ROW_VERSIONS counter."},
 	{parser.ForceRebuild, V8_4_LTS}:     {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: true, Notes: "INPLACE with table rebuild. Reclaims fragmented space and resets TOTAL_ROW_VERSIONS counter."},
 
+	// ═══════════════════════════════════════════════════
+	// ORDER BY (ALTER TABLE ... ORDER BY col)
+	// Physically reorders rows by copying the table. Only supported by the COPY algorithm.
+	// InnoDB stores rows in clustered-index order, so the new order is not kept after DML.
+	// ═══════════════════════════════════════════════════
+	{parser.OrderBy, V8_0_Early}:   {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true, Notes: "COPY with SHARED lock — writes blocked. Rebuilds the whole table to reorder rows."},
+	{parser.OrderBy, V8_0_Instant}: {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true, Notes: "COPY with SHARED lock — writes blocked. Rebuilds the whole table to reorder rows."},
+	{parser.OrderBy, V8_0_Full}:    {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true, Notes: "COPY with SHARED lock — writes blocked. Rebuilds the whole table to reorder rows."},
+	{parser.OrderBy, V8_4_LTS}:     {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true, Notes: "COPY with SHARED lock — writes blocked. Rebuilds the whole table to reorder rows."},
+
 	// ═══════════════════════════════════════════════════
 	// REORGANIZE PARTITION
 	// Copies data between partition definitions. Does not rebuild the full table.
@@ -415,6 +429,59 @@ var ddlMatrix = map[matrixKey]DDLClassification{
 	{parser.ChangeIndexType, V8_0_Full}:    {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "INSTANT, metadata-only. InnoDB always uses B-tree for secondary indexes; the USING clause is stored in the data dictionary only."},
 	{parser.ChangeIndexType, V8_4_LTS}:     {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "INSTANT, metadata-only. InnoDB always uses B-tree for secondary indexes; the USING clause is stored in the data dictionary only."},
 
+	// ═══════════════════════════════════════════════════
+	// CHANGE INDEX VISIBILITY — ALTER INDEX <idx> VISIBLE | INVISIBLE
+	// Only the data dictionary flag changes: the index is still maintained on every write,
+	// the optimizer just stops (or starts) using it.
+	// ═══════════════════════════════════════════════════
+	{parser.ChangeIndexVisibility, V8_0_Early}:   {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "INPLACE, metadata-only. INSTANT algorithm not available before 8.0.12. The index is still maintained on writes; only optimizer use changes."},
+	{parser.ChangeIndexVisibility, V8_0_Instant}: {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "INSTANT, metadata-only. The index is still maintained on writes; only optimizer use changes."},
+	{parser.ChangeIndexVisibility, V8_0_Full}:    {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "INSTANT, metadata-only. The index is still maintained on writes; only optimizer use changes."},
+	{parser.ChangeIndexVisibility, V8_4_LTS}:     {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "INSTANT, metadata-only. The index is still maintained on writes; only optimizer use changes."},
+
+	// ═══════════════════════════════════════════════════
+	// ENGINE_ATTRIBUTE / SECONDARY_ENGINE_ATTRIBUTE / SECONDARY_ENGINE
+	// JSON attributes for the storage engines and the secondary engine assignment are
+	// data dictionary entries only (ENGINE_ATTRIBUTE needs 8.0.21+, checked in analyzeDDL).
+	// ═══════════════════════════════════════════════════
+	{parser.EngineAttribute, V8_0_Early}:   {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "INPLACE, metadata-only. INSTANT algorithm not available before 8.0.12. Only the data dictionary entry changes."},
+	{parser.EngineAttribute, V8_0_Instant}: {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "INSTANT, metadata-only. Only the data dictionary entry changes."},
+	{parser.EngineAttribute, V8_0_Full}:    {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "INSTANT, metadata-only. Only the data dictionary entry changes."},
+	{parser.EngineAttribute, V8_4_LTS}:     {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "INSTANT, metadata-only. Only the data dictionary entry changes."},
+	{parser.SecondaryEngine, V8_0_Early}:   {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "INPLACE, metadata-only. INSTANT algorithm not available before 8.0.12. Defines the secondary engine; no data is loaded until SECONDARY_LOAD."},
+	{parser.SecondaryEngine, V8_0_Instant}: {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "INSTANT, metadata-only. Defines the secondary engine; no data is loaded until SECONDARY_LOAD."},
+	{parser.SecondaryEngine, V8_0_Full}:    {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "INSTANT, metadata-only. Defines the secondary engine; no data is loaded until SECONDARY_LOAD."},
+	{parser.SecondaryEngine, V8_4_LTS}:     {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "INSTANT, metadata-only. Defines the secondary engine; no data is loaded until SECONDARY_LOAD."},
+
+	// ═══════════════════════════════════════════════════
+	// SECONDARY_LOAD / SECONDARY_UNLOAD (HeatWave)
+	// Loading reads the whole table from InnoDB into the secondary engine's cluster; the
+	// InnoDB table itself isn't modified or locked against DML. Unloading only drops the
+	// secondary copy.
+	// ═══════════════════════════════════════════════════
+	{parser.SecondaryLoad, V8_0_Early}:     {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "Copies the table into the secondary engine. The InnoDB table isn't modified and DML continues, but the load reads every row: long-running and I/O- and CPU-heavy on a large table."},
+	{parser.SecondaryLoad, V8_0_Instant}:   {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "Copies the table into the secondary engine. The InnoDB table isn't modified and DML continues, but the load reads every row: long-running and I/O- and CPU-heavy on a large table."},
+	{parser.SecondaryLoad, V8_0_Full}:      {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "Copies the table into the secondary engine. The InnoDB table isn't modified and DML continues, but the load reads every row: long-running and I/O- and CPU-heavy on a large table."},
+	{parser.SecondaryLoad, V8_4_LTS}:       {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "Copies the table into the secondary engine. The InnoDB table isn't modified and DML continues, but the load reads every row: long-running and I/O- and CPU-heavy on a large table."},
+	{parser.SecondaryUnload, V8_0_Early}:   {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "Removes the table's copy from the secondary engine. The InnoDB table isn't touched; queries offloaded to the secondary engine run on InnoDB again."},
+	{parser.SecondaryUnload, V8_0_Instant}: {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "Removes the table's copy from the secondary engine. The InnoDB table isn't touched; queries offloaded to the secondary engine run on InnoDB again."},
+	{parser.SecondaryUnload, V8_0_Full}:    {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "Removes the table's copy from the secondary engine. The InnoDB table isn't touched; queries offloaded to the secondary engine run on InnoDB again."},
+	{parser.SecondaryUnload, V8_4_LTS}:     {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "Removes the table's copy from the secondary engine. The InnoDB table isn't touched; queries offloaded to the secondary engine run on InnoDB again."},
+
+	// ═══════════════════════════════════════════════════
+	// PARTITIONING SCHEME — REMOVE PARTITIONING, PARTITION BY ...
+	// Unlike the per-partition operations, these redistribute every row into a new
+	// table layout: always a full COPY with writes blocked.
+	// ═══════════════════════════════════════════════════
+	{parser.RemovePartitioning, V8_0_Early}:   {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true, Notes: "Copies every row into an unpartitioned table. Writes are blocked for the whole copy."},
+	{parser.RemovePartitioning, V8_0_Instant}: {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true, Notes: "Copies every row into an unpartitioned table. Writes are blocked for the whole copy."},
+	{parser.RemovePartitioning, V8_0_Full}:    {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true, Notes: "Copies every row into an unpartitioned table. Writes are blocked for the whole copy."},
+	{parser.RemovePartitioning, V8_4_LTS}:     {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true, Notes: "Copies every row into an unpartitioned table. Writes are blocked for the whole copy."},
+	{parser.RepartitionTable, V8_0_Early}:     {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true, Notes: "Copies every row into the new partitions. Writes are blocked for the whole copy."},
+	{parser.RepartitionTable, V8_0_Instant}:   {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true, Notes: "Copies every row into the new partitions. Writes are blocked for the whole copy."},
+	{parser.RepartitionTable, V8_0_Full}:      {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true, Notes: "Copies every row into the new partitions. Writes are blocked for the whole copy."},
+	{parser.RepartitionTable, V8_4_LTS}:       {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true, Notes: "Copies every row into the new partitions. Writes are blocked for the whole copy."},
+
 	// ═══════════════════════════════════════════════════
 	// REPLACE PRIMARY KEY (§2.3) — DROP PRIMARY KEY + ADD PRIMARY KEY
 	// The combined DROP+ADD PK is handled as a single InnoDB operation: INPLACE, LOCK=NONE,
@@ -435,6 +502,20 @@ var ddlMatrix = map[matrixKey]DDLClassification{
 	{parser.OptimizeTable, V8_0_Full}:    {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: true, Notes: "Mapped to ALTER TABLE ... FORCE internally. INPLACE with full table rebuild. Reclaims fragmented space and resets TOTAL_ROW_VERSIONS counter."},
 	{parser.OptimizeTable, V8_4_LTS}:     {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: true, Notes: "Mapped to ALTER TABLE ... FORCE internally. INPLACE with full table rebuild. Reclaims fragmented space and resets TOTAL_ROW_VERSIONS counter."},
 
+	// ═══════════════════════════════════════════════════
+	// ANALYZE TABLE and CHECK TABLE
+	// Neither changes the table. ANALYZE samples index pages to refresh the statistics;
+	// CHECK reads every index under a read lock that blocks writes.
+	// ═══════════════════════════════════════════════════
+	{parser.AnalyzeTable, V8_0_Early}:   {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "Refreshes index statistics by sampling innodb_stats_persistent_sample_pages pages per index: fast at any table size. Marks the table for a flush, so new queries wait for running ones to finish. Written to the binary log unless NO_WRITE_TO_BINLOG/LOCAL."},
+	{parser.AnalyzeTable, V8_0_Instant}: {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "Refreshes index statistics by sampling innodb_stats_persistent_sample_pages pages per index: fast at any table size. Before 8.0.24 it marks the table for a flush, so new queries wait for running ones to finish. Written to the binary log unless NO_WRITE_TO_BINLOG/LOCAL."},
+	{parser.AnalyzeTable, V8_0_Full}:    {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "Refreshes index statistics by sampling innodb_stats_persistent_sample_pages pages per index: fast at any table size. Written to the binary log unless NO_WRITE_TO_BINLOG/LOCAL."},
+	{parser.AnalyzeTable, V8_4_LTS}:     {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "Refreshes index statistics by sampling innodb_stats_persistent_sample_pages pages per index: fast at any table size. Written to the binary log unless NO_WRITE_TO_BINLOG/LOCAL."},
+	{parser.CheckTable, V8_0_Early}:     {Algorithm: AlgoInplace, Lock: LockShared, RebuildsTable: false, Notes: "Reads every index to verify it, under a read lock that blocks writes for the whole scan: duration grows with table size. Not written to the binary log."},
+	{parser.CheckTable, V8_0_Instant}:   {Algorithm: AlgoInplace, Lock: LockShared, RebuildsTable: false, Notes: "Reads every index to verify it, under a read lock that blocks writes for the whole scan: duration grows with table size. Not written to the binary log."},
+	{parser.CheckTable, V8_0_Full}:      {Algorithm: AlgoInplace, Lock: LockShared, RebuildsTable: false, Notes: "Reads every index to verify it, under a read lock that blocks writes for the whole scan: duration grows with table size. Not written to the binary log."},
+	{parser.CheckTable, V8_4_LTS}:       {Algorithm: AlgoInplace, Lock: LockShared, RebuildsTable: false, Notes: "Reads every index to verify it, under a read lock that blocks writes for the whole scan: duration grows with table size. Not written to the binary log."},
+
 	// ═══════════════════════════════════════════════════
 	// ALTER TABLESPACE RENAME (§7.1)
 	// Metadata-only rename of a general tablespace. INPLACE, LOCK=NONE.
@@ -444,13 +525,37 @@ var ddlMatrix = map[matrixKey]DDLClassification{
 	{parser.AlterTablespace, V8_0_Instant}: {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "INPLACE, metadata-only. Requires MySQL 8.0.21+; statement is rejected on 8.0.12-8.0.20. Renames the tablespace entry in the data dictionary. Does not accept ALGORITHM= clause explicitly."},
 	{parser.AlterTablespace, V8_0_Full}:    {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "INPLACE, metadata-only. Renames the tablespace entry in the data dictionary. Does not accept ALGORITHM= clause explicitly."},
 	{parser.AlterTablespace, V8_4_LTS}:     {Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: false, Notes: "INPLACE, metadata-only. Renames the tablespace entry in the data dictionary. Does not accept ALGORITHM= clause explicitly."},
+
+	// ═══════════════════════════════════════════════════
+	// CREATE TABLE / ... LIKE / ... AS SELECT
+	// A plain CREATE TABLE and LIKE only write a definition. AS SELECT is a data copy: the
+	// cost scales with the SELECT result, not with any ALTER algorithm.
+	// ═══════════════════════════════════════════════════
+	{parser.CreateTable, V8_0_Early}:   {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "Metadata-only. Creates a new, empty table; no existing data is touched."},
+	{parser.CreateTable, V8_0_Instant}: {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "Metadata-only. Creates a new, empty table; no existing data is touched."},
+	{parser.CreateTable, V8_0_Full}:    {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "Metadata-only. Creates a new, empty table; no existing data is touched."},
+	{parser.CreateTable, V8_4_LTS}:     {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "Metadata-only. Creates a new, empty table; no existing data is touched."},
+
+	{parser.CreateTableLike, V8_0_Early}:   {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "Metadata-only. Creates an empty table with the source table's definition; no rows are copied."},
+	{parser.CreateTableLike, V8_0_Instant}: {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "Metadata-only. Creates an empty table with the source table's definition; no rows are copied."},
+	{parser.CreateTableLike, V8_0_Full}:    {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "Metadata-only. Creates an empty table with the source table's definition; no rows are copied."},
+	{parser.CreateTableLike, V8_4_LTS}:     {Algorithm: AlgoInstant, Lock: LockNone, RebuildsTable: false, Notes: "Metadata-only. Creates an empty table with the source table's definition; no rows are copied."},
+
+	{parser.CreateTableAsSelect, V8_0_Early}:   {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: false, Notes: "Copies every row returned by the SELECT into a new table in one statement. InnoDB takes shared locks on the scanned source rows (unless READ COMMITTED), blocking writes to them until commit."},
+	{parser.CreateTableAsSelect, V8_0_Instant}: {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: false, Notes: "Copies every row returned by the SELECT into a new table in one statement. InnoDB takes shared locks on the scanned source rows (unless READ COMMITTED), blocking writes to them until commit."},
+	{parser.CreateTableAsSelect, V8_0_Full}:    {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: false, Notes: "Copies every row returned by the SELECT into a new table in one statement. InnoDB takes shared locks on the scanned source rows (unless READ COMMITTED), blocking writes to them until commit."},
+	{parser.CreateTableAsSelect, V8_4_LTS}:     {Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: false, Notes: "Copies every row returned by the SELECT into a new table in one statement. InnoDB takes shared locks on the scanned source rows (unless READ COMMITTED), blocking writes to them until commit."},
 }
 
-// ClassifyDDL looks up the DDL operation in the matrix.
+// ClassifyDDL looks up the DDL operation in the matrix, after the overrides set with
+// SetMatrixOverrides.
 func ClassifyDDL(op parser.DDLOperation, major, minor, patch int) DDLClassification {
 	vr := classifyVersion(major, minor, patch)
 	key := matrixKey{Op: op, Version: vr}
 
+	if c, ok := overriddenClassification(key); ok {
+		return c
+	}
 	if c, ok := ddlMatrix[key]; ok {
 		return c
 	}
@@ -351,7 +351,7 @@ func TestSpec_3_14_DropColumn_IndexedColumn_IsInplace(t *testing.T) {
 	// A warning should mention the index name.
 	found := false
 	for _, w := range result.Warnings {
-		if strings.Contains(w, "idx_status") {
+		if strings.Contains(w.Message, "idx_status") {
 			found = true
 			break
 		}
@@ -1232,7 +1232,7 @@ func TestSpec_7_2_TableEncryption_EmitsKeyringWarning(t *testing.T) {
 
 	hasKeyringWarn := false
 	for _, w := range result.Warnings {
-		if strings.Contains(w, "keyring") {
+		if strings.Contains(w.Message, "keyring") {
 			hasKeyringWarn = true
 			break
 		}
@@ -1330,6 +1330,74 @@ func TestSpec_7_1_AlterTablespace_AnalyzeSafe(t *testing.T) {
 	}
 }
 
+// View, routine, trigger and event definitions are SAFE + DIRECT, not the unparsable fallback.
+func TestAnalyze_ObjectDefinition_IsSafe(t *testing.T) {
+	for _, objType := range []string{"VIEW", "PROCEDURE", "FUNCTION", "TRIGGER", "EVENT"} {
+		input := Input{
+			Parsed: &parser.ParsedSQL{
+				Type:       parser.DDL,
+				RawSQL:     "CREATE " + objType + " obj ...",
+				DDLOp:      parser.ObjectDefinition,
+				ObjectType: objType,
+				ObjectName: "obj",
+			},
+			Meta:    &mysql.TableMetadata{},
+			Version: v8_0_35,
+			Topo:    standaloneInfo(),
+		}
+		result := Analyze(input)
+		if result.Risk != RiskSafe {
+			t.Errorf("%s: Risk = %q, want SAFE", objType, result.Risk)
+		}
+		if result.Method != ExecDirect {
+			t.Errorf("%s: Method = %q, want DIRECT", objType, result.Method)
+		}
+		want := "This is a " + objType + " definition; it is metadata-only and safe"
+		if !strings.Contains(result.Recommendation, want) {
+			t.Errorf("%s: Recommendation = %q, want it to contain %q", objType, result.Recommendation, want)
+		}
+		if containsWarning(result.WarningMessages(), "could not be fully parsed") {
+			t.Errorf("%s: should not get the unparsable warning, got %v", objType, result.Warnings)
+		}
+		if gotTrigger := containsWarning(result.WarningMessages(), "metadata lock"); gotTrigger != (objType == "TRIGGER") {
+			t.Errorf("%s: metadata lock warning = %v, want %v", objType, gotTrigger, objType == "TRIGGER")
+		}
+	}
+}
+
+// GRANT, ALTER USER and the like are out of scope: SAFE + DIRECT with a scope message, not
+// the unparsable fallback, and with no algorithm, command or password.
+func TestAnalyze_AccountManagement_IsOutOfScope(t *testing.T) {
+	for _, sql := range []string{"GRANT SELECT ON shop.* TO 'app'@'%'", "ALTER USER 'app'@'%' IDENTIFIED BY 'secret'"} {
+		parsed, err := parser.Parse(sql)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", sql, err)
+		}
+		result, err := AnalyzeOffline(parsed, nil, Options{Version: &v8_0_35})
+		if err != nil {
+			t.Fatalf("%s: %v", sql, err)
+		}
+		if result.Risk != RiskSafe || result.Method != ExecDirect {
+			t.Errorf("%s: Risk/Method = %s/%s, want SAFE/DIRECT", sql, result.Risk, result.Method)
+		}
+		if !strings.Contains(result.Recommendation, "account-management statement: dbsafe analyzes schema changes (DDL) and data changes (DML)") {
+			t.Errorf("%s: Recommendation = %q", sql, result.Recommendation)
+		}
+		if result.HasWarning(WarnDDLUnparsed) {
+			t.Errorf("%s: should not get the unparsable warning, got %v", sql, result.Warnings)
+		}
+		if result.Classification.Algorithm != "" || result.Classification.Lock != "" {
+			t.Errorf("%s: Classification = %+v, want no algorithm or lock", sql, result.Classification)
+		}
+		if cmd := result.Command(); cmd != "" {
+			t.Errorf("%s: Command() = %q, want none", sql, cmd)
+		}
+		if strings.Contains(result.Statement, "secret") {
+			t.Errorf("%s: Statement = %q, want the password redacted", sql, result.Statement)
+		}
+	}
+}
+
 // =============================================================
 // MODIFY COLUMN charset change (Issue #26)
 // =============================================================
@@ -1364,7 +1432,7 @@ func TestSpec_ModifyColumn_CharsetChange_IsCopy(t *testing.T) {
 	// Should have a charset-change warning
 	hasCharsetWarn := false
 	for _, w := range result.Warnings {
-		if strings.Contains(w, "charset change") {
+		if strings.Contains(w.Message, "charset change") {
 			hasCharsetWarn = true
 			break
 		}
@@ -1500,7 +1568,7 @@ func TestSpec_9_2_MultiOp_AddPrimaryKey_NullableColumn_IsCopy(t *testing.T) {
 	// Warning should mention the nullable column
 	found := false
 	for _, w := range result.Warnings {
-		if strings.Contains(w, "tenant_id") && strings.Contains(w, "nullable") {
+		if strings.Contains(w.Message, "tenant_id") && strings.Contains(w.Message, "nullable") {
 			found = true
 			break
 		}
@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/parser"
+)
+
+// DiffStep is one ALTER TABLE of a schema diff together with its analysis.
+type DiffStep struct {
+	SQL    string
+	Result *Result
+}
+
+// DiffPlan is the ordered migration from one CREATE TABLE definition to another.
+type DiffPlan struct {
+	Database string
+	Table    string
+	Steps    []DiffStep
+	Risk     RiskLevel // highest risk among the steps; SAFE when there are none
+	Warnings []Warning // warnings about the migration as a whole
+}
+
+var riskRank = map[RiskLevel]int{RiskSafe: 0, RiskCaution: 1, RiskDangerous: 2}
+
+// AnalyzeDiff computes the ALTER TABLE statements that turn currentSQL into desiredSQL
+// (both CREATE TABLE statements for the same table) and analyzes each one against the
+// live table, the same way AnalyzeStatement does. Topology, metadata and version are
+// loaded once; later steps see the columns and indexes added or dropped by earlier ones.
+// With a nil db and opts.Version set the analysis runs offline, like AnalyzeOffline,
+// against currentSQL as the table's definition.
+func AnalyzeDiff(ctx context.Context, db *sql.DB, currentSQL, desiredSQL string, opts Options) (*DiffPlan, error) {
+	statements, err := parser.DiffCreateTables(currentSQL, desiredSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &DiffPlan{Risk: RiskSafe}
+	offline := db == nil && opts.Version != nil
+	var input Input
+	var dropped, added []string
+	for i, stmt := range statements {
+		parsed, err := parser.Parse(stmt)
+		if err != nil {
+			return nil, fmt.Errorf("parsing generated statement %q: %w", stmt, err)
+		}
+		if i == 0 {
+			database := opts.Database
+			if database == "" {
+				database = parsed.Database
+			}
+			if offline {
+				if input, err = offlineDiffInput(currentSQL, parsed, database, opts); err != nil {
+					return nil, err
+				}
+			} else {
+				if database == "" {
+					return nil, fmt.Errorf("database not specified: qualify the table (e.g. mydb.users) or set Options.Database")
+				}
+				if input, err = loadInput(ctx, db, parsed, database, opts); err != nil {
+					return nil, err
+				}
+			}
+			plan.Database, plan.Table = database, parsed.Table
+		} else {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			input.Parsed = parsed
+		}
+
+		result := analyze(input)
+		if offline {
+			addOfflineWarning(input, result)
+		}
+		observe(input.Observer, result)
+		plan.Steps = append(plan.Steps, DiffStep{SQL: stmt, Result: result})
+		if riskRank[result.Risk] > riskRank[plan.Risk] {
+			plan.Risk = result.Risk
+		}
+		input.Meta = metaAfterStep(input.Meta, parsed)
+
+		switch parsed.DDLOp {
+		case parser.DropColumn:
+			dropped = append(dropped, parsed.ColumnName)
+		case parser.AddColumn:
+			added = append(added, parsed.ColumnName)
+		}
+	}
+
+	if warn, ok := possibleRenameWarning(dropped, added); ok {
+		plan.Warnings = append(plan.Warnings, newWarning(WarnPossibleRename, warn))
+	}
+	return plan, nil
+}
+
+// offlineDiffInput builds the input of the first diff step without a server, from the
+// current definition and opts.Version (set).
+func offlineDiffInput(currentSQL string, parsed *parser.ParsedSQL, database string, opts Options) (Input, error) {
+	def, err := parser.ParseTableDefinition(currentSQL)
+	if err != nil {
+		return Input{}, fmt.Errorf("current definition: %w", err)
+	}
+	return offlineInput(parsed, MetadataFromDefinition(def, currentSQL), database, opts), nil
+}
+
+// metaAfterStep returns a copy of meta with the columns and indexes added, dropped or
+// renamed by parsed applied, so the next step is validated against the table as it
+// will be by then. Sizes and everything else are left as they were.
+func metaAfterStep(meta *mysql.TableMetadata, parsed *parser.ParsedSQL) *mysql.TableMetadata {
+	next := *meta
+	next.Columns = slices.Clone(meta.Columns)
+	next.Indexes = slices.Clone(meta.Indexes)
+
+	for _, sub := range parsed.SubOperations {
+		switch sub.Op {
+		case parser.AddColumn:
+			next.Columns = append(next.Columns, mysql.ColumnInfo{
+				Name:     sub.ColumnName,
+				Type:     sub.NewColumnType,
+				Nullable: !sub.HasNotNull,
+			})
+		case parser.DropColumn:
+			next.Columns = slices.DeleteFunc(next.Columns, func(c mysql.ColumnInfo) bool {
+				return strings.EqualFold(c.Name, sub.ColumnName)
+			})
+		case parser.ChangeColumn:
+			for i := range next.Columns {
+				if strings.EqualFold(next.Columns[i].Name, sub.OldColumnName) {
+					next.Columns[i].Name = sub.ColumnName
+				}
+			}
+		case parser.AddIndex, parser.AddFulltextIndex, parser.AddSpatialIndex:
+			idx := mysql.IndexInfo{Name: sub.IndexName, Columns: sub.IndexColumns, NonUnique: !sub.IsUniqueIndex, Type: "BTREE"}
+			switch sub.Op {
+			case parser.AddFulltextIndex:
+				idx.Type = "FULLTEXT"
+			case parser.AddSpatialIndex:
+				idx.Type = "SPATIAL"
+			}
+			next.Indexes = append(next.Indexes, idx)
+		case parser.DropIndex:
+			next.Indexes = slices.DeleteFunc(next.Indexes, func(idx mysql.IndexInfo) bool {
+				return strings.EqualFold(idx.Name, sub.IndexName)
+			})
+		}
+	}
+	return &next
+}
+
+// possibleRenameWarning flags a diff that drops some columns and adds others: the diff
+// can't tell a rename from a replacement, and DROP + ADD loses the column's data.
+func possibleRenameWarning(dropped, added []string) (string, bool) {
+	if len(dropped) == 0 || len(added) == 0 {
+		return "", false
+	}
+	return fmt.Sprintf(
+		"The diff drops `%s` and adds `%s`. If that is a rename, the DROP COLUMN destroys the existing data: "+
+			"use ALTER TABLE ... RENAME COLUMN instead of the generated DROP and ADD.",
+		strings.Join(dropped, "`, `"), strings.Join(added, "`, `"),
+	), true
+}
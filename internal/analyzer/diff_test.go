@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/parser"
+)
+
+func TestAnalyzeDiff_NoDifferences(t *testing.T) {
+	// Equivalent definitions need no server round trip.
+	plan, err := AnalyzeDiff(context.Background(), nil,
+		"CREATE TABLE shop.users (id INT PRIMARY KEY)", "CREATE TABLE shop.users (id INT PRIMARY KEY)", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Steps) != 0 || plan.Risk != RiskSafe {
+		t.Errorf("plan = %+v, want no steps and SAFE", plan)
+	}
+}
+
+func TestAnalyzeDiff_Errors(t *testing.T) {
+	_, err := AnalyzeDiff(context.Background(), nil, "CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)", Options{})
+	if err == nil || !strings.Contains(err.Error(), "different tables") {
+		t.Errorf("error = %v, want different tables", err)
+	}
+
+	_, err = AnalyzeDiff(context.Background(), nil, "CREATE TABLE a (id INT)", "CREATE TABLE a (id INT, x INT)", Options{})
+	if err == nil || !strings.Contains(err.Error(), "database not specified") {
+		t.Errorf("error = %v, want database not specified", err)
+	}
+}
+
+func TestMetaAfterStep(t *testing.T) {
+	meta := &mysql.TableMetadata{
+		Table:    "users",
+		RowCount: 1000,
+		Columns:  []mysql.ColumnInfo{{Name: "id"}, {Name: "legacy"}, {Name: "nick"}},
+		Indexes:  []mysql.IndexInfo{{Name: "PRIMARY", Columns: []string{"id"}}, {Name: "idx_legacy", Columns: []string{"legacy"}}},
+	}
+	apply := func(meta *mysql.TableMetadata, sql string) *mysql.TableMetadata {
+		parsed, err := parser.Parse(sql)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", sql, err)
+		}
+		return metaAfterStep(meta, parsed)
+	}
+
+	next := apply(meta, "ALTER TABLE users DROP INDEX idx_legacy")
+	next = apply(next, "ALTER TABLE users DROP COLUMN legacy")
+	next = apply(next, "ALTER TABLE users CHANGE COLUMN nick nickname VARCHAR(50)")
+	next = apply(next, "ALTER TABLE users ADD COLUMN email VARCHAR(255) NOT NULL")
+	next = apply(next, "ALTER TABLE users ADD UNIQUE KEY uk_email (email)")
+
+	var cols []string
+	for _, c := range next.Columns {
+		cols = append(cols, c.Name)
+	}
+	if got := strings.Join(cols, ","); got != "id,nickname,email" {
+		t.Errorf("columns = %s, want id,nickname,email", got)
+	}
+	if email := findColumnInfo(next, "email"); email == nil || email.Nullable {
+		t.Errorf("email column = %+v, want NOT NULL", email)
+	}
+	if len(next.Indexes) != 2 || next.Indexes[1].Name != "uk_email" || next.Indexes[1].NonUnique {
+		t.Errorf("indexes = %+v, want PRIMARY and unique uk_email", next.Indexes)
+	}
+	if next.RowCount != 1000 {
+		t.Errorf("RowCount = %d, want 1000 (unchanged)", next.RowCount)
+	}
+
+	// The original metadata is left untouched.
+	if len(meta.Columns) != 3 || meta.Columns[2].Name != "nick" || len(meta.Indexes) != 2 {
+		t.Errorf("original metadata was modified: %+v", meta)
+	}
+}
+
+func TestPossibleRenameWarning(t *testing.T) {
+	if _, ok := possibleRenameWarning([]string{"legacy"}, nil); ok {
+		t.Error("a drop without an add should not warn")
+	}
+	warn, ok := possibleRenameWarning([]string{"nick"}, []string{"nickname"})
+	if !ok || !strings.Contains(warn, "drops `nick` and adds `nickname`") || !strings.Contains(warn, "RENAME COLUMN") {
+		t.Errorf("warning = %q, want a rename hint for nick -> nickname", warn)
+	}
+}
@@ -41,7 +41,7 @@ func TestAnalyze_OtherDDL(t *testing.T) {
 
 	foundWarning := false
 	for _, warning := range result.Warnings {
-		if strings.Contains(warning, "could not be fully parsed") {
+		if strings.Contains(warning.Message, "could not be fully parsed") {
 			foundWarning = true
 			break
 		}
@@ -149,7 +149,7 @@ func TestAnalyze_ColumnValidation(t *testing.T) {
 
 			foundWarning := false
 			for _, w := range result.Warnings {
-				if strings.Contains(strings.ToLower(w), strings.ToLower(tt.wantWarning)) {
+				if strings.Contains(strings.ToLower(w.Message), strings.ToLower(tt.wantWarning)) {
 					foundWarning = true
 					break
 				}
@@ -193,7 +193,7 @@ func TestAnalyze_ChangeColumn_SameNameAllowed(t *testing.T) {
 
 	// Should not have "already exists" warning
 	for _, w := range result.Warnings {
-		if strings.Contains(w, "already exists") {
+		if strings.Contains(w.Message, "already exists") {
 			t.Errorf("should not warn about column already existing when renaming to itself: %s", w)
 		}
 	}
@@ -354,6 +354,178 @@ func TestGenerateChunkedScript_ChunkCount(t *testing.T) {
 	}
 }
 
+func TestGenerateChunkedScript_SleepSeconds(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:        parser.DML,
+			DMLOp:       parser.Delete,
+			Database:    "db",
+			Table:       "test",
+			WhereClause: "1=1",
+		},
+		Meta:      &mysql.TableMetadata{Database: "db", Table: "test"},
+		ChunkSize: 1000,
+	}
+	sleep := 2.5
+	input.SleepSeconds = &sleep
+	result := &Result{Database: "db", Table: "test", AffectedRows: 5000}
+
+	generateChunkedScript(input, result)
+
+	if !strings.Contains(result.GeneratedScript, "SET @sleep_time = 2.5;") {
+		t.Errorf("script should use the configured sleep, got:\n%s", result.GeneratedScript)
+	}
+	if !strings.Contains(result.GeneratedScript, "-- Tunables:") {
+		t.Error("script should document its tunables at the top")
+	}
+
+	sleep = 0
+	generateChunkedScript(input, result)
+	if !strings.Contains(result.GeneratedScript, "SET @sleep_time = 0;") {
+		t.Errorf("script should honor an explicit 0s sleep, got:\n%s", result.GeneratedScript)
+	}
+
+	input.SleepSeconds = nil
+	generateChunkedScript(input, result)
+	if !strings.Contains(result.GeneratedScript, "SET @sleep_time = 0.5;") {
+		t.Error("script should default to 0.5s sleep")
+	}
+}
+
+func TestGenerateChunkedScript_LagAwareDelete(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:        parser.DML,
+			DMLOp:       parser.Delete,
+			RawSQL:      "DELETE FROM logs WHERE created_at < '2020-01-01'",
+			Database:    "mydb",
+			Table:       "logs",
+			WhereClause: "created_at < '2020-01-01'",
+		},
+		Meta:          &mysql.TableMetadata{Database: "mydb", Table: "logs"},
+		ChunkSize:     5000,
+		MaxReplicaLag: 10,
+	}
+	sleep := 1.0
+	input.SleepSeconds = &sleep
+	result := &Result{Database: "mydb", Table: "logs", AffectedRows: 100000}
+
+	generateChunkedScript(input, result)
+
+	script := result.GeneratedScript
+	for _, want := range []string{
+		"#!/usr/bin/env bash",
+		`BATCH_SIZE="${BATCH_SIZE:-5000}"`,
+		`SLEEP_SECONDS="${SLEEP_SECONDS:-1}"`,
+		`MAX_LAG="${MAX_LAG:-10}"`,
+		"SHOW REPLICA STATUS",
+		"Seconds_Behind_Source",
+		"DELETE FROM `mydb`.`logs`",
+		"created_at < '2020-01-01'",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("lag-aware script missing %q", want)
+		}
+	}
+	if !strings.HasSuffix(result.ScriptPath, ".sh") {
+		t.Errorf("ScriptPath = %q, want a .sh file", result.ScriptPath)
+	}
+}
+
+func TestGenerateChunkedScript_LagAwareUpdate(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:        parser.DML,
+			DMLOp:       parser.Update,
+			RawSQL:      "UPDATE users SET active = 0 WHERE last_login < '2020-01-01';",
+			Database:    "prod",
+			Table:       "users",
+			WhereClause: "last_login < '2020-01-01'",
+			SetClause:   "active = 0",
+		},
+		Meta:          &mysql.TableMetadata{Database: "prod", Table: "users"},
+		ChunkSize:     10000,
+		MaxReplicaLag: 5,
+	}
+	result := &Result{Database: "prod", Table: "users", AffectedRows: 500000}
+
+	generateChunkedScript(input, result)
+
+	script := result.GeneratedScript
+	if !strings.Contains(script, "UPDATE `prod`.`users` SET active = 0\nWHERE (last_login < '2020-01-01')\nAND `id` BETWEEN") {
+		t.Errorf("lag-aware UPDATE should append the id range to the statement, got:\n%s", script)
+	}
+	if !strings.Contains(script, "primary key") {
+		t.Error("UPDATE script should mention primary key requirement")
+	}
+}
+
+func TestGenerateChunkedScript_UpdatePrimaryKey(t *testing.T) {
+	input := Input{
+		Parsed: &parser.ParsedSQL{
+			Type:        parser.DML,
+			DMLOp:       parser.Update,
+			RawSQL:      "UPDATE users SET active = 0 WHERE last_login < '2020-01-01'",
+			Database:    "prod",
+			Table:       "users",
+			WhereClause: "last_login < '2020-01-01'",
+			SetClause:   "active = 0",
+		},
+		Meta: &mysql.TableMetadata{
+			Database: "prod",
+			Table:    "users",
+			Columns:  []mysql.ColumnInfo{{Name: "user_id", Type: "bigint unsigned"}, {Name: "last_login", Type: "datetime"}},
+			Indexes:  []mysql.IndexInfo{{Name: "PRIMARY", Columns: []string{"user_id"}}},
+		},
+		ChunkSize: 10000,
+	}
+	result := &Result{Database: "prod", Table: "users", AffectedRows: 500000}
+
+	generateChunkedScript(input, result)
+	if !strings.Contains(result.GeneratedScript, "SELECT MIN(`user_id`) FROM `prod`.`users`") ||
+		!strings.Contains(result.GeneratedScript, "AND `user_id` BETWEEN @current") {
+		t.Errorf("UPDATE script should walk the user_id primary key, got:\n%s", result.GeneratedScript)
+	}
+
+	input.MaxReplicaLag = 5
+	generateChunkedScript(input, result)
+	if !strings.Contains(result.GeneratedScript, "\nAND `user_id` BETWEEN") || strings.Contains(result.GeneratedScript, "MIN(id)") {
+		t.Errorf("lag-aware UPDATE script should walk the user_id primary key, got:\n%s", result.GeneratedScript)
+	}
+}
+
+// An OR in the WHERE must stay grouped: "a OR b AND pk BETWEEN ..." would update every row
+// matching a in every chunk.
+func TestGenerateChunkedScript_UpdateOrPredicate(t *testing.T) {
+	parsed, err := parser.Parse("UPDATE t SET n = n + 1 WHERE status = 'a' OR status = 'b'")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	parsed.Database = "db"
+	input := Input{
+		Parsed: parsed,
+		Meta: &mysql.TableMetadata{
+			Database: "db",
+			Table:    "t",
+			Columns:  []mysql.ColumnInfo{{Name: "id", Type: "int"}},
+			Indexes:  []mysql.IndexInfo{{Name: "PRIMARY", Columns: []string{"id"}}},
+		},
+		ChunkSize: 1000,
+	}
+	result := &Result{Database: "db", Table: "t", AffectedRows: 500000}
+
+	generateChunkedScript(input, result)
+	if !strings.Contains(result.GeneratedScript, "UPDATE `db`.`t` SET n = n + 1\n    WHERE (`status` = 'a' or `status` = 'b')\n      AND `id` BETWEEN") {
+		t.Errorf("UPDATE chunk should wrap the OR predicate in parentheses, got:\n%s", result.GeneratedScript)
+	}
+
+	input.MaxReplicaLag = 5
+	generateChunkedScript(input, result)
+	if !strings.Contains(result.GeneratedScript, "UPDATE `db`.`t` SET n = n + 1\nWHERE (`status` = 'a' or `status` = 'b')\nAND `id` BETWEEN") {
+		t.Errorf("lag-aware UPDATE chunk should wrap the OR predicate in parentheses, got:\n%s", result.GeneratedScript)
+	}
+}
+
 func TestApplyTopologyWarnings_GaleraWriteSetExceeds(t *testing.T) {
 	input := Input{
 		Parsed: &parser.ParsedSQL{
@@ -377,7 +549,7 @@ func TestApplyTopologyWarnings_GaleraWriteSetExceeds(t *testing.T) {
 	// Should warn about exceeding write-set size
 	foundWarning := false
 	for _, w := range result.ClusterWarnings {
-		if strings.Contains(w, "EXCEEDS wsrep_max_ws_size") {
+		if strings.Contains(w.Message, "EXCEEDS wsrep_max_ws_size") {
 			foundWarning = true
 			break
 		}
@@ -417,7 +589,7 @@ func TestApplyTopologyWarnings_GaleraFlowControl(t *testing.T) {
 	// Should warn about flow control pressure
 	foundWarning := false
 	for _, w := range result.ClusterWarnings {
-		if strings.Contains(w, "Flow control") && strings.Contains(w, "15") {
+		if strings.Contains(w.Message, "Flow control") && strings.Contains(w.Message, "15") {
 			foundWarning = true
 			break
 		}
@@ -449,7 +621,7 @@ func TestApplyTopologyWarnings_GroupReplicationLimit(t *testing.T) {
 	// Should warn about exceeding GR transaction size limit
 	foundWarning := false
 	for _, w := range result.ClusterWarnings {
-		if strings.Contains(w, "EXCEEDS group_replication_transaction_size_limit") {
+		if strings.Contains(w.Message, "EXCEEDS group_replication_transaction_size_limit") {
 			foundWarning = true
 			break
 		}
@@ -480,7 +652,7 @@ func TestApplyTopologyWarnings_ReplicationLag(t *testing.T) {
 	// Should warn about replication lag
 	foundWarning := false
 	for _, w := range result.ClusterWarnings {
-		if strings.Contains(w, "lag") && strings.Contains(w, "60") {
+		if strings.Contains(w.Message, "lag") && strings.Contains(w.Message, "60") {
 			foundWarning = true
 			break
 		}
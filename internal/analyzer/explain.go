@@ -0,0 +1,212 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/parser"
+)
+
+// Explanation is the reasoning behind the classification of an operation on a version,
+// without a statement or a table: the matrix entry for the version's range, why the
+// version falls in that range, and the table- and statement-specific refinements dbsafe
+// applies on top of the matrix during a real analysis.
+type Explanation struct {
+	Operation      parser.DDLOperation
+	Version        mysql.ServerVersion
+	Range          VersionRange
+	RangeReason    string
+	Classification DDLClassification
+	Refinements    []string
+}
+
+// String returns the range's version span, e.g. "8.0.12 – 8.0.28".
+func (vr VersionRange) String() string {
+	switch vr {
+	case V8_0_Early:
+		return "8.0.0 – 8.0.11"
+	case V8_0_Instant:
+		return "8.0.12 – 8.0.28"
+	case V8_0_Full:
+		return "8.0.29+"
+	case V8_4_LTS:
+		return "8.4 LTS"
+	}
+	return "unknown"
+}
+
+// MatrixOperations returns the operations in the classification matrix, sorted.
+func MatrixOperations() []parser.DDLOperation {
+	var ops []parser.DDLOperation
+	for key := range ddlMatrix {
+		if !slices.Contains(ops, key.Op) {
+			ops = append(ops, key.Op)
+		}
+	}
+	slices.Sort(ops)
+	return ops
+}
+
+// ExplainOperation explains how op is classified on version v. op must be in the
+// classification matrix (see MatrixOperations).
+func ExplainOperation(op parser.DDLOperation, v mysql.ServerVersion) (*Explanation, error) {
+	if !slices.Contains(MatrixOperations(), op) {
+		return nil, fmt.Errorf("unknown operation %q", op)
+	}
+	vr := classifyVersion(v.Major, v.Minor, v.EffectivePatch())
+	return &Explanation{
+		Operation:      op,
+		Version:        v,
+		Range:          vr,
+		RangeReason:    versionRangeReason(v, vr),
+		Classification: ClassifyDDL(op, v.Major, v.Minor, v.EffectivePatch()),
+		Refinements:    operationRefinements(op, vr),
+	}, nil
+}
+
+// versionRangeReason says why v falls in range vr and what the range means for online DDL.
+func versionRangeReason(v mysql.ServerVersion, vr VersionRange) string {
+	var reason string
+	switch {
+	case v.Major == 5:
+		reason = fmt.Sprintf("%s predates 8.0 and has no ALGORITHM=INSTANT, so the 8.0.0 – 8.0.11 rules are the closest dbsafe has. dbsafe isn't validated against 5.7.", v.String())
+	case v.Major != 8 || (v.Minor != 0 && v.Minor != 4):
+		reason = fmt.Sprintf("%s is outside the ranges dbsafe knows, so the newest 8.0 behavior (8.0.29+) is assumed.", v.String())
+	case vr == V8_0_Early:
+		reason = "Before 8.0.12 there is no ALGORITHM=INSTANT: every ALTER is INPLACE or COPY."
+	case vr == V8_0_Instant:
+		reason = "8.0.12 introduced ALGORITHM=INSTANT, but only for ADD COLUMN as the last column (plus a few metadata-only changes). DROP COLUMN and ADD COLUMN at any position became INSTANT in 8.0.29."
+	case vr == V8_0_Full:
+		reason = "Since 8.0.29, ADD COLUMN at any position and DROP COLUMN are INSTANT, within the limit of 64 row versions per table."
+	case vr == V8_4_LTS:
+		reason = "8.4 LTS keeps the 8.0.29+ online DDL behavior: ADD COLUMN at any position and DROP COLUMN are INSTANT."
+	}
+	if v.IsAurora() && v.Patch == 0 {
+		reason = fmt.Sprintf("The MySQL version Aurora MySQL %s is compatible with isn't in the version string, so dbsafe assumes 8.0.%d, the oldest Aurora MySQL 3 base. ",
+			v.AuroraVersion, v.EffectivePatch()) + reason
+	}
+	return reason
+}
+
+// operationRefinements describes the checks analyzeDDL and classifySubOp make against the
+// statement and the live table that can change op's matrix classification or risk.
+func operationRefinements(op parser.DDLOperation, vr VersionRange) []string {
+	var r []string
+	switch op {
+	case parser.AddColumn:
+		if vr == V8_0_Instant {
+			r = append(r, "With FIRST or AFTER, the column isn't the last one, so INSTANT doesn't apply: INPLACE with a table rebuild.")
+		}
+		r = append(r,
+			"An AUTO_INCREMENT column needs INPLACE with at least a SHARED lock and a full rebuild: writes are blocked.",
+			"A STORED generated column is COPY with a SHARED lock: every row gets the computed value.",
+			"A DEFAULT (expression) is evaluated for every existing row and can rule out INSTANT.",
+			"A generated column placed with FIRST/AFTER before a generated column it references is rejected (DANGEROUS).",
+			"On a ROW_FORMAT=COMPRESSED table INSTANT isn't supported and MySQL falls back to an INPLACE rebuild.",
+			"A column that takes the worst-case in-page record over 8126 bytes (columns up to 255 bytes on DYNAMIC, a 768-byte prefix of long ones on COMPACT/REDUNDANT) fails with \"Row size too large\", even INSTANT.",
+		)
+		if vr == V8_0_Full || vr == V8_4_LTS {
+			r = append(r, "Once the table has used all 64 row versions (TOTAL_ROW_VERSIONS), INSTANT isn't possible: INPLACE with a rebuild that resets the counter. dbsafe warns from 56 on.")
+		}
+	case parser.DropColumn:
+		r = append(r,
+			"A column that is part of an index can't be dropped INSTANT: INPLACE with a table rebuild. Dropping the index first is faster.",
+			"A STORED generated column is dropped INPLACE with a table rebuild, to remove the stored values.",
+			"A column referenced by a generated column can't be dropped unless the generated column is dropped too (DANGEROUS).",
+			"A column of the partitioning expression can't be dropped: the table must be repartitioned on other columns first (DANGEROUS).",
+			"On a ROW_FORMAT=COMPRESSED table INSTANT isn't supported and MySQL falls back to an INPLACE rebuild.",
+		)
+		if vr == V8_0_Full || vr == V8_4_LTS {
+			r = append(r, "Once the table has used all 64 row versions (TOTAL_ROW_VERSIONS), INSTANT isn't possible: INPLACE with a rebuild that resets the counter. dbsafe warns from 56 on.")
+		}
+	case parser.ModifyColumn:
+		r = append(r,
+			"Appending members to the end of an ENUM or SET is INSTANT (metadata-only), as long as the storage size doesn't change.",
+			"Extending a VARCHAR within the same length-prefix size (up to 255 bytes, or beyond) is INPLACE without a rebuild.",
+			"Changing only NULL / NOT NULL, or moving the column with FIRST/AFTER, is INPLACE with a rebuild and concurrent DML.",
+			"A charset change is COPY with a SHARED lock.",
+			"Narrowing a numeric type stays COPY, and fails on existing values that don't fit: dbsafe suggests a range check.",
+			"A new generation expression is COPY for a STORED column (every value is recomputed) and INPLACE without a rebuild for a VIRTUAL one.",
+			"Setting an SRID on a spatial column is COPY, and every existing geometry must match it.",
+			"Changing the type of a column of the partitioning expression can't be done in place: COPY, and rejected if the partitioning method doesn't allow the new type (DANGEROUS).",
+			"Several changes at once (e.g. an ENUM append plus AFTER) take the most restrictive algorithm among them: dbsafe names the one that dominates.",
+		)
+	case parser.ChangeColumn:
+		r = append(r,
+			"A rename that keeps the data type uses the matrix entry; a data type change is COPY with a SHARED lock.",
+			"A column of the partitioning expression can't be renamed, and a type change repartitions every row (DANGEROUS).",
+			"Setting an SRID on a spatial column is COPY, and every existing geometry must match it.",
+			"Several changes at once (rename, type, charset, NULL / NOT NULL, FIRST/AFTER) take the most restrictive algorithm among them: dbsafe names the one that dominates.",
+		)
+	case parser.AddIndex:
+		r = append(r,
+			"A UNIQUE index fails on duplicate values: dbsafe suggests a duplicate check, and warns about nullable columns, which allow repeated NULLs.",
+			"An index on a VIRTUAL generated column stores the computed values: the build evaluates the expression for every row, and later writes compute them again.",
+		)
+	case parser.AddFulltextIndex:
+		r = append(r, "Only the first FULLTEXT index rebuilds the table (to add the hidden FTS_DOC_ID column); later ones don't.")
+	case parser.ChangeIndexVisibility:
+		r = append(r, "Making an index INVISIBLE gets a note on using it as a reversible trial before DROP INDEX.")
+	case parser.DropIndex:
+		r = append(r, "The only index covering a foreign key's columns can't be dropped (DANGEROUS), unless another index covers them.")
+	case parser.AddPrimaryKey:
+		r = append(r,
+			"If any key column is nullable, MySQL must convert it to NOT NULL: COPY instead of INPLACE.",
+			"The key fails on duplicate values: dbsafe suggests a duplicate check.",
+		)
+	case parser.AddForeignKey:
+		r = append(r, "INPLACE is only allowed with foreign_key_checks=0; with the default foreign_key_checks=1 the ALTER is COPY with a SHARED lock.")
+	case parser.AddCheckConstraint:
+		r = append(r, "NOT ENFORCED skips validating existing rows; otherwise dbsafe suggests a query for the rows that violate the check.")
+	case parser.ChangeEngine:
+		r = append(r, "ENGINE set to the table's current engine is a null ALTER (like FORCE): an INPLACE rebuild with concurrent DML.")
+	case parser.ConvertCharset:
+		r = append(r,
+			"With an indexed string column the conversion is COPY; without one it is INPLACE. Either way a SHARED lock blocks writes for the whole rebuild.",
+			"Every string column is converted, explicit CHARACTER SET or not, except those already in the target charset and collation: indexed ones among them don't force COPY.",
+			"Indexes on string columns can exceed the 3072-byte key limit in the wider charset.",
+		)
+	case parser.EngineAttribute:
+		if vr == V8_0_Early || vr == V8_0_Instant {
+			r = append(r, "ENGINE_ATTRIBUTE and SECONDARY_ENGINE_ATTRIBUTE need 8.0.21 or later.")
+		}
+	case parser.SecondaryLoad:
+		r = append(r, "Nothing is blocked, but the load reads the whole table: dbsafe notes its size, and that a SECONDARY_ENGINE and a HeatWave cluster are needed.")
+	case parser.RemovePartitioning:
+		r = append(r, "On a table larger than the danger threshold dbsafe recommends pt-online-schema-change: gh-ost doesn't handle partitioning scheme changes well.")
+	case parser.RepartitionTable:
+		r = append(r,
+			"Every unique key, the PRIMARY KEY included, must contain all columns of the partitioning expression, or MySQL rejects the ALTER (DANGEROUS).",
+			"On a table larger than the danger threshold dbsafe recommends pt-online-schema-change: gh-ost doesn't handle partitioning scheme changes well.",
+		)
+	case parser.RenameTable:
+		r = append(r, "Several pairs in one RENAME TABLE are one atomic swap; dbsafe checks that no pair renames a table already renamed away or onto a name an earlier pair created.")
+	case parser.AnalyzeTable:
+		r = append(r, "Before 8.0.24 the table flush at the end makes new queries wait behind running ones: CAUTION on those versions, SAFE otherwise.")
+	case parser.CheckTable:
+		r = append(r, "On a table larger than the danger threshold, writes are blocked for a long scan: CAUTION, with a suggestion to check a replica or a backup instead.")
+	case parser.AlterTablespace:
+		if vr == V8_0_Early || vr == V8_0_Instant {
+			r = append(r, "ALTER TABLESPACE ... RENAME TO needs 8.0.21 or later.")
+		}
+	}
+	r = append(r, "A table with held metadata locks, and the topology (Galera, Group Replication, replicas, Aurora), can raise the risk further.")
+	return r
+}
+
+// ParseOperation parses an operation name as printed by dbsafe, e.g. "ADD_COLUMN" or
+// "add column".
+func ParseOperation(name string) (parser.DDLOperation, error) {
+	op := parser.DDLOperation(strings.ToUpper(strings.Join(strings.Fields(strings.ReplaceAll(name, "-", " ")), "_")))
+	ops := MatrixOperations()
+	if !slices.Contains(ops, op) {
+		names := make([]string, len(ops))
+		for i, o := range ops {
+			names[i] = string(o)
+		}
+		return "", fmt.Errorf("unknown operation %q: use one of %s", name, strings.Join(names, ", "))
+	}
+	return op, nil
+}
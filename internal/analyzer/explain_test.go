@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/parser"
+)
+
+func TestExplainOperation(t *testing.T) {
+	tests := []struct {
+		name      string
+		op        parser.DDLOperation
+		version   mysql.ServerVersion
+		wantRange VersionRange
+		wantAlgo  Algorithm
+		wantRef   string
+	}{
+		{"add column 8.0.35", parser.AddColumn, v8_0_35, V8_0_Full, AlgoInstant, "AUTO_INCREMENT"},
+		{"add column 8.0.20 first/after", parser.AddColumn, mysql.ServerVersion{Major: 8, Minor: 0, Patch: 20}, V8_0_Instant, AlgoInstant, "FIRST or AFTER"},
+		{"drop column 8.0.28", parser.DropColumn, mysql.ServerVersion{Major: 8, Minor: 0, Patch: 28}, V8_0_Instant, AlgoInplace, "part of an index"},
+		{"add foreign key 8.4", parser.AddForeignKey, v8_4_0, V8_4_LTS, AlgoInplace, "foreign_key_checks"},
+		{"add primary key early", parser.AddPrimaryKey, mysql.ServerVersion{Major: 8, Minor: 0, Patch: 5}, V8_0_Early, AlgoInplace, "nullable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := ExplainOperation(tt.op, tt.version)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if e.Range != tt.wantRange {
+				t.Errorf("Range = %s, want %s", e.Range, tt.wantRange)
+			}
+			if e.Classification.Algorithm != tt.wantAlgo {
+				t.Errorf("Algorithm = %s, want %s", e.Classification.Algorithm, tt.wantAlgo)
+			}
+			if e.RangeReason == "" {
+				t.Error("expected a version range reason")
+			}
+			if !containsWarning(e.Refinements, tt.wantRef) {
+				t.Errorf("expected a refinement mentioning %q, got %v", tt.wantRef, e.Refinements)
+			}
+		})
+	}
+}
+
+func TestExplainOperation_FirstAfterOnlyBefore8029(t *testing.T) {
+	e, err := ExplainOperation(parser.AddColumn, v8_0_35)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if containsWarning(e.Refinements, "FIRST or AFTER") {
+		t.Errorf("FIRST/AFTER is INSTANT on 8.0.29+, got %v", e.Refinements)
+	}
+}
+
+func TestExplainOperation_AuroraAndUnknownVersions(t *testing.T) {
+	aurora, err := mysql.ParseVersion("8.0.mysql_aurora.3.05.2")
+	if err != nil {
+		t.Fatalf("version: %v", err)
+	}
+	e, err := ExplainOperation(parser.AddColumn, aurora)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(e.RangeReason, "Aurora MySQL 3.05.2 is compatible with isn't in the version string, so dbsafe assumes 8.0.23") {
+		t.Errorf("RangeReason = %q", e.RangeReason)
+	}
+
+	e, err = ExplainOperation(parser.AddColumn, mysql.ServerVersion{Major: 9, Minor: 1, Patch: 0})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Range != V8_0_Full || !strings.Contains(e.RangeReason, "outside the ranges") {
+		t.Errorf("got %s: %q", e.Range, e.RangeReason)
+	}
+}
+
+func TestParseOperation(t *testing.T) {
+	for _, name := range []string{"ADD_COLUMN", "add_column", "add column", "Add-Column"} {
+		op, err := ParseOperation(name)
+		if err != nil || op != parser.AddColumn {
+			t.Errorf("ParseOperation(%q) = %q, %v, want ADD_COLUMN", name, op, err)
+		}
+	}
+	if _, err := ParseOperation("UPDATE"); err == nil || !strings.Contains(err.Error(), "ADD_COLUMN") {
+		t.Errorf("error = %v, want the list of operations", err)
+	}
+}
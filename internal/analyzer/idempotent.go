@@ -66,6 +66,12 @@ func GenerateIdempotentSP(parsed *parser.ParsedSQL, database, table string) (sp
 		}
 		return buildSP(procName, "IF", indexExistsCondition(database, table, parsed.IndexName), ddl), ""
 
+	case parser.ChangeIndexVisibility:
+		if parsed.IndexName == "" {
+			return "", "Cannot generate idempotent SP: index name not detected."
+		}
+		return buildSP(procName, "IF", indexExistsCondition(database, table, parsed.IndexName), ddl), ""
+
 	// ── FK operations ────────────────────────────────────────────────────────
 	case parser.AddForeignKey:
 		if parsed.IndexName == "" {
@@ -96,11 +102,13 @@ func GenerateIdempotentSP(parsed *parser.ParsedSQL, database, table string) (sp
 	case parser.ConvertCharset, parser.ChangeCharset:
 		return "", "Cannot generate idempotent SP for CHARACTER SET changes: the check would require inspecting every column's collation."
 
-	case parser.AddPartition, parser.DropPartition, parser.ReorganizePartition, parser.RebuildPartition, parser.TruncatePartition:
+	case parser.AddPartition, parser.DropPartition, parser.ReorganizePartition, parser.RebuildPartition, parser.TruncatePartition,
+		parser.RemovePartitioning, parser.RepartitionTable:
 		return "", "Cannot generate idempotent SP for partition operations (not supported in v1)."
 
 	case parser.SetDefault, parser.DropDefault, parser.ChangeAutoIncrement,
-		parser.KeyBlockSize, parser.StatsOption, parser.TableEncryption, parser.ChangeRowFormat:
+		parser.KeyBlockSize, parser.StatsOption, parser.TableEncryption, parser.ChangeRowFormat,
+		parser.EngineAttribute, parser.SecondaryEngine, parser.SecondaryUnload:
 		return "", "Idempotent SP not generated: metadata-only operations are already safe to re-run."
 
 	default:
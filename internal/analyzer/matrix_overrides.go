@@ -0,0 +1,139 @@
+package analyzer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/nethalo/dbsafe/internal/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// MatrixOverride replaces the classification matrix entry of an operation in some version
+// ranges, for servers whose online DDL differs from upstream MySQL's, e.g. a patched fork.
+// The table- and statement-specific refinements of an analysis still apply on top.
+type MatrixOverride struct {
+	Operation      parser.DDLOperation
+	Versions       []VersionRange // every range when empty
+	Classification DDLClassification
+}
+
+var (
+	matrixOverridesMu sync.RWMutex
+	matrixOverrides   map[matrixKey]DDLClassification
+)
+
+// SetMatrixOverrides makes ClassifyDDL return the overrides' classifications instead of
+// the built-in matrix entries, a later override winning over an earlier one for the same
+// operation and range. nil restores the built-in matrix.
+func SetMatrixOverrides(overrides []MatrixOverride) {
+	m := map[matrixKey]DDLClassification{}
+	for _, o := range overrides {
+		versions := o.Versions
+		if len(versions) == 0 {
+			versions = []VersionRange{V8_0_Early, V8_0_Instant, V8_0_Full, V8_4_LTS}
+		}
+		for _, vr := range versions {
+			m[matrixKey{Op: o.Operation, Version: vr}] = o.Classification
+		}
+	}
+	matrixOverridesMu.Lock()
+	defer matrixOverridesMu.Unlock()
+	matrixOverrides = m
+}
+
+// overriddenClassification returns the override for key, if any.
+func overriddenClassification(key matrixKey) (DDLClassification, bool) {
+	matrixOverridesMu.RLock()
+	defer matrixOverridesMu.RUnlock()
+	c, ok := matrixOverrides[key]
+	return c, ok
+}
+
+// matrixOverrideFile is the YAML form of the overrides:
+//
+//	overrides:
+//	  - operation: ADD_INDEX
+//	    versions: ["8.0.29+", "8.4"]
+//	    algorithm: INSTANT
+//	    lock: NONE
+//	    rebuilds_table: false
+//	    notes: Our fork builds secondary indexes in the background.
+type matrixOverrideFile struct {
+	Overrides []struct {
+		Operation     string   `yaml:"operation"`
+		Versions      []string `yaml:"versions"`
+		Algorithm     string   `yaml:"algorithm"`
+		Lock          string   `yaml:"lock"`
+		RebuildsTable bool     `yaml:"rebuilds_table"`
+		Notes         string   `yaml:"notes"`
+	} `yaml:"overrides"`
+}
+
+// ParseMatrixOverrides reads matrix overrides from YAML (see matrixOverrideFile). The
+// operation is one of MatrixOperations; the versions are range names (8.0.0-8.0.11,
+// 8.0.12-8.0.28, 8.0.29+ or 8.4), all of them when omitted; the algorithm is INSTANT,
+// INPLACE or COPY and the lock NONE, SHARED or EXCLUSIVE.
+func ParseMatrixOverrides(data []byte) ([]MatrixOverride, error) {
+	var file matrixOverrideFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	ops := MatrixOperations()
+	overrides := make([]MatrixOverride, 0, len(file.Overrides))
+	for i, entry := range file.Overrides {
+		at := fmt.Sprintf("override %d", i+1)
+		op := parser.DDLOperation(strings.ToUpper(strings.TrimSpace(entry.Operation)))
+		if !slices.Contains(ops, op) {
+			return nil, fmt.Errorf("%s: operation %q isn't in the classification matrix (see dbsafe explain)", at, entry.Operation)
+		}
+		o := MatrixOverride{Operation: op}
+		for _, name := range entry.Versions {
+			vr, ok := parseVersionRange(name)
+			if !ok {
+				return nil, fmt.Errorf("%s: version range %q: use 8.0.0-8.0.11, 8.0.12-8.0.28, 8.0.29+ or 8.4", at, name)
+			}
+			o.Versions = append(o.Versions, vr)
+		}
+
+		algo := Algorithm(strings.ToUpper(strings.TrimSpace(entry.Algorithm)))
+		if algo != AlgoInstant && algo != AlgoInplace && algo != AlgoCopy {
+			return nil, fmt.Errorf("%s: algorithm %q: use INSTANT, INPLACE or COPY", at, entry.Algorithm)
+		}
+		lock := LockLevel(strings.ToUpper(strings.TrimSpace(entry.Lock)))
+		if lock != LockNone && lock != LockShared && lock != LockExclusive {
+			return nil, fmt.Errorf("%s: lock %q: use NONE, SHARED or EXCLUSIVE", at, entry.Lock)
+		}
+		notes := entry.Notes
+		if notes == "" {
+			notes = "Classification from the matrix override file."
+		}
+		o.Classification = DDLClassification{Algorithm: algo, Lock: lock, RebuildsTable: entry.RebuildsTable, Notes: notes}
+		overrides = append(overrides, o)
+	}
+	return overrides, nil
+}
+
+// parseVersionRange reads a range by the first version it covers and its span, as
+// VersionRange.String writes it (8.0.12 – 8.0.28, 8.4 LTS) or without the spaces.
+func parseVersionRange(name string) (VersionRange, bool) {
+	s := strings.ToUpper(strings.Join(strings.Fields(strings.ReplaceAll(name, "–", "-")), ""))
+	switch strings.TrimSuffix(s, "LTS") {
+	case "8.0.0-8.0.11":
+		return V8_0_Early, true
+	case "8.0.12-8.0.28":
+		return V8_0_Instant, true
+	case "8.0.29+":
+		return V8_0_Full, true
+	case "8.4":
+		return V8_4_LTS, true
+	}
+	return 0, false
+}
@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nethalo/dbsafe/internal/parser"
+	"github.com/nethalo/dbsafe/internal/topology"
+)
+
+func TestMatrixOverrides(t *testing.T) {
+	defer SetMatrixOverrides(nil)
+
+	overrides, err := ParseMatrixOverrides([]byte(`
+overrides:
+  - operation: add_index
+    versions: ["8.0.29+", "8.4 LTS"]
+    algorithm: instant
+    lock: NONE
+    notes: Our fork builds secondary indexes in the background.
+`))
+	if err != nil {
+		t.Fatalf("ParseMatrixOverrides: %v", err)
+	}
+	if len(overrides) != 1 || overrides[0].Operation != parser.AddIndex ||
+		len(overrides[0].Versions) != 2 || overrides[0].Versions[1] != V8_4_LTS {
+		t.Fatalf("overrides = %+v", overrides)
+	}
+	before := ClassifyDDL(parser.AddIndex, 8, 0, 20)
+	SetMatrixOverrides(overrides)
+
+	if c := ClassifyDDL(parser.AddIndex, 8, 0, 35); c.Algorithm != AlgoInstant || c.Notes != overrides[0].Classification.Notes {
+		t.Errorf("8.0.35 classification = %+v, want the override", c)
+	}
+	if c := ClassifyDDL(parser.AddIndex, 8, 0, 20); c != before {
+		t.Errorf("8.0.20 classification = %+v, want the built-in %+v", c, before)
+	}
+	result := Analyze(ddlInput(parser.AddIndex, v8_4_0, 1024*1024*1024, topology.Standalone))
+	if result.Classification.Algorithm != AlgoInstant {
+		t.Errorf("Analyze algorithm = %s, want INSTANT from the override", result.Classification.Algorithm)
+	}
+
+	SetMatrixOverrides(nil)
+	if c := ClassifyDDL(parser.AddIndex, 8, 0, 35); c.Algorithm == AlgoInstant {
+		t.Error("SetMatrixOverrides(nil) didn't restore the built-in matrix")
+	}
+
+	// Omitted versions cover every range; omitted notes get a default.
+	all, err := ParseMatrixOverrides([]byte("overrides:\n  - {operation: ADD_INDEX, algorithm: COPY, lock: SHARED}\n"))
+	if err != nil {
+		t.Fatalf("ParseMatrixOverrides: %v", err)
+	}
+	SetMatrixOverrides(all)
+	for _, v := range [][3]int{{8, 0, 5}, {8, 0, 20}, {8, 0, 35}, {8, 4, 2}} {
+		if c := ClassifyDDL(parser.AddIndex, v[0], v[1], v[2]); c.Algorithm != AlgoCopy || c.Notes == "" {
+			t.Errorf("%v classification = %+v, want the override", v, c)
+		}
+	}
+
+	if overrides, err := ParseMatrixOverrides(nil); err != nil || len(overrides) != 0 {
+		t.Errorf("empty file = %v, %v; want no overrides", overrides, err)
+	}
+
+	for _, tc := range []struct {
+		name, yaml, want string
+	}{
+		{"unknown operation", "overrides:\n  - {operation: ADD_WIDGET, algorithm: COPY, lock: SHARED}\n", "ADD_WIDGET"},
+		{"unknown version range", "overrides:\n  - {operation: ADD_INDEX, versions: [\"9.0\"], algorithm: COPY, lock: SHARED}\n", "9.0"},
+		{"bad algorithm", "overrides:\n  - {operation: ADD_INDEX, algorithm: ONLINE, lock: SHARED}\n", "ONLINE"},
+		{"bad lock", "overrides:\n  - {operation: ADD_INDEX, algorithm: COPY, lock: DEFAULT}\n", "DEFAULT"},
+		{"unknown key", "overrides:\n  - {operation: ADD_INDEX, algorithm: COPY, lock: SHARED, locks: NONE}\n", "locks"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseMatrixOverrides([]byte(tc.yaml))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want one mentioning %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package analyzer
+
+import (
+	"github.com/nethalo/dbsafe/internal/parser"
+)
+
+// Attribute is one key/value describing an analysis. Values are string, int64, float64 or
+// bool, the scalar types OpenTelemetry attributes take.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// AnalysisObserver is notified of each analysis once its result is final, with the result
+// and its attributes (see AnalysisAttributes), for programs that import the analyzer and
+// record analyses in their tracing or metrics: once per Analyze, AnalyzeStatement,
+// AnalyzeParsed and AnalyzeOffline, and once per step or statement of AnalyzeDiff and
+// AnalyzeBatch. It runs synchronously, so it should not block.
+type AnalysisObserver interface {
+	ObserveAnalysis(result *Result, attrs []Attribute)
+}
+
+// observe notifies observer, when set, of result.
+func observe(observer AnalysisObserver, result *Result) {
+	if observer != nil {
+		observer.ObserveAnalysis(result, AnalysisAttributes(result))
+	}
+}
+
+// NopObserver is an AnalysisObserver that does nothing.
+type NopObserver struct{}
+
+// ObserveAnalysis implements AnalysisObserver.
+func (NopObserver) ObserveAnalysis(*Result, []Attribute) {}
+
+// ObserverFunc adapts a function to an AnalysisObserver.
+type ObserverFunc func(result *Result, attrs []Attribute)
+
+// ObserveAnalysis implements AnalysisObserver.
+func (f ObserverFunc) ObserveAnalysis(result *Result, attrs []Attribute) {
+	f(result, attrs)
+}
+
+// OTelEventName is the event name OTelObserver emits.
+const OTelEventName = "dbsafe.analysis"
+
+// OTelObserver is an AnalysisObserver that renames the attributes after OpenTelemetry's
+// database semantic conventions and hands them to a function, under OTelEventName: the
+// database becomes db.namespace, the table db.collection.name, db.system.name is "mysql",
+// and every other key gets a "dbsafe." prefix. The function converts them to the
+// program's telemetry library, e.g. attribute.String or attribute.Int64, and records
+// them on a span or as an event, so dbsafe doesn't depend on one.
+type OTelObserver func(name string, attrs []Attribute)
+
+// ObserveAnalysis implements AnalysisObserver.
+func (f OTelObserver) ObserveAnalysis(result *Result, attrs []Attribute) {
+	otel := make([]Attribute, 0, len(attrs)+1)
+	otel = append(otel, Attribute{Key: "db.system.name", Value: "mysql"})
+	for _, a := range attrs {
+		switch a.Key {
+		case "database":
+			a.Key = "db.namespace"
+		case "table":
+			a.Key = "db.collection.name"
+		default:
+			a.Key = "dbsafe." + a.Key
+		}
+		otel = append(otel, a)
+	}
+	f(OTelEventName, otel)
+}
+
+// AnalysisAttributes describes a result as attributes: the statement type and operation,
+// the table and its size, the classification (DDL) or affected rows (DML), the risk,
+// method and duration estimate, the warning counts, and the topology and server version.
+// Keys that don't apply to the statement are left out.
+func AnalysisAttributes(result *Result) []Attribute {
+	attrs := []Attribute{
+		{Key: "statement_type", Value: string(result.StatementType)},
+	}
+	add := func(key string, value any) {
+		attrs = append(attrs, Attribute{Key: key, Value: value})
+	}
+	if result.Database != "" {
+		add("database", result.Database)
+	}
+	if result.Table != "" {
+		add("table", result.Table)
+	}
+	switch result.StatementType {
+	case parser.DDL:
+		add("operation", string(result.DDLOp))
+		add("algorithm", string(result.Classification.Algorithm))
+		add("lock", string(result.Classification.Lock))
+		add("rebuilds_table", result.Classification.RebuildsTable)
+	case parser.DML:
+		add("operation", string(result.DMLOp))
+		add("affected_rows", result.AffectedRows)
+	}
+	if result.TableMeta != nil && result.TableMeta.Table != "" {
+		add("table_size_bytes", result.TableMeta.TotalSize())
+		add("table_rows", result.TableMeta.RowCount)
+	}
+	add("risk", string(result.Risk))
+	if result.Method != "" {
+		add("method", string(result.Method))
+	}
+	add("duration_estimate_seconds", int64(EstimateDuration(result).Seconds()))
+
+	var critical int64
+	for _, w := range result.Warnings {
+		if w.Severity == SeverityCritical {
+			critical++
+		}
+	}
+	add("warnings", int64(len(result.Warnings)))
+	add("critical_warnings", critical)
+
+	if result.Topology != nil {
+		add("topology", string(result.Topology.Type))
+	}
+	if result.Version.Major > 0 {
+		add("server_version", result.Version.String())
+	}
+	return attrs
+}
@@ -0,0 +1,132 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nethalo/dbsafe/internal/parser"
+	"github.com/nethalo/dbsafe/internal/topology"
+)
+
+func TestAnalysisObserver(t *testing.T) {
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 5*1024*1024*1024, topology.Standalone)
+	input.Parsed.NewColumnType = "TEXT"
+	var observed *Result
+	var attrs map[string]any
+	input.Observer = ObserverFunc(func(result *Result, a []Attribute) {
+		observed = result
+		attrs = map[string]any{}
+		for _, kv := range a {
+			attrs[kv.Key] = kv.Value
+		}
+	})
+
+	result := Analyze(input)
+	if observed != result {
+		t.Fatal("the observer wasn't given the result")
+	}
+	for key, want := range map[string]any{
+		"statement_type":   "DDL",
+		"table":            "test",
+		"database":         "testdb",
+		"operation":        string(parser.ModifyColumn),
+		"algorithm":        string(AlgoCopy),
+		"rebuilds_table":   true,
+		"table_size_bytes": int64(5 * 1024 * 1024 * 1024),
+		"risk":             string(RiskDangerous),
+		"method":           string(result.Method),
+		"topology":         string(topology.Standalone),
+		"server_version":   v8_0_35.String(),
+		"warnings":         int64(len(result.Warnings)),
+	} {
+		if attrs[key] != want {
+			t.Errorf("%s = %#v, want %#v", key, attrs[key], want)
+		}
+	}
+	if d, _ := attrs["duration_estimate_seconds"].(int64); d <= 0 {
+		t.Errorf("duration_estimate_seconds = %#v, want a positive estimate", attrs["duration_estimate_seconds"])
+	}
+
+	var name string
+	otel := map[string]any{}
+	OTelObserver(func(n string, a []Attribute) {
+		name = n
+		for _, kv := range a {
+			otel[kv.Key] = kv.Value
+		}
+	}).ObserveAnalysis(result, AnalysisAttributes(result))
+	if name != OTelEventName || otel["db.system.name"] != "mysql" || otel["db.namespace"] != "testdb" ||
+		otel["db.collection.name"] != "test" || otel["dbsafe.risk"] != string(RiskDangerous) || otel["risk"] != nil {
+		t.Errorf("OTel event %s: %v", name, otel)
+	}
+
+	NopObserver{}.ObserveAnalysis(result, nil)
+}
+
+// The wrappers that add warnings after the analysis notify the observer once per final
+// result, so the counts it records match what they return.
+func TestAnalysisObserver_FinalResults(t *testing.T) {
+	var observed []*Result
+	var counts []any
+	opts := Options{
+		Version:    &v8_0_35,
+		Idempotent: true,
+		Observer: ObserverFunc(func(result *Result, attrs []Attribute) {
+			observed = append(observed, result)
+			for _, a := range attrs {
+				if a.Key == "warnings" {
+					counts = append(counts, a.Value)
+				}
+			}
+		}),
+	}
+	check := func(name string, results ...*Result) {
+		t.Helper()
+		if len(observed) != len(results) {
+			t.Fatalf("%s: observed %d results, want %d", name, len(observed), len(results))
+		}
+		for i, result := range results {
+			if observed[i] != result || counts[i] != int64(len(result.Warnings)) {
+				t.Errorf("%s: result %d observed with %v warnings, returned with %d: %v",
+					name, i, counts[i], len(result.Warnings), result.WarningMessages())
+			}
+		}
+		observed, counts = nil, nil
+	}
+
+	parsed, err := parser.Parse("ALTER TABLE shop.users RENAME TO shop.members")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := AnalyzeOffline(parsed, offlineMeta(t), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.HasWarning(WarnOfflineAnalysis) {
+		t.Fatalf("expected the offline warning: %v", result.WarningMessages())
+	}
+	check("AnalyzeOffline", result)
+
+	plan, err := AnalyzeDiff(context.Background(), nil, offlineUsersTable,
+		"CREATE TABLE shop.users (id INT NOT NULL PRIMARY KEY, email VARCHAR(100), nick VARCHAR(50), KEY idx_email (email)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var steps []*Result
+	for _, step := range plan.Steps {
+		steps = append(steps, step.Result)
+	}
+	check("AnalyzeDiff", steps...)
+
+	dump, err := NewSchemaDump(offlineUsersTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Metadata = dump
+	inputs, err := BatchInputs(context.Background(), nil, []*parser.ParsedSQL{parsed, parsed}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := AnalyzeBatch(inputs)
+	check("AnalyzeBatch", batch.Results...)
+}
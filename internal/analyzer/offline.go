@@ -0,0 +1,268 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/parser"
+	"github.com/nethalo/dbsafe/internal/topology"
+)
+
+// AnalyzeOffline runs the analysis without a server, for an assumed version
+// (opts.Version, required). meta describes the table, typically built from its CREATE
+// TABLE with MetadataFromDefinition; when nil, it is looked up in opts.Metadata, and
+// without one the table's columns are unknown and the checks against them are skipped. The table is treated as empty and the topology as
+// opts.Topology, or standalone (Galera for percona-xtradb-cluster, an Aurora writer for aurora-mysql).
+func AnalyzeOffline(parsed *parser.ParsedSQL, meta *mysql.TableMetadata, opts Options) (*Result, error) {
+	if name, ok := UnsupportedOperation(parsed); ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedStatement, name)
+	}
+	if opts.Version == nil {
+		return nil, fmt.Errorf("offline analysis needs an assumed server version (Options.Version)")
+	}
+
+	database := opts.Database
+	if database == "" {
+		database = parsed.Database
+	}
+	if meta == nil {
+		var err error
+		if meta, err = offlineMetadata(parsed, database, opts); err != nil {
+			return nil, err
+		}
+	}
+	input := offlineInput(parsed, meta, database, opts)
+	result := analyze(input)
+	addOfflineWarning(input, result)
+	addIdempotentSP(result, parsed, opts)
+	addProjectedSchema(result, input, opts)
+	observe(input.Observer, result)
+	return result, nil
+}
+
+// offlineMetadata looks up the metadata of the table parsed needs in opts.Metadata; nil
+// without one, or when the statement has no table to look up.
+func offlineMetadata(parsed *parser.ParsedSQL, database string, opts Options) (*mysql.TableMetadata, error) {
+	if opts.Metadata == nil {
+		return nil, nil
+	}
+	switch parsed.DDLOp {
+	case parser.AlterTablespace, parser.ObjectDefinition, parser.AccountManagement:
+		return nil, nil
+	}
+	if newTable(parsed) {
+		return nil, nil
+	}
+	meta, err := sourceMetadata(opts.Metadata, parsed, database)
+	if err != nil {
+		return nil, fmt.Errorf("metadata collection failed: %w", err)
+	}
+	return meta, nil
+}
+
+// addOfflineWarning says what an offline analysis could not know.
+func addOfflineWarning(input Input, result *Result) {
+	msg := fmt.Sprintf(
+		"Offline analysis for MySQL %s: no server was queried, so table size, triggers, inbound foreign keys, replication state and server variables are unknown. "+
+			"The table is treated as empty, which can recommend direct execution for a table that needs an online schema change tool.",
+		input.Version.String(),
+	)
+	if input.MetadataUnknown {
+		msg += " Without the table's CREATE TABLE, column checks were skipped."
+	}
+	result.addWarning(WarnOfflineAnalysis, msg)
+}
+
+// offlineInput builds the analysis input for opts.Version without a server.
+func offlineInput(parsed *parser.ParsedSQL, meta *mysql.TableMetadata, database string, opts Options) Input {
+	version := *opts.Version
+	unknown := meta == nil
+	switch {
+	case parsed.DDLOp == parser.AlterTablespace || parsed.DDLOp == parser.ObjectDefinition || parsed.DDLOp == parser.AccountManagement:
+		meta, unknown = &mysql.TableMetadata{}, false
+	case meta == nil:
+		database, table := metadataTable(parsed, database)
+		meta = &mysql.TableMetadata{Database: database, Table: table, Engine: "InnoDB"}
+	case meta.Database == "":
+		withDB := *meta
+		withDB.Database = database
+		meta = &withDB
+	}
+
+	topo := &topology.Info{Type: topology.Standalone, Version: version}
+	switch version.Flavor {
+	case "percona-xtradb-cluster":
+		topo.Type = topology.Galera
+	case "aurora-mysql":
+		topo.Type = topology.AuroraWriter
+		topo.IsCloudManaged = true
+		topo.CloudProvider = "aws-aurora"
+	}
+	if opts.Topology != "" {
+		topo.Type = opts.Topology
+		aurora := opts.Topology == topology.AuroraWriter || opts.Topology == topology.AuroraReader
+		topo.IsCloudManaged = aurora
+		topo.CloudProvider = ""
+		if aurora {
+			topo.CloudProvider = "aws-aurora"
+		}
+	}
+
+	return Input{
+		Parsed:                 parsed,
+		Meta:                   meta,
+		Topo:                   topo,
+		Version:                version,
+		ChunkSize:              opts.ChunkSize,
+		SleepSeconds:           opts.SleepSeconds,
+		MaxReplicaLag:          opts.MaxReplicaLag,
+		SafePtOSC:              opts.SafePtOSC,
+		WithHooks:              opts.WithHooks,
+		NoOnlineTools:          opts.NoOnlineTools,
+		PtOSCChunking:          opts.PtOSCChunking,
+		PtOSCRecursionMethod:   opts.PtOSCRecursionMethod,
+		GhostReplication:       opts.GhostReplication,
+		DangerousSizeThreshold: opts.DangerousSizeThreshold,
+		CautionSizeThreshold:   opts.CautionSizeThreshold,
+		CopyBytesPerSec:        opts.CopyBytesPerSec,
+		FreeDiskBytes:          opts.FreeDiskBytes,
+		BinlogFreeBytes:        opts.FreeDiskBytes,
+		TrafficProfile:         opts.TrafficProfile,
+		Trace:                  opts.Trace,
+		Observer:               opts.Observer,
+		Connection:             opts.Connection,
+		MetadataUnknown:        unknown,
+		offline:                true,
+	}
+}
+
+// MetadataFromDefinition converts a parsed CREATE TABLE into table metadata for offline
+// analysis. Sizes and row counts are zero; string columns without an explicit character
+// set get the table's.
+func MetadataFromDefinition(def *parser.TableDefinition, createSQL string) *mysql.TableMetadata {
+	meta := &mysql.TableMetadata{
+		Database:    def.Database,
+		Table:       def.Table,
+		Engine:      def.Engine,
+		RowFormat:   def.RowFormat,
+		CreateTable: createSQL,
+	}
+	if meta.Engine == "" {
+		meta.Engine = "InnoDB"
+	}
+
+	for i, c := range def.Columns {
+		col := mysql.ColumnInfo{
+			Name:              c.Name,
+			Type:              c.Type,
+			Nullable:          c.Nullable,
+			Default:           c.Default,
+			Position:          i + 1,
+			IsStoredGenerated: c.Stored,
+			GenerationExpr:    c.GenerationExpr,
+			AutoIncrement:     c.AutoIncrement,
+			SRID:              c.SRID,
+		}
+		charset := c.Charset
+		if charset == "" && isStringType(c.Type) {
+			charset = def.Charset
+		}
+		if charset != "" {
+			col.CharacterSet = &charset
+		}
+		if c.Collation != "" {
+			collation := c.Collation
+			col.Collation = &collation
+		}
+		meta.Columns = append(meta.Columns, col)
+	}
+
+	for _, idx := range def.Indexes {
+		meta.Indexes = append(meta.Indexes, mysql.IndexInfo{
+			Name:      idx.Name,
+			Columns:   idx.Columns,
+			NonUnique: !idx.Unique,
+			Type:      idx.Type,
+			SubParts:  idx.SubParts,
+			Invisible: idx.Invisible,
+		})
+	}
+
+	for _, fk := range def.ForeignKeys {
+		meta.ForeignKeys = append(meta.ForeignKeys, mysql.ForeignKeyInfo{
+			Name:             fk.Name,
+			Columns:          fk.Columns,
+			ReferencedTable:  fk.ReferencedTable,
+			ReferencedSchema: def.Database,
+			ReferencedCols:   fk.ReferencedCols,
+			DeleteRule:       fk.DeleteRule,
+			UpdateRule:       fk.UpdateRule,
+		})
+	}
+	for _, chk := range def.Checks {
+		meta.CheckConstraints = append(meta.CheckConstraints, mysql.CheckConstraintInfo{Name: chk.Name, Expression: chk.Expression})
+	}
+	return meta
+}
+
+// SchemaDump is a MetadataSource over a captured schema, for analysis without a server:
+// the CREATE TABLE statements of SHOW CREATE TABLE output or mysqldump --no-data (see
+// parser.ParseSchemaDump). Each table gets the foreign keys the other tables of the dump
+// declare on it as inbound foreign keys. Sizes, row counts and triggers are unknown.
+type SchemaDump struct {
+	tables []*mysql.TableMetadata
+}
+
+// NewSchemaDump parses a schema dump holding one or more CREATE TABLE statements.
+func NewSchemaDump(dump string) (*SchemaDump, error) {
+	defs, err := parser.ParseSchemaDump(dump)
+	if err != nil {
+		return nil, err
+	}
+	s := &SchemaDump{}
+	for _, def := range defs {
+		s.tables = append(s.tables, MetadataFromDefinition(def, def.CreateSQL))
+	}
+	for _, child := range s.tables {
+		for _, fk := range child.ForeignKeys {
+			parent := s.lookup(fk.ReferencedSchema, fk.ReferencedTable)
+			if parent == nil {
+				continue
+			}
+			fk.ChildSchema, fk.ChildTable = child.Database, child.Table
+			parent.InboundForeignKeys = append(parent.InboundForeignKeys, fk)
+		}
+	}
+	return s, nil
+}
+
+// TableMetadata implements mysql.MetadataSource. A table the dump qualifies with a database
+// (or that follows a USE) only matches that database; an unqualified one matches any.
+func (s *SchemaDump) TableMetadata(database, table string) (*mysql.TableMetadata, error) {
+	meta := s.lookup(database, table)
+	if meta == nil {
+		names := make([]string, len(s.tables))
+		for i, t := range s.tables {
+			names[i] = t.Table
+			if t.Database != "" {
+				names[i] = t.Database + "." + t.Table
+			}
+		}
+		return nil, fmt.Errorf("table %s not in the schema dump (it has %s)", table, strings.Join(names, ", "))
+	}
+	withDB := *meta
+	if withDB.Database == "" {
+		withDB.Database = database
+	}
+	return &withDB, nil
+}
+
+func (s *SchemaDump) lookup(database, table string) *mysql.TableMetadata {
+	for _, t := range s.tables {
+		if strings.EqualFold(t.Table, table) && (database == "" || t.Database == "" || strings.EqualFold(t.Database, database)) {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/parser"
+	"github.com/nethalo/dbsafe/internal/topology"
+)
+
+const offlineUsersTable = "CREATE TABLE shop.users (id INT NOT NULL PRIMARY KEY, email VARCHAR(100)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"
+
+func offlineMeta(t *testing.T) *mysql.TableMetadata {
+	t.Helper()
+	def, err := parser.ParseTableDefinition(offlineUsersTable)
+	if err != nil {
+		t.Fatalf("parsing definition: %v", err)
+	}
+	return MetadataFromDefinition(def, offlineUsersTable)
+}
+
+func TestAnalyzeOffline_WithDefinition(t *testing.T) {
+	parsed, err := parser.Parse("ALTER TABLE shop.users ADD COLUMN nick VARCHAR(50)")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	version := v8_0_35
+	result, err := AnalyzeOffline(parsed, offlineMeta(t), Options{Version: &version})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Classification.Algorithm != AlgoInstant {
+		t.Errorf("algorithm = %s, want INSTANT", result.Classification.Algorithm)
+	}
+	if result.Topology.Type != topology.Standalone {
+		t.Errorf("topology = %s, want standalone", result.Topology.Type)
+	}
+	if !result.HasWarning(WarnOfflineAnalysis) {
+		t.Errorf("expected an offline analysis warning, got %v", result.WarningMessages())
+	}
+	if containsWarning(result.WarningMessages(), "column checks were skipped") {
+		t.Error("column checks should run when the definition is known")
+	}
+}
+
+func TestAnalyzeOffline_ProjectedSchema(t *testing.T) {
+	parsed, err := parser.Parse("ALTER TABLE shop.users ADD COLUMN nick VARCHAR(50) AFTER id, DROP COLUMN email")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	version := v8_0_35
+	result, err := AnalyzeOffline(parsed, offlineMeta(t), Options{Version: &version, ProjectSchema: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProjectedSchema == nil {
+		t.Fatalf("no projected schema: %v", result.WarningMessages())
+	}
+	lines := strings.Join(result.ProjectedSchema.Lines(), "\n")
+	for _, want := range []string{"  CREATE TABLE `shop`.`users` (", "+ \t`nick` varchar(50),", "- \t`email` varchar(100),", "  \tPRIMARY KEY (`id`)"} {
+		if !strings.Contains(lines, want) {
+			t.Errorf("Lines() =\n%s\nwant a line %q", lines, want)
+		}
+	}
+
+	// Without the table's CREATE TABLE there is nothing to apply the ALTER to.
+	result, err = AnalyzeOffline(parsed, nil, Options{Version: &version, ProjectSchema: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProjectedSchema != nil || !result.HasWarning(WarnSchemaProjection) {
+		t.Errorf("expected a SCHEMA_PROJECTION note, got %v", result.WarningMessages())
+	}
+}
+
+func TestAnalyzeOffline_ValidatesColumns(t *testing.T) {
+	parsed, err := parser.Parse("ALTER TABLE shop.users DROP COLUMN nick")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	version := v8_0_35
+	result, err := AnalyzeOffline(parsed, offlineMeta(t), Options{Version: &version})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.HasWarning(WarnColumnNotFound) {
+		t.Errorf("expected a column not found warning, got %v", result.WarningMessages())
+	}
+}
+
+func TestAnalyzeOffline_WithoutDefinition(t *testing.T) {
+	parsed, err := parser.Parse("ALTER TABLE shop.users DROP COLUMN nick")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	version, err := mysql.ParseAssumedVersion("8.0.36-percona-xtradb-cluster")
+	if err != nil {
+		t.Fatalf("version: %v", err)
+	}
+	result, err := AnalyzeOffline(parsed, nil, Options{Version: &version})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.HasWarning(WarnColumnNotFound) {
+		t.Error("column checks should be skipped without a definition")
+	}
+	if !containsWarning(result.WarningMessages(), "column checks were skipped") {
+		t.Errorf("expected the offline warning to mention skipped checks, got %v", result.WarningMessages())
+	}
+	if result.Topology.Type != topology.Galera {
+		t.Errorf("topology = %s, want galera for percona-xtradb-cluster", result.Topology.Type)
+	}
+}
+
+func TestAnalyzeOffline_RequiresVersion(t *testing.T) {
+	parsed, err := parser.Parse("ALTER TABLE shop.users ADD COLUMN nick VARCHAR(50)")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if _, err := AnalyzeOffline(parsed, nil, Options{}); err == nil {
+		t.Error("expected an error without Options.Version")
+	}
+}
+
+func TestAnalyzeDiff_Offline(t *testing.T) {
+	version := v8_0_35
+	plan, err := AnalyzeDiff(context.Background(), nil, offlineUsersTable,
+		"CREATE TABLE shop.users (id INT NOT NULL PRIMARY KEY, email VARCHAR(100), nick VARCHAR(50)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		Options{Version: &version})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Database != "shop" || plan.Table != "users" {
+		t.Errorf("table = %s.%s, want shop.users", plan.Database, plan.Table)
+	}
+	if len(plan.Steps) != 1 {
+		t.Fatalf("got %d steps, want 1", len(plan.Steps))
+	}
+	step := plan.Steps[0].Result
+	if step.Classification.Algorithm != AlgoInstant || !step.HasWarning(WarnOfflineAnalysis) {
+		t.Errorf("step = %s with %v, want INSTANT with an offline warning", step.Classification.Algorithm, step.WarningMessages())
+	}
+}
+
+func TestSchemaDump(t *testing.T) {
+	dump, err := NewSchemaDump(offlineUsersTable + ";\n" +
+		"CREATE TABLE shop.orders (id INT NOT NULL PRIMARY KEY, user_id INT NOT NULL, KEY (user_id), " +
+		"CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE) ENGINE=InnoDB;")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	users, err := dump.TableMetadata("shop", "USERS")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users.Columns) != 2 || len(users.InboundForeignKeys) != 1 {
+		t.Fatalf("users: %d columns, %d inbound foreign keys", len(users.Columns), len(users.InboundForeignKeys))
+	}
+	if fk := users.InboundForeignKeys[0]; fk.ChildTable != "orders" || fk.DeleteRule != "CASCADE" {
+		t.Errorf("inbound foreign key = %+v", fk)
+	}
+	if _, err := dump.TableMetadata("other", "users"); err == nil || !strings.Contains(err.Error(), "shop.users, shop.orders") {
+		t.Errorf("error = %v, want the tables in the dump", err)
+	}
+
+	// The whole pipeline runs against the dump.
+	parsed, err := parser.Parse("ALTER TABLE shop.users DROP COLUMN nick")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	version := v8_0_35
+	result, err := AnalyzeOffline(parsed, nil, Options{Version: &version, Metadata: dump})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.HasWarning(WarnColumnNotFound) || containsWarning(result.WarningMessages(), "column checks were skipped") {
+		t.Errorf("expected the columns to be checked, got %v", result.WarningMessages())
+	}
+}
+
+// CREATE TABLE ... AS SELECT reads the source's metadata from the database that qualifies
+// it, and looks nothing up when the SELECT has no FROM.
+func TestAnalyzeOffline_CreateTableAsSelectSource(t *testing.T) {
+	dump, err := NewSchemaDump(offlineUsersTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Version: &v8_0_35, Metadata: dump, Database: "reports"}
+
+	parsed, err := parser.Parse("CREATE TABLE users_archive AS SELECT * FROM shop.users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := AnalyzeOffline(parsed, nil, opts)
+	if err != nil {
+		t.Fatalf("qualified source: %v", err)
+	}
+	if result.TableMeta.Database != "shop" || result.TableMeta.Table != "users" ||
+		!containsWarning(result.WarningMessages(), "from shop.users") {
+		t.Errorf("metadata %s.%s, warnings %v", result.TableMeta.Database, result.TableMeta.Table, result.WarningMessages())
+	}
+
+	parsed, err = parser.Parse("CREATE TABLE consts AS SELECT 1 AS one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AnalyzeOffline(parsed, nil, opts); err != nil {
+		t.Errorf("no FROM: %v, want no lookup of the new table", err)
+	}
+}
+
+// sizedTables is a MetadataSource of tables with the given row counts, 1 KB a row.
+type sizedTables map[string]int64
+
+func (s sizedTables) TableMetadata(database, table string) (*mysql.TableMetadata, error) {
+	rows, ok := s[database+"."+table]
+	if !ok {
+		return nil, fmt.Errorf("table %s.%s not found", database, table)
+	}
+	return &mysql.TableMetadata{Database: database, Table: table, Engine: "InnoDB", RowCount: rows, DataLength: rows * 1024, AvgRowLength: 1024}, nil
+}
+
+func TestAnalyzeOffline_CreateTableAsSelectUnion(t *testing.T) {
+	opts := Options{Version: &v8_0_35, Database: "shop", Metadata: sizedTables{"shop.orders": 1_000_000, "archive.orders": 500_000}}
+
+	parsed, err := parser.Parse("CREATE TABLE all_orders AS SELECT * FROM orders UNION ALL SELECT * FROM archive.orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := AnalyzeOffline(parsed, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AffectedRows != 1_500_000 || result.Risk != RiskDangerous {
+		t.Errorf("AffectedRows, Risk = %d, %s, want both tables' 1500000 rows, DANGEROUS", result.AffectedRows, result.Risk)
+	}
+	if !containsWarning(result.WarningMessages(), "from orders, archive.orders") || !containsWarning(result.WarningMessages(), "every table of the UNION") {
+		t.Errorf("warnings should name both tables, got %v", result.WarningMessages())
+	}
+
+	parsed, err = parser.Parse("CREATE TABLE recent AS SELECT * FROM (SELECT * FROM orders ORDER BY id DESC LIMIT 10) AS t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err = AnalyzeOffline(parsed, nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !result.HasWarning(WarnCTASSizeUnknown) || !containsWarning(result.WarningMessages(), "copies an unknown number of rows") ||
+		containsWarning(result.WarningMessages(), "~0 rows") {
+		t.Errorf("derived table: want the size reported as unknown, got %v", result.WarningMessages())
+	}
+}
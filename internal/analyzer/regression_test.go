@@ -305,6 +305,23 @@ func TestRegression_FullPipeline(t *testing.T) {
 			wantClusterSubstr: []string{"Aurora"},
 		},
 
+		{
+			// Aurora writer with binlogs: gh-ost is kept, with a caution instead of the override.
+			name:     "12c. Aurora writer binlog enabled COPY 2GB → GH-OST + caution",
+			sql:      "ALTER TABLE orders MODIFY COLUMN existing_col TEXT",
+			version:  vAurora,
+			topoType: topology.AuroraWriter,
+			topoSetup: func(info *topology.Info) {
+				info.AuroraBinlogEnabled = true
+			},
+			tableSizeBytes:      large,
+			wantRisk:            RiskDangerous,
+			wantMethod:          ExecGhost,
+			wantAlternative:     ExecPtOSC,
+			wantClusterSubstr:   []string{"--allow-on-master", "cluster writer endpoint"},
+			wantNoClusterSubstr: []string{"NOT compatible"},
+		},
+
 		// ─────────────────────────────────────────────────────────────────
 		// Group 4 — RDS (cloud-managed but not Aurora)
 		// ─────────────────────────────────────────────────────────────────
@@ -622,29 +639,29 @@ func TestRegression_FullPipeline(t *testing.T) {
 
 			// Warnings — must contain
 			for _, substr := range tc.wantWarningSubstr {
-				if !containsWarning(result.Warnings, substr) {
+				if !containsWarning(result.WarningMessages(), substr) {
 					t.Errorf("Warnings missing %q\n  got: %v", substr, result.Warnings)
 				}
 			}
 
 			// Cluster warnings — must contain
 			for _, substr := range tc.wantClusterSubstr {
-				if !containsWarning(result.ClusterWarnings, substr) {
-					t.Errorf("ClusterWarnings missing %q\n  got: %v", substr, result.ClusterWarnings)
+				if !containsWarning(result.ClusterWarningMessages(), substr) {
+					t.Errorf("ClusterWarnings missing %q\n  got: %v", substr, result.ClusterWarningMessages())
 				}
 			}
 
 			// Warnings — must NOT contain
 			for _, substr := range tc.wantNoWarningSubstr {
-				if containsWarning(result.Warnings, substr) {
+				if containsWarning(result.WarningMessages(), substr) {
 					t.Errorf("Warnings should NOT contain %q\n  got: %v", substr, result.Warnings)
 				}
 			}
 
 			// Cluster warnings — must NOT contain
 			for _, substr := range tc.wantNoClusterSubstr {
-				if containsWarning(result.ClusterWarnings, substr) {
-					t.Errorf("ClusterWarnings should NOT contain %q\n  got: %v", substr, result.ClusterWarnings)
+				if containsWarning(result.ClusterWarningMessages(), substr) {
+					t.Errorf("ClusterWarnings should NOT contain %q\n  got: %v", substr, result.ClusterWarningMessages())
 				}
 			}
 		})
@@ -0,0 +1,141 @@
+package analyzer
+
+import (
+	"time"
+
+	"github.com/nethalo/dbsafe/internal/parser"
+	"github.com/nethalo/dbsafe/internal/topology"
+)
+
+// Weights of the RiskScore factors. They add up to 100; each factor contributes between
+// 0 and its weight. Changing a weight changes every score, so the scenarios in
+// risk_score_test.go pin them down.
+const (
+	scoreWeightAlgorithm = 30 // DDL: INSTANT 0, INPLACE 10 (20 if it rebuilds), COPY 30. DML: share of rows affected
+	scoreWeightLock      = 15 // DDL: NONE 0, SHARED 10, EXCLUSIVE 15. DML: row locks held by one unchunked statement
+	scoreWeightSize      = 20 // table size, 5 points per decade from 100 MB (not counted for INSTANT DDL)
+	scoreWeightDeps      = 10 // 5 for triggers, 5 for foreign keys (either direction)
+	scoreWeightTopology  = 10 // Galera 10, Group Replication 7, Aurora 5, replica 3
+	scoreWeightDuration  = 15 // 5 from 1 minute, 10 from 10 minutes, 15 from 1 hour
+)
+
+// RiskScore condenses a result into a number from 0 (trivial) to 100 (riskiest), for
+// sorting migrations in a backlog. It is the sum of the weighted factors above and is
+// deterministic for a given result. It complements Risk rather than replacing it: a
+// DANGEROUS result can still score low when the danger is data loss on a small table.
+func RiskScore(result *Result) int {
+	score := scoreAlgorithm(result) + scoreLock(result) + scoreSize(result) +
+		scoreDependencies(result) + scoreTopology(result) + scoreDuration(result)
+	return min(score, 100)
+}
+
+func scoreAlgorithm(result *Result) int {
+	if result.StatementType == parser.DML {
+		if !result.HasWhere {
+			return scoreWeightAlgorithm
+		}
+		pct := min(max(result.AffectedPct, 0), 100)
+		return int(pct * scoreWeightAlgorithm / 100)
+	}
+	switch result.Classification.Algorithm {
+	case AlgoInstant:
+		return 0
+	case AlgoInplace:
+		if result.Classification.RebuildsTable {
+			return 20
+		}
+		return 10
+	default: // COPY, or DEPENDS where the worst case applies
+		return scoreWeightAlgorithm
+	}
+}
+
+func scoreLock(result *Result) int {
+	if result.StatementType == parser.DML {
+		if result.Method == ExecChunked {
+			return 0
+		}
+		switch {
+		case result.AffectedRows >= 100_000:
+			return scoreWeightLock
+		case result.AffectedRows >= 10_000:
+			return 10
+		}
+		return 0
+	}
+	switch result.Classification.Lock {
+	case LockNone:
+		return 0
+	case LockShared, LockDepends:
+		return 10
+	default:
+		return scoreWeightLock
+	}
+}
+
+func scoreSize(result *Result) int {
+	if result.TableMeta == nil {
+		return 0
+	}
+	if result.StatementType == parser.DDL && result.Classification.Algorithm == AlgoInstant {
+		return 0
+	}
+	const mb = 1024 * 1024
+	size := result.TableMeta.TotalSize()
+	switch {
+	case size >= 100*1024*mb:
+		return scoreWeightSize
+	case size >= 10*1024*mb:
+		return 15
+	case size >= 1024*mb:
+		return 10
+	case size >= 100*mb:
+		return 5
+	}
+	return 0
+}
+
+func scoreDependencies(result *Result) int {
+	meta := result.TableMeta
+	if meta == nil {
+		return 0
+	}
+	score := 0
+	if len(meta.Triggers) > 0 {
+		score += scoreWeightDeps / 2
+	}
+	if len(meta.ForeignKeys)+len(meta.InboundForeignKeys) > 0 {
+		score += scoreWeightDeps / 2
+	}
+	return score
+}
+
+func scoreTopology(result *Result) int {
+	if result.Topology == nil {
+		return 0
+	}
+	switch result.Topology.Type {
+	case topology.Galera:
+		return scoreWeightTopology
+	case topology.GroupRepl:
+		return 7
+	case topology.AuroraWriter, topology.AuroraReader:
+		return 5
+	case topology.AsyncReplica, topology.SemiSyncReplica:
+		return 3
+	}
+	return 0
+}
+
+func scoreDuration(result *Result) int {
+	d := EstimateDuration(result)
+	switch {
+	case d >= time.Hour:
+		return scoreWeightDuration
+	case d >= 10*time.Minute:
+		return 10
+	case d >= time.Minute:
+		return 5
+	}
+	return 0
+}
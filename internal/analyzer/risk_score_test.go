@@ -0,0 +1,140 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/parser"
+	"github.com/nethalo/dbsafe/internal/topology"
+)
+
+func TestRiskScore_Scenarios(t *testing.T) {
+	const (
+		mb = int64(1024 * 1024)
+		gb = 1024 * mb
+	)
+	withDeps := func(meta *mysql.TableMetadata) *mysql.TableMetadata {
+		meta.Triggers = []mysql.TriggerInfo{{Name: "trg_audit", Event: "UPDATE", Timing: "AFTER"}}
+		meta.InboundForeignKeys = []mysql.ForeignKeyInfo{{Name: "fk_child", ChildTable: "child"}}
+		return meta
+	}
+
+	tests := []struct {
+		name   string
+		result *Result
+		want   int
+	}{
+		{
+			name: "instant add column",
+			result: &Result{
+				StatementType:  parser.DDL,
+				TableMeta:      &mysql.TableMetadata{DataLength: 100 * gb},
+				Topology:       &topology.Info{Type: topology.Standalone},
+				Classification: DDLClassification{Algorithm: AlgoInstant, Lock: LockNone},
+				Method:         ExecDirect,
+			},
+			want: 0,
+		},
+		{
+			name: "inplace rebuild on Aurora writer",
+			result: &Result{
+				StatementType:  parser.DDL,
+				TableMeta:      &mysql.TableMetadata{DataLength: 5 * gb},
+				Topology:       &topology.Info{Type: topology.AuroraWriter},
+				Classification: DDLClassification{Algorithm: AlgoInplace, Lock: LockNone, RebuildsTable: true},
+				Method:         ExecDirect,
+			},
+			want: 20 + 0 + 10 + 0 + 5 + 5, // ~3m rebuild
+		},
+		{
+			name: "copy with triggers and FKs on Galera",
+			result: &Result{
+				StatementType:  parser.DDL,
+				TableMeta:      withDeps(&mysql.TableMetadata{DataLength: 100 * gb}),
+				Topology:       &topology.Info{Type: topology.Galera},
+				Classification: DDLClassification{Algorithm: AlgoCopy, Lock: LockShared, RebuildsTable: true},
+				Method:         ExecPtOSC,
+			},
+			want: 30 + 10 + 20 + 10 + 10 + 15,
+		},
+		{
+			name: "every factor at its maximum",
+			result: &Result{
+				StatementType:  parser.DDL,
+				TableMeta:      withDeps(&mysql.TableMetadata{DataLength: 200 * gb}),
+				Topology:       &topology.Info{Type: topology.Galera},
+				Classification: DDLClassification{Algorithm: AlgoCopy, Lock: LockExclusive, RebuildsTable: true},
+				Method:         ExecDirect,
+			},
+			want: 100,
+		},
+		{
+			name: "unchunked DELETE without WHERE on a replica",
+			result: &Result{
+				StatementType: parser.DML,
+				TableMeta:     &mysql.TableMetadata{DataLength: 50 * mb},
+				Topology:      &topology.Info{Type: topology.AsyncReplica},
+				AffectedRows:  200_000,
+				AffectedPct:   100,
+				Method:        ExecDirect,
+			},
+			want: 30 + 15 + 0 + 0 + 3 + 0,
+		},
+		{
+			name: "chunked DELETE of 40% of the rows",
+			result: &Result{
+				StatementType: parser.DML,
+				TableMeta:     &mysql.TableMetadata{DataLength: 100 * mb, IndexLength: 20 * mb},
+				Topology:      &topology.Info{Type: topology.Standalone},
+				HasWhere:      true,
+				AffectedRows:  200_000,
+				AffectedPct:   40,
+				Method:        ExecChunked,
+			},
+			want: 12 + 0 + 5 + 0 + 0 + 0,
+		},
+		{
+			name:   "missing metadata and topology",
+			result: &Result{StatementType: parser.DDL, Classification: DDLClassification{Algorithm: AlgoInplace, Lock: LockNone}},
+			want:   10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RiskScore(tt.result); got != tt.want {
+				t.Errorf("RiskScore() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRiskScore_AlgorithmOrdering(t *testing.T) {
+	score := func(algo Algorithm, rebuilds bool) int {
+		return RiskScore(&Result{
+			StatementType:  parser.DDL,
+			TableMeta:      &mysql.TableMetadata{DataLength: 2 * 1024 * 1024 * 1024},
+			Topology:       &topology.Info{Type: topology.Standalone},
+			Classification: DDLClassification{Algorithm: algo, Lock: LockNone, RebuildsTable: rebuilds},
+			Method:         ExecDirect,
+		})
+	}
+	instant, inplace, rebuild, copyAlgo := score(AlgoInstant, false), score(AlgoInplace, false), score(AlgoInplace, true), score(AlgoCopy, true)
+	if !(instant < inplace && inplace < rebuild && rebuild < copyAlgo) {
+		t.Errorf("scores should order INSTANT < INPLACE < INPLACE rebuild < COPY, got %d, %d, %d, %d",
+			instant, inplace, rebuild, copyAlgo)
+	}
+}
+
+func TestRiskScore_FromAnalyze(t *testing.T) {
+	instant := Analyze(ddlInput(parser.AddColumn, v8_0_35, 10*1024*1024, topology.Standalone))
+	if got := RiskScore(instant); got != 0 {
+		t.Errorf("INSTANT ADD COLUMN score = %d, want 0", got)
+	}
+
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 50*1024*1024*1024, topology.Galera)
+	input.Parsed.NewColumnType = "bigint"
+	copyResult := Analyze(input)
+	if got := RiskScore(copyResult); got < 70 {
+		t.Errorf("COPY of a 50 GB table on Galera score = %d, want >= 70", got)
+	}
+}
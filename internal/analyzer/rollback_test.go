@@ -251,6 +251,24 @@ func TestGenerateDDLRollback(t *testing.T) {
 			wantRollbackSQL:   "RENAME TABLE `db`.`new_name` TO `db`.`old_name`;",
 			wantRollbackNotes: "metadata-only operation",
 		},
+		{
+			name: "RENAME TABLE swap rollback",
+			input: Input{
+				Parsed: &parser.ParsedSQL{
+					Type:         parser.DDL,
+					DDLOp:        parser.RenameTable,
+					Database:     "db",
+					Table:        "orders",
+					NewTableName: "orders_old",
+					RenamePairs: []parser.RenamePair{
+						{From: "orders", To: "orders_old"},
+						{From: "orders_new", To: "orders"},
+					},
+				},
+			},
+			wantRollbackSQL:   "RENAME TABLE `db`.`orders` TO `db`.`orders_new`, `db`.`orders_old` TO `db`.`orders`;",
+			wantRollbackNotes: "one atomic RENAME TABLE",
+		},
 		{
 			name: "RENAME TABLE rollback without new name",
 			input: Input{
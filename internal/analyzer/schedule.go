@@ -0,0 +1,164 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TrafficProfile is a team's relative traffic for each hour of the day (0–23, in the
+// timezone the team schedules in), with optional labels for what runs at that hour, e.g.
+// "batch jobs". Only the weights' relative sizes matter.
+type TrafficProfile struct {
+	Weights [24]float64
+	Labels  [24]string
+}
+
+// ScheduleWindow is a recommended start time that fits an operation's estimated duration
+// into the quietest hours of a TrafficProfile.
+type ScheduleWindow struct {
+	StartHour      int           // hour to start at, 0–23
+	EndHour        int           // hour boundary the operation finishes before, 0–23
+	Duration       time.Duration // the estimated duration the window was fitted to
+	Recommendation string        // e.g. "Estimated 40-minute operation; start at 02:00 to finish before the 03:00 batch jobs."
+}
+
+// ParseTrafficProfile parses an hourly traffic profile: entries separated by commas or
+// newlines, each HOUR=WEIGHT or FIRST-LAST=WEIGHT (inclusive, wrapping past midnight),
+// optionally followed by a label: "0-1=1, 2=1, 3=8 batch jobs, 4-6=2, 7-23=10". Text after
+// # is a comment. Hours not listed get the highest weight given, so they are never
+// mistaken for quiet ones.
+func ParseTrafficProfile(spec string) (*TrafficProfile, error) {
+	var p TrafficProfile
+	var listed [24]bool
+	maxWeight := 0.0
+	entries := 0
+	for _, line := range strings.Split(spec, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		for _, entry := range strings.Split(line, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			hours, rest, ok := strings.Cut(entry, "=")
+			if !ok {
+				return nil, fmt.Errorf("traffic profile entry %q: want HOUR=WEIGHT or FIRST-LAST=WEIGHT", entry)
+			}
+			first, last, err := parseHourRange(strings.TrimSpace(hours))
+			if err != nil {
+				return nil, fmt.Errorf("traffic profile entry %q: %w", entry, err)
+			}
+			fields := strings.Fields(rest)
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("traffic profile entry %q: missing weight", entry)
+			}
+			weight, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil || weight < 0 {
+				return nil, fmt.Errorf("traffic profile entry %q: weight must be a number ≥ 0", entry)
+			}
+			label := strings.Join(fields[1:], " ")
+			for h := first; ; h = (h + 1) % 24 {
+				p.Weights[h], p.Labels[h], listed[h] = weight, label, true
+				if h == last {
+					break
+				}
+			}
+			maxWeight = math.Max(maxWeight, weight)
+			entries++
+		}
+	}
+	if entries == 0 {
+		return nil, fmt.Errorf("traffic profile is empty")
+	}
+	for h := range p.Weights {
+		if !listed[h] {
+			p.Weights[h] = maxWeight
+		}
+	}
+	return &p, nil
+}
+
+// parseHourRange parses "H" or "H-H" with hours 0–23.
+func parseHourRange(s string) (first, last int, err error) {
+	a, b, isRange := strings.Cut(s, "-")
+	if first, err = parseHour(a); err != nil {
+		return 0, 0, err
+	}
+	last = first
+	if isRange {
+		if last, err = parseHour(b); err != nil {
+			return 0, 0, err
+		}
+	}
+	return first, last, nil
+}
+
+func parseHour(s string) (int, error) {
+	h, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || h < 0 || h > 23 {
+		return 0, fmt.Errorf("hour %q must be 0–23", s)
+	}
+	return h, nil
+}
+
+// RecommendWindow fits an operation of duration d, rounded up to whole hours, into the
+// consecutive hours with the lowest total traffic (the earliest such start on a tie), and
+// says what it finishes before: a labeled hour, or the hour traffic picks up. Returns nil
+// when d is under a minute, too short to be worth scheduling.
+func RecommendWindow(p *TrafficProfile, d time.Duration) *ScheduleWindow {
+	if p == nil || d < time.Minute {
+		return nil
+	}
+	hours := int(math.Ceil(d.Hours()))
+
+	best, bestLoad := 0, math.Inf(1)
+	for start := 0; start < 24; start++ {
+		load := 0.0
+		for i := 0; i < hours && i < 24; i++ {
+			load += p.Weights[(start+i)%24]
+		}
+		if load < bestLoad {
+			best, bestLoad = start, load
+		}
+	}
+	w := &ScheduleWindow{StartHour: best, EndHour: (best + hours) % 24, Duration: d}
+
+	estimate := fmt.Sprintf("Estimated %s operation", durationAdjective(d))
+	if hours >= 24 {
+		w.Recommendation = fmt.Sprintf("%s: longer than a day, so no window avoids the busy hours. Start at %02d:00, at the quietest point of the traffic profile.",
+			estimate, w.StartHour)
+		return w
+	}
+
+	windowMax := 0.0
+	for i := 0; i < hours; i++ {
+		windowMax = math.Max(windowMax, p.Weights[(best+i)%24])
+	}
+	var finish string
+	switch {
+	case p.Labels[w.EndHour] != "":
+		finish = fmt.Sprintf("to finish before the %02d:00 %s", w.EndHour, p.Labels[w.EndHour])
+	case p.Weights[w.EndHour] > windowMax:
+		finish = fmt.Sprintf("to finish before traffic picks up at %02d:00", w.EndHour)
+	default:
+		finish = fmt.Sprintf("to finish by %02d:00", w.EndHour)
+	}
+	w.Recommendation = fmt.Sprintf("%s; start at %02d:00 %s.", estimate, w.StartHour, finish)
+	return w
+}
+
+// durationAdjective formats d for "Estimated ... operation": "40-minute", "2-hour",
+// "2h 10m".
+func durationAdjective(d time.Duration) string {
+	minutes := int(math.Ceil(d.Minutes()))
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%d-minute", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%d-hour", minutes/60)
+	default:
+		return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+	}
+}
@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nethalo/dbsafe/internal/parser"
+	"github.com/nethalo/dbsafe/internal/topology"
+)
+
+func TestParseTrafficProfile(t *testing.T) {
+	p, err := ParseTrafficProfile("0-1=1, 2=1, 3=8 batch jobs  # nightly ETL\n4-6=2\n22-23=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Weights[0] != 1 || p.Weights[3] != 8 || p.Weights[5] != 2 || p.Weights[23] != 5 {
+		t.Errorf("Weights = %v", p.Weights)
+	}
+	if p.Labels[3] != "batch jobs" || p.Labels[2] != "" {
+		t.Errorf("Labels[2], Labels[3] = %q, %q, want \"\" and \"batch jobs\"", p.Labels[2], p.Labels[3])
+	}
+	if p.Weights[12] != 8 {
+		t.Errorf("unlisted hour weight = %v, want the highest given (8)", p.Weights[12])
+	}
+
+	wrapped, err := ParseTrafficProfile("22-2=1, 3-21=9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wrapped.Weights[23] != 1 || wrapped.Weights[0] != 1 || wrapped.Weights[2] != 1 || wrapped.Weights[3] != 9 {
+		t.Errorf("wrapped range weights = %v", wrapped.Weights)
+	}
+
+	for _, bad := range []string{"", "# only a comment", "3", "24=1", "2=high", "1-x=2", "5=-1", "5="} {
+		if _, err := ParseTrafficProfile(bad); err == nil {
+			t.Errorf("ParseTrafficProfile(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestRecommendWindow(t *testing.T) {
+	profile, err := ParseTrafficProfile("0-1=3, 2=1, 3=6 batch jobs, 4-5=1, 6-8=4, 9-23=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		d     time.Duration
+		start int
+		want  string
+	}{
+		{40 * time.Minute, 2, "Estimated 40-minute operation; start at 02:00 to finish before the 03:00 batch jobs."},
+		{2 * time.Hour, 4, "Estimated 2-hour operation; start at 04:00 to finish before traffic picks up at 06:00."},
+		{2*time.Hour + 10*time.Minute, 4, "Estimated 2h 10m operation; start at 04:00 to finish by 07:00."},
+		{30 * time.Hour, 0, "Estimated 30-hour operation: longer than a day"},
+	}
+	for _, tt := range tests {
+		w := RecommendWindow(profile, tt.d)
+		if w == nil {
+			t.Fatalf("RecommendWindow(%s) = nil", tt.d)
+		}
+		if w.StartHour != tt.start || !strings.HasPrefix(w.Recommendation, tt.want) {
+			t.Errorf("RecommendWindow(%s) = %02d:00 %q, want %02d:00 %q", tt.d, w.StartHour, w.Recommendation, tt.start, tt.want)
+		}
+	}
+
+	if w := RecommendWindow(profile, 30*time.Second); w != nil {
+		t.Errorf("RecommendWindow(30s) = %+v, want nil", w)
+	}
+	if w := RecommendWindow(nil, time.Hour); w != nil {
+		t.Errorf("RecommendWindow without a profile = %+v, want nil", w)
+	}
+}
+
+func TestAnalyze_TrafficProfile(t *testing.T) {
+	profile, err := ParseTrafficProfile("0-2=1, 3=5 backups, 4-23=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 100 GB through gh-ost: well over a minute.
+	input := ddlInput(parser.ModifyColumn, v8_0_35, 100*1024*1024*1024, topology.Standalone)
+	input.Parsed.NewColumnType = "bigint"
+	input.TrafficProfile = profile
+	result := Analyze(input)
+	if result.Schedule == nil || !strings.Contains(result.Schedule.Recommendation, "start at ") {
+		t.Errorf("Schedule = %+v, want a recommended start", result.Schedule)
+	}
+
+	// INSTANT: nothing to schedule.
+	instant := ddlInput(parser.AddColumn, v8_0_35, 100*1024*1024*1024, topology.Standalone)
+	instant.TrafficProfile = profile
+	if result := Analyze(instant); result.Schedule != nil {
+		t.Errorf("Schedule = %+v for an INSTANT operation, want nil", result.Schedule)
+	}
+}
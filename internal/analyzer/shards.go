@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ShardResult is the analysis of a statement on one shard, or why there is none.
+type ShardResult struct {
+	Name   string
+	Result *Result // nil when Err is set
+	Err    error
+}
+
+// Size returns the size of the shard's table, 0 when unknown.
+func (s *ShardResult) Size() int64 {
+	if s.Result == nil || s.Result.TableMeta == nil {
+		return 0
+	}
+	return s.Result.TableMeta.TotalSize()
+}
+
+// ShardPlan is the analysis of one statement on several shards holding the same table:
+// each shard's result and the worst case, which the rollout has to be planned for.
+type ShardPlan struct {
+	Shards   []ShardResult // in the order given
+	Worst    *ShardResult  // highest risk, then largest table; nil when no shard was analyzed
+	Risk     RiskLevel     // highest risk among the shards; DANGEROUS when one couldn't be analyzed
+	Longest  time.Duration // longest EstimateDuration of a shard
+	Total    time.Duration // sum of the estimates, for running the shards one after another
+	Warnings []Warning     // how the shards differ, and the ones that failed
+}
+
+// AggregateShards combines the per-shard analyses of a statement: the worst case, the
+// duration estimates, a SHARD_DIVERGENCE warning naming the shards whose risk or method
+// differs from the rest (those whose table is above the dangerous size first), and a
+// SHARD_ANALYSIS_FAILED warning for each shard that couldn't be analyzed.
+func AggregateShards(shards []ShardResult) *ShardPlan {
+	plan := &ShardPlan{Shards: shards, Risk: RiskSafe}
+	var analyzed []*ShardResult
+	for i := range plan.Shards {
+		s := &plan.Shards[i]
+		if s.Err != nil || s.Result == nil {
+			plan.Risk = RiskDangerous
+			plan.Warnings = append(plan.Warnings, newWarning(WarnShardAnalysisFailed, fmt.Sprintf(
+				"Shard %s couldn't be analyzed: %v. Its risk is unknown, so the combined risk is DANGEROUS until it can be.",
+				s.Name, s.Err,
+			)))
+			continue
+		}
+		analyzed = append(analyzed, s)
+		if riskRank[s.Result.Risk] > riskRank[plan.Risk] {
+			plan.Risk = s.Result.Risk
+		}
+		if plan.Worst == nil || riskRank[s.Result.Risk] > riskRank[plan.Worst.Result.Risk] ||
+			(s.Result.Risk == plan.Worst.Result.Risk && s.Size() > plan.Worst.Size()) {
+			plan.Worst = s
+		}
+		d := EstimateDuration(s.Result)
+		plan.Total += d
+		if d > plan.Longest {
+			plan.Longest = d
+		}
+	}
+
+	if msg := shardDivergence(analyzed); msg != "" {
+		plan.Warnings = append([]Warning{newWarning(WarnShardDivergence, msg)}, plan.Warnings...)
+	}
+	return plan
+}
+
+// shardDivergence describes how the risk and the recommended method differ across the
+// shards, "" when they agree.
+func shardDivergence(shards []*ShardResult) string {
+	byRisk := map[RiskLevel][]string{}
+	byMethod := map[ExecutionMethod][]string{}
+	var methods []ExecutionMethod
+	var oversized []string
+	for _, s := range shards {
+		byRisk[s.Result.Risk] = append(byRisk[s.Result.Risk], fmt.Sprintf("%s (%s)", s.Name, humanBytes(s.Size())))
+		if _, ok := byMethod[s.Result.Method]; !ok {
+			methods = append(methods, s.Result.Method)
+		}
+		byMethod[s.Result.Method] = append(byMethod[s.Result.Method], s.Name)
+		if s.Result.Risk == RiskDangerous && s.Result.dangerousSize > 0 && s.Size() > s.Result.dangerousSize {
+			oversized = append(oversized, s.Name)
+		}
+	}
+
+	var parts []string
+	if len(byRisk) > 1 {
+		var groups []string
+		for _, risk := range []RiskLevel{RiskDangerous, RiskCaution, RiskSafe} {
+			if names := byRisk[risk]; len(names) > 0 {
+				groups = append(groups, fmt.Sprintf("%s on %s", risk, strings.Join(names, ", ")))
+			}
+		}
+		parts = append(parts, "The risk differs across shards: "+strings.Join(groups, "; ")+".")
+		if len(oversized) > 0 {
+			parts = append(parts, fmt.Sprintf(
+				"On %s the table is above the dangerous size (%s), which is what makes the operation DANGEROUS there: "+
+					"plan the rollout for those shards, with the method their analysis recommends, rather than for the smaller ones.",
+				strings.Join(oversized, ", "), humanBytes(shards[0].Result.dangerousSize),
+			))
+		}
+	}
+	if len(methods) > 1 {
+		var groups []string
+		for _, m := range methods {
+			groups = append(groups, fmt.Sprintf("%s on %s", m, strings.Join(byMethod[m], ", ")))
+		}
+		parts = append(parts, "The recommended method differs: "+strings.Join(groups, "; ")+
+			". Use each shard's own command, or the worst shard's method everywhere for a single procedure.")
+	}
+	return strings.Join(parts, " ")
+}
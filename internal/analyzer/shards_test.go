@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAggregateShards(t *testing.T) {
+	shard := func(name string, size int64) ShardResult {
+		input := batchInput(t, "ALTER TABLE testdb.test MODIFY COLUMN existing_col TEXT")
+		meta := *input.Meta
+		meta.DataLength, meta.IndexLength = size, 0
+		input.Meta = &meta
+		return ShardResult{Name: name, Result: Analyze(input)}
+	}
+	small, large := shard("shard1", 50*1024*1024), shard("shard2", 5*1024*1024*1024)
+	if small.Result.Risk == RiskDangerous || large.Result.Risk != RiskDangerous {
+		t.Fatalf("test setup: risks %s and %s, want the large shard alone DANGEROUS", small.Result.Risk, large.Result.Risk)
+	}
+
+	plan := AggregateShards([]ShardResult{small, large, shard("shard3", 60*1024*1024)})
+	if plan.Risk != RiskDangerous || plan.Worst == nil || plan.Worst.Name != "shard2" {
+		t.Fatalf("plan = risk %s, worst %+v; want DANGEROUS on shard2", plan.Risk, plan.Worst)
+	}
+	if plan.Longest != EstimateDuration(large.Result) || plan.Total < plan.Longest {
+		t.Errorf("durations = %v longest, %v total", plan.Longest, plan.Total)
+	}
+	if len(plan.Warnings) != 1 || plan.Warnings[0].Code != WarnShardDivergence {
+		t.Fatalf("warnings = %v, want one SHARD_DIVERGENCE", plan.Warnings)
+	}
+	msg := plan.Warnings[0].Message
+	for _, want := range []string{"DANGEROUS on shard2 (5.0 GB)", "On shard2 the table is above the dangerous size (1.0 GB)", "The recommended method differs"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("divergence warning missing %q:\n%s", want, msg)
+		}
+	}
+
+	same := AggregateShards([]ShardResult{small, shard("shard3", 60*1024*1024)})
+	if len(same.Warnings) != 0 || same.Worst.Name != "shard3" {
+		t.Errorf("agreeing shards: worst %s, warnings %v; want the larger shard and no warnings", same.Worst.Name, same.Warnings)
+	}
+
+	failed := AggregateShards([]ShardResult{small, {Name: "shard4", Err: errors.New("connection refused")}})
+	if failed.Risk != RiskDangerous || failed.Worst.Name != "shard1" || len(failed.Warnings) != 1 ||
+		failed.Warnings[0].Code != WarnShardAnalysisFailed || failed.Warnings[0].Severity != SeverityCritical {
+		t.Errorf("failed shard: risk %s, warnings %v; want DANGEROUS with a critical SHARD_ANALYSIS_FAILED", failed.Risk, failed.Warnings)
+	}
+
+	if none := AggregateShards(nil); none.Risk != RiskSafe || none.Worst != nil {
+		t.Errorf("no shards: %+v", none)
+	}
+}
@@ -0,0 +1,479 @@
+package analyzer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/parser"
+	"github.com/nethalo/dbsafe/internal/topology"
+)
+
+// ErrUnsupportedStatement is returned by AnalyzeStatement for statements dbsafe doesn't
+// analyze (INSERT, LOAD DATA, plain CREATE TABLE).
+var ErrUnsupportedStatement = errors.New("statement is not analyzed by dbsafe")
+
+// Options configures AnalyzeStatement.
+type Options struct {
+	Database      string          // the table's database, over the one qualifying it in the SQL; that one when empty
+	ChunkSize     int             // DML chunk size; 0 uses DefaultChunkSize
+	SleepSeconds  *float64        // pause between chunks in generated scripts; nil uses the default, 0 doesn't pause
+	MaxReplicaLag int             // when > 0, generate a replica-lag-aware shell script (seconds)
+	ExplainRows   bool            // run EXPLAIN over the connection to estimate affected rows
+	SafePtOSC     bool            // emit pt-osc as --dry-run then --execute --no-drop-old-table
+	WithHooks     bool            // add gh-ost --hooks-path / pt-osc --plugin and a commented hook scaffold
+	NoOnlineTools bool            // never recommend gh-ost or pt-osc: large rebuilds run natively in a maintenance window
+	PtOSCChunking PtOSCChunking   // pt-osc --chunk-size/--chunk-time/--chunk-size-limit overrides
+	Idempotent    bool            // generate an idempotent stored procedure wrapper for DDL
+	ProjectSchema bool            // project the table's CREATE TABLE after an ALTER TABLE (Result.ProjectedSchema)
+	Connection    *ConnectionInfo // optional: connection details for generated commands
+	Verbose       bool            // debug logging during topology detection
+
+	// DangerousSizeThreshold and CautionSizeThreshold (bytes) move the table-size
+	// boundaries of the DDL risk bands; 0 uses the defaults (see Input).
+	DangerousSizeThreshold int64
+	CautionSizeThreshold   int64
+
+	// Version, when set, is used instead of the server's VERSION(). It is required by
+	// AnalyzeOffline, which has no server to ask.
+	Version *mysql.ServerVersion
+
+	// Topology, when set, is the topology AnalyzeOffline assumes instead of the one of the
+	// version's flavor (standalone, Galera for percona-xtradb-cluster, an Aurora writer
+	// for aurora-mysql). A live analysis detects it.
+	Topology topology.Type
+
+	// Metadata, when set, supplies the table's metadata instead of the server, e.g. a
+	// SchemaDump. AnalyzeOffline uses it when it isn't given the table's metadata.
+	Metadata mysql.MetadataSource
+
+	// PtOSCRecursionMethod is the --recursion-method of generated pt-osc commands:
+	// processlist, hosts, dsn[=DSN] or none. Empty picks one from the topology.
+	PtOSCRecursionMethod string
+
+	// GhostReplication sets --assume-master-host and --replica-server-id on generated gh-ost
+	// commands, for replication topologies gh-ost can't work out by itself.
+	GhostReplication GhostReplication
+
+	// FreeDiskBytes is the free space on the server's data directory filesystem, checked
+	// against the disk estimate. When 0 and the server runs on this machine (Connection is
+	// a socket or a loopback host), it is read from the filesystem of @@datadir. It also
+	// bounds the binlog volume estimate when the binary logs are in the data directory.
+	FreeDiskBytes int64
+
+	// TrafficProfile, when set, adds a recommended start time to the result (see
+	// RecommendWindow).
+	TrafficProfile *TrafficProfile
+
+	// CopyBytesPerSec is the assumed native copy speed for the duration estimates; 0 uses
+	// the default (see Input).
+	CopyBytesPerSec int64
+
+	// WriteRateSample, when > 0, is how long the table's write rate is sampled for before
+	// an ALTER that isn't INSTANT (see mysql.SampleWriteRate). A hot table is sent to an
+	// online schema change tool below the size thresholds, and a cold one can take a
+	// blocking INPLACE ALTER directly above them. Only used with a live connection.
+	WriteRateSample time.Duration
+
+	// Observer, when set, is notified of each completed analysis (see AnalysisObserver).
+	Observer AnalysisObserver
+
+	// Trace records each classification decision in Result.Trace.
+	Trace bool
+}
+
+// AnalyzeStatement parses sqlText, loads topology, version, table metadata and
+// foreign_key_checks from db, and runs the full analysis pipeline — the same steps
+// as `dbsafe plan`, which uses it with `dbsafe serve`. ctx is checked between loading steps.
+func AnalyzeStatement(ctx context.Context, db *sql.DB, sqlText string, opts Options) (*Result, error) {
+	parsed, err := parser.Parse(sqlText)
+	if err != nil {
+		return nil, fmt.Errorf("SQL parse error: %w", err)
+	}
+	return AnalyzeParsed(ctx, db, parsed, opts)
+}
+
+// AnalyzeParsed is AnalyzeStatement for an already-parsed statement.
+func AnalyzeParsed(ctx context.Context, db *sql.DB, parsed *parser.ParsedSQL, opts Options) (*Result, error) {
+	if name, ok := UnsupportedOperation(parsed); ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedStatement, name)
+	}
+
+	database := opts.Database
+	if database == "" {
+		database = parsed.Database
+	}
+	// Tablespace operations, view/routine/trigger/event definitions and account management
+	// have no associated table.
+	if database == "" && parsed.DDLOp != parser.AlterTablespace && parsed.DDLOp != parser.ObjectDefinition && parsed.DDLOp != parser.AccountManagement {
+		return nil, fmt.Errorf("database not specified: qualify the table (e.g. mydb.users) or set Options.Database")
+	}
+
+	input, err := loadInput(ctx, db, parsed, database, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	estimatedRows, explainErr := explainEstimate(db, parsed, opts)
+	input.EstimatedRows = estimatedRows
+
+	result := analyze(input)
+	if explainErr != nil {
+		result.addWarning(WarnExplainFailed, fmt.Sprintf("EXPLAIN failed: %v", explainErr))
+	}
+
+	addIdempotentSP(result, parsed, opts)
+	addProjectedSchema(result, input, opts)
+	observe(input.Observer, result)
+	return result, nil
+}
+
+// explainEstimate runs EXPLAIN when opts asks for it: for DML with a WHERE clause to
+// estimate the affected rows, for CREATE TABLE ... AS SELECT on the SELECT to size the
+// copy. The estimate comes from the optimizer's index statistics and is approximate.
+func explainEstimate(db *sql.DB, parsed *parser.ParsedSQL, opts Options) (int64, error) {
+	if !opts.ExplainRows {
+		return 0, nil
+	}
+	switch {
+	case parsed.Type == parser.DML && parsed.HasWhere:
+		return mysql.EstimateRowsAffected(db, parsed.RawSQL)
+	case parsed.DDLOp == parser.CreateTableAsSelect && parsed.SelectSQL != "":
+		return mysql.EstimateRowsAffected(db, parsed.SelectSQL)
+	}
+	return 0, nil
+}
+
+// addIdempotentSP generates the idempotent stored procedure wrapper for DDL when opts asks for it.
+func addIdempotentSP(result *Result, parsed *parser.ParsedSQL, opts Options) {
+	if opts.Idempotent && result.StatementType == parser.DDL {
+		sp, warn := GenerateIdempotentSP(parsed, result.Database, result.Table)
+		result.IdempotentSP = sp
+		if warn != "" {
+			result.addWarning(WarnIdempotentUnsupported, warn)
+		}
+	}
+}
+
+// ProjectedSchema is a table's CREATE TABLE before and after an ALTER TABLE, normalized
+// alike so they compare line by line (see parser.ProjectAlter).
+type ProjectedSchema struct {
+	Before string
+	After  string
+}
+
+// Lines returns the projected definition with the lines the ALTER changes marked: "+ "
+// for a line it adds, "- " for one it removes and "  " for one it keeps.
+func (p *ProjectedSchema) Lines() []string {
+	before, after := strings.Split(p.Before, "\n"), strings.Split(p.After, "\n")
+	// common[i][j] is the length of the longest common subsequence of before[i:] and after[j:].
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			lines = append(lines, "  "+after[j])
+			i, j = i+1, j+1
+		case j < len(after) && (i == len(before) || common[i][j+1] >= common[i+1][j]):
+			lines = append(lines, "+ "+after[j])
+			j++
+		default:
+			lines = append(lines, "- "+before[i])
+			i++
+		}
+	}
+	return lines
+}
+
+// addProjectedSchema projects the table's definition after an ALTER TABLE when opts asks
+// for it. It needs the table's CREATE TABLE: offline, from the schema file.
+func addProjectedSchema(result *Result, input Input, opts Options) {
+	if !opts.ProjectSchema || !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(input.Parsed.RawSQL)), "ALTER TABLE") {
+		return
+	}
+	if input.Meta.CreateTable == "" {
+		result.addWarning(WarnSchemaProjection, "No projected schema: the table's CREATE TABLE is unknown (offline, pass it with --schema-file).")
+		return
+	}
+	before, after, err := parser.ProjectAlter(input.Meta.CreateTable, input.Parsed.RawSQL)
+	if err != nil {
+		result.addWarning(WarnSchemaProjection, fmt.Sprintf("No projected schema: the ALTER could not be applied to the table's definition: %v.", err))
+		return
+	}
+	result.ProjectedSchema = &ProjectedSchema{Before: before, After: after}
+}
+
+// longTransactionSeconds is how long a transaction must have been open to be reported when
+// metadata locks can't be inspected directly.
+const longTransactionSeconds = 60
+
+// metadataTable is the database and table whose metadata the analysis of parsed needs: the
+// source table for CREATE TABLE ... LIKE / AS SELECT, which target a table that doesn't
+// exist yet, in its own database when the statement qualifies it.
+func metadataTable(parsed *parser.ParsedSQL, database string) (string, string) {
+	if parsed.SourceTable != "" {
+		if parsed.SourceDatabase != "" {
+			database = parsed.SourceDatabase
+		}
+		return database, parsed.SourceTable
+	}
+	return database, parsed.Table
+}
+
+// sourceMetadata loads the metadata of the table the analysis of parsed needs (see
+// metadataTable). CREATE TABLE ... AS SELECT ... UNION copies the rows of every SELECT's
+// table, so their row counts and sizes are added up.
+func sourceMetadata(source mysql.MetadataSource, parsed *parser.ParsedSQL, database string) (*mysql.TableMetadata, error) {
+	meta, err := source.TableMetadata(metadataTable(parsed, database))
+	if err != nil || len(parsed.UnionSources) < 2 {
+		return meta, err
+	}
+	union := *meta
+	for _, t := range parsed.UnionSources[1:] {
+		db := database
+		if t.Database != "" {
+			db = t.Database
+		}
+		m, err := source.TableMetadata(db, t.Table)
+		if err != nil {
+			return nil, err
+		}
+		union.RowCount += m.RowCount
+		union.DataLength += m.DataLength
+		union.IndexLength += m.IndexLength
+	}
+	if union.RowCount > 0 {
+		union.AvgRowLength = union.DataLength / union.RowCount
+	}
+	return &union, nil
+}
+
+// newTable reports whether parsed creates a table without copying an existing one: a plain
+// CREATE TABLE, or CREATE TABLE ... AS SELECT from no table. There is no metadata to load.
+func newTable(parsed *parser.ParsedSQL) bool {
+	return parsed.DDLOp == parser.CreateTable || (parsed.DDLOp == parser.CreateTableAsSelect && parsed.SourceTable == "")
+}
+
+// loadInput loads what the analysis needs from the server — topology, table metadata,
+// version and the relevant server variables — and combines it with opts. ctx is checked
+// between loading steps. opts.Version, when set, replaces the server's version.
+func loadInput(ctx context.Context, db *sql.DB, parsed *parser.ParsedSQL, database string, opts Options) (Input, error) {
+	if err := ctx.Err(); err != nil {
+		return Input{}, err
+	}
+	topo, err := topology.Detect(db, opts.Verbose)
+	if err != nil {
+		return Input{}, fmt.Errorf("topology detection failed: %w", err)
+	}
+
+	// Collect table metadata (skip for tablespace operations and object definitions — no table involved).
+	// CREATE TABLE ... LIKE / AS SELECT target a table that doesn't exist yet, so
+	// collect metadata for the source table instead.
+	if err := ctx.Err(); err != nil {
+		return Input{}, err
+	}
+	var meta *mysql.TableMetadata
+	var source mysql.MetadataSource = mysql.LiveMetadata{DB: db}
+	if opts.Metadata != nil {
+		source = opts.Metadata
+	}
+	switch {
+	case parsed.DDLOp == parser.AlterTablespace || parsed.DDLOp == parser.ObjectDefinition || parsed.DDLOp == parser.AccountManagement:
+		meta = &mysql.TableMetadata{}
+	case newTable(parsed):
+		// The table doesn't exist yet.
+		meta = &mysql.TableMetadata{Database: database, Table: parsed.Table}
+	default:
+		meta, err = sourceMetadata(source, parsed, database)
+		if err != nil {
+			return Input{}, fmt.Errorf("metadata collection failed: %w", err)
+		}
+	}
+
+	var version mysql.ServerVersion
+	if opts.Version != nil {
+		version = *opts.Version
+	} else if version, err = mysql.GetServerVersion(db); err != nil {
+		return Input{}, fmt.Errorf("version detection failed: %w", err)
+	}
+
+	// Query the session's foreign_key_checks (whether ADD FOREIGN KEY requires COPY or
+	// INPLACE) and unique_checks, and their global values. Default to checks enabled
+	// (COPY required) if they can't be read.
+	var checks *mysql.CheckSettings
+	if s, err := mysql.GetCheckSettings(db); err == nil {
+		checks = &s
+	}
+
+	// max_connections and Threads_running scale the generated pt-osc load thresholds;
+	// they stay 0 (default thresholds) if they can't be read.
+	var maxConnections, threadsRunning int64
+	if parsed.Type == parser.DDL {
+		maxConnections, _ = mysql.GetVariableInt(db, "max_connections")
+		if val, err := mysql.GetStatus(db, "Threads_running"); err == nil {
+			threadsRunning, _ = strconv.ParseInt(val, 10, 64)
+		}
+	}
+	serverConfig := mysql.GetServerConfig(db)
+
+	// Free space for the disk estimate: the override, or statfs of the data directory when
+	// the server is local. A remote server's filesystem can't be read over SQL.
+	freeDisk := opts.FreeDiskBytes
+	if freeDisk == 0 && serverConfig.DataDir != "" && localServer(opts.Connection) {
+		freeDisk, _ = mysql.FreeDiskBytes(serverConfig.DataDir)
+	}
+	// The binary logs usually live in the data directory; when they don't, their own
+	// filesystem is read, again only for a local server.
+	binlogFree := freeDisk
+	if dir := serverConfig.BinlogDir; dir != "" && !inDir(dir, serverConfig.DataDir) {
+		binlogFree = 0
+		if localServer(opts.Connection) {
+			binlogFree, _ = mysql.FreeDiskBytes(dir)
+		}
+	}
+
+	// Pre-flight: sessions holding a metadata lock on the table would block the ALTER, and
+	// everything queued behind it. When performance_schema can't be read, fall back to
+	// listing long-running transactions, which may hold one. ANALYZE and CHECK TABLE only
+	// take shared metadata locks, which don't queue behind open transactions.
+	var lockHolders []mysql.MetadataLockHolder
+	var longTransactions []mysql.TransactionInfo
+	if parsed.Type == parser.DDL && parsed.SourceTable == "" && !newTable(parsed) &&
+		parsed.DDLOp != parser.AlterTablespace && parsed.DDLOp != parser.ObjectDefinition && parsed.DDLOp != parser.AccountManagement &&
+		parsed.DDLOp != parser.AnalyzeTable && parsed.DDLOp != parser.CheckTable {
+		if lockHolders, err = mysql.GetMetadataLockHolders(ctx, db, database, parsed.Table); err != nil {
+			if err := ctx.Err(); err != nil {
+				return Input{}, err
+			}
+			longTransactions, _ = mysql.GetLongRunningTransactions(ctx, db, longTransactionSeconds)
+		}
+	}
+
+	// The table's write rate decides between a direct ALTER and an online schema change
+	// tool when the ALTER blocks writes; an INSTANT one doesn't need the sample.
+	if opts.WriteRateSample > 0 && parsed.Type == parser.DDL && meta.Table != "" && parsed.SourceTable == "" &&
+		!newTable(parsed) && parsed.DDLOp != parser.AnalyzeTable && parsed.DDLOp != parser.CheckTable {
+		v := version
+		if ClassifyDDLWithContext(parsed, v.Major, v.Minor, v.EffectivePatch()).Algorithm != AlgoInstant {
+			if rate, err := mysql.SampleWriteRate(ctx, db, database, meta.Table, opts.WriteRateSample); err == nil {
+				withRate := *meta
+				withRate.WriteRate = &rate
+				meta = &withRate
+			} else if err := ctx.Err(); err != nil {
+				return Input{}, err
+			}
+		}
+	}
+
+	// Generated commands connect over TLS when this connection does, including a
+	// --tls=preferred one the server accepted TLS for.
+	conn := opts.Connection
+	if conn != nil && !conn.TLS {
+		if encrypted, err := mysql.SessionTLS(db); err == nil && encrypted {
+			withTLS := *conn
+			withTLS.TLS = true
+			conn = &withTLS
+		}
+	}
+
+	// transaction_isolation decides whether chunked DELETEs risk gap-lock deadlocks;
+	// tx_isolation is its name before MySQL 5.7.20.
+	var txIsolation string
+	if parsed.Type == parser.DML {
+		txIsolation, _ = mysql.GetVariable(db, "transaction_isolation")
+		if txIsolation == "" {
+			txIsolation, _ = mysql.GetVariable(db, "tx_isolation")
+		}
+	}
+
+	return Input{
+		Parsed:                   parsed,
+		Meta:                     meta,
+		Topo:                     topo,
+		Version:                  version,
+		ChunkSize:                opts.ChunkSize,
+		SleepSeconds:             opts.SleepSeconds,
+		MaxReplicaLag:            opts.MaxReplicaLag,
+		SafePtOSC:                opts.SafePtOSC,
+		WithHooks:                opts.WithHooks,
+		NoOnlineTools:            opts.NoOnlineTools,
+		PtOSCChunking:            opts.PtOSCChunking,
+		PtOSCRecursionMethod:     opts.PtOSCRecursionMethod,
+		GhostReplication:         opts.GhostReplication,
+		DangerousSizeThreshold:   opts.DangerousSizeThreshold,
+		CautionSizeThreshold:     opts.CautionSizeThreshold,
+		CopyBytesPerSec:          opts.CopyBytesPerSec,
+		ForeignKeyChecksDisabled: checks != nil && !checks.ForeignKeyChecks,
+		UniqueChecksDisabled:     checks != nil && !checks.UniqueChecks,
+		CheckSettings:            checks,
+		MaxConnections:           maxConnections,
+		ThreadsRunning:           threadsRunning,
+		TxIsolation:              txIsolation,
+		ServerConfig:             serverConfig,
+		FreeDiskBytes:            freeDisk,
+		BinlogFreeBytes:          binlogFree,
+		TrafficProfile:           opts.TrafficProfile,
+		Trace:                    opts.Trace,
+		Observer:                 opts.Observer,
+		MetadataLockHolders:      lockHolders,
+		LongTransactions:         longTransactions,
+		Connection:               conn,
+	}, nil
+}
+
+// localServer reports whether the connection is to a server on this machine: a Unix
+// socket or a loopback host.
+func localServer(conn *ConnectionInfo) bool {
+	if conn == nil {
+		return false
+	}
+	switch conn.Host {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return conn.Socket != ""
+}
+
+// inDir reports whether path is dir or inside it.
+func inDir(path, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// UnsupportedOperation reports whether dbsafe has nothing to analyze for the statement
+// (INSERT, LOAD DATA), returning the operation name for messages.
+func UnsupportedOperation(parsed *parser.ParsedSQL) (string, bool) {
+	switch {
+	case parsed.Type == parser.DML && parsed.DMLOp == parser.Insert:
+		return "INSERT", true
+	case parsed.Type == parser.DML && parsed.DMLOp == parser.LoadData:
+		return "LOAD DATA INFILE", true
+	}
+	return "", false
+}
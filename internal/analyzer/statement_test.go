@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nethalo/dbsafe/internal/mysql"
+	"github.com/nethalo/dbsafe/internal/mysql/mysqltest"
+	"github.com/nethalo/dbsafe/internal/parser"
+)
+
+func TestAnalyzeStatement_Unsupported(t *testing.T) {
+	for _, sqlText := range []string{
+		"INSERT INTO users (id) VALUES (1)",
+		"LOAD DATA INFILE '/tmp/users.csv' INTO TABLE users",
+	} {
+		// Unsupported statements are rejected before the connection is used.
+		_, err := AnalyzeStatement(context.Background(), nil, sqlText, Options{Database: "testdb"})
+		if !errors.Is(err, ErrUnsupportedStatement) {
+			t.Errorf("AnalyzeStatement(%q) error = %v, want ErrUnsupportedStatement", sqlText, err)
+		}
+	}
+}
+
+func TestAnalyzeStatement_ParseError(t *testing.T) {
+	_, err := AnalyzeStatement(context.Background(), nil, "ALTER TABLE", Options{})
+	if err == nil || !strings.Contains(err.Error(), "SQL parse error") {
+		t.Errorf("error = %v, want SQL parse error", err)
+	}
+}
+
+func TestAnalyzeStatement_MissingDatabase(t *testing.T) {
+	_, err := AnalyzeStatement(context.Background(), nil, "ALTER TABLE users ADD COLUMN x INT", Options{})
+	if err == nil || !strings.Contains(err.Error(), "database not specified") {
+		t.Errorf("error = %v, want database not specified", err)
+	}
+}
+
+func TestAnalyzeStatement_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := AnalyzeStatement(ctx, nil, "ALTER TABLE shop.users ADD COLUMN x INT", Options{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
+
+func TestAnalyze_DefaultChunkSize(t *testing.T) {
+	const sqlText = "UPDATE shop.events SET archived = 1"
+	parsed, err := parser.Parse(sqlText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	live, err := AnalyzeParsed(context.Background(), mysqltest.FakeServer(t, 500000), parsed, Options{})
+	if err != nil {
+		t.Fatalf("AnalyzeParsed: %v", err)
+	}
+	v := mysql.ServerVersion{Major: 8, Minor: 0, Patch: 36}
+	schema, err := NewSchemaDump("CREATE TABLE shop.events (id BIGINT NOT NULL PRIMARY KEY, archived TINYINT)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	offline, err := AnalyzeOffline(parsed, nil, Options{Version: &v, Metadata: schema})
+	if err != nil {
+		t.Fatalf("AnalyzeOffline: %v", err)
+	}
+
+	if live.Method != ExecChunked || live.ChunkSize != DefaultChunkSize || live.ChunkCount != 50 ||
+		!strings.Contains(live.GeneratedScript, "SET @batch_size = 10000;") {
+		t.Errorf("live: method %s, %d chunks of %d, want 50 chunks of %d:\n%s",
+			live.Method, live.ChunkCount, live.ChunkSize, DefaultChunkSize, live.GeneratedScript)
+	}
+	if offline.ChunkSize != DefaultChunkSize {
+		t.Errorf("offline chunk size = %d, want %d", offline.ChunkSize, DefaultChunkSize)
+	}
+}
+
+func TestUnsupportedOperation(t *testing.T) {
+	tests := []struct {
+		sql      string
+		wantName string
+		wantOK   bool
+	}{
+		{"INSERT INTO users (id) VALUES (1)", "INSERT", true},
+		{"CREATE TABLE t (id INT PRIMARY KEY)", "", false},
+		{"CREATE TABLE t2 LIKE t", "", false},
+		{"DELETE FROM users WHERE id = 1", "", false},
+	}
+	for _, tt := range tests {
+		parsed, err := parser.Parse(tt.sql)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.sql, err)
+		}
+		name, ok := UnsupportedOperation(parsed)
+		if name != tt.wantName || ok != tt.wantOK {
+			t.Errorf("UnsupportedOperation(%q) = (%q, %v), want (%q, %v)", tt.sql, name, ok, tt.wantName, tt.wantOK)
+		}
+	}
+}
+
+func TestLocalServer(t *testing.T) {
+	tests := []struct {
+		conn *ConnectionInfo
+		want bool
+	}{
+		{nil, false},
+		{&ConnectionInfo{Host: "localhost"}, true},
+		{&ConnectionInfo{Host: "127.0.0.1", Port: 3306}, true},
+		{&ConnectionInfo{Host: "db.example.com", Socket: "/var/run/mysqld/mysqld.sock"}, true},
+		{&ConnectionInfo{Host: "db.example.com"}, false},
+	}
+	for _, tt := range tests {
+		if got := localServer(tt.conn); got != tt.want {
+			t.Errorf("localServer(%+v) = %v, want %v", tt.conn, got, tt.want)
+		}
+	}
+}
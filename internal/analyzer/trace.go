@@ -0,0 +1,35 @@
+package analyzer
+
+import "fmt"
+
+// TraceStep is one decision in the classification of a DDL statement: the matrix
+// baseline, an override that fired, or the risk and method that followed.
+type TraceStep struct {
+	Step           string            // e.g. "ADD PRIMARY KEY on a nullable column"
+	Classification DDLClassification // the classification after the step
+	Detail         string            // risk and method, for the steps that decide them
+}
+
+// reclassify replaces the classification and records the step that did it.
+func (r *Result) reclassify(step string, cls DDLClassification) {
+	r.Classification = cls
+	r.trace(step, cls)
+}
+
+// trace records a step with its classification, when tracing.
+func (r *Result) trace(step string, cls DDLClassification) {
+	if r.tracing {
+		r.Trace = append(r.Trace, TraceStep{Step: step, Classification: cls})
+	}
+}
+
+// traceOutcome records the risk and method decided so far, when tracing.
+func (r *Result) traceOutcome(step string) {
+	if r.tracing {
+		r.Trace = append(r.Trace, TraceStep{
+			Step:           step,
+			Classification: r.Classification,
+			Detail:         fmt.Sprintf("risk %s, method %s", r.Risk, r.Method),
+		})
+	}
+}
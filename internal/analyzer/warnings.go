@@ -0,0 +1,327 @@
+package analyzer
+
+// WarningCode is a stable identifier for a kind of warning. Codes never change once
+// released, so CI pipelines can match on them instead of on the message text.
+type WarningCode string
+
+const (
+	// Live-server state
+	WarnMetadataLockHeld         WarningCode = "METADATA_LOCK_HELD"
+	WarnLongRunningTransactions  WarningCode = "LONG_RUNNING_TRANSACTIONS"
+	WarnOnlineAlterLogLimit      WarningCode = "ONLINE_ALTER_LOG_LIMIT"
+	WarnInplaceTmpdirSpace       WarningCode = "INPLACE_TMPDIR_SPACE"
+	WarnRedoUndoPressure         WarningCode = "REDO_UNDO_PRESSURE"
+	WarnExplainFailed            WarningCode = "EXPLAIN_FAILED"
+	WarnOfflineAnalysis          WarningCode = "OFFLINE_ANALYSIS"
+	WarnNoExplainEstimate        WarningCode = "NO_EXPLAIN_ESTIMATE"
+	WarnIdempotentUnsupported    WarningCode = "IDEMPOTENT_UNSUPPORTED"
+	WarnSchemaProjection         WarningCode = "SCHEMA_PROJECTION"
+	WarnDDLUnparsed              WarningCode = "DDL_UNPARSED"
+	WarnColumnAlreadyExists      WarningCode = "COLUMN_ALREADY_EXISTS"
+	WarnColumnNotFound           WarningCode = "COLUMN_NOT_FOUND"
+	WarnColumnOperationNoOp      WarningCode = "COLUMN_OPERATION_NOOP"
+	WarnColumnIfExistsSyntax     WarningCode = "COLUMN_IF_EXISTS_SYNTAX"
+	WarnLockWaitSyntax           WarningCode = "LOCK_WAIT_SYNTAX"
+	WarnLockWaitModifier         WarningCode = "LOCK_WAIT_MODIFIER"
+	WarnAlgorithmHintUnsupported WarningCode = "ALGORITHM_HINT_UNSUPPORTED"
+	WarnAlgorithmHintSlower      WarningCode = "ALGORITHM_HINT_SLOWER"
+	WarnLockHintUnsupported      WarningCode = "LOCK_HINT_UNSUPPORTED"
+	WarnInvisibleIndexTrial      WarningCode = "INVISIBLE_INDEX_TRIAL"
+	WarnCheckSettingsDiffer      WarningCode = "CHECK_SETTINGS_DIFFER"
+	WarnSQLModeDependent         WarningCode = "SQL_MODE_DEPENDENT"
+	WarnInsufficientDiskSpace    WarningCode = "INSUFFICIENT_DISK_SPACE"
+	WarnSecondaryLoad            WarningCode = "SECONDARY_LOAD"
+	WarnBinlogVolume             WarningCode = "BINLOG_VOLUME"
+	WarnUnsupportedVersion       WarningCode = "UNSUPPORTED_VERSION"
+	WarnUpgradeBenefit           WarningCode = "UPGRADE_BENEFIT"
+
+	// Operations forced onto a slower algorithm or a stronger lock
+	WarnFKChecksOnForcesCopy        WarningCode = "FK_CHECKS_ON_FORCES_COPY"
+	WarnNullablePKForcesCopy        WarningCode = "NULLABLE_PK_FORCES_COPY"
+	WarnCharsetChangeCopy           WarningCode = "CHARSET_CHANGE_COPY"
+	WarnColumnTypeChangeCopy        WarningCode = "COLUMN_TYPE_CHANGE_COPY"
+	WarnGeneratedExprChangeCopy     WarningCode = "GENERATED_EXPR_CHANGE_COPY"
+	WarnStoredGeneratedColumnCopy   WarningCode = "STORED_GENERATED_COLUMN_COPY"
+	WarnAutoIncrementSharedLock     WarningCode = "AUTO_INCREMENT_SHARED_LOCK"
+	WarnConvertCharsetSharedLock    WarningCode = "CONVERT_CHARSET_SHARED_LOCK"
+	WarnFirstFulltextIndexRebuild   WarningCode = "FIRST_FULLTEXT_INDEX_REBUILD"
+	WarnDropIndexedColumnRebuild    WarningCode = "DROP_INDEXED_COLUMN_REBUILD"
+	WarnCompressedInstantFallback   WarningCode = "COMPRESSED_INSTANT_FALLBACK"
+	WarnCompressedRebuildCost       WarningCode = "COMPRESSED_REBUILD_COST"
+	WarnDirectCopyTradeoff          WarningCode = "DIRECT_COPY_TRADEOFF"
+	WarnPtOSCForeignKeys            WarningCode = "PTOSC_FOREIGN_KEYS"
+	WarnTriggerDoubleWrite          WarningCode = "TRIGGER_DOUBLE_WRITE"
+	WarnLOBBackfillDeferred         WarningCode = "LOB_BACKFILL_DEFERRED"
+	WarnOrderByIneffective          WarningCode = "ORDER_BY_INEFFECTIVE"
+	WarnExpressionDefault           WarningCode = "EXPRESSION_DEFAULT"
+	WarnTriggerMetadataLock         WarningCode = "TRIGGER_METADATA_LOCK"
+	WarnKeyringRequired             WarningCode = "KEYRING_REQUIRED"
+	WarnTablespaceRenameUnsupported WarningCode = "TABLESPACE_RENAME_UNSUPPORTED"
+	WarnRowVersionLimit             WarningCode = "ROW_VERSION_LIMIT"
+	WarnMixedColumnChanges          WarningCode = "MIXED_COLUMN_CHANGES"
+	WarnMaintenanceWindowRequired   WarningCode = "MAINTENANCE_WINDOW_REQUIRED"
+	WarnVirtualColumnIndex          WarningCode = "VIRTUAL_COLUMN_INDEX"
+
+	// Statements that fail on existing data or hit a hard limit
+	WarnNumericNarrowing           WarningCode = "NUMERIC_NARROWING"
+	WarnNullablePKNullValues       WarningCode = "NULLABLE_PK_NULL_VALUES"
+	WarnSpatialSRIDValidation      WarningCode = "SPATIAL_SRID_VALIDATION"
+	WarnUniqueDuplicates           WarningCode = "UNIQUE_DUPLICATES"
+	WarnNullableUniqueColumn       WarningCode = "NULLABLE_UNIQUE_COLUMN"
+	WarnSecondAutoIncrement        WarningCode = "SECOND_AUTO_INCREMENT"
+	WarnCheckNotEnforced           WarningCode = "CHECK_NOT_ENFORCED"
+	WarnCheckConstraintViolation   WarningCode = "CHECK_CONSTRAINT_VIOLATION"
+	WarnCheckConstraintColumn      WarningCode = "CHECK_CONSTRAINT_COLUMN"
+	WarnGeneratedColumnDependent   WarningCode = "GENERATED_COLUMN_DEPENDENT"
+	WarnGeneratedColumnOrder       WarningCode = "GENERATED_COLUMN_ORDER"
+	WarnDropLastColumn             WarningCode = "DROP_LAST_COLUMN"
+	WarnNoStoredColumns            WarningCode = "NO_STORED_COLUMNS"
+	WarnForeignKeyIndexRequired    WarningCode = "FOREIGN_KEY_INDEX_REQUIRED"
+	WarnKeyTooLong                 WarningCode = "KEY_TOO_LONG"
+	WarnTooManyColumns             WarningCode = "TOO_MANY_COLUMNS"
+	WarnColumnLimitApproaching     WarningCode = "COLUMN_LIMIT_APPROACHING"
+	WarnRowSizeTooLarge            WarningCode = "ROW_SIZE_TOO_LARGE"
+	WarnRecordSizeTooLarge         WarningCode = "RECORD_SIZE_TOO_LARGE"
+	WarnPossibleRename             WarningCode = "POSSIBLE_RENAME"
+	WarnRenameSwapInvalid          WarningCode = "RENAME_SWAP_INVALID"
+	WarnEngineAttributeUnsupported WarningCode = "ENGINE_ATTRIBUTE_UNSUPPORTED"
+
+	// Partitioning
+	WarnPartitionKeyNotInUniqueKey WarningCode = "PARTITION_KEY_NOT_IN_UNIQUE_KEY"
+	WarnPartitionColumnDependency  WarningCode = "PARTITION_COLUMN_DEPENDENCY"
+	WarnPartitionColumnTypeChange  WarningCode = "PARTITION_COLUMN_TYPE_CHANGE"
+
+	// CREATE TABLE anti-patterns
+	WarnCreateTableAsSelect WarningCode = "CREATE_TABLE_AS_SELECT"
+	WarnCTASSizeUnknown     WarningCode = "CTAS_SIZE_UNKNOWN"
+	WarnNoPrimaryKey        WarningCode = "NO_PRIMARY_KEY"
+	WarnNonInnoDBEngine     WarningCode = "NON_INNODB_ENGINE"
+	WarnLegacyCharset       WarningCode = "LEGACY_CHARSET"
+	WarnNoRowFormat         WarningCode = "NO_ROW_FORMAT"
+
+	// DML
+	WarnNoWhereClause          WarningCode = "NO_WHERE_CLAUSE"
+	WarnTriggerFiresPerRow     WarningCode = "TRIGGER_FIRES_PER_ROW"
+	WarnChunkedDeleteGapLocks  WarningCode = "CHUNKED_DELETE_GAP_LOCKS"
+	WarnMultiTableDML          WarningCode = "MULTI_TABLE_DML"
+	WarnFKCascadeAmplification WarningCode = "FK_CASCADE_AMPLIFICATION"
+	WarnFKRestrictChildren     WarningCode = "FK_RESTRICT_CHILDREN"
+
+	// Maintenance statements
+	WarnAnalyzeTableFlush  WarningCode = "ANALYZE_TABLE_FLUSH"
+	WarnCheckTableReadLock WarningCode = "CHECK_TABLE_READ_LOCK"
+
+	// Batches of statements
+	WarnMergeableAlters WarningCode = "MERGEABLE_ALTERS"
+
+	// Shards
+	WarnShardDivergence     WarningCode = "SHARD_DIVERGENCE"
+	WarnShardAnalysisFailed WarningCode = "SHARD_ANALYSIS_FAILED"
+
+	// Topology and cluster
+	WarnRDSGhostFlags     WarningCode = "RDS_GHOST_FLAGS"
+	WarnGhostCutover      WarningCode = "GHOST_CUTOVER"
+	WarnPtOSCCutover      WarningCode = "PTOSC_CUTOVER"
+	WarnProxiedConnection WarningCode = "PROXIED_CONNECTION"
+	WarnProxyOnlineTools  WarningCode = "PROXY_ONLINE_TOOLS"
+	WarnAuroraReader      WarningCode = "AURORA_READER"
+	WarnAuroraGhostBinlog WarningCode = "AURORA_GHOST_BINLOG"
+	WarnGhostIncompatible WarningCode = "GHOST_INCOMPATIBLE"
+	WarnGaleraTOI         WarningCode = "GALERA_TOI"
+	WarnWriteSetTooLarge  WarningCode = "WRITE_SET_TOO_LARGE"
+	WarnFlowControlPaused WarningCode = "FLOW_CONTROL_PAUSED"
+	WarnGRMultiPrimary    WarningCode = "GR_MULTI_PRIMARY"
+	WarnMasterMaster      WarningCode = "MASTER_MASTER"
+	WarnReplicationLag    WarningCode = "REPLICATION_LAG"
+	WarnSemiSyncFallback  WarningCode = "SEMI_SYNC_FALLBACK"
+)
+
+// Severity grades a warning: CRITICAL means the statement will fail or destroy data as
+// written, WARNING that it blocks, is slow or may fail on existing rows, and INFO that it
+// is worth knowing but needs no action.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "INFO"
+	SeverityWarning  Severity = "WARNING"
+	SeverityCritical Severity = "CRITICAL"
+)
+
+// severityRank orders the severities, for filtering.
+var severityRank = map[Severity]int{SeverityInfo: 0, SeverityWarning: 1, SeverityCritical: 2}
+
+// warningSeverity is the severity of each code. Codes not listed are SeverityWarning.
+var warningSeverity = map[WarningCode]Severity{
+	WarnInplaceTmpdirSpace:       SeverityInfo,
+	WarnOfflineAnalysis:          SeverityInfo,
+	WarnColumnOperationNoOp:      SeverityInfo,
+	WarnAlgorithmHintSlower:      SeverityInfo,
+	WarnInvisibleIndexTrial:      SeverityInfo,
+	WarnDropIndexedColumnRebuild: SeverityInfo,
+	WarnCompressedRebuildCost:    SeverityInfo,
+	WarnDirectCopyTradeoff:       SeverityInfo,
+	WarnOrderByIneffective:       SeverityInfo,
+	WarnExpressionDefault:        SeverityInfo,
+	WarnTriggerMetadataLock:      SeverityInfo,
+	WarnNullableUniqueColumn:     SeverityInfo,
+	WarnCheckNotEnforced:         SeverityInfo,
+	WarnNoRowFormat:              SeverityInfo,
+	WarnTriggerFiresPerRow:       SeverityInfo,
+	WarnMergeableAlters:          SeverityInfo,
+	WarnMixedColumnChanges:       SeverityInfo,
+	WarnSecondaryLoad:            SeverityInfo,
+	WarnVirtualColumnIndex:       SeverityInfo,
+	WarnUpgradeBenefit:           SeverityInfo,
+	WarnLockWaitModifier:         SeverityInfo,
+	WarnSchemaProjection:         SeverityInfo,
+	WarnLOBBackfillDeferred:      SeverityInfo,
+	WarnGhostCutover:             SeverityInfo,
+	WarnPtOSCCutover:             SeverityInfo,
+
+	WarnMetadataLockHeld:            SeverityCritical,
+	WarnInsufficientDiskSpace:       SeverityCritical,
+	WarnColumnAlreadyExists:         SeverityCritical,
+	WarnColumnNotFound:              SeverityCritical,
+	WarnAlgorithmHintUnsupported:    SeverityCritical,
+	WarnLockHintUnsupported:         SeverityCritical,
+	WarnTablespaceRenameUnsupported: SeverityCritical,
+	WarnGeneratedColumnDependent:    SeverityCritical,
+	WarnGeneratedColumnOrder:        SeverityCritical,
+	WarnDropLastColumn:              SeverityCritical,
+	WarnNoStoredColumns:             SeverityCritical,
+	WarnNullablePKNullValues:        SeverityCritical,
+	WarnForeignKeyIndexRequired:     SeverityCritical,
+	WarnKeyTooLong:                  SeverityCritical,
+	WarnTooManyColumns:              SeverityCritical,
+	WarnRowSizeTooLarge:             SeverityCritical,
+	WarnRecordSizeTooLarge:          SeverityCritical,
+	WarnPossibleRename:              SeverityCritical,
+	WarnRenameSwapInvalid:           SeverityCritical,
+	WarnNoWhereClause:               SeverityCritical,
+	WarnPartitionKeyNotInUniqueKey:  SeverityCritical,
+	WarnPartitionColumnDependency:   SeverityCritical,
+	WarnEngineAttributeUnsupported:  SeverityCritical,
+	WarnSecondAutoIncrement:         SeverityCritical,
+	WarnCheckConstraintColumn:       SeverityCritical,
+	WarnShardAnalysisFailed:         SeverityCritical,
+	WarnAuroraReader:                SeverityCritical,
+	WarnWriteSetTooLarge:            SeverityCritical,
+}
+
+// Warning is one finding about a statement: a stable code, its severity and the
+// human-readable message shown in text output.
+type Warning struct {
+	Code     WarningCode
+	Severity Severity
+	Message  string
+}
+
+// String returns the warning's message.
+func (w Warning) String() string {
+	return w.Message
+}
+
+// newWarning builds a warning with the code's severity.
+func newWarning(code WarningCode, message string) Warning {
+	severity, ok := warningSeverity[code]
+	if !ok {
+		severity = SeverityWarning
+	}
+	return Warning{Code: code, Severity: severity, Message: message}
+}
+
+// addWarning appends a coded warning to the result.
+func (r *Result) addWarning(code WarningCode, message string) {
+	r.Warnings = append(r.Warnings, newWarning(code, message))
+}
+
+// WarningMessages returns the messages of the result's warnings, in order.
+func (r *Result) WarningMessages() []string {
+	return warningMessages(r.Warnings)
+}
+
+// addClusterWarning appends a coded topology or cluster warning to the result.
+func (r *Result) addClusterWarning(code WarningCode, message string) {
+	r.ClusterWarnings = append(r.ClusterWarnings, newWarning(code, message))
+}
+
+// ClusterWarningMessages returns the messages of the result's cluster warnings, in order.
+func (r *Result) ClusterWarningMessages() []string {
+	return warningMessages(r.ClusterWarnings)
+}
+
+// WarningMessages returns the messages of the plan's warnings, in order.
+func (p *DiffPlan) WarningMessages() []string {
+	return warningMessages(p.Warnings)
+}
+
+// WarningMessages returns the messages of the plan's warnings, in order.
+func (p *ShardPlan) WarningMessages() []string {
+	return warningMessages(p.Warnings)
+}
+
+func warningMessages(warnings []Warning) []string {
+	if len(warnings) == 0 {
+		return nil
+	}
+	messages := make([]string, len(warnings))
+	for i, w := range warnings {
+		messages[i] = w.Message
+	}
+	return messages
+}
+
+// FilterWarnings removes the result's warnings and cluster warnings below min.
+func (r *Result) FilterWarnings(min Severity) {
+	r.Warnings = filterWarnings(r.Warnings, min)
+	r.ClusterWarnings = filterWarnings(r.ClusterWarnings, min)
+}
+
+// FilterWarnings removes the warnings below min from the plan and each of its steps.
+func (p *DiffPlan) FilterWarnings(min Severity) {
+	p.Warnings = filterWarnings(p.Warnings, min)
+	for _, step := range p.Steps {
+		step.Result.FilterWarnings(min)
+	}
+}
+
+// FilterWarnings removes the warnings below min from the batch and each of its results.
+func (b *BatchResult) FilterWarnings(min Severity) {
+	b.Warnings = filterWarnings(b.Warnings, min)
+	for _, result := range b.Results {
+		result.FilterWarnings(min)
+	}
+}
+
+// FilterWarnings removes the warnings below min from the plan and each shard's result.
+func (p *ShardPlan) FilterWarnings(min Severity) {
+	p.Warnings = filterWarnings(p.Warnings, min)
+	for _, s := range p.Shards {
+		if s.Result != nil {
+			s.Result.FilterWarnings(min)
+		}
+	}
+}
+
+func filterWarnings(warnings []Warning, min Severity) []Warning {
+	var kept []Warning
+	for _, w := range warnings {
+		if severityRank[w.Severity] >= severityRank[min] {
+			kept = append(kept, w)
+		}
+	}
+	return kept
+}
+
+// HasWarning reports whether the result has a warning or cluster warning with the given
+// code.
+func (r *Result) HasWarning(code WarningCode) bool {
+	for _, warnings := range [][]Warning{r.Warnings, r.ClusterWarnings} {
+		for _, w := range warnings {
+			if w.Code == code {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package mysql
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// FreeDiskBytes is not supported on this platform: pass the free space explicitly instead.
+func FreeDiskBytes(dir string) (int64, error) {
+	return 0, fmt.Errorf("free disk space of %s can't be read on %s", dir, runtime.GOOS)
+}
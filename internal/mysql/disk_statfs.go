@@ -0,0 +1,19 @@
+//go:build linux || darwin
+
+package mysql
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// FreeDiskBytes returns the space available to unprivileged users on the filesystem that
+// holds dir. dir is a path on this machine, so the result only describes the server's
+// data directory when the server runs here.
+func FreeDiskBytes(dir string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, fmt.Errorf("statfs %s: %w", dir, err)
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
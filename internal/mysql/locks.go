@@ -0,0 +1,85 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// MetadataLockHolder is another session that holds, or waits for, a metadata lock on a table.
+type MetadataLockHolder struct {
+	ProcessID  int64
+	LockType   string // SHARED_READ, SHARED_UPGRADABLE, EXCLUSIVE, ...
+	LockStatus string // GRANTED or PENDING
+	Query      string // statement the session is running, "" when idle
+	TrxSeconds int64  // age of the session's open InnoDB transaction, 0 if none
+}
+
+// TransactionInfo is an open InnoDB transaction.
+type TransactionInfo struct {
+	ProcessID int64
+	Seconds   int64
+	Query     string
+}
+
+// GetMetadataLockHolders lists the other sessions holding or waiting for a metadata lock on
+// database.table, from performance_schema.metadata_locks joined with information_schema.innodb_trx.
+// Returns an error when performance_schema can't be queried.
+func GetMetadataLockHolders(ctx context.Context, db *sql.DB, database, table string) ([]MetadataLockHolder, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+			t.PROCESSLIST_ID,
+			ml.LOCK_TYPE,
+			ml.LOCK_STATUS,
+			COALESCE(t.PROCESSLIST_INFO, ''),
+			COALESCE(TIMESTAMPDIFF(SECOND, trx.trx_started, NOW()), 0)
+		FROM performance_schema.metadata_locks ml
+		JOIN performance_schema.threads t ON t.THREAD_ID = ml.OWNER_THREAD_ID
+		LEFT JOIN information_schema.innodb_trx trx ON trx.trx_mysql_thread_id = t.PROCESSLIST_ID
+		WHERE ml.OBJECT_TYPE = 'TABLE' AND ml.OBJECT_SCHEMA = ? AND ml.OBJECT_NAME = ?
+			AND t.PROCESSLIST_ID IS NOT NULL AND t.PROCESSLIST_ID <> CONNECTION_ID()
+		ORDER BY t.PROCESSLIST_ID
+	`, database, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []MetadataLockHolder
+	for rows.Next() {
+		var h MetadataLockHolder
+		if err := rows.Scan(&h.ProcessID, &h.LockType, &h.LockStatus, &h.Query, &h.TrxSeconds); err != nil {
+			return nil, err
+		}
+		result = append(result, h)
+	}
+	return result, rows.Err()
+}
+
+// GetLongRunningTransactions lists the InnoDB transactions of other sessions that have been
+// open for at least minSeconds, oldest first.
+func GetLongRunningTransactions(ctx context.Context, db *sql.DB, minSeconds int) ([]TransactionInfo, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+			trx_mysql_thread_id,
+			TIMESTAMPDIFF(SECOND, trx_started, NOW()),
+			COALESCE(trx_query, '')
+		FROM information_schema.innodb_trx
+		WHERE trx_started <= NOW() - INTERVAL ? SECOND
+			AND trx_mysql_thread_id <> CONNECTION_ID()
+		ORDER BY trx_started
+	`, minSeconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []TransactionInfo
+	for rows.Next() {
+		var t TransactionInfo
+		if err := rows.Scan(&t.ProcessID, &t.Seconds, &t.Query); err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
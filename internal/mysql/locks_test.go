@@ -0,0 +1,85 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestGetMetadataLockHolders(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"PROCESSLIST_ID", "LOCK_TYPE", "LOCK_STATUS", "PROCESSLIST_INFO", "trx_seconds"}).
+		AddRow(41, "SHARED_READ", "GRANTED", "", 312).
+		AddRow(57, "SHARED_UPGRADABLE", "PENDING", "ALTER TABLE users ADD INDEX idx_a (a)", 0)
+
+	mock.ExpectQuery("SELECT.*FROM performance_schema.metadata_locks").
+		WithArgs("testdb", "users").
+		WillReturnRows(rows)
+
+	holders, err := GetMetadataLockHolders(context.Background(), db, "testdb", "users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(holders) != 2 {
+		t.Fatalf("expected 2 holders, got %d", len(holders))
+	}
+	if holders[0].ProcessID != 41 || holders[0].LockType != "SHARED_READ" || holders[0].TrxSeconds != 312 {
+		t.Errorf("holders[0] = %+v", holders[0])
+	}
+	if holders[1].LockStatus != "PENDING" || holders[1].Query != "ALTER TABLE users ADD INDEX idx_a (a)" {
+		t.Errorf("holders[1] = %+v", holders[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestGetMetadataLockHolders_PerformanceSchemaUnavailable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT.*FROM performance_schema.metadata_locks").
+		WillReturnError(errors.New("SELECT command denied to user"))
+
+	if _, err := GetMetadataLockHolders(context.Background(), db, "testdb", "users"); err == nil {
+		t.Error("expected an error when performance_schema can't be queried")
+	}
+}
+
+func TestGetLongRunningTransactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"trx_mysql_thread_id", "seconds", "trx_query"}).
+		AddRow(9, 900, "")
+
+	mock.ExpectQuery("SELECT.*FROM information_schema.innodb_trx").
+		WithArgs(60).
+		WillReturnRows(rows)
+
+	trxs, err := GetLongRunningTransactions(context.Background(), db, 60)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trxs) != 1 || trxs[0].ProcessID != 9 || trxs[0].Seconds != 900 {
+		t.Errorf("transactions = %+v", trxs)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
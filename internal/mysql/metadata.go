@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 )
 
 // TableMetadata holds all metadata about a table needed for analysis.
@@ -24,6 +26,10 @@ type TableMetadata struct {
 	ForeignKeys        []ForeignKeyInfo
 	InboundForeignKeys []ForeignKeyInfo
 	Triggers           []TriggerInfo
+	CheckConstraints   []CheckConstraintInfo
+	Partitioning       *PartitionInfo // nil when the table isn't partitioned
+	TotalRowVersions   int64          // INSTANT ADD/DROP COLUMN row versions in use (8.0.29+); 0 when unknown
+	WriteRate          *float64       // rows written per second, sampled with SampleWriteRate; nil when not sampled
 }
 
 // TotalSize returns data + index size in bytes.
@@ -42,6 +48,8 @@ type IndexInfo struct {
 	Columns   []string
 	NonUnique bool
 	Type      string // BTREE, HASH, FULLTEXT, SPATIAL
+	SubParts  []int  // prefix length in characters per column (0 = whole column)
+	Invisible bool   // INVISIBLE: still maintained, but ignored by the optimizer
 }
 
 // ForeignKeyInfo describes a foreign key relationship.
@@ -55,6 +63,22 @@ type ForeignKeyInfo struct {
 	UpdateRule       string
 	ChildTable       string // populated only for inbound FKs (the table that owns the constraint)
 	ChildSchema      string // populated only for inbound FKs
+	ChildRows        int64  // inbound FKs only: the child table's estimated row count (TABLE_ROWS)
+	ChildAvgRowLen   int64  // inbound FKs only: the child table's AVG_ROW_LENGTH
+}
+
+// PartitionInfo describes how a table is partitioned.
+type PartitionInfo struct {
+	Method     string      // RANGE, RANGE COLUMNS, LIST, LIST COLUMNS, HASH, LINEAR HASH, KEY or LINEAR KEY
+	Expression string      // partitioning expression or column list, e.g. "to_days(`created_at`)" or "`created_at`"
+	Partitions []Partition // in definition order
+}
+
+// Partition is one partition of a partitioned table.
+type Partition struct {
+	Name        string
+	Description string // RANGE: the LESS THAN bound (e.g. "'2024-01-01'", "1000" or "MAXVALUE"); LIST: the values
+	RowCount    int64  // TABLE_ROWS estimate, including subpartitions
 }
 
 // TriggerInfo describes a trigger on a table.
@@ -65,6 +89,12 @@ type TriggerInfo struct {
 	Statement string
 }
 
+// CheckConstraintInfo is a CHECK constraint of the table.
+type CheckConstraintInfo struct {
+	Name       string
+	Expression string // CHECK_CLAUSE, e.g. (`price` > 0)
+}
+
 // ColumnInfo describes a single column in a table.
 type ColumnInfo struct {
 	Name              string
@@ -74,7 +104,10 @@ type ColumnInfo struct {
 	Position          int
 	CharacterSet      *string
 	Collation         *string
-	IsStoredGenerated bool // true when EXTRA contains "STORED GENERATED"
+	IsStoredGenerated bool   // true when EXTRA contains "STORED GENERATED"
+	GenerationExpr    string // GENERATION_EXPRESSION for generated columns, "" otherwise
+	AutoIncrement     bool   // true when EXTRA contains "auto_increment"
+	SRID              string // SRID attribute of a spatial column (MySQL 8.0+), "" if it has none
 }
 
 // escapeIdentifier safely escapes a MySQL identifier (database, table, column name)
@@ -87,6 +120,22 @@ func escapeIdentifier(identifier string) string {
 	return "`" + escaped + "`"
 }
 
+// MetadataSource supplies the metadata of a table: a live server (LiveMetadata) or a
+// captured schema.
+type MetadataSource interface {
+	TableMetadata(database, table string) (*TableMetadata, error)
+}
+
+// LiveMetadata is a MetadataSource that queries a server with GetTableMetadata.
+type LiveMetadata struct {
+	DB *sql.DB
+}
+
+// TableMetadata implements MetadataSource.
+func (l LiveMetadata) TableMetadata(database, table string) (*TableMetadata, error) {
+	return GetTableMetadata(l.DB, database, table)
+}
+
 // GetTableMetadata collects comprehensive metadata about a table.
 func GetTableMetadata(db *sql.DB, database, table string) (*TableMetadata, error) {
 	ctx := context.Background()
@@ -137,6 +186,10 @@ func GetTableMetadata(db *sql.DB, database, table string) (*TableMetadata, error
 	if err != nil {
 		return nil, fmt.Errorf("querying columns: %w", err)
 	}
+	// SRS_ID only exists on MySQL 8.0+: without it, spatial columns have no SRID to know.
+	if slices.ContainsFunc(meta.Columns, func(c ColumnInfo) bool { return spatialTypes[strings.ToLower(c.Type)] }) {
+		_ = getColumnSRIDs(ctx, db, database, table, meta.Columns)
+	}
 
 	// Indexes
 	meta.Indexes, err = getIndexes(ctx, db, database, table)
@@ -162,6 +215,19 @@ func GetTableMetadata(db *sql.DB, database, table string) (*TableMetadata, error
 		return nil, fmt.Errorf("querying triggers: %w", err)
 	}
 
+	// Partitions
+	meta.Partitioning, err = getPartitioning(ctx, db, database, table)
+	if err != nil {
+		return nil, fmt.Errorf("querying partitions: %w", err)
+	}
+
+	// Row versions. TOTAL_ROW_VERSIONS only exists on 8.0.29+; older servers leave it 0.
+	meta.TotalRowVersions, _ = getTotalRowVersions(ctx, db, database, table)
+
+	// CHECK constraints. CHECK_CONSTRAINTS only exists on MySQL 8.0.16+ (and MariaDB 10.2+);
+	// older servers don't enforce CHECK, so they have none.
+	meta.CheckConstraints, _ = getCheckConstraints(ctx, db, database, table)
+
 	return meta, nil
 }
 
@@ -171,7 +237,9 @@ func getIndexes(ctx context.Context, db *sql.DB, database, table string) ([]Inde
 			INDEX_NAME,
 			COLUMN_NAME,
 			NON_UNIQUE,
-			IFNULL(INDEX_TYPE, 'BTREE')
+			IFNULL(INDEX_TYPE, 'BTREE'),
+			IFNULL(SUB_PART, 0),
+			IS_VISIBLE = 'NO'
 		FROM information_schema.STATISTICS
 		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
 		ORDER BY INDEX_NAME, SEQ_IN_INDEX
@@ -186,8 +254,9 @@ func getIndexes(ctx context.Context, db *sql.DB, database, table string) ([]Inde
 
 	for rows.Next() {
 		var name, col, idxType string
-		var nonUnique bool
-		if err := rows.Scan(&name, &col, &nonUnique, &idxType); err != nil {
+		var nonUnique, invisible bool
+		var subPart int
+		if err := rows.Scan(&name, &col, &nonUnique, &idxType, &subPart, &invisible); err != nil {
 			return nil, err
 		}
 
@@ -196,10 +265,12 @@ func getIndexes(ctx context.Context, db *sql.DB, database, table string) ([]Inde
 				Name:      name,
 				NonUnique: nonUnique,
 				Type:      idxType,
+				Invisible: invisible,
 			}
 			order = append(order, name)
 		}
 		indexMap[name].Columns = append(indexMap[name].Columns, col)
+		indexMap[name].SubParts = append(indexMap[name].SubParts, subPart)
 	}
 
 	var result []IndexInfo
@@ -272,12 +343,17 @@ func getInboundForeignKeys(ctx context.Context, db *sql.DB, database, table stri
 			k.COLUMN_NAME,
 			k.REFERENCED_COLUMN_NAME,
 			r.DELETE_RULE,
-			r.UPDATE_RULE
+			r.UPDATE_RULE,
+			COALESCE(t.TABLE_ROWS, 0),
+			COALESCE(t.AVG_ROW_LENGTH, 0)
 		FROM information_schema.KEY_COLUMN_USAGE k
 		JOIN information_schema.REFERENTIAL_CONSTRAINTS r
 			ON r.CONSTRAINT_SCHEMA = k.TABLE_SCHEMA
 			AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
 			AND r.TABLE_NAME = k.TABLE_NAME
+		LEFT JOIN information_schema.TABLES t
+			ON t.TABLE_SCHEMA = k.TABLE_SCHEMA
+			AND t.TABLE_NAME = k.TABLE_NAME
 		WHERE k.REFERENCED_TABLE_SCHEMA = ? AND k.REFERENCED_TABLE_NAME = ?
 		ORDER BY k.TABLE_NAME, k.CONSTRAINT_NAME, k.ORDINAL_POSITION
 	`, database, table)
@@ -291,7 +367,8 @@ func getInboundForeignKeys(ctx context.Context, db *sql.DB, database, table stri
 
 	for rows.Next() {
 		var name, childSchema, childTable, col, refCol, deleteRule, updateRule string
-		if err := rows.Scan(&name, &childSchema, &childTable, &col, &refCol, &deleteRule, &updateRule); err != nil {
+		var childRows, childAvgRowLen int64
+		if err := rows.Scan(&name, &childSchema, &childTable, &col, &refCol, &deleteRule, &updateRule, &childRows, &childAvgRowLen); err != nil {
 			return nil, err
 		}
 
@@ -305,6 +382,8 @@ func getInboundForeignKeys(ctx context.Context, db *sql.DB, database, table stri
 				ReferencedSchema: database,
 				DeleteRule:       deleteRule,
 				UpdateRule:       updateRule,
+				ChildRows:        childRows,
+				ChildAvgRowLen:   childAvgRowLen,
 			}
 			order = append(order, key)
 		}
@@ -345,6 +424,120 @@ func getTriggers(ctx context.Context, db *sql.DB, database, table string) ([]Tri
 	return result, nil
 }
 
+// getCheckConstraints returns the table's CHECK constraints. CHECK_CONSTRAINTS has no
+// table name on MySQL, so they are found through TABLE_CONSTRAINTS.
+func getCheckConstraints(ctx context.Context, db *sql.DB, database, table string) ([]CheckConstraintInfo, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT cc.CONSTRAINT_NAME, cc.CHECK_CLAUSE
+		FROM information_schema.TABLE_CONSTRAINTS tc
+		JOIN information_schema.CHECK_CONSTRAINTS cc
+			ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+		WHERE tc.TABLE_SCHEMA = ? AND tc.TABLE_NAME = ? AND tc.CONSTRAINT_TYPE = 'CHECK'
+		ORDER BY cc.CONSTRAINT_NAME
+	`, database, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []CheckConstraintInfo
+	for rows.Next() {
+		var c CheckConstraintInfo
+		if err := rows.Scan(&c.Name, &c.Expression); err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
+
+// getPartitioning returns the table's partitioning, or nil when it isn't partitioned.
+// Subpartitions are folded into their partition.
+func getPartitioning(ctx context.Context, db *sql.DB, database, table string) (*PartitionInfo, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+			PARTITION_NAME,
+			PARTITION_METHOD,
+			IFNULL(PARTITION_EXPRESSION, ''),
+			IFNULL(PARTITION_DESCRIPTION, ''),
+			IFNULL(TABLE_ROWS, 0)
+		FROM information_schema.PARTITIONS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND PARTITION_NAME IS NOT NULL
+		ORDER BY PARTITION_ORDINAL_POSITION, SUBPARTITION_ORDINAL_POSITION
+	`, database, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var info *PartitionInfo
+	for rows.Next() {
+		var p Partition
+		var method, expr string
+		if err := rows.Scan(&p.Name, &method, &expr, &p.Description, &p.RowCount); err != nil {
+			return nil, err
+		}
+		if info == nil {
+			info = &PartitionInfo{Method: method, Expression: expr}
+		}
+		if n := len(info.Partitions); n > 0 && info.Partitions[n-1].Name == p.Name {
+			info.Partitions[n-1].RowCount += p.RowCount
+			continue
+		}
+		info.Partitions = append(info.Partitions, p)
+	}
+	return info, rows.Err()
+}
+
+// getTotalRowVersions returns the row versions INSTANT ADD/DROP COLUMN have used on the
+// table. For a partitioned table it is the highest count of any partition.
+func getTotalRowVersions(ctx context.Context, db *sql.DB, database, table string) (int64, error) {
+	name := database + "/" + table
+	escaped := strings.NewReplacer(`\`, `\\`, "_", `\_`, "%", `\%`).Replace(name)
+	var versions int64
+	err := db.QueryRowContext(ctx, `
+		SELECT IFNULL(MAX(TOTAL_ROW_VERSIONS), 0)
+		FROM information_schema.INNODB_TABLES
+		WHERE NAME = ? OR NAME LIKE ?
+	`, name, escaped+"#p#%").Scan(&versions)
+	return versions, err
+}
+
+// SampleWriteRate returns the rows written to the table per second (inserted, updated or
+// deleted), from performance_schema's table I/O counters read twice, interval apart. It
+// fails when performance_schema has no row for the table: the instrumentation may be off,
+// so an absent row doesn't mean the table is cold.
+func SampleWriteRate(ctx context.Context, db *sql.DB, database, table string, interval time.Duration) (float64, error) {
+	count := func() (int64, error) {
+		var n int64
+		err := db.QueryRowContext(ctx, `
+			SELECT COUNT_WRITE
+			FROM performance_schema.table_io_waits_summary_by_table
+			WHERE OBJECT_SCHEMA = ? AND OBJECT_NAME = ?
+		`, database, table).Scan(&n)
+		if err != nil {
+			return 0, fmt.Errorf("reading table I/O counters: %w", err)
+		}
+		return n, nil
+	}
+
+	before, err := count()
+	if err != nil {
+		return 0, err
+	}
+	select {
+	case <-time.After(interval):
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	after, err := count()
+	if err != nil {
+		return 0, err
+	}
+	// The counters restart when the summary is truncated between the reads.
+	return float64(max(after-before, 0)) / interval.Seconds(), nil
+}
+
 func getColumns(ctx context.Context, db *sql.DB, database, table string) ([]ColumnInfo, error) {
 	rows, err := db.QueryContext(ctx, `
 		SELECT
@@ -355,7 +548,8 @@ func getColumns(ctx context.Context, db *sql.DB, database, table string) ([]Colu
 			ORDINAL_POSITION,
 			CHARACTER_SET_NAME,
 			COLLATION_NAME,
-			EXTRA
+			EXTRA,
+			IFNULL(GENERATION_EXPRESSION, '')
 		FROM information_schema.COLUMNS
 		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
 		ORDER BY ORDINAL_POSITION
@@ -371,7 +565,7 @@ func getColumns(ctx context.Context, db *sql.DB, database, table string) ([]Colu
 		var nullable string
 		var defaultVal, charSet, collation, extra sql.NullString
 
-		if err := rows.Scan(&c.Name, &c.Type, &nullable, &defaultVal, &c.Position, &charSet, &collation, &extra); err != nil {
+		if err := rows.Scan(&c.Name, &c.Type, &nullable, &defaultVal, &c.Position, &charSet, &collation, &extra, &c.GenerationExpr); err != nil {
 			return nil, err
 		}
 
@@ -388,12 +582,47 @@ func getColumns(ctx context.Context, db *sql.DB, database, table string) ([]Colu
 		if extra.Valid && strings.Contains(strings.ToUpper(extra.String), "STORED GENERATED") {
 			c.IsStoredGenerated = true
 		}
+		if extra.Valid && strings.Contains(strings.ToLower(extra.String), "auto_increment") {
+			c.AutoIncrement = true
+		}
 
 		result = append(result, c)
 	}
 	return result, nil
 }
 
+// spatialTypes are the COLUMN_TYPE values of spatial columns.
+var spatialTypes = map[string]bool{
+	"geometry": true, "point": true, "linestring": true, "polygon": true, "multipoint": true,
+	"multilinestring": true, "multipolygon": true, "geometrycollection": true, "geomcollection": true,
+}
+
+// getColumnSRIDs sets the SRID of the columns that have one, from COLUMNS.SRS_ID.
+func getColumnSRIDs(ctx context.Context, db *sql.DB, database, table string, columns []ColumnInfo) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT COLUMN_NAME, SRS_ID
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND SRS_ID IS NOT NULL
+	`, database, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, srid string
+		if err := rows.Scan(&name, &srid); err != nil {
+			return err
+		}
+		for i := range columns {
+			if strings.EqualFold(columns[i].Name, name) {
+				columns[i].SRID = srid
+			}
+		}
+	}
+	return rows.Err()
+}
+
 func humanBytes(b int64) string {
 	const (
 		KB = 1024
@@ -3,7 +3,9 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 )
@@ -124,19 +126,19 @@ func TestGetTableMetadata(t *testing.T) {
 		// Mock COLUMNS query
 		colRows := sqlmock.NewRows([]string{
 			"COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT",
-			"ORDINAL_POSITION", "CHARACTER_SET_NAME", "COLLATION_NAME", "EXTRA",
+			"ORDINAL_POSITION", "CHARACTER_SET_NAME", "COLLATION_NAME", "EXTRA", "GENERATION_EXPRESSION",
 		}).
-			AddRow("id", "int", "NO", nil, 1, nil, nil, "auto_increment").
-			AddRow("name", "varchar(100)", "YES", "''", 2, "utf8mb4", "utf8mb4_unicode_ci", "")
+			AddRow("id", "int", "NO", nil, 1, nil, nil, "auto_increment", "").
+			AddRow("name", "varchar(100)", "YES", "''", 2, "utf8mb4", "utf8mb4_unicode_ci", "", "")
 
 		mock.ExpectQuery("SELECT.*FROM information_schema.COLUMNS").
 			WithArgs("testdb", "users").
 			WillReturnRows(colRows)
 
 		// Mock STATISTICS query (indexes)
-		idxRows := sqlmock.NewRows([]string{"INDEX_NAME", "COLUMN_NAME", "NON_UNIQUE", "INDEX_TYPE"}).
-			AddRow("PRIMARY", "id", false, "BTREE").
-			AddRow("idx_name", "name", true, "BTREE")
+		idxRows := sqlmock.NewRows([]string{"INDEX_NAME", "COLUMN_NAME", "NON_UNIQUE", "INDEX_TYPE", "SUB_PART", "INVISIBLE"}).
+			AddRow("PRIMARY", "id", false, "BTREE", 0, false).
+			AddRow("idx_name", "name", true, "BTREE", 0, false)
 
 		mock.ExpectQuery("SELECT.*FROM information_schema.STATISTICS").
 			WithArgs("testdb", "users").
@@ -156,7 +158,7 @@ func TestGetTableMetadata(t *testing.T) {
 		// Mock inbound FK query
 		inboundFKRows := sqlmock.NewRows([]string{
 			"CONSTRAINT_NAME", "TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME",
-			"REFERENCED_COLUMN_NAME", "DELETE_RULE", "UPDATE_RULE",
+			"REFERENCED_COLUMN_NAME", "DELETE_RULE", "UPDATE_RULE", "TABLE_ROWS", "AVG_ROW_LENGTH",
 		}) // No inbound foreign keys
 
 		mock.ExpectQuery("SELECT.*FROM information_schema.KEY_COLUMN_USAGE k.*REFERENCED_TABLE_SCHEMA").
@@ -172,6 +174,20 @@ func TestGetTableMetadata(t *testing.T) {
 			WithArgs("testdb", "users").
 			WillReturnRows(triggerRows)
 
+		// Mock PARTITIONS query
+		partitionRows := sqlmock.NewRows([]string{
+			"PARTITION_NAME", "PARTITION_METHOD", "PARTITION_EXPRESSION", "PARTITION_DESCRIPTION", "TABLE_ROWS",
+		}) // Not partitioned
+
+		mock.ExpectQuery("SELECT.*FROM information_schema.PARTITIONS").
+			WithArgs("testdb", "users").
+			WillReturnRows(partitionRows)
+
+		// Mock INNODB_TABLES query
+		mock.ExpectQuery("SELECT.*FROM information_schema.INNODB_TABLES").
+			WithArgs("testdb/users", "testdb/users#p#%").
+			WillReturnRows(sqlmock.NewRows([]string{"TOTAL_ROW_VERSIONS"}).AddRow(3))
+
 		meta, err := GetTableMetadata(db, "testdb", "users")
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -198,6 +214,9 @@ func TestGetTableMetadata(t *testing.T) {
 		if meta.AvgRowLength != 102 {
 			t.Errorf("AvgRowLength = %d, want %d", meta.AvgRowLength, 102)
 		}
+		if meta.TotalRowVersions != 3 {
+			t.Errorf("TotalRowVersions = %d, want %d", meta.TotalRowVersions, 3)
+		}
 		if meta.AutoIncrement != 1001 {
 			t.Errorf("AutoIncrement = %d, want %d", meta.AutoIncrement, 1001)
 		}
@@ -250,11 +269,12 @@ func TestGetColumns(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{
 		"COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT",
-		"ORDINAL_POSITION", "CHARACTER_SET_NAME", "COLLATION_NAME", "EXTRA",
+		"ORDINAL_POSITION", "CHARACTER_SET_NAME", "COLLATION_NAME", "EXTRA", "GENERATION_EXPRESSION",
 	}).
-		AddRow("id", "int", "NO", nil, 1, nil, nil, "").
-		AddRow("name", "varchar(100)", "YES", "John", 2, "utf8mb4", "utf8mb4_unicode_ci", "").
-		AddRow("created_at", "timestamp", "NO", "CURRENT_TIMESTAMP", 3, nil, nil, "")
+		AddRow("id", "int", "NO", nil, 1, nil, nil, "", "").
+		AddRow("name", "varchar(100)", "YES", "John", 2, "utf8mb4", "utf8mb4_unicode_ci", "", "").
+		AddRow("created_at", "timestamp", "NO", "CURRENT_TIMESTAMP", 3, nil, nil, "", "").
+		AddRow("name_upper", "varchar(100)", "YES", nil, 4, "utf8mb4", "utf8mb4_unicode_ci", "VIRTUAL GENERATED", "upper(`name`)")
 
 	mock.ExpectQuery("SELECT.*FROM information_schema.COLUMNS").
 		WithArgs("testdb", "users").
@@ -265,8 +285,8 @@ func TestGetColumns(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if len(cols) != 3 {
-		t.Fatalf("expected 3 columns, got %d", len(cols))
+	if len(cols) != 4 {
+		t.Fatalf("expected 4 columns, got %d", len(cols))
 	}
 
 	// Check first column (id)
@@ -293,12 +313,46 @@ func TestGetColumns(t *testing.T) {
 	if cols[1].CharacterSet == nil || *cols[1].CharacterSet != "utf8mb4" {
 		t.Errorf("cols[1].CharacterSet = %v, want 'utf8mb4'", cols[1].CharacterSet)
 	}
+	if cols[1].GenerationExpr != "" {
+		t.Errorf("cols[1].GenerationExpr = %q, want empty", cols[1].GenerationExpr)
+	}
+
+	// Check generated column (name_upper)
+	if cols[3].GenerationExpr != "upper(`name`)" {
+		t.Errorf("cols[3].GenerationExpr = %q, want %q", cols[3].GenerationExpr, "upper(`name`)")
+	}
+	if cols[3].IsStoredGenerated {
+		t.Error("cols[3].IsStoredGenerated = true, want false for VIRTUAL")
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("unfulfilled expectations: %v", err)
 	}
 }
 
+func TestGetColumnSRIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT COLUMN_NAME, SRS_ID").
+		WithArgs("testdb", "places").
+		WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME", "SRS_ID"}).AddRow("G", "4326"))
+
+	cols := []ColumnInfo{{Name: "id", Type: "int"}, {Name: "g", Type: "geometry"}}
+	if err := getColumnSRIDs(context.Background(), db, "testdb", "places", cols); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cols[0].SRID != "" || cols[1].SRID != "4326" {
+		t.Errorf("SRIDs = %q, %q, want none and 4326", cols[0].SRID, cols[1].SRID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
 func TestGetIndexes(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -306,11 +360,11 @@ func TestGetIndexes(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"INDEX_NAME", "COLUMN_NAME", "NON_UNIQUE", "INDEX_TYPE"}).
-		AddRow("PRIMARY", "id", false, "BTREE").
-		AddRow("idx_email", "email", true, "BTREE").
-		AddRow("idx_name_created", "name", true, "BTREE").
-		AddRow("idx_name_created", "created_at", true, "BTREE")
+	rows := sqlmock.NewRows([]string{"INDEX_NAME", "COLUMN_NAME", "NON_UNIQUE", "INDEX_TYPE", "SUB_PART", "INVISIBLE"}).
+		AddRow("PRIMARY", "id", false, "BTREE", 0, false).
+		AddRow("idx_email", "email", true, "BTREE", 0, true).
+		AddRow("idx_name_created", "name", true, "BTREE", 20, false).
+		AddRow("idx_name_created", "created_at", true, "BTREE", 0, false)
 
 	mock.ExpectQuery("SELECT.*FROM information_schema.STATISTICS").
 		WithArgs("testdb", "users").
@@ -336,6 +390,11 @@ func TestGetIndexes(t *testing.T) {
 		t.Errorf("indexes[0].Columns = %v, want ['id']", indexes[0].Columns)
 	}
 
+	// Check invisible index
+	if !indexes[1].Invisible || indexes[0].Invisible {
+		t.Errorf("Invisible = %v/%v, want idx_email invisible and PRIMARY visible", indexes[1].Invisible, indexes[0].Invisible)
+	}
+
 	// Check composite index
 	if indexes[2].Name != "idx_name_created" {
 		t.Errorf("indexes[2].Name = %q, want %q", indexes[2].Name, "idx_name_created")
@@ -346,6 +405,9 @@ func TestGetIndexes(t *testing.T) {
 	if indexes[2].Columns[0] != "name" || indexes[2].Columns[1] != "created_at" {
 		t.Errorf("indexes[2].Columns = %v, want ['name', 'created_at']", indexes[2].Columns)
 	}
+	if len(indexes[2].SubParts) != 2 || indexes[2].SubParts[0] != 20 || indexes[2].SubParts[1] != 0 {
+		t.Errorf("indexes[2].SubParts = %v, want [20 0]", indexes[2].SubParts)
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("unfulfilled expectations: %v", err)
@@ -426,10 +488,10 @@ func TestGetInboundForeignKeys(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{
 		"CONSTRAINT_NAME", "TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME",
-		"REFERENCED_COLUMN_NAME", "DELETE_RULE", "UPDATE_RULE",
+		"REFERENCED_COLUMN_NAME", "DELETE_RULE", "UPDATE_RULE", "TABLE_ROWS", "AVG_ROW_LENGTH",
 	}).
-		AddRow("fk_order", "testdb", "order_items", "order_id", "id", "CASCADE", "NO ACTION").
-		AddRow("fk_product", "testdb", "order_items", "product_id", "id", "RESTRICT", "NO ACTION")
+		AddRow("fk_order", "testdb", "order_items", "order_id", "id", "CASCADE", "NO ACTION", 3_000_000, 120).
+		AddRow("fk_product", "testdb", "order_items", "product_id", "id", "RESTRICT", "NO ACTION", 3_000_000, 120)
 
 	mock.ExpectQuery("SELECT.*FROM information_schema.KEY_COLUMN_USAGE k.*REFERENCED_TABLE_SCHEMA").
 		WithArgs("testdb", "orders").
@@ -453,6 +515,9 @@ func TestGetInboundForeignKeys(t *testing.T) {
 	if fks[0].DeleteRule != "CASCADE" {
 		t.Errorf("fks[0].DeleteRule = %q, want %q", fks[0].DeleteRule, "CASCADE")
 	}
+	if fks[0].ChildRows != 3_000_000 || fks[0].ChildAvgRowLen != 120 {
+		t.Errorf("fks[0] child size = %d rows × %d bytes, want 3000000 × 120", fks[0].ChildRows, fks[0].ChildAvgRowLen)
+	}
 	if len(fks[0].Columns) != 1 || fks[0].Columns[0] != "order_id" {
 		t.Errorf("fks[0].Columns = %v, want ['order_id']", fks[0].Columns)
 	}
@@ -516,3 +581,100 @@ func TestGetTriggers(t *testing.T) {
 		t.Errorf("unfulfilled expectations: %v", err)
 	}
 }
+
+func TestGetCheckConstraints(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT cc.CONSTRAINT_NAME, cc.CHECK_CLAUSE.*FROM information_schema.TABLE_CONSTRAINTS").
+		WithArgs("shop", "orders").
+		WillReturnRows(sqlmock.NewRows([]string{"CONSTRAINT_NAME", "CHECK_CLAUSE"}).
+			AddRow("chk_price", "(`price` > 0)"))
+
+	checks, err := getCheckConstraints(context.Background(), db, "shop", "orders")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(checks) != 1 || checks[0].Name != "chk_price" || checks[0].Expression != "(`price` > 0)" {
+		t.Errorf("checks = %+v", checks)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestGetPartitioning(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{
+		"PARTITION_NAME", "PARTITION_METHOD", "PARTITION_EXPRESSION", "PARTITION_DESCRIPTION", "TABLE_ROWS",
+	}).
+		AddRow("p2023", "RANGE COLUMNS", "`created_at`", "'2024-01-01'", 100).
+		AddRow("p2023", "RANGE COLUMNS", "`created_at`", "'2024-01-01'", 50). // subpartition
+		AddRow("pmax", "RANGE COLUMNS", "`created_at`", "MAXVALUE", 10)
+	mock.ExpectQuery("SELECT.*FROM information_schema.PARTITIONS").
+		WithArgs("testdb", "events").
+		WillReturnRows(rows)
+
+	info, err := getPartitioning(context.Background(), db, "testdb", "events")
+	if err != nil {
+		t.Fatalf("getPartitioning() error = %v", err)
+	}
+	if info == nil {
+		t.Fatal("getPartitioning() = nil, want partitioning")
+	}
+	if info.Method != "RANGE COLUMNS" || info.Expression != "`created_at`" {
+		t.Errorf("Method, Expression = %q, %q", info.Method, info.Expression)
+	}
+	want := []Partition{
+		{Name: "p2023", Description: "'2024-01-01'", RowCount: 150},
+		{Name: "pmax", Description: "MAXVALUE", RowCount: 10},
+	}
+	if !reflect.DeepEqual(info.Partitions, want) {
+		t.Errorf("Partitions = %+v, want %+v", info.Partitions, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestSampleWriteRate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT COUNT_WRITE.*FROM performance_schema.table_io_waits_summary_by_table").
+		WithArgs("testdb", "orders").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT_WRITE"}).AddRow(1000))
+	mock.ExpectQuery("SELECT COUNT_WRITE.*FROM performance_schema.table_io_waits_summary_by_table").
+		WithArgs("testdb", "orders").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT_WRITE"}).AddRow(1050))
+
+	rate, err := SampleWriteRate(context.Background(), db, "testdb", "orders", 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("SampleWriteRate() error = %v", err)
+	}
+	if rate < 499 || rate > 501 {
+		t.Errorf("SampleWriteRate() = %.1f, want 500", rate)
+	}
+
+	// No row: performance_schema doesn't instrument the table, so the rate is unknown.
+	mock.ExpectQuery("SELECT COUNT_WRITE").
+		WithArgs("testdb", "orders").
+		WillReturnError(sql.ErrNoRows)
+	if _, err := SampleWriteRate(context.Background(), db, "testdb", "orders", time.Millisecond); err == nil {
+		t.Error("SampleWriteRate() without a counter row: want an error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
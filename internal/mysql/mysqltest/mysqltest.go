@@ -0,0 +1,38 @@
+// Package mysqltest provides fake MySQL servers for tests.
+package mysqltest
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// FakeServer is a connection to a standalone MySQL 8.0.36 whose information_schema.TABLES
+// has one table of rows rows. Every other query returns no rows. The connection is closed
+// when the test ends.
+func FakeServer(t testing.TB, rows int64) *sql.DB {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(func(expected, actual string) error {
+		if expected != "" && !regexp.MustCompile(expected).MatchString(actual) {
+			return errors.New("not this query")
+		}
+		return nil
+	})))
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	mock.MatchExpectationsInOrder(false)
+	for range 10 {
+		mock.ExpectQuery(`SELECT VERSION\(\)`).WillReturnRows(sqlmock.NewRows([]string{"v"}).AddRow("8.0.36"))
+		mock.ExpectQuery(`IFNULL\(ROW_FORMAT`).WillReturnRows(sqlmock.NewRows([]string{"e", "r", "d", "i", "a", "ai", "f"}).
+			AddRow("InnoDB", rows, rows*100, 0, 100, 0, "Dynamic"))
+	}
+	for range 500 {
+		mock.ExpectQuery("").WillReturnRows(sqlmock.NewRows(nil))
+	}
+	return db
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -153,6 +155,39 @@ func ParseVersion(raw string) (ServerVersion, error) {
 	return v, nil
 }
 
+// assumableFlavors are the flavors ParseAssumedVersion accepts.
+var assumableFlavors = []string{"mysql", "percona", "percona-xtradb-cluster", "aurora-mysql"}
+
+// ParseAssumedVersion parses a version given by the user instead of read from a server:
+// "8.0.36", optionally followed by a flavor ("8.0.36-percona", "8.0.28-aurora-mysql"),
+// or an Aurora version string as VERSION() reports it ("8.0.mysql_aurora.3.05.2").
+// The flavor defaults to mysql.
+func ParseAssumedVersion(s string) (ServerVersion, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "mysql_aurora") {
+		return ParseVersion(s)
+	}
+	number, flavor, _ := strings.Cut(s, "-")
+	if !regexp.MustCompile(`^\d+\.\d+\.\d+$`).MatchString(number) {
+		return ServerVersion{}, fmt.Errorf("invalid version %q: want MAJOR.MINOR.PATCH, optionally followed by -FLAVOR (e.g. 8.0.36-percona)", s)
+	}
+	v, err := ParseVersion(number)
+	if err != nil {
+		return ServerVersion{}, err
+	}
+	if flavor != "" {
+		flavor = strings.ToLower(flavor)
+		if !slices.Contains(assumableFlavors, flavor) {
+			return ServerVersion{}, fmt.Errorf("unknown flavor %q in version %q: use one of %s", flavor, s, strings.Join(assumableFlavors, ", "))
+		}
+		v.Flavor = flavor
+		if v.IsAurora() {
+			v.IsLTS = false
+		}
+	}
+	return v, nil
+}
+
 // GetVariable reads a single MySQL variable.
 // Returns the value, or empty string if variable doesn't exist.
 // Note: Some variables (like wsrep_on) require SHOW VARIABLES without GLOBAL.
@@ -220,6 +255,106 @@ func GetVariableInt(db *sql.DB, name string) (int64, error) {
 	return strconv.ParseInt(val, 10, 64)
 }
 
+// CheckSettings holds foreign_key_checks and unique_checks at session and global scope.
+// The session values apply to statements run on the connection that read them; new
+// connections, like the ones gh-ost and pt-osc open, start with the global values.
+type CheckSettings struct {
+	ForeignKeyChecks       bool
+	UniqueChecks           bool
+	GlobalForeignKeyChecks bool
+	GlobalUniqueChecks     bool
+}
+
+// GetCheckSettings reads foreign_key_checks and unique_checks at session and global scope.
+func GetCheckSettings(db *sql.DB) (CheckSettings, error) {
+	var s CheckSettings
+	err := db.QueryRowContext(context.Background(),
+		"SELECT @@session.foreign_key_checks, @@session.unique_checks, @@global.foreign_key_checks, @@global.unique_checks",
+	).Scan(&s.ForeignKeyChecks, &s.UniqueChecks, &s.GlobalForeignKeyChecks, &s.GlobalUniqueChecks)
+	if err != nil {
+		return CheckSettings{}, fmt.Errorf("querying foreign_key_checks and unique_checks: %w", err)
+	}
+	return s, nil
+}
+
+// SessionTLS reports whether the connection is encrypted: its session Ssl_cipher is set.
+// All connections of db share one DSN, so any of them answers for the others.
+func SessionTLS(db *sql.DB) (bool, error) {
+	var name, cipher string
+	err := db.QueryRowContext(context.Background(), `SHOW SESSION STATUS LIKE 'Ssl\_cipher'`).Scan(&name, &cipher)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("querying Ssl_cipher: %w", err)
+	}
+	return cipher != "", nil
+}
+
+// ServerConfig holds the server variables that bound how an online ALTER can run, and
+// where the binary log goes. Zero values mean the variable couldn't be read.
+type ServerConfig struct {
+	OnlineAlterLogMaxSize int64  // innodb_online_alter_log_max_size in bytes
+	InnoDBTmpdir          string // innodb_tmpdir; empty means tmpdir is used
+	Tmpdir                string // tmpdir
+	DataDir               string // datadir
+	BinlogDisabled        bool   // log_bin=OFF; false when enabled or unknown
+	BinlogDir             string // directory of log_bin_basename
+	BinlogSpaceLimit      int64  // binlog_space_limit in bytes (Percona Server); 0 means none
+
+	RedoLogCapacity    int64 // innodb_redo_log_capacity (8.0.30+), or innodb_log_file_size × innodb_log_files_in_group before
+	UndoLogTruncateOff bool  // innodb_undo_log_truncate=OFF; false when enabled or unknown
+
+	// SQLMode is @@GLOBAL.sql_mode, the mode new sessions start with; nil when unknown
+	// (an empty mode is valid, and not strict).
+	SQLMode *string
+}
+
+// TempDir returns the directory online ALTERs write their temporary sort files to and the
+// variable it comes from, or ("", "") if neither variable could be read.
+func (c ServerConfig) TempDir() (dir, variable string) {
+	if c.InnoDBTmpdir != "" {
+		return c.InnoDBTmpdir, "innodb_tmpdir"
+	}
+	if c.Tmpdir != "" {
+		return c.Tmpdir, "tmpdir"
+	}
+	return "", ""
+}
+
+// GetServerConfig reads the ServerConfig variables. Variables that can't be read are left
+// at their zero value.
+func GetServerConfig(db *sql.DB) ServerConfig {
+	var cfg ServerConfig
+	cfg.OnlineAlterLogMaxSize, _ = GetVariableInt(db, "innodb_online_alter_log_max_size")
+	cfg.InnoDBTmpdir, _ = GetVariable(db, "innodb_tmpdir")
+	cfg.Tmpdir, _ = GetVariable(db, "tmpdir")
+	cfg.DataDir, _ = GetVariable(db, "datadir")
+	if logBin, err := GetVariable(db, "log_bin"); err == nil {
+		cfg.BinlogDisabled = strings.EqualFold(logBin, "OFF") || logBin == "0"
+	}
+	if basename, _ := GetVariable(db, "log_bin_basename"); basename != "" {
+		cfg.BinlogDir = filepath.Dir(basename)
+	}
+	cfg.BinlogSpaceLimit, _ = GetVariableInt(db, "binlog_space_limit")
+	if cfg.RedoLogCapacity, _ = GetVariableInt(db, "innodb_redo_log_capacity"); cfg.RedoLogCapacity == 0 {
+		// MariaDB 10.5+ has a single redo log file and no innodb_log_files_in_group.
+		if size, _ := GetVariableInt(db, "innodb_log_file_size"); size > 0 {
+			files, _ := GetVariableInt(db, "innodb_log_files_in_group")
+			cfg.RedoLogCapacity = size * max(files, 1)
+		}
+	}
+	if truncate, err := GetVariable(db, "innodb_undo_log_truncate"); err == nil {
+		cfg.UndoLogTruncateOff = strings.EqualFold(truncate, "OFF") || truncate == "0"
+	}
+	// Not GetVariable: it takes an empty global value for unset.
+	var sqlMode string
+	if err := db.QueryRowContext(context.Background(), "SELECT @@GLOBAL.sql_mode").Scan(&sqlMode); err == nil {
+		cfg.SQLMode = &sqlMode
+	}
+	return cfg
+}
+
 // validateSafeForExplain checks if SQL is safe to use with EXPLAIN.
 // This prevents SQL injection by ensuring only SELECT/UPDATE/DELETE statements are explained.
 func validateSafeForExplain(sqlText string) error {
@@ -249,6 +384,9 @@ func validateSafeForExplain(sqlText string) error {
 }
 
 // EstimateRowsAffected runs EXPLAIN on a DML statement to get row estimate.
+// The value is the largest "rows" column across the plan — an optimizer estimate
+// derived from index statistics, so it is approximate and can be off by a wide margin
+// on tables with stale statistics.
 // Note: This function validates the SQL is a safe DML statement before executing EXPLAIN.
 func EstimateRowsAffected(db *sql.DB, sqlText string) (int64, error) {
 	// Security: Validate that this is a safe SQL statement before using EXPLAIN
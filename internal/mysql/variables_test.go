@@ -728,6 +728,58 @@ func TestGetVariableInt(t *testing.T) {
 	}
 }
 
+func TestGetCheckSettings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{
+		"@@session.foreign_key_checks", "@@session.unique_checks", "@@global.foreign_key_checks", "@@global.unique_checks",
+	}).AddRow(0, 1, 1, 1)
+	mock.ExpectQuery("SELECT @@session.foreign_key_checks, @@session.unique_checks, @@global.foreign_key_checks, @@global.unique_checks").
+		WillReturnRows(rows)
+
+	got, err := GetCheckSettings(db)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := CheckSettings{ForeignKeyChecks: false, UniqueChecks: true, GlobalForeignKeyChecks: true, GlobalUniqueChecks: true}
+	if got != want {
+		t.Errorf("GetCheckSettings() = %+v, want %+v", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestSessionTLS(t *testing.T) {
+	for _, tt := range []struct {
+		cipher string
+		want   bool
+	}{
+		{"TLS_AES_256_GCM_SHA384", true},
+		{"", false},
+	} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to create mock: %v", err)
+		}
+		mock.ExpectQuery(`SHOW SESSION STATUS LIKE 'Ssl\\_cipher'`).
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Ssl_cipher", tt.cipher))
+
+		got, err := SessionTLS(db)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("SessionTLS() with Ssl_cipher %q = %v, want %v", tt.cipher, got, tt.want)
+		}
+		db.Close()
+	}
+}
+
 func TestEstimateRowsAffected(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -801,3 +853,57 @@ func TestEstimateRowsAffected(t *testing.T) {
 		t.Errorf("unfulfilled expectations: %v", err)
 	}
 }
+
+func TestParseAssumedVersion(t *testing.T) {
+	tests := []struct {
+		in         string
+		wantMinor  int
+		wantPatch  int
+		wantFlavor string
+		wantErr    bool
+	}{
+		{in: "8.0.36", wantMinor: 0, wantPatch: 36, wantFlavor: "mysql"},
+		{in: "8.4.2", wantMinor: 4, wantPatch: 2, wantFlavor: "mysql"},
+		{in: "8.0.36-Percona", wantMinor: 0, wantPatch: 36, wantFlavor: "percona"},
+		{in: "8.0.35-percona-xtradb-cluster", wantMinor: 0, wantPatch: 35, wantFlavor: "percona-xtradb-cluster"},
+		{in: "8.0.28-aurora-mysql", wantMinor: 0, wantPatch: 28, wantFlavor: "aurora-mysql"},
+		{in: "8.0.mysql_aurora.3.04.0", wantMinor: 0, wantPatch: 0, wantFlavor: "aurora-mysql"},
+		{in: "8.0.36-mariadb", wantErr: true},
+		{in: "8.0", wantErr: true},
+		{in: "eight", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v, err := ParseAssumedVersion(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if v.Major != 8 || v.Minor != tt.wantMinor || v.Patch != tt.wantPatch {
+				t.Errorf("version = %d.%d.%d, want 8.%d.%d", v.Major, v.Minor, v.Patch, tt.wantMinor, tt.wantPatch)
+			}
+			if v.Flavor != tt.wantFlavor {
+				t.Errorf("Flavor = %q, want %q", v.Flavor, tt.wantFlavor)
+			}
+		})
+	}
+}
+
+func TestFreeDiskBytes(t *testing.T) {
+	free, err := FreeDiskBytes(t.TempDir())
+	if err != nil {
+		t.Skipf("free disk space not available here: %v", err)
+	}
+	if free <= 0 {
+		t.Errorf("FreeDiskBytes = %d, want > 0", free)
+	}
+	if _, err := FreeDiskBytes("/nonexistent/dbsafe"); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
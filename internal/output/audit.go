@@ -0,0 +1,146 @@
+package output
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nethalo/dbsafe/internal/analyzer"
+)
+
+// AuditEntry is one line of the audit log: what was analyzed, by whom, and what dbsafe
+// recommended. PrevHash is the SHA-256 of the previous line as written (without its newline),
+// "" for the first line, so a line edited or removed later breaks the chain.
+type AuditEntry struct {
+	Timestamp      string `json:"timestamp"` // RFC 3339, UTC
+	User           string `json:"user,omitempty"`
+	Host           string `json:"host,omitempty"`
+	Statement      string `json:"statement"`
+	Database       string `json:"database,omitempty"`
+	Table          string `json:"table,omitempty"`
+	Operation      string `json:"operation,omitempty"`
+	Algorithm      string `json:"algorithm,omitempty"`
+	Lock           string `json:"lock,omitempty"`
+	RebuildsTable  bool   `json:"rebuilds_table,omitempty"`
+	Risk           string `json:"risk"`
+	Method         string `json:"method"`
+	Recommendation string `json:"recommendation,omitempty"`
+	Command        string `json:"command,omitempty"`
+	PrevHash       string `json:"prev_hash"`
+}
+
+// reLiteralPassword matches a password given literally on a command line, not through an
+// environment variable ("$MYSQL_PWD").
+var reLiteralPassword = regexp.MustCompile(`(--password[= ]|\bpassword=)("[^"$][^"]*"|'[^']*'|[^\s"'$,][^\s,]*)`)
+
+// NewAuditEntry builds the audit log entry for result. conn (nil offline) names the user and
+// host. Credential fields are replaced with "xxxxx": password, when known, as the password
+// of a DSN ("user:password@"), and any literal --password= value. The password is not
+// replaced elsewhere, so a short or common one ("a", "root") doesn't mangle the statement.
+func NewAuditEntry(result *analyzer.Result, conn *analyzer.ConnectionInfo, password string) AuditEntry {
+	redact := func(s string) string {
+		if password != "" {
+			s = strings.ReplaceAll(s, ":"+password+"@", ":xxxxx@")
+		}
+		return reLiteralPassword.ReplaceAllString(s, "${1}xxxxx")
+	}
+	e := AuditEntry{
+		Timestamp:      result.AnalyzedAt.UTC().Format(time.RFC3339),
+		Statement:      redact(strings.TrimSpace(result.Statement)),
+		Database:       result.Database,
+		Table:          result.Table,
+		Risk:           string(result.Risk),
+		Method:         string(result.Method),
+		Recommendation: redact(result.Recommendation),
+		Command:        redact(result.Command()),
+	}
+	if result.DDLOp != "" {
+		e.Operation = string(result.DDLOp)
+		e.Algorithm = string(result.Classification.Algorithm)
+		e.Lock = string(result.Classification.Lock)
+		e.RebuildsTable = result.Classification.RebuildsTable
+	} else if result.DMLOp != "" {
+		e.Operation = string(result.DMLOp)
+	}
+	if conn != nil {
+		e.User = conn.User
+		e.Host = conn.Host
+		if e.Host == "" {
+			e.Host = conn.Socket
+		}
+	}
+	return e
+}
+
+// AppendAudit appends entries to the audit log at path, one JSON line each, chaining each
+// line's PrevHash to the line before it. The file is created 0600 and only ever opened for
+// appending: earlier lines are read to hash the last one, never rewritten. An exclusive
+// lock on the file is held from reading the last line to writing, so concurrent runs
+// don't chain to the same line.
+func AppendAudit(path string, entries ...AuditEntry) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	unlock, err := lockFile(f)
+	if err != nil {
+		return fmt.Errorf("locking %s: %w", path, err)
+	}
+	defer unlock()
+
+	last, err := lastLine(f)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	var buf bytes.Buffer
+	for _, e := range entries {
+		e.PrevHash = ""
+		if len(last) > 0 {
+			sum := sha256.Sum256(last)
+			e.PrevHash = hex.EncodeToString(sum[:])
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		last = line
+	}
+	// One write for all the lines, so a run that doesn't take the lock can't interleave with them.
+	_, err = f.Write(buf.Bytes())
+	return err
+}
+
+// lastLine returns the last non-empty line of f, without its newline, reading backwards
+// from the end so a long log isn't read whole.
+func lastLine(f *os.File) ([]byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	const block = 4096
+	var tail []byte
+	for end := info.Size(); end > 0; {
+		start := max(end-block, 0)
+		chunk := make([]byte, end-start)
+		if _, err := f.ReadAt(chunk, start); err != nil && err != io.EOF {
+			return nil, err
+		}
+		tail = append(chunk, tail...)
+		trimmed := bytes.TrimRight(tail, "\n")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			return trimmed[i+1:], nil
+		}
+		end = start
+	}
+	return bytes.TrimRight(tail, "\n"), nil
+}
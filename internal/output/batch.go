@@ -0,0 +1,118 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nethalo/dbsafe/internal/analyzer"
+)
+
+// batchSummary is a statement's one-line summary in a batch: algorithm and lock for DDL
+// that has them, the method otherwise.
+func batchSummary(result *analyzer.Result, algorithm string) string {
+	if c := result.Classification; c.Algorithm != "" {
+		return fmt.Sprintf("%s, LOCK=%s", algorithm, c.Lock)
+	}
+	return string(result.Method)
+}
+
+func (r *TextRenderer) RenderBatch(batch *analyzer.BatchResult) {
+	width := 60
+	fmt.Fprintln(r.w)
+
+	header := TitleStyle.Render("dbsafe — Batch")
+	lines := []string{
+		r.labelValue("Statements:", fmt.Sprintf("%d", len(batch.Results))),
+		r.labelValue("Combined risk:", riskText(batch.Risk)),
+		"",
+	}
+	for i, result := range batch.Results {
+		lines = append(lines,
+			fmt.Sprintf("%d. %s", i+1, CodeStyle.Render(strings.TrimSpace(result.Statement))),
+			fmt.Sprintf("   %s — %s", batchSummary(result, r.colorAlgorithm(result.Classification.Algorithm)), riskText(result.Risk)),
+		)
+	}
+	fmt.Fprintln(r.w, BoxStyle.Width(width).Render(header+"\n"+strings.Join(lines, "\n")))
+
+	r.renderWarnings(batch.Warnings, width)
+
+	for i, result := range batch.Results {
+		fmt.Fprintln(r.w)
+		fmt.Fprintln(r.w, TitleStyle.Render(fmt.Sprintf("Statement %d of %d", i+1, len(batch.Results))))
+		r.RenderPlan(result)
+	}
+}
+
+func (r *PlainRenderer) RenderBatch(batch *analyzer.BatchResult) {
+	fmt.Fprintf(r.w, "=== dbsafe — Batch ===\n\n")
+	fmt.Fprintf(r.w, "Statements:    %d\n", len(batch.Results))
+	fmt.Fprintf(r.w, "Combined risk: %s\n\n", batch.Risk)
+	for i, result := range batch.Results {
+		fmt.Fprintf(r.w, "%d. %s\n   %s — %s\n", i+1, strings.TrimSpace(result.Statement),
+			batchSummary(result, string(result.Classification.Algorithm)), result.Risk)
+	}
+	fmt.Fprintln(r.w)
+
+	for _, w := range batch.Warnings {
+		fmt.Fprintf(r.w, "WARNING: %s\n", w.Message)
+	}
+	if len(batch.Warnings) > 0 {
+		fmt.Fprintln(r.w)
+	}
+
+	for i, result := range batch.Results {
+		fmt.Fprintf(r.w, "### Statement %d of %d ###\n\n", i+1, len(batch.Results))
+		r.RenderPlan(result)
+		fmt.Fprintln(r.w)
+	}
+}
+
+func (r *MarkdownRenderer) RenderBatch(batch *analyzer.BatchResult) {
+	fmt.Fprintf(r.w, "# dbsafe — Batch\n\n")
+	fmt.Fprintf(r.w, "**Combined risk:** %s %s\n\n", riskEmoji[batch.Risk], batch.Risk)
+	fmt.Fprintf(r.w, "| # | Statement | Plan | Risk |\n|---|---|---|---|\n")
+	for i, result := range batch.Results {
+		fmt.Fprintf(r.w, "| %d | `%s` | %s | %s |\n", i+1, strings.TrimSpace(result.Statement),
+			batchSummary(result, string(result.Classification.Algorithm)), result.Risk)
+	}
+	fmt.Fprintln(r.w)
+
+	for _, w := range batch.Warnings {
+		fmt.Fprintf(r.w, "> ⚠️ %s\n\n", w.Message)
+	}
+
+	for _, result := range batch.Results {
+		fmt.Fprintf(r.w, "---\n\n")
+		r.RenderPlan(result)
+		fmt.Fprintln(r.w)
+	}
+}
+
+type jsonBatchOutput struct {
+	Risk           string          `json:"risk"`
+	Warnings       []string        `json:"warnings,omitempty"`
+	WarningDetails []jsonWarning   `json:"warning_details,omitempty"`
+	Statements     []jsonBatchStep `json:"statements"`
+}
+
+type jsonBatchStep struct {
+	SQL      string         `json:"sql"`
+	Analysis jsonPlanOutput `json:"analysis"`
+}
+
+func (r *JSONRenderer) RenderBatch(batch *analyzer.BatchResult) {
+	out := jsonBatchOutput{
+		Risk:           string(batch.Risk),
+		Warnings:       batch.WarningMessages(),
+		WarningDetails: jsonWarnings(batch.Warnings),
+		Statements:     []jsonBatchStep{},
+	}
+	for _, result := range batch.Results {
+		out.Statements = append(out.Statements, jsonBatchStep{SQL: strings.TrimSpace(result.Statement), Analysis: buildJSONPlan(result)})
+	}
+
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(out)
+}
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nethalo/dbsafe/internal/analyzer"
+)
+
+const noDiffMessage = "No differences: the current and desired definitions are equivalent."
+
+func (r *TextRenderer) RenderDiff(plan *analyzer.DiffPlan) {
+	width := 60
+	fmt.Fprintln(r.w)
+
+	header := TitleStyle.Render("dbsafe — Schema Diff")
+	if len(plan.Steps) == 0 {
+		fmt.Fprintln(r.w, SafeBoxStyle.Width(width).Render(header+"\n"+noDiffMessage))
+		return
+	}
+
+	lines := []string{
+		r.labelValue("Table:", fmt.Sprintf("%s.%s", plan.Database, plan.Table)),
+		r.labelValue("Steps:", fmt.Sprintf("%d", len(plan.Steps))),
+		r.labelValue("Combined risk:", riskText(plan.Risk)),
+		"",
+	}
+	for i, step := range plan.Steps {
+		c := step.Result.Classification
+		lines = append(lines,
+			fmt.Sprintf("%d. %s", i+1, CodeStyle.Render(step.SQL)),
+			fmt.Sprintf("   %s, LOCK=%s — %s", r.colorAlgorithm(c.Algorithm), c.Lock, riskText(step.Result.Risk)),
+		)
+	}
+	fmt.Fprintln(r.w, BoxStyle.Width(width).Render(header+"\n"+strings.Join(lines, "\n")))
+
+	r.renderWarnings(plan.Warnings, width)
+
+	for i, step := range plan.Steps {
+		fmt.Fprintln(r.w)
+		fmt.Fprintln(r.w, TitleStyle.Render(fmt.Sprintf("Step %d of %d", i+1, len(plan.Steps))))
+		r.RenderPlan(step.Result)
+	}
+}
+
+func (r *PlainRenderer) RenderDiff(plan *analyzer.DiffPlan) {
+	fmt.Fprintf(r.w, "=== dbsafe — Schema Diff ===\n\n")
+	if len(plan.Steps) == 0 {
+		fmt.Fprintln(r.w, noDiffMessage)
+		return
+	}
+
+	fmt.Fprintf(r.w, "Table:         %s.%s\n", plan.Database, plan.Table)
+	fmt.Fprintf(r.w, "Steps:         %d\n", len(plan.Steps))
+	fmt.Fprintf(r.w, "Combined risk: %s\n\n", plan.Risk)
+	for i, step := range plan.Steps {
+		c := step.Result.Classification
+		fmt.Fprintf(r.w, "%d. %s\n   %s, LOCK=%s — %s\n", i+1, step.SQL, c.Algorithm, c.Lock, step.Result.Risk)
+	}
+	fmt.Fprintln(r.w)
+
+	for _, w := range plan.Warnings {
+		fmt.Fprintf(r.w, "WARNING: %s\n", w.Message)
+	}
+	if len(plan.Warnings) > 0 {
+		fmt.Fprintln(r.w)
+	}
+
+	for i, step := range plan.Steps {
+		fmt.Fprintf(r.w, "### Step %d of %d ###\n\n", i+1, len(plan.Steps))
+		r.RenderPlan(step.Result)
+		fmt.Fprintln(r.w)
+	}
+}
+
+func (r *MarkdownRenderer) RenderDiff(plan *analyzer.DiffPlan) {
+	fmt.Fprintf(r.w, "# dbsafe — Schema Diff\n\n")
+	if len(plan.Steps) == 0 {
+		fmt.Fprintf(r.w, "%s\n", noDiffMessage)
+		return
+	}
+
+	fmt.Fprintf(r.w, "**Table:** `%s.%s`  \n", plan.Database, plan.Table)
+	fmt.Fprintf(r.w, "**Combined risk:** %s %s\n\n", riskEmoji[plan.Risk], plan.Risk)
+	fmt.Fprintf(r.w, "| # | Statement | Algorithm | Lock | Risk |\n|---|---|---|---|---|\n")
+	for i, step := range plan.Steps {
+		c := step.Result.Classification
+		fmt.Fprintf(r.w, "| %d | `%s` | %s | %s | %s |\n", i+1, step.SQL, c.Algorithm, c.Lock, step.Result.Risk)
+	}
+	fmt.Fprintln(r.w)
+
+	for _, w := range plan.Warnings {
+		fmt.Fprintf(r.w, "> ⚠️ %s\n\n", w.Message)
+	}
+
+	for _, step := range plan.Steps {
+		fmt.Fprintf(r.w, "---\n\n")
+		r.RenderPlan(step.Result)
+		fmt.Fprintln(r.w)
+	}
+}
+
+type jsonDiffOutput struct {
+	Database       string         `json:"database,omitempty"`
+	Table          string         `json:"table,omitempty"`
+	Risk           string         `json:"risk"`
+	Warnings       []string       `json:"warnings,omitempty"`
+	WarningDetails []jsonWarning  `json:"warning_details,omitempty"`
+	Steps          []jsonDiffStep `json:"steps"`
+}
+
+type jsonDiffStep struct {
+	SQL      string         `json:"sql"`
+	Analysis jsonPlanOutput `json:"analysis"`
+}
+
+func (r *JSONRenderer) RenderDiff(plan *analyzer.DiffPlan) {
+	out := jsonDiffOutput{
+		Database:       plan.Database,
+		Table:          plan.Table,
+		Risk:           string(plan.Risk),
+		Warnings:       plan.WarningMessages(),
+		WarningDetails: jsonWarnings(plan.Warnings),
+		Steps:          []jsonDiffStep{},
+	}
+	for _, step := range plan.Steps {
+		out.Steps = append(out.Steps, jsonDiffStep{SQL: step.SQL, Analysis: buildJSONPlan(step.Result)})
+	}
+
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(out)
+}
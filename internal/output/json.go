@@ -2,6 +2,7 @@ package output
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 
 	"github.com/nethalo/dbsafe/internal/analyzer"
@@ -26,6 +27,7 @@ type jsonPlanOutput struct {
 	Topology                    jsonTopology      `json:"topology"`
 	Operation                   jsonOperation     `json:"operation"`
 	Risk                        string            `json:"risk"`
+	RiskScore                   int               `json:"risk_score"`
 	Method                      string            `json:"recommended_method"`
 	AlternativeMethod           string            `json:"alternative_method,omitempty"`
 	Recommendation              string            `json:"recommendation"`
@@ -33,22 +35,44 @@ type jsonPlanOutput struct {
 	AlternativeExecutionCommand string            `json:"alternative_execution_command,omitempty"`
 	MethodRationale             string            `json:"method_rationale,omitempty"`
 	Warnings                    []string          `json:"warnings,omitempty"`
+	WarningDetails              []jsonWarning     `json:"warning_details,omitempty"`
 	ClusterWarnings             []string          `json:"cluster_warnings,omitempty"`
+	ClusterWarningDetails       []jsonWarning     `json:"cluster_warning_details,omitempty"`
 	Rollback                    jsonRollback      `json:"rollback"`
 	Script                      *jsonScript       `json:"generated_script,omitempty"`
 	DiskEstimate                *jsonDiskEstimate `json:"disk_space_estimate,omitempty"`
+	BinlogBytes                 int64             `json:"estimated_binlog_bytes,omitempty"`
+	Schedule                    *jsonSchedule     `json:"schedule,omitempty"`
+	Trace                       []jsonTraceStep   `json:"trace,omitempty"`
 	IdempotentProcedure         string            `json:"idempotent_procedure,omitempty"`
+	ProjectedSchema             *jsonProjected    `json:"projected_schema,omitempty"`
 	OptimizedDDL                string            `json:"optimized_ddl,omitempty"`
 }
 
+// jsonWarning is a warning with its stable code and severity. The plain messages stay in
+// "warnings" for existing consumers.
+type jsonWarning struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
 type jsonTableMeta struct {
 	SizeBytes    int64           `json:"size_bytes"`
 	SizeHuman    string          `json:"size_human"`
 	RowCount     int64           `json:"row_count"`
 	IndexCount   int             `json:"index_count"`
+	Indexes      []jsonIndex     `json:"indexes,omitempty"` // only when the statement adds an index
 	ForeignKeys  jsonForeignKeys `json:"foreign_keys"`
 	TriggerCount int             `json:"trigger_count"`
 	Engine       string          `json:"engine"`
+	RowFormat    string          `json:"row_format,omitempty"`
+	WriteRate    *float64        `json:"write_rate_per_sec,omitempty"` // rows written per second, when sampled
+}
+
+type jsonProjected struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
 }
 
 type jsonForeignKeys struct {
@@ -56,6 +80,14 @@ type jsonForeignKeys struct {
 	Inbound  []jsonFKDetail `json:"inbound,omitempty"`
 }
 
+type jsonIndex struct {
+	Name      string   `json:"name"`
+	Columns   []string `json:"columns"`
+	Type      string   `json:"type,omitempty"`
+	Unique    bool     `json:"unique"`
+	Invisible bool     `json:"invisible,omitempty"`
+}
+
 type jsonFKDetail struct {
 	Name       string   `json:"name"`
 	Columns    []string `json:"columns"`
@@ -92,12 +124,15 @@ type jsonOperation struct {
 	Lock          string             `json:"lock,omitempty"`
 	RebuildsTable *bool              `json:"rebuilds_table,omitempty"`
 	SubOperations []jsonSubOperation `json:"sub_operations,omitempty"`
+	ColumnsBefore int                `json:"columns_before,omitempty"`
+	ColumnsAfter  int                `json:"columns_after,omitempty"`
 
 	// DML
 	DMLOp        string  `json:"dml_operation,omitempty"`
 	AffectedRows int64   `json:"affected_rows,omitempty"`
 	AffectedPct  float64 `json:"affected_pct,omitempty"`
 	WriteSetSize int64   `json:"write_set_bytes,omitempty"`
+	CascadeRows  int64   `json:"cascade_rows,omitempty"`
 	ChunkSize    int     `json:"chunk_size,omitempty"`
 	ChunkCount   int64   `json:"chunk_count,omitempty"`
 }
@@ -118,6 +153,22 @@ type jsonScript struct {
 	Path string `json:"path"`
 }
 
+type jsonSchedule struct {
+	Start            string `json:"start"`
+	End              string `json:"end"`
+	EstimatedSeconds int64  `json:"estimated_seconds"`
+	Recommendation   string `json:"recommendation"`
+}
+
+type jsonTraceStep struct {
+	Step          string `json:"step"`
+	Algorithm     string `json:"algorithm"`
+	Lock          string `json:"lock"`
+	RebuildsTable bool   `json:"rebuilds_table"`
+	Notes         string `json:"notes,omitempty"`
+	Detail        string `json:"detail,omitempty"`
+}
+
 type jsonDiskEstimate struct {
 	RequiredBytes int64  `json:"required_bytes"`
 	RequiredHuman string `json:"required_human"`
@@ -125,6 +176,12 @@ type jsonDiskEstimate struct {
 }
 
 func (r *JSONRenderer) RenderPlan(result *analyzer.Result) {
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(buildJSONPlan(result))
+}
+
+func buildJSONPlan(result *analyzer.Result) jsonPlanOutput {
 	out := jsonPlanOutput{
 		Statement: result.Statement,
 		Type:      string(result.StatementType),
@@ -139,6 +196,8 @@ func (r *JSONRenderer) RenderPlan(result *analyzer.Result) {
 			ForeignKeys:  buildJSONForeignKeys(result.TableMeta),
 			TriggerCount: len(result.TableMeta.Triggers),
 			Engine:       result.TableMeta.Engine,
+			RowFormat:    result.TableMeta.RowFormat,
+			WriteRate:    result.TableMeta.WriteRate,
 		},
 		Topology: jsonTopology{
 			Type:           string(result.Topology.Type),
@@ -148,14 +207,17 @@ func (r *JSONRenderer) RenderPlan(result *analyzer.Result) {
 			AuroraVersion:  result.Topology.Version.AuroraVersion,
 		},
 		Risk:                        string(result.Risk),
+		RiskScore:                   analyzer.RiskScore(result),
 		Method:                      string(result.Method),
 		AlternativeMethod:           string(result.AlternativeMethod),
 		Recommendation:              result.Recommendation,
 		ExecutionCommand:            result.ExecutionCommand,
 		AlternativeExecutionCommand: result.AlternativeExecutionCommand,
 		MethodRationale:             result.MethodRationale,
-		Warnings:                    result.Warnings,
-		ClusterWarnings:             result.ClusterWarnings,
+		Warnings:                    result.WarningMessages(),
+		WarningDetails:              jsonWarnings(result.Warnings),
+		ClusterWarnings:             result.ClusterWarningMessages(),
+		ClusterWarningDetails:       jsonWarnings(result.ClusterWarnings),
 	}
 
 	// Topology details
@@ -173,10 +235,16 @@ func (r *JSONRenderer) RenderPlan(result *analyzer.Result) {
 	if result.StatementType == parser.DDL {
 		rebuilds := result.Classification.RebuildsTable
 		op := jsonOperation{
-			DDLOp:         string(result.DDLOp),
-			Algorithm:     string(result.Classification.Algorithm),
-			Lock:          string(result.Classification.Lock),
-			RebuildsTable: &rebuilds,
+			DDLOp:     string(result.DDLOp),
+			Algorithm: string(result.Classification.Algorithm),
+			Lock:      string(result.Classification.Lock),
+		}
+		if result.Classification.Algorithm != "" { // none for account management
+			op.RebuildsTable = &rebuilds
+		}
+		if result.ColumnsBefore > 0 {
+			op.ColumnsBefore = result.ColumnsBefore
+			op.ColumnsAfter = result.ColumnsAfter
 		}
 		for _, sr := range result.SubOpResults {
 			op.SubOperations = append(op.SubOperations, jsonSubOperation{
@@ -193,6 +261,7 @@ func (r *JSONRenderer) RenderPlan(result *analyzer.Result) {
 			AffectedRows: result.AffectedRows,
 			AffectedPct:  result.AffectedPct,
 			WriteSetSize: result.WriteSetSize,
+			CascadeRows:  result.CascadeRows,
 			ChunkSize:    result.ChunkSize,
 			ChunkCount:   result.ChunkCount,
 		}
@@ -223,17 +292,53 @@ func (r *JSONRenderer) RenderPlan(result *analyzer.Result) {
 		}
 	}
 
+	out.BinlogBytes = result.EstimatedBinlogBytes
+
+	if s := result.Schedule; s != nil {
+		out.Schedule = &jsonSchedule{
+			Start:            fmt.Sprintf("%02d:00", s.StartHour),
+			End:              fmt.Sprintf("%02d:00", s.EndHour),
+			EstimatedSeconds: int64(s.Duration.Seconds()),
+			Recommendation:   s.Recommendation,
+		}
+	}
+
+	for _, step := range result.Trace {
+		out.Trace = append(out.Trace, jsonTraceStep{
+			Step:          step.Step,
+			Algorithm:     string(step.Classification.Algorithm),
+			Lock:          string(step.Classification.Lock),
+			RebuildsTable: step.Classification.RebuildsTable,
+			Notes:         step.Classification.Notes,
+			Detail:        step.Detail,
+		})
+	}
+
 	if result.IdempotentSP != "" {
 		out.IdempotentProcedure = result.IdempotentSP
 	}
 
+	if p := result.ProjectedSchema; p != nil {
+		out.ProjectedSchema = &jsonProjected{Before: p.Before, After: p.After}
+	}
+
 	if result.OptimizedDDL != "" {
 		out.OptimizedDDL = result.OptimizedDDL
 	}
 
-	enc := json.NewEncoder(r.w)
-	enc.SetIndent("", "  ")
-	_ = enc.Encode(out)
+	if addsIndex(result) {
+		for _, idx := range result.TableMeta.Indexes {
+			out.TableMeta.Indexes = append(out.TableMeta.Indexes, jsonIndex{
+				Name:      idx.Name,
+				Columns:   idx.Columns,
+				Type:      idx.Type,
+				Unique:    !idx.NonUnique,
+				Invisible: idx.Invisible,
+			})
+		}
+	}
+
+	return out
 }
 
 func buildJSONForeignKeys(meta *mysql.TableMetadata) jsonForeignKeys {
@@ -291,9 +396,19 @@ func (r *JSONRenderer) RenderTopology(conn mysql.ConnectionConfig, topo *topolog
 		if topo.Version.AuroraVersion != "" {
 			out["aurora_version"] = topo.Version.AuroraVersion
 		}
+	case topology.Proxied:
+		out["proxy"] = topo.Proxy
 	}
 
 	enc := json.NewEncoder(r.w)
 	enc.SetIndent("", "  ")
 	_ = enc.Encode(out)
 }
+
+func jsonWarnings(warnings []analyzer.Warning) []jsonWarning {
+	var out []jsonWarning
+	for _, w := range warnings {
+		out = append(out, jsonWarning{Code: string(w.Code), Severity: string(w.Severity), Message: w.Message})
+	}
+	return out
+}
@@ -16,6 +16,12 @@ type MarkdownRenderer struct {
 	w io.Writer
 }
 
+var riskEmoji = map[analyzer.RiskLevel]string{
+	analyzer.RiskSafe:      "✅",
+	analyzer.RiskCaution:   "⚠️",
+	analyzer.RiskDangerous: "❌",
+}
+
 func (r *MarkdownRenderer) RenderPlan(result *analyzer.Result) {
 	fmt.Fprintf(r.w, "# dbsafe — %s Analysis\n\n", result.StatementType)
 	fmt.Fprintf(r.w, "**Statement:** `%s`\n\n", result.Statement)
@@ -29,6 +35,12 @@ func (r *MarkdownRenderer) RenderPlan(result *analyzer.Result) {
 	fmt.Fprintf(r.w, "| Indexes | %d |\n", len(result.TableMeta.Indexes))
 	fmt.Fprintf(r.w, "| Triggers | %d |\n", len(result.TableMeta.Triggers))
 	fmt.Fprintf(r.w, "| Engine | %s |\n", result.TableMeta.Engine)
+	if result.TableMeta.RowFormat != "" {
+		fmt.Fprintf(r.w, "| Row format | %s |\n", result.TableMeta.RowFormat)
+	}
+	if result.TableMeta.WriteRate != nil {
+		fmt.Fprintf(r.w, "| Write rate | %s |\n", formatWriteRate(*result.TableMeta.WriteRate))
+	}
 	fmt.Fprintf(r.w, "| MySQL version | %s |\n\n", result.Version.String())
 
 	// Foreign keys detail
@@ -64,6 +76,22 @@ func (r *MarkdownRenderer) RenderPlan(result *analyzer.Result) {
 		fmt.Fprintln(r.w)
 	}
 
+	// Existing indexes
+	if addsIndex(result) && len(result.TableMeta.Indexes) > 0 {
+		fmt.Fprintf(r.w, "### Existing Indexes\n\n")
+		fmt.Fprintf(r.w, "| Index | Column(s) | Type | Unique | Visible |\n")
+		fmt.Fprintf(r.w, "|---|---|---|---|---|\n")
+		for _, idx := range result.TableMeta.Indexes {
+			fmt.Fprintf(r.w, "| %s | %s | %s | %v | %v |\n",
+				idx.Name,
+				strings.Join(idx.Columns, ", "),
+				idx.Type,
+				!idx.NonUnique,
+				!idx.Invisible)
+		}
+		fmt.Fprintln(r.w)
+	}
+
 	// Topology
 	if result.Topology.Type != topology.Standalone || result.Topology.IsCloudManaged {
 		fmt.Fprintf(r.w, "## Topology\n\n")
@@ -95,7 +123,7 @@ func (r *MarkdownRenderer) RenderPlan(result *analyzer.Result) {
 		if len(result.Warnings) > 0 {
 			fmt.Fprintf(r.w, "## ⚠ Warnings\n\n")
 			for _, w := range result.Warnings {
-				fmt.Fprintf(r.w, "- **Warning:** %s\n", w)
+				fmt.Fprintf(r.w, "- **Warning:** %s\n", w.Message)
 			}
 		}
 		return
@@ -109,9 +137,15 @@ func (r *MarkdownRenderer) RenderPlan(result *analyzer.Result) {
 		for _, sr := range result.SubOpResults {
 			fmt.Fprintf(r.w, "| Sub-op: %s | %s / %s |\n", sr.Op, sr.Classification.Algorithm, sr.Classification.Lock)
 		}
-		fmt.Fprintf(r.w, "| Algorithm | **%s** |\n", result.Classification.Algorithm)
-		fmt.Fprintf(r.w, "| Lock | %s |\n", result.Classification.Lock)
-		fmt.Fprintf(r.w, "| Rebuilds table | %v |\n\n", result.Classification.RebuildsTable)
+		if cols := formatColumnDelta(result.ColumnsBefore, result.ColumnsAfter); cols != "" {
+			fmt.Fprintf(r.w, "| Columns | %s |\n", cols)
+		}
+		if result.Classification.Algorithm != "" { // none for account management
+			fmt.Fprintf(r.w, "| Algorithm | **%s** |\n", result.Classification.Algorithm)
+			fmt.Fprintf(r.w, "| Lock | %s |\n", result.Classification.Lock)
+			fmt.Fprintf(r.w, "| Rebuilds table | %v |\n", result.Classification.RebuildsTable)
+		}
+		fmt.Fprintln(r.w)
 		if result.OptimizedDDL != "" {
 			fmt.Fprintf(r.w, "**Suggested DDL:**\n\n```sql\n%s\n```\n\n", result.OptimizedDDL)
 		}
@@ -119,36 +153,52 @@ func (r *MarkdownRenderer) RenderPlan(result *analyzer.Result) {
 		fmt.Fprintf(r.w, "| Property | Value |\n|---|---|\n")
 		fmt.Fprintf(r.w, "| Type | %s |\n", result.DMLOp)
 		fmt.Fprintf(r.w, "| Affected rows | ~%s (%.1f%%) |\n", formatNumber(result.AffectedRows), result.AffectedPct)
+		if result.CascadeRows > 0 {
+			fmt.Fprintf(r.w, "| Cascaded rows | ~%s in child tables |\n", formatNumber(result.CascadeRows))
+		}
 		if result.WriteSetSize > 0 {
 			fmt.Fprintf(r.w, "| Write-set estimate | %s |\n", humanBytes(result.WriteSetSize))
 		}
 		fmt.Fprintln(r.w)
 	}
 
+	// Classification decisions (--trace)
+	if len(result.Trace) > 0 {
+		fmt.Fprintf(r.w, "### Decision Trace\n\n")
+		for i, step := range result.Trace {
+			fmt.Fprintf(r.w, "%d. %s\n", i+1, formatTraceStep(step))
+		}
+		fmt.Fprintln(r.w)
+	}
+
 	// Warnings
 	if len(result.Warnings) > 0 || len(result.ClusterWarnings) > 0 {
 		fmt.Fprintf(r.w, "## ⚠ Warnings\n\n")
 		for _, w := range result.Warnings {
-			fmt.Fprintf(r.w, "- **Warning:** %s\n", w)
+			fmt.Fprintf(r.w, "- **Warning:** %s\n", w.Message)
 		}
 		for _, w := range result.ClusterWarnings {
-			fmt.Fprintf(r.w, "- **Cluster:** %s\n", w)
+			fmt.Fprintf(r.w, "- **Cluster:** %s\n", w.Message)
 		}
 		fmt.Fprintln(r.w)
 	}
 
 	// Recommendation
-	riskEmoji := map[analyzer.RiskLevel]string{
-		analyzer.RiskSafe:      "✅",
-		analyzer.RiskCaution:   "⚠️",
-		analyzer.RiskDangerous: "❌",
-	}
 	fmt.Fprintf(r.w, "## %s Recommendation: %s\n\n", riskEmoji[result.Risk], result.Risk)
 	fmt.Fprintf(r.w, "**Method:** %s\n\n", result.Method)
 	fmt.Fprintf(r.w, "%s\n\n", result.Recommendation)
+	if result.ExecutionCommand == "" && result.MethodRationale != "" {
+		fmt.Fprintf(r.w, "> %s\n\n", result.MethodRationale)
+	}
 	if result.DiskEstimate != nil {
 		fmt.Fprintf(r.w, "> **Disk space required:** ~%s\n> %s\n\n", result.DiskEstimate.RequiredHuman, result.DiskEstimate.Reason)
 	}
+	if result.EstimatedBinlogBytes > 0 {
+		fmt.Fprintf(r.w, "> **Binlog volume:** ~%s\n\n", humanBytes(result.EstimatedBinlogBytes))
+	}
+	if result.Schedule != nil {
+		fmt.Fprintf(r.w, "> **Schedule:** %s\n\n", result.Schedule.Recommendation)
+	}
 
 	// Execution command(s) (if available)
 	if result.ExecutionCommand != "" {
@@ -197,6 +247,19 @@ func (r *MarkdownRenderer) RenderPlan(result *analyzer.Result) {
 		fmt.Fprintf(r.w, "Run this instead of the raw DDL to make it safe to re-execute:\n\n")
 		fmt.Fprintf(r.w, "```sql\n%s\n```\n", result.IdempotentSP)
 	}
+
+	// Projected CREATE TABLE
+	if result.ProjectedSchema != nil {
+		fmt.Fprintf(r.w, "\n## Projected Schema\n\n")
+		fmt.Fprintf(r.w, "The table after the ALTER:\n\n")
+		fmt.Fprintf(r.w, "```diff\n%s\n```\n", strings.Join(result.ProjectedSchema.Lines(), "\n"))
+	}
+}
+
+// WriteReport writes the full analysis as a Markdown report for change-management
+// tickets, independent of the --format used for terminal output.
+func WriteReport(w io.Writer, result *analyzer.Result) {
+	(&MarkdownRenderer{w: w}).RenderPlan(result)
 }
 
 func (r *MarkdownRenderer) RenderTopology(conn mysql.ConnectionConfig, topo *topology.Info) {
@@ -25,6 +25,12 @@ func (r *PlainRenderer) RenderPlan(result *analyzer.Result) {
 	fmt.Fprintf(r.w, "Row count:     ~%s\n", formatNumber(result.TableMeta.RowCount))
 	fmt.Fprintf(r.w, "Indexes:       %d\n", len(result.TableMeta.Indexes))
 	fmt.Fprintf(r.w, "Engine:        %s\n", result.TableMeta.Engine)
+	if result.TableMeta.RowFormat != "" {
+		fmt.Fprintf(r.w, "Row format:    %s\n", result.TableMeta.RowFormat)
+	}
+	if result.TableMeta.WriteRate != nil {
+		fmt.Fprintf(r.w, "Write rate:    %s\n", formatWriteRate(*result.TableMeta.WriteRate))
+	}
 	fmt.Fprintln(r.w)
 
 	// Foreign keys
@@ -68,6 +74,15 @@ func (r *PlainRenderer) RenderPlan(result *analyzer.Result) {
 		fmt.Fprintln(r.w)
 	}
 
+	// Existing indexes
+	if addsIndex(result) && len(result.TableMeta.Indexes) > 0 {
+		fmt.Fprintf(r.w, "--- Existing Indexes (%d) ---\n", len(result.TableMeta.Indexes))
+		for _, idx := range result.TableMeta.Indexes {
+			fmt.Fprintf(r.w, "  %s\n", formatIndex(idx))
+		}
+		fmt.Fprintln(r.w)
+	}
+
 	// Topology
 	if result.Topology.Type != topology.Standalone || result.Topology.IsCloudManaged {
 		fmt.Fprintf(r.w, "--- Topology ---\n")
@@ -81,7 +96,7 @@ func (r *PlainRenderer) RenderPlan(result *analyzer.Result) {
 	// For unparsable DDL, only show warnings
 	if result.DDLOp == parser.OtherDDL {
 		for _, w := range result.Warnings {
-			fmt.Fprintf(r.w, "WARNING: %s\n", w)
+			fmt.Fprintf(r.w, "WARNING: %s\n", w.Message)
 		}
 		return
 	}
@@ -97,24 +112,41 @@ func (r *PlainRenderer) RenderPlan(result *analyzer.Result) {
 			}
 			fmt.Fprintf(r.w, "Sub-ops:       %s\n", strings.Join(parts, ", "))
 		}
-		fmt.Fprintf(r.w, "Algorithm:     %s\n", result.Classification.Algorithm)
-		fmt.Fprintf(r.w, "Lock:          %s\n", result.Classification.Lock)
-		fmt.Fprintf(r.w, "Rebuilds:      %v\n", result.Classification.RebuildsTable)
+		if cols := formatColumnDelta(result.ColumnsBefore, result.ColumnsAfter); cols != "" {
+			fmt.Fprintf(r.w, "Columns:       %s\n", cols)
+		}
+		if result.Classification.Algorithm != "" { // none for account management
+			fmt.Fprintf(r.w, "Algorithm:     %s\n", result.Classification.Algorithm)
+			fmt.Fprintf(r.w, "Lock:          %s\n", result.Classification.Lock)
+			fmt.Fprintf(r.w, "Rebuilds:      %v\n", result.Classification.RebuildsTable)
+		}
 		if result.OptimizedDDL != "" {
 			fmt.Fprintf(r.w, "Suggested DDL: %s\n", result.OptimizedDDL)
 		}
 	} else {
 		fmt.Fprintf(r.w, "Type:          %s\n", result.DMLOp)
 		fmt.Fprintf(r.w, "Affected rows: ~%s (%.1f%%)\n", formatNumber(result.AffectedRows), result.AffectedPct)
+		if result.CascadeRows > 0 {
+			fmt.Fprintf(r.w, "Cascaded rows: ~%s in child tables\n", formatNumber(result.CascadeRows))
+		}
 	}
 	fmt.Fprintln(r.w)
 
+	// Classification decisions (--trace)
+	if len(result.Trace) > 0 {
+		fmt.Fprintf(r.w, "--- Decision Trace ---\n")
+		for i, step := range result.Trace {
+			fmt.Fprintf(r.w, "%d. %s\n", i+1, formatTraceStep(step))
+		}
+		fmt.Fprintln(r.w)
+	}
+
 	// Warnings
 	for _, w := range result.Warnings {
-		fmt.Fprintf(r.w, "WARNING: %s\n", w)
+		fmt.Fprintf(r.w, "WARNING: %s\n", w.Message)
 	}
 	for _, w := range result.ClusterWarnings {
-		fmt.Fprintf(r.w, "CLUSTER WARNING: %s\n", w)
+		fmt.Fprintf(r.w, "CLUSTER WARNING: %s\n", w.Message)
 	}
 	if len(result.Warnings) > 0 || len(result.ClusterWarnings) > 0 {
 		fmt.Fprintln(r.w)
@@ -128,6 +160,12 @@ func (r *PlainRenderer) RenderPlan(result *analyzer.Result) {
 	if result.DiskEstimate != nil {
 		fmt.Fprintf(r.w, "Disk required: ~%s (%s)\n", result.DiskEstimate.RequiredHuman, result.DiskEstimate.Reason)
 	}
+	if result.EstimatedBinlogBytes > 0 {
+		fmt.Fprintf(r.w, "Binlog volume: ~%s\n", humanBytes(result.EstimatedBinlogBytes))
+	}
+	if result.Schedule != nil {
+		fmt.Fprintf(r.w, "Schedule:      %s\n", result.Schedule.Recommendation)
+	}
 	fmt.Fprintln(r.w)
 
 	// Execution command(s) (if available)
@@ -175,6 +213,12 @@ func (r *PlainRenderer) RenderPlan(result *analyzer.Result) {
 		fmt.Fprintf(r.w, "\n--- Idempotent Procedure ---\n")
 		fmt.Fprintf(r.w, "%s\n", result.IdempotentSP)
 	}
+
+	// Projected CREATE TABLE
+	if result.ProjectedSchema != nil {
+		fmt.Fprintf(r.w, "\n--- Projected Schema ---\n")
+		fmt.Fprintf(r.w, "%s\n", strings.Join(result.ProjectedSchema.Lines(), "\n"))
+	}
 }
 
 func (r *PlainRenderer) RenderTopology(conn mysql.ConnectionConfig, topo *topology.Info) {
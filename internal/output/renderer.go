@@ -11,6 +11,9 @@ import (
 // Renderer defines the output interface.
 type Renderer interface {
 	RenderPlan(result *analyzer.Result)
+	RenderDiff(plan *analyzer.DiffPlan)
+	RenderBatch(batch *analyzer.BatchResult)
+	RenderShards(plan *analyzer.ShardPlan)
 	RenderTopology(conn mysql.ConnectionConfig, topo *topology.Info)
 }
 
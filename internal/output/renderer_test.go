@@ -2,8 +2,15 @@ package output
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -97,8 +104,8 @@ func dmlResult() *analyzer.Result {
 
 func dmlResultWithWarnings() *analyzer.Result {
 	r := dmlResult()
-	r.Warnings = []string{"No WHERE clause! This will affect ALL rows."}
-	r.ClusterWarnings = []string{"Flow control paused at 5.0%."}
+	r.Warnings = []analyzer.Warning{{Code: analyzer.WarnNoWhereClause, Severity: analyzer.SeverityCritical, Message: "No WHERE clause! This will affect ALL rows."}}
+	r.ClusterWarnings = []analyzer.Warning{{Code: analyzer.WarnFlowControlPaused, Severity: analyzer.SeverityWarning, Message: "Flow control paused at 5.0%."}}
 	return r
 }
 
@@ -128,7 +135,7 @@ func galeraResult() *analyzer.Result {
 		FlowControlPausedPct: "0.0%",
 	}
 	r.Classification.Algorithm = analyzer.AlgoInplace
-	r.ClusterWarnings = []string{"TOI will execute this DDL on ALL 3 nodes simultaneously."}
+	r.ClusterWarnings = []analyzer.Warning{{Code: analyzer.WarnGaleraTOI, Severity: analyzer.SeverityWarning, Message: "TOI will execute this DDL on ALL 3 nodes simultaneously."}}
 	return r
 }
 
@@ -309,6 +316,72 @@ func TestPlainRenderer_RenderPlan_DiskEstimate_Shown(t *testing.T) {
 	}
 }
 
+func TestRenderPlan_Schedule(t *testing.T) {
+	result := ddlResultWithDiskEstimate()
+	result.Schedule = &analyzer.ScheduleWindow{
+		StartHour: 2, EndHour: 3, Duration: 40 * time.Minute,
+		Recommendation: "Estimated 40-minute operation; start at 02:00 to finish before the 03:00 batch jobs.",
+	}
+
+	var plain bytes.Buffer
+	(&PlainRenderer{w: &plain}).RenderPlan(result)
+	if !strings.Contains(plain.String(), "Schedule:      Estimated 40-minute operation; start at 02:00") {
+		t.Errorf("plain output missing the schedule:\n%s", plain.String())
+	}
+
+	var js bytes.Buffer
+	(&JSONRenderer{w: &js}).RenderPlan(result)
+	var out map[string]any
+	if err := json.Unmarshal(js.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	schedule, _ := out["schedule"].(map[string]any)
+	if schedule["start"] != "02:00" || schedule["end"] != "03:00" || schedule["estimated_seconds"] != float64(2400) {
+		t.Errorf("schedule = %v", out["schedule"])
+	}
+}
+
+func TestRenderPlan_Trace(t *testing.T) {
+	result := ddlResult()
+	result.Trace = []analyzer.TraceStep{
+		{Step: "matrix baseline: ADD_PRIMARY_KEY on 8.0.35, range 8.0.29+", Classification: analyzer.DDLClassification{Algorithm: analyzer.AlgoInplace, Lock: analyzer.LockNone, RebuildsTable: true}},
+		{Step: "ADD PRIMARY KEY on a nullable column", Classification: analyzer.DDLClassification{Algorithm: analyzer.AlgoCopy, Lock: analyzer.LockShared, RebuildsTable: true}},
+		{Step: "final", Classification: analyzer.DDLClassification{Algorithm: analyzer.AlgoCopy, Lock: analyzer.LockShared, RebuildsTable: true}, Detail: "risk DANGEROUS, method gh-ost"},
+	}
+
+	var plain bytes.Buffer
+	(&PlainRenderer{w: &plain}).RenderPlan(result)
+	for _, want := range []string{
+		"--- Decision Trace ---",
+		"2. ADD PRIMARY KEY on a nullable column → COPY / SHARED, rebuilds",
+		"3. final → COPY / SHARED, rebuilds: risk DANGEROUS, method gh-ost",
+	} {
+		if !strings.Contains(plain.String(), want) {
+			t.Errorf("plain output missing %q:\n%s", want, plain.String())
+		}
+	}
+
+	var js bytes.Buffer
+	(&JSONRenderer{w: &js}).RenderPlan(result)
+	var out map[string]any
+	if err := json.Unmarshal(js.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	trace, _ := out["trace"].([]any)
+	if len(trace) != 3 {
+		t.Fatalf("trace = %v, want 3 steps", out["trace"])
+	}
+	if step, _ := trace[1].(map[string]any); step["algorithm"] != "COPY" || step["lock"] != "SHARED" {
+		t.Errorf("trace[1] = %v", step)
+	}
+
+	var untraced bytes.Buffer
+	(&PlainRenderer{w: &untraced}).RenderPlan(ddlResult())
+	if strings.Contains(untraced.String(), "Decision Trace") {
+		t.Error("plain output shows a decision trace without --trace")
+	}
+}
+
 func TestPlainRenderer_RenderPlan_DiskEstimate_Absent_ForInstant(t *testing.T) {
 	var buf bytes.Buffer
 	r := &PlainRenderer{w: &buf}
@@ -421,6 +494,9 @@ func TestJSONRenderer_RenderPlan_DDL(t *testing.T) {
 	if out["risk"] != "SAFE" {
 		t.Errorf("risk = %v, want SAFE", out["risk"])
 	}
+	if out["risk_score"] != float64(0) {
+		t.Errorf("risk_score = %v, want 0", out["risk_score"])
+	}
 	if out["recommended_method"] != "DIRECT" {
 		t.Errorf("recommended_method = %v, want DIRECT", out["recommended_method"])
 	}
@@ -463,6 +539,9 @@ func TestJSONRenderer_RenderPlan_DML(t *testing.T) {
 	if out["risk"] != "DANGEROUS" {
 		t.Errorf("risk = %v, want DANGEROUS", out["risk"])
 	}
+	if out["risk_score"] != float64(17) {
+		t.Errorf("risk_score = %v, want 17", out["risk_score"])
+	}
 	if out["recommended_method"] != "CHUNKED" {
 		t.Errorf("recommended_method = %v, want CHUNKED", out["recommended_method"])
 	}
@@ -503,6 +582,36 @@ func TestJSONRenderer_RenderPlan_Warnings(t *testing.T) {
 	if len(clusterWarnings) != 1 {
 		t.Errorf("cluster_warnings length = %d, want 1", len(clusterWarnings))
 	}
+	details := out["cluster_warning_details"].([]any)
+	if len(details) != 1 || details[0].(map[string]any)["code"] != "FLOW_CONTROL_PAUSED" {
+		t.Errorf("cluster_warning_details = %v, want one FLOW_CONTROL_PAUSED", details)
+	}
+}
+
+func TestJSONRenderer_RenderPlan_WarningDetails(t *testing.T) {
+	var buf bytes.Buffer
+	r := &JSONRenderer{w: &buf}
+	r.RenderPlan(dmlResultWithWarnings())
+
+	var out struct {
+		Warnings       []string `json:"warnings"`
+		WarningDetails []struct {
+			Code     string `json:"code"`
+			Severity string `json:"severity"`
+			Message  string `json:"message"`
+		} `json:"warning_details"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if len(out.WarningDetails) != 1 {
+		t.Fatalf("warning_details length = %d, want 1", len(out.WarningDetails))
+	}
+	d := out.WarningDetails[0]
+	if d.Code != "NO_WHERE_CLAUSE" || d.Severity != "CRITICAL" || d.Message != out.Warnings[0] {
+		t.Errorf("warning_details[0] = %+v, want NO_WHERE_CLAUSE/CRITICAL with the same message as warnings[0]", d)
+	}
 }
 
 func TestJSONRenderer_RenderPlan_NoWarningsOmitted(t *testing.T) {
@@ -989,6 +1098,8 @@ func TestFormatTopoType(t *testing.T) {
 		{&topology.Info{Type: topology.SemiSyncReplica}, "Semi-sync Replication"},
 		{&topology.Info{Type: topology.Galera, GaleraClusterSize: 3}, "Percona XtraDB Cluster (3 nodes)"},
 		{&topology.Info{Type: topology.GroupRepl, GRMode: "SINGLE-PRIMARY", GRMemberCount: 3}, "Group Replication (SINGLE-PRIMARY, 3 members)"},
+		{&topology.Info{Type: topology.Proxied, Proxy: "proxysql"}, "Proxied (ProxySQL)"},
+		{&topology.Info{Type: topology.Proxied, Proxy: "vitess"}, "Proxied (Vitess vtgate)"},
 	}
 	for _, tt := range tests {
 		got := formatTopoType(tt.topo)
@@ -1190,3 +1301,580 @@ func TestJSONRenderer_MultiOp_SubOperations(t *testing.T) {
 		t.Errorf("JSON sub_operations: got %v, want 2-element array", op["sub_operations"])
 	}
 }
+
+func TestRenderers_MultiOp_ColumnDelta(t *testing.T) {
+	result := multiOpResult()
+	result.ColumnsBefore = 42
+	result.ColumnsAfter = 47
+
+	for _, format := range []string{"text", "plain", "markdown"} {
+		var buf bytes.Buffer
+		NewRenderer(format, &buf).RenderPlan(result)
+		if !strings.Contains(buf.String(), "42 → 47 (+5)") {
+			t.Errorf("%s output missing column delta, got:\n%s", format, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	NewRenderer("json", &buf).RenderPlan(result)
+	var parsed map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("JSON output is not valid JSON: %v", err)
+	}
+	op := parsed["operation"].(map[string]any)
+	if op["columns_before"] != float64(42) || op["columns_after"] != float64(47) {
+		t.Errorf("JSON columns_before/after = %v/%v, want 42/47", op["columns_before"], op["columns_after"])
+	}
+
+	// Unchanged column count renders nothing.
+	result.ColumnsAfter = 42
+	buf.Reset()
+	NewRenderer("plain", &buf).RenderPlan(result)
+	if strings.Contains(buf.String(), "Columns:") {
+		t.Error("plain output should omit Columns: line when the count is unchanged")
+	}
+}
+
+func TestRenderers_RowFormat(t *testing.T) {
+	result := ddlResult()
+	result.TableMeta.RowFormat = "Compressed"
+
+	for _, format := range []string{"text", "plain", "markdown"} {
+		var buf bytes.Buffer
+		NewRenderer(format, &buf).RenderPlan(result)
+		if !strings.Contains(buf.String(), "Row format") || !strings.Contains(buf.String(), "Compressed") {
+			t.Errorf("%s output missing row format, got:\n%s", format, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	NewRenderer("json", &buf).RenderPlan(result)
+	var parsed map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("JSON output is not valid JSON: %v", err)
+	}
+	meta := parsed["table_metadata"].(map[string]any)
+	if meta["row_format"] != "Compressed" {
+		t.Errorf("JSON row_format = %v, want Compressed", meta["row_format"])
+	}
+
+	// Unknown row format renders nothing.
+	result.TableMeta.RowFormat = ""
+	buf.Reset()
+	NewRenderer("plain", &buf).RenderPlan(result)
+	if strings.Contains(buf.String(), "Row format:") {
+		t.Error("plain output should omit Row format: line when unknown")
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	result := ddlResultWithDiskEstimate()
+	result.Warnings = []analyzer.Warning{{Code: analyzer.WarnTriggerFiresPerRow, Severity: analyzer.SeverityInfo, Message: "Table has 1 trigger(s)."}}
+	result.ExecutionCommand = "gh-ost --alter=\"ADD COLUMN email VARCHAR(255)\" --execute"
+	result.MethodRationale = "gh-ost is preferred: triggerless, throttles on replica lag."
+
+	var buf bytes.Buffer
+	WriteReport(&buf, result)
+	out := buf.String()
+
+	for _, want := range []string{
+		"## Table Metadata",
+		"| Table | `testdb.users` |",
+		"| Algorithm | **COPY** |",
+		"| Lock | NONE |",
+		"| Rebuilds table | true |",
+		"Recommendation: DANGEROUS",
+		"**Method:** GH-OST",
+		"> gh-ost is preferred",
+		"- **Warning:** Table has 1 trigger(s).",
+		"**Disk space required:** ~2.0 GB",
+		"```bash\ngh-ost --alter=",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+}
+
+func TestWriteReport_RationaleWithoutCommand(t *testing.T) {
+	result := ddlResult()
+	result.MethodRationale = "pt-osc excluded: table has triggers."
+
+	var buf bytes.Buffer
+	WriteReport(&buf, result)
+	if !strings.Contains(buf.String(), "> pt-osc excluded: table has triggers.") {
+		t.Errorf("report should include the method rationale even without a command, got:\n%s", buf.String())
+	}
+}
+
+func diffPlan() *analyzer.DiffPlan {
+	return &analyzer.DiffPlan{
+		Database: "testdb",
+		Table:    "users",
+		Steps:    []analyzer.DiffStep{{SQL: "ALTER TABLE `users` ADD COLUMN `email` varchar(255)", Result: ddlResult()}},
+		Risk:     analyzer.RiskDangerous,
+		Warnings: []analyzer.Warning{{Code: analyzer.WarnPossibleRename, Severity: analyzer.SeverityCritical, Message: "The diff drops `mail` and adds `email`."}},
+	}
+}
+
+func TestJSONRenderer_RenderDiff(t *testing.T) {
+	var buf bytes.Buffer
+	r := &JSONRenderer{w: &buf}
+	r.RenderDiff(diffPlan())
+
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if out["risk"] != "DANGEROUS" {
+		t.Errorf("risk = %v, want DANGEROUS", out["risk"])
+	}
+	steps, ok := out["steps"].([]any)
+	if !ok || len(steps) != 1 {
+		t.Fatalf("steps = %v, want 1 step", out["steps"])
+	}
+	step := steps[0].(map[string]any)
+	if step["sql"] != "ALTER TABLE `users` ADD COLUMN `email` varchar(255)" {
+		t.Errorf("steps[0].sql = %v", step["sql"])
+	}
+	analysis, ok := step["analysis"].(map[string]any)
+	if !ok || analysis["table"] != "users" {
+		t.Errorf("steps[0].analysis should be the step's plan output, got %v", step["analysis"])
+	}
+}
+
+func TestPlainRenderer_RenderDiff(t *testing.T) {
+	var buf bytes.Buffer
+	r := &PlainRenderer{w: &buf}
+	r.RenderDiff(diffPlan())
+	out := buf.String()
+
+	for _, want := range []string{
+		"Table:         testdb.users",
+		"Combined risk: DANGEROUS",
+		"1. ALTER TABLE `users` ADD COLUMN `email` varchar(255)",
+		"WARNING: The diff drops `mail`",
+		"### Step 1 of 1 ###",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderDiff_NoDifferences(t *testing.T) {
+	empty := &analyzer.DiffPlan{Risk: analyzer.RiskSafe}
+	for _, format := range []string{"text", "plain", "markdown"} {
+		var buf bytes.Buffer
+		NewRenderer(format, &buf).RenderDiff(empty)
+		if !strings.Contains(buf.String(), "No differences") {
+			t.Errorf("%s output should say there are no differences, got:\n%s", format, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	NewRenderer("json", &buf).RenderDiff(empty)
+	if !strings.Contains(buf.String(), `"steps": []`) {
+		t.Errorf("JSON output should have an empty steps array, got:\n%s", buf.String())
+	}
+}
+
+func addIndexResult() *analyzer.Result {
+	result := ddlResult()
+	result.Statement = "ALTER TABLE users ADD INDEX idx_name_created (name, created_at)"
+	result.DDLOp = parser.AddIndex
+	result.TableMeta.Indexes = []mysql.IndexInfo{
+		{Name: "PRIMARY", Columns: []string{"id"}, SubParts: []int{0}, Type: "BTREE"},
+		{Name: "uk_email", Columns: []string{"email"}, SubParts: []int{0}, Type: "BTREE"},
+		{Name: "idx_name", Columns: []string{"name"}, SubParts: []int{20}, NonUnique: true, Type: "BTREE", Invisible: true},
+	}
+	return result
+}
+
+func TestFormatIndex(t *testing.T) {
+	indexes := addIndexResult().TableMeta.Indexes
+	want := []string{
+		"PRIMARY (id) BTREE",
+		"uk_email (email) BTREE UNIQUE",
+		"idx_name (name(20)) BTREE INVISIBLE",
+	}
+	for i, idx := range indexes {
+		if got := formatIndex(idx); got != want[i] {
+			t.Errorf("formatIndex(%s) = %q, want %q", idx.Name, got, want[i])
+		}
+	}
+}
+
+func TestRenderPlan_ExistingIndexes(t *testing.T) {
+	for _, format := range []string{"text", "plain", "markdown"} {
+		var buf bytes.Buffer
+		NewRenderer(format, &buf).RenderPlan(addIndexResult())
+		if !strings.Contains(buf.String(), "Existing Indexes") || !strings.Contains(buf.String(), "uk_email") {
+			t.Errorf("%s output should list the existing indexes, got:\n%s", format, buf.String())
+		}
+
+		buf.Reset()
+		NewRenderer(format, &buf).RenderPlan(ddlResult())
+		if strings.Contains(buf.String(), "Existing Indexes") {
+			t.Errorf("%s output should list existing indexes only when adding one, got:\n%s", format, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	NewRenderer("json", &buf).RenderPlan(addIndexResult())
+	var out struct {
+		TableMeta struct {
+			Indexes []jsonIndex `json:"indexes"`
+		} `json:"table_metadata"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(out.TableMeta.Indexes) != 3 || !out.TableMeta.Indexes[1].Unique || !out.TableMeta.Indexes[2].Invisible {
+		t.Errorf("indexes = %+v, want 3 with uk_email unique and idx_name invisible", out.TableMeta.Indexes)
+	}
+}
+
+// =============================================================
+// Severity ordering and color control
+// =============================================================
+
+func TestSortBySeverity(t *testing.T) {
+	warnings := []analyzer.Warning{
+		{Code: "A", Severity: analyzer.SeverityInfo},
+		{Code: "B", Severity: analyzer.SeverityWarning},
+		{Code: "C", Severity: analyzer.SeverityCritical},
+		{Code: "D", Severity: analyzer.SeverityInfo},
+		{Code: "E", Severity: analyzer.SeverityCritical},
+	}
+
+	sorted := sortBySeverity(warnings)
+
+	var got []string
+	for _, w := range sorted {
+		got = append(got, string(w.Code))
+	}
+	if want := "C E B A D"; strings.Join(got, " ") != want {
+		t.Errorf("order = %s, want %s", strings.Join(got, " "), want)
+	}
+	if warnings[0].Code != "A" {
+		t.Error("sortBySeverity modified its input")
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name       string
+		noColor    bool
+		noColorEnv string
+		terminal   bool
+		want       bool
+	}{
+		{"terminal", false, "", true, true},
+		{"--no-color", true, "", true, false},
+		{"NO_COLOR set", false, "1", true, false},
+		{"not a terminal", false, "", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ColorEnabled(tt.noColor, tt.noColorEnv, tt.terminal); got != tt.want {
+				t.Errorf("ColorEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextRenderer_WarningsBySeverity(t *testing.T) {
+	result := ddlResult()
+	result.Warnings = []analyzer.Warning{
+		{Code: "NOTE", Severity: analyzer.SeverityInfo, Message: "informational note"},
+		{Code: "FAIL", Severity: analyzer.SeverityCritical, Message: "statement will fail"},
+	}
+
+	var buf bytes.Buffer
+	NewRenderer("text", &buf).RenderPlan(result)
+	out := buf.String()
+
+	critical, note := strings.Index(out, "statement will fail"), strings.Index(out, "informational note")
+	if critical < 0 || note < 0 || critical > note {
+		t.Errorf("expected the CRITICAL warning before the INFO note:\n%s", out)
+	}
+	if !strings.Contains(out, "Critical") || !strings.Contains(out, "Note") {
+		t.Errorf("expected severity titles in output:\n%s", out)
+	}
+}
+
+func TestWriteRollback_Flyway(t *testing.T) {
+	result := ddlResult()
+	result.RollbackSQL = "ALTER TABLE `testdb`.`users` DROP COLUMN `email`;"
+	result.RollbackNotes = "DROP COLUMN is INSTANT in your MySQL version."
+
+	var buf bytes.Buffer
+	if err := WriteRollback(&buf, result, RollbackFlyway); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "-- Flyway undo migration generated by dbsafe\n" +
+		"-- Table: testdb.users\n" +
+		"-- Undoes:\n-- ALTER TABLE users ADD COLUMN email VARCHAR(255)\n" +
+		"--\n-- DROP COLUMN is INSTANT in your MySQL version.\n\n" +
+		"ALTER TABLE `testdb`.`users` DROP COLUMN `email`;\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	// Without a rollback statement, the options are written commented out.
+	result.RollbackSQL, result.RollbackNotes = "", ""
+	result.RollbackOptions = []analyzer.RollbackOption{
+		{Label: "Pre-backup", SQL: "CREATE TABLE users_bak AS SELECT * FROM users;", Description: "Back up first."},
+		{Label: "Point-in-time recovery", Description: "Use the binary logs."},
+	}
+	buf.Reset()
+	if err := WriteRollback(&buf, result, RollbackFlyway); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "-- Pre-backup: Back up first.\n-- CREATE TABLE users_bak") || strings.Contains(out, "Point-in-time") {
+		t.Errorf("unexpected options:\n%s", out)
+	}
+
+	// Nothing to undo with, or an unknown format: an error, not an empty migration.
+	result.RollbackOptions = nil
+	result.RollbackNotes = "No rollback needed."
+	if err := WriteRollback(&buf, result, RollbackFlyway); err == nil || !strings.Contains(err.Error(), "No rollback needed.") {
+		t.Errorf("error = %v, want the rollback notes", err)
+	}
+	if err := WriteRollback(&buf, ddlResult(), "liquibase"); err == nil || !strings.Contains(err.Error(), "supported: flyway") {
+		t.Errorf("error = %v, want the supported formats", err)
+	}
+}
+
+func TestAppendAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	conn := &analyzer.ConnectionInfo{Host: "db1", User: "deploy"}
+
+	first := ddlResult()
+	first.Method = analyzer.ExecGhost
+	first.ExecutionCommand = "gh-ost --user=deploy --password=s3cret --alter=..."
+	if err := AppendAudit(path, NewAuditEntry(first, conn, "s3cret")); err != nil {
+		t.Fatalf("AppendAudit: %v", err)
+	}
+	second := ddlResult()
+	second.Statement = "ALTER TABLE users DROP COLUMN email"
+	if err := AppendAudit(path, NewAuditEntry(second, conn, "")); err != nil {
+		t.Fatalf("AppendAudit: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("permissions = %v, want 0600", info.Mode().Perm())
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Errorf("password not redacted:\n%s", data)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), data)
+	}
+
+	var e1, e2 AuditEntry
+	if err := json.Unmarshal([]byte(lines[0]), &e1); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &e2); err != nil {
+		t.Fatal(err)
+	}
+	if e1.PrevHash != "" || e1.User != "deploy" || e1.Host != "db1" || e1.Table != "users" || e1.Method != string(analyzer.ExecGhost) {
+		t.Errorf("first entry = %+v", e1)
+	}
+	if !strings.Contains(e1.Command, "--password=xxxxx") {
+		t.Errorf("command = %q, want the password redacted", e1.Command)
+	}
+	sum := sha256.Sum256([]byte(lines[0]))
+	if want := hex.EncodeToString(sum[:]); e2.PrevHash != want {
+		t.Errorf("second prev_hash = %q, want the first line's hash %q", e2.PrevHash, want)
+	}
+	if e2.Statement != "ALTER TABLE users DROP COLUMN email" {
+		t.Errorf("second statement = %q", e2.Statement)
+	}
+}
+
+// A short password is redacted from credential fields only, not from every place its
+// characters happen to appear.
+func TestNewAuditEntry_ShortPassword(t *testing.T) {
+	result := ddlResult()
+	result.Statement = "ALTER TABLE users ADD COLUMN a INT"
+	result.Method = analyzer.ExecGhost
+	result.ExecutionCommand = "migrate -dsn 'deploy:a@tcp(db1:3306)/shop' --password=a --alter='ADD COLUMN a INT'"
+
+	e := NewAuditEntry(result, &analyzer.ConnectionInfo{Host: "db1", User: "deploy"}, "a")
+	if e.Statement != result.Statement {
+		t.Errorf("statement = %q, want it unchanged", e.Statement)
+	}
+	want := "migrate -dsn 'deploy:xxxxx@tcp(db1:3306)/shop' --password=xxxxx --alter='ADD COLUMN a INT'"
+	if e.Command != want {
+		t.Errorf("command = %q, want %q", e.Command, want)
+	}
+}
+
+// Concurrent runs appending to the same log must still leave one unbroken hash chain.
+func TestAppendAudit_Concurrent(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("the audit log is only locked on linux and darwin")
+	}
+	path := filepath.Join(t.TempDir(), "audit.log")
+	const runs = 50
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make(chan error, runs)
+	for range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs <- AppendAudit(path, NewAuditEntry(ddlResult(), nil, ""), NewAuditEntry(ddlResult(), nil, ""))
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("AppendAudit: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2*runs {
+		t.Fatalf("got %d lines, want %d", len(lines), 2*runs)
+	}
+	for i, line := range lines {
+		var e AuditEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("line %d: %v", i+1, err)
+		}
+		want := ""
+		if i > 0 {
+			sum := sha256.Sum256([]byte(lines[i-1]))
+			want = hex.EncodeToString(sum[:])
+		}
+		if e.PrevHash != want {
+			t.Errorf("line %d: prev_hash = %q, want %q", i+1, e.PrevHash, want)
+		}
+	}
+}
+
+// An account statement's password must not reach the audit log.
+func TestAppendAudit_AccountManagement(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	parsed, err := parser.Parse("ALTER USER 'app'@'%' IDENTIFIED BY 'hunter2'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := mysql.ServerVersion{Major: 8, Minor: 0, Patch: 35, Flavor: "mysql"}
+	result, err := analyzer.AnalyzeOffline(parsed, nil, analyzer.Options{Version: &v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendAudit(path, NewAuditEntry(result, nil, "")); err != nil {
+		t.Fatalf("AppendAudit: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Errorf("password in the audit line:\n%s", data)
+	}
+	var e AuditEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Command != "" || e.Algorithm != "" {
+		t.Errorf("entry = %+v, want no command or algorithm", e)
+	}
+}
+
+func TestRenderBatch(t *testing.T) {
+	batch := &analyzer.BatchResult{
+		Results:  []*analyzer.Result{ddlResult(), dmlResult()},
+		Risk:     analyzer.RiskCaution,
+		Warnings: []analyzer.Warning{{Code: analyzer.WarnMergeableAlters, Severity: analyzer.SeverityWarning, Message: "Statements 1, 2 each alter `users`"}},
+	}
+
+	for _, format := range []string{"text", "plain", "markdown"} {
+		var buf bytes.Buffer
+		NewRenderer(format, &buf).RenderBatch(batch)
+		out := buf.String()
+		for _, want := range []string{"ADD COLUMN email", "DELETE FROM logs", "CAUTION", "Statements 1, 2 each alter"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s output missing %q:\n%s", format, want, out)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	NewRenderer("json", &buf).RenderBatch(batch)
+	var out struct {
+		Risk       string   `json:"risk"`
+		Warnings   []string `json:"warnings"`
+		Statements []struct {
+			SQL      string          `json:"sql"`
+			Analysis json.RawMessage `json:"analysis"`
+		} `json:"statements"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if out.Risk != "CAUTION" || len(out.Warnings) != 1 || len(out.Statements) != 2 ||
+		out.Statements[1].SQL != "DELETE FROM logs WHERE created_at < '2023-01-01'" || out.Statements[0].Analysis == nil {
+		t.Errorf("JSON = %+v", out)
+	}
+}
+
+func TestRenderShards(t *testing.T) {
+	large := ddlResult()
+	large.TableMeta.DataLength = 5 * 1024 * 1024 * 1024
+	large.Classification.Algorithm = analyzer.AlgoCopy
+	large.Risk, large.Method = analyzer.RiskDangerous, analyzer.ExecGhost
+	plan := analyzer.AggregateShards([]analyzer.ShardResult{
+		{Name: "shard1", Result: ddlResult()},
+		{Name: "shard2", Result: large},
+		{Name: "shard3", Err: errors.New("connection refused")},
+	})
+
+	for _, format := range []string{"text", "plain", "markdown"} {
+		var buf bytes.Buffer
+		NewRenderer(format, &buf).RenderShards(plan)
+		out := buf.String()
+		for _, want := range []string{"shard1", "shard2", "shard3", "DANGEROUS", "GH-OST", "5.0 GB", "not analyzed", "connection refused"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s output missing %q:\n%s", format, want, out)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	NewRenderer("json", &buf).RenderShards(plan)
+	var out struct {
+		Risk       string `json:"risk"`
+		WorstShard string `json:"worst_shard"`
+		Shards     []struct {
+			Name     string          `json:"name"`
+			Error    string          `json:"error"`
+			Analysis json.RawMessage `json:"analysis"`
+		} `json:"shards"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if out.Risk != "DANGEROUS" || out.WorstShard != "shard2" || len(out.Shards) != 3 ||
+		out.Shards[1].Analysis == nil || out.Shards[2].Error != "connection refused" {
+		t.Errorf("JSON = %+v", out)
+	}
+}
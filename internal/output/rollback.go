@@ -0,0 +1,97 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/nethalo/dbsafe/internal/analyzer"
+)
+
+// RollbackFormat is a migration framework the rollback can be written for.
+type RollbackFormat string
+
+const (
+	RollbackFlyway RollbackFormat = "flyway" // undo migration, U<version>__<description>.sql
+)
+
+// rollbackWriters serializes a result's rollback for each framework.
+var rollbackWriters = map[RollbackFormat]func(io.Writer, *analyzer.Result) error{
+	RollbackFlyway: writeFlywayUndo,
+}
+
+// RollbackFormats returns the supported rollback formats, sorted.
+func RollbackFormats() []RollbackFormat {
+	formats := make([]RollbackFormat, 0, len(rollbackWriters))
+	for f := range rollbackWriters {
+		formats = append(formats, f)
+	}
+	slices.Sort(formats)
+	return formats
+}
+
+// WriteRollback writes the result's rollback (RollbackSQL, or the RollbackOptions when
+// there is none) as a changeset for format. It fails when the result has no rollback SQL
+// at all: an undo migration that does nothing would pass for one that works.
+func WriteRollback(w io.Writer, result *analyzer.Result, format RollbackFormat) error {
+	write, ok := rollbackWriters[format]
+	if !ok {
+		return fmt.Errorf("unknown rollback format %q (supported: %s)", format, joinFormats(RollbackFormats()))
+	}
+	if result.RollbackSQL == "" && !slices.ContainsFunc(result.RollbackOptions, func(o analyzer.RollbackOption) bool { return o.SQL != "" }) {
+		if result.RollbackNotes != "" {
+			return fmt.Errorf("no rollback SQL to write: %s", result.RollbackNotes)
+		}
+		return fmt.Errorf("no rollback SQL to write")
+	}
+	return write(w, result)
+}
+
+// writeFlywayUndo writes a Flyway undo migration: SQL with a comment header. Without a
+// RollbackSQL, the options are written commented out, to pick one by hand: Flyway runs
+// whatever the script holds, and the options (a backup restore, a point-in-time recovery)
+// depend on steps taken before the migration.
+func writeFlywayUndo(w io.Writer, result *analyzer.Result) error {
+	table := result.Table
+	if result.Database != "" {
+		table = result.Database + "." + result.Table
+	}
+	fmt.Fprintf(w, "-- Flyway undo migration generated by dbsafe\n")
+	fmt.Fprintf(w, "-- Table: %s\n", table)
+	fmt.Fprintf(w, "-- Undoes:\n%s\n", commentLines(strings.TrimSpace(result.Statement)))
+	if result.RollbackNotes != "" {
+		fmt.Fprintf(w, "--\n%s\n", commentLines(result.RollbackNotes))
+	}
+	fmt.Fprintln(w)
+
+	if result.RollbackSQL != "" {
+		fmt.Fprintf(w, "%s\n", strings.TrimSpace(result.RollbackSQL))
+		return nil
+	}
+	fmt.Fprintf(w, "-- No automatic undo: uncomment the option that matches how the migration was prepared.\n")
+	for _, opt := range result.RollbackOptions {
+		if opt.SQL == "" {
+			continue
+		}
+		fmt.Fprintf(w, "\n-- %s: %s\n%s\n", opt.Label, opt.Description, commentLines(opt.SQL))
+	}
+	return nil
+}
+
+// commentLines prefixes every line of s with "-- ".
+func commentLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("-- "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func joinFormats(formats []RollbackFormat) string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,178 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nethalo/dbsafe/internal/analyzer"
+)
+
+func (r *TextRenderer) RenderShards(plan *analyzer.ShardPlan) {
+	width := 60
+	fmt.Fprintln(r.w)
+
+	header := TitleStyle.Render("dbsafe — Shards")
+	lines := []string{
+		r.labelValue("Shards:", fmt.Sprintf("%d", len(plan.Shards))),
+		r.labelValue("Combined risk:", riskText(plan.Risk)),
+	}
+	if plan.Worst != nil {
+		lines = append(lines,
+			r.labelValue("Worst case:", plan.Worst.Name),
+			r.labelValue("Duration:", shardDurations(plan)),
+		)
+	}
+	lines = append(lines, "")
+	for _, s := range plan.Shards {
+		if s.Result == nil {
+			lines = append(lines, fmt.Sprintf("%s: %s", s.Name, DangerText.Render("not analyzed")))
+			continue
+		}
+		c := s.Result.Classification
+		lines = append(lines,
+			fmt.Sprintf("%s: %s", CodeStyle.Render(s.Name), shardSummary(&s)),
+			fmt.Sprintf("   %s, LOCK=%s — %s", r.colorAlgorithm(c.Algorithm), c.Lock, riskText(s.Result.Risk)),
+		)
+	}
+	fmt.Fprintln(r.w, BoxStyle.Width(width).Render(header+"\n"+strings.Join(lines, "\n")))
+
+	r.renderWarnings(plan.Warnings, width)
+
+	if plan.Worst != nil {
+		fmt.Fprintln(r.w)
+		fmt.Fprintln(r.w, TitleStyle.Render("Worst case: "+plan.Worst.Name))
+		r.RenderPlan(plan.Worst.Result)
+	}
+}
+
+func (r *PlainRenderer) RenderShards(plan *analyzer.ShardPlan) {
+	fmt.Fprintf(r.w, "=== dbsafe — Shards ===\n\n")
+	fmt.Fprintf(r.w, "Shards:        %d\n", len(plan.Shards))
+	fmt.Fprintf(r.w, "Combined risk: %s\n", plan.Risk)
+	if plan.Worst != nil {
+		fmt.Fprintf(r.w, "Worst case:    %s\n", plan.Worst.Name)
+		fmt.Fprintf(r.w, "Duration:      %s\n", shardDurations(plan))
+	}
+	fmt.Fprintln(r.w)
+	for _, s := range plan.Shards {
+		if s.Result == nil {
+			fmt.Fprintf(r.w, "%s: not analyzed\n", s.Name)
+			continue
+		}
+		c := s.Result.Classification
+		fmt.Fprintf(r.w, "%s: %s\n   %s, LOCK=%s — %s\n", s.Name, shardSummary(&s), c.Algorithm, c.Lock, s.Result.Risk)
+	}
+	fmt.Fprintln(r.w)
+
+	for _, w := range plan.Warnings {
+		fmt.Fprintf(r.w, "WARNING: %s\n", w.Message)
+	}
+	if len(plan.Warnings) > 0 {
+		fmt.Fprintln(r.w)
+	}
+
+	if plan.Worst != nil {
+		fmt.Fprintf(r.w, "### Worst case: %s ###\n\n", plan.Worst.Name)
+		r.RenderPlan(plan.Worst.Result)
+	}
+}
+
+func (r *MarkdownRenderer) RenderShards(plan *analyzer.ShardPlan) {
+	fmt.Fprintf(r.w, "# dbsafe — Shards\n\n")
+	fmt.Fprintf(r.w, "**Combined risk:** %s %s  \n", riskEmoji[plan.Risk], plan.Risk)
+	if plan.Worst != nil {
+		fmt.Fprintf(r.w, "**Worst case:** `%s`  \n", plan.Worst.Name)
+		fmt.Fprintf(r.w, "**Duration:** %s\n", shardDurations(plan))
+	}
+	fmt.Fprintf(r.w, "\n| Shard | Size | Algorithm | Lock | Method | Estimate | Risk |\n|---|---|---|---|---|---|---|\n")
+	for _, s := range plan.Shards {
+		if s.Result == nil {
+			fmt.Fprintf(r.w, "| `%s` | | | | | | not analyzed |\n", s.Name)
+			continue
+		}
+		c := s.Result.Classification
+		fmt.Fprintf(r.w, "| `%s` | %s | %s | %s | %s | %s | %s |\n", s.Name, humanBytes(s.Size()), c.Algorithm, c.Lock,
+			s.Result.Method, formatEstimate(analyzer.EstimateDuration(s.Result)), s.Result.Risk)
+	}
+	fmt.Fprintln(r.w)
+
+	for _, w := range plan.Warnings {
+		fmt.Fprintf(r.w, "> ⚠️ %s\n\n", w.Message)
+	}
+
+	if plan.Worst != nil {
+		fmt.Fprintf(r.w, "---\n\n")
+		r.RenderPlan(plan.Worst.Result)
+	}
+}
+
+type jsonShardsOutput struct {
+	Risk           string        `json:"risk"`
+	WorstShard     string        `json:"worst_shard,omitempty"`
+	LongestSeconds int64         `json:"longest_seconds"`
+	TotalSeconds   int64         `json:"total_seconds"`
+	Warnings       []string      `json:"warnings,omitempty"`
+	WarningDetails []jsonWarning `json:"warning_details,omitempty"`
+	Shards         []jsonShard   `json:"shards"`
+}
+
+type jsonShard struct {
+	Name            string          `json:"name"`
+	SizeBytes       int64           `json:"size_bytes"`
+	EstimateSeconds int64           `json:"estimate_seconds"`
+	Error           string          `json:"error,omitempty"`
+	Analysis        *jsonPlanOutput `json:"analysis,omitempty"`
+}
+
+func (r *JSONRenderer) RenderShards(plan *analyzer.ShardPlan) {
+	out := jsonShardsOutput{
+		Risk:           string(plan.Risk),
+		LongestSeconds: int64(plan.Longest.Seconds()),
+		TotalSeconds:   int64(plan.Total.Seconds()),
+		Warnings:       plan.WarningMessages(),
+		WarningDetails: jsonWarnings(plan.Warnings),
+		Shards:         []jsonShard{},
+	}
+	if plan.Worst != nil {
+		out.WorstShard = plan.Worst.Name
+	}
+	for _, s := range plan.Shards {
+		shard := jsonShard{Name: s.Name, SizeBytes: s.Size()}
+		if s.Result == nil {
+			shard.Error = fmt.Sprint(s.Err)
+		} else {
+			analysis := buildJSONPlan(s.Result)
+			shard.Analysis = &analysis
+			shard.EstimateSeconds = int64(analyzer.EstimateDuration(s.Result).Seconds())
+		}
+		out.Shards = append(out.Shards, shard)
+	}
+
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(out)
+}
+
+// shardSummary is a shard's table size, recommended method and duration estimate.
+func shardSummary(s *analyzer.ShardResult) string {
+	return fmt.Sprintf("%s, %s, %s", humanBytes(s.Size()), s.Result.Method, formatEstimate(analyzer.EstimateDuration(s.Result)))
+}
+
+// shardDurations is the longest shard's estimate and the total for running them in turn.
+func shardDurations(plan *analyzer.ShardPlan) string {
+	return fmt.Sprintf("%s longest, %s for all shards one after another", formatEstimate(plan.Longest), formatEstimate(plan.Total))
+}
+
+// formatEstimate rounds a duration estimate the way the analysis messages do.
+func formatEstimate(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("~%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("~%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
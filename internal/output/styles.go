@@ -1,7 +1,12 @@
 package output
 
 import (
+	"cmp"
+	"slices"
+
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/nethalo/dbsafe/internal/analyzer"
 )
 
 // Colors
@@ -75,3 +80,59 @@ const (
 	IconDanger  = "❌"
 	IconInfo    = "ℹ"
 )
+
+// SetColor turns colored output on or off. Lip Gloss picks the color profile from stdout,
+// so colors are already off when it isn't a terminal; this forces them off for --no-color.
+func SetColor(enabled bool) {
+	if !enabled {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// ColorEnabled decides whether to color the output: not with --no-color, not when the
+// NO_COLOR environment variable is set (https://no-color.org), and not when stdout isn't
+// a terminal.
+func ColorEnabled(noColorFlag bool, noColorEnv string, stdoutIsTerminal bool) bool {
+	return !noColorFlag && noColorEnv == "" && stdoutIsTerminal
+}
+
+var severityRank = map[analyzer.Severity]int{
+	analyzer.SeverityCritical: 0,
+	analyzer.SeverityWarning:  1,
+	analyzer.SeverityInfo:     2,
+}
+
+// sortBySeverity returns the warnings ordered CRITICAL, WARNING, INFO, keeping the order
+// in which they were raised within a severity. The input is left unchanged.
+func sortBySeverity(warnings []analyzer.Warning) []analyzer.Warning {
+	sorted := slices.Clone(warnings)
+	slices.SortStableFunc(sorted, func(a, b analyzer.Warning) int {
+		return cmp.Compare(severityRank[a.Severity], severityRank[b.Severity])
+	})
+	return sorted
+}
+
+// warningBox returns the box style and title line for a warning of the given severity:
+// red for CRITICAL, yellow for WARNING and the neutral box for INFO.
+func warningBox(severity analyzer.Severity) (lipgloss.Style, string) {
+	switch severity {
+	case analyzer.SeverityCritical:
+		return DangerBoxStyle, DangerText.Render(IconDanger + " Critical")
+	case analyzer.SeverityInfo:
+		return BoxStyle, TitleStyle.Render(IconInfo + " Note")
+	}
+	return WarningBoxStyle, WarningText.Render(IconWarning + " Warning")
+}
+
+// riskText renders a risk level in its color: SAFE green, CAUTION yellow, DANGEROUS red.
+func riskText(risk analyzer.RiskLevel) string {
+	switch risk {
+	case analyzer.RiskSafe:
+		return SafeText.Render(string(risk))
+	case analyzer.RiskCaution:
+		return WarningText.Render(string(risk))
+	case analyzer.RiskDangerous:
+		return DangerText.Render(string(risk))
+	}
+	return string(risk)
+}
@@ -33,6 +33,12 @@ func (r *TextRenderer) RenderPlan(result *analyzer.Result) {
 		r.labelValue("Triggers:", formatTriggers(result.TableMeta.Triggers)),
 		r.labelValue("Engine:", result.TableMeta.Engine),
 	}
+	if result.TableMeta.RowFormat != "" {
+		metaLines = append(metaLines, r.labelValue("Row format:", result.TableMeta.RowFormat))
+	}
+	if result.TableMeta.WriteRate != nil {
+		metaLines = append(metaLines, r.labelValue("Write rate:", formatWriteRate(*result.TableMeta.WriteRate)))
+	}
 	metaBox := BoxStyle.Width(width).Render(header + "\n" + strings.Join(metaLines, "\n"))
 	fmt.Fprintln(r.w, metaBox)
 
@@ -41,6 +47,11 @@ func (r *TextRenderer) RenderPlan(result *analyzer.Result) {
 		r.renderForeignKeys(result, width)
 	}
 
+	// Existing indexes, for context before adding another one
+	if addsIndex(result) && len(result.TableMeta.Indexes) > 0 {
+		r.renderIndexes(result, width)
+	}
+
 	// Topology box (if not standalone, or if cloud-managed)
 	if result.Topology.Type != topology.Standalone || result.Topology.IsCloudManaged {
 		r.renderTopoBox(result, width)
@@ -49,20 +60,18 @@ func (r *TextRenderer) RenderPlan(result *analyzer.Result) {
 	// For unparsable DDL (OtherDDL), only show warnings - skip operation/recommendation/rollback
 	if result.DDLOp == parser.OtherDDL {
 		// Warnings
-		if len(result.Warnings) > 0 {
-			for _, w := range result.Warnings {
-				warnBox := WarningBoxStyle.Width(width).Render(
-					WarningText.Render(IconWarning+" Warning") + "\n" + w,
-				)
-				fmt.Fprintln(r.w, warnBox)
-			}
-		}
+		r.renderWarnings(result.Warnings, width)
 		return // Skip operation, recommendation, and rollback sections
 	}
 
 	// Operation box
 	r.renderOperationBox(result, width)
 
+	// Classification decisions (--trace)
+	if len(result.Trace) > 0 {
+		r.renderTrace(result, width)
+	}
+
 	// Suggested DDL with ALGORITHM/LOCK hints (INSTANT/INPLACE ALTER TABLE only)
 	if result.OptimizedDDL != "" {
 		r.renderOptimizedDDL(result, width)
@@ -73,15 +82,8 @@ func (r *TextRenderer) RenderPlan(result *analyzer.Result) {
 		r.renderClusterWarnings(result, width)
 	}
 
-	// Warnings
-	if len(result.Warnings) > 0 {
-		for _, w := range result.Warnings {
-			warnBox := WarningBoxStyle.Width(width).Render(
-				WarningText.Render(IconWarning+" Warning") + "\n" + w,
-			)
-			fmt.Fprintln(r.w, warnBox)
-		}
-	}
+	// Warnings, most severe first
+	r.renderWarnings(result.Warnings, width)
 
 	// Recommendation box
 	r.renderRecommendation(result, width)
@@ -99,6 +101,11 @@ func (r *TextRenderer) RenderPlan(result *analyzer.Result) {
 		r.renderIdempotentSP(result, width)
 	}
 
+	// Projected CREATE TABLE
+	if result.ProjectedSchema != nil {
+		r.renderProjectedSchema(result, width)
+	}
+
 	// Script generated note
 	if result.GeneratedScript != "" {
 		note := MutedText.Render(fmt.Sprintf("Chunked script written to: %s", result.ScriptPath))
@@ -153,12 +160,20 @@ func (r *TextRenderer) renderOperationBox(result *analyzer.Result, width int) {
 			}
 			lines = append(lines, r.labelValue("Sub-ops:", strings.Join(parts, ", ")))
 		}
-		lines = append(lines, r.labelValue("Algorithm:", r.colorAlgorithm(result.Classification.Algorithm)))
-		lines = append(lines, r.labelValue("Lock:", string(result.Classification.Lock)))
-		lines = append(lines, r.labelValue("Rebuilds table:", fmt.Sprintf("%v", result.Classification.RebuildsTable)))
+		if cols := formatColumnDelta(result.ColumnsBefore, result.ColumnsAfter); cols != "" {
+			lines = append(lines, r.labelValue("Columns:", cols))
+		}
+		if result.Classification.Algorithm != "" { // none for account management
+			lines = append(lines, r.labelValue("Algorithm:", r.colorAlgorithm(result.Classification.Algorithm)))
+			lines = append(lines, r.labelValue("Lock:", string(result.Classification.Lock)))
+			lines = append(lines, r.labelValue("Rebuilds table:", fmt.Sprintf("%v", result.Classification.RebuildsTable)))
+		}
 	} else {
 		lines = append(lines, r.labelValue("Type:", string(result.DMLOp)))
 		lines = append(lines, r.labelValue("Affected rows:", fmt.Sprintf("~%s (%.1f%%)", formatNumber(result.AffectedRows), result.AffectedPct)))
+		if result.CascadeRows > 0 {
+			lines = append(lines, r.labelValue("Cascaded rows:", "~"+formatNumber(result.CascadeRows)+" in child tables"))
+		}
 		if result.WriteSetSize > 0 {
 			lines = append(lines, r.labelValue("Write-set est:", humanBytes(result.WriteSetSize)))
 		}
@@ -172,12 +187,20 @@ func (r *TextRenderer) renderOperationBox(result *analyzer.Result, width int) {
 	fmt.Fprintln(r.w, opBox)
 }
 
+// renderWarnings writes one box per warning, most severe first, colored by severity.
+func (r *TextRenderer) renderWarnings(warnings []analyzer.Warning, width int) {
+	for _, w := range sortBySeverity(warnings) {
+		style, title := warningBox(w.Severity)
+		fmt.Fprintln(r.w, style.Width(width).Render(title+"\n"+w.Message))
+	}
+}
+
 func (r *TextRenderer) renderClusterWarnings(result *analyzer.Result, width int) {
 	var content strings.Builder
 	content.WriteString(WarningText.Render(IconWarning + " Cluster Warning"))
 	content.WriteString("\n")
 	for _, w := range result.ClusterWarnings {
-		content.WriteString("\n" + w)
+		content.WriteString("\n" + w.Message)
 	}
 	warnBox := WarningBoxStyle.Width(width).Render(content.String())
 	fmt.Fprintln(r.w, warnBox)
@@ -211,6 +234,12 @@ func (r *TextRenderer) renderRecommendation(result *analyzer.Result, width int)
 			MutedText.Render(result.DiskEstimate.Reason),
 		)
 	}
+	if result.EstimatedBinlogBytes > 0 {
+		diskLine += fmt.Sprintf("\n\n%s ~%s", WarningText.Render("Binlog volume:"), humanBytes(result.EstimatedBinlogBytes))
+	}
+	if result.Schedule != nil {
+		diskLine += fmt.Sprintf("\n\n%s %s", WarningText.Render("Schedule:"), result.Schedule.Recommendation)
+	}
 	content := fmt.Sprintf("%s\n%s %s\n\n%s%s\n\nMethod: %s", title, icon, label, result.Recommendation, diskLine, result.Method)
 	recBox := style.Width(width).Render(content)
 	fmt.Fprintln(r.w, recBox)
@@ -279,12 +308,22 @@ func (r *TextRenderer) renderRollback(result *analyzer.Result, width int) {
 
 func (r *TextRenderer) renderOptimizedDDL(result *analyzer.Result, width int) {
 	title := TitleStyle.Render("Suggested DDL")
-	note := MutedText.Render("Ready to run with explicit ALGORITHM and LOCK hints:")
+	note := MutedText.Render("Ready to run with explicit ALGORITHM and LOCK hints; the server rejects it rather than fall back to a slower algorithm or a stronger lock:")
 	content := title + "\n" + note + "\n\n" + CodeStyle.Render(result.OptimizedDDL)
 	box := BoxStyle.Width(width).Render(content)
 	fmt.Fprintln(r.w, box)
 }
 
+func (r *TextRenderer) renderTrace(result *analyzer.Result, width int) {
+	title := TitleStyle.Render("Decision Trace")
+	lines := make([]string, len(result.Trace))
+	for i, step := range result.Trace {
+		lines[i] = fmt.Sprintf("%d. %s", i+1, formatTraceStep(step))
+	}
+	box := BoxStyle.Width(width).Render(title + "\n" + strings.Join(lines, "\n"))
+	fmt.Fprintln(r.w, box)
+}
+
 func (r *TextRenderer) renderIdempotentSP(result *analyzer.Result, width int) {
 	title := TitleStyle.Render("Idempotent Procedure")
 	note := MutedText.Render("Run this instead of the raw DDL to make it safe to re-execute:")
@@ -293,6 +332,23 @@ func (r *TextRenderer) renderIdempotentSP(result *analyzer.Result, width int) {
 	fmt.Fprintln(r.w, box)
 }
 
+func (r *TextRenderer) renderProjectedSchema(result *analyzer.Result, width int) {
+	title := TitleStyle.Render("Projected Schema")
+	note := MutedText.Render("The table after the ALTER (+ added, - removed):")
+	lines := result.ProjectedSchema.Lines()
+	for i, line := range lines {
+		switch line[0] {
+		case '+':
+			lines[i] = SafeText.Render(line)
+		case '-':
+			lines[i] = DangerText.Render(line)
+		}
+	}
+	content := title + "\n" + note + "\n\n" + strings.Join(lines, "\n")
+	box := BoxStyle.Width(width).Render(content)
+	fmt.Fprintln(r.w, box)
+}
+
 func (r *TextRenderer) RenderTopology(conn mysql.ConnectionConfig, topo *topology.Info) {
 	width := 60
 	fmt.Fprintln(r.w)
@@ -388,6 +444,14 @@ func formatTopoType(topo *topology.Info) string {
 		return "Aurora MySQL (Writer)"
 	case topology.AuroraReader:
 		return "Aurora MySQL (Reader)"
+	case topology.Proxied:
+		switch topo.Proxy {
+		case "proxysql":
+			return "Proxied (ProxySQL)"
+		case "vitess":
+			return "Proxied (Vitess vtgate)"
+		}
+		return "Proxied"
 	default:
 		if topo.IsCloudManaged {
 			return fmt.Sprintf("Standalone (%s)", topo.CloudProvider)
@@ -444,6 +508,83 @@ func (r *TextRenderer) renderForeignKeys(result *analyzer.Result, width int) {
 	fmt.Fprintln(r.w, fkBox)
 }
 
+func (r *TextRenderer) renderIndexes(result *analyzer.Result, width int) {
+	var lines []string
+	for _, idx := range result.TableMeta.Indexes {
+		lines = append(lines, "  "+formatIndex(idx))
+	}
+	title := TitleStyle.Render("Existing Indexes") + " " + MutedText.Render(fmt.Sprintf("(%d)", len(result.TableMeta.Indexes)))
+	box := BoxStyle.Width(width).Render(title + "\n" + strings.Join(lines, "\n"))
+	fmt.Fprintln(r.w, box)
+}
+
+// addsIndex reports whether the statement adds an index (or primary key), alone or in a
+// multi-op ALTER, so the table's existing indexes are worth showing.
+func addsIndex(result *analyzer.Result) bool {
+	isAdd := func(op parser.DDLOperation) bool {
+		switch op {
+		case parser.AddIndex, parser.AddFulltextIndex, parser.AddSpatialIndex, parser.AddPrimaryKey:
+			return true
+		}
+		return false
+	}
+	if isAdd(result.DDLOp) {
+		return true
+	}
+	for _, sub := range result.SubOpResults {
+		if isAdd(sub.Op) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatIndex renders an index as "idx_name_created (name(20), created_at) BTREE", followed
+// by UNIQUE for unique secondary indexes and INVISIBLE for invisible ones.
+func formatIndex(idx mysql.IndexInfo) string {
+	cols := make([]string, len(idx.Columns))
+	for i, col := range idx.Columns {
+		cols[i] = col
+		if i < len(idx.SubParts) && idx.SubParts[i] > 0 {
+			cols[i] = fmt.Sprintf("%s(%d)", col, idx.SubParts[i])
+		}
+	}
+	s := fmt.Sprintf("%s (%s)", idx.Name, strings.Join(cols, ", "))
+	if idx.Type != "" {
+		s += " " + idx.Type
+	}
+	if !idx.NonUnique && idx.Name != "PRIMARY" {
+		s += " UNIQUE"
+	}
+	if idx.Invisible {
+		s += " INVISIBLE"
+	}
+	return s
+}
+
+// formatColumnDelta renders a multi-op ALTER's net column change as "42 → 47 (+5)".
+// Returns "" when the count is unknown or unchanged.
+func formatColumnDelta(before, after int) string {
+	if before == 0 || before == after {
+		return ""
+	}
+	return fmt.Sprintf("%d → %d (%+d)", before, after, after-before)
+}
+
+// formatTraceStep renders a decision trace step as
+// "matrix baseline: ADD_COLUMN on 8.0.35 (8.0.29+) → INSTANT / NONE, no rebuild".
+func formatTraceStep(step analyzer.TraceStep) string {
+	rebuild := "no rebuild"
+	if step.Classification.RebuildsTable {
+		rebuild = "rebuilds"
+	}
+	s := fmt.Sprintf("%s → %s / %s, %s", step.Step, step.Classification.Algorithm, step.Classification.Lock, rebuild)
+	if step.Detail != "" {
+		s += ": " + step.Detail
+	}
+	return s
+}
+
 func formatTriggers(triggers []mysql.TriggerInfo) string {
 	if len(triggers) == 0 {
 		return "None"
@@ -455,6 +596,14 @@ func formatTriggers(triggers []mysql.TriggerInfo) string {
 	return fmt.Sprintf("%d (%s)", len(triggers), strings.Join(names, ", "))
 }
 
+// formatWriteRate formats a sampled write rate, keeping a decimal for a nearly idle table.
+func formatWriteRate(rate float64) string {
+	if rate < 10 {
+		return fmt.Sprintf("~%.1f rows/s", rate)
+	}
+	return fmt.Sprintf("~%s rows/s", formatNumber(int64(rate)))
+}
+
 func formatNumber(n int64) string {
 	if n >= 1_000_000_000 {
 		return fmt.Sprintf("%.0f,000,000,000+", float64(n)/1_000_000_000)
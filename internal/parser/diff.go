@@ -0,0 +1,225 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/vt/schemadiff"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
+)
+
+// diffHints keeps the generated ALTERs close to what a person would write: constraint
+// names are compared as declared, a missing table charset/collation is not a difference,
+// and the AUTO_INCREMENT counter of a SHOW CREATE TABLE dump is ignored.
+var diffHints = &schemadiff.DiffHints{
+	ConstraintNamesStrategy:     schemadiff.ConstraintNamesStrict,
+	TableCharsetCollateStrategy: schemadiff.TableCharsetCollateIgnoreEmpty,
+	AutoIncrementStrategy:       schemadiff.AutoIncrementIgnore,
+}
+
+// DiffCreateTables compares two CREATE TABLE statements for the same table and returns the
+// ALTER TABLE statements that turn current into desired, in the order they should run.
+// Each change gets its own statement so it can be classified on its own, except for
+// changes MySQL only accepts together (replacing the primary key, adding an
+// AUTO_INCREMENT column along with its key). Returns no statements when the definitions
+// are equivalent.
+func DiffCreateTables(current, desired string) ([]string, error) {
+	from, err := parseCreateTable(current)
+	if err != nil {
+		return nil, fmt.Errorf("current definition: %w", err)
+	}
+	to, err := parseCreateTable(desired)
+	if err != nil {
+		return nil, fmt.Errorf("desired definition: %w", err)
+	}
+	if !strings.EqualFold(from.Table.Name.String(), to.Table.Name.String()) {
+		return nil, fmt.Errorf("definitions are for different tables (%s and %s); renames are not diffed",
+			from.Table.Name.String(), to.Table.Name.String())
+	}
+	// Diff the unqualified definitions; the generated ALTERs keep the current qualifier.
+	table := from.Table
+	from.Table.Qualifier, to.Table.Qualifier = sqlparser.IdentifierCS{}, sqlparser.IdentifierCS{}
+
+	env, err := newDiffEnv()
+	if err != nil {
+		return nil, err
+	}
+	diff, err := schemadiff.DiffTables(env, from, to, diffHints)
+	if err != nil {
+		return nil, fmt.Errorf("diffing definitions: %w", err)
+	}
+
+	var statements []string
+	for d := diff; d != nil && !d.IsEmpty(); d = d.SubsequentDiff() {
+		alter, ok := d.Statement().(*sqlparser.AlterTable)
+		if !ok {
+			return nil, fmt.Errorf("unexpected diff statement: %s", d.CanonicalStatementString())
+		}
+		for _, group := range groupAlterOptions(alter.AlterOptions) {
+			statements = append(statements, sqlparser.CanonicalString(&sqlparser.AlterTable{Table: table, AlterOptions: group}))
+		}
+		if alter.PartitionSpec != nil {
+			statements = append(statements, sqlparser.CanonicalString(&sqlparser.AlterTable{Table: table, PartitionSpec: alter.PartitionSpec}))
+		}
+	}
+	return statements, nil
+}
+
+// ProjectAlter applies an ALTER TABLE to the table a CREATE TABLE statement defines (e.g.
+// SHOW CREATE TABLE output) and returns both definitions, normalized the same way so they
+// can be compared line by line: the table as it is, and as the ALTER leaves it. ALGORITHM,
+// LOCK, FORCE and WAIT/NOWAIT are ignored, as they change how the ALTER runs and not its
+// result. It fails when the ALTER doesn't apply, e.g. it drops a column the table lacks.
+func ProjectAlter(createSQL, alterSQL string) (before, after string, err error) {
+	create, err := parseCreateTable(createSQL)
+	if err != nil {
+		return "", "", fmt.Errorf("current definition: %w", err)
+	}
+	p, err := getParser()
+	if err != nil {
+		return "", "", fmt.Errorf("creating parser: %w", err)
+	}
+	alterSQL = strings.TrimRight(strings.TrimSpace(alterSQL), ";")
+	if m := reAlterLockWait.FindStringSubmatch(alterSQL); m != nil {
+		alterSQL = m[1] + " " + alterSQL[len(m[0]):]
+	}
+	stmt, err := p.ParseStrictDDL(alterSQL)
+	if err != nil {
+		return "", "", fmt.Errorf("parsing ALTER: %w", err)
+	}
+	alter, ok := stmt.(*sqlparser.AlterTable)
+	if !ok {
+		return "", "", fmt.Errorf("not an ALTER TABLE statement")
+	}
+	if !strings.EqualFold(alter.Table.Name.String(), create.Table.Name.String()) {
+		return "", "", fmt.Errorf("the ALTER is for %s, the definition for %s", alter.Table.Name.String(), create.Table.Name.String())
+	}
+
+	options := alter.AlterOptions[:0:0]
+	for _, opt := range alter.AlterOptions {
+		switch opt.(type) {
+		case sqlparser.AlgorithmValue, *sqlparser.LockOption, *sqlparser.Force:
+		default:
+			options = append(options, opt)
+		}
+	}
+	alter = &sqlparser.AlterTable{Table: create.Table, AlterOptions: options, PartitionSpec: alter.PartitionSpec, PartitionOption: alter.PartitionOption}
+
+	env, err := newDiffEnv()
+	if err != nil {
+		return "", "", err
+	}
+	current, err := schemadiff.NewCreateTableEntity(env, create)
+	if err != nil {
+		return "", "", fmt.Errorf("current definition: %w", err)
+	}
+	projected, err := current.Apply(schemadiff.EntityDiffByStatement(alter))
+	if err != nil {
+		return "", "", err
+	}
+	return current.Create().CanonicalStatementString(), projected.Create().CanonicalStatementString(), nil
+}
+
+func newDiffEnv() (*schemadiff.Environment, error) {
+	vtEnv, err := vtenv.New(vtenv.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("creating diff environment: %w", err)
+	}
+	return schemadiff.NewEnv(vtEnv, collations.MySQL8().DefaultConnectionCharset()), nil
+}
+
+func parseCreateTable(sql string) (*sqlparser.CreateTable, error) {
+	p, err := getParser()
+	if err != nil {
+		return nil, fmt.Errorf("creating parser: %w", err)
+	}
+	stmt, err := p.ParseStrictDDL(strings.TrimRight(strings.TrimSpace(sql), ";"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing SQL: %w", err)
+	}
+	create, ok := stmt.(*sqlparser.CreateTable)
+	if !ok || create.TableSpec == nil {
+		return nil, fmt.Errorf("not a CREATE TABLE statement with column definitions")
+	}
+	return create, nil
+}
+
+// groupAlterOptions splits ALTER options into one group per statement. DROP PRIMARY KEY
+// stays with the ADD PRIMARY KEY replacing it (a table without a primary key in between
+// breaks replication and online schema change tools), and an AUTO_INCREMENT column stays
+// with the key that indexes it (MySQL rejects an AUTO_INCREMENT column without one).
+func groupAlterOptions(opts []sqlparser.AlterOption) [][]sqlparser.AlterOption {
+	together := make([]bool, len(opts))
+
+	pkDrop, pkAdd := -1, -1
+	for i, opt := range opts {
+		switch o := opt.(type) {
+		case *sqlparser.DropKey:
+			if o.Type == sqlparser.PrimaryKeyType {
+				pkDrop = i
+			}
+		case *sqlparser.AddIndexDefinition:
+			if o.IndexDefinition.Info.Type == sqlparser.IndexTypePrimary {
+				pkAdd = i
+			}
+		}
+	}
+	if pkDrop >= 0 && pkAdd >= 0 {
+		together[pkDrop], together[pkAdd] = true, true
+	}
+
+	for i, opt := range opts {
+		col := autoIncrementColumn(opt)
+		if col == "" {
+			continue
+		}
+		for j, other := range opts {
+			if add, ok := other.(*sqlparser.AddIndexDefinition); ok &&
+				len(add.IndexDefinition.Columns) > 0 &&
+				add.IndexDefinition.Columns[0].Column.EqualString(col) {
+				together[i], together[j] = true, true
+			}
+		}
+	}
+
+	var groups [][]sqlparser.AlterOption
+	var combined []sqlparser.AlterOption
+	combinedAt := -1
+	for i, opt := range opts {
+		if !together[i] {
+			groups = append(groups, []sqlparser.AlterOption{opt})
+			continue
+		}
+		if combinedAt < 0 {
+			combinedAt = len(groups)
+			groups = append(groups, nil)
+		}
+		combined = append(combined, opt)
+	}
+	if combinedAt >= 0 {
+		groups[combinedAt] = combined
+	}
+	return groups
+}
+
+// autoIncrementColumn returns the name of the AUTO_INCREMENT column an ADD/MODIFY/CHANGE
+// COLUMN option defines, or "".
+func autoIncrementColumn(opt sqlparser.AlterOption) string {
+	var col *sqlparser.ColumnDefinition
+	switch o := opt.(type) {
+	case *sqlparser.AddColumns:
+		if len(o.Columns) == 1 {
+			col = o.Columns[0]
+		}
+	case *sqlparser.ModifyColumn:
+		col = o.NewColDefinition
+	case *sqlparser.ChangeColumn:
+		col = o.NewColDefinition
+	}
+	if col == nil || col.Type.Options == nil || !col.Type.Options.Autoincrement {
+		return ""
+	}
+	return col.Name.String()
+}
@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffCreateTables(t *testing.T) {
+	current := "CREATE TABLE shop.users (id INT PRIMARY KEY, name VARCHAR(50), legacy INT, KEY idx_name (name)) ENGINE=InnoDB AUTO_INCREMENT=42"
+	desired := "CREATE TABLE users (id INT PRIMARY KEY, name VARCHAR(100), email VARCHAR(255) NOT NULL, UNIQUE KEY uk_email (email)) ENGINE=InnoDB"
+
+	got, err := DiffCreateTables(current, desired)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"ALTER TABLE `shop`.`users` DROP KEY `idx_name`",
+		"ALTER TABLE `shop`.`users` DROP COLUMN `legacy`",
+		"ALTER TABLE `shop`.`users` MODIFY COLUMN `name` varchar(100)",
+		"ALTER TABLE `shop`.`users` ADD COLUMN `email` varchar(255) NOT NULL",
+		"ALTER TABLE `shop`.`users` ADD UNIQUE KEY `uk_email` (`email`)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("DiffCreateTables() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Every generated statement must be classifiable as a single operation.
+	for _, stmt := range got {
+		parsed, err := Parse(stmt)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", stmt, err)
+		}
+		if parsed.DDLOp == MultipleOps || parsed.DDLOp == OtherDDL {
+			t.Errorf("Parse(%q).DDLOp = %q, want a single operation", stmt, parsed.DDLOp)
+		}
+		if parsed.Database != "shop" || parsed.Table != "users" {
+			t.Errorf("Parse(%q) table = %s.%s, want shop.users", stmt, parsed.Database, parsed.Table)
+		}
+	}
+}
+
+func TestDiffCreateTables_KeepsDependentChangesTogether(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		desired string
+		want    []string
+	}{
+		{
+			name:    "primary key replacement",
+			current: "CREATE TABLE t (id INT NOT NULL, x INT NOT NULL, PRIMARY KEY (id))",
+			desired: "CREATE TABLE t (id INT NOT NULL, x INT NOT NULL, PRIMARY KEY (id, x))",
+			want:    []string{"ALTER TABLE `t` DROP PRIMARY KEY, ADD PRIMARY KEY (`id`, `x`)"},
+		},
+		{
+			name:    "auto_increment column with its key",
+			current: "CREATE TABLE t (x INT)",
+			desired: "CREATE TABLE t (x INT, seq BIGINT NOT NULL AUTO_INCREMENT, PRIMARY KEY (seq), KEY idx_x (x))",
+			want: []string{
+				"ALTER TABLE `t` ADD COLUMN `seq` bigint NOT NULL AUTO_INCREMENT, ADD PRIMARY KEY (`seq`)",
+				"ALTER TABLE `t` ADD KEY `idx_x` (`x`)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffCreateTables(tt.current, tt.desired)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("DiffCreateTables() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestDiffCreateTables_Equivalent(t *testing.T) {
+	got, err := DiffCreateTables(
+		"CREATE TABLE t (id INT PRIMARY KEY) ENGINE=InnoDB AUTO_INCREMENT=100;",
+		"create table t (id int primary key) engine=InnoDB",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("equivalent definitions should produce no statements, got %v", got)
+	}
+}
+
+func TestDiffCreateTables_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		desired string
+		wantErr string
+	}{
+		{"different tables", "CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)", "different tables"},
+		{"not a CREATE TABLE", "ALTER TABLE a ADD COLUMN x INT", "CREATE TABLE a (id INT)", "current definition"},
+		{"syntax error", "CREATE TABLE a (id INT)", "CREATE TABLE a (id INT", "desired definition"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DiffCreateTables(tt.current, tt.desired)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProjectAlter(t *testing.T) {
+	current := "CREATE TABLE `users` (\n  `id` int NOT NULL AUTO_INCREMENT,\n  `name` varchar(50) DEFAULT NULL,\n  PRIMARY KEY (`id`),\n  KEY `idx_name` (`name`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"
+
+	before, after, err := ProjectAlter(current,
+		"ALTER TABLE shop.users NOWAIT ADD COLUMN email varchar(255) NOT NULL AFTER id, MODIFY name varchar(100), DROP INDEX idx_name, ALGORITHM=INPLACE, LOCK=NONE;")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(before, "`name` varchar(50)") || !strings.Contains(before, "KEY `idx_name`") {
+		t.Errorf("before =\n%s", before)
+	}
+	want := "CREATE TABLE `users` (\n\t`id` int NOT NULL AUTO_INCREMENT,\n\t`email` varchar(255) NOT NULL,\n\t`name` varchar(100),\n\tPRIMARY KEY (`id`)\n)"
+	if !strings.HasPrefix(after, want) {
+		t.Errorf("after =\n%s\nwant it to start with\n%s", after, want)
+	}
+
+	for _, tt := range []struct {
+		alter   string
+		wantErr string
+	}{
+		{"ALTER TABLE users DROP COLUMN nope", "`nope` not found"},
+		{"ALTER TABLE orders ADD COLUMN x int", "the ALTER is for orders"},
+		{"ALTER TABLE users RENAME TO people", "unsupported"},
+	} {
+		if _, _, err := ProjectAlter(current, tt.alter); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ProjectAlter(%q) error = %v, want it to contain %q", tt.alter, err, tt.wantErr)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package parser
 import (
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -13,8 +15,43 @@ import (
 var (
 	// OPTIMIZE TABLE [NO_WRITE_TO_BINLOG|LOCAL] <tbl>
 	reOptimizeTable = regexp.MustCompile(`(?i)^OPTIMIZE\s+(?:NO_WRITE_TO_BINLOG\s+|LOCAL\s+)?TABLE\s+(\S+)`)
+	// ANALYZE [NO_WRITE_TO_BINLOG|LOCAL] TABLE <tbl>[, ...] [UPDATE|DROP HISTOGRAM ...]
+	reAnalyzeTable = regexp.MustCompile(`(?i)^ANALYZE\s+(?:NO_WRITE_TO_BINLOG\s+|LOCAL\s+)?TABLE\s+([^\s,]+)`)
+	// CHECK TABLE <tbl>[, ...] [options]
+	reCheckTable = regexp.MustCompile(`(?i)^CHECK\s+TABLE\s+([^\s,]+)`)
 	// ALTER TABLESPACE <name> RENAME TO <new_name>
 	reAlterTablespace = regexp.MustCompile(`(?i)^ALTER\s+TABLESPACE\s+(\S+)\s+RENAME\s+TO\s+(\S+)`)
+	// CREATE TABLE <tbl> [(...)] [IGNORE|REPLACE] [AS] [(]SELECT ... — Vitess only partially
+	// parses this and drops the SELECT. Matches every SELECT keyword, for createTableSelect
+	// to pick the one the query starts at.
+	reCreateTableSelect = regexp.MustCompile(`(?i)\bSELECT\b`)
+	// CREATE/ALTER/DROP of a view, stored routine, trigger or event — Vitess can't parse the
+	// routine, trigger and event forms and has no table to analyze for any of them.
+	reObjectDefinition = regexp.MustCompile(`(?is)^(?:CREATE|ALTER|DROP)\s+(?:OR\s+REPLACE\s+)?(?:ALGORITHM\s*=\s*\w+\s+)?(?:DEFINER\s*=\s*\S+\s+)?(?:SQL\s+SECURITY\s+\w+\s+)?(VIEW|PROCEDURE|FUNCTION|TRIGGER|EVENT)\s+(?:IF\s+(?:NOT\s+)?EXISTS\s+)?([^\s(]+)`)
+	// Account management (GRANT, REVOKE, CREATE/ALTER/DROP/RENAME USER, roles, SET PASSWORD)
+	// — Vitess can't parse these, and they change no table.
+	reAccountManagement = regexp.MustCompile(`(?is)^(GRANT|REVOKE|(?:CREATE|ALTER|DROP|RENAME)\s+USER|(?:CREATE|DROP)\s+ROLE|SET\s+(?:DEFAULT\s+)?ROLE|SET\s+PASSWORD)\b`)
+	// The password literals of an account-management statement: IDENTIFIED [WITH plugin]
+	// BY|AS 'x', REPLACE 'x' (the current password) and SET PASSWORD [FOR user] = 'x'.
+	reAccountSecret = regexp.MustCompile(`(?is)(\bIDENTIFIED\s+(?:WITH\s+\S+\s+)?(?:BY|AS)\s+|\bREPLACE\s+|\bPASSWORD\s*(?:FOR\s+\S+\s*)?=\s*(?:PASSWORD\s*\(\s*)?)('(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*")`)
+	// ALTER TABLE <tbl> ORDER BY <cols> — Vitess returns no AlterOptions for it.
+	reAlterOrderBy = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+\S+\s+ORDER\s+BY\s`)
+	// ALTER TABLE ... SECONDARY_LOAD / SECONDARY_UNLOAD / SECONDARY_ENGINE = x as the whole
+	// statement — Vitess accepts them but drops the clause.
+	reAlterSecondary = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+\S+\s+(SECONDARY_LOAD|SECONDARY_UNLOAD|SECONDARY_ENGINE)\b(?:\s*=?\s*\S+?)?\s*;?\s*$`)
+	// ADD [COLUMN] IF NOT EXISTS / DROP [COLUMN] IF EXISTS in an ALTER TABLE — Vitess only
+	// partially parses the modifier, so it is stripped before parsing.
+	reColumnIfExists = regexp.MustCompile(`(?i)\b(ADD|DROP)(\s+COLUMN)?\s+IF\s+(?:NOT\s+)?EXISTS\b`)
+	// ALTER TABLE <tbl> WAIT n | NOWAIT — MariaDB's metadata lock timeout, which Vitess
+	// doesn't parse, so it is stripped before parsing.
+	reAlterLockWait = regexp.MustCompile(`(?is)^(ALTER\s+TABLE\s+\S+)\s+(NOWAIT|WAIT\s+(\d+))\s`)
+	// SERIAL, which Vitess doesn't parse, so it is expanded before parsing: the type of an
+	// ADD/MODIFY/CHANGE COLUMN, and the SERIAL DEFAULT VALUE attribute of an integer column.
+	reSerialType    = regexp.MustCompile("(?i)(\\b(?:ADD|MODIFY)(?:\\s+COLUMN)?\\s+(?:`[^`]+`|\\w+)|\\bCHANGE(?:\\s+COLUMN)?\\s+(?:`[^`]+`|\\w+)\\s+(?:`[^`]+`|\\w+))\\s+SERIAL\\b")
+	reSerialDefault = regexp.MustCompile(`(?i)\bSERIAL\s+DEFAULT\s+VALUE\b`)
+	// A backquoted identifier, or a bare one followed by the "(" that makes it a function
+	// name — the fallback column scan of ExpressionColumns.
+	reExprIdentifier = regexp.MustCompile("`([^`]+)`|\\b([A-Za-z_][A-Za-z0-9_$]*)\\b\\s*(\\()?")
 )
 
 // StatementType classifies the SQL statement.
@@ -57,8 +94,11 @@ const (
 	AddSpatialIndex     DDLOperation = "ADD_SPATIAL_INDEX"
 	ChangeAutoIncrement DDLOperation = "CHANGE_AUTO_INCREMENT"
 	ForceRebuild        DDLOperation = "FORCE_REBUILD"
+	OrderBy             DDLOperation = "ORDER_BY" // ALTER TABLE ... ORDER BY <col> (physical reorder, COPY)
 	MultipleOps         DDLOperation = "MULTIPLE_OPS"
 	CreateTable         DDLOperation = "CREATE_TABLE"
+	CreateTableLike     DDLOperation = "CREATE_TABLE_LIKE"      // CREATE TABLE ... LIKE <src> (metadata-only)
+	CreateTableAsSelect DDLOperation = "CREATE_TABLE_AS_SELECT" // CREATE TABLE ... [AS] SELECT (copies rows)
 	AddCheckConstraint  DDLOperation = "ADD_CHECK_CONSTRAINT"
 	OtherDDL            DDLOperation = "OTHER"
 
@@ -67,13 +107,32 @@ const (
 	StatsOption     DDLOperation = "STATS_OPTION"
 	TableEncryption DDLOperation = "TABLE_ENCRYPTION"
 
+	// Index visibility (metadata-only)
+	ChangeIndexVisibility DDLOperation = "CHANGE_INDEX_VISIBILITY" // ALTER INDEX <idx> VISIBLE | INVISIBLE
+
+	// Engine attributes and secondary engines (HeatWave)
+	EngineAttribute DDLOperation = "ENGINE_ATTRIBUTE" // ALTER TABLE ... [SECONDARY_]ENGINE_ATTRIBUTE = '<json>'
+	SecondaryEngine DDLOperation = "SECONDARY_ENGINE" // ALTER TABLE ... SECONDARY_ENGINE = <engine> | NULL
+	SecondaryLoad   DDLOperation = "SECONDARY_LOAD"   // ALTER TABLE ... SECONDARY_LOAD (copy into the secondary engine)
+	SecondaryUnload DDLOperation = "SECONDARY_UNLOAD" // ALTER TABLE ... SECONDARY_UNLOAD
+
+	// Partitioning scheme changes (full table copy)
+	RemovePartitioning DDLOperation = "REMOVE_PARTITIONING" // ALTER TABLE ... REMOVE PARTITIONING
+	RepartitionTable   DDLOperation = "REPARTITION_TABLE"   // ALTER TABLE ... PARTITION BY ...
+
 	// Multi-op combined patterns
 	ChangeIndexType   DDLOperation = "CHANGE_INDEX_TYPE"   // DROP INDEX + ADD INDEX (same name)
 	ReplacePrimaryKey DDLOperation = "REPLACE_PRIMARY_KEY" // DROP PRIMARY KEY + ADD PRIMARY KEY
 
 	// Statement-level DDL operations (not ALTER TABLE sub-operations)
-	OptimizeTable   DDLOperation = "OPTIMIZE_TABLE"   // OPTIMIZE TABLE <tbl>
-	AlterTablespace DDLOperation = "ALTER_TABLESPACE" // ALTER TABLESPACE <name> RENAME TO <new>
+	OptimizeTable    DDLOperation = "OPTIMIZE_TABLE"    // OPTIMIZE TABLE <tbl>
+	AnalyzeTable     DDLOperation = "ANALYZE_TABLE"     // ANALYZE TABLE <tbl>
+	CheckTable       DDLOperation = "CHECK_TABLE"       // CHECK TABLE <tbl>
+	AlterTablespace  DDLOperation = "ALTER_TABLESPACE"  // ALTER TABLESPACE <name> RENAME TO <new>
+	ObjectDefinition DDLOperation = "OBJECT_DEFINITION" // CREATE/ALTER/DROP VIEW, PROCEDURE, FUNCTION, TRIGGER or EVENT
+
+	// Account management: no table is changed; recognized to report it as out of scope
+	AccountManagement DDLOperation = "ACCOUNT_MANAGEMENT" // GRANT, REVOKE, CREATE/ALTER/DROP/RENAME USER, roles, SET PASSWORD
 )
 
 // DMLOperation enumerates DML operations.
@@ -89,57 +148,98 @@ const (
 // SubOperation holds per-sub-operation details for a multi-op ALTER TABLE.
 // Each entry in SubOperations corresponds to one clause in the compound ALTER.
 type SubOperation struct {
-	Op                DDLOperation
-	ColumnName        string   // ADD/DROP/MODIFY/CHANGE COLUMN (new name for CHANGE)
-	OldColumnName     string   // CHANGE COLUMN original name
-	NewColumnType     string   // CHANGE/MODIFY COLUMN base type
-	NewColumnCharset  string   // MODIFY COLUMN explicit CHARACTER SET
-	NewColumnNullable *bool    // MODIFY COLUMN NULL/NOT NULL
-	IsFirstAfter      bool     // ADD/MODIFY COLUMN ... FIRST|AFTER
-	IndexName         string   // ADD/DROP INDEX, ADD FK, RENAME INDEX
-	IndexColumns      []string // ADD PRIMARY KEY / ADD INDEX columns
-	IsUniqueIndex     bool     // ADD UNIQUE KEY/INDEX
-	HasAutoIncrement  bool     // ADD COLUMN ... AUTO_INCREMENT
-	HasNotNull        bool     // ADD COLUMN ... NOT NULL
-	IsGeneratedStored bool     // ADD/MODIFY ... AS (...) STORED
-	IsGeneratedColumn bool     // ADD/MODIFY ... AS (...) expression
-	NewEngine         string   // ENGINE=<name>
-	CheckExpr         string   // ADD CONSTRAINT CHECK (expr)
+	Op                   DDLOperation
+	ColumnName           string   // ADD/DROP/MODIFY/CHANGE COLUMN (new name for CHANGE)
+	OldColumnName        string   // CHANGE COLUMN original name
+	NewColumnType        string   // ADD/CHANGE/MODIFY COLUMN base type
+	NewColumnCharset     string   // ADD/MODIFY/CHANGE COLUMN explicit CHARACTER SET
+	NewColumnSRID        string   // ADD/CHANGE/MODIFY COLUMN spatial SRID attribute
+	NewColumnNullable    *bool    // MODIFY/CHANGE COLUMN NULL/NOT NULL
+	IsFirstAfter         bool     // ADD/MODIFY/CHANGE COLUMN ... FIRST|AFTER
+	AfterColumn          string   // ADD/MODIFY/CHANGE COLUMN ... AFTER <col> anchor
+	IndexName            string   // ADD/DROP INDEX, ADD FK, RENAME INDEX, ALTER INDEX
+	IndexColumns         []string // ADD PRIMARY KEY / ADD INDEX columns
+	IsUniqueIndex        bool     // ADD UNIQUE KEY/INDEX
+	IndexInvisible       bool     // ALTER INDEX ... INVISIBLE
+	HasAutoIncrement     bool     // ADD COLUMN ... AUTO_INCREMENT
+	HasUniqueKey         bool     // ADD COLUMN ... UNIQUE [KEY] (SERIAL included)
+	HasNotNull           bool     // ADD COLUMN ... NOT NULL
+	HasExpressionDefault bool     // ADD COLUMN ... DEFAULT (expr)
+	IsGeneratedStored    bool     // ADD/MODIFY ... AS (...) STORED
+	IsGeneratedColumn    bool     // ADD/MODIFY ... AS (...) expression
+	NewGenerationExpr    string   // ADD/MODIFY ... AS (expr): the generation expression
+	NewEngine            string   // ENGINE=<name>
+	CheckExpr            string   // ADD CONSTRAINT CHECK (expr)
+	CheckNotEnforced     bool     // ADD CONSTRAINT CHECK (expr) NOT ENFORCED
 }
 
 // ParsedSQL holds the result of parsing a SQL statement.
 type ParsedSQL struct {
-	Type              StatementType
-	RawSQL            string
-	Database          string // extracted from qualified table name if present
-	Table             string
-	DDLOp             DDLOperation
-	DMLOp             DMLOperation
-	WhereClause       string // for DML: the WHERE as string
-	HasWhere          bool
-	ColumnName        string         // for ADD/DROP/MODIFY COLUMN
-	OldColumnName     string         // for CHANGE COLUMN
-	NewColumnName     string         // for CHANGE COLUMN
-	NewColumnType     string         // for CHANGE/MODIFY COLUMN: the new column type (e.g. "decimal(14,4)")
-	NewColumnCharset  string         // for MODIFY COLUMN: explicit CHARACTER SET clause if present (lowercase)
-	NewColumnNullable *bool          // for MODIFY COLUMN: nil=unspecified, *true=NULL, *false=NOT NULL
-	ColumnDef         string         // full column definition for ADD COLUMN
-	IsFirstAfter      bool           // ADD COLUMN/MODIFY COLUMN ... FIRST or AFTER
-	IndexName         string         // for ADD/DROP INDEX
-	HasNotNull        bool           // ADD COLUMN ... NOT NULL
-	HasDefault        bool           // ADD COLUMN ... DEFAULT
-	HasAutoIncrement  bool           // ADD COLUMN ... AUTO_INCREMENT
-	IsGeneratedStored bool           // ADD/MODIFY COLUMN ... AS (...) STORED
-	IsGeneratedColumn bool           // ADD/MODIFY COLUMN has an AS (...) expression (STORED or VIRTUAL)
-	SubOperations     []SubOperation // for multi-op ALTER TABLE: per-sub-op details
-	TablespaceName    string         // for ALTER TABLESPACE
-	NewTablespaceName string         // for ALTER TABLESPACE ... RENAME TO
-	IndexColumns      []string       // for ADD PRIMARY KEY / ADD INDEX: the indexed column names
-	IsUniqueIndex     bool           // true when ADD UNIQUE KEY/INDEX
-	NewEngine         string         // for ENGINE=<name>: the target engine (lowercased)
-	CheckExpr         string         // for ADD CONSTRAINT ... CHECK: the check expression
-	NewTableName      string         // for RENAME TABLE: the new table name
-	NewIndexName      string         // for RENAME INDEX: the new index name
+	Type                 StatementType
+	RawSQL               string
+	Database             string // extracted from qualified table name if present
+	Table                string
+	DDLOp                DDLOperation
+	DMLOp                DMLOperation
+	WhereClause          string // for DML: the WHERE as string
+	HasWhere             bool
+	UpdateColumns        []string       // for UPDATE: the columns assigned in SET
+	SetClause            string         // for UPDATE: the SET assignments as SQL (e.g. "n = n + 1")
+	JoinTables           []string       // for multi-table DELETE/UPDATE: every table the statement joins, as written (db.table or table)
+	TargetTables         []string       // for multi-table DELETE/UPDATE: the tables rows are deleted from or updated, as written
+	TargetRef            string         // for multi-table DELETE/UPDATE: how the statement refers to Table (its alias, or its name)
+	FromClause           string         // for multi-table DELETE/UPDATE: the table references, joins included
+	ColumnName           string         // for ADD/DROP/MODIFY COLUMN
+	OldColumnName        string         // for CHANGE COLUMN
+	NewColumnName        string         // for CHANGE COLUMN
+	NewColumnType        string         // for ADD/CHANGE/MODIFY COLUMN: the new column type (e.g. "decimal(14,4)")
+	NewColumnCharset     string         // for ADD/MODIFY/CHANGE COLUMN: explicit CHARACTER SET clause if present (lowercase)
+	NewColumnSRID        string         // for ADD/CHANGE/MODIFY COLUMN: SRID attribute of a spatial column (e.g. "4326")
+	NewColumnNullable    *bool          // for MODIFY/CHANGE COLUMN: nil=unspecified, *true=NULL, *false=NOT NULL
+	ColumnDef            string         // full column definition for ADD COLUMN
+	IsFirstAfter         bool           // ADD/MODIFY/CHANGE COLUMN ... FIRST or AFTER
+	AfterColumn          string         // ADD/MODIFY/CHANGE COLUMN ... AFTER <col>: the anchor column
+	IndexName            string         // for ADD/DROP INDEX
+	HasNotNull           bool           // ADD COLUMN ... NOT NULL
+	HasDefault           bool           // ADD COLUMN ... DEFAULT
+	HasExpressionDefault bool           // ADD COLUMN ... DEFAULT (expr): evaluated per row, not a literal
+	HasAutoIncrement     bool           // ADD COLUMN ... AUTO_INCREMENT
+	HasUniqueKey         bool           // ADD COLUMN ... UNIQUE [KEY], as SERIAL declares: the ALTER also builds a UNIQUE index
+	ColumnIfExists       bool           // ADD COLUMN IF NOT EXISTS / DROP COLUMN IF EXISTS
+	IsGeneratedStored    bool           // ADD/MODIFY COLUMN ... AS (...) STORED
+	IsGeneratedColumn    bool           // ADD/MODIFY COLUMN has an AS (...) expression (STORED or VIRTUAL)
+	NewGenerationExpr    string         // ADD/MODIFY COLUMN ... AS (expr): the generation expression
+	SubOperations        []SubOperation // for multi-op ALTER TABLE: per-sub-op details
+	TablespaceName       string         // for ALTER TABLESPACE
+	NewTablespaceName    string         // for ALTER TABLESPACE ... RENAME TO
+	IndexColumns         []string       // for ADD PRIMARY KEY / ADD INDEX: the indexed column names
+	IsUniqueIndex        bool           // true when ADD UNIQUE KEY/INDEX
+	IndexInvisible       bool           // for ALTER INDEX: true for INVISIBLE, false for VISIBLE
+	PartitionColumns     []string       // for PARTITION BY: the columns the partitioning expression uses
+	NewEngine            string         // for ENGINE=<name>: the target engine (lowercased)
+	CheckExpr            string         // for ADD CONSTRAINT ... CHECK: the check expression
+	CheckNotEnforced     bool           // for ADD CONSTRAINT ... CHECK: NOT ENFORCED (existing rows aren't validated)
+	NewTableName         string         // for RENAME TABLE: the new table name
+	RenamePairs          []RenamePair   // for RENAME TABLE: every FROM TO pair, in order
+	NewIndexName         string         // for RENAME INDEX: the new index name
+	SourceTable          string         // for CREATE TABLE ... LIKE / AS SELECT: the table copied from
+	SourceDatabase       string         // for CREATE TABLE ... LIKE / AS SELECT: the database qualifying SourceTable, "" if unqualified
+	UnionSources         []TableRef     // for CREATE TABLE ... AS SELECT ... UNION: the table each SELECT reads, in order (SourceTable is the first)
+	SourceUnknown        bool           // for CREATE TABLE ... AS SELECT: some rows come from no table to size (a derived table, or a SELECT that doesn't parse)
+	SelectSQL            string         // for CREATE TABLE ... AS SELECT: the SELECT query
+	NewCharset           string         // for CONVERT TO CHARACTER SET: the target charset (lowercase)
+	NewCollation         string         // for CONVERT TO CHARACTER SET: the COLLATE clause, "" if none (lowercase)
+	AlgorithmHint        string         // explicit ALGORITHM= clause in the ALTER (uppercase), "" if absent
+	LockHint             string         // explicit LOCK= clause in the ALTER (uppercase), "" if absent
+	ObjectType           string         // for OBJECT_DEFINITION: VIEW, PROCEDURE, FUNCTION, TRIGGER or EVENT; for ACCOUNT_MANAGEMENT: the statement, e.g. GRANT or ALTER USER
+	ObjectName           string         // for OBJECT_DEFINITION: the view, routine, trigger or event name
+	TableEngine          string         // for CREATE TABLE: ENGINE= option (lowercase), "" if absent
+	TableCharset         string         // for CREATE TABLE: table default character set (lowercase), "" if absent
+	TableRowFormat       string         // for CREATE TABLE: ROW_FORMAT= option (uppercase), "" if absent
+	HasPrimaryKey        bool           // for CREATE TABLE: a PRIMARY KEY is declared
+	ColumnCharsets       []ColCharset   // for CREATE TABLE: columns declared with an explicit CHARACTER SET
+	LockWait             string         // MariaDB ALTER TABLE <tbl> WAIT n | NOWAIT: "WAIT" or "NOWAIT", "" if absent
+	LockWaitSeconds      int            // for WAIT n: the seconds
 }
 
 var (
@@ -164,21 +264,49 @@ func splitQualified(name string) (string, string) {
 	return "", name
 }
 
+// SplitStatements splits SQL holding one or more statements separated by semicolons,
+// e.g. a migration file, into the statements, in order. Semicolons in strings, quoted
+// identifiers and comments don't split. Leading comments are stripped, and pieces holding
+// only comments dropped.
+func SplitStatements(sql string) ([]string, error) {
+	p, err := getParser()
+	if err != nil {
+		return nil, fmt.Errorf("creating parser: %w", err)
+	}
+	pieces, err := p.SplitStatementToPieces(sql)
+	if err != nil {
+		return nil, fmt.Errorf("splitting statements: %w", err)
+	}
+	var statements []string
+	for _, piece := range pieces {
+		if stmt := strings.TrimSpace(sqlparser.StripLeadingComments(piece)); stmt != "" {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements, nil
+}
+
 // Parse parses a SQL statement and extracts information needed for analysis.
 func Parse(sql string) (*ParsedSQL, error) {
 	sql = strings.TrimSpace(sql)
 	sql = strings.TrimRight(sql, ";")
 
-	// Pre-pass: OPTIMIZE TABLE — Vitess parses this as OtherAdmin without preserving the table name.
-	if m := reOptimizeTable.FindStringSubmatch(sql); m != nil {
-		db, table := splitQualified(m[1])
-		return &ParsedSQL{
-			Type:     DDL,
-			RawSQL:   sql,
-			DDLOp:    OptimizeTable,
-			Database: db,
-			Table:    table,
-		}, nil
+	// Pre-pass: OPTIMIZE, ANALYZE and CHECK TABLE — Vitess parses these as OtherAdmin (or
+	// not at all) without preserving the table name. Only the first table of a list is kept.
+	for _, maint := range []struct {
+		re *regexp.Regexp
+		op DDLOperation
+	}{{reOptimizeTable, OptimizeTable}, {reAnalyzeTable, AnalyzeTable}, {reCheckTable, CheckTable}} {
+		if m := maint.re.FindStringSubmatch(sql); m != nil {
+			db, table := splitQualified(m[1])
+			return &ParsedSQL{
+				Type:     DDL,
+				RawSQL:   sql,
+				DDLOp:    maint.op,
+				Database: db,
+				Table:    table,
+			}, nil
+		}
 	}
 
 	// Pre-pass: ALTER TABLESPACE ... RENAME TO — Vitess returns a parse error for this statement.
@@ -192,18 +320,72 @@ func Parse(sql string) (*ParsedSQL, error) {
 		}, nil
 	}
 
+	// Pre-pass: view, stored routine, trigger and event definitions — out of scope for
+	// table analysis, but recognized so they aren't reported as unparsable.
+	if m := reObjectDefinition.FindStringSubmatch(sql); m != nil {
+		db, name := splitQualified(m[2])
+		return &ParsedSQL{
+			Type:       DDL,
+			RawSQL:     sql,
+			DDLOp:      ObjectDefinition,
+			Database:   db,
+			ObjectType: strings.ToUpper(m[1]),
+			ObjectName: name,
+		}, nil
+	}
+
+	// Pre-pass: account-management statements — not schema or data changes, but recognized
+	// so they aren't reported as unparsable. Their passwords are redacted from RawSQL, which
+	// ends up in the output and the audit log.
+	if m := reAccountManagement.FindStringSubmatch(sql); m != nil {
+		return &ParsedSQL{
+			Type:       DDL,
+			RawSQL:     reAccountSecret.ReplaceAllString(sql, "${1}'xxxxx'"),
+			DDLOp:      AccountManagement,
+			ObjectType: strings.ToUpper(strings.Join(strings.Fields(m[1]), " ")),
+		}, nil
+	}
+
 	p, err := getParser()
 	if err != nil {
 		return nil, fmt.Errorf("creating parser: %w", err)
 	}
 
-	stmt, err := p.Parse(sql)
+	// Pre-pass: ADD/DROP COLUMN IF [NOT] EXISTS — parse the statement without the modifier.
+	parseSQL, columnIfExists := sql, false
+	if strings.HasPrefix(strings.ToUpper(sql), "ALTER") && reColumnIfExists.MatchString(sql) {
+		parseSQL, columnIfExists = reColumnIfExists.ReplaceAllString(sql, "$1$2"), true
+	}
+
+	// Pre-pass: ALTER TABLE <tbl> WAIT n | NOWAIT — parse the statement without the clause.
+	var lockWait string
+	var lockWaitSeconds int
+	if m := reAlterLockWait.FindStringSubmatch(parseSQL); m != nil {
+		lockWait = "NOWAIT"
+		if m[3] != "" {
+			lockWait = "WAIT"
+			lockWaitSeconds, _ = strconv.Atoi(m[3])
+		}
+		parseSQL = m[1] + " " + parseSQL[len(m[0]):]
+	}
+
+	// Pre-pass: SERIAL is BIGINT UNSIGNED NOT NULL AUTO_INCREMENT UNIQUE, and SERIAL DEFAULT
+	// VALUE is NOT NULL AUTO_INCREMENT UNIQUE.
+	if strings.HasPrefix(strings.ToUpper(parseSQL), "ALTER") {
+		parseSQL = reSerialDefault.ReplaceAllString(parseSQL, "NOT NULL AUTO_INCREMENT UNIQUE KEY")
+		parseSQL = reSerialType.ReplaceAllString(parseSQL, "$1 BIGINT UNSIGNED NOT NULL AUTO_INCREMENT UNIQUE KEY")
+	}
+
+	stmt, err := p.Parse(parseSQL)
 	if err != nil {
 		return nil, fmt.Errorf("parsing SQL: %w", err)
 	}
 
 	result := &ParsedSQL{
-		RawSQL: sql,
+		RawSQL:          sql,
+		ColumnIfExists:  columnIfExists,
+		LockWait:        lockWait,
+		LockWaitSeconds: lockWaitSeconds,
 	}
 
 	switch s := stmt.(type) {
@@ -211,6 +393,14 @@ func Parse(sql string) (*ParsedSQL, error) {
 		result.Type = DDL
 		result.Database, result.Table = extractTableName(s.Table)
 		classifyAlterTable(s, result)
+		if !s.FullyParsed && reAlterOrderBy.MatchString(sql) {
+			result.DDLOp = OrderBy
+		}
+		if !s.FullyParsed && result.DDLOp == OtherDDL {
+			if m := reAlterSecondary.FindStringSubmatch(sql); m != nil {
+				result.DDLOp = DDLOperation(strings.ToUpper(m[1]))
+			}
+		}
 
 	case *sqlparser.RenameTable:
 		result.Type = DDL
@@ -219,11 +409,38 @@ func Parse(sql string) (*ParsedSQL, error) {
 			result.Database, result.Table = extractTableName(s.TablePairs[0].FromTable)
 			_, result.NewTableName = extractTableName(s.TablePairs[0].ToTable)
 		}
+		for _, pair := range s.TablePairs {
+			var rp RenamePair
+			rp.FromDatabase, rp.From = extractTableName(pair.FromTable)
+			rp.ToDatabase, rp.To = extractTableName(pair.ToTable)
+			result.RenamePairs = append(result.RenamePairs, rp)
+		}
 
 	case *sqlparser.CreateTable:
 		result.Type = DDL
 		result.DDLOp = CreateTable
 		result.Database, result.Table = extractTableName(s.Table)
+		switch {
+		case s.OptLike != nil:
+			result.DDLOp = CreateTableLike
+			result.SourceDatabase, result.SourceTable = extractTableName(s.OptLike.LikeTable)
+		case !s.FullyParsed:
+			if selectSQL, query := createTableSelect(p, sql); selectSQL != "" {
+				result.DDLOp = CreateTableAsSelect
+				result.SelectSQL = selectSQL
+				sources, ok := selectSources(query)
+				result.SourceUnknown = !ok
+				if len(sources) > 0 {
+					result.SourceDatabase, result.SourceTable = sources[0].Database, sources[0].Table
+				}
+				if len(sources) > 1 {
+					result.UnionSources = sources
+				}
+			}
+		}
+		if result.DDLOp == CreateTable && s.TableSpec != nil {
+			extractCreateTableSpec(s.TableSpec, result)
+		}
 
 	case *sqlparser.Delete:
 		result.Type = DML
@@ -231,6 +448,11 @@ func Parse(sql string) (*ParsedSQL, error) {
 		if len(s.TableExprs) > 0 {
 			result.Database, result.Table = extractFromTableExprs(s.TableExprs)
 		}
+		var targets []string
+		for _, t := range s.Targets {
+			targets = append(targets, t.Name.String())
+		}
+		extractMultiTable(s.TableExprs, targets, result)
 		extractWhere(s.Where, result)
 
 	case *sqlparser.Update:
@@ -239,6 +461,15 @@ func Parse(sql string) (*ParsedSQL, error) {
 		if len(s.TableExprs) > 0 {
 			result.Database, result.Table = extractFromTableExprs(s.TableExprs)
 		}
+		var targets []string
+		for _, e := range s.Exprs {
+			result.UpdateColumns = append(result.UpdateColumns, e.Name.Name.String())
+			if q := e.Name.Qualifier.Name.String(); q != "" && !slices.Contains(targets, q) {
+				targets = append(targets, q)
+			}
+		}
+		result.SetClause = sqlparser.String(s.Exprs)
+		extractMultiTable(s.TableExprs, targets, result)
 		extractWhere(s.Where, result)
 
 	case *sqlparser.Insert:
@@ -262,12 +493,125 @@ func Parse(sql string) (*ParsedSQL, error) {
 	return result, nil
 }
 
+// TableRef is a table as a statement names it. Database is "" when unqualified.
+type TableRef struct {
+	Database string
+	Table    string
+}
+
+// RenamePair is one "FROM TO" pair of a RENAME TABLE. Databases are "" when unqualified.
+type RenamePair struct {
+	FromDatabase string
+	From         string
+	ToDatabase   string
+	To           string
+}
+
+// ColCharset is a column declared with an explicit CHARACTER SET (lowercase).
+type ColCharset struct {
+	Column  string
+	Charset string
+}
+
+// extractCreateTableSpec records the table options, primary key and column character sets
+// of a CREATE TABLE.
+func extractCreateTableSpec(spec *sqlparser.TableSpec, result *ParsedSQL) {
+	for _, opt := range spec.Options {
+		switch strings.ToUpper(opt.Name) {
+		case "ENGINE":
+			result.TableEngine = strings.ToLower(opt.String)
+		case "CHARSET", "CHARACTER SET", "DEFAULT CHARSET", "DEFAULT CHARACTER SET":
+			result.TableCharset = strings.ToLower(opt.String)
+		case "ROW_FORMAT":
+			result.TableRowFormat = strings.ToUpper(opt.String)
+		}
+	}
+	for _, idx := range spec.Indexes {
+		if idx.Info.Type == sqlparser.IndexTypePrimary {
+			result.HasPrimaryKey = true
+		}
+	}
+	for _, col := range spec.Columns {
+		if col.Type.Options != nil && col.Type.Options.KeyOpt == sqlparser.ColKeyPrimary {
+			result.HasPrimaryKey = true
+		}
+		if col.Type.Charset.Name != "" {
+			result.ColumnCharsets = append(result.ColumnCharsets, ColCharset{Column: col.Name.String(), Charset: strings.ToLower(col.Type.Charset.Name)})
+		}
+	}
+}
+
+// partitionColumns returns the columns a PARTITION BY clause partitions on: the COLUMNS or
+// KEY column list, or the columns its expression references. KEY() with no columns
+// partitions on the primary key and returns nil.
+func partitionColumns(p *sqlparser.PartitionOption) []string {
+	var cols []string
+	for _, c := range p.ColList {
+		cols = append(cols, c.String())
+	}
+	if p.Expr != nil {
+		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+			if col, ok := node.(*sqlparser.ColName); ok && !slices.Contains(cols, col.Name.String()) {
+				cols = append(cols, col.Name.String())
+			}
+			return true, nil
+		}, p.Expr)
+	}
+	return cols
+}
+
 func extractTableName(tn sqlparser.TableName) (string, string) {
 	db := tn.Qualifier.String()
 	table := tn.Name.String()
 	return db, table
 }
 
+// createTableSelect finds the query of a CREATE TABLE ... [AS] SELECT: the first SELECT
+// keyword from which the rest of the statement parses as a SELECT or a UNION, so a SELECT in
+// a column comment doesn't count, with parentheses around the query dropped. When none
+// parses it falls back to the first SELECT, with a nil query. Returns "" without a SELECT.
+func createTableSelect(p *sqlparser.Parser, sql string) (string, sqlparser.SelectStatement) {
+	var first string
+	for _, m := range reCreateTableSelect.FindAllStringIndex(sql, -1) {
+		query := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(sql[m[0]:]), ";"))
+		if strings.HasSuffix(strings.TrimSpace(sql[:m[0]]), "(") {
+			query = strings.TrimSpace(strings.TrimSuffix(query, ")"))
+		}
+		if first == "" {
+			first = query
+		}
+		if stmt, err := p.Parse(query); err == nil {
+			if q, ok := stmt.(sqlparser.SelectStatement); ok {
+				return query, q
+			}
+		}
+	}
+	return first, nil
+}
+
+// selectSources returns the table each SELECT of query reads its rows from, in order: one
+// for a SELECT, one per SELECT of a UNION. A SELECT without FROM reads Vitess's implicit
+// dual, no table. ok is false when a SELECT reads from no table that can be sized, such as
+// a derived table, or when query is nil (it didn't parse).
+func selectSources(query sqlparser.SelectStatement) (tables []TableRef, ok bool) {
+	switch q := query.(type) {
+	case *sqlparser.Union:
+		left, leftOK := selectSources(q.Left)
+		right, rightOK := selectSources(q.Right)
+		return append(left, right...), leftOK && rightOK
+	case *sqlparser.Select:
+		db, table := extractFromTableExprs(q.From)
+		switch {
+		case strings.EqualFold(table, "dual"):
+			return nil, true
+		case table == "":
+			return nil, false
+		}
+		return []TableRef{{Database: db, Table: table}}, true
+	}
+	return nil, false
+}
+
 func extractFromTableExprs(exprs sqlparser.TableExprs) (string, string) {
 	for _, expr := range exprs {
 		if t, ok := expr.(*sqlparser.AliasedTableExpr); ok {
@@ -279,6 +623,61 @@ func extractFromTableExprs(exprs sqlparser.TableExprs) (string, string) {
 	return "", ""
 }
 
+// extractMultiTable records the tables of a multi-table DELETE or UPDATE: those it joins,
+// the targets (targets are the names the statement uses, aliases included: the DELETE
+// list, or the qualifiers of the SET columns) and the FROM clause. Table becomes the first
+// target, or the first table when no target can be told (unqualified SET columns).
+// Single-table statements are left alone.
+func extractMultiTable(exprs sqlparser.TableExprs, targets []string, result *ParsedSQL) {
+	type tableRef struct{ db, name, ref string }
+	var refs []tableRef
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch n := node.(type) {
+		case *sqlparser.AliasedTableExpr:
+			if tn, ok := n.Expr.(sqlparser.TableName); ok {
+				db, name := extractTableName(tn)
+				ref := name
+				if !n.As.IsEmpty() {
+					ref = n.As.String()
+				}
+				refs = append(refs, tableRef{db, name, ref})
+			}
+			return false, nil
+		case *sqlparser.Subquery:
+			return false, nil
+		}
+		return true, nil
+	}, exprs)
+	if len(refs) < 2 {
+		return
+	}
+
+	qualified := func(r tableRef) string {
+		if r.db != "" {
+			return r.db + "." + r.name
+		}
+		return r.name
+	}
+	for _, r := range refs {
+		result.JoinTables = append(result.JoinTables, qualified(r))
+	}
+	for _, t := range targets {
+		for _, r := range refs {
+			if strings.EqualFold(r.ref, t) {
+				if result.TargetRef == "" {
+					result.Database, result.Table, result.TargetRef = r.db, r.name, r.ref
+				}
+				result.TargetTables = append(result.TargetTables, qualified(r))
+				break
+			}
+		}
+	}
+	if result.TargetRef == "" {
+		result.Database, result.Table, result.TargetRef = refs[0].db, refs[0].name, refs[0].ref
+	}
+	result.FromClause = sqlparser.String(exprs)
+}
+
 func extractWhere(where *sqlparser.Where, result *ParsedSQL) {
 	if where != nil {
 		result.WhereClause = sqlparser.String(where.Expr)
@@ -305,32 +704,44 @@ func classifyAlterTable(alter *sqlparser.AlterTable, result *ParsedSQL) {
 		case sqlparser.TruncateAction:
 			result.DDLOp = TruncatePartition
 			return
+		case sqlparser.RemoveAction:
+			result.DDLOp = RemovePartitioning
+			return
 		}
 	}
+	// PARTITION BY replaces the whole partitioning scheme, whatever else the ALTER does.
+	if alter.PartitionOption != nil {
+		result.DDLOp = RepartitionTable
+		result.PartitionColumns = partitionColumns(alter.PartitionOption)
+		return
+	}
+
+	// ALGORITHM= and LOCK= clauses are hints, not operations: record them and classify the rest.
+	opts := extractAlgorithmLockHints(alter.AlterOptions, result)
 
-	if len(alter.AlterOptions) == 0 {
+	if len(opts) == 0 {
 		result.DDLOp = OtherDDL
 		return
 	}
 
 	// If multiple operations, check for well-known two-op patterns before falling back.
-	if len(alter.AlterOptions) > 1 {
+	if len(opts) > 1 {
 		// Pattern: exactly DROP INDEX + ADD INDEX on the same index name → index type change.
-		if len(alter.AlterOptions) == 2 {
-			if indexName, ok := detectDropAddIndexPattern(alter.AlterOptions); ok {
+		if len(opts) == 2 {
+			if indexName, ok := detectDropAddIndexPattern(opts); ok {
 				result.DDLOp = ChangeIndexType
 				result.IndexName = indexName
 				return
 			}
 			// Pattern: exactly DROP PRIMARY KEY + ADD PRIMARY KEY → primary key replacement.
-			if detectDropAddPKPattern(alter.AlterOptions) {
+			if detectDropAddPKPattern(opts) {
 				result.DDLOp = ReplacePrimaryKey
 				return
 			}
 		}
 
 		result.DDLOp = MultipleOps
-		for _, opt := range alter.AlterOptions {
+		for _, opt := range opts {
 			subOp := extractAlterOpDetails(opt)
 			result.SubOperations = append(result.SubOperations, subOp)
 			// Propagate AUTO_INCREMENT flag so the analyzer can apply the correct
@@ -343,10 +754,10 @@ func classifyAlterTable(alter *sqlparser.AlterTable, result *ParsedSQL) {
 	}
 
 	// Single operation
-	result.DDLOp = classifySingleAlterOp(alter.AlterOptions[0])
+	result.DDLOp = classifySingleAlterOp(opts[0])
 
 	// Extract details via the shared helper and populate SubOperations[0].
-	subOp := extractAlterOpDetails(alter.AlterOptions[0])
+	subOp := extractAlterOpDetails(opts[0])
 	result.SubOperations = []SubOperation{subOp}
 
 	// Copy common fields to top-level ParsedSQL for backward compatibility with
@@ -355,20 +766,27 @@ func classifyAlterTable(alter *sqlparser.AlterTable, result *ParsedSQL) {
 	result.OldColumnName = subOp.OldColumnName
 	result.NewColumnType = subOp.NewColumnType
 	result.NewColumnCharset = subOp.NewColumnCharset
+	result.NewColumnSRID = subOp.NewColumnSRID
 	result.NewColumnNullable = subOp.NewColumnNullable
 	result.IsFirstAfter = subOp.IsFirstAfter
+	result.AfterColumn = subOp.AfterColumn
 	result.IndexName = subOp.IndexName
 	result.IndexColumns = subOp.IndexColumns
 	result.IsUniqueIndex = subOp.IsUniqueIndex
+	result.IndexInvisible = subOp.IndexInvisible
 	result.HasAutoIncrement = subOp.HasAutoIncrement
+	result.HasUniqueKey = subOp.HasUniqueKey
 	result.HasNotNull = subOp.HasNotNull
+	result.HasExpressionDefault = subOp.HasExpressionDefault
 	result.IsGeneratedStored = subOp.IsGeneratedStored
 	result.IsGeneratedColumn = subOp.IsGeneratedColumn
+	result.NewGenerationExpr = subOp.NewGenerationExpr
 	result.NewEngine = subOp.NewEngine
 	result.CheckExpr = subOp.CheckExpr
+	result.CheckNotEnforced = subOp.CheckNotEnforced
 
 	// Handle fields not in SubOperation (single-op only).
-	switch opt := alter.AlterOptions[0].(type) {
+	switch opt := opts[0].(type) {
 	case *sqlparser.AddColumns:
 		if len(opt.Columns) > 0 {
 			col := opt.Columns[0]
@@ -386,6 +804,9 @@ func classifyAlterTable(alter *sqlparser.AlterTable, result *ParsedSQL) {
 		_, result.NewTableName = extractTableName(opt.Table)
 	case *sqlparser.RenameIndex:
 		result.NewIndexName = opt.NewName.String()
+	case *sqlparser.AlterCharset:
+		result.NewCharset = strings.ToLower(opt.CharacterSet)
+		result.NewCollation = strings.ToLower(opt.Collate)
 	}
 }
 
@@ -399,6 +820,11 @@ func extractAlterOpDetails(opt sqlparser.AlterOption) SubOperation {
 		if len(o.Columns) > 0 {
 			col := o.Columns[0]
 			subOp.ColumnName = col.Name.String()
+			subOp.NewColumnType = baseColumnTypeString(col.Type)
+			subOp.NewColumnSRID = columnSRID(col.Type)
+			if col.Type.Charset.Name != "" {
+				subOp.NewColumnCharset = strings.ToLower(col.Type.Charset.Name)
+			}
 			if col.Type.Options != nil {
 				if col.Type.Options.Null != nil && !*col.Type.Options.Null {
 					subOp.HasNotNull = true
@@ -406,8 +832,16 @@ func extractAlterOpDetails(opt sqlparser.AlterOption) SubOperation {
 				if col.Type.Options.Autoincrement {
 					subOp.HasAutoIncrement = true
 				}
+				if col.Type.Options.KeyOpt == sqlparser.ColKeyUnique || col.Type.Options.KeyOpt == sqlparser.ColKeyUniqueKey {
+					subOp.HasUniqueKey = true
+				}
+				// Vitess clears DefaultLiteral for parenthesized DEFAULT (expr) values.
+				if col.Type.Options.Default != nil && !col.Type.Options.DefaultLiteral {
+					subOp.HasExpressionDefault = true
+				}
 				if col.Type.Options.As != nil {
 					subOp.IsGeneratedColumn = true
+					subOp.NewGenerationExpr = sqlparser.String(col.Type.Options.As)
 					if col.Type.Options.Storage == sqlparser.StoredStorage {
 						subOp.IsGeneratedStored = true
 					}
@@ -416,6 +850,9 @@ func extractAlterOpDetails(opt sqlparser.AlterOption) SubOperation {
 			if o.First || o.After != nil {
 				subOp.IsFirstAfter = true
 			}
+			if o.After != nil {
+				subOp.AfterColumn = o.After.Name.String()
+			}
 		}
 
 	case *sqlparser.DropColumn:
@@ -425,6 +862,7 @@ func extractAlterOpDetails(opt sqlparser.AlterOption) SubOperation {
 		subOp.ColumnName = o.NewColDefinition.Name.String()
 		if o.NewColDefinition.Type != nil {
 			subOp.NewColumnType = baseColumnTypeString(o.NewColDefinition.Type)
+			subOp.NewColumnSRID = columnSRID(o.NewColDefinition.Type)
 			if o.NewColDefinition.Type.Charset.Name != "" {
 				subOp.NewColumnCharset = strings.ToLower(o.NewColDefinition.Type.Charset.Name)
 			}
@@ -432,6 +870,7 @@ func extractAlterOpDetails(opt sqlparser.AlterOption) SubOperation {
 				subOp.NewColumnNullable = o.NewColDefinition.Type.Options.Null
 				if o.NewColDefinition.Type.Options.As != nil {
 					subOp.IsGeneratedColumn = true
+					subOp.NewGenerationExpr = sqlparser.String(o.NewColDefinition.Type.Options.As)
 					if o.NewColDefinition.Type.Options.Storage == sqlparser.StoredStorage {
 						subOp.IsGeneratedStored = true
 					}
@@ -441,12 +880,28 @@ func extractAlterOpDetails(opt sqlparser.AlterOption) SubOperation {
 		if o.First || o.After != nil {
 			subOp.IsFirstAfter = true
 		}
+		if o.After != nil {
+			subOp.AfterColumn = o.After.Name.String()
+		}
 
 	case *sqlparser.ChangeColumn:
 		subOp.OldColumnName = o.OldColumn.Name.String()
 		subOp.ColumnName = o.NewColDefinition.Name.String() // new column name
 		if o.NewColDefinition.Type != nil {
 			subOp.NewColumnType = baseColumnTypeString(o.NewColDefinition.Type)
+			subOp.NewColumnSRID = columnSRID(o.NewColDefinition.Type)
+			if o.NewColDefinition.Type.Charset.Name != "" {
+				subOp.NewColumnCharset = strings.ToLower(o.NewColDefinition.Type.Charset.Name)
+			}
+			if o.NewColDefinition.Type.Options != nil {
+				subOp.NewColumnNullable = o.NewColDefinition.Type.Options.Null
+			}
+		}
+		if o.First || o.After != nil {
+			subOp.IsFirstAfter = true
+		}
+		if o.After != nil {
+			subOp.AfterColumn = o.After.Name.String()
 		}
 
 	case *sqlparser.AddIndexDefinition:
@@ -464,6 +919,7 @@ func extractAlterOpDetails(opt sqlparser.AlterOption) SubOperation {
 	case *sqlparser.AddConstraintDefinition:
 		if chk, ok := o.ConstraintDefinition.Details.(*sqlparser.CheckConstraintDefinition); ok {
 			subOp.CheckExpr = sqlparser.String(chk.Expr)
+			subOp.CheckNotEnforced = !chk.Enforced
 		} else {
 			subOp.IndexName = o.ConstraintDefinition.Name.String()
 		}
@@ -471,6 +927,10 @@ func extractAlterOpDetails(opt sqlparser.AlterOption) SubOperation {
 	case *sqlparser.RenameIndex:
 		subOp.IndexName = o.OldName.String()
 
+	case *sqlparser.AlterIndex:
+		subOp.IndexName = o.Name.String()
+		subOp.IndexInvisible = o.Invisible
+
 	case sqlparser.TableOptions:
 		for _, tableOpt := range o {
 			if strings.ToUpper(tableOpt.Name) == "ENGINE" && tableOpt.String != "" {
@@ -483,6 +943,23 @@ func extractAlterOpDetails(opt sqlparser.AlterOption) SubOperation {
 	return subOp
 }
 
+// extractAlgorithmLockHints records explicit ALGORITHM= and LOCK= clauses on result and
+// returns the remaining alter options.
+func extractAlgorithmLockHints(opts []sqlparser.AlterOption, result *ParsedSQL) []sqlparser.AlterOption {
+	var rest []sqlparser.AlterOption
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case sqlparser.AlgorithmValue:
+			result.AlgorithmHint = strings.ToUpper(string(o))
+		case *sqlparser.LockOption:
+			result.LockHint = strings.ToUpper(o.Type.ToString())
+		default:
+			rest = append(rest, opt)
+		}
+	}
+	return rest
+}
+
 func classifySingleAlterOp(opt sqlparser.AlterOption) DDLOperation {
 	switch opt := opt.(type) {
 	case *sqlparser.AddColumns:
@@ -514,10 +991,14 @@ func classifySingleAlterOp(opt sqlparser.AlterOption) DDLOperation {
 		}
 	case *sqlparser.RenameIndex:
 		return RenameIndex
+	case *sqlparser.AlterIndex:
+		return ChangeIndexVisibility
 	case *sqlparser.RenameTableName:
 		return RenameTable
 	case *sqlparser.Force:
 		return ForceRebuild
+	case *sqlparser.OrderByOption:
+		return OrderBy
 	case *sqlparser.AddConstraintDefinition:
 		if _, ok := opt.ConstraintDefinition.Details.(*sqlparser.CheckConstraintDefinition); ok {
 			return AddCheckConstraint
@@ -550,6 +1031,8 @@ func classifySingleAlterOp(opt sqlparser.AlterOption) DDLOperation {
 				return StatsOption
 			case "ENCRYPTION":
 				return TableEncryption
+			case "ENGINE_ATTRIBUTE", "SECONDARY_ENGINE_ATTRIBUTE":
+				return EngineAttribute
 			}
 		}
 		return OtherDDL
@@ -601,6 +1084,98 @@ func detectDropAddPKPattern(opts []sqlparser.AlterOption) bool {
 	return hasDrop && hasAdd
 }
 
+// NormalizeExpr returns a canonical form of a SQL expression for comparison: parsed and
+// re-printed, so quoting, spacing and redundant parentheses don't matter, with charset
+// introducers dropped and lowercased. information_schema's GENERATION_EXPRESSION
+// ("(`price` * `qty`)", "_utf8mb4'x'") and the expression written in an ALTER
+// ("price*qty", "'x'") normalize to the same string. An expression that doesn't parse
+// falls back to lowercase with backticks and whitespace removed.
+func NormalizeExpr(expr string) string {
+	if p, err := getParser(); err == nil {
+		if e, err := p.ParseExpr(expr); err == nil {
+			e = sqlparser.Rewrite(e, nil, func(c *sqlparser.Cursor) bool {
+				if intro, ok := c.Node().(*sqlparser.IntroducerExpr); ok {
+					c.Replace(intro.Expr)
+				}
+				return true
+			}).(sqlparser.Expr)
+			return strings.ToLower(sqlparser.String(e))
+		}
+	}
+	return strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(expr, "`", "")), ""))
+}
+
+// ColumnComparison parses a WHERE condition that is a single comparison of a column with a
+// literal (`col < '2025-01-01'`, `id >= 100`), returning the column, the operator (=, <,
+// <=, > or >=) and the literal as written in SQL. ok is false for anything else, such as
+// conditions combined with AND or OR, or a comparison with another column or a function.
+func ColumnComparison(expr string) (column, op, value string, ok bool) {
+	p, err := getParser()
+	if err != nil {
+		return "", "", "", false
+	}
+	e, err := p.ParseExpr(expr)
+	if err != nil {
+		return "", "", "", false
+	}
+	cmp, isCmp := e.(*sqlparser.ComparisonExpr)
+	if !isCmp || cmp.Modifier != 0 || cmp.Escape != nil {
+		return "", "", "", false
+	}
+	col, isCol := cmp.Left.(*sqlparser.ColName)
+	lit, isLit := cmp.Right.(*sqlparser.Literal)
+	if !isCol || !isLit {
+		return "", "", "", false
+	}
+	switch cmp.Operator {
+	case sqlparser.EqualOp, sqlparser.LessThanOp, sqlparser.LessEqualOp, sqlparser.GreaterThanOp, sqlparser.GreaterEqualOp:
+	default:
+		return "", "", "", false
+	}
+	return col.Name.String(), cmp.Operator.ToString(), sqlparser.String(lit), true
+}
+
+// ExpressionColumns returns the columns a SQL expression references, in order of first
+// appearance, quoted or not. An expression that doesn't parse falls back to an identifier
+// scan that skips function names but can return keywords, so match the result against
+// known column names.
+func ExpressionColumns(expr string) []string {
+	var cols []string
+	add := func(name string) {
+		if !slices.ContainsFunc(cols, func(c string) bool { return strings.EqualFold(c, name) }) {
+			cols = append(cols, name)
+		}
+	}
+	if p, err := getParser(); err == nil {
+		if e, err := p.ParseExpr(expr); err == nil {
+			_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+				if col, ok := node.(*sqlparser.ColName); ok {
+					add(col.Name.String())
+				}
+				return true, nil
+			}, e)
+			return cols
+		}
+	}
+	for _, m := range reExprIdentifier.FindAllStringSubmatch(expr, -1) {
+		switch {
+		case m[1] != "":
+			add(m[1])
+		case m[3] == "":
+			add(m[2])
+		}
+	}
+	return cols
+}
+
+// columnSRID returns the SRID attribute of a spatial column definition, or "" if none.
+func columnSRID(ct *sqlparser.ColumnType) string {
+	if ct == nil || ct.Options == nil || ct.Options.SRID == nil {
+		return ""
+	}
+	return ct.Options.SRID.Val
+}
+
 // baseColumnTypeString returns only the data type portion of a Vitess ColumnType —
 // type keyword + length/scale + UNSIGNED/ZEROFILL + enum values — without column-level
 // options (NULL / NOT NULL, DEFAULT, AUTO_INCREMENT, COLLATE, etc.).
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -176,15 +177,27 @@ func TestParse_AlterTableAddForeignKey(t *testing.T) {
 
 func TestParse_AddCheckConstraint(t *testing.T) {
 	tests := []struct {
-		name     string
-		sql      string
-		wantExpr string
+		name            string
+		sql             string
+		wantExpr        string
+		wantNotEnforced bool
 	}{
 		{
 			name:     "simple check on column",
 			sql:      "ALTER TABLE orders ADD CONSTRAINT chk_amount CHECK (amount > 0)",
 			wantExpr: "amount > 0",
 		},
+		{
+			name:     "explicitly enforced check",
+			sql:      "ALTER TABLE orders ADD CONSTRAINT chk_amount CHECK (amount > 0) ENFORCED",
+			wantExpr: "amount > 0",
+		},
+		{
+			name:            "not enforced check",
+			sql:             "ALTER TABLE orders ADD CONSTRAINT chk_amount CHECK (amount > 0) NOT ENFORCED",
+			wantExpr:        "amount > 0",
+			wantNotEnforced: true,
+		},
 		{
 			name:     "compound check expression",
 			sql:      "ALTER TABLE users ADD CONSTRAINT chk_age CHECK (age >= 18 AND age <= 120)",
@@ -209,6 +222,9 @@ func TestParse_AddCheckConstraint(t *testing.T) {
 			if result.CheckExpr != tt.wantExpr {
 				t.Errorf("CheckExpr = %q, want %q", result.CheckExpr, tt.wantExpr)
 			}
+			if result.CheckNotEnforced != tt.wantNotEnforced {
+				t.Errorf("CheckNotEnforced = %v, want %v", result.CheckNotEnforced, tt.wantNotEnforced)
+			}
 		})
 	}
 }
@@ -221,6 +237,12 @@ func TestParse_AlterTableConvertCharset(t *testing.T) {
 	if result.DDLOp != ConvertCharset {
 		t.Errorf("DDLOp = %q, want %q", result.DDLOp, ConvertCharset)
 	}
+	if result.NewCharset != "utf8mb4" {
+		t.Errorf("NewCharset = %q, want utf8mb4", result.NewCharset)
+	}
+	if result.NewCollation != "utf8mb4_unicode_ci" {
+		t.Errorf("NewCollation = %q, want utf8mb4_unicode_ci", result.NewCollation)
+	}
 }
 
 func TestParse_AlterTableChangeCharset(t *testing.T) {
@@ -339,18 +361,28 @@ func TestParse_Update(t *testing.T) {
 		sql      string
 		table    string
 		hasWhere bool
+		columns  []string
 	}{
 		{
 			name:     "update with where",
 			sql:      "UPDATE users SET status = 'inactive' WHERE last_login < '2023-01-01'",
 			table:    "users",
 			hasWhere: true,
+			columns:  []string{"status"},
 		},
 		{
 			name:     "update without where",
 			sql:      "UPDATE counters SET value = 0",
 			table:    "counters",
 			hasWhere: false,
+			columns:  []string{"value"},
+		},
+		{
+			name:     "update several columns",
+			sql:      "UPDATE users u SET u.status = 'inactive', updated_at = NOW() WHERE id = 1",
+			table:    "users",
+			hasWhere: true,
+			columns:  []string{"status", "updated_at"},
 		},
 	}
 
@@ -372,6 +404,9 @@ func TestParse_Update(t *testing.T) {
 			if result.HasWhere != tt.hasWhere {
 				t.Errorf("HasWhere = %v, want %v", result.HasWhere, tt.hasWhere)
 			}
+			if !reflect.DeepEqual(result.UpdateColumns, tt.columns) {
+				t.Errorf("UpdateColumns = %v, want %v", result.UpdateColumns, tt.columns)
+			}
 		})
 	}
 }
@@ -457,31 +492,106 @@ func TestParse_LoadData(t *testing.T) {
 
 func TestParse_CreateTable(t *testing.T) {
 	tests := []struct {
-		name     string
-		sql      string
-		table    string
-		database string
+		name        string
+		sql         string
+		table       string
+		database    string
+		wantOp      DDLOperation
+		sourceTable string
+		sourceDB    string
+		selectSQL   string
 	}{
 		{
-			name:  "simple create table",
-			sql:   "CREATE TABLE users (id INT PRIMARY KEY, name VARCHAR(100))",
-			table: "users",
+			name:   "simple create table",
+			sql:    "CREATE TABLE users (id INT PRIMARY KEY, name VARCHAR(100))",
+			table:  "users",
+			wantOp: CreateTable,
+		},
+		{
+			name:        "create table as select",
+			sql:         "CREATE TABLE new_users AS SELECT * FROM old_users",
+			table:       "new_users",
+			wantOp:      CreateTableAsSelect,
+			sourceTable: "old_users",
+			selectSQL:   "SELECT * FROM old_users",
 		},
 		{
-			name:  "create table as select",
-			sql:   "CREATE TABLE new_users AS SELECT * FROM old_users",
-			table: "new_users",
+			name:        "create table select (without AS)",
+			sql:         "CREATE TABLE new_users SELECT * FROM old_users",
+			table:       "new_users",
+			wantOp:      CreateTableAsSelect,
+			sourceTable: "old_users",
+			selectSQL:   "SELECT * FROM old_users",
 		},
 		{
-			name:  "create table select (without AS)",
-			sql:   "CREATE TABLE new_users SELECT * FROM old_users",
-			table: "new_users",
+			name:        "create table with columns and select",
+			sql:         "CREATE TABLE archive (id INT) SELECT id FROM orders WHERE created_at < '2024-01-01'",
+			table:       "archive",
+			wantOp:      CreateTableAsSelect,
+			sourceTable: "orders",
+			selectSQL:   "SELECT id FROM orders WHERE created_at < '2024-01-01'",
+		},
+		{
+			name:        "create table ignore select from a qualified table",
+			sql:         "CREATE TABLE archive IGNORE SELECT * FROM shop.orders",
+			table:       "archive",
+			wantOp:      CreateTableAsSelect,
+			sourceTable: "orders",
+			sourceDB:    "shop",
+			selectSQL:   "SELECT * FROM shop.orders",
+		},
+		{
+			name:        "create table replace as select with a subquery",
+			sql:         "CREATE TABLE archive REPLACE AS SELECT * FROM orders WHERE id IN (SELECT order_id FROM refunds)",
+			table:       "archive",
+			wantOp:      CreateTableAsSelect,
+			sourceTable: "orders",
+			selectSQL:   "SELECT * FROM orders WHERE id IN (SELECT order_id FROM refunds)",
+		},
+		{
+			name:        "create table as parenthesized select",
+			sql:         "CREATE TABLE archive AS (SELECT * FROM orders);",
+			table:       "archive",
+			wantOp:      CreateTableAsSelect,
+			sourceTable: "orders",
+			selectSQL:   "SELECT * FROM orders",
+		},
+		{
+			name:        "select in a column comment",
+			sql:         "CREATE TABLE archive (id INT COMMENT 'rows we select later') SELECT id FROM orders",
+			table:       "archive",
+			wantOp:      CreateTableAsSelect,
+			sourceTable: "orders",
+			selectSQL:   "SELECT id FROM orders",
+		},
+		{
+			name:      "create table as select without from",
+			sql:       "CREATE TABLE consts AS SELECT 1 AS one",
+			table:     "consts",
+			wantOp:    CreateTableAsSelect,
+			selectSQL: "SELECT 1 AS one",
+		},
+		{
+			name:        "create table like",
+			sql:         "CREATE TABLE users_copy LIKE users",
+			table:       "users_copy",
+			wantOp:      CreateTableLike,
+			sourceTable: "users",
+		},
+		{
+			name:        "create table like a qualified table",
+			sql:         "CREATE TABLE users_copy LIKE shop.users",
+			table:       "users_copy",
+			wantOp:      CreateTableLike,
+			sourceTable: "users",
+			sourceDB:    "shop",
 		},
 		{
 			name:     "create table with qualified name",
 			sql:      "CREATE TABLE mydb.users (id INT)",
 			table:    "users",
 			database: "mydb",
+			wantOp:   CreateTable,
 		},
 	}
 
@@ -494,8 +604,8 @@ func TestParse_CreateTable(t *testing.T) {
 			if result.Type != DDL {
 				t.Errorf("Type = %q, want DDL", result.Type)
 			}
-			if result.DDLOp != CreateTable {
-				t.Errorf("DDLOp = %q, want %q", result.DDLOp, CreateTable)
+			if result.DDLOp != tt.wantOp {
+				t.Errorf("DDLOp = %q, want %q", result.DDLOp, tt.wantOp)
 			}
 			if result.Table != tt.table {
 				t.Errorf("Table = %q, want %q", result.Table, tt.table)
@@ -503,10 +613,86 @@ func TestParse_CreateTable(t *testing.T) {
 			if result.Database != tt.database {
 				t.Errorf("Database = %q, want %q", result.Database, tt.database)
 			}
+			if result.SourceTable != tt.sourceTable {
+				t.Errorf("SourceTable = %q, want %q", result.SourceTable, tt.sourceTable)
+			}
+			if result.SourceDatabase != tt.sourceDB {
+				t.Errorf("SourceDatabase = %q, want %q", result.SourceDatabase, tt.sourceDB)
+			}
+			if result.SelectSQL != tt.selectSQL {
+				t.Errorf("SelectSQL = %q, want %q", result.SelectSQL, tt.selectSQL)
+			}
 		})
 	}
 }
 
+func TestParse_CreateTableAsSelectSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		source  string
+		union   []TableRef
+		unknown bool
+	}{
+		{"single table", "CREATE TABLE a AS SELECT * FROM orders", "orders", nil, false},
+		{"union", "CREATE TABLE a AS SELECT id FROM orders UNION ALL SELECT id FROM shop.refunds UNION SELECT id FROM returns",
+			"orders", []TableRef{{Table: "orders"}, {Database: "shop", Table: "refunds"}, {Table: "returns"}}, false},
+		{"union with a constant row", "CREATE TABLE a AS SELECT id FROM orders UNION SELECT 0", "orders", nil, false},
+		{"derived table", "CREATE TABLE a AS SELECT * FROM (SELECT id FROM orders) AS t", "", nil, true},
+		{"union with a derived table", "CREATE TABLE a AS SELECT id FROM orders UNION SELECT id FROM (SELECT id FROM refunds) AS t",
+			"orders", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.DDLOp != CreateTableAsSelect || result.SourceTable != tt.source {
+				t.Errorf("DDLOp, SourceTable = %q, %q, want CREATE_TABLE_AS_SELECT, %q", result.DDLOp, result.SourceTable, tt.source)
+			}
+			if !reflect.DeepEqual(result.UnionSources, tt.union) {
+				t.Errorf("UnionSources = %v, want %v", result.UnionSources, tt.union)
+			}
+			if result.SourceUnknown != tt.unknown {
+				t.Errorf("SourceUnknown = %v, want %v", result.SourceUnknown, tt.unknown)
+			}
+		})
+	}
+}
+
+func TestParse_CreateTableSpec(t *testing.T) {
+	result, err := Parse("CREATE TABLE logs (id INT NOT NULL PRIMARY KEY, msg VARCHAR(100) CHARACTER SET latin1, note TEXT) ENGINE=MyISAM DEFAULT CHARSET=utf8mb3 ROW_FORMAT=dynamic")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.TableEngine != "myisam" || result.TableCharset != "utf8mb3" || result.TableRowFormat != "DYNAMIC" {
+		t.Errorf("table options = (%q, %q, %q), want (myisam, utf8mb3, DYNAMIC)", result.TableEngine, result.TableCharset, result.TableRowFormat)
+	}
+	if !result.HasPrimaryKey {
+		t.Error("HasPrimaryKey = false, want true for a column-level PRIMARY KEY")
+	}
+	if len(result.ColumnCharsets) != 1 || result.ColumnCharsets[0] != (ColCharset{Column: "msg", Charset: "latin1"}) {
+		t.Errorf("ColumnCharsets = %v, want [{msg latin1}]", result.ColumnCharsets)
+	}
+
+	result, err = Parse("CREATE TABLE t (a INT, b INT, PRIMARY KEY (a, b)) CHARACTER SET utf8mb4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.HasPrimaryKey || result.TableCharset != "utf8mb4" || result.TableEngine != "" {
+		t.Errorf("got HasPrimaryKey=%v TableCharset=%q TableEngine=%q", result.HasPrimaryKey, result.TableCharset, result.TableEngine)
+	}
+
+	result, err = Parse("CREATE TABLE t (a INT, UNIQUE KEY (a))")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.HasPrimaryKey {
+		t.Error("HasPrimaryKey = true for a table with only a UNIQUE key")
+	}
+}
+
 func TestParse_UnknownStatements(t *testing.T) {
 	tests := []struct {
 		name string
@@ -804,6 +990,74 @@ func TestParse_ModifyColumn_ExtractsNewColumnType(t *testing.T) {
 	}
 }
 
+// TestParse_MultiOp_AddColumnExtractsType verifies that ADD COLUMN sub-operations carry
+// the column type and charset so the analyzer can size the resulting row.
+func TestParse_MultiOp_AddColumnExtractsType(t *testing.T) {
+	result, err := Parse("ALTER TABLE orders ADD COLUMN note VARCHAR(200) CHARACTER SET latin1, ADD COLUMN qty INT UNSIGNED NOT NULL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.SubOperations) != 2 {
+		t.Fatalf("SubOperations = %d, want 2", len(result.SubOperations))
+	}
+	if got := result.SubOperations[0]; got.NewColumnType != "varchar(200)" || got.NewColumnCharset != "latin1" {
+		t.Errorf("sub-op 0 type/charset = %q/%q, want varchar(200)/latin1", got.NewColumnType, got.NewColumnCharset)
+	}
+	if got := result.SubOperations[1].NewColumnType; got != "int unsigned" {
+		t.Errorf("sub-op 1 NewColumnType = %q, want %q", got, "int unsigned")
+	}
+}
+
+// TestParse_SpatialSRID verifies that the SRID attribute of a spatial column is captured
+// for MODIFY and CHANGE COLUMN, and left empty when absent.
+func TestParse_SpatialSRID(t *testing.T) {
+	tests := []struct {
+		sql      string
+		wantType string
+		wantSRID string
+	}{
+		{"ALTER TABLE places MODIFY COLUMN g GEOMETRY NOT NULL SRID 4326", "geometry", "4326"},
+		{"ALTER TABLE places CHANGE COLUMN loc location POINT SRID 0", "point", "0"},
+		{"ALTER TABLE places MODIFY COLUMN g GEOMETRY NOT NULL", "geometry", ""},
+	}
+	for _, tt := range tests {
+		result, err := Parse(tt.sql)
+		if err != nil {
+			t.Fatalf("Parse(%q): unexpected error: %v", tt.sql, err)
+		}
+		if result.NewColumnType != tt.wantType {
+			t.Errorf("Parse(%q).NewColumnType = %q, want %q", tt.sql, result.NewColumnType, tt.wantType)
+		}
+		if result.NewColumnSRID != tt.wantSRID {
+			t.Errorf("Parse(%q).NewColumnSRID = %q, want %q", tt.sql, result.NewColumnSRID, tt.wantSRID)
+		}
+	}
+}
+
+// TestParse_AfterColumn verifies that the AFTER anchor column is captured for
+// ADD, MODIFY and CHANGE COLUMN, and left empty for FIRST or no position clause.
+func TestParse_AfterColumn(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"ALTER TABLE users ADD COLUMN x INT AFTER email", "email"},
+		{"ALTER TABLE users MODIFY COLUMN x BIGINT AFTER id", "id"},
+		{"ALTER TABLE users CHANGE COLUMN x y INT AFTER name", "name"},
+		{"ALTER TABLE users ADD COLUMN x INT FIRST", ""},
+		{"ALTER TABLE users ADD COLUMN x INT", ""},
+	}
+	for _, tt := range tests {
+		result, err := Parse(tt.sql)
+		if err != nil {
+			t.Fatalf("Parse(%q): unexpected error: %v", tt.sql, err)
+		}
+		if result.AfterColumn != tt.want {
+			t.Errorf("Parse(%q).AfterColumn = %q, want %q", tt.sql, result.AfterColumn, tt.want)
+		}
+	}
+}
+
 // TestParse_WhereClauseContent verifies the WhereClause string contains the actual
 // condition, not just that it's non-empty.
 func TestParse_WhereClauseContent(t *testing.T) {
@@ -860,6 +1114,63 @@ func TestParse_UpdateQualifiedTable(t *testing.T) {
 	}
 }
 
+// TestParse_MultiTableDML checks that multi-table DELETE and UPDATE are attributed to the
+// table they change, not the first one joined.
+func TestParse_MultiTableDML(t *testing.T) {
+	tests := []struct {
+		sql         string
+		wantDB      string
+		wantTable   string
+		wantJoin    []string
+		wantTargets []string
+		wantRef     string
+	}{
+		{
+			sql:    "DELETE c FROM customers c JOIN shop.orders o ON o.customer_id = c.id WHERE o.total = 0",
+			wantDB: "", wantTable: "customers", wantJoin: []string{"customers", "shop.orders"}, wantTargets: []string{"customers"}, wantRef: "c",
+		},
+		{
+			sql:    "DELETE FROM items USING orders JOIN items ON items.order_id = orders.id WHERE orders.status = 'void'",
+			wantDB: "", wantTable: "items", wantJoin: []string{"orders", "items"}, wantTargets: []string{"items"}, wantRef: "items",
+		},
+		{
+			sql:    "DELETE o, i FROM orders o JOIN items i ON i.order_id = o.id WHERE o.created_at < '2020-01-01'",
+			wantDB: "", wantTable: "orders", wantJoin: []string{"orders", "items"}, wantTargets: []string{"orders", "items"}, wantRef: "o",
+		},
+		{
+			sql:    "UPDATE customers c JOIN mydb.orders o ON o.customer_id = c.id SET o.status = 'vip' WHERE c.vip = 1",
+			wantDB: "mydb", wantTable: "orders", wantJoin: []string{"customers", "mydb.orders"}, wantTargets: []string{"mydb.orders"}, wantRef: "o",
+		},
+		{
+			sql:    "UPDATE orders o, customers c SET status = 'x' WHERE c.id = o.customer_id",
+			wantDB: "", wantTable: "orders", wantJoin: []string{"orders", "customers"}, wantTargets: nil, wantRef: "o",
+		},
+	}
+	for _, tt := range tests {
+		result, err := Parse(tt.sql)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.sql, err)
+		}
+		if result.Database != tt.wantDB || result.Table != tt.wantTable {
+			t.Errorf("%q: table = %q.%q, want %q.%q", tt.sql, result.Database, result.Table, tt.wantDB, tt.wantTable)
+		}
+		if !reflect.DeepEqual(result.JoinTables, tt.wantJoin) || !reflect.DeepEqual(result.TargetTables, tt.wantTargets) {
+			t.Errorf("%q: JoinTables = %v, TargetTables = %v, want %v and %v", tt.sql, result.JoinTables, result.TargetTables, tt.wantJoin, tt.wantTargets)
+		}
+		if result.TargetRef != tt.wantRef || result.FromClause == "" {
+			t.Errorf("%q: TargetRef = %q, FromClause = %q, want %q and a FROM clause", tt.sql, result.TargetRef, result.FromClause, tt.wantRef)
+		}
+	}
+
+	single, err := Parse("DELETE FROM orders WHERE id IN (SELECT order_id FROM items)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if single.JoinTables != nil || single.Table != "orders" {
+		t.Errorf("subquery DELETE: JoinTables = %v, Table = %q, want a single-table statement on orders", single.JoinTables, single.Table)
+	}
+}
+
 // TestParse_RenameTableQualified verifies that the source database and table are
 // extracted correctly when RENAME TABLE uses a qualified name.
 func TestParse_RenameTableQualified(t *testing.T) {
@@ -942,6 +1253,73 @@ func TestParse_AddColumnAutoIncrement(t *testing.T) {
 	}
 }
 
+// TestParse_Serial verifies that SERIAL and SERIAL DEFAULT VALUE, which Vitess doesn't
+// parse, are read as NOT NULL AUTO_INCREMENT UNIQUE.
+func TestParse_Serial(t *testing.T) {
+	tests := []struct {
+		sql        string
+		op         DDLOperation
+		column     string
+		columnType string
+	}{
+		{"ALTER TABLE t ADD COLUMN id SERIAL", AddColumn, "id", "bigint unsigned"},
+		{"ALTER TABLE t ADD `serial` SERIAL FIRST", AddColumn, "serial", "bigint unsigned"},
+		{"ALTER TABLE t ADD COLUMN seq INT SERIAL DEFAULT VALUE", AddColumn, "seq", "int"},
+		{"ALTER TABLE t ADD COLUMN note TEXT, ADD COLUMN id SERIAL", MultipleOps, "id", "bigint unsigned"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			result, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.DDLOp != tt.op || result.RawSQL != tt.sql {
+				t.Fatalf("DDLOp = %q, RawSQL = %q", result.DDLOp, result.RawSQL)
+			}
+			sub := result.SubOperations[len(result.SubOperations)-1]
+			if sub.ColumnName != tt.column || !strings.EqualFold(sub.NewColumnType, tt.columnType) ||
+				!sub.HasAutoIncrement || !sub.HasUniqueKey || !sub.HasNotNull {
+				t.Errorf("got %+v", sub)
+			}
+		})
+	}
+
+	// A column named serial is just a column.
+	result, err := Parse("ALTER TABLE t ADD COLUMN `serial` VARCHAR(20)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.HasAutoIncrement || result.HasUniqueKey || result.ColumnName != "serial" {
+		t.Errorf("column named serial: got %+v", result)
+	}
+}
+
+// TestParse_AddColumnExpressionDefault verifies that DEFAULT (expr) is distinguished
+// from a literal DEFAULT in ADD COLUMN.
+func TestParse_AddColumnExpressionDefault(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{"ALTER TABLE t ADD COLUMN token CHAR(36) DEFAULT (UUID())", true},
+		{"ALTER TABLE t ADD COLUMN tags JSON DEFAULT (JSON_ARRAY())", true},
+		{"ALTER TABLE t ADD COLUMN qty INT DEFAULT 5", false},
+		{"ALTER TABLE t ADD COLUMN created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP", false},
+		{"ALTER TABLE t ADD COLUMN note VARCHAR(10)", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			result, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.HasExpressionDefault != tt.want {
+				t.Errorf("HasExpressionDefault = %v, want %v", result.HasExpressionDefault, tt.want)
+			}
+		})
+	}
+}
+
 // TestParse_MultipleOps_AutoIncrementPropagated verifies that HasAutoIncrement is set
 // when ADD COLUMN AUTO_INCREMENT appears as part of a multi-op ALTER TABLE.
 func TestParse_MultipleOps_AutoIncrementPropagated(t *testing.T) {
@@ -1079,6 +1457,83 @@ func TestParse_ModifyVirtualGeneratedColumn(t *testing.T) {
 	}
 }
 
+// TestParse_ModifyGeneratedColumn_Expression verifies that the generation expression is captured.
+func TestParse_ModifyGeneratedColumn_Expression(t *testing.T) {
+	result, err := Parse("ALTER TABLE t MODIFY COLUMN total DECIMAL(12,2) AS (price*qty*1.1) STORED")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NewGenerationExpr != "price * qty * 1.1" {
+		t.Errorf("NewGenerationExpr = %q, want %q", result.NewGenerationExpr, "price * qty * 1.1")
+	}
+	if result.SubOperations[0].NewGenerationExpr != result.NewGenerationExpr {
+		t.Errorf("SubOperations[0].NewGenerationExpr = %q, want %q", result.SubOperations[0].NewGenerationExpr, result.NewGenerationExpr)
+	}
+}
+
+func TestNormalizeExpr(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"((`price` * `qty`) * 1.1)", "price*qty*1.1", true},
+		{"upper(`name`)", "UPPER(name)", true},
+		{"concat(`first`,_utf8mb4' ',`last`)", "CONCAT(first, ' ', last)", true},
+		{"(`price` * (`qty` + 1))", "price*qty+1", false},
+		{"(`price` * `qty`)", "price*qty*1.1", false},
+	}
+	for _, tt := range tests {
+		if got := NormalizeExpr(tt.a) == NormalizeExpr(tt.b); got != tt.same {
+			t.Errorf("NormalizeExpr(%q) == NormalizeExpr(%q) is %v, want %v (%q vs %q)",
+				tt.a, tt.b, got, tt.same, NormalizeExpr(tt.a), NormalizeExpr(tt.b))
+		}
+	}
+}
+
+func TestExpressionColumns(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"a + total * 2", []string{"a", "total"}},
+		{"((`price` * `qty`) * 1.1)", []string{"price", "qty"}},
+		{"concat(`first`,_utf8mb4' ',`last`, `first`)", []string{"first", "last"}},
+		{"json_unquote(json_extract(doc, '$.id'))", []string{"doc"}},
+		// Doesn't parse: the identifier scan skips the function name.
+		{"upper(`name`) +* x", []string{"name", "x"}},
+	}
+	for _, tt := range tests {
+		if got := ExpressionColumns(tt.expr); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExpressionColumns(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestColumnComparison(t *testing.T) {
+	tests := []struct {
+		expr              string
+		column, op, value string
+		ok                bool
+	}{
+		{"created_at < '2024-01-01'", "created_at", "<", "'2024-01-01'", true},
+		{"`id` >= 100", "id", ">=", "100", true},
+		{"(created_at <= '2024-01-01')", "created_at", "<=", "'2024-01-01'", true},
+		{"created_at < '2025-01-01' AND tenant_id = 7", "", "", "", false},
+		{"created_at < '2024-01-01' OR status = 'archived'", "", "", "", false},
+		{"(created_at < '2024-01-01' AND (tenant_id = 7))", "", "", "", false},
+		{"created_at < now()", "", "", "", false},
+		{"name LIKE 'a%'", "", "", "", false},
+		{"created_at <", "", "", "", false},
+	}
+	for _, tt := range tests {
+		column, op, value, ok := ColumnComparison(tt.expr)
+		if column != tt.column || op != tt.op || value != tt.value || ok != tt.ok {
+			t.Errorf("ColumnComparison(%q) = %q, %q, %q, %v; want %q, %q, %q, %v",
+				tt.expr, column, op, value, ok, tt.column, tt.op, tt.value, tt.ok)
+		}
+	}
+}
+
 // TestParse_ChangeIndexType verifies that DROP INDEX + ADD INDEX on the same name
 // is detected as ChangeIndexType.
 func TestParse_ChangeIndexType(t *testing.T) {
@@ -1257,6 +1712,19 @@ func TestParse_ForceRebuild(t *testing.T) {
 	}
 }
 
+func TestParse_OrderBy(t *testing.T) {
+	result, err := Parse("ALTER TABLE orders ORDER BY created_at")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DDLOp != OrderBy {
+		t.Errorf("DDLOp = %q, want %q", result.DDLOp, OrderBy)
+	}
+	if result.Table != "orders" {
+		t.Errorf("Table = %q, want %q", result.Table, "orders")
+	}
+}
+
 func TestParse_ReorganizePartition(t *testing.T) {
 	sql := `ALTER TABLE partition_test REORGANIZE PARTITION pmax INTO (
 		PARTITION p2026 VALUES LESS THAN (2027),
@@ -1291,6 +1759,64 @@ func TestParse_TruncatePartition(t *testing.T) {
 	}
 }
 
+func TestParse_RemovePartitioning(t *testing.T) {
+	result, err := Parse("ALTER TABLE partition_test REMOVE PARTITIONING")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DDLOp != RemovePartitioning {
+		t.Errorf("DDLOp = %q, want %q", result.DDLOp, RemovePartitioning)
+	}
+}
+
+func TestParse_RepartitionTable(t *testing.T) {
+	tests := []struct {
+		sql     string
+		columns []string
+	}{
+		{"ALTER TABLE orders PARTITION BY RANGE (YEAR(created_at)) (PARTITION p2025 VALUES LESS THAN (2026), PARTITION pmax VALUES LESS THAN MAXVALUE)", []string{"created_at"}},
+		{"ALTER TABLE orders PARTITION BY RANGE COLUMNS (region, created_at) (PARTITION p0 VALUES LESS THAN ('m', '2026-01-01'))", []string{"region", "created_at"}},
+		{"ALTER TABLE orders PARTITION BY HASH (id) PARTITIONS 8", []string{"id"}},
+		{"ALTER TABLE orders PARTITION BY KEY () PARTITIONS 4", nil},
+	}
+	for _, tt := range tests {
+		result, err := Parse(tt.sql)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.sql, err)
+		}
+		if result.DDLOp != RepartitionTable {
+			t.Errorf("%q: DDLOp = %q, want %q", tt.sql, result.DDLOp, RepartitionTable)
+		}
+		if !reflect.DeepEqual(result.PartitionColumns, tt.columns) {
+			t.Errorf("%q: PartitionColumns = %v, want %v", tt.sql, result.PartitionColumns, tt.columns)
+		}
+	}
+}
+
+func TestParse_EngineAttributeAndSecondaryEngine(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want DDLOperation
+	}{
+		{`ALTER TABLE t ENGINE_ATTRIBUTE='{"key":"value"}'`, EngineAttribute},
+		{`ALTER TABLE t SECONDARY_ENGINE_ATTRIBUTE='{"key":"value"}'`, EngineAttribute},
+		{"ALTER TABLE t SECONDARY_ENGINE = RAPID", SecondaryEngine},
+		{"ALTER TABLE db.t SECONDARY_ENGINE NULL;", SecondaryEngine},
+		{"ALTER TABLE t SECONDARY_LOAD", SecondaryLoad},
+		{"alter table db.t secondary_unload;", SecondaryUnload},
+		{"ALTER TABLE t ADD COLUMN c INT, SECONDARY_LOAD", OtherDDL},
+	}
+	for _, tt := range tests {
+		result, err := Parse(tt.sql)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.sql, err)
+		}
+		if result.DDLOp != tt.want {
+			t.Errorf("%q: DDLOp = %q, want %q", tt.sql, result.DDLOp, tt.want)
+		}
+	}
+}
+
 func TestParse_ModifyColumn_IsFirstAfter(t *testing.T) {
 	// MODIFY COLUMN with AFTER should set IsFirstAfter=true
 	result, err := Parse("ALTER TABLE t MODIFY COLUMN name VARCHAR(100) AFTER id")
@@ -1308,6 +1834,25 @@ func TestParse_ModifyColumn_IsFirstAfter(t *testing.T) {
 	}
 }
 
+func TestParse_ChangeColumn_Modifiers(t *testing.T) {
+	result, err := Parse("ALTER TABLE t CHANGE COLUMN name full_name VARCHAR(100) CHARACTER SET latin1 NOT NULL FIRST")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DDLOp != ChangeColumn {
+		t.Errorf("DDLOp = %q, want ChangeColumn", result.DDLOp)
+	}
+	if !result.IsFirstAfter {
+		t.Error("IsFirstAfter = false, want true for CHANGE COLUMN ... FIRST")
+	}
+	if result.NewColumnNullable == nil || *result.NewColumnNullable {
+		t.Errorf("NewColumnNullable = %v, want *false (NOT NULL)", result.NewColumnNullable)
+	}
+	if result.NewColumnCharset != "latin1" {
+		t.Errorf("NewColumnCharset = %q, want latin1", result.NewColumnCharset)
+	}
+}
+
 func TestParse_ModifyColumn_NotNull_SetsNullable(t *testing.T) {
 	result, err := Parse("ALTER TABLE t MODIFY COLUMN name VARCHAR(100) NOT NULL")
 	if err != nil {
@@ -1444,6 +1989,37 @@ func TestParse_OptimizeTable(t *testing.T) {
 	}
 }
 
+func TestParse_AnalyzeAndCheckTable(t *testing.T) {
+	tests := []struct {
+		sql     string
+		wantOp  DDLOperation
+		wantDB  string
+		wantTbl string
+	}{
+		{"ANALYZE TABLE orders", AnalyzeTable, "", "orders"},
+		{"ANALYZE NO_WRITE_TO_BINLOG TABLE mydb.orders", AnalyzeTable, "mydb", "orders"},
+		{"ANALYZE LOCAL TABLE orders, customers", AnalyzeTable, "", "orders"},
+		{"ANALYZE TABLE orders UPDATE HISTOGRAM ON status WITH 16 BUCKETS", AnalyzeTable, "", "orders"},
+		{"CHECK TABLE orders", CheckTable, "", "orders"},
+		{"check table `mydb`.`orders` QUICK", CheckTable, "mydb", "orders"},
+		{"CHECK TABLE orders,customers EXTENDED", CheckTable, "", "orders"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			result, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Type != DDL || result.DDLOp != tt.wantOp {
+				t.Errorf("Type/DDLOp = %q/%q, want DDL/%q", result.Type, result.DDLOp, tt.wantOp)
+			}
+			if result.Database != tt.wantDB || result.Table != tt.wantTbl {
+				t.Errorf("table = %q.%q, want %q.%q", result.Database, result.Table, tt.wantDB, tt.wantTbl)
+			}
+		})
+	}
+}
+
 // Regression #37: ALTER TABLE ... ENGINE=InnoDB must extract NewEngine.
 func TestParse_ChangeEngine_ExtractsEngineName(t *testing.T) {
 	result, err := Parse("ALTER TABLE orders ENGINE=InnoDB")
@@ -1497,6 +2073,108 @@ func TestParse_AlterTablespace(t *testing.T) {
 	}
 }
 
+func TestParse_ObjectDefinition(t *testing.T) {
+	tests := []struct {
+		sql      string
+		wantType string
+		wantDB   string
+		wantName string
+	}{
+		{"CREATE VIEW v_active AS SELECT * FROM users WHERE active = 1", "VIEW", "", "v_active"},
+		{"CREATE OR REPLACE ALGORITHM=MERGE DEFINER=`app`@`%` SQL SECURITY INVOKER VIEW shop.v_orders AS SELECT 1", "VIEW", "shop", "v_orders"},
+		{"ALTER VIEW v_active AS SELECT id FROM users", "VIEW", "", "v_active"},
+		{"DROP VIEW IF EXISTS v_active", "VIEW", "", "v_active"},
+		{"CREATE PROCEDURE shop.cleanup() BEGIN DELETE FROM logs WHERE ts < NOW(); END", "PROCEDURE", "shop", "cleanup"},
+		{"CREATE DEFINER=CURRENT_USER FUNCTION f_total(x INT) RETURNS INT DETERMINISTIC RETURN x * 2", "FUNCTION", "", "f_total"},
+		{"DROP PROCEDURE IF EXISTS cleanup", "PROCEDURE", "", "cleanup"},
+		{"CREATE TRIGGER trg_audit AFTER UPDATE ON users FOR EACH ROW INSERT INTO audit VALUES (NEW.id)", "TRIGGER", "", "trg_audit"},
+		{"drop trigger trg_audit", "TRIGGER", "", "trg_audit"},
+		{"CREATE EVENT IF NOT EXISTS purge ON SCHEDULE EVERY 1 DAY DO DELETE FROM logs", "EVENT", "", "purge"},
+	}
+	for _, tt := range tests {
+		result, err := Parse(tt.sql)
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", tt.sql, err)
+			continue
+		}
+		if result.Type != DDL || result.DDLOp != ObjectDefinition {
+			t.Errorf("Parse(%q): Type/DDLOp = %q/%q, want DDL/OBJECT_DEFINITION", tt.sql, result.Type, result.DDLOp)
+		}
+		if result.ObjectType != tt.wantType || result.Database != tt.wantDB || result.ObjectName != tt.wantName {
+			t.Errorf("Parse(%q): object = %q %q.%q, want %q %q.%q", tt.sql,
+				result.ObjectType, result.Database, result.ObjectName, tt.wantType, tt.wantDB, tt.wantName)
+		}
+		if result.Table != "" {
+			t.Errorf("Parse(%q): Table = %q, want empty", tt.sql, result.Table)
+		}
+	}
+
+	// Tables whose names merely contain these words are still ALTER/CREATE TABLE.
+	result, err := Parse("ALTER TABLE view_stats ADD COLUMN trigger_count INT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DDLOp != AddColumn {
+		t.Errorf("DDLOp = %q, want ADD_COLUMN", result.DDLOp)
+	}
+}
+
+func TestParse_AccountManagement(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"GRANT SELECT, INSERT ON shop.* TO 'app'@'%'", "GRANT"},
+		{"grant r_read to 'app'@'%'", "GRANT"},
+		{"REVOKE ALL PRIVILEGES ON shop.* FROM 'app'@'%'", "REVOKE"},
+		{"CREATE USER IF NOT EXISTS 'app'@'%' IDENTIFIED BY 'secret'", "CREATE USER"},
+		{"ALTER  USER 'app'@'%' PASSWORD EXPIRE", "ALTER USER"},
+		{"DROP USER 'app'@'%'", "DROP USER"},
+		{"RENAME USER 'app'@'%' TO 'svc'@'%'", "RENAME USER"},
+		{"CREATE ROLE r_read", "CREATE ROLE"},
+		{"SET DEFAULT ROLE r_read TO 'app'@'%'", "SET DEFAULT ROLE"},
+		{"SET PASSWORD FOR 'app'@'%' = 'secret'", "SET PASSWORD"},
+	}
+	for _, tt := range tests {
+		result, err := Parse(tt.sql)
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", tt.sql, err)
+			continue
+		}
+		if result.Type != DDL || result.DDLOp != AccountManagement || result.ObjectType != tt.want {
+			t.Errorf("Parse(%q) = %q/%q %q, want DDL/ACCOUNT_MANAGEMENT %q", tt.sql, result.Type, result.DDLOp, result.ObjectType, tt.want)
+		}
+	}
+
+	// Password literals never survive into RawSQL.
+	for _, sql := range []string{
+		"CREATE USER 'app'@'%' IDENTIFIED BY 'secret'",
+		"ALTER USER 'app'@'%' IDENTIFIED WITH caching_sha2_password BY \"secret\"",
+		"ALTER USER 'app'@'%' IDENTIFIED WITH mysql_native_password AS '*secret'",
+		"ALTER USER 'app'@'%' IDENTIFIED BY 'new' REPLACE 'secret'",
+		"SET PASSWORD FOR 'app'@'%' = 'secret'",
+		"SET PASSWORD = 'it''s secret'",
+	} {
+		result, err := Parse(sql)
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", sql, err)
+			continue
+		}
+		if strings.Contains(result.RawSQL, "secret") || !strings.Contains(result.RawSQL, "'xxxxx'") {
+			t.Errorf("Parse(%q).RawSQL = %q, want the password redacted", sql, result.RawSQL)
+		}
+	}
+
+	// A table named like an account statement is still an ALTER TABLE.
+	result, err := Parse("ALTER TABLE user_grants ADD COLUMN revoked_at DATETIME")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DDLOp != AddColumn {
+		t.Errorf("DDLOp = %q, want ADD_COLUMN", result.DDLOp)
+	}
+}
+
 func TestParse_AddForeignKey_ExtractsIndexName(t *testing.T) {
 	result, err := Parse("ALTER TABLE order_items ADD CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders(id)")
 	if err != nil {
@@ -1526,6 +2204,33 @@ func TestParse_RenameIndex_ExtractsOldIndexName(t *testing.T) {
 	}
 }
 
+func TestParse_ChangeIndexVisibility(t *testing.T) {
+	tests := []struct {
+		sql       string
+		invisible bool
+	}{
+		{"ALTER TABLE orders ALTER INDEX idx_created INVISIBLE", true},
+		{"ALTER TABLE orders ALTER INDEX idx_created VISIBLE", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			result, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.DDLOp != ChangeIndexVisibility {
+				t.Errorf("DDLOp = %q, want %q", result.DDLOp, ChangeIndexVisibility)
+			}
+			if result.IndexName != "idx_created" {
+				t.Errorf("IndexName = %q, want idx_created", result.IndexName)
+			}
+			if result.IndexInvisible != tt.invisible {
+				t.Errorf("IndexInvisible = %v, want %v", result.IndexInvisible, tt.invisible)
+			}
+		})
+	}
+}
+
 func TestParse_RenameTable_ExtractsNewTableName(t *testing.T) {
 	result, err := Parse("RENAME TABLE old_users TO new_users")
 	if err != nil {
@@ -1557,3 +2262,139 @@ func TestParse_AlterTableRenameTo_ExtractsNewTableName(t *testing.T) {
 		t.Errorf("NewTableName = %q, want archived_users", result.NewTableName)
 	}
 }
+
+func TestParse_AlterTableAlgorithmLockHints(t *testing.T) {
+	result, err := Parse("ALTER TABLE users ADD COLUMN age INT, ALGORITHM=INSTANT, LOCK=NONE")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Hints are not operations: a single ADD COLUMN stays a single op.
+	if result.DDLOp != AddColumn {
+		t.Errorf("DDLOp = %q, want %q", result.DDLOp, AddColumn)
+	}
+	if result.ColumnName != "age" {
+		t.Errorf("ColumnName = %q, want age", result.ColumnName)
+	}
+	if result.AlgorithmHint != "INSTANT" {
+		t.Errorf("AlgorithmHint = %q, want INSTANT", result.AlgorithmHint)
+	}
+	if result.LockHint != "NONE" {
+		t.Errorf("LockHint = %q, want NONE", result.LockHint)
+	}
+}
+
+func TestParse_AlterTableAlgorithmHint_MultipleOps(t *testing.T) {
+	result, err := Parse("ALTER TABLE users algorithm=inplace, ADD COLUMN age INT, DROP COLUMN legacy")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DDLOp != MultipleOps {
+		t.Errorf("DDLOp = %q, want %q", result.DDLOp, MultipleOps)
+	}
+	if len(result.SubOperations) != 2 {
+		t.Errorf("SubOperations length = %d, want 2", len(result.SubOperations))
+	}
+	if result.AlgorithmHint != "INPLACE" {
+		t.Errorf("AlgorithmHint = %q, want INPLACE", result.AlgorithmHint)
+	}
+	if result.LockHint != "" {
+		t.Errorf("LockHint = %q, want empty", result.LockHint)
+	}
+}
+
+func TestParse_RenameTableSwap(t *testing.T) {
+	result, err := Parse("RENAME TABLE shop.orders TO shop.orders_old, shop.orders_new TO shop.orders")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DDLOp != RenameTable || result.Table != "orders" || result.NewTableName != "orders_old" {
+		t.Errorf("got %s %s -> %s, want RENAME_TABLE orders -> orders_old", result.DDLOp, result.Table, result.NewTableName)
+	}
+	want := []RenamePair{
+		{FromDatabase: "shop", From: "orders", ToDatabase: "shop", To: "orders_old"},
+		{FromDatabase: "shop", From: "orders_new", ToDatabase: "shop", To: "orders"},
+	}
+	if !reflect.DeepEqual(result.RenamePairs, want) {
+		t.Errorf("RenamePairs = %+v, want %+v", result.RenamePairs, want)
+	}
+}
+
+func TestParse_LockWait(t *testing.T) {
+	tests := []struct {
+		sql     string
+		op      DDLOperation
+		wait    string
+		seconds int
+	}{
+		{"ALTER TABLE db.t NOWAIT ADD COLUMN nick VARCHAR(50)", AddColumn, "NOWAIT", 0},
+		{"alter table `t` wait 5 add index idx_nick (nick)", AddIndex, "WAIT", 5},
+		{"ALTER TABLE t ADD COLUMN nowait_flag INT", AddColumn, "", 0},
+	}
+	for _, tt := range tests {
+		result, err := Parse(tt.sql)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.sql, err)
+		}
+		if result.DDLOp != tt.op || result.LockWait != tt.wait || result.LockWaitSeconds != tt.seconds {
+			t.Errorf("%s: got %s LockWait=%q %d, want %s %q %d", tt.sql, result.DDLOp, result.LockWait, result.LockWaitSeconds, tt.op, tt.wait, tt.seconds)
+		}
+		if result.RawSQL != tt.sql {
+			t.Errorf("RawSQL = %q, want the statement as written", result.RawSQL)
+		}
+	}
+}
+
+func TestParse_ColumnIfExists(t *testing.T) {
+	tests := []struct {
+		sql    string
+		op     DDLOperation
+		column string
+		ifExst bool
+	}{
+		{"ALTER TABLE t ADD COLUMN IF NOT EXISTS nick VARCHAR(50)", AddColumn, "nick", true},
+		{"alter table t add if not exists nick varchar(50)", AddColumn, "nick", true},
+		{"ALTER TABLE t DROP COLUMN IF EXISTS nick", DropColumn, "nick", true},
+		{"ALTER TABLE t DROP COLUMN nick", DropColumn, "nick", false},
+	}
+	for _, tt := range tests {
+		result, err := Parse(tt.sql)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.sql, err)
+		}
+		if result.DDLOp != tt.op || result.ColumnName != tt.column || result.ColumnIfExists != tt.ifExst {
+			t.Errorf("%s: got %s %q ColumnIfExists=%v, want %s %q %v", tt.sql, result.DDLOp, result.ColumnName, result.ColumnIfExists, tt.op, tt.column, tt.ifExst)
+		}
+		if result.RawSQL != tt.sql {
+			t.Errorf("RawSQL = %q, want the statement as written", result.RawSQL)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	sql := `-- add the column first
+ALTER TABLE shop.orders ADD COLUMN note VARCHAR(20) DEFAULT 'a;b';
+/* then index it */
+ALTER TABLE shop.orders ADD INDEX idx_note (note);
+-- trailing comment only
+`
+	got, err := SplitStatements(sql)
+	if err != nil {
+		t.Fatalf("SplitStatements: %v", err)
+	}
+	want := []string{
+		"ALTER TABLE shop.orders ADD COLUMN note VARCHAR(20) DEFAULT 'a;b'",
+		"ALTER TABLE shop.orders ADD INDEX idx_note (note)",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d statements %q, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("statement %d = %q, want %q", i+1, got[i], want[i])
+		}
+	}
+
+	if got, err := SplitStatements("DROP TABLE t"); err != nil || len(got) != 1 || got[0] != "DROP TABLE t" {
+		t.Errorf("single statement = %q, %v", got, err)
+	}
+}
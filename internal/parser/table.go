@@ -0,0 +1,233 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// TableDefinition is a table as described by a CREATE TABLE statement: what offline
+// analysis uses in place of the live table's metadata.
+type TableDefinition struct {
+	Database    string
+	Table       string
+	Engine      string // as written, "" if absent
+	RowFormat   string // uppercase, "" if absent
+	Charset     string // table default character set (lowercase), "" if absent
+	Columns     []ColumnDefinition
+	Indexes     []IndexDefinition
+	ForeignKeys []ForeignKeyDefinition
+	Checks      []CheckDefinition
+	CreateSQL   string // the CREATE TABLE statement the definition was parsed from
+}
+
+// ColumnDefinition is one column of a TableDefinition.
+type ColumnDefinition struct {
+	Name           string
+	Type           string  // base type in INFORMATION_SCHEMA.COLUMNS.COLUMN_TYPE format
+	Nullable       bool    // false for NOT NULL and PRIMARY KEY columns
+	Default        *string // DEFAULT clause, nil if absent
+	Charset        string  // explicit CHARACTER SET (lowercase), "" if absent
+	Collation      string  // explicit COLLATE (lowercase), "" if absent
+	GenerationExpr string  // AS (expr) of a generated column, "" otherwise
+	Stored         bool    // STORED generated column
+	AutoIncrement  bool    // AUTO_INCREMENT column
+	SRID           string  // SRID attribute of a spatial column, "" if absent
+}
+
+// IndexDefinition is one index of a TableDefinition. The primary key is named PRIMARY.
+type IndexDefinition struct {
+	Name      string
+	Columns   []string
+	SubParts  []int // prefix length per column, 0 for the whole column
+	Unique    bool
+	Type      string // BTREE, FULLTEXT or SPATIAL
+	Invisible bool   // declared INVISIBLE
+}
+
+// ForeignKeyDefinition is one foreign key of a TableDefinition.
+type ForeignKeyDefinition struct {
+	Name            string
+	Columns         []string
+	ReferencedTable string
+	ReferencedCols  []string
+	DeleteRule      string // CASCADE, SET NULL, RESTRICT, NO ACTION or SET DEFAULT
+	UpdateRule      string
+}
+
+// CheckDefinition is one CHECK constraint of a TableDefinition.
+type CheckDefinition struct {
+	Name       string
+	Expression string
+}
+
+// ParseSchemaDump parses the CREATE TABLE statements of a schema dump, e.g. SHOW CREATE
+// TABLE output or mysqldump --no-data. Other statements are skipped; USE sets the database
+// of the unqualified tables that follow it.
+func ParseSchemaDump(dump string) ([]*TableDefinition, error) {
+	p, err := getParser()
+	if err != nil {
+		return nil, fmt.Errorf("creating parser: %w", err)
+	}
+	pieces, err := p.SplitStatementToPieces(dump)
+	if err != nil {
+		return nil, fmt.Errorf("splitting statements: %w", err)
+	}
+
+	var defs []*TableDefinition
+	database := ""
+	for _, piece := range pieces {
+		stmt := strings.TrimSpace(sqlparser.StripLeadingComments(piece))
+		fields := strings.Fields(strings.ToUpper(stmt))
+		switch {
+		case len(fields) == 2 && fields[0] == "USE":
+			database = strings.Trim(strings.Fields(stmt)[1], "`")
+		case len(fields) >= 2 && fields[0] == "CREATE" && fields[1] == "TABLE":
+			def, err := ParseTableDefinition(stmt)
+			if err != nil {
+				return nil, fmt.Errorf("CREATE TABLE #%d: %w", len(defs)+1, err)
+			}
+			if def.Database == "" {
+				def.Database = database
+			}
+			defs = append(defs, def)
+		}
+	}
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("no CREATE TABLE statement found")
+	}
+	return defs, nil
+}
+
+// ParseTableDefinition parses a CREATE TABLE statement (e.g. SHOW CREATE TABLE output)
+// into its columns, indexes, foreign keys, CHECK constraints and table options.
+func ParseTableDefinition(sql string) (*TableDefinition, error) {
+	create, err := parseCreateTable(sql)
+	if err != nil {
+		return nil, err
+	}
+	spec := create.TableSpec
+	def := &TableDefinition{CreateSQL: strings.TrimSpace(sql)}
+	def.Database, def.Table = extractTableName(create.Table)
+
+	for _, opt := range spec.Options {
+		switch strings.ToUpper(opt.Name) {
+		case "ENGINE":
+			def.Engine = opt.String
+		case "CHARSET", "CHARACTER SET", "DEFAULT CHARSET", "DEFAULT CHARACTER SET":
+			def.Charset = strings.ToLower(opt.String)
+		case "ROW_FORMAT":
+			def.RowFormat = strings.ToUpper(opt.String)
+		}
+	}
+
+	primary := make(map[string]bool)
+	for _, idx := range spec.Indexes {
+		if idx.Info.Type == sqlparser.IndexTypePrimary {
+			for _, col := range idx.Columns {
+				primary[strings.ToLower(col.Column.String())] = true
+			}
+		}
+	}
+
+	for _, col := range spec.Columns {
+		c := ColumnDefinition{
+			Name:     col.Name.String(),
+			Type:     baseColumnTypeString(col.Type),
+			Nullable: true,
+			Charset:  strings.ToLower(col.Type.Charset.Name),
+			SRID:     columnSRID(col.Type),
+		}
+		if opts := col.Type.Options; opts != nil {
+			if opts.Null != nil {
+				c.Nullable = *opts.Null
+			}
+			if opts.Default != nil {
+				d := sqlparser.String(opts.Default)
+				c.Default = &d
+			}
+			c.Collation = strings.ToLower(opts.Collate)
+			if opts.As != nil {
+				c.GenerationExpr = sqlparser.String(opts.As)
+				c.Stored = opts.Storage == sqlparser.StoredStorage
+			}
+			c.AutoIncrement = opts.Autoincrement
+			switch opts.KeyOpt {
+			case sqlparser.ColKeyPrimary:
+				primary[strings.ToLower(c.Name)] = true
+				def.Indexes = append(def.Indexes, IndexDefinition{Name: "PRIMARY", Columns: []string{c.Name}, SubParts: []int{0}, Unique: true, Type: "BTREE"})
+			case sqlparser.ColKeyUnique, sqlparser.ColKeyUniqueKey:
+				def.Indexes = append(def.Indexes, IndexDefinition{Name: c.Name, Columns: []string{c.Name}, SubParts: []int{0}, Unique: true, Type: "BTREE"})
+			}
+		}
+		if primary[strings.ToLower(c.Name)] {
+			c.Nullable = false
+		}
+		def.Columns = append(def.Columns, c)
+	}
+
+	for _, idx := range spec.Indexes {
+		d := IndexDefinition{Name: idx.Info.Name.String(), Unique: idx.Info.IsUnique(), Type: "BTREE"}
+		switch idx.Info.Type {
+		case sqlparser.IndexTypePrimary:
+			d.Name = "PRIMARY"
+		case sqlparser.IndexTypeFullText:
+			d.Type = "FULLTEXT"
+		case sqlparser.IndexTypeSpatial:
+			d.Type = "SPATIAL"
+		}
+		for _, opt := range idx.Options {
+			if strings.EqualFold(opt.Name, "INVISIBLE") {
+				d.Invisible = true
+			}
+		}
+		for _, col := range idx.Columns {
+			if col.Column.IsEmpty() {
+				continue // functional key part
+			}
+			d.Columns = append(d.Columns, col.Column.String())
+			subPart := 0
+			if col.Length != nil {
+				subPart = *col.Length
+			}
+			d.SubParts = append(d.SubParts, subPart)
+		}
+		def.Indexes = append(def.Indexes, d)
+	}
+
+	for _, c := range spec.Constraints {
+		if chk, ok := c.Details.(*sqlparser.CheckConstraintDefinition); ok {
+			def.Checks = append(def.Checks, CheckDefinition{Name: c.Name.String(), Expression: sqlparser.String(chk.Expr)})
+			continue
+		}
+		fk, ok := c.Details.(*sqlparser.ForeignKeyDefinition)
+		if !ok || fk.ReferenceDefinition == nil {
+			continue
+		}
+		ref := fk.ReferenceDefinition
+		d := ForeignKeyDefinition{
+			Name:            c.Name.String(),
+			ReferencedTable: ref.ReferencedTable.Name.String(),
+			DeleteRule:      referenceRule(ref.OnDelete),
+			UpdateRule:      referenceRule(ref.OnUpdate),
+		}
+		for _, col := range fk.Source {
+			d.Columns = append(d.Columns, col.String())
+		}
+		for _, col := range ref.ReferencedColumns {
+			d.ReferencedCols = append(d.ReferencedCols, col.String())
+		}
+		def.ForeignKeys = append(def.ForeignKeys, d)
+	}
+	return def, nil
+}
+
+// referenceRule returns an ON DELETE/ON UPDATE action as information_schema reports it:
+// NO ACTION when the clause is absent.
+func referenceRule(action sqlparser.ReferenceAction) string {
+	if rule := strings.ToUpper(sqlparser.String(action)); rule != "" {
+		return rule
+	}
+	return "NO ACTION"
+}
@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseTableDefinition(t *testing.T) {
+	def, err := ParseTableDefinition("CREATE TABLE `shop`.`orders` (\n" +
+		"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `user_id` int NOT NULL,\n" +
+		"  `note` varchar(255) DEFAULT NULL,\n" +
+		"  `code` char(8) CHARACTER SET latin1 COLLATE latin1_bin NOT NULL,\n" +
+		"  `total` decimal(10,2) GENERATED ALWAYS AS ((`user_id` * 2)) STORED,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uk_code` (`code`),\n" +
+		"  KEY `idx_note` (`note`(20)) /*!80000 INVISIBLE */,\n" +
+		"  CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,\n" +
+		"  CONSTRAINT `chk_total` CHECK ((`total` >= 0))\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 ROW_FORMAT=dynamic")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if def.Database != "shop" || def.Table != "orders" {
+		t.Errorf("table = %s.%s, want shop.orders", def.Database, def.Table)
+	}
+	if def.Engine != "InnoDB" || def.Charset != "utf8mb4" || def.RowFormat != "DYNAMIC" {
+		t.Errorf("options = %q/%q/%q, want InnoDB/utf8mb4/DYNAMIC", def.Engine, def.Charset, def.RowFormat)
+	}
+
+	if len(def.Columns) != 5 {
+		t.Fatalf("got %d columns, want 5", len(def.Columns))
+	}
+	id, note, code, total := def.Columns[0], def.Columns[2], def.Columns[3], def.Columns[4]
+	if id.Type != "bigint unsigned" || id.Nullable || !id.AutoIncrement {
+		t.Errorf("id = %+v, want NOT NULL AUTO_INCREMENT bigint unsigned", id)
+	}
+	if note.AutoIncrement {
+		t.Errorf("note = %+v, want no AUTO_INCREMENT", note)
+	}
+	if !note.Nullable || note.Default == nil || *note.Default != "null" {
+		t.Errorf("note = %+v, want nullable with DEFAULT NULL", note)
+	}
+	if code.Charset != "latin1" || code.Collation != "latin1_bin" {
+		t.Errorf("code charset/collation = %q/%q, want latin1/latin1_bin", code.Charset, code.Collation)
+	}
+	if !total.Stored || total.GenerationExpr == "" {
+		t.Errorf("total = %+v, want a stored generated column", total)
+	}
+
+	wantIndexes := []IndexDefinition{
+		{Name: "PRIMARY", Columns: []string{"id"}, SubParts: []int{0}, Unique: true, Type: "BTREE"},
+		{Name: "uk_code", Columns: []string{"code"}, SubParts: []int{0}, Unique: true, Type: "BTREE"},
+		{Name: "idx_note", Columns: []string{"note"}, SubParts: []int{20}, Type: "BTREE", Invisible: true},
+	}
+	if !reflect.DeepEqual(def.Indexes, wantIndexes) {
+		t.Errorf("indexes = %+v, want %+v", def.Indexes, wantIndexes)
+	}
+
+	wantFKs := []ForeignKeyDefinition{{
+		Name: "fk_user", Columns: []string{"user_id"}, ReferencedTable: "users", ReferencedCols: []string{"id"},
+		DeleteRule: "CASCADE", UpdateRule: "NO ACTION",
+	}}
+	if !reflect.DeepEqual(def.ForeignKeys, wantFKs) {
+		t.Errorf("foreign keys = %+v, want %+v", def.ForeignKeys, wantFKs)
+	}
+
+	if len(def.Checks) != 1 || def.Checks[0].Name != "chk_total" || !slices.Equal(ExpressionColumns(def.Checks[0].Expression), []string{"total"}) {
+		t.Errorf("checks = %+v, want chk_total on total", def.Checks)
+	}
+}
+
+func TestParseTableDefinition_ColumnLevelKeys(t *testing.T) {
+	def, err := ParseTableDefinition("CREATE TABLE t (id INT PRIMARY KEY, email VARCHAR(100) UNIQUE)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if def.Columns[0].Nullable {
+		t.Error("primary key column should not be nullable")
+	}
+	if len(def.Indexes) != 2 || def.Indexes[0].Name != "PRIMARY" || !def.Indexes[1].Unique {
+		t.Errorf("indexes = %+v, want PRIMARY and a unique key", def.Indexes)
+	}
+}
+
+func TestParseTableDefinition_SRID(t *testing.T) {
+	def, err := ParseTableDefinition("CREATE TABLE places (id INT PRIMARY KEY, g GEOMETRY NOT NULL SRID 4326, p POINT)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if def.Columns[1].SRID != "4326" || def.Columns[2].SRID != "" {
+		t.Errorf("SRIDs = %q, %q, want 4326 and none", def.Columns[1].SRID, def.Columns[2].SRID)
+	}
+}
+
+func TestParseTableDefinition_NotCreateTable(t *testing.T) {
+	if _, err := ParseTableDefinition("ALTER TABLE t ADD COLUMN x INT"); err == nil {
+		t.Error("expected an error for a non-CREATE TABLE statement")
+	}
+}
+
+func TestParseSchemaDump(t *testing.T) {
+	dump := "-- MySQL dump 10.13\n" +
+		"/*!40101 SET NAMES utf8mb4 */;\n" +
+		"USE `shop`;\n" +
+		"--\n-- Table structure for table `users`\n--\n\n" +
+		"DROP TABLE IF EXISTS `users`;\n" +
+		"CREATE TABLE `users` (\n  `id` int NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB;\n" +
+		"CREATE TABLE `audit`.`events` (`id` bigint NOT NULL, `note` varchar(255) COMMENT 'a; b') ENGINE=InnoDB;\n"
+	defs, err := ParseSchemaDump(dump)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(defs) != 2 {
+		t.Fatalf("got %d definitions, want 2", len(defs))
+	}
+	if defs[0].Database != "shop" || defs[0].Table != "users" || !strings.HasPrefix(defs[0].CreateSQL, "CREATE TABLE `users`") {
+		t.Errorf("first = %s.%s %q", defs[0].Database, defs[0].Table, defs[0].CreateSQL)
+	}
+	if defs[1].Database != "audit" || defs[1].Table != "events" || len(defs[1].Columns) != 2 {
+		t.Errorf("second = %s.%s with %d columns", defs[1].Database, defs[1].Table, len(defs[1].Columns))
+	}
+
+	if _, err := ParseSchemaDump("DROP TABLE t;"); err == nil {
+		t.Error("expected an error for a dump without CREATE TABLE")
+	}
+}
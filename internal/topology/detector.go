@@ -22,6 +22,7 @@ const (
 	GroupRepl       Type = "group-replication"
 	AuroraWriter    Type = "aurora-writer"
 	AuroraReader    Type = "aurora-reader"
+	Proxied         Type = "proxied" // ProxySQL or Vitess vtgate in front of the backend
 )
 
 // Info holds the full topology state.
@@ -33,6 +34,12 @@ type Info struct {
 	IsReplica      bool
 	IsPrimary      bool // has replicas attached
 	ReplicaLagSecs *int64
+	SourceHost     string // replica: the host it replicates from (Source_Host)
+	SourcePort     int
+	MasterMaster   bool // replica whose source is also one of its replicas: circular (master-master) replication
+
+	// Semi-sync
+	SemiSyncTimeoutMs int64 // rpl_semi_sync_source_timeout (or _master_ on older servers), 0 if unknown
 
 	// Galera / PXC
 	GaleraClusterSize    int
@@ -52,9 +59,16 @@ type Info struct {
 	ReadOnly      bool
 	SuperReadOnly bool
 
+	// Binary log
+	BinlogRowImage string // binlog_row_image (lowercase): full, minimal or noblob; "" if unknown
+
 	// Cloud
-	IsCloudManaged bool
-	CloudProvider  string // "aws-aurora", "aws-rds", ""
+	IsCloudManaged      bool
+	CloudProvider       string // "aws-aurora", "aws-rds", ""
+	AuroraBinlogEnabled bool   // Aurora writer with log_bin=ON (binlog_format set in the cluster parameter group)
+
+	// Proxy
+	Proxy string // "proxysql", "vitess", ""
 }
 
 // Detect connects to MySQL and determines the topology.
@@ -75,18 +89,21 @@ func Detect(db *sql.DB, verbose bool) (*Info, error) {
 	sro, _ := mysql.GetVariable(db, "super_read_only")
 	info.SuperReadOnly = sro == "ON"
 
+	// Proxy detection: through a proxy, variables and status may come from any backend
+	// (or from the proxy itself), so the backend topology can't be determined reliably.
+	if detectProxy(db, info) {
+		return info, nil
+	}
+
+	// Row image size drives the write-set estimate for DML on Galera and Group Replication.
+	image, _ := mysql.GetVariable(db, "binlog_row_image")
+	info.BinlogRowImage = strings.ToLower(image)
+
 	// Aurora detection: must happen before Galera/GR since Aurora has its own replication model.
 	if version.IsAurora() {
 		info.IsCloudManaged = true
 		info.CloudProvider = "aws-aurora"
-		// Aurora sets innodb_read_only=ON on readers but leaves read_only=OFF on both;
-		// only innodb_read_only reliably distinguishes Writer from Reader.
-		iro, _ := mysql.GetVariable(db, "innodb_read_only")
-		if iro == "ON" {
-			info.Type = AuroraReader
-		} else {
-			info.Type = AuroraWriter
-		}
+		detectAuroraRole(db, info)
 		return info, nil
 	}
 
@@ -127,12 +144,7 @@ func Detect(db *sql.DB, verbose bool) (*Info, error) {
 	if info.Version.EnrichFromBasedir(basedir) {
 		info.IsCloudManaged = true
 		info.CloudProvider = "aws-aurora"
-		iro, _ := mysql.GetVariable(db, "innodb_read_only")
-		if iro == "ON" {
-			info.Type = AuroraReader
-		} else {
-			info.Type = AuroraWriter
-		}
+		detectAuroraRole(db, info)
 	} else if strings.Contains(basedir, "rdsdbbin") {
 		info.IsCloudManaged = true
 		info.CloudProvider = "aws-rds"
@@ -141,6 +153,41 @@ func Detect(db *sql.DB, verbose bool) (*Info, error) {
 	return info, nil
 }
 
+// detectAuroraRole sets Writer or Reader and, on the writer, whether binary logging is on.
+// Aurora sets innodb_read_only=ON on readers but leaves read_only=OFF on both;
+// only innodb_read_only reliably distinguishes Writer from Reader.
+func detectAuroraRole(db *sql.DB, info *Info) {
+	iro, _ := mysql.GetVariable(db, "innodb_read_only")
+	if iro == "ON" {
+		info.Type = AuroraReader
+		return
+	}
+	info.Type = AuroraWriter
+	// Aurora replicates through the storage layer; binlogs only exist when the cluster
+	// parameter group sets binlog_format, which turns log_bin ON on the writer.
+	logBin, _ := mysql.GetVariable(db, "log_bin")
+	info.AuroraBinlogEnabled = logBin == "ON"
+}
+
+// detectProxy reports whether the connection goes through a SQL-aware proxy.
+// Vitess vtgate advertises itself in VERSION() (e.g. "8.0.30-Vitess"). ProxySQL answers
+// "SELECT @@version_comment LIMIT 1" — the query the mysql client sends on connect —
+// itself with "(ProxySQL)" instead of forwarding it to a backend.
+func detectProxy(db *sql.DB, info *Info) bool {
+	if strings.Contains(strings.ToLower(info.Version.Raw), "vitess") {
+		info.Proxy = "vitess"
+	} else {
+		var comment string
+		err := db.QueryRowContext(context.Background(), "SELECT @@version_comment LIMIT 1").Scan(&comment)
+		if err != nil || !strings.Contains(strings.ToLower(comment), "proxysql") {
+			return false
+		}
+		info.Proxy = "proxysql"
+	}
+	info.Type = Proxied
+	return true
+}
+
 func detectGalera(db *sql.DB, info *Info, verbose bool) (bool, error) {
 	// First, check if this is PXC by looking at version_comment
 	versionComment, _ := mysql.GetVariable(db, "version_comment")
@@ -282,6 +329,7 @@ func detectGroupReplication(db *sql.DB, info *Info) (bool, error) {
 
 func detectReplication(db *sql.DB, info *Info) (bool, error) {
 	detected := false
+	var sourceServerID string
 
 	// Check if this server is a replica
 	rows, err := db.QueryContext(context.Background(), "SHOW REPLICA STATUS")
@@ -313,6 +361,12 @@ func detectReplication(db *sql.DB, info *Info) (bool, error) {
 						lag, _ := strconv.ParseInt(values[i].String, 10, 64)
 						info.ReplicaLagSecs = &lag
 					}
+				case "Source_Host", "Master_Host":
+					info.SourceHost = values[i].String
+				case "Source_Port", "Master_Port":
+					info.SourcePort, _ = strconv.Atoi(values[i].String)
+				case "Source_Server_Id", "Master_Server_Id":
+					sourceServerID = values[i].String
 				}
 			}
 		}
@@ -326,14 +380,28 @@ func detectReplication(db *sql.DB, info *Info) (bool, error) {
 		detected = true
 	}
 
+	// A replica whose own source is among its replicas is in circular (master-master)
+	// replication: gh-ost refuses to run there without --allow-master-master.
+	if info.IsReplica && info.IsPrimary && sourceServerID != "" && sourceServerID != "0" {
+		for _, id := range replicaServerIDs(db) {
+			if id == sourceServerID {
+				info.MasterMaster = true
+				break
+			}
+		}
+	}
+
 	if detected {
 		// Check semi-sync
-		semiSync, _ := mysql.GetVariable(db, "rpl_semi_sync_source_enabled")
+		prefix := "rpl_semi_sync_source"
+		semiSync, _ := mysql.GetVariable(db, prefix+"_enabled")
 		if semiSync == "" {
-			semiSync, _ = mysql.GetVariable(db, "rpl_semi_sync_master_enabled")
+			prefix = "rpl_semi_sync_master"
+			semiSync, _ = mysql.GetVariable(db, prefix+"_enabled")
 		}
 		if semiSync == "ON" {
 			info.Type = SemiSyncReplica
+			info.SemiSyncTimeoutMs, _ = mysql.GetVariableInt(db, prefix+"_timeout")
 		} else {
 			info.Type = AsyncReplica
 		}
@@ -341,3 +409,34 @@ func detectReplication(db *sql.DB, info *Info) (bool, error) {
 
 	return detected, nil
 }
+
+// replicaServerIDs returns the server_id of each replica registered with this server
+// (SHOW REPLICAS, or SHOW SLAVE HOSTS before 8.0.22), nil when they can't be listed.
+func replicaServerIDs(db *sql.DB) []string {
+	rows, err := db.QueryContext(context.Background(), "SHOW REPLICAS")
+	if err != nil {
+		if rows, err = db.QueryContext(context.Background(), "SHOW SLAVE HOSTS"); err != nil {
+			return nil
+		}
+	}
+	defer rows.Close()
+
+	cols, _ := rows.Columns()
+	var ids []string
+	for rows.Next() {
+		values := make([]sql.NullString, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return ids
+		}
+		for i, col := range cols {
+			if strings.EqualFold(col, "Server_id") {
+				ids = append(ids, values[i].String)
+			}
+		}
+	}
+	return ids
+}
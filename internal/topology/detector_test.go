@@ -260,6 +260,12 @@ func TestDetect_PXCCluster(t *testing.T) {
 	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'super\\\\_read\\\\_only'").
 		WillReturnRows(superReadOnlyRows)
 
+	// Mock binlog_row_image
+	rowImageRows := sqlmock.NewRows([]string{"Variable_name", "Value"}).
+		AddRow("binlog_row_image", "MINIMAL")
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'binlog\\\\_row\\\\_image'").
+		WillReturnRows(rowImageRows)
+
 	// Mock wsrep_on (Galera detection)
 	// wsrep_on requires SHOW VARIABLES (not GLOBAL)
 	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'wsrep\\\\_on'").
@@ -313,6 +319,10 @@ func TestDetect_PXCCluster(t *testing.T) {
 		t.Errorf("expected Flavor=percona-xtradb-cluster, got %s", info.Version.Flavor)
 	}
 
+	if info.BinlogRowImage != "minimal" {
+		t.Errorf("expected BinlogRowImage=minimal, got %q", info.BinlogRowImage)
+	}
+
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("unfulfilled expectations: %v", err)
 	}
@@ -499,6 +509,10 @@ func TestDetect_Standalone(t *testing.T) {
 	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'super\\\\_read\\\\_only'").
 		WillReturnRows(superReadOnlyRows)
 
+	// Mock proxy detection - answered by the server itself, not ProxySQL
+	mock.ExpectQuery("SELECT @@version_comment LIMIT 1").
+		WillReturnRows(sqlmock.NewRows([]string{"@@version_comment"}).AddRow("MySQL Community Server - GPL"))
+
 	// Mock wsrep_on - doesn't exist on standalone
 	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'wsrep\\\\_on'").
 		WillReturnError(sql.ErrNoRows)
@@ -565,6 +579,10 @@ func TestDetect_AuroraWriter(t *testing.T) {
 	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'innodb\\\\_read\\\\_only'").
 		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("innodb_read_only", "OFF"))
 
+	// Mock log_bin = OFF (Aurora default: no binlogs)
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'log\\\\_bin'").
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("log_bin", "OFF"))
+
 	info, err := Detect(db, false)
 	if err != nil {
 		t.Fatalf("Detect returned error: %v", err)
@@ -582,6 +600,46 @@ func TestDetect_AuroraWriter(t *testing.T) {
 	if info.Version.AuroraVersion != "3.04.0" {
 		t.Errorf("expected AuroraVersion=3.04.0, got %s", info.Version.AuroraVersion)
 	}
+	if info.AuroraBinlogEnabled {
+		t.Error("expected AuroraBinlogEnabled=false")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestDetect_AuroraWriter_BinlogEnabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT VERSION\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow("8.0.mysql_aurora.3.04.0"))
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'read\\\\_only'").
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("read_only", "OFF"))
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'super\\\\_read\\\\_only'").
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("super_read_only", "OFF"))
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'innodb\\\\_read\\\\_only'").
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("innodb_read_only", "OFF"))
+
+	// Mock log_bin = ON (binlog_format set in the cluster parameter group)
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'log\\\\_bin'").
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("log_bin", "ON"))
+
+	info, err := Detect(db, false)
+	if err != nil {
+		t.Fatalf("Detect returned error: %v", err)
+	}
+
+	if info.Type != AuroraWriter {
+		t.Errorf("expected Type=AuroraWriter, got %s", info.Type)
+	}
+	if !info.AuroraBinlogEnabled {
+		t.Error("expected AuroraBinlogEnabled=true")
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("unfulfilled expectations: %v", err)
@@ -684,6 +742,10 @@ func TestDetect_AuroraWriter_FromBasedir(t *testing.T) {
 	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'innodb\\\\_read\\\\_only'").
 		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("innodb_read_only", "OFF"))
 
+	// Mock log_bin = OFF (Aurora default: no binlogs)
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'log\\\\_bin'").
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("log_bin", "OFF"))
+
 	info, err := Detect(db, false)
 	if err != nil {
 		t.Fatalf("Detect returned error: %v", err)
@@ -701,6 +763,9 @@ func TestDetect_AuroraWriter_FromBasedir(t *testing.T) {
 	if info.Version.AuroraVersion != "3.04.0" {
 		t.Errorf("expected AuroraVersion=3.04.0, got %s", info.Version.AuroraVersion)
 	}
+	if info.AuroraBinlogEnabled {
+		t.Error("expected AuroraBinlogEnabled=false")
+	}
 	if info.Version.Patch != 28 {
 		t.Errorf("expected Patch=28 (from VERSION()), got %d", info.Version.Patch)
 	}
@@ -1148,6 +1213,63 @@ func TestDetect_AsyncReplication_Primary(t *testing.T) {
 	}
 }
 
+// A replica whose source is also registered as one of its replicas is a master-master pair.
+func TestDetect_AsyncReplication_MasterMaster(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT VERSION\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow("8.0.35"))
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'read\\\\_only'").
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("read_only", "OFF"))
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'super\\\\_read\\\\_only'").
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("super_read_only", "OFF"))
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'wsrep\\\\_on'").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SHOW VARIABLES LIKE 'wsrep\\\\_on'").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'group\\\\_replication\\\\_group\\\\_name'").
+		WillReturnError(sql.ErrNoRows)
+
+	// Replicates from server 2 on db-b:3306...
+	mock.ExpectQuery("SHOW REPLICA STATUS").
+		WillReturnRows(sqlmock.NewRows([]string{"Source_Host", "Source_Port", "Source_Server_Id", "Seconds_Behind_Source"}).
+			AddRow("db-b", 3306, 2, "0"))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM information_schema.PROCESSLIST").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+	// ...which is also one of its replicas.
+	mock.ExpectQuery("SHOW REPLICAS").
+		WillReturnRows(sqlmock.NewRows([]string{"Server_Id", "Host", "Port", "Source_Id", "Replica_UUID"}).
+			AddRow(2, "db-b", 3306, 1, "uuid-b"))
+
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'rpl\\\\_semi\\\\_sync\\\\_source\\\\_enabled'").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SHOW VARIABLES LIKE 'rpl\\\\_semi\\\\_sync\\\\_source\\\\_enabled'").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'rpl\\\\_semi\\\\_sync\\\\_master\\\\_enabled'").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SHOW VARIABLES LIKE 'rpl\\\\_semi\\\\_sync\\\\_master\\\\_enabled'").
+		WillReturnError(sql.ErrNoRows)
+
+	info, err := Detect(db, false)
+	if err != nil {
+		t.Fatalf("Detect returned error: %v", err)
+	}
+	if !info.MasterMaster {
+		t.Error("expected MasterMaster=true")
+	}
+	if info.SourceHost != "db-b" || info.SourcePort != 3306 {
+		t.Errorf("source = %s:%d, want db-b:3306", info.SourceHost, info.SourcePort)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
 func TestDetect_SemiSyncReplication(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -1192,6 +1314,9 @@ func TestDetect_SemiSyncReplication(t *testing.T) {
 	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'rpl\\\\_semi\\\\_sync\\\\_source\\\\_enabled'").
 		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
 			AddRow("rpl_semi_sync_source_enabled", "ON"))
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'rpl\\\\_semi\\\\_sync\\\\_source\\\\_timeout'").
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("rpl_semi_sync_source_timeout", "10000"))
 
 	info, err := Detect(db, false)
 	if err != nil {
@@ -1201,6 +1326,9 @@ func TestDetect_SemiSyncReplication(t *testing.T) {
 	if info.Type != SemiSyncReplica {
 		t.Errorf("expected Type=SemiSyncReplica, got %s", info.Type)
 	}
+	if info.SemiSyncTimeoutMs != 10000 {
+		t.Errorf("expected SemiSyncTimeoutMs=10000, got %d", info.SemiSyncTimeoutMs)
+	}
 	if !info.IsReplica {
 		t.Error("expected IsReplica=true")
 	}
@@ -1263,6 +1391,9 @@ func TestDetect_SemiSync_OldSyntax(t *testing.T) {
 	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'rpl\\\\_semi\\\\_sync\\\\_master\\\\_enabled'").
 		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
 			AddRow("rpl_semi_sync_master_enabled", "ON"))
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'rpl\\\\_semi\\\\_sync\\\\_master\\\\_timeout'").
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("rpl_semi_sync_master_timeout", "500"))
 
 	info, err := Detect(db, false)
 	if err != nil {
@@ -1272,6 +1403,9 @@ func TestDetect_SemiSync_OldSyntax(t *testing.T) {
 	if info.Type != SemiSyncReplica {
 		t.Errorf("expected Type=SemiSyncReplica, got %s", info.Type)
 	}
+	if info.SemiSyncTimeoutMs != 500 {
+		t.Errorf("expected SemiSyncTimeoutMs=500, got %d", info.SemiSyncTimeoutMs)
+	}
 	if !info.IsReplica {
 		t.Error("expected IsReplica=true")
 	}
@@ -1285,3 +1419,74 @@ func TestDetect_SemiSync_OldSyntax(t *testing.T) {
 		t.Errorf("unfulfilled expectations: %v", err)
 	}
 }
+
+func TestDetect_ProxySQL(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT VERSION\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow("8.0.35"))
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'read\\\\_only'").
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("read_only", "OFF"))
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'super\\\\_read\\\\_only'").
+		WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("super_read_only", "OFF"))
+
+	// ProxySQL intercepts this query and answers it itself
+	mock.ExpectQuery("SELECT @@version_comment LIMIT 1").
+		WillReturnRows(sqlmock.NewRows([]string{"@@version_comment"}).AddRow("(ProxySQL)"))
+
+	info, err := Detect(db, false)
+	if err != nil {
+		t.Fatalf("Detect returned error: %v", err)
+	}
+
+	if info.Type != Proxied {
+		t.Errorf("expected Type=Proxied, got %s", info.Type)
+	}
+	if info.Proxy != "proxysql" {
+		t.Errorf("expected Proxy=proxysql, got %q", info.Proxy)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestDetect_Vitess(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	// vtgate advertises itself in VERSION(); no further proxy query is needed
+	mock.ExpectQuery("SELECT VERSION\\(\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow("8.0.30-Vitess"))
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'read\\\\_only'").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SHOW VARIABLES LIKE 'read\\\\_only'").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SHOW GLOBAL VARIABLES LIKE 'super\\\\_read\\\\_only'").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SHOW VARIABLES LIKE 'super\\\\_read\\\\_only'").
+		WillReturnError(sql.ErrNoRows)
+
+	info, err := Detect(db, false)
+	if err != nil {
+		t.Fatalf("Detect returned error: %v", err)
+	}
+
+	if info.Type != Proxied {
+		t.Errorf("expected Type=Proxied, got %s", info.Type)
+	}
+	if info.Proxy != "vitess" {
+		t.Errorf("expected Proxy=vitess, got %q", info.Proxy)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}